@@ -25,6 +25,11 @@ export default defineConfig({
           { text: 'Querying', link: '/guide/querying' },
           { text: 'Scripts', link: '/guide/scripts' },
           { text: 'Indexes and statistics', link: '/guide/indexes-and-stats' },
+          { text: 'Backup and restore', link: '/guide/backup' },
+          { text: 'Copying between servers', link: '/guide/transfer' },
+          { text: 'Masking personal data', link: '/guide/masking' },
+          { text: 'Generating test data', link: '/guide/generate' },
+          { text: 'Audit log', link: '/guide/audit-log' },
         ],
       },
       {
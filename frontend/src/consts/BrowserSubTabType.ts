@@ -3,4 +3,5 @@ export enum BrowserSubTabType {
   Indexes = 'indexes',
   Statistics = 'statistics',
   Schema = 'schema',
+  Terminal = 'terminal',
 }
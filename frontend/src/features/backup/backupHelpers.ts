@@ -0,0 +1,86 @@
+import { type models } from 'wailsjs/go/models.ts'
+
+export type BackupFormat = 'directory' | 'archive'
+
+/** The file name offered when saving an archive: the database and the time. */
+export function defaultArchiveName(database: string, gzip: boolean, now: Date = new Date()): string {
+  const pad = (n: number) => String(n).padStart(2, '0')
+  const stamp =
+    `${now.getFullYear()}${pad(now.getMonth() + 1)}${pad(now.getDate())}` +
+    `-${pad(now.getHours())}${pad(now.getMinutes())}`
+  return `${database}-${stamp}.archive${gzip ? '.gz' : ''}`
+}
+
+/**
+ * How far through a dump or restore is, from 0 to 100: the collections done,
+ * plus the part of the current one when its size is known.
+ */
+export function progressPercent(progress: models.BackupProgress | null): number {
+  if (!progress || progress.collections <= 0) {
+    return 0
+  }
+  let done = progress.collectionsDone
+  if (progress.total > 0 && done < progress.collections) {
+    done += Math.min(1, progress.documents / progress.total)
+  }
+  return Math.min(100, Math.round((done / progress.collections) * 100))
+}
+
+export type BackupTotals = {
+  collections: number
+  documents: number
+  failed: number
+}
+
+export function totals(result: models.BackupResult): BackupTotals {
+  return result.collections.reduce(
+    (acc, c) => ({
+      collections: acc.collections + 1,
+      documents: acc.documents + c.documents,
+      failed: acc.failed + c.failed,
+    }),
+    { collections: 0, documents: 0, failed: 0 },
+  )
+}
+
+export interface DumpRequestOptions {
+  dumpId: string
+  serverId: string
+  database: string
+  collections: string[]
+  path: string
+  format: BackupFormat
+  gzip: boolean
+}
+
+export function buildDumpRequest(opts: DumpRequestOptions): models.DumpRequest {
+  return {
+    dumpId: opts.dumpId,
+    serverId: opts.serverId,
+    database: opts.database,
+    collections: [...opts.collections],
+    path: opts.path,
+    format: opts.format,
+    gzip: opts.gzip,
+  }
+}
+
+export interface RestoreRequestOptions {
+  restoreId: string
+  serverId: string
+  path: string
+  format: BackupFormat
+  database: string
+  drop: boolean
+}
+
+export function buildRestoreRequest(opts: RestoreRequestOptions): models.RestoreRequest {
+  return {
+    restoreId: opts.restoreId,
+    serverId: opts.serverId,
+    path: opts.path,
+    format: opts.format,
+    database: opts.database.trim(),
+    drop: opts.drop,
+  }
+}
@@ -0,0 +1,98 @@
+import { describe, expect, test } from 'vitest'
+import {
+  buildDumpRequest,
+  buildRestoreRequest,
+  defaultArchiveName,
+  progressPercent,
+  totals,
+} from '../backupHelpers'
+
+describe('defaultArchiveName', () => {
+  const when = new Date(2026, 2, 7, 9, 5)
+  test('names the database and the time', () => {
+    expect(defaultArchiveName('shop', false, when)).toBe('shop-20260307-0905.archive')
+  })
+  test('adds .gz when compressed', () => {
+    expect(defaultArchiveName('shop', true, when)).toBe('shop-20260307-0905.archive.gz')
+  })
+})
+
+describe('progressPercent', () => {
+  const base = { id: 'b', namespace: 'shop.orders', documents: 0, total: 0, collectionsDone: 0, collections: 4 }
+
+  test('is zero before any progress', () => {
+    expect(progressPercent(null)).toBe(0)
+    expect(progressPercent({ ...base, collections: 0 })).toBe(0)
+  })
+  test('counts finished collections', () => {
+    expect(progressPercent({ ...base, collectionsDone: 2 })).toBe(50)
+  })
+  test('adds the part of the current collection', () => {
+    expect(progressPercent({ ...base, collectionsDone: 1, documents: 50, total: 100 })).toBe(38)
+  })
+  test('never passes 100', () => {
+    expect(progressPercent({ ...base, collectionsDone: 4, documents: 10, total: 5 })).toBe(100)
+  })
+})
+
+describe('totals', () => {
+  test('sums the collections', () => {
+    expect(
+      totals({
+        path: '/tmp/dump',
+        cancelled: false,
+        collections: [
+          { namespace: 'shop.orders', type: 'collection', documents: 10, indexes: 2, failed: 1 },
+          { namespace: 'shop.big', type: 'view', documents: 0, indexes: 0, failed: 0 },
+        ],
+      }),
+    ).toEqual({ collections: 2, documents: 10, failed: 1 })
+  })
+})
+
+describe('buildDumpRequest', () => {
+  test('copies the options', () => {
+    const collections = ['orders']
+    const req = buildDumpRequest({
+      dumpId: 'd1',
+      serverId: 'srv',
+      database: 'shop',
+      collections,
+      path: '/backups',
+      format: 'directory',
+      gzip: true,
+    })
+    expect(req).toEqual({
+      dumpId: 'd1',
+      serverId: 'srv',
+      database: 'shop',
+      collections: ['orders'],
+      path: '/backups',
+      format: 'directory',
+      gzip: true,
+    })
+    expect(req.collections).not.toBe(collections)
+  })
+})
+
+describe('buildRestoreRequest', () => {
+  test('trims the target database', () => {
+    expect(
+      buildRestoreRequest({
+        restoreId: 'r1',
+        serverId: 'srv',
+        path: '/backups/shop.archive',
+        format: 'archive',
+        database: '  copy ',
+        drop: true,
+      }),
+    ).toEqual({
+      restoreId: 'r1',
+      serverId: 'srv',
+      path: '/backups/shop.archive',
+      format: 'archive',
+      database: 'copy',
+      drop: true,
+    })
+  })
+})
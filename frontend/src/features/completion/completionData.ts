@@ -153,6 +153,61 @@ export const mongoMethods = [
     detail: '(spec) - finds and modifies a document (legacy; prefer findOneAnd*)',
     snippet: 'findAndModify({$1})$0',
   },
+  {
+    label: 'hideIndex',
+    detail: '(index) - hides an index from the query planner',
+    snippet: "hideIndex('$1')$0",
+  },
+  {
+    label: 'unhideIndex',
+    detail: '(index) - makes a hidden index visible to the query planner',
+    snippet: "unhideIndex('$1')$0",
+  },
+  {
+    label: 'latencyStats',
+    detail: '(options?) - returns latency statistics for the collection',
+    snippet: 'latencyStats()$0',
+  },
+  {
+    label: 'compact',
+    detail: "(options?) - defragments the collection's data and indexes",
+    snippet: 'compact()$0',
+  },
+  {
+    label: 'reIndex',
+    detail: '() - rebuilds all indexes on the collection',
+    snippet: 'reIndex()$0',
+  },
+  {
+    label: 'getShardVersion',
+    detail: "() - returns the collection's shard version",
+    snippet: 'getShardVersion()$0',
+  },
+  {
+    label: 'configureQueryAnalyzer',
+    detail: '(options) - configures query sampling for the collection',
+    snippet: "configureQueryAnalyzer({ mode: '$1' })$0",
+  },
+  {
+    label: 'analyzeShardKey',
+    detail: '(key, options?) - reports how well a shard key would work',
+    snippet: 'analyzeShardKey({$1})$0',
+  },
+  {
+    label: 'getSearchIndexes',
+    detail: "(name?) - lists the collection's Atlas Search indexes",
+    snippet: 'getSearchIndexes()$0',
+  },
+  {
+    label: 'checkMetadataConsistency',
+    detail: "(options?) - checks the collection's sharding metadata",
+    snippet: 'checkMetadataConsistency()$0',
+  },
+  {
+    label: 'runCommand',
+    detail: '(name, options?) - runs a command against the collection',
+    snippet: "runCommand('$1')$0",
+  },
 ]
 
 export const queryOperators = [
@@ -550,4 +605,75 @@ export const dbMethods = [
     detail: '() - Drop all roles in the database',
     snippet: 'dropAllRoles()$0',
   },
+  // Administration
+  {
+    label: 'serverStatus',
+    detail: '(options?) - Server status and metrics',
+    snippet: 'serverStatus()$0',
+  },
+  {
+    label: 'hostInfo',
+    detail: '() - Host system information',
+    snippet: 'hostInfo()$0',
+  },
+  {
+    label: 'hello',
+    detail: '() - Describes the role of the connected member',
+    snippet: 'hello()$0',
+  },
+  {
+    label: 'currentOp',
+    detail: '(filter?) - List in-progress operations',
+    snippet: 'currentOp()$0',
+  },
+  {
+    label: 'killOp',
+    detail: '(opid) - Terminate an operation',
+    snippet: 'killOp($1)$0',
+  },
+  {
+    label: 'setProfilingLevel',
+    detail: '(level, slowms?) - Configure the database profiler',
+    snippet: 'setProfilingLevel($1)$0',
+  },
+  {
+    label: 'getProfilingStatus',
+    detail: '() - Current profiler settings',
+    snippet: 'getProfilingStatus()$0',
+  },
+  {
+    label: 'getLogComponents',
+    detail: '() - Log verbosity by component',
+    snippet: 'getLogComponents()$0',
+  },
+  {
+    label: 'setLogLevel',
+    detail: '(level, component?) - Set log verbosity',
+    snippet: 'setLogLevel($1)$0',
+  },
+  {
+    label: 'fsyncLock',
+    detail: '() - Flush writes and lock the server against writes',
+    snippet: 'fsyncLock()$0',
+  },
+  {
+    label: 'fsyncUnlock',
+    detail: '() - Release an fsyncLock',
+    snippet: 'fsyncUnlock()$0',
+  },
+  {
+    label: 'getReplicationInfo',
+    detail: '() - Oplog size and time window',
+    snippet: 'getReplicationInfo()$0',
+  },
+  {
+    label: 'printCollectionStats',
+    detail: '(scale?) - Print statistics for every collection',
+    snippet: 'printCollectionStats()$0',
+  },
+  {
+    label: 'rotateCertificates',
+    detail: '(message?) - Reload TLS certificates',
+    snippet: 'rotateCertificates()$0',
+  },
 ]
@@ -4,6 +4,7 @@ import * as connectionsProxy from 'wailsjs/go/api/ConnectionsProxy'
 import * as collectionsProxy from 'wailsjs/go/api/CollectionsProxy'
 import * as databasesProxy from 'wailsjs/go/api/DatabasesProxy'
 import { useTabStore } from '@/features/tabs/tabs.ts'
+import { useTerminalStore } from '@/features/terminal/terminalStore.ts'
 import { useSettingsStore } from '@/features/settings/settingsStore.ts'
 import { useNotifier } from '@/utils/dialog.ts'
 import { i18nGlobal } from '@/i18n'
@@ -445,6 +446,7 @@ export const useDataBrowserStore = defineStore('browser', {
       this.serverTreeStates = {}
       const tabStore = useTabStore()
       tabStore.removeAllTabs()
+      useTerminalStore().closeAll()
     },
     async disconnect(serverId: string) {
       const server = this.connections.find((x) => x.serverID === serverId)
@@ -467,6 +469,8 @@ export const useDataBrowserStore = defineStore('browser', {
       this.connections = this.connections.filter((x) => x.serverID !== serverId)
       delete this.serverTreeStates[serverId]
       useTabStore().removeTabById(serverId)
+      // A shell has a connection of its own, so it would outlive the server's.
+      useTerminalStore().closeServer(serverId)
     },
     async refreshConnectedServers(force: boolean = false) {
       if (!force && !isEmpty(this.connections)) {
@@ -30,6 +30,16 @@ export function useDataTreeContextMenu() {
       key: 'serverStatus',
       disabled: false,
     },
+    {
+      label: t('dataBrowser.contextMenu.openShell'),
+      key: 'openShell',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.restore'),
+      key: 'restore',
+      disabled: false,
+    },
     { type: 'divider', key: 'd1', label: '' },
     {
       label: t('dataBrowser.contextMenu.disconnect'),
@@ -49,6 +59,21 @@ export function useDataTreeContextMenu() {
       key: 'statistics',
       disabled: false,
     },
+    {
+      label: t('dataBrowser.contextMenu.backUp'),
+      key: 'backUp',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.restore'),
+      key: 'restore',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.copyToServer'),
+      key: 'copyToServer',
+      disabled: false,
+    },
     {
       label: t('dataBrowser.contextMenu.refresh'),
       key: 'refresh',
@@ -104,6 +129,31 @@ export function useDataTreeContextMenu() {
       key: 'inspectSchema',
       disabled: false,
     },
+    {
+      label: t('dataBrowser.contextMenu.importData'),
+      key: 'importData',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.exportData'),
+      key: 'exportData',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.backUp'),
+      key: 'backUp',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.copyToServer'),
+      key: 'copyToServer',
+      disabled: false,
+    },
+    {
+      label: t('dataBrowser.contextMenu.generateData'),
+      key: 'generateData',
+      disabled: false,
+    },
     {
       label: t('dataBrowser.contextMenu.statistics'),
       key: 'statistics',
@@ -138,8 +188,10 @@ export function useDataTreeContextMenu() {
           : viewsFolderMenuOptions.value
       }
       case DataNodeType.Collection:
-      case DataNodeType.View:
         return collectionMenuOptions.value
+      case DataNodeType.View:
+        // Views are read-only, so there is nothing to import or generate into.
+        return collectionMenuOptions.value.filter((o) => o.key !== 'importData' && o.key !== 'generateData')
       default:
         return []
     }
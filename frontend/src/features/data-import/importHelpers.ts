@@ -0,0 +1,89 @@
+import { type models } from 'wailsjs/go/models.ts'
+
+export type ImportFormat = 'csv' | 'json' | 'ndjson'
+export type ImportMode = 'insert' | 'upsert'
+
+export const columnTypes = [
+  'auto',
+  'string',
+  'number',
+  'boolean',
+  'date',
+  'objectId',
+  'ejson',
+  'skip',
+] as const
+
+export type ColumnType = (typeof columnTypes)[number]
+
+/** Guesses the format from the file's extension, falling back to JSON. */
+export function formatFromPath(path: string): ImportFormat {
+  const ext = path.toLowerCase().split('.').pop() ?? ''
+  switch (ext) {
+    case 'csv':
+    case 'tsv':
+    case 'txt':
+      return 'csv'
+    case 'ndjson':
+    case 'jsonl':
+      return 'ndjson'
+    default:
+      return 'json'
+  }
+}
+
+/** Maps each CSV column to the field its header names. */
+export function columnsFromHeaders(headers: string[]): models.ImportColumn[] {
+  return headers.map((h) => ({ field: h, type: 'auto' }))
+}
+
+/** Splits the comma-separated upsert key list, dropping blanks. */
+export function parseUpsertKeys(text: string): string[] {
+  return text
+    .split(',')
+    .map((k) => k.trim())
+    .filter((k) => k !== '')
+}
+
+/** How far through the file an import is, from 0 to 100. */
+export function progressPercent(progress: models.ImportProgress | null): number {
+  if (!progress || progress.totalBytes <= 0) {
+    return 0
+  }
+  return Math.min(100, Math.round((progress.bytesRead / progress.totalBytes) * 100))
+}
+
+export interface ImportRequestOptions {
+  importId: string
+  serverId: string
+  database: string
+  collection: string
+  path: string
+  format: ImportFormat
+  separator: string
+  hasHeader: boolean
+  ignoreBlanks: boolean
+  columns: models.ImportColumn[]
+  mode: ImportMode
+  upsertKeys: string
+}
+
+export function buildImportRequest(opts: ImportRequestOptions): models.ImportRequest {
+  return {
+    importId: opts.importId,
+    serverId: opts.serverId,
+    database: opts.database,
+    collection: opts.collection,
+    path: opts.path,
+    format: opts.format,
+    csv: {
+      separator: opts.separator,
+      hasHeader: opts.hasHeader,
+      ignoreBlanks: opts.ignoreBlanks,
+      columns: opts.format === 'csv' ? opts.columns : [],
+    },
+    mode: opts.mode,
+    upsertKeys: opts.mode === 'upsert' ? parseUpsertKeys(opts.upsertKeys) : [],
+    batchSize: 0,
+  }
+}
@@ -0,0 +1,85 @@
+import { describe, expect, test } from 'vitest'
+import {
+  buildImportRequest,
+  columnsFromHeaders,
+  formatFromPath,
+  parseUpsertKeys,
+  progressPercent,
+  type ImportRequestOptions,
+} from '../importHelpers'
+
+describe('formatFromPath', () => {
+  test('reads the extension', () => {
+    expect(formatFromPath('/data/people.CSV')).toBe('csv')
+    expect(formatFromPath('/data/people.tsv')).toBe('csv')
+    expect(formatFromPath('/data/events.ndjson')).toBe('ndjson')
+    expect(formatFromPath('/data/events.jsonl')).toBe('ndjson')
+    expect(formatFromPath('/data/dump.json')).toBe('json')
+  })
+  test('falls back to json', () => {
+    expect(formatFromPath('/data/export')).toBe('json')
+  })
+})
+
+describe('columnsFromHeaders', () => {
+  test('maps each header to its own field', () => {
+    expect(columnsFromHeaders(['name', 'address.city'])).toEqual([
+      { field: 'name', type: 'auto' },
+      { field: 'address.city', type: 'auto' },
+    ])
+  })
+})
+
+describe('parseUpsertKeys', () => {
+  test('splits and trims', () => {
+    expect(parseUpsertKeys(' sku, region ,, ')).toEqual(['sku', 'region'])
+  })
+})
+
+describe('progressPercent', () => {
+  test('is zero without progress', () => {
+    expect(progressPercent(null)).toBe(0)
+  })
+  test('rounds bytes read to a percentage', () => {
+    expect(
+      progressPercent({
+        importId: 'a',
+        rows: 1,
+        inserted: 1,
+        updated: 0,
+        rejected: 0,
+        bytesRead: 333,
+        totalBytes: 1000,
+      }),
+    ).toBe(33)
+  })
+})
+
+describe('buildImportRequest', () => {
+  const base: ImportRequestOptions = {
+    importId: 'imp-1',
+    serverId: 'srv',
+    database: 'shop',
+    collection: 'orders',
+    path: '/tmp/orders.json',
+    format: 'json',
+    separator: ',',
+    hasHeader: true,
+    ignoreBlanks: false,
+    columns: [{ field: 'a', type: 'number' }],
+    mode: 'insert',
+    upsertKeys: 'sku',
+  }
+
+  test('leaves csv columns and upsert keys out when unused', () => {
+    const req = buildImportRequest(base)
+    expect(req.csv.columns).toEqual([])
+    expect(req.upsertKeys).toEqual([])
+  })
+
+  test('passes csv columns and upsert keys through', () => {
+    const req = buildImportRequest({ ...base, format: 'csv', mode: 'upsert' })
+    expect(req.csv.columns).toEqual([{ field: 'a', type: 'number' }])
+    expect(req.upsertKeys).toEqual(['sku'])
+  })
+})
@@ -0,0 +1,123 @@
+import { type models } from 'wailsjs/go/models.ts'
+
+export type GenerateOutput = 'insert' | 'ndjson'
+
+export type OverrideKind = 'constant' | 'choice' | 'range' | 'sequence' | 'fake' | 'omit'
+
+export const overrideKinds: OverrideKind[] = [
+  'constant',
+  'choice',
+  'range',
+  'sequence',
+  'fake',
+  'omit',
+]
+
+export const DefaultCount = 1000
+export const DefaultSampleSize = 1000
+
+/** One field override, as the dialog edits it. */
+export interface OverrideRow {
+  id: string
+  path: string
+  kind: OverrideKind
+  // values holds one Extended JSON value per line: one for a constant, any
+  // number for a choice.
+  values: string
+  min: string
+  max: string
+  fake: string
+}
+
+export function newOverride(): OverrideRow {
+  return {
+    id: crypto.randomUUID(),
+    path: '',
+    kind: 'constant',
+    values: '',
+    min: '',
+    max: '',
+    fake: 'name',
+  }
+}
+
+/** The override the backend expects, carrying only what its kind uses. */
+export function buildOverride(row: OverrideRow): models.GenerateOverride {
+  const override: models.GenerateOverride = { path: row.path.trim(), kind: row.kind }
+  switch (row.kind) {
+    case 'constant':
+      override.values = [row.values.trim()]
+      break
+    case 'choice':
+      override.values = row.values
+        .split('\n')
+        .map((v) => v.trim())
+        .filter((v) => v !== '')
+      break
+    case 'range':
+      override.min = row.min.trim()
+      override.max = row.max.trim()
+      break
+    case 'sequence':
+      if (row.min.trim() !== '') {
+        override.min = row.min.trim()
+      }
+      break
+    case 'fake':
+      override.fake = row.fake
+      break
+  }
+  return override
+}
+
+/** The collection offered for the documents: the sampled one's name, marked. */
+export function defaultTargetName(collection: string): string {
+  return `${collection}_generated`
+}
+
+export function defaultFileName(collection: string): string {
+  return `${collection}-generated.ndjson`
+}
+
+export interface GenerateRequestOptions {
+  generateId: string
+  serverId: string
+  database: string
+  collection: string
+  sampleSize: number | null
+  count: number | null
+  overrides: OverrideRow[]
+  seed: number | null
+  output: GenerateOutput
+  targetServerId: string
+  targetDatabase: string
+  targetCollection: string
+  path: string
+}
+
+export function buildGenerateRequest(opts: GenerateRequestOptions): models.GenerateRequest {
+  const insert = opts.output === 'insert'
+  return {
+    generateId: opts.generateId,
+    serverId: opts.serverId,
+    database: opts.database,
+    collection: opts.collection,
+    sampleSize: opts.sampleSize ?? 0,
+    count: opts.count ?? 0,
+    overrides: opts.overrides.filter((row) => row.path.trim() !== '').map(buildOverride),
+    seed: opts.seed ?? 0,
+    output: opts.output,
+    targetServerId: insert ? opts.targetServerId : '',
+    targetDatabase: insert ? opts.targetDatabase.trim() : '',
+    targetCollection: insert ? opts.targetCollection.trim() : '',
+    path: insert ? '' : opts.path,
+  }
+}
+
+/** How far through a generation is, from 0 to 100. */
+export function progressPercent(progress: models.GenerateProgress | null): number {
+  if (!progress || progress.total <= 0) {
+    return 0
+  }
+  return Math.min(100, Math.round((progress.generated / progress.total) * 100))
+}
@@ -0,0 +1,103 @@
+import { describe, expect, test } from 'vitest'
+import {
+  buildGenerateRequest,
+  buildOverride,
+  defaultFileName,
+  defaultTargetName,
+  newOverride,
+  progressPercent,
+} from '../generateHelpers'
+
+describe('buildOverride', () => {
+  const row = { ...newOverride(), path: ' status ' }
+
+  test('sends a constant as its one value', () => {
+    expect(buildOverride({ ...row, values: ' "active" ' })).toEqual({
+      path: 'status',
+      kind: 'constant',
+      values: ['"active"'],
+    })
+  })
+  test('sends a choice as a value per line, skipping blank ones', () => {
+    const values = buildOverride({ ...row, kind: 'choice', values: '"a"\n\n 2 \n' }).values
+    expect(values).toEqual(['"a"', '2'])
+  })
+  test('sends only the bounds a range or sequence uses', () => {
+    expect(buildOverride({ ...row, kind: 'range', min: ' 1 ', max: '5', values: 'x' })).toEqual({
+      path: 'status',
+      kind: 'range',
+      min: '1',
+      max: '5',
+    })
+    expect(buildOverride({ ...row, kind: 'sequence', max: '5' })).toEqual({
+      path: 'status',
+      kind: 'sequence',
+    })
+    expect(buildOverride({ ...row, kind: 'sequence', min: '100' }).min).toBe('100')
+  })
+  test('sends the kind of fake value, and nothing for omit', () => {
+    expect(buildOverride({ ...row, kind: 'fake', fake: 'email' }).fake).toBe('email')
+    expect(buildOverride({ ...row, kind: 'omit', values: '1' })).toEqual({
+      path: 'status',
+      kind: 'omit',
+    })
+  })
+})
+
+describe('buildGenerateRequest', () => {
+  const base = {
+    generateId: 'g1',
+    serverId: 'a',
+    database: 'shop',
+    collection: 'orders',
+    sampleSize: null,
+    count: 500,
+    overrides: [],
+    seed: null,
+    output: 'insert' as const,
+    targetServerId: 'b',
+    targetDatabase: ' test ',
+    targetCollection: ' orders_generated ',
+    path: '/tmp/orders.ndjson',
+  }
+
+  test('sends the target collection when inserting', () => {
+    const req = buildGenerateRequest(base)
+    expect(req.targetDatabase).toBe('test')
+    expect(req.targetCollection).toBe('orders_generated')
+    expect(req.path).toBe('')
+    expect(req.sampleSize).toBe(0)
+    expect(req.seed).toBe(0)
+  })
+  test('sends the file when writing NDJSON', () => {
+    const req = buildGenerateRequest({ ...base, output: 'ndjson', seed: 7 })
+    expect(req.path).toBe('/tmp/orders.ndjson')
+    expect(req.targetServerId).toBe('')
+    expect(req.targetCollection).toBe('')
+    expect(req.seed).toBe(7)
+  })
+  test('leaves out overrides without a path', () => {
+    const overrides = [newOverride(), { ...newOverride(), path: 'age', kind: 'omit' as const }]
+    expect(buildGenerateRequest({ ...base, overrides }).overrides).toEqual([
+      { path: 'age', kind: 'omit' },
+    ])
+  })
+})
+
+describe('default names', () => {
+  test('mark the sampled collection', () => {
+    expect(defaultTargetName('orders')).toBe('orders_generated')
+    expect(defaultFileName('orders')).toBe('orders-generated.ndjson')
+  })
+})
+
+describe('progressPercent', () => {
+  test('is zero before any progress', () => {
+    expect(progressPercent(null)).toBe(0)
+    expect(progressPercent({ generateId: 'g', generated: 0, total: 0 })).toBe(0)
+  })
+  test('is the share generated, never past 100', () => {
+    expect(progressPercent({ generateId: 'g', generated: 250, total: 1000 })).toBe(25)
+    expect(progressPercent({ generateId: 'g', generated: 12, total: 10 })).toBe(100)
+  })
+})
@@ -0,0 +1,67 @@
+import { type models } from 'wailsjs/go/models.ts'
+
+export type MaskingStrategy = 'hash' | 'redact' | 'shuffle' | 'fake' | 'jitter' | 'keep'
+export type FakeKind = 'name' | 'email' | 'phone' | 'address'
+
+export const strategies: MaskingStrategy[] = ['hash', 'redact', 'shuffle', 'fake', 'jitter', 'keep']
+export const fakeKinds: FakeKind[] = ['name', 'email', 'phone', 'address']
+
+/** DefaultJitterDays is how far a new jitter rule moves dates. */
+export const DefaultJitterDays = 30
+
+export function newRule(): models.MaskingRule {
+  return { path: '', strategy: 'hash' }
+}
+
+export function newProfile(name: string): models.MaskingProfile {
+  return { name, key: '', rules: [newRule()] }
+}
+
+/**
+ * Gives a rule the settings its strategy needs and drops those it doesn't,
+ * so a rule switched from fake to hash isn't saved with a kind.
+ */
+export function setStrategy(rule: models.MaskingRule, strategy: MaskingStrategy) {
+  rule.strategy = strategy
+  if (strategy === 'fake') {
+    rule.kind = rule.kind || 'name'
+  } else {
+    delete rule.kind
+  }
+  if (strategy === 'jitter') {
+    rule.days = rule.days || DefaultJitterDays
+  } else {
+    delete rule.days
+  }
+}
+
+/** uniqueName adds a number to base until no profile has it. */
+export function uniqueName(base: string, profiles: models.MaskingProfile[]): string {
+  const taken = new Set(profiles.map((p) => p.name))
+  let name = base
+  for (let n = 2; taken.has(name); n++) {
+    name = `${base} ${n}`
+  }
+  return name
+}
+
+/** generateKey makes a random 256-bit key, as hex. */
+export function generateKey(): string {
+  const bytes = crypto.getRandomValues(new Uint8Array(32))
+  return Array.from(bytes, (b) => b.toString(16).padStart(2, '0')).join('')
+}
+
+export function profileOptions(profiles: models.MaskingProfile[] | null | undefined) {
+  return (profiles ?? []).map((p) => ({ label: p.name, value: p.name }))
+}
+
+/** Issues found checking one collection against a profile. */
+export interface CollectionIssues {
+  collection: string
+  issues: models.MaskingIssue[]
+}
+
+/** issueCount totals the issues found across collections. */
+export function issueCount(found: CollectionIssues[]): number {
+  return found.reduce((n, c) => n + c.issues.length, 0)
+}
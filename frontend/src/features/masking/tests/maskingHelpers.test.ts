@@ -0,0 +1,70 @@
+import { describe, expect, it } from 'vitest'
+import { type models } from 'wailsjs/go/models.ts'
+import {
+  DefaultJitterDays,
+  generateKey,
+  issueCount,
+  newProfile,
+  profileOptions,
+  setStrategy,
+  uniqueName,
+} from '../maskingHelpers'
+
+describe('setStrategy', () => {
+  it('adds a kind for fake and days for jitter', () => {
+    const rule: models.MaskingRule = { path: 'email', strategy: 'hash' }
+    setStrategy(rule, 'fake')
+    expect(rule).toEqual({ path: 'email', strategy: 'fake', kind: 'name' })
+    setStrategy(rule, 'jitter')
+    expect(rule).toEqual({ path: 'email', strategy: 'jitter', days: DefaultJitterDays })
+  })
+
+  it('drops settings the new strategy does not use', () => {
+    const rule: models.MaskingRule = { path: 'born', strategy: 'jitter', days: 5 }
+    setStrategy(rule, 'redact')
+    expect(rule).toEqual({ path: 'born', strategy: 'redact' })
+  })
+
+  it('keeps a kind already chosen', () => {
+    const rule: models.MaskingRule = { path: 'email', strategy: 'fake', kind: 'email' }
+    setStrategy(rule, 'fake')
+    expect(rule.kind).toBe('email')
+  })
+})
+
+describe('uniqueName', () => {
+  it('numbers names already taken', () => {
+    const profiles = [newProfile('Profile'), newProfile('Profile 2')]
+    expect(uniqueName('Profile', profiles)).toBe('Profile 3')
+    expect(uniqueName('Other', profiles)).toBe('Other')
+  })
+})
+
+describe('generateKey', () => {
+  it('makes 64 hex digits, different each time', () => {
+    const key = generateKey()
+    expect(key).toMatch(/^[0-9a-f]{64}$/)
+    expect(generateKey()).not.toBe(key)
+  })
+})
+
+describe('profileOptions', () => {
+  it('lists profiles by name', () => {
+    expect(profileOptions([newProfile('customers')])).toEqual([
+      { label: 'customers', value: 'customers' },
+    ])
+    expect(profileOptions(null)).toEqual([])
+  })
+})
+
+describe('issueCount', () => {
+  it('totals issues across collections', () => {
+    const issue = { rule: 0, path: 'a', message: 'not found' }
+    expect(
+      issueCount([
+        { collection: 'a', issues: [issue, issue] },
+        { collection: 'b', issues: [issue] },
+      ]),
+    ).toBe(3)
+  })
+})
@@ -6,6 +6,8 @@ import { useTabStore } from '@/features/tabs/tabs'
 import { useNotifier } from '@/utils/dialog'
 import { useSettingsStore } from '@/features/settings/settingsStore'
 import { i18nGlobal } from '@/i18n'
+import { resolveRawValue } from '@/features/results-document-tree/resolveRawValue'
+import { getTruncation, replaceAtPath } from '@/features/results-document-tree/truncatedValues'
 
 export type PageContext = models.PageContext
 
@@ -67,6 +69,47 @@ function makeId(): string {
   return `${Date.now()}-${Math.random().toString(36).slice(2)}`
 }
 
+/** A running script's latest progress(done, total, message) report. */
+export interface ScriptProgress {
+  done: number
+  /** 0 when the script didn't say how much work there is */
+  total: number
+  message: string
+}
+
+/** A prompt() or confirm() question a running script is waiting on. */
+export interface ScriptPrompt {
+  promptID: string
+  kind: 'prompt' | 'confirm'
+  message: string
+  default?: string
+}
+
+/** Payload of the backend's query-output event. */
+export interface QueryOutputEvent {
+  serverID: string
+  queryID: string
+  lines: string[]
+}
+
+/**
+ * How many printed lines a running query's tab keeps. The finished result
+ * carries the whole output; the live view only needs the recent tail.
+ */
+const MAX_LIVE_OUTPUT_LINES = 1000
+
+/** Payload of the backend's query-progress event. */
+export interface QueryProgressEvent extends ScriptProgress {
+  serverID: string
+  queryID: string
+}
+
+/** Payload of the backend's query-prompt event. */
+export interface QueryPromptEvent extends ScriptPrompt {
+  serverID: string
+  queryID: string
+}
+
 export interface QueryState {
   loading: boolean
   cancelled: boolean
@@ -98,6 +141,12 @@ export interface QueryState {
   totalEstimated: boolean
   loadingPage: boolean
   loadingCount: boolean
+  /** What the running query has printed so far (the most recent lines) */
+  liveOutput: string[]
+  /** Progress reported by the running script; null until it reports any */
+  progress: ScriptProgress | null
+  /** The question the running script is waiting on, if any */
+  pendingPrompt: ScriptPrompt | null
 }
 
 interface QueryStoreState {
@@ -135,6 +184,9 @@ function createQueryState(database: string): QueryState {
     totalEstimated: false,
     loadingPage: false,
     loadingCount: false,
+    liveOutput: [],
+    progress: null,
+    pendingPrompt: null,
   }
 }
 
@@ -302,6 +354,9 @@ export const useQueryStore = defineStore('query', {
       state.totalEstimated = false
       state.loadingPage = false
       state.loadingCount = false
+      state.liveOutput = []
+      state.progress = null
+      state.pendingPrompt = null
       this.appendMessage(queryId, {
         level: 'info',
         text: i18nGlobal.t('query.messages.executing'),
@@ -390,11 +445,67 @@ export const useQueryStore = defineStore('query', {
         this.appendMessage(queryId, { level: 'error', text: String(e), query: queryPayload })
         state.activeResultTab = 'messages'
       } finally {
+        if (state.executionId === thisExecution) {
+          state.liveOutput = []
+          state.progress = null
+          state.pendingPrompt = null
+        }
         state.loading = false
         state.runStartedAt = null
       }
     },
 
+    /** Appends a query-output event's lines to the tab that ran the query. */
+    appendOutput(event: QueryOutputEvent) {
+      const state = this.queries[event.queryID]
+      if (!state || !state.loading) {
+        return
+      }
+      const lines = state.liveOutput.concat(event.lines)
+      state.liveOutput =
+        lines.length > MAX_LIVE_OUTPUT_LINES ? lines.slice(-MAX_LIVE_OUTPUT_LINES) : lines
+    },
+
+    /** Records a query-progress event for the tab that ran the script. */
+    setProgress(event: QueryProgressEvent) {
+      const state = this.queries[event.queryID]
+      if (!state || !state.loading) {
+        return
+      }
+      state.progress = { done: event.done, total: event.total, message: event.message }
+    },
+
+    /** Records a query-prompt event for the tab that ran the script. */
+    showPrompt(event: QueryPromptEvent) {
+      const state = this.queries[event.queryID]
+      if (!state || !state.loading) {
+        return
+      }
+      state.pendingPrompt = {
+        promptID: event.promptID,
+        kind: event.kind,
+        message: event.message,
+        default: event.default,
+      }
+    },
+
+    /**
+     * Sends the user's reply to the script waiting on the tab's prompt. ok is
+     * false when the user dismissed it.
+     */
+    async answerPrompt(queryId: string, value: string, ok: boolean) {
+      const state = this.getQueryState(queryId)
+      const prompt = state.pendingPrompt
+      if (!prompt) {
+        return
+      }
+      state.pendingPrompt = null
+      const result = await shellProxy.AnswerPrompt(prompt.promptID, value, ok)
+      if (!result.isSuccess) {
+        useNotifier().error(translateError(result.errorCode, result.errorDetail))
+      }
+    },
+
     async fetchPage(queryId: string, page: number, pageSize: number) {
       const tabStore = useTabStore()
       const serverId = tabStore.currentTabId
@@ -454,6 +565,42 @@ export const useQueryStore = defineStore('query', {
       }
     },
 
+    /**
+     * Loads a value the backend left out of an oversized result document and
+     * puts it in place of its marker. rowKey is the result tree row showing
+     * the marker.
+     */
+    async loadTruncatedValue(queryId: string, rowKey: string) {
+      const tabStore = useTabStore()
+      const serverId = tabStore.currentTabId
+      if (!serverId) {
+        return
+      }
+      const state = this.getQueryState(queryId)
+      const pageContext = state.pageContext
+      const truncation = getTruncation(resolveRawValue(state.documents, rowKey))
+      const docIndex = Number(rowKey.match(/^__doc_(\d+)/)?.[1])
+      const doc = state.documents[docIndex] as Record<string, unknown> | undefined
+      if (!pageContext || !truncation || !doc) {
+        return
+      }
+      const result = await shellProxy.FetchValue(
+        serverId,
+        pageContext.database || state.selectedDatabase,
+        pageContext.collection,
+        JSON.stringify(doc._id),
+        truncation.path,
+      )
+      if (result.isSuccess && result.data) {
+        state.documents[docIndex] = replaceAtPath(doc, truncation.path, result.data.documents?.[0])
+        state._rawJsonCache = null
+      } else {
+        useNotifier().error(translateError(result.errorCode, result.errorDetail), {
+          title: i18nGlobal.t('errorTitles.loadValue'),
+        })
+      }
+    },
+
     async cancelQuery(queryId: string) {
       const tabStore = useTabStore()
       const serverId = tabStore.currentTabId
@@ -465,6 +612,9 @@ export const useQueryStore = defineStore('query', {
       state.cancelled = true
       state.loading = false
       state.error = ''
+      state.liveOutput = []
+      state.progress = null
+      state.pendingPrompt = null
       this.appendMessage(queryId, {
         level: 'warning',
         text: i18nGlobal.t('errors.query_cancelled'),
@@ -0,0 +1,94 @@
+import { setActivePinia, createPinia } from 'pinia'
+import { beforeEach, describe, expect, test, vi } from 'vitest'
+
+vi.mock('wailsjs/go/api/ShellProxy', () => ({
+  ExecuteQuery: vi.fn(),
+  CancelQuery: vi.fn(async () => undefined),
+  AnswerPrompt: vi.fn(async () => ({ isSuccess: true })),
+  FetchPage: vi.fn(),
+  CountForPage: vi.fn(),
+  CheckMongosh: vi.fn(async () => ({ isSuccess: true, data: true })),
+}))
+
+vi.mock('@/utils/dialog', () => ({
+  useNotifier: () => ({ info: vi.fn(), success: vi.fn(), error: vi.fn(), warning: vi.fn() }),
+  useDialoger: () => ({}),
+  useMessager: () => ({}),
+}))
+
+import * as shellProxy from 'wailsjs/go/api/ShellProxy'
+import { useQueryStore } from '@/features/queries/queryStore'
+
+const QUERY_ID = 'q-1'
+
+describe('queryStore script output, progress and prompts', () => {
+  beforeEach(() => {
+    setActivePinia(createPinia())
+    ;(shellProxy.AnswerPrompt as ReturnType<typeof vi.fn>).mockClear()
+  })
+
+  test('progress is recorded only for a running query', () => {
+    const store = useQueryStore()
+    const state = store.getQueryState(QUERY_ID)
+    const event = { serverID: 's', queryID: QUERY_ID, done: 3, total: 10, message: 'copying' }
+
+    store.setProgress(event)
+    expect(state.progress).toBeNull()
+
+    state.loading = true
+    store.setProgress(event)
+    expect(state.progress).toEqual({ done: 3, total: 10, message: 'copying' })
+  })
+
+  test('output lines accumulate while the query runs', () => {
+    const store = useQueryStore()
+    const state = store.getQueryState(QUERY_ID)
+    state.loading = true
+
+    store.appendOutput({ serverID: 's', queryID: QUERY_ID, lines: ['one', 'two'] })
+    store.appendOutput({ serverID: 's', queryID: QUERY_ID, lines: ['three'] })
+    expect(state.liveOutput).toEqual(['one', 'two', 'three'])
+  })
+
+  test('live output keeps only the most recent lines', () => {
+    const store = useQueryStore()
+    const state = store.getQueryState(QUERY_ID)
+    state.loading = true
+
+    const lines = Array.from({ length: 1500 }, (_, i) => `line ${i}`)
+    store.appendOutput({ serverID: 's', queryID: QUERY_ID, lines })
+    expect(state.liveOutput).toHaveLength(1000)
+    expect(state.liveOutput[999]).toBe('line 1499')
+  })
+
+  test('events for unknown tabs are ignored', () => {
+    const store = useQueryStore()
+    store.setProgress({ serverID: 's', queryID: 'missing', done: 1, total: 2, message: '' })
+    expect(store.queries['missing']).toBeUndefined()
+  })
+
+  test('answering a prompt sends the reply and clears it', async () => {
+    const store = useQueryStore()
+    const state = store.getQueryState(QUERY_ID)
+    state.loading = true
+    store.showPrompt({
+      serverID: 's',
+      queryID: QUERY_ID,
+      promptID: 'p-1',
+      kind: 'prompt',
+      message: 'Batch size?',
+      default: '100',
+    })
+    expect(state.pendingPrompt?.promptID).toBe('p-1')
+
+    await store.answerPrompt(QUERY_ID, '250', true)
+    expect(shellProxy.AnswerPrompt).toHaveBeenCalledWith('p-1', '250', true)
+    expect(state.pendingPrompt).toBeNull()
+  })
+
+  test('answering without a pending prompt does nothing', async () => {
+    const store = useQueryStore()
+    await store.answerPrompt(QUERY_ID, '', false)
+    expect(shellProxy.AnswerPrompt).not.toHaveBeenCalled()
+  })
+})
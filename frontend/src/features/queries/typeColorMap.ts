@@ -19,6 +19,7 @@ export const typeColorMapDark: Record<string, string> = {
   document: 'var(--n-td-text-color)',
   object: 'var(--n-td-text-color)',
   array: 'var(--n-td-text-color)',
+  truncated: '#808080',
 }
 
 export const typeColorMapLight: Record<string, string> = {
@@ -42,5 +43,6 @@ export const typeColorMapLight: Record<string, string> = {
   document: 'var(--n-td-text-color)',
   object: 'var(--n-td-text-color)',
   array: 'var(--n-td-text-color)',
+  truncated: '#6a6a6a',
 }
 
@@ -0,0 +1,152 @@
+import { describe, expect, it, beforeEach, vi } from 'vitest'
+import { setActivePinia, createPinia } from 'pinia'
+import { useHistoryStore } from './historyStore'
+
+vi.mock('wailsjs/go/api/HistoryProxy', () => ({
+  Search: vi.fn(),
+  Delete: vi.fn(),
+  Clear: vi.fn(),
+}))
+
+const notifierError = vi.fn()
+vi.mock('@/utils/dialog', () => ({
+  useNotifier: () => ({ error: notifierError }),
+}))
+
+vi.mock('@/i18n', () => ({
+  i18nGlobal: { t: (key: string) => key },
+}))
+
+const connect = vi.fn()
+const isConnected = vi.fn()
+vi.mock('@/features/data-browser/browserStore', () => ({
+  useDataBrowserStore: () => ({
+    connect,
+    isConnected,
+    connections: [{ serverID: 'prod', name: 'Production' }],
+  }),
+}))
+
+const upsertTab = vi.fn()
+const openQuery = vi.fn()
+vi.mock('@/features/tabs/tabs', () => ({
+  useTabStore: () => ({ upsertTab, openQuery }),
+}))
+
+const entry = {
+  id: 'h1',
+  serverID: 'prod',
+  database: 'shop',
+  query: 'db.orders.find()',
+  engine: 'builtin',
+  startedAt: 1000,
+  durationMs: 12,
+  outcome: 'succeeded',
+  resultCount: 3,
+}
+
+describe('historyStore', () => {
+  beforeEach(() => {
+    setActivePinia(createPinia())
+    vi.clearAllMocks()
+  })
+
+  it('searches with the filter the backend expects', async () => {
+    const { Search } = await import('wailsjs/go/api/HistoryProxy')
+    vi.mocked(Search).mockResolvedValue({ isSuccess: true, data: [entry] })
+    const store = useHistoryStore()
+
+    await store.search({ text: ' orders ', serverId: 'prod', range: [100, 200] })
+
+    expect(Search).toHaveBeenCalledWith({ text: 'orders', serverID: 'prod', from: 100, to: 200, limit: 0 })
+    expect(store.entries).toEqual([entry])
+    expect(store.loading).toBe(false)
+  })
+
+  it('searches every time without a range', async () => {
+    const { Search } = await import('wailsjs/go/api/HistoryProxy')
+    vi.mocked(Search).mockResolvedValue({ isSuccess: true, data: [] })
+    const store = useHistoryStore()
+
+    await store.search({ text: '', serverId: '', range: null })
+
+    expect(Search).toHaveBeenCalledWith({ text: '', serverID: '', from: 0, to: 0, limit: 0 })
+  })
+
+  it('reports a failed search and keeps the previous entries', async () => {
+    const { Search } = await import('wailsjs/go/api/HistoryProxy')
+    vi.mocked(Search).mockResolvedValue({ isSuccess: true, data: [entry] })
+    const store = useHistoryStore()
+    await store.search({ text: '', serverId: '', range: null })
+
+    vi.mocked(Search).mockResolvedValue({ isSuccess: false, data: [], errorCode: 'unknown_error' })
+    await store.search({ text: 'x', serverId: '', range: null })
+
+    expect(notifierError).toHaveBeenCalled()
+    expect(store.entries).toEqual([entry])
+  })
+
+  it('removes a deleted entry', async () => {
+    const { Search, Delete } = await import('wailsjs/go/api/HistoryProxy')
+    vi.mocked(Search).mockResolvedValue({ isSuccess: true, data: [entry] })
+    vi.mocked(Delete).mockResolvedValue({ isSuccess: true })
+    const store = useHistoryStore()
+    await store.search({ text: '', serverId: '', range: null })
+
+    await store.remove('h1')
+
+    expect(Delete).toHaveBeenCalledWith('h1')
+    expect(store.entries).toEqual([])
+  })
+
+  it('clears the history', async () => {
+    const { Search, Clear } = await import('wailsjs/go/api/HistoryProxy')
+    vi.mocked(Search).mockResolvedValue({ isSuccess: true, data: [entry] })
+    vi.mocked(Clear).mockResolvedValue({ isSuccess: true })
+    const store = useHistoryStore()
+    await store.search({ text: '', serverId: '', range: null })
+
+    await store.clear()
+
+    expect(store.entries).toEqual([])
+  })
+
+  it('replays into a new query tab on a connected server', async () => {
+    isConnected.mockReturnValue(true)
+    openQuery.mockReturnValue('query-1')
+    const store = useHistoryStore()
+
+    expect(await store.replay(entry)).toBe(true)
+
+    expect(connect).not.toHaveBeenCalled()
+    expect(upsertTab).toHaveBeenCalledWith({ serverId: 'prod', title: 'Production', forceSwitch: true, blank: false })
+    expect(openQuery).toHaveBeenCalledWith('prod', 'shop', 'db.orders.find()')
+  })
+
+  it('connects before replaying', async () => {
+    isConnected.mockReturnValue(false)
+    connect.mockResolvedValue({ success: true })
+    openQuery.mockReturnValue('query-1')
+    const store = useHistoryStore()
+
+    expect(await store.replay(entry)).toBe(true)
+    expect(connect).toHaveBeenCalledWith('prod')
+  })
+
+  it('does not replay when the connection fails', async () => {
+    isConnected.mockReturnValue(false)
+    connect.mockResolvedValue({ success: false })
+    const store = useHistoryStore()
+
+    expect(await store.replay(entry)).toBe(false)
+    expect(openQuery).not.toHaveBeenCalled()
+  })
+
+  it('does not replay a truncated query', async () => {
+    const store = useHistoryStore()
+
+    expect(await store.replay({ ...entry, queryTruncated: true })).toBe(false)
+    expect(isConnected).not.toHaveBeenCalled()
+    expect(openQuery).not.toHaveBeenCalled()
+  })
+})
@@ -0,0 +1,95 @@
+import { defineStore } from 'pinia'
+import { ref } from 'vue'
+import * as historyProxy from 'wailsjs/go/api/HistoryProxy'
+import { type models } from 'wailsjs/go/models'
+import { useDataBrowserStore } from '@/features/data-browser/browserStore'
+import { useTabStore } from '@/features/tabs/tabs'
+import { useNotifier } from '@/utils/dialog'
+import { i18nGlobal } from '@/i18n'
+
+export interface HistorySearch {
+  text: string
+  // Only entries run against this server; empty for every server.
+  serverId: string
+  // Bounds on when the query started, in Unix milliseconds, or null.
+  range: [number, number] | null
+}
+
+export const useHistoryStore = defineStore('queryHistory', () => {
+  const entries = ref<models.HistoryEntry[]>([])
+  const loading = ref(false)
+
+  function notifyError(title: string, result: { errorCode?: string; errorDetail?: string }) {
+    useNotifier().error(i18nGlobal.t(`errors.${result.errorCode}`), {
+      title: i18nGlobal.t(title),
+      detail: result.errorDetail,
+    })
+  }
+
+  async function search(params: HistorySearch) {
+    loading.value = true
+    try {
+      const result = await historyProxy.Search({
+        text: params.text.trim(),
+        serverID: params.serverId,
+        from: params.range?.[0] ?? 0,
+        to: params.range?.[1] ?? 0,
+        limit: 0,
+      })
+      if (!result.isSuccess) {
+        notifyError('errorTitles.loadHistory', result)
+        return
+      }
+      entries.value = result.data ?? []
+    } finally {
+      loading.value = false
+    }
+  }
+
+  async function remove(id: string) {
+    const result = await historyProxy.Delete(id)
+    if (!result.isSuccess) {
+      notifyError('errorTitles.deleteHistory', result)
+      return
+    }
+    entries.value = entries.value.filter((e) => e.id !== id)
+  }
+
+  async function clear() {
+    const result = await historyProxy.Clear()
+    if (!result.isSuccess) {
+      notifyError('errorTitles.deleteHistory', result)
+      return
+    }
+    entries.value = []
+  }
+
+  // Opens the entry's query in a new query tab on the server it ran against,
+  // connecting first when needed. The query is not run: replaying a write
+  // against the wrong server is too easy to do by accident.
+  async function replay(entry: models.HistoryEntry): Promise<boolean> {
+    if (entry.queryTruncated) {
+      return false
+    }
+
+    const browserStore = useDataBrowserStore()
+    const tabStore = useTabStore()
+    if (!browserStore.isConnected(entry.serverID)) {
+      const result = await browserStore.connect(entry.serverID)
+      if (!result.success) {
+        return false
+      }
+    }
+
+    const connection = browserStore.connections.find((c) => c.serverID === entry.serverID)
+    tabStore.upsertTab({
+      serverId: entry.serverID,
+      title: connection?.name || '',
+      forceSwitch: true,
+      blank: false,
+    })
+    return tabStore.openQuery(entry.serverID, entry.database, entry.query) !== undefined
+  }
+
+  return { entries, loading, search, remove, clear, replay }
+})
@@ -1,5 +1,7 @@
 import type { DocumentRow } from './types'
 import { i18nGlobal } from '@/i18n'
+import { formatBytes } from '@/utils/formatBytes'
+import { getTruncation, TRUNCATED_KEY } from './truncatedValues'
 
 const bsonKeyLookup: Record<string, string> = {
   $oid: 'objectId',
@@ -46,6 +48,9 @@ export function getTypeKey(val: unknown): string {
     return 'array'
   }
   if (typeof val === 'object') {
+    if (TRUNCATED_KEY in val) {
+      return 'truncated'
+    }
     for (const bsonKey in bsonKeyLookup) {
       if (bsonKey in val) {
         if (bsonKey === '$binary') {
@@ -81,6 +86,13 @@ export function getDisplayValue(val: unknown): string {
   }
   if (typeof val === 'object') {
     const obj = val as Record<string, unknown>
+    const truncation = getTruncation(obj)
+    if (truncation) {
+      const size = formatBytes(truncation.size)
+      return truncation.omitted > 0
+        ? i18nGlobal.t('query.truncatedRest', { count: truncation.omitted, size })
+        : i18nGlobal.t('query.truncatedValue', { type: truncation.type, size })
+    }
     if ('$oid' in obj) {
       return String(obj.$oid)
     }
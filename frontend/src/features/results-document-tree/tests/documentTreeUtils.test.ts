@@ -98,4 +98,20 @@ describe('buildTreeData', () => {
     expect(result[1].key).toBe('__doc_1')
     expect(result[2].key).toBe('__doc_2')
   })
+
+  test('shows truncation markers as leaves', () => {
+    const docs = [
+      {
+        _id: 1,
+        body: {
+          $vervetTruncated: { path: ['body'], type: 'string', size: { $numberLong: '2048' } },
+        },
+      },
+    ]
+    const result = buildTreeData(docs)
+    const body = result[0].children?.find((c) => c.field === 'body')
+    expect(body!.type).toBe('truncated')
+    expect(body!.value).toBe('query.truncatedValue:{"type":"string","size":"2.0 KiB"}')
+    expect(body!.children).toBeUndefined()
+  })
 })
@@ -0,0 +1,60 @@
+import { describe, it, expect } from 'vitest'
+import { getTruncation, replaceAtPath } from '../truncatedValues'
+
+describe('getTruncation', () => {
+  it('reads a value marker', () => {
+    const marker = {
+      $vervetTruncated: { path: ['a.b', 'c'], type: 'string', size: { $numberLong: '4101' } },
+    }
+    expect(getTruncation(marker)).toEqual({
+      path: ['a.b', 'c'],
+      type: 'string',
+      size: 4101,
+      omitted: 0,
+    })
+  })
+
+  it('reads a marker for the rest of a container', () => {
+    const marker = {
+      $vervetTruncated: {
+        path: ['items'],
+        type: 'array',
+        size: { $numberLong: '900' },
+        omitted: { $numberLong: '100' },
+      },
+    }
+    expect(getTruncation(marker)?.omitted).toBe(100)
+  })
+
+  it('returns null for ordinary values', () => {
+    expect(getTruncation('text')).toBeNull()
+    expect(getTruncation(null)).toBeNull()
+    expect(getTruncation([1, 2])).toBeNull()
+    expect(getTruncation({ $numberLong: '1' })).toBeNull()
+  })
+})
+
+describe('replaceAtPath', () => {
+  it('replaces a nested value in place', () => {
+    const doc = { _id: 1, outer: { list: ['a', { marker: true }] } }
+    const result = replaceAtPath(doc, ['outer', 'list', '1'], 'loaded')
+    expect(result).toBe(doc)
+    expect(doc.outer.list[1]).toBe('loaded')
+  })
+
+  it('replaces the whole document for an empty path', () => {
+    const loaded = { _id: 1, all: true }
+    expect(replaceAtPath({ _id: 1 }, [], loaded)).toBe(loaded)
+  })
+
+  it('leaves the document alone when the path is missing', () => {
+    const doc = { _id: 1 }
+    expect(replaceAtPath(doc, ['a', 'b'], 'x')).toEqual({ _id: 1 })
+  })
+
+  it('treats a dotted field name as one key', () => {
+    const doc = { _id: 1, 'a.b': { c: { marker: true } } }
+    replaceAtPath(doc, ['a.b', 'c'], 'loaded')
+    expect(doc['a.b'].c).toBe('loaded')
+  })
+})
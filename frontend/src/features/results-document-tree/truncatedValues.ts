@@ -0,0 +1,62 @@
+/** Key of the marker the backend puts in place of a value it left out of an oversized document. */
+export const TRUNCATED_KEY = '$vervetTruncated'
+
+export interface Truncation {
+  /**
+   * Keys leading to the value in the stored document, one per level since a
+   * field name may contain a dot; empty for the whole document
+   */
+  path: string[]
+  type: string
+  /** Size of the value in BSON, in bytes */
+  size: number
+  /** When set, the marker stands for this many trailing elements of the array or document at path */
+  omitted: number
+}
+
+function ejsonNumber(val: unknown): number {
+  if (typeof val === 'object' && val !== null) {
+    const obj = val as Record<string, unknown>
+    return Number(obj.$numberLong ?? obj.$numberInt ?? obj.$numberDouble ?? 0)
+  }
+  return Number(val ?? 0)
+}
+
+/** Returns the truncation a value is a marker for, or null when it is an ordinary value. */
+export function getTruncation(val: unknown): Truncation | null {
+  if (typeof val !== 'object' || val === null || Array.isArray(val) || !(TRUNCATED_KEY in val)) {
+    return null
+  }
+  const marker = (val as Record<string, unknown>)[TRUNCATED_KEY] as Record<string, unknown> | null
+  if (typeof marker !== 'object' || marker === null) {
+    return null
+  }
+  return {
+    path: Array.isArray(marker.path) ? marker.path.map(String) : [],
+    type: String(marker.type ?? ''),
+    size: ejsonNumber(marker.size),
+    omitted: ejsonNumber(marker.omitted),
+  }
+}
+
+/**
+ * Puts a loaded value in place of what was left out at path, returning the
+ * document to keep. An empty path replaces the whole document.
+ */
+export function replaceAtPath(doc: unknown, path: string[], value: unknown): unknown {
+  if (path.length === 0) {
+    return value
+  }
+  let parent: unknown = doc
+  for (const segment of path.slice(0, -1)) {
+    if (typeof parent !== 'object' || parent === null) {
+      return doc
+    }
+    parent = (parent as Record<string, unknown>)[segment]
+  }
+  if (typeof parent === 'object' && parent !== null) {
+    const container = parent as Record<string, unknown>
+    container[path[path.length - 1]] = value
+  }
+  return doc
+}
@@ -9,7 +9,10 @@ export interface CollectionContext {
   collectionName: string
 }
 
-export function useDocumentContextMenu(collectionContext: Ref<CollectionContext | undefined>) {
+export function useDocumentContextMenu(
+  collectionContext: Ref<CollectionContext | undefined>,
+  canLoadValues: Readonly<Ref<boolean>> = ref(false),
+) {
   const { t } = useI18n()
   const showMenu = ref(false)
   const menuX = ref(0)
@@ -62,10 +65,18 @@ export function useDocumentContextMenu(collectionContext: Ref<CollectionContext
       return options
     }
 
-    return [
+    const options: DropdownOption[] = []
+    if (targetRow.value.type === 'truncated' && canLoadValues.value) {
+      options.push(
+        { label: t('query.contextMenu.loadValue'), key: 'loadValue' },
+        { type: 'divider', key: 'd1' },
+      )
+    }
+    options.push(
       { label: t('query.contextMenu.copyValue'), key: 'copyValue' },
       { label: t('query.contextMenu.copyField'), key: 'copyField' },
-    ]
+    )
+    return options
   })
 
   return {
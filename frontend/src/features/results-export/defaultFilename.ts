@@ -1,8 +1,21 @@
-export type ExportFormat = 'csv' | 'json' | 'ndjson'
+import type { Compression } from './exportStore'
+
+export type ExportFormat = 'csv' | 'json' | 'ndjson' | 'xlsx' | 'markdown' | 'html' | 'sql'
+
+const extensions: Partial<Record<ExportFormat, string>> = {
+  markdown: 'md',
+}
+
+const compressedExtensions: Record<Compression, string> = {
+  none: '',
+  gzip: '.gz',
+  zstd: '.zst',
+}
 
 export function buildDefaultFilename(
   collection: string | undefined,
   format: ExportFormat,
+  compression: Compression = 'none',
 ): string {
   const base = collection && collection.length > 0 ? collection : 'vervet-export'
   const now = new Date()
@@ -10,5 +23,5 @@ export function buildDefaultFilename(
   const ts =
     `${now.getFullYear()}${pad(now.getMonth() + 1)}${pad(now.getDate())}` +
     `-${pad(now.getHours())}${pad(now.getMinutes())}${pad(now.getSeconds())}`
-  return `${base}-${ts}.${format}`
+  return `${base}-${ts}.${extensions[format] ?? format}${compressedExtensions[compression]}`
 }
@@ -1,7 +1,25 @@
+import type { models } from 'wailsjs/go/models'
 import type { ExportFormat } from './defaultFilename'
+import type { ArrayMode, Compression, SqlDialect, ValueMode } from './exportStore'
 
 export type SeparatorChoice = 'comma' | 'tab' | 'semicolon' | 'pipe' | 'custom'
 
+/** Whether to split the export into numbered files, and by what. */
+export type SplitMode = 'none' | 'documents' | 'megabytes'
+
+/**
+ * ServerExportOptions asks the backend to read the documents itself rather
+ * than write the EJSON the dialog holds: everything the pageContext's find
+ * matches, or the results of the pipeline when one is given.
+ */
+export interface ServerExportOptions {
+  serverId: string
+  database: string
+  pageContext?: models.PageContext
+  pipeline?: string
+  exportId: string
+}
+
 export interface ExportPayloadOptions {
   format: ExportFormat
   ejson: string
@@ -11,6 +29,18 @@ export interface ExportPayloadOptions {
   separator: string
   includeHeader: boolean
   utf8Bom: boolean
+  arrays?: ArrayMode
+  arrayDelimiter?: string
+  unwindPath?: string
+  values?: ValueMode
+  sqlDialect?: SqlDialect
+  // sqlTable names the table a SQL export creates; empty uses the collection.
+  sqlTable?: string
+  server?: ServerExportOptions
+  maskingProfile?: string
+  compression?: Compression
+  splitMode?: SplitMode
+  splitSize?: number | null
 }
 
 export interface ExportPayload {
@@ -22,6 +52,25 @@ export interface ExportPayload {
     separator: string
     includeHeader: boolean
     utf8Bom: boolean
+    arrays?: ArrayMode
+    arrayDelimiter?: string
+    unwindPath?: string
+    values?: ValueMode
+  }
+  sql?: {
+    dialect?: SqlDialect
+    table?: string
+  }
+  serverId?: string
+  database?: string
+  pageContext?: models.PageContext
+  pipeline?: string
+  exportId?: string
+  maskingProfile?: string
+  compression?: 'gzip' | 'zstd'
+  split?: {
+    documents?: number
+    megabytes?: number
   }
 }
 
@@ -56,17 +105,76 @@ export function separatorChoiceFromValue(separator: string): SeparatorChoice {
 }
 
 export function buildExportPayload(opts: ExportPayloadOptions): ExportPayload {
-  return {
+  const payload: ExportPayload = {
     format: opts.format,
-    ejson: opts.ejson,
+    ejson: opts.server ? '' : opts.ejson,
     collectionName: opts.collectionName ?? '',
     defaultFilename: opts.defaultFilename,
-    csv: opts.isCsv
-      ? {
-          separator: opts.separator,
-          includeHeader: opts.includeHeader,
-          utf8Bom: opts.utf8Bom,
-        }
-      : undefined,
+    csv: opts.isCsv ? buildCsvOptions(opts) : undefined,
+  }
+  if (opts.format === 'sql') {
+    payload.sql = buildSqlOptions(opts)
+  }
+  if (opts.maskingProfile) {
+    payload.maskingProfile = opts.maskingProfile
+  }
+  if (opts.compression && opts.compression !== 'none') {
+    payload.compression = opts.compression
+  }
+  if (opts.splitMode && opts.splitMode !== 'none' && opts.splitSize && opts.splitSize > 0) {
+    payload.split = { [opts.splitMode]: opts.splitSize }
+  }
+  if (opts.server) {
+    const pipeline = opts.server.pipeline?.trim() ?? ''
+    payload.serverId = opts.server.serverId
+    payload.database = opts.server.database
+    payload.exportId = opts.server.exportId
+    if (pipeline !== '') {
+      payload.pipeline = pipeline
+    } else {
+      payload.pageContext = opts.server.pageContext
+    }
+  }
+  return payload
+}
+
+function buildCsvOptions(opts: ExportPayloadOptions): ExportPayload['csv'] {
+  const csv: ExportPayload['csv'] = {
+    separator: opts.separator,
+    includeHeader: opts.includeHeader,
+    utf8Bom: opts.utf8Bom,
+  }
+  // Only send what differs from the backend's defaults.
+  if (opts.arrays && opts.arrays !== 'ejson') {
+    csv.arrays = opts.arrays
+  }
+  if (opts.arrays === 'join' && opts.arrayDelimiter) {
+    csv.arrayDelimiter = opts.arrayDelimiter
+  }
+  if (opts.arrays === 'unwind' && opts.unwindPath?.trim()) {
+    csv.unwindPath = opts.unwindPath.trim()
+  }
+  if (opts.values && opts.values !== 'relaxed') {
+    csv.values = opts.values
+  }
+  return csv
+}
+
+function buildSqlOptions(opts: ExportPayloadOptions): ExportPayload['sql'] {
+  const sql: ExportPayload['sql'] = {}
+  if (opts.sqlDialect && opts.sqlDialect !== 'postgres') {
+    sql.dialect = opts.sqlDialect
+  }
+  if (opts.sqlTable?.trim()) {
+    sql.table = opts.sqlTable.trim()
+  }
+  return sql
+}
+
+/** exportProgressPercent is 0 until the backend knows how many documents to expect. */
+export function exportProgressPercent(progress: models.ExportProgress | null): number {
+  if (!progress || progress.total <= 0) {
+    return 0
   }
+  return Math.min(100, Math.round((progress.documents / progress.total) * 100))
 }
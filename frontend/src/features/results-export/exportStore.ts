@@ -1,28 +1,65 @@
 import { defineStore } from 'pinia'
 import type { ExportFormat } from './defaultFilename'
 
+/**
+ * How arrays are written: one Extended JSON cell, a column per element, one
+ * joined cell, or a row per element.
+ */
+export type ArrayMode = 'ejson' | 'indexed' | 'join' | 'unwind'
+
+/** How ObjectIds, dates and other BSON types are written. */
+export type ValueMode = 'relaxed' | 'canonical' | 'plain'
+
+/** How the exported file is compressed. */
+export type Compression = 'none' | 'gzip' | 'zstd'
+
+/** The database a SQL export's statements are written for. */
+export type SqlDialect = 'postgres' | 'mysql' | 'sqlite' | 'sqlserver'
+
 export interface CsvOptions {
   separator: string
   includeHeader: boolean
   utf8Bom: boolean
+  arrays: ArrayMode
+  arrayDelimiter: string
+  unwindPath: string
+  values: ValueMode
 }
 
 interface State {
   format: ExportFormat
   csv: CsvOptions
+  sqlDialect: SqlDialect
+  compression: Compression
 }
 
 export const useExportStore = defineStore('export', {
   state: (): State => ({
     format: 'csv',
-    csv: { separator: ',', includeHeader: true, utf8Bom: false },
+    csv: {
+      separator: ',',
+      includeHeader: true,
+      utf8Bom: false,
+      arrays: 'ejson',
+      arrayDelimiter: ';',
+      unwindPath: '',
+      values: 'relaxed',
+    },
+    sqlDialect: 'postgres',
+    compression: 'none',
   }),
   actions: {
     setFormat(f: ExportFormat) {
       this.format = f
     },
-    setCsv(c: CsvOptions) {
-      this.csv = { ...c }
+    setCsv(c: Partial<CsvOptions>) {
+      this.csv = { ...this.csv, ...c }
+    },
+    setSqlDialect(d: SqlDialect) {
+      this.sqlDialect = d
+    },
+    setCompression(c: Compression) {
+      this.compression = c
     },
   },
 })
@@ -1,9 +1,15 @@
 import { beforeEach, describe, expect, test, vi } from 'vitest'
 import { createPinia, setActivePinia } from 'pinia'
-import { separatorFromChoice, buildExportPayload } from '../exportDialogHelpers'
+import {
+  separatorFromChoice,
+  buildExportPayload,
+  exportProgressPercent,
+} from '../exportDialogHelpers'
 
 vi.mock('wailsjs/go/api/ExportProxy', () => ({
   ExportResults: vi.fn().mockResolvedValue({ isSuccess: true, data: '/tmp/out.csv' }),
+  ExportToString: vi.fn().mockResolvedValue({ isSuccess: true, data: '| a |' }),
+  CancelExport: vi.fn().mockResolvedValue({ isSuccess: true }),
 }))
 
 vi.mock('@/utils/dialog.ts', () => ({
@@ -112,4 +118,219 @@ describe('buildExportPayload', () => {
     expect(payload.collectionName).toBe('')
     expect(payload.csv).toEqual({ separator: '\t', includeHeader: false, utf8Bom: true })
   })
+
+  test('sends the masking profile only when one is chosen', () => {
+    const opts = {
+      format: 'json' as const,
+      ejson: '[]',
+      collectionName: 'users',
+      defaultFilename: 'users.json',
+      isCsv: false,
+      separator: ',',
+      includeHeader: false,
+      utf8Bom: false,
+    }
+
+    expect(buildExportPayload({ ...opts, maskingProfile: 'customers' }).maskingProfile).toBe(
+      'customers',
+    )
+    expect(buildExportPayload({ ...opts, maskingProfile: '' })).not.toHaveProperty('maskingProfile')
+  })
+})
+
+describe('buildExportPayload for a server export', () => {
+  const base = {
+    format: 'csv' as const,
+    ejson: '[{"a":1}]',
+    collectionName: 'orders',
+    defaultFilename: 'orders.csv',
+    isCsv: true,
+    separator: ',',
+    includeHeader: true,
+    utf8Bom: false,
+  }
+
+  test('sends the page context instead of the loaded documents', () => {
+    const pageContext = { collection: 'orders', filter: { status: 'open' } }
+    const payload = buildExportPayload({
+      ...base,
+      server: { serverId: 'srv', database: 'shop', pageContext, exportId: 'exp-1' },
+    })
+
+    expect(payload.ejson).toBe('')
+    expect(payload.serverId).toBe('srv')
+    expect(payload.database).toBe('shop')
+    expect(payload.exportId).toBe('exp-1')
+    expect(payload.pageContext).toEqual(pageContext)
+    expect(payload.pipeline).toBeUndefined()
+  })
+
+  test('prefers a pipeline over the page context', () => {
+    const payload = buildExportPayload({
+      ...base,
+      server: {
+        serverId: 'srv',
+        database: 'shop',
+        pageContext: { collection: 'orders' },
+        pipeline: '  [{"$match":{}}]  ',
+        exportId: 'exp-2',
+      },
+    })
+
+    expect(payload.pipeline).toBe('[{"$match":{}}]')
+    expect(payload.pageContext).toBeUndefined()
+  })
+
+  test('ignores a blank pipeline', () => {
+    const payload = buildExportPayload({
+      ...base,
+      server: {
+        serverId: 'srv',
+        database: 'shop',
+        pageContext: { collection: 'orders' },
+        pipeline: ' ',
+        exportId: 'e',
+      },
+    })
+
+    expect(payload.pipeline).toBeUndefined()
+    expect(payload.pageContext).toEqual({ collection: 'orders' })
+  })
+})
+
+describe('exportProgressPercent', () => {
+  test('is zero while the total is unknown', () => {
+    expect(exportProgressPercent(null)).toBe(0)
+    expect(exportProgressPercent({ exportId: 'e', documents: 50, total: 0 })).toBe(0)
+  })
+
+  test('is the share of documents written', () => {
+    expect(exportProgressPercent({ exportId: 'e', documents: 250, total: 1000 })).toBe(25)
+  })
+
+  test('never passes 100', () => {
+    expect(exportProgressPercent({ exportId: 'e', documents: 1200, total: 1000 })).toBe(100)
+  })
+})
+
+describe('buildExportPayload CSV array and value modes', () => {
+  const base = {
+    format: 'csv' as const,
+    ejson: '[]',
+    collectionName: 'orders',
+    defaultFilename: 'orders.csv',
+    isCsv: true,
+    separator: ',',
+    includeHeader: true,
+    utf8Bom: false,
+  }
+
+  test('leaves the defaults out', () => {
+    const payload = buildExportPayload({
+      ...base,
+      arrays: 'ejson',
+      values: 'relaxed',
+      arrayDelimiter: ';',
+      unwindPath: 'items',
+    })
+
+    expect(payload.csv).toStrictEqual({ separator: ',', includeHeader: true, utf8Bom: false })
+  })
+
+  test('sends the delimiter only when joining', () => {
+    const payload = buildExportPayload({
+      ...base,
+      arrays: 'join',
+      arrayDelimiter: '|',
+      unwindPath: 'items',
+    })
+
+    expect(payload.csv?.arrays).toBe('join')
+    expect(payload.csv?.arrayDelimiter).toBe('|')
+    expect(payload.csv?.unwindPath).toBeUndefined()
+  })
+
+  test('sends the trimmed unwind path only when unwinding', () => {
+    const payload = buildExportPayload({
+      ...base,
+      arrays: 'unwind',
+      unwindPath: ' items ',
+      values: 'plain',
+    })
+
+    expect(payload.csv?.arrays).toBe('unwind')
+    expect(payload.csv?.unwindPath).toBe('items')
+    expect(payload.csv?.values).toBe('plain')
+  })
+})
+
+describe('buildExportPayload SQL options', () => {
+  const base = {
+    format: 'sql' as const,
+    ejson: '[]',
+    collectionName: 'orders',
+    defaultFilename: 'orders.sql',
+    isCsv: false,
+    separator: ',',
+    includeHeader: true,
+    utf8Bom: false,
+  }
+
+  test('leaves the defaults out', () => {
+    const payload = buildExportPayload({ ...base, sqlDialect: 'postgres', sqlTable: ' ' })
+
+    expect(payload.sql).toStrictEqual({})
+    expect(payload.csv).toBeUndefined()
+  })
+
+  test('sends the dialect and the trimmed table name', () => {
+    const payload = buildExportPayload({ ...base, sqlDialect: 'mysql', sqlTable: ' order_rows ' })
+
+    expect(payload.sql).toStrictEqual({ dialect: 'mysql', table: 'order_rows' })
+  })
+
+  test('sends no SQL options for other formats', () => {
+    const payload = buildExportPayload({ ...base, format: 'markdown', sqlDialect: 'mysql' })
+
+    expect(payload.sql).toBeUndefined()
+  })
+})
+
+describe('buildExportPayload compression and splitting', () => {
+  const base = {
+    format: 'ndjson' as const,
+    ejson: '[]',
+    collectionName: 'orders',
+    defaultFilename: 'orders.ndjson',
+    isCsv: false,
+    separator: ',',
+    includeHeader: true,
+    utf8Bom: false,
+  }
+
+  test('leaves them out by default', () => {
+    const payload = buildExportPayload({ ...base, compression: 'none', splitMode: 'none' })
+
+    expect(payload.compression).toBeUndefined()
+    expect(payload.split).toBeUndefined()
+  })
+
+  test('sends the compression', () => {
+    expect(buildExportPayload({ ...base, compression: 'zstd' }).compression).toBe('zstd')
+  })
+
+  test('sends the split limit it was given', () => {
+    expect(buildExportPayload({ ...base, splitMode: 'documents', splitSize: 5000 }).split).toEqual({
+      documents: 5000,
+    })
+    expect(buildExportPayload({ ...base, splitMode: 'megabytes', splitSize: 100 }).split).toEqual({
+      megabytes: 100,
+    })
+  })
+
+  test('sends no split without a size', () => {
+    const payload = buildExportPayload({ ...base, splitMode: 'documents', splitSize: null })
+
+    expect(payload.split).toBeUndefined()
+  })
 })
@@ -19,6 +19,19 @@ describe('buildDefaultFilename', () => {
   test('uses collection name + timestamp for ndjson', () => {
     expect(buildDefaultFilename('users', 'ndjson')).toBe('users-20260424-143022.ndjson')
   })
+  test('uses collection name + timestamp for xlsx', () => {
+    expect(buildDefaultFilename('users', 'xlsx')).toBe('users-20260424-143022.xlsx')
+  })
+  test('uses .md for markdown and the format name for html and sql', () => {
+    expect(buildDefaultFilename('users', 'markdown')).toBe('users-20260424-143022.md')
+    expect(buildDefaultFilename('users', 'html')).toBe('users-20260424-143022.html')
+    expect(buildDefaultFilename('users', 'sql')).toBe('users-20260424-143022.sql')
+  })
+  test('adds the compression extension', () => {
+    expect(buildDefaultFilename('users', 'ndjson', 'gzip')).toBe('users-20260424-143022.ndjson.gz')
+    expect(buildDefaultFilename('users', 'csv', 'zstd')).toBe('users-20260424-143022.csv.zst')
+    expect(buildDefaultFilename('users', 'csv', 'none')).toBe('users-20260424-143022.csv')
+  })
   test('falls back to vervet-export when collection missing', () => {
     expect(buildDefaultFilename(undefined, 'csv')).toBe('vervet-export-20260424-143022.csv')
     expect(buildDefaultFilename('', 'csv')).toBe('vervet-export-20260424-143022.csv')
@@ -22,4 +22,28 @@ describe('exportStore', () => {
     expect(s.csv.includeHeader).toBe(false)
     expect(s.csv.utf8Bom).toBe(true)
   })
+
+  test('setCsv keeps the options it is not given', () => {
+    const s = useExportStore()
+    s.setCsv({ arrays: 'unwind', unwindPath: 'items' })
+    s.setCsv({ separator: ';' })
+    expect(s.csv.arrays).toBe('unwind')
+    expect(s.csv.unwindPath).toBe('items')
+    expect(s.csv.separator).toBe(';')
+    expect(s.csv.values).toBe('relaxed')
+  })
+
+  test('remembers the compression', () => {
+    const s = useExportStore()
+    expect(s.compression).toBe('none')
+    s.setCompression('zstd')
+    expect(s.compression).toBe('zstd')
+  })
+
+  test('remembers the SQL dialect', () => {
+    const s = useExportStore()
+    expect(s.sqlDialect).toBe('postgres')
+    s.setSqlDialect('sqlite')
+    expect(s.sqlDialect).toBe('sqlite')
+  })
 })
@@ -65,4 +65,36 @@ describe('settingsStore', () => {
     expect(store.query.defaultLimit).toBe(100)
     expect(store.query.defaultPageSize).toBe(50)
   })
+
+  it('defaults audit redaction to values when missing from payload', async () => {
+    ;(settingsProxy.GetSettings as unknown as ReturnType<typeof vi.fn>).mockResolvedValue({
+      isSuccess: true,
+      data: {
+        general: { theme: 'auto', language: 'auto', font: { size: 14 } },
+        editor: { font: { size: 14 } },
+        terminal: { font: { size: 14 }, cursorStyle: 'block' },
+        workspaces: { fileExtensions: ['.js'] },
+        logging: { level: 'info', consoleEnabled: false, fileEnabled: true, maxSizeMB: 10, maxBackups: 5 },
+      },
+    })
+    const store = useSettingsStore()
+    await store.loadSettings()
+    expect(store.audit.redaction).toBe('values')
+  })
+
+  it('defaults masking profiles to an empty list when the backend sends none', async () => {
+    ;(settingsProxy.GetSettings as unknown as ReturnType<typeof vi.fn>).mockResolvedValue({
+      isSuccess: true,
+      data: {
+        general: { theme: 'auto', language: 'auto', font: { size: 14 } },
+        editor: { font: { size: 14 } },
+        terminal: { font: { size: 14 }, cursorStyle: 'block' },
+        workspaces: { fileExtensions: ['.js'] },
+        masking: { profiles: null },
+      },
+    })
+    const store = useSettingsStore()
+    await store.loadSettings()
+    expect(store.masking.profiles).toEqual([])
+  })
 })
@@ -43,6 +43,7 @@ export const useSettingsStore = defineStore('settings', {
         defaultLimit: 42,
         defaultPageSize: 25,
         queryEngine: 'builtin',
+        scriptSandbox: 'unrestricted',
       },
       terminal: {
         font: {
@@ -60,6 +61,12 @@ export const useSettingsStore = defineStore('settings', {
         maxSizeMB: 10,
         maxBackups: 5,
       },
+      audit: {
+        redaction: 'values',
+      },
+      masking: {
+        profiles: [] as models.MaskingProfile[],
+      },
       fontList: [],
       fontListLoaded: false,
     }) as unknown as SettingsStore,
@@ -132,6 +139,13 @@ export const useSettingsStore = defineStore('settings', {
         { value: 'error', label: 'settings.logging.levels.error' },
       ]
     },
+    auditRedactionOptions() {
+      return [
+        { value: 'none', label: 'settings.audit.redactNone' },
+        { value: 'values', label: 'settings.audit.redactValues' },
+        { value: 'all', label: 'settings.audit.redactAll' },
+      ]
+    },
     isDark(): boolean {
       const th = this.general.theme || 'auto'
       if (th === 'dark') {
@@ -182,6 +196,7 @@ export const useSettingsStore = defineStore('settings', {
           defaultLimit: 42,
           defaultPageSize: 25,
           queryEngine: legacyEngine ?? 'builtin',
+          scriptSandbox: 'unrestricted',
         })
       }
       const confirmDestructive = get(result.data, 'general.confirmDestructive')
@@ -202,6 +217,14 @@ export const useSettingsStore = defineStore('settings', {
           maxBackups: 5,
         })
       }
+      const audit = get(result.data, 'audit')
+      if (audit === undefined) {
+        set(this, 'audit', { redaction: 'values' })
+      }
+      const profiles = get(result.data, 'masking.profiles')
+      if (profiles == null) {
+        set(this, 'masking', { profiles: [] })
+      }
 
       i18nGlobal.locale = this.currentLanguage
     },
@@ -236,6 +259,8 @@ export const useSettingsStore = defineStore('settings', {
         workspaces: this.workspaces,
         updates: this.updates,
         logging: this.logging,
+        audit: this.audit,
+        masking: this.masking,
       } as models.Settings
       const result = await settingsProxy.SetSettings(payload)
       if (!result.isSuccess) {
@@ -254,6 +279,9 @@ export const useSettingsStore = defineStore('settings', {
         if (get(this, 'workspaces.fileExtensions') == null) {
           set(this, 'workspaces.fileExtensions', ['.js', '.mongodb'])
         }
+        if (get(this, 'masking.profiles') == null) {
+          set(this, 'masking', { profiles: [] })
+        }
         return true
       }
       return false
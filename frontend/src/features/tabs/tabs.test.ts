@@ -234,6 +234,18 @@ describe('tabs.innerTabOrder maintenance on close', () => {
     store.closeSchemaTab('s1', id)
     expect(store.tabItems[0]!.innerTabOrder).toEqual([])
   })
+
+  it('removes id on closeTerminalTab', () => {
+    const store = useTabStore()
+    store.tabItems = [seedServerTab('s1')]
+    const a = store.openTerminalTab('s1')!
+    const b = store.openTerminalTab('s1')!
+    expect(b).not.toBe(a)
+    store.closeTerminalTab('s1', b)
+    expect(store.tabItems[0]!.innerTabOrder).toEqual([a])
+    expect(store.tabItems[0]!.activeInnerTabId).toBe(a)
+    expect(store.currentSubTab).toBe('terminal')
+  })
 })
 
 describe('tabs.findFallbackInnerTabId via innerTabOrder', () => {
@@ -1,5 +1,12 @@
 import { defineStore } from 'pinia'
-import { type IndexTabItem, type QueryTabItem, type SchemaTabItem, type ServerTabItem, type StatisticsTabItem } from '@/types/ServerTabItem.ts'
+import {
+  type IndexTabItem,
+  type QueryTabItem,
+  type SchemaTabItem,
+  type ServerTabItem,
+  type StatisticsTabItem,
+  type TerminalTabItem,
+} from '@/types/ServerTabItem.ts'
 import { useDialoger } from '@/utils/dialog.ts'
 import { i18nGlobal } from '@/i18n'
 import { findIndex } from 'lodash'
@@ -46,6 +53,7 @@ let queryIdCounter = 0
 let indexTabIdCounter = 0
 let statisticsTabIdCounter = 0
 let schemaTabIdCounter = 0
+let terminalTabIdCounter = 0
 
 function innerTabType(id: string): BrowserSubTabType {
   if (id.startsWith('index-')) {
@@ -57,6 +65,9 @@ function innerTabType(id: string): BrowserSubTabType {
   if (id.startsWith('schema-')) {
     return BrowserSubTabType.Schema
   }
+  if (id.startsWith('terminal-')) {
+    return BrowserSubTabType.Terminal
+  }
   return BrowserSubTabType.Query
 }
 
@@ -612,6 +623,75 @@ export const useTabStore = defineStore('tabs', {
       return i18nGlobal.t('schemaBrowser.tabLabel', { collection: schemaTab.collectionName })
     },
 
+    openTerminalTab(serverId: string) {
+      const tabIndex = findIndex(this.tabItems, { serverId })
+      if (tabIndex === -1) {
+        return
+      }
+
+      const tab = this.tabItems[tabIndex]
+      if (!tab) {
+        return
+      }
+
+      if (!tab.terminalTabs) {
+        tab.terminalTabs = []
+      }
+
+      // Each terminal tab is a shell of its own, so a new one is always opened.
+      const terminalTab: TerminalTabItem = {
+        id: `terminal-${++terminalTabIdCounter}`,
+        serverId,
+      }
+
+      tab.terminalTabs.push(terminalTab)
+      tab.innerTabOrder.push(terminalTab.id)
+      tab.activeInnerTabId = terminalTab.id
+      this._setActivatedIndex(tabIndex, true)
+      return terminalTab.id
+    },
+
+    closeTerminalTab(serverId: string, terminalTabId: string) {
+      const tabIndex = findIndex(this.tabItems, { serverId })
+      if (tabIndex === -1) {
+        return
+      }
+
+      const tab = this.tabItems[tabIndex]
+      if (!tab || !tab.terminalTabs) {
+        return
+      }
+
+      const idx = tab.terminalTabs.findIndex((t) => t.id === terminalTabId)
+      if (idx === -1) {
+        return
+      }
+
+      tab.terminalTabs.splice(idx, 1)
+
+      const orderIdx = tab.innerTabOrder.indexOf(terminalTabId)
+      if (orderIdx !== -1) {
+        tab.innerTabOrder.splice(orderIdx, 1)
+      }
+
+      if (tab.activeInnerTabId === terminalTabId) {
+        if (tab.terminalTabs.length > 0) {
+          const newIdx = Math.min(idx, tab.terminalTabs.length - 1)
+          tab.activeInnerTabId = tab.terminalTabs[newIdx]?.id
+        } else {
+          tab.activeInnerTabId = findFallbackInnerTabId(tab)
+        }
+      }
+    },
+
+    terminalTabLabel(tab: ServerTabItem, terminalTab: TerminalTabItem): string {
+      const index = (tab.terminalTabs ?? []).indexOf(terminalTab)
+      if (index <= 0) {
+        return i18nGlobal.t('terminal.tabLabel')
+      }
+      return i18nGlobal.t('terminal.tabLabelNumbered', { number: index + 1 })
+    },
+
     reorderTabs(from: number, to: number) {
       const len = this.tabItems.length
       if (from === to) {
@@ -0,0 +1,125 @@
+import { setActivePinia, createPinia } from 'pinia'
+import { beforeEach, describe, expect, test, vi } from 'vitest'
+
+vi.mock('wailsjs/go/api/TerminalProxy', () => ({
+  Open: vi.fn(async () => ({ isSuccess: true, data: 'session-1' })),
+  Write: vi.fn(async () => ({ isSuccess: true })),
+  Resize: vi.fn(async () => ({ isSuccess: true })),
+  Close: vi.fn(async () => ({ isSuccess: true })),
+}))
+
+vi.mock('@/utils/dialog', () => ({
+  useNotifier: () => ({ info: vi.fn(), success: vi.fn(), error: vi.fn(), warning: vi.fn() }),
+}))
+
+import * as terminalProxy from 'wailsjs/go/api/TerminalProxy'
+import { type TerminalSink, useTerminalStore } from '@/features/terminal/terminalStore'
+
+function makeSink(): TerminalSink & { written: string[] } {
+  const written: string[] = []
+  return {
+    written,
+    write: (data: string) => written.push(data),
+    exited: vi.fn(),
+    dispose: vi.fn(),
+  }
+}
+
+describe('terminalStore', () => {
+  beforeEach(() => {
+    setActivePinia(createPinia())
+    vi.clearAllMocks()
+  })
+
+  test('output that arrives before Open returns is drawn once it does', async () => {
+    const store = useTerminalStore()
+    const sink = makeSink()
+    ;(terminalProxy.Open as ReturnType<typeof vi.fn>).mockImplementationOnce(async () => {
+      store.handleOutput({ sessionID: 'session-1', data: 'Current Mongosh Log ID' })
+      return { isSuccess: true, data: 'session-1' }
+    })
+
+    expect(await store.open('terminal-1', 's1', 80, 24, sink)).toBe(true)
+    store.handleOutput({ sessionID: 'session-1', data: '\r\ntest> ' })
+    store.handleOutput({ sessionID: 'other', data: 'not ours' })
+
+    expect(terminalProxy.Open).toHaveBeenCalledWith('s1', 80, 24)
+    expect(sink.written).toEqual(['Current Mongosh Log ID', '\r\ntest> '])
+  })
+
+  test('input and resizes go to the session', async () => {
+    const store = useTerminalStore()
+    await store.open('terminal-1', 's1', 80, 24, makeSink())
+
+    await store.write('terminal-1', 'show dbs\r')
+    await store.resize('terminal-1', 120, 40)
+    await store.resize('terminal-1', 0, 40)
+
+    expect(terminalProxy.Write).toHaveBeenCalledWith('session-1', 'show dbs\r')
+    expect(terminalProxy.Resize).toHaveBeenCalledTimes(1)
+    expect(terminalProxy.Resize).toHaveBeenCalledWith('session-1', 120, 40)
+  })
+
+  test('an exited shell can be started again', async () => {
+    const store = useTerminalStore()
+    const sink = makeSink()
+    await store.open('terminal-1', 's1', 80, 24, sink)
+
+    const exit = { sessionID: 'session-1', serverID: 's1', exitCode: 0 }
+    store.handleExit(exit)
+    expect(sink.exited).toHaveBeenCalledWith(exit)
+    expect(store.sessions['terminal-1']).toEqual({ serverId: 's1', sessionId: null, running: false })
+
+    await store.write('terminal-1', 'x')
+    expect(terminalProxy.Write).not.toHaveBeenCalled()
+
+    expect(await store.open('terminal-1', 's1', 80, 24, sink)).toBe(true)
+    expect(store.sessions['terminal-1']?.running).toBe(true)
+  })
+
+  test('a failed start leaves the tab ready to retry', async () => {
+    const store = useTerminalStore()
+    ;(terminalProxy.Open as ReturnType<typeof vi.fn>).mockResolvedValueOnce({
+      isSuccess: false,
+      errorDetail: 'mongosh not found',
+    })
+
+    expect(await store.open('terminal-1', 's1', 80, 24, makeSink())).toBe(false)
+    expect(store.sessions['terminal-1']?.running).toBe(false)
+  })
+
+  test('disconnecting a server closes its shells only', async () => {
+    const store = useTerminalStore()
+    const mine = makeSink()
+    const theirs = makeSink()
+    await store.open('terminal-1', 's1', 80, 24, mine)
+    ;(terminalProxy.Open as ReturnType<typeof vi.fn>).mockResolvedValueOnce({
+      isSuccess: true,
+      data: 'session-2',
+    })
+    await store.open('terminal-2', 's2', 80, 24, theirs)
+
+    await store.closeServer('s1')
+
+    expect(terminalProxy.Close).toHaveBeenCalledTimes(1)
+    expect(terminalProxy.Close).toHaveBeenCalledWith('session-1')
+    expect(mine.dispose).toHaveBeenCalled()
+    expect(theirs.dispose).not.toHaveBeenCalled()
+    expect(Object.keys(store.sessions)).toEqual(['terminal-2'])
+  })
+
+  test('a tab closed while its shell starts closes the shell', async () => {
+    const store = useTerminalStore()
+    let finish: (v: unknown) => void = () => {}
+    ;(terminalProxy.Open as ReturnType<typeof vi.fn>).mockReturnValueOnce(
+      new Promise((resolve) => (finish = resolve)),
+    )
+
+    const opening = store.open('terminal-1', 's1', 80, 24, makeSink())
+    await store.close('terminal-1')
+    finish({ isSuccess: true, data: 'session-1' })
+
+    expect(await opening).toBe(false)
+    expect(terminalProxy.Close).toHaveBeenCalledWith('session-1')
+  })
+})
@@ -0,0 +1,157 @@
+import { defineStore } from 'pinia'
+import * as terminalProxy from 'wailsjs/go/api/TerminalProxy'
+import { useNotifier } from '@/utils/dialog'
+import { i18nGlobal } from '@/i18n'
+
+/** Payload of the backend's terminal-output event. */
+export interface TerminalOutputEvent {
+  sessionID: string
+  data: string
+}
+
+/** Payload of the backend's terminal-exit event. */
+export interface TerminalExitEvent {
+  sessionID: string
+  serverID: string
+  exitCode: number
+  error?: string
+}
+
+/**
+ * Where a terminal tab's session draws. The xterm view outlives the tab's
+ * component, so output that arrives while another server tab is showing
+ * still lands in its scrollback.
+ */
+export interface TerminalSink {
+  write(data: string): void
+  exited(event: TerminalExitEvent): void
+  dispose(): void
+}
+
+export interface TerminalSessionState {
+  serverId: string
+  sessionId: string | null
+  running: boolean
+}
+
+interface TerminalStoreState {
+  sessions: Record<string, TerminalSessionState>
+}
+
+// Kept outside the store's state: sinks hold xterm instances, which must
+// not be made reactive, and output can arrive for a session before Open
+// has told us its ID.
+const sinks = new Map<string, TerminalSink>()
+const pending = new Map<string, string[]>()
+
+export const useTerminalStore = defineStore('terminal', {
+  state: (): TerminalStoreState => ({
+    sessions: {},
+  }),
+  actions: {
+    /**
+     * Starts mongosh for the tab, cols wide and rows high, drawing into
+     * sink. A tab whose shell has exited is started again.
+     */
+    async open(
+      tabId: string,
+      serverId: string,
+      cols: number,
+      rows: number,
+      sink: TerminalSink,
+    ): Promise<boolean> {
+      const existing = this.sessions[tabId]
+      if (existing?.running) {
+        return true
+      }
+      sinks.set(tabId, sink)
+      this.sessions[tabId] = { serverId, sessionId: null, running: true }
+
+      const result = await terminalProxy.Open(serverId, cols, rows)
+      const state = this.sessions[tabId]
+      if (!result.isSuccess) {
+        if (state) {
+          state.running = false
+        }
+        useNotifier().error(i18nGlobal.t('terminal.openFailed'), { detail: result.errorDetail })
+        return false
+      }
+      if (!state) {
+        // The tab was closed while mongosh started.
+        await terminalProxy.Close(result.data)
+        return false
+      }
+      state.sessionId = result.data
+      for (const data of pending.get(result.data) ?? []) {
+        sink.write(data)
+      }
+      pending.delete(result.data)
+      return true
+    },
+
+    handleOutput(event: TerminalOutputEvent) {
+      const tabId = this._tabFor(event.sessionID)
+      const sink = tabId ? sinks.get(tabId) : undefined
+      if (sink) {
+        sink.write(event.data)
+        return
+      }
+      const chunks = pending.get(event.sessionID) ?? []
+      chunks.push(event.data)
+      pending.set(event.sessionID, chunks)
+    },
+
+    handleExit(event: TerminalExitEvent) {
+      pending.delete(event.sessionID)
+      const tabId = this._tabFor(event.sessionID)
+      const state = tabId ? this.sessions[tabId] : undefined
+      if (!tabId || !state) {
+        return
+      }
+      state.running = false
+      state.sessionId = null
+      sinks.get(tabId)?.exited(event)
+    },
+
+    async write(tabId: string, data: string) {
+      const sessionId = this.sessions[tabId]?.sessionId
+      if (sessionId) {
+        await terminalProxy.Write(sessionId, data)
+      }
+    },
+
+    async resize(tabId: string, cols: number, rows: number) {
+      const sessionId = this.sessions[tabId]?.sessionId
+      if (sessionId && cols > 0 && rows > 0) {
+        await terminalProxy.Resize(sessionId, cols, rows)
+      }
+    },
+
+    /** Ends the tab's shell, if it is running, and drops its view. */
+    async close(tabId: string) {
+      const state = this.sessions[tabId]
+      delete this.sessions[tabId]
+      sinks.get(tabId)?.dispose()
+      sinks.delete(tabId)
+      if (state?.sessionId) {
+        await terminalProxy.Close(state.sessionId)
+      }
+    },
+
+    /** Closes every terminal tab of serverId, as its connection goes. */
+    async closeServer(serverId: string) {
+      const tabIds = Object.keys(this.sessions).filter(
+        (id) => this.sessions[id]?.serverId === serverId,
+      )
+      await Promise.all(tabIds.map((id) => this.close(id)))
+    },
+
+    async closeAll() {
+      await Promise.all(Object.keys(this.sessions).map((id) => this.close(id)))
+    },
+
+    _tabFor(sessionId: string): string | undefined {
+      return Object.keys(this.sessions).find((id) => this.sessions[id]?.sessionId === sessionId)
+    },
+  },
+})
@@ -0,0 +1,60 @@
+import { Terminal, type ITerminalOptions, type ITheme } from '@xterm/xterm'
+import { FitAddon } from '@xterm/addon-fit'
+import '@xterm/xterm/css/xterm.css'
+
+/**
+ * A terminal tab's xterm instance. It draws into element, which the tab's
+ * component moves into its container each time it mounts, so scrollback
+ * and the running shell survive switching server tabs.
+ */
+export interface TerminalView {
+  term: Terminal
+  fit: FitAddon
+  element: HTMLDivElement
+}
+
+const views = new Map<string, TerminalView>()
+
+/** Returns tabId's view, creating it with options the first time. */
+export function terminalView(tabId: string, options: ITerminalOptions): TerminalView {
+  let view = views.get(tabId)
+  if (!view) {
+    const term = new Terminal(options)
+    const fit = new FitAddon()
+    term.loadAddon(fit)
+    const element = document.createElement('div')
+    element.className = 'terminal-view'
+    view = { term, fit, element }
+    views.set(tabId, view)
+  }
+  return view
+}
+
+/**
+ * Moves view into container. xterm measures its cells when it opens, so it
+ * is opened on the first attach, once its element is in the document.
+ */
+export function attachTerminalView(view: TerminalView, container: HTMLElement) {
+  container.appendChild(view.element)
+  if (!view.term.element) {
+    view.term.open(view.element)
+  }
+}
+
+export function disposeTerminalView(tabId: string) {
+  const view = views.get(tabId)
+  if (!view) {
+    return
+  }
+  views.delete(tabId)
+  view.term.dispose()
+  view.element.remove()
+}
+
+/** Applies changed settings or theme to an open view. */
+export function updateTerminalView(view: TerminalView, options: ITerminalOptions, theme: ITheme) {
+  view.term.options.fontFamily = options.fontFamily
+  view.term.options.fontSize = options.fontSize
+  view.term.options.cursorStyle = options.cursorStyle
+  view.term.options.theme = theme
+}
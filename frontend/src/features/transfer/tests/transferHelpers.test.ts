@@ -0,0 +1,119 @@
+import { describe, expect, test } from 'vitest'
+import {
+  buildTransferRequest,
+  checkpointSummary,
+  newRow,
+  progressPercent,
+  syncRows,
+  totals,
+} from '../transferHelpers'
+
+describe('syncRows', () => {
+  test('adds new collections and keeps edits to those still chosen', () => {
+    const edited = { ...newRow('orders'), target: 'orders_copy' }
+    expect(syncRows([edited, newRow('users')], ['orders', 'log'])).toEqual([edited, newRow('log')])
+  })
+})
+
+describe('buildTransferRequest', () => {
+  const base = {
+    transferId: 't1',
+    sourceServerId: 'a',
+    sourceDatabase: 'shop',
+    targetServerId: 'b',
+    targetDatabase: ' shop_copy ',
+    mode: 'merge' as const,
+    copyIndexes: true,
+    copyOptions: false,
+    batchSize: null,
+  }
+
+  test('leaves out unchanged names and empty queries', () => {
+    const req = buildTransferRequest({ ...base, rows: [newRow('orders')] })
+    expect(req.targetDatabase).toBe('shop_copy')
+    expect(req.collections).toEqual([{ name: 'orders' }])
+    expect(req.batchSize).toBe(0)
+  })
+  test('sends a renamed target and the query as its kind', () => {
+    const req = buildTransferRequest({
+      ...base,
+      batchSize: 500,
+      rows: [
+        { name: 'orders', target: ' recent ', queryKind: 'filter', query: ' {"total": 1} ' },
+        { name: 'users', target: '', queryKind: 'pipeline', query: '[{"$limit": 5}]' },
+      ],
+    })
+    expect(req.collections).toEqual([
+      { name: 'orders', target: 'recent', filter: '{"total": 1}' },
+      { name: 'users', pipeline: '[{"$limit": 5}]' },
+    ])
+    expect(req.batchSize).toBe(500)
+  })
+  test('sends the masking profile only when one is chosen', () => {
+    const rows = [newRow('orders')]
+    expect(buildTransferRequest({ ...base, rows, maskingProfile: 'customers' }).maskingProfile).toBe(
+      'customers',
+    )
+    expect(buildTransferRequest({ ...base, rows, maskingProfile: '' })).not.toHaveProperty(
+      'maskingProfile',
+    )
+  })
+})
+
+describe('progressPercent', () => {
+  const base = { transferId: 't', collection: 'orders', copied: 0, total: 0, collectionsDone: 0, collections: 4 }
+
+  test('is zero before any progress', () => {
+    expect(progressPercent(null)).toBe(0)
+    expect(progressPercent({ ...base, collections: 0 })).toBe(0)
+  })
+  test('adds the part of the current collection', () => {
+    expect(progressPercent({ ...base, collectionsDone: 1, copied: 50, total: 100 })).toBe(38)
+  })
+  test('never passes 100', () => {
+    expect(progressPercent({ ...base, collectionsDone: 4, copied: 10, total: 5 })).toBe(100)
+  })
+})
+
+describe('totals', () => {
+  test('sums the collections', () => {
+    expect(
+      totals({
+        cancelled: false,
+        collections: [
+          { name: 'a', target: 'a', copied: 10, failed: 1, indexes: 2 },
+          { name: 'b', target: 'b', copied: 5, failed: 0, indexes: 0 },
+        ],
+      }),
+    ).toEqual({ collections: 2, copied: 15, failed: 1 })
+  })
+})
+
+describe('checkpointSummary', () => {
+  test('counts what was copied before the transfer stopped', () => {
+    const names: Record<string, string> = { a: 'Prod', b: 'Staging' }
+    const summary = checkpointSummary(
+      {
+        request: {
+          transferId: 't1',
+          sourceServerId: 'a',
+          sourceDatabase: 'shop',
+          targetServerId: 'b',
+          targetDatabase: 'shop',
+          collections: [{ name: 'orders' }, { name: 'users' }],
+          mode: 'merge',
+          copyIndexes: true,
+          copyOptions: true,
+          batchSize: 0,
+        },
+        collections: [
+          { name: 'orders', prepared: true, done: true, copied: 100, failed: 0, indexes: 1 },
+          { name: 'users', prepared: true, done: false, copied: 30, failed: 0, indexes: 0 },
+        ],
+        updatedAt: '2026-03-01T12:00:00Z',
+      },
+      (id) => names[id] ?? id,
+    )
+    expect(summary).toEqual({ source: 'Prod/shop', target: 'Staging/shop', done: 1, collections: 2, copied: 130 })
+  })
+})
@@ -0,0 +1,124 @@
+import { type models } from 'wailsjs/go/models.ts'
+
+export type TransferMode = 'merge' | 'drop'
+export type QueryKind = 'filter' | 'pipeline'
+
+/** One collection to copy, as the dialog edits it. */
+export interface TransferRow {
+  name: string
+  target: string
+  queryKind: QueryKind
+  query: string
+}
+
+export function newRow(name: string): TransferRow {
+  return { name, target: name, queryKind: 'filter', query: '' }
+}
+
+/**
+ * Keeps the rows of the chosen collections in the order chosen, reusing the
+ * edits already made to those still chosen.
+ */
+export function syncRows(rows: TransferRow[], names: string[]): TransferRow[] {
+  return names.map((name) => rows.find((r) => r.name === name) ?? newRow(name))
+}
+
+export interface TransferRequestOptions {
+  transferId: string
+  sourceServerId: string
+  sourceDatabase: string
+  targetServerId: string
+  targetDatabase: string
+  rows: TransferRow[]
+  mode: TransferMode
+  copyIndexes: boolean
+  copyOptions: boolean
+  batchSize: number | null
+  maskingProfile?: string
+}
+
+export function buildTransferRequest(opts: TransferRequestOptions): models.TransferRequest {
+  const req: models.TransferRequest = {
+    transferId: opts.transferId,
+    sourceServerId: opts.sourceServerId,
+    sourceDatabase: opts.sourceDatabase,
+    targetServerId: opts.targetServerId,
+    targetDatabase: opts.targetDatabase.trim(),
+    collections: opts.rows.map((row) => {
+      const collection: models.TransferCollection = { name: row.name }
+      const target = row.target.trim()
+      if (target !== '' && target !== row.name) {
+        collection.target = target
+      }
+      const query = row.query.trim()
+      if (query !== '') {
+        collection[row.queryKind] = query
+      }
+      return collection
+    }),
+    mode: opts.mode,
+    copyIndexes: opts.copyIndexes,
+    copyOptions: opts.copyOptions,
+    batchSize: opts.batchSize ?? 0,
+  }
+  if (opts.maskingProfile) {
+    req.maskingProfile = opts.maskingProfile
+  }
+  return req
+}
+
+/**
+ * How far through a transfer is, from 0 to 100: the collections done, plus
+ * the part of the current one when its size is known.
+ */
+export function progressPercent(progress: models.TransferProgress | null): number {
+  if (!progress || progress.collections <= 0) {
+    return 0
+  }
+  let done = progress.collectionsDone
+  if (progress.total > 0 && done < progress.collections) {
+    done += Math.min(1, progress.copied / progress.total)
+  }
+  return Math.min(100, Math.round((done / progress.collections) * 100))
+}
+
+export type TransferTotals = {
+  collections: number
+  copied: number
+  failed: number
+}
+
+export function totals(result: models.TransferResult): TransferTotals {
+  return result.collections.reduce(
+    (acc, c) => ({
+      collections: acc.collections + 1,
+      copied: acc.copied + c.copied,
+      failed: acc.failed + c.failed,
+    }),
+    { collections: 0, copied: 0, failed: 0 },
+  )
+}
+
+export type CheckpointSummary = {
+  source: string
+  target: string
+  done: number
+  collections: number
+  copied: number
+}
+
+/** What an interrupted transfer copied before it stopped. */
+export function checkpointSummary(
+  checkpoint: models.TransferCheckpoint,
+  serverName: (serverId: string) => string,
+): CheckpointSummary {
+  const { request } = checkpoint
+  const states = checkpoint.collections ?? []
+  return {
+    source: `${serverName(request.sourceServerId)}/${request.sourceDatabase}`,
+    target: `${serverName(request.targetServerId)}/${request.targetDatabase}`,
+    done: states.filter((s) => s.done).length,
+    collections: request.collections.length,
+    copied: states.reduce((sum, s) => sum + s.copied, 0),
+  }
+}
@@ -89,9 +89,20 @@ export default {
       queryEngineMongosh: 'mongosh',
       queryEngineHelp:
         'Built-in engine requires no external dependencies. mongosh requires mongosh to be installed separately.',
+      scriptSandbox: 'Script file access',
+      scriptSandboxUnrestricted: 'Unrestricted',
+      scriptSandboxWorkspace: 'Workspace only',
+      scriptSandboxReadOnly: 'Read-only',
+      scriptSandboxHelp:
+        'Limits what built-in engine scripts can reach. Workspace only confines fs, require(), load() and imports to the active workspace folders and the script\'s own folder, and hides environment variables from process.env. Read-only applies the same limits and also blocks every write. Scripts run with mongosh are not affected.',
     },
     terminal: {
-      name: 'Messages',
+      name: 'Terminal',
+      fontTip: 'Used by shell tabs and by the messages pane of query tabs.',
+      cursorStyle: 'Cursor Style',
+      cursorStyleBlock: 'Block',
+      cursorStyleUnderline: 'Underline',
+      cursorStyleBar: 'Bar',
     },
     workspaces: {
       name: 'Workspaces',
@@ -119,6 +130,46 @@ export default {
       revealDisabledBody: 'Logging to file is currently disabled. The folder may still contain older logs. Open it anyway?',
       restartHint: 'Destination and rotation changes take effect on next restart. Log level changes apply immediately.',
     },
+    audit: {
+      name: 'Audit',
+      description:
+        'Every change made through Vervet — script writes, drops, renames, index changes and document edits — is recorded in an append-only audit log. Each entry is chained to the one before it, so edits to the log can be detected.',
+      redaction: 'Record arguments',
+      redactNone: 'In full',
+      redactValues: 'Field names only, values redacted',
+      redactAll: 'Not at all',
+      redactionHelp:
+        'Filters, updates and documents can hold personal data. Redacting values keeps field names and operators so the entry still shows what a change touched.',
+      export: 'Export log…',
+      exported: 'Audit log saved to {path}',
+      verify: 'Verify log',
+      valid: 'The log is intact: {count} entries, unbroken.',
+      lastHash: 'Newest entry hash: {hash}. Keep a copy of it elsewhere to detect entries removed from the end later.',
+      broken: 'The log is broken at line {line}: {reason}.',
+      mongoshHint:
+        'Only the built-in query engine is audited. Scripts run with mongosh and commands sent through runCommand are not recorded.',
+    },
+    masking: {
+      name: 'Masking',
+      description:
+        'Masking profiles scrub personal data from exports and copies between servers. Each rule masks the field at a path; paths reach into arrays, so contacts.email masks the email of every contact.',
+      profile: 'Profile',
+      noProfiles: 'No profiles yet',
+      addProfile: 'Add',
+      removeProfile: 'Remove',
+      newProfile: 'Profile',
+      unnamed: '(unnamed)',
+      profileName: 'Name',
+      key: 'Key',
+      keyPlaceholder: 'A new random key each time',
+      generateKey: 'Generate',
+      keyHelp:
+        'Hashes, fake values and jitter follow from the key, so with one the same value masks the same way in every export and copy, and masked references still match. Anyone with the key can test guesses against the hashes.',
+      rules: 'Rules',
+      path: 'Field path, e.g. contacts.email',
+      days: 'days',
+      addRule: 'Add rule',
+    },
     updates: {
       title: 'Updates',
       frequency: 'Check for updates',
@@ -215,6 +266,7 @@ export default {
     contextMenu: {
       addDatabase: 'Add Database...',
       serverStatus: 'Server Status',
+      openShell: 'Open Shell',
       disconnect: 'Disconnect',
       openQuery: 'Open Query',
       dropDatabase: 'Drop Database',
@@ -225,6 +277,12 @@ export default {
       dropCollection: 'Drop Collection',
       viewIndexes: 'View Indexes',
       inspectSchema: 'Inspect Schema',
+      importData: 'Import Data...',
+      exportData: 'Export Data...',
+      backUp: 'Back Up...',
+      restore: 'Restore...',
+      copyToServer: 'Copy to Server...',
+      generateData: 'Generate Data...',
     },
     subTab: {
       query: 'Query',
@@ -319,6 +377,14 @@ export default {
       },
     },
   },
+  terminal: {
+    tabLabel: 'Shell',
+    tabLabelNumbered: 'Shell {number}',
+    openFailed: 'Could not start mongosh',
+    exited: 'mongosh exited with code {code}. Press Enter to start it again.',
+    exitedWithError: 'mongosh stopped: {error}. Press Enter to start it again.',
+    notStarted: 'mongosh did not start. Press Enter to try again.',
+  },
   schemaBrowser: {
     tabLabel: '{collection} schema',
     resample: 'Re-sample',
@@ -450,6 +516,10 @@ export default {
       bgFinished: '{db} query finished ({elapsed})',
       bgFailed: '{db} query failed',
     },
+    scriptPrompt: {
+      title: 'The script is asking',
+    },
+    progress: '{done} of {total}',
     database: 'Database',
     selectDatabase: 'Select database...',
     emptyState: 'No open tabs',
@@ -468,6 +538,8 @@ export default {
     objectFields: "{'{'} {count} fields {'}'}",
     binaryValue: 'Binary ({subType})',
     timestampValue: 'Timestamp({t}, {i})',
+    truncatedValue: '… {type} too large to show ({size})',
+    truncatedRest: '… {count} more not shown ({size})',
     tabContextMenu: {
       duplicate: 'Duplicate',
     },
@@ -479,6 +551,7 @@ export default {
       deleteDocument: 'Delete Document',
       copyValue: 'Copy Value',
       copyField: 'Copy Field',
+      loadValue: 'Load Full Value',
       copied: 'Copied to clipboard',
     },
     dialogs: {
@@ -511,6 +584,7 @@ export default {
       boolean: 'Boolean',
       minKey: 'MinKey',
       maxKey: 'MaxKey',
+      truncated: 'Truncated',
     },
     openFile: 'Open',
     saveFile: 'Save',
@@ -526,16 +600,61 @@ export default {
     unsavedChangesDontSave: "Don't Save",
     unsavedChangesCancel: 'Cancel',
     modified: 'Modified',
+    history: 'History',
+  },
+  queryHistory: {
+    title: 'Query History',
+    searchPlaceholder: 'Search queries, databases and script paths...',
+    from: 'From',
+    to: 'To',
+    currentServerOnly: 'This server only',
+    empty: 'No queries found',
+    resultCount: '{count} result(s)',
+    firstPageCount: '{count} result(s) on the first page',
+    openInNewTab: 'Open in new tab',
+    truncated: 'Too long to replay: only the start of this query was kept',
+    delete: 'Remove from history',
+    clear: 'Clear History',
+    clearConfirm: 'Remove every query from the history? This cannot be undone.',
+    outcomes: {
+      succeeded: 'Succeeded',
+      failed: 'Failed',
+      cancelled: 'Cancelled',
+    },
   },
   export: {
     title: 'Export results',
+    collectionTitle: 'Export {collection}',
     button: 'Export',
     contextMenu: 'Export results…',
+    scope: {
+      label: 'Documents',
+      loaded: 'Loaded results',
+      all: 'Everything the query matches',
+    },
+    pipeline: {
+      label: 'Aggregation pipeline (optional)',
+      placeholder: 'Extended JSON array of stages. Leave empty to export the whole collection.',
+    },
     format: {
       label: 'Format',
       csv: 'CSV',
       json: 'JSON',
       ndjson: 'NDJSON',
+      xlsx: 'Excel (XLSX)',
+      markdown: 'Markdown table',
+      html: 'HTML table',
+      sql: 'SQL',
+    },
+    sql: {
+      dialect: 'SQL dialect',
+      dialects: {
+        postgres: 'PostgreSQL',
+        mysql: 'MySQL',
+        sqlite: 'SQLite',
+        sqlserver: 'SQL Server',
+      },
+      table: 'Table name',
     },
     csv: {
       separator: {
@@ -547,14 +666,263 @@ export default {
         custom: 'Custom…',
         customPlaceholder: 'Single character',
       },
+      arrays: {
+        label: 'Arrays',
+        ejson: 'One cell of Extended JSON',
+        indexed: 'A column per element (tags.0, tags.1)',
+        join: 'Join values into one cell',
+        unwind: 'A row per element',
+        delimiterPlaceholder: 'Delimiter',
+        unwindPathPlaceholder: 'Array to unwind, e.g. items',
+      },
+      values: {
+        label: 'ObjectIds, dates and other BSON types',
+        relaxed: 'Relaxed Extended JSON',
+        canonical: 'Canonical Extended JSON',
+        plain: 'Plain values (ObjectId hex, ISO dates)',
+      },
       includeHeader: 'Include header row',
       utf8Bom: 'UTF-8 BOM',
       utf8BomHelp: 'Enable if you plan to open this file in Excel — helps preserve non-ASCII characters.',
     },
+    compression: {
+      label: 'Compression',
+      none: 'None',
+      gzip: 'gzip (.gz)',
+      zstd: 'Zstandard (.zst)',
+    },
+    split: {
+      label: 'Split into files',
+      none: "Don't split",
+      documents: 'Every N documents',
+      megabytes: 'Every N megabytes',
+      documentsPlaceholder: 'Documents per file',
+      megabytesPlaceholder: 'Megabytes per file, before compression',
+      help: 'Files are numbered, such as orders-001.ndjson, and each one is a complete export.',
+    },
     filenamePreview: 'Default filename: {name}',
     cancel: 'Cancel',
     saved: 'Saved to {path}',
+    copy: 'Copy to clipboard',
+    copied: 'Copied to the clipboard',
+    copyFailed: 'Failed to copy to clipboard',
     error: 'Export failed: {message}',
+    progress: '{documents} documents written',
+    progressOf: '{documents} of {total} documents written',
+    stop: 'Stop',
+  },
+  import: {
+    title: 'Import into {collection}',
+    file: 'File',
+    chooseFile: 'Choose…',
+    selectTitle: 'Choose a file to import',
+    noFile: 'No file chosen',
+    format: {
+      label: 'Format',
+      csv: 'CSV',
+      json: 'JSON',
+      ndjson: 'NDJSON',
+    },
+    csv: {
+      hasHeader: 'First row is a header',
+      ignoreBlanks: 'Leave out empty cells',
+      columns: 'Columns',
+      column: 'Column',
+      field: 'Field',
+      type: 'Type',
+      skipped: 'Skipped',
+      fieldHelp: 'Dot paths such as address.city build nested documents. Leave a field empty to skip its column.',
+    },
+    types: {
+      auto: 'Auto',
+      string: 'String',
+      number: 'Number',
+      boolean: 'Boolean',
+      date: 'Date',
+      objectId: 'ObjectId',
+      ejson: 'Extended JSON',
+      skip: 'Skip',
+    },
+    mode: {
+      label: 'Mode',
+      insert: 'Insert',
+      upsert: 'Upsert',
+      upsertKeysPlaceholder: 'Match on fields, e.g. sku, region',
+    },
+    preview: 'Preview',
+    refreshPreview: 'Refresh',
+    previewEmpty: 'No documents to preview',
+    start: 'Import',
+    stop: 'Stop',
+    progress: '{rows} rows read: {inserted} inserted, {updated} updated, {rejected} rejected',
+    done: 'Import finished: {inserted} inserted, {updated} updated, {rejected} rejected of {rows} rows',
+    cancelled: 'Stopped after {rows} rows: {inserted} inserted, {updated} updated, {rejected} rejected',
+    rejected: '{count} rows rejected',
+    errorsTruncated: 'Only the first {count} rejected rows are listed.',
+    row: 'Row',
+    error: 'Error',
+  },
+  backup: {
+    title: 'Back Up {database}',
+    collections: 'Collections',
+    allCollections: 'All collections and views',
+    collectionsHelp: 'Leave empty to back up the whole database.',
+    format: {
+      label: 'Format',
+      directory: 'Directory (mongodump layout)',
+      archive: 'Single archive file',
+    },
+    gzip: 'Compress with gzip',
+    directory: 'Directory',
+    archiveFile: 'Archive file',
+    archiveFilter: 'mongodump archives',
+    choose: 'Choose…',
+    noLocation: 'No location chosen',
+    selectDirectoryTitle: 'Choose a directory to back up into',
+    saveArchiveTitle: 'Save backup archive',
+    start: 'Back Up',
+    stop: 'Stop',
+    progress: '{namespace}: {documents} documents, {collectionsDone} of {collections} collections done',
+    done: 'Backed up {collections} collections and views, {documents} documents',
+    cancelled: 'Backup stopped. The files it wrote were removed.',
+    namespace: 'Namespace',
+    documents: 'Documents',
+    indexes: 'Indexes',
+    failed: 'Failed',
+    error: 'Error',
+    restore: {
+      title: 'Restore',
+      selectDirectoryTitle: 'Choose a dump directory',
+      selectArchiveTitle: 'Choose a mongodump archive',
+      noSource: 'No dump chosen',
+      database: 'Restore into database',
+      databasePlaceholder: 'The database each collection came from',
+      databaseHelp: 'Only for a dump of a single database.',
+      drop: 'Drop each collection before restoring it',
+      dropHelp: 'Collections and views in the dump replace those with the same name.',
+      mergeHelp:
+        'Documents are added to existing collections. Those whose _id is already there are skipped.',
+      start: 'Restore',
+      progress: '{namespace}: {documents} documents, {collectionsDone} of {collections} collections done',
+      done: 'Restored {collections} collections and views, {documents} documents',
+      cancelled: 'Restore stopped after {documents} documents. What was restored stays.',
+      failures: 'Some documents or indexes could not be restored',
+    },
+  },
+  transfer: {
+    title: 'Copy {database}',
+    source: 'From',
+    targetServer: 'To server',
+    targetDatabase: 'To database',
+    collections: 'Collections',
+    collectionsPlaceholder: 'Choose collections and views to copy',
+    collection: 'Collection',
+    targetName: 'Copy as',
+    query: {
+      filter: 'Filter',
+      pipeline: 'Pipeline',
+      filterPlaceholder: 'All documents',
+      pipelinePlaceholder: '[{ "$match": {} }]',
+    },
+    mode: {
+      label: 'Existing collections',
+      merge: 'Merge',
+      drop: 'Drop and replace',
+      mergeHelp: 'Copied documents replace those with the same _id; the rest are kept.',
+      dropHelp: 'Each target collection is dropped before anything is copied into it.',
+    },
+    copyIndexes: 'Copy indexes',
+    copyOptions: 'Copy collection options (validator, collation, capped, time series)',
+    batchSize: 'Batch size',
+    start: 'Copy',
+    stop: 'Stop',
+    progress: '{collection}: {copied} documents, {collectionsDone} of {collections} collections done',
+    done: 'Copied {collections} collections, {copied} documents',
+    cancelled: 'Copy stopped after {copied} documents. It can be resumed.',
+    target: 'Target',
+    copied: 'Copied',
+    failed: 'Failed',
+    indexes: 'Indexes',
+    error: 'Error',
+    interrupted: {
+      title: 'Interrupted copies',
+      summary: '{source} → {target}: {done} of {collections} collections, {copied} documents',
+      resume: 'Resume',
+      discard: 'Discard',
+    },
+  },
+  generate: {
+    title: 'Generate Data Like {collection}',
+    help:
+      'Documents are made up from a sample of the collection: the same fields, as often, with values of the same types in the same ranges.',
+    count: 'Documents',
+    sampleSize: 'Sample size',
+    seed: 'Seed',
+    seedPlaceholder: 'Random',
+    overrides: {
+      label: 'Overrides',
+      help:
+        'Set how chosen fields are made. Paths use dots and reach into arrays; a path the sample lacks adds the field. Values are Extended JSON.',
+      path: 'Field path',
+      kinds: {
+        constant: 'Constant',
+        choice: 'One of',
+        range: 'Range',
+        sequence: 'Sequence',
+        fake: 'Fake',
+        omit: 'Leave out',
+      },
+      constantPlaceholder: '"active"',
+      choicePlaceholder: 'One value per line',
+      min: 'Min (number or date)',
+      max: 'Max',
+      start: 'Start (default 1)',
+      add: 'Add Override',
+      remove: 'Remove',
+    },
+    output: {
+      label: 'Output',
+      insert: 'Insert into a collection',
+      ndjson: 'Write an NDJSON file',
+    },
+    targetServer: 'Server',
+    targetDatabase: 'Database',
+    targetCollection: 'Collection',
+    file: 'File',
+    noFile: 'No file chosen',
+    choose: 'Choose…',
+    ndjsonFilter: 'NDJSON Files',
+    saveTitle: 'Save Generated Documents',
+    start: 'Generate',
+    stop: 'Stop',
+    progress: '{generated} of {total} documents',
+    done: 'Generated {generated} documents',
+    cancelled: 'Stopped after {generated} documents',
+    failed: '{failed} documents were refused: {error}',
+  },
+  masking: {
+    strategies: {
+      hash: 'Hash',
+      redact: 'Redact',
+      shuffle: 'Shuffle',
+      fake: 'Fake',
+      jitter: 'Jitter date',
+      keep: 'Keep',
+    },
+    kinds: {
+      name: 'Name',
+      email: 'Email',
+      phone: 'Phone',
+      address: 'Address',
+    },
+    picker: {
+      label: 'Masking profile',
+      none: 'No masking',
+      manage: 'Manage…',
+      issues: 'The profile has {count} problem(s) with these documents',
+      profile: 'profile',
+      suggestion: '— did you mean {path}?',
+    },
   },
   serverPane: {
     serverTree: {
@@ -818,6 +1186,17 @@ export default {
     updateDocument: 'Failed to update document',
     deleteDocument: 'Failed to delete document',
     insertDocument: 'Failed to insert document',
+    loadValue: 'Failed to load value',
     loadFile: 'Failed to load file',
+    loadHistory: 'Failed to load query history',
+    deleteHistory: 'Failed to update query history',
+    exportAudit: 'Failed to export audit log',
+    verifyAudit: 'Failed to verify audit log',
+    previewImport: 'Failed to read import file',
+    importData: 'Import failed',
+    backup: 'Backup failed',
+    restore: 'Restore failed',
+    transfer: 'Copy failed',
+    generate: 'Generating data failed',
   },
 }
@@ -27,6 +27,12 @@ export const enum DialogType {
   ExportResults = 'exportResults',
   DestructiveConfirm = 'destructiveConfirm',
   NamespaceFinder = 'namespaceFinder',
+  QueryHistory = 'queryHistory',
+  Import = 'import',
+  Backup = 'backup',
+  Restore = 'restore',
+  Transfer = 'transfer',
+  Generate = 'generate',
 }
 
 export type ServerDialogData = {
@@ -52,9 +58,55 @@ export type DestructiveConfirmData = {
   onConfirm: () => Promise<void>
 }
 
+/**
+ * ExportSource is where a result set came from. With it, the export dialog
+ * can have the backend stream every matching document, not just the loaded
+ * page.
+ */
+export type ExportSource = {
+  serverId: string
+  database: string
+  pageContext: models.PageContext
+}
+
 export type ExportResultsData = {
   ejson: string
   collectionName?: string
+  source?: ExportSource
+}
+
+export type ImportDialogData = {
+  serverID: string
+  dbName: string
+  collectionName: string
+}
+
+export type BackupDialogData = {
+  serverID: string
+  dbName: string
+  // collections preselects what to back up; empty means the whole database.
+  collections: string[]
+}
+
+export type RestoreDialogData = {
+  serverID: string
+  // dbName is the database to restore into; empty restores each collection
+  // into the database it was dumped from.
+  dbName: string
+}
+
+export type TransferDialogData = {
+  serverID: string
+  dbName: string
+  // collections preselects what to copy; empty offers the whole database.
+  collections: string[]
+}
+
+export type GenerateDialogData = {
+  serverID: string
+  dbName: string
+  // collectionName is the collection sampled for the documents' shape.
+  collectionName: string
 }
 
 export const useDialogStore = defineStore('dialog', {
@@ -111,6 +163,30 @@ export const useDialogStore = defineStore('dialog', {
         visible: false,
         type: DialogMode.New,
       } as DialogState,
+      [DialogType.QueryHistory]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
+      [DialogType.Import]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
+      [DialogType.Backup]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
+      [DialogType.Restore]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
+      [DialogType.Transfer]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
+      [DialogType.Generate]: {
+        visible: false,
+        type: DialogMode.New,
+      } as DialogState,
     } as Record<DialogType, DialogState>,
   }),
   actions: {
@@ -245,6 +321,47 @@ export const useDialogStore = defineStore('dialog', {
     closeNamespaceFinder() {
       this.hide(DialogType.NamespaceFinder)
     },
+    openQueryHistoryDialog() {
+      this.showNewDialog(DialogType.QueryHistory)
+    },
+    closeQueryHistoryDialog() {
+      this.hide(DialogType.QueryHistory)
+    },
+    openImportDialog(serverID: string, dbName: string, collectionName: string) {
+      const data: ImportDialogData = { serverID, dbName, collectionName }
+      this.showNewDialog(DialogType.Import, data)
+    },
+    closeImportDialog() {
+      this.hide(DialogType.Import)
+    },
+    openBackupDialog(serverID: string, dbName: string, collections: string[] = []) {
+      const data: BackupDialogData = { serverID, dbName, collections }
+      this.showNewDialog(DialogType.Backup, data)
+    },
+    closeBackupDialog() {
+      this.hide(DialogType.Backup)
+    },
+    openRestoreDialog(serverID: string, dbName: string = '') {
+      const data: RestoreDialogData = { serverID, dbName }
+      this.showNewDialog(DialogType.Restore, data)
+    },
+    closeRestoreDialog() {
+      this.hide(DialogType.Restore)
+    },
+    openTransferDialog(serverID: string, dbName: string, collections: string[] = []) {
+      const data: TransferDialogData = { serverID, dbName, collections }
+      this.showNewDialog(DialogType.Transfer, data)
+    },
+    closeTransferDialog() {
+      this.hide(DialogType.Transfer)
+    },
+    openGenerateDialog(serverID: string, dbName: string, collectionName: string) {
+      const data: GenerateDialogData = { serverID, dbName, collectionName }
+      this.showNewDialog(DialogType.Generate, data)
+    },
+    closeGenerateDialog() {
+      this.hide(DialogType.Generate)
+    },
   },
   getters: {
     serverDialogData(state): ServerDialogData | NewServerDialogData | undefined {
@@ -28,6 +28,11 @@ export type SchemaTabItem = {
   collectionName: string
 }
 
+export type TerminalTabItem = {
+  id: string
+  serverId: string
+}
+
 export type ServerTabItem = {
   title: string
   blank: boolean
@@ -37,6 +42,7 @@ export type ServerTabItem = {
   indexTabs?: IndexTabItem[]
   statisticsTabs?: StatisticsTabItem[]
   schemaTabs?: SchemaTabItem[]
+  terminalTabs?: TerminalTabItem[]
   innerTabOrder: string[]
   activeInnerTabId?: string
 }
@@ -2,4 +2,8 @@
 // This file is automatically generated. DO NOT EDIT
 import {api} from '../models';
 
+export function CancelExport(arg1:string):Promise<api.EmptyResult>;
+
 export function ExportResults(arg1:api.ExportRequest):Promise<api.Result_string_>;
+
+export function ExportToString(arg1:api.ExportRequest):Promise<api.Result_string_>;
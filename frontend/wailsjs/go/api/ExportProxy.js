@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelExport(arg1) {
+  return window['go']['api']['ExportProxy']['CancelExport'](arg1);
+}
+
 export function ExportResults(arg1) {
   return window['go']['api']['ExportProxy']['ExportResults'](arg1);
 }
+
+export function ExportToString(arg1) {
+  return window['go']['api']['ExportProxy']['ExportToString'](arg1);
+}
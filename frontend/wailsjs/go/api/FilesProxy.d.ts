@@ -6,6 +6,8 @@ export function ReadFile(arg1:string):Promise<api.Result_string_>;
 
 export function SaveFile(arg1:any,arg2:any,arg3:Array<api.FileFilter>):Promise<api.Result_string_>;
 
+export function SelectDirectory(arg1:string):Promise<api.Result_string_>;
+
 export function SelectFile(arg1:string,arg2:Array<api.FileFilter>):Promise<api.Result_string_>;
 
 export function WriteFile(arg1:string,arg2:string):Promise<api.EmptyResult>;
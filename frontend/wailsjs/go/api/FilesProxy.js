@@ -10,6 +10,10 @@ export function SaveFile(arg1, arg2, arg3) {
   return window['go']['api']['FilesProxy']['SaveFile'](arg1, arg2, arg3);
 }
 
+export function SelectDirectory(arg1) {
+  return window['go']['api']['FilesProxy']['SelectDirectory'](arg1);
+}
+
 export function SelectFile(arg1, arg2) {
   return window['go']['api']['FilesProxy']['SelectFile'](arg1, arg2);
 }
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AnswerPrompt(arg1, arg2, arg3) {
+  return window['go']['api']['ShellProxy']['AnswerPrompt'](arg1, arg2, arg3);
+}
+
 export function CancelQuery(arg1, arg2) {
   return window['go']['api']['ShellProxy']['CancelQuery'](arg1, arg2);
 }
@@ -14,6 +18,10 @@ export function CountForPage(arg1, arg2, arg3) {
   return window['go']['api']['ShellProxy']['CountForPage'](arg1, arg2, arg3);
 }
 
+export function DeleteDocument(arg1, arg2, arg3, arg4) {
+  return window['go']['api']['ShellProxy']['DeleteDocument'](arg1, arg2, arg3, arg4);
+}
+
 export function ExecuteQuery(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['api']['ShellProxy']['ExecuteQuery'](arg1, arg2, arg3, arg4, arg5);
 }
@@ -21,3 +29,15 @@ export function ExecuteQuery(arg1, arg2, arg3, arg4, arg5) {
 export function FetchPage(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['api']['ShellProxy']['FetchPage'](arg1, arg2, arg3, arg4, arg5);
 }
+
+export function FetchValue(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['api']['ShellProxy']['FetchValue'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function InsertDocument(arg1, arg2, arg3, arg4) {
+  return window['go']['api']['ShellProxy']['InsertDocument'](arg1, arg2, arg3, arg4);
+}
+
+export function ReplaceDocument(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['api']['ShellProxy']['ReplaceDocument'](arg1, arg2, arg3, arg4, arg5);
+}
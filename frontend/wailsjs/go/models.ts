@@ -18,6 +18,10 @@ export namespace api {
 	    separator: string;
 	    includeHeader: boolean;
 	    utf8Bom: boolean;
+	    arrays?: string;
+	    arrayDelimiter?: string;
+	    unwindPath?: string;
+	    values?: string;
 	}
 	export interface ExportRequest {
 	    format: string;
@@ -25,7 +29,24 @@ export namespace api {
 	    collectionName: string;
 	    defaultFilename: string;
 	    csv?: ExportCSVOptions;
+	    sql?: ExportSQLOptions;
 	    columns?: string[];
+	    serverId?: string;
+	    database?: string;
+	    pageContext?: models.PageContext;
+	    pipeline?: string;
+	    exportId?: string;
+	    compression?: string;
+	    split?: ExportSplitOptions;
+	    maskingProfile?: string;
+	}
+	export interface ExportSQLOptions {
+	    dialect?: string;
+	    table?: string;
+	}
+	export interface ExportSplitOptions {
+	    documents?: number;
+	    megabytes?: number;
 	}
 	export interface FileFilter {
 	    displayName: string;
@@ -61,6 +82,12 @@ export namespace api {
 	    errorCode?: string;
 	    errorDetail?: string;
 	}
+	export interface Result___vervet_internal_models_HistoryEntry_ {
+	    isSuccess: boolean;
+	    data: models.HistoryEntry[];
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
 	export interface Result___vervet_internal_models_Index_ {
 	    isSuccess: boolean;
 	    data: models.Index[];
@@ -73,6 +100,18 @@ export namespace api {
 	    errorCode?: string;
 	    errorDetail?: string;
 	}
+	export interface Result___vervet_internal_models_MaskingIssue_ {
+	    isSuccess: boolean;
+	    data: models.MaskingIssue[];
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
+	export interface Result___vervet_internal_models_TransferCheckpoint_ {
+	    isSuccess: boolean;
+	    data: models.TransferCheckpoint[];
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
 	export interface Result_bool_ {
 	    isSuccess: boolean;
 	    data: boolean;
@@ -103,6 +142,18 @@ export namespace api {
 	    errorCode?: string;
 	    errorDetail?: string;
 	}
+	export interface Result_vervet_internal_models_AuditVerification_ {
+	    isSuccess: boolean;
+	    data: models.AuditVerification;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
+	export interface Result_vervet_internal_models_BackupResult_ {
+	    isSuccess: boolean;
+	    data: models.BackupResult;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
 	export interface Result_vervet_internal_models_CollectionSchema_ {
 	    isSuccess: boolean;
 	    data: models.CollectionSchema;
@@ -121,6 +172,24 @@ export namespace api {
 	    errorCode?: string;
 	    errorDetail?: string;
 	}
+	export interface Result_vervet_internal_models_GenerateResult_ {
+	    isSuccess: boolean;
+	    data: models.GenerateResult;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
+	export interface Result_vervet_internal_models_ImportPreview_ {
+	    isSuccess: boolean;
+	    data: models.ImportPreview;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
+	export interface Result_vervet_internal_models_ImportResult_ {
+	    isSuccess: boolean;
+	    data: models.ImportResult;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
 	export interface Result_vervet_internal_models_NamespaceInventory_ {
 	    isSuccess: boolean;
 	    data: models.NamespaceInventory;
@@ -145,6 +214,12 @@ export namespace api {
 	    errorCode?: string;
 	    errorDetail?: string;
 	}
+	export interface Result_vervet_internal_models_TransferResult_ {
+	    isSuccess: boolean;
+	    data: models.TransferResult;
+	    errorCode?: string;
+	    errorDetail?: string;
+	}
 	export interface Result_vervet_internal_models_WindowState_ {
 	    isSuccess: boolean;
 	    data: models.WindowState;
@@ -180,6 +255,97 @@ export namespace api {
 
 export namespace models {
 	
+	export interface AuditVerification {
+	    entries: number;
+	    valid: boolean;
+	    brokenAt?: number;
+	    reason?: string;
+	    lastHash?: string;
+	}
+	export interface BackupCollection {
+	    namespace: string;
+	    type: string;
+	    documents: number;
+	    indexes: number;
+	    failed: number;
+	    firstError?: string;
+	}
+	export interface BackupProgress {
+	    id: string;
+	    namespace: string;
+	    documents: number;
+	    total: number;
+	    collectionsDone: number;
+	    collections: number;
+	}
+	export interface BackupResult {
+	    path: string;
+	    collections: BackupCollection[];
+	    cancelled: boolean;
+	}
+	export interface DumpRequest {
+	    dumpId: string;
+	    serverId: string;
+	    database: string;
+	    collections: string[];
+	    path: string;
+	    format: string;
+	    gzip: boolean;
+	}
+	export interface ExportProgress {
+	    exportId: string;
+	    documents: number;
+	    total: number;
+	}
+	export interface ImportColumn {
+	    field: string;
+	    type: string;
+	}
+	export interface ImportCSVOptions {
+	    separator: string;
+	    hasHeader: boolean;
+	    ignoreBlanks: boolean;
+	    columns: ImportColumn[];
+	}
+	export interface ImportRowError {
+	    row: number;
+	    error: string;
+	}
+	export interface ImportPreview {
+	    headers: string[];
+	    documents: string[];
+	    errors: ImportRowError[];
+	}
+	export interface ImportProgress {
+	    importId: string;
+	    rows: number;
+	    inserted: number;
+	    updated: number;
+	    rejected: number;
+	    bytesRead: number;
+	    totalBytes: number;
+	}
+	export interface ImportRequest {
+	    importId: string;
+	    serverId: string;
+	    database: string;
+	    collection: string;
+	    path: string;
+	    format: string;
+	    csv: ImportCSVOptions;
+	    mode: string;
+	    upsertKeys: string[];
+	    batchSize: number;
+	}
+	export interface ImportResult {
+	    rows: number;
+	    inserted: number;
+	    updated: number;
+	    rejected: number;
+	    errors: ImportRowError[];
+	    errorsTruncated: boolean;
+	    cancelled: boolean;
+	}
 	export interface TypeStat {
 	    type: string;
 	    count: number;
@@ -200,6 +366,12 @@ export namespace models {
 	    totalCount: number;
 	    fields: FieldInfo[];
 	}
+	export interface MaskingIssue {
+	    rule: number;
+	    path: string;
+	    message: string;
+	    suggestion?: string;
+	}
 	export interface Connection {
 	    serverID?: string;
 	    name?: string;
@@ -272,6 +444,28 @@ export namespace models {
 	    font: FontSettings;
 	    confirmDestructive: boolean;
 	}
+	export interface HistoryEntry {
+	    id: string;
+	    serverID: string;
+	    database: string;
+	    scriptPath?: string;
+	    query: string;
+	    queryTruncated?: boolean;
+	    engine: string;
+	    startedAt: number;
+	    durationMs: number;
+	    outcome: string;
+	    errorCode?: string;
+	    resultCount: number;
+	    resultsPaged?: boolean;
+	}
+	export interface HistoryFilter {
+	    text: string;
+	    serverID: string;
+	    from: number;
+	    to: number;
+	    limit: number;
+	}
 	export interface Index {
 	    name: string;
 	    keys: IndexKeyField[];
@@ -295,6 +489,7 @@ export namespace models {
 	}
 	
 	export interface PageContext {
+	    database?: string;
 	    collection: string;
 	    filter?: any;
 	    projection?: any;
@@ -305,6 +500,7 @@ export namespace models {
 	    userSkip?: number;
 	    maxTimeMS?: number;
 	    comment?: string;
+	    extendedJSON?: boolean;
 	}
 	export interface QueryResult {
 	    documents: any[];
@@ -317,6 +513,7 @@ export namespace models {
 	    defaultLimit: number;
 	    defaultPageSize: number;
 	    queryEngine: string;
+	    scriptSandbox: string;
 	}
 	export interface RegisteredServer {
 	    id: string;
@@ -327,6 +524,103 @@ export namespace models {
 	    isCluster: boolean;
 	    isSrv: boolean;
 	}
+	export interface RestoreRequest {
+	    restoreId: string;
+	    serverId: string;
+	    path: string;
+	    format: string;
+	    database: string;
+	    drop: boolean;
+	}
+	export interface TransferCollection {
+	    name: string;
+	    target?: string;
+	    filter?: string;
+	    pipeline?: string;
+	}
+	export interface TransferRequest {
+	    transferId: string;
+	    sourceServerId: string;
+	    sourceDatabase: string;
+	    targetServerId: string;
+	    targetDatabase: string;
+	    collections: TransferCollection[];
+	    mode: string;
+	    copyIndexes: boolean;
+	    copyOptions: boolean;
+	    batchSize: number;
+	    maskingProfile?: string;
+	}
+	export interface TransferCollectionState {
+	    name: string;
+	    prepared: boolean;
+	    done: boolean;
+	    lastId?: string;
+	    copied: number;
+	    failed: number;
+	    firstError?: string;
+	    indexes: number;
+	}
+	export interface TransferCheckpoint {
+	    request: TransferRequest;
+	    collections: TransferCollectionState[];
+	    updatedAt: string;
+	}
+	export interface TransferCollectionResult {
+	    name: string;
+	    target: string;
+	    copied: number;
+	    failed: number;
+	    firstError?: string;
+	    indexes: number;
+	}
+	export interface TransferProgress {
+	    transferId: string;
+	    collection: string;
+	    copied: number;
+	    total: number;
+	    collectionsDone: number;
+	    collections: number;
+	}
+	export interface TransferResult {
+	    collections: TransferCollectionResult[];
+	    cancelled: boolean;
+	}
+	export interface GenerateOverride {
+	    path: string;
+	    kind: string;
+	    values?: string[];
+	    min?: string;
+	    max?: string;
+	    fake?: string;
+	}
+	export interface GenerateRequest {
+	    generateId: string;
+	    serverId: string;
+	    database: string;
+	    collection: string;
+	    sampleSize: number;
+	    count: number;
+	    overrides: GenerateOverride[];
+	    seed: number;
+	    output: string;
+	    targetServerId: string;
+	    targetDatabase: string;
+	    targetCollection: string;
+	    path: string;
+	}
+	export interface GenerateProgress {
+	    generateId: string;
+	    generated: number;
+	    total: number;
+	}
+	export interface GenerateResult {
+	    generated: number;
+	    failed: number;
+	    firstError?: string;
+	    path?: string;
+	    cancelled: boolean;
+	}
 	export interface UpdatesSettings {
 	    frequency: string;
 	    lastCheckedAt: string;
@@ -347,6 +641,23 @@ export namespace models {
 	    positionX: number;
 	    positionY: number;
 	}
+	export interface AuditSettings {
+	    redaction: string;
+	}
+	export interface MaskingRule {
+	    path: string;
+	    strategy: string;
+	    kind?: string;
+	    days?: number;
+	}
+	export interface MaskingProfile {
+	    name: string;
+	    key: string;
+	    rules: MaskingRule[];
+	}
+	export interface MaskingSettings {
+	    profiles: MaskingProfile[];
+	}
 	export interface Settings {
 	    window: WindowSettings;
 	    general: GeneralSettings;
@@ -356,6 +667,8 @@ export namespace models {
 	    workspaces: WorkspacesSettings;
 	    updates: UpdatesSettings;
 	    logging: LoggingSettings;
+	    audit: AuditSettings;
+	    masking: MaskingSettings;
 	}
 	
 	
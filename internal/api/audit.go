@@ -0,0 +1,42 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type AuditProvider interface {
+	Export() (string, error)
+	Verify() (models.AuditVerification, error)
+}
+
+type AuditProxy struct {
+	log   *slog.Logger
+	audit AuditProvider
+}
+
+func NewAuditProxy(log *slog.Logger, audit AuditProvider) *AuditProxy {
+	return &AuditProxy{log: log, audit: audit}
+}
+
+// Export saves a copy of the audit log where the user picks, returning the
+// path written, or empty when they cancelled.
+func (ap *AuditProxy) Export() Result[string] {
+	path, err := ap.audit.Export()
+	if err != nil {
+		logFail(ap.log, "Export", err)
+		return FailResult[string](err)
+	}
+	return SuccessResult(path)
+}
+
+// Verify checks the audit log's hash chain is unbroken.
+func (ap *AuditProxy) Verify() Result[models.AuditVerification] {
+	result, err := ap.audit.Verify()
+	if err != nil {
+		logFail(ap.log, "Verify", err)
+		return FailResult[models.AuditVerification](err)
+	}
+	return SuccessResult(result)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAuditProvider struct {
+	err          error
+	path         string
+	verification models.AuditVerification
+}
+
+func (m *MockAuditProvider) Export() (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.path, nil
+}
+
+func (m *MockAuditProvider) Verify() (models.AuditVerification, error) {
+	if m.err != nil {
+		return models.AuditVerification{}, m.err
+	}
+	return m.verification, nil
+}
+
+func TestAuditProxy_Export(t *testing.T) {
+	t.Run("successful export", func(t *testing.T) {
+		proxy := NewAuditProxy(testLogger(), &MockAuditProvider{path: "/tmp/audit.ndjson"})
+
+		result := proxy.Export()
+
+		assert.True(t, result.IsSuccess)
+		assert.Equal(t, "/tmp/audit.ndjson", result.Data)
+	})
+
+	t.Run("export error", func(t *testing.T) {
+		proxy := NewAuditProxy(testLogger(), &MockAuditProvider{err: assert.AnError})
+
+		result := proxy.Export()
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
+
+func TestAuditProxy_Verify(t *testing.T) {
+	t.Run("successful verify", func(t *testing.T) {
+		provider := &MockAuditProvider{
+			verification: models.AuditVerification{Entries: 2, Valid: false, BrokenAt: 2},
+		}
+		proxy := NewAuditProxy(testLogger(), provider)
+
+		result := proxy.Verify()
+
+		assert.True(t, result.IsSuccess, "a broken chain is a result, not an error")
+		assert.False(t, result.Data.Valid)
+		assert.Equal(t, int64(2), result.Data.BrokenAt)
+	})
+
+	t.Run("verify error", func(t *testing.T) {
+		proxy := NewAuditProxy(testLogger(), &MockAuditProvider{err: assert.AnError})
+
+		result := proxy.Verify()
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
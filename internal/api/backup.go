@@ -0,0 +1,50 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type BackupProvider interface {
+	Dump(req models.DumpRequest) (models.BackupResult, error)
+	Restore(req models.RestoreRequest) (models.BackupResult, error)
+	Cancel(id string)
+}
+
+type BackupProxy struct {
+	log      *slog.Logger
+	provider BackupProvider
+}
+
+func NewBackupProxy(log *slog.Logger, provider BackupProvider) *BackupProxy {
+	return &BackupProxy{log: log, provider: provider}
+}
+
+// Dump writes a database, or some of its collections, in mongodump's
+// directory layout or archive format, reporting progress as backup-progress
+// events.
+func (bp *BackupProxy) Dump(req models.DumpRequest) Result[models.BackupResult] {
+	result, err := bp.provider.Dump(req)
+	if err != nil {
+		logFail(bp.log, "Dump", err)
+		return FailResult[models.BackupResult](err)
+	}
+	return SuccessResult(result)
+}
+
+// Restore loads a dump written by Dump or mongodump, reporting progress as
+// backup-progress events.
+func (bp *BackupProxy) Restore(req models.RestoreRequest) Result[models.BackupResult] {
+	result, err := bp.provider.Restore(req)
+	if err != nil {
+		logFail(bp.log, "Restore", err)
+		return FailResult[models.BackupResult](err)
+	}
+	return SuccessResult(result)
+}
+
+func (bp *BackupProxy) CancelBackup(id string) EmptyResult {
+	bp.provider.Cancel(id)
+	return Success()
+}
@@ -27,7 +27,12 @@ type ShellProvider interface {
 	ExecuteQuery(serverID, queryID, dbName, query, scriptPath string) (models.QueryResult, error)
 	FetchPage(serverID, dbName string, pc models.PageContext, page, pageSize int64) (models.QueryResult, error)
 	CountForPage(serverID, dbName string, pc models.PageContext) (count int64, estimated bool, err error)
+	FetchValue(serverID, dbName, collection, id string, path []string) (models.QueryResult, error)
+	InsertDocument(serverID, dbName, collection, document string) error
+	ReplaceDocument(serverID, dbName, collection, id, document string) error
+	DeleteDocument(serverID, dbName, collection, id string) error
 	CancelQuery(serverID, queryID string)
+	AnswerPrompt(promptID, value string, ok bool) error
 	CheckMongosh() bool
 	CloseAll()
 }
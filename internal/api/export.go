@@ -2,6 +2,8 @@ package api
 
 import (
 	"log/slog"
+
+	"vervet/internal/models"
 )
 
 // ExportCSVOptions is the CSV-specific sub-options from the frontend.
@@ -9,21 +11,63 @@ type ExportCSVOptions struct {
 	Separator     string `json:"separator"` // may be "\t" — first rune wins
 	IncludeHeader bool   `json:"includeHeader"`
 	UTF8BOM       bool   `json:"utf8Bom"`
+	// Arrays is "ejson" (the default), "indexed", "join" or "unwind".
+	Arrays         string `json:"arrays,omitempty"`
+	ArrayDelimiter string `json:"arrayDelimiter,omitempty"`
+	// UnwindPath is the array "unwind" writes a row per element of.
+	UnwindPath string `json:"unwindPath,omitempty"`
+	// Values is "relaxed" (the default), "canonical" or "plain".
+	Values string `json:"values,omitempty"`
+}
+
+// ExportSQLOptions is the SQL-specific sub-options from the frontend.
+type ExportSQLOptions struct {
+	// Dialect is "postgres" (the default), "mysql", "sqlite" or "sqlserver".
+	Dialect string `json:"dialect,omitempty"`
+	// Table names the table created; empty uses CollectionName.
+	Table string `json:"table,omitempty"`
+}
+
+// ExportSplitOptions splits a file export into numbered files.
+type ExportSplitOptions struct {
+	Documents int64 `json:"documents,omitempty"` // documents per file
+	Megabytes int64 `json:"megabytes,omitempty"` // size per file, before compression
 }
 
 // ExportRequest is the request shape sent by the frontend for an export operation.
 type ExportRequest struct {
-	Format          string            `json:"format"` // "csv" | "json" | "ndjson"
+	Format          string            `json:"format"` // "csv" | "json" | "ndjson" | "xlsx" | "markdown" | "html" | "sql"
 	EJSON           string            `json:"ejson"`
 	CollectionName  string            `json:"collectionName"`
 	DefaultFilename string            `json:"defaultFilename"`
 	CSV             *ExportCSVOptions `json:"csv,omitempty"`
+	SQL             *ExportSQLOptions `json:"sql,omitempty"`
 	Columns         []string          `json:"columns,omitempty"`
+	// Compression is "gzip" or "zstd"; empty writes the file uncompressed.
+	Compression string              `json:"compression,omitempty"`
+	Split       *ExportSplitOptions `json:"split,omitempty"`
+	// MaskingProfile names the saved masking profile to apply; empty writes
+	// the documents as they are.
+	MaskingProfile string `json:"maskingProfile,omitempty"`
+
+	// ServerID, when set, exports from the server instead of EJSON: every
+	// document PageContext's find returns, or the results of Pipeline run
+	// against CollectionName, streamed straight to the file.
+	ServerID    string              `json:"serverId,omitempty"`
+	Database    string              `json:"database,omitempty"`
+	PageContext *models.PageContext `json:"pageContext,omitempty"`
+	// Pipeline is an aggregation pipeline as an Extended JSON array.
+	Pipeline string `json:"pipeline,omitempty"`
+	// ExportID identifies a server export in progress events and to
+	// CancelExport.
+	ExportID string `json:"exportId,omitempty"`
 }
 
 // ExportProvider is the interface ExportProxy depends on.
 type ExportProvider interface {
 	Export(req ExportRequest) (string, error)
+	Render(req ExportRequest) (string, error)
+	Cancel(exportID string)
 }
 
 // ExportProxy is the Wails-bound proxy for export operations.
@@ -51,3 +95,22 @@ func (ep *ExportProxy) ExportResults(req ExportRequest) Result[string] {
 
 	return SuccessResult(path)
 }
+
+// ExportToString serializes docs and returns the result as a string, for
+// copying to the clipboard.
+func (ep *ExportProxy) ExportToString(req ExportRequest) Result[string] {
+	out, err := ep.provider.Render(req)
+	if err != nil {
+		logFail(ep.log, "ExportToString", err)
+		return FailResult[string](err)
+	}
+
+	return SuccessResult(out)
+}
+
+// CancelExport stops a running server export. The partly written file is
+// removed.
+func (ep *ExportProxy) CancelExport(exportID string) EmptyResult {
+	ep.provider.Cancel(exportID)
+	return Success()
+}
@@ -9,14 +9,24 @@ import (
 
 // mockExportProvider implements ExportProvider for tests.
 type mockExportProvider struct {
-	path string
-	err  error
+	path      string
+	rendered  string
+	err       error
+	cancelled string
 }
 
 func (m *mockExportProvider) Export(_ ExportRequest) (string, error) {
 	return m.path, m.err
 }
 
+func (m *mockExportProvider) Render(_ ExportRequest) (string, error) {
+	return m.rendered, m.err
+}
+
+func (m *mockExportProvider) Cancel(exportID string) {
+	m.cancelled = exportID
+}
+
 func TestExportProxy_ExportResults_Success(t *testing.T) {
 	provider := &mockExportProvider{path: "/tmp/results.json"}
 	proxy := NewExportProxy(testLogger(), provider)
@@ -59,3 +69,29 @@ func TestExportProxy_ExportResults_ProviderError(t *testing.T) {
 	assert.Empty(t, result.Data)
 	assert.NotEmpty(t, result.ErrorCode)
 }
+
+func TestExportProxy_ExportToString(t *testing.T) {
+	provider := &mockExportProvider{rendered: "| a |\n| --- |\n| 1 |\n"}
+	proxy := NewExportProxy(testLogger(), provider)
+
+	result := proxy.ExportToString(ExportRequest{Format: "markdown", EJSON: `[{"a":1}]`})
+
+	assert.True(t, result.IsSuccess)
+	assert.Equal(t, provider.rendered, result.Data)
+
+	provider.err = errors.New("unknown format")
+	result = proxy.ExportToString(ExportRequest{Format: "markdown", EJSON: `[{"a":1}]`})
+
+	assert.False(t, result.IsSuccess)
+	assert.NotEmpty(t, result.ErrorCode)
+}
+
+func TestExportProxy_CancelExport(t *testing.T) {
+	provider := &mockExportProvider{}
+	proxy := NewExportProxy(testLogger(), provider)
+
+	result := proxy.CancelExport("exp-1")
+
+	assert.True(t, result.IsSuccess)
+	assert.Equal(t, "exp-1", provider.cancelled)
+}
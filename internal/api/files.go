@@ -13,6 +13,7 @@ type FileFilter struct {
 type FilesProvider interface {
 	Init(ctx context.Context) error
 	SelectFile(title string, filters []FileFilter) (string, error)
+	SelectDirectory(title string) (string, error)
 	SaveFile(title *string, name *string, filters []FileFilter) (string, error)
 	ReadFile(path string) (string, error)
 	WriteFile(path string, content string) error
@@ -36,6 +37,15 @@ func (fp *FilesProxy) SelectFile(title string, filters []FileFilter) Result[stri
 	return SuccessResult(path)
 }
 
+func (fp *FilesProxy) SelectDirectory(title string) Result[string] {
+	path, err := fp.service.SelectDirectory(title)
+	if err != nil {
+		logFail(fp.log, "SelectDirectory", err)
+		return FailResult[string](err)
+	}
+	return SuccessResult(path)
+}
+
 func (fp *FilesProxy) SaveFile(title *string, defaultName *string, filters []FileFilter) Result[string] {
 	path, err := fp.service.SaveFile(title, defaultName, filters)
 	if err != nil {
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type GenerateProvider interface {
+	Generate(req models.GenerateRequest) (models.GenerateResult, error)
+	Cancel(generateID string)
+}
+
+type GenerateProxy struct {
+	log      *slog.Logger
+	provider GenerateProvider
+}
+
+func NewGenerateProxy(log *slog.Logger, provider GenerateProvider) *GenerateProxy {
+	return &GenerateProxy{log: log, provider: provider}
+}
+
+// GenerateData makes documents like those of a sampled collection and
+// inserts them into another collection or writes them to an NDJSON file,
+// reporting progress as generate-progress events.
+func (gp *GenerateProxy) GenerateData(req models.GenerateRequest) Result[models.GenerateResult] {
+	result, err := gp.provider.Generate(req)
+	if err != nil {
+		logFail(gp.log, "GenerateData", err)
+		return FailResult[models.GenerateResult](err)
+	}
+	return SuccessResult(result)
+}
+
+func (gp *GenerateProxy) CancelGenerate(generateID string) EmptyResult {
+	gp.provider.Cancel(generateID)
+	return Success()
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type HistoryProvider interface {
+	Search(filter models.HistoryFilter) ([]models.HistoryEntry, error)
+	Delete(id string) error
+	Clear() error
+}
+
+type HistoryProxy struct {
+	log     *slog.Logger
+	history HistoryProvider
+}
+
+func NewHistoryProxy(log *slog.Logger, history HistoryProvider) *HistoryProxy {
+	return &HistoryProxy{log: log, history: history}
+}
+
+// Search returns the recorded query executions filter matches, newest first.
+func (hp *HistoryProxy) Search(filter models.HistoryFilter) Result[[]models.HistoryEntry] {
+	entries, err := hp.history.Search(filter)
+	if err != nil {
+		logFail(hp.log, "Search", err)
+		return FailResult[[]models.HistoryEntry](err)
+	}
+	return SuccessResult(entries)
+}
+
+func (hp *HistoryProxy) Delete(id string) EmptyResult {
+	if err := hp.history.Delete(id); err != nil {
+		logFail(hp.log, "Delete", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
+func (hp *HistoryProxy) Clear() EmptyResult {
+	if err := hp.history.Clear(); err != nil {
+		logFail(hp.log, "Clear", err)
+		return Fail(err)
+	}
+	return Success()
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockHistoryProvider struct {
+	err     error
+	entries []models.HistoryEntry
+	filter  models.HistoryFilter
+	deleted string
+	cleared bool
+}
+
+func (m *MockHistoryProvider) Search(filter models.HistoryFilter) ([]models.HistoryEntry, error) {
+	m.filter = filter
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.entries, nil
+}
+
+func (m *MockHistoryProvider) Delete(id string) error {
+	m.deleted = id
+	return m.err
+}
+
+func (m *MockHistoryProvider) Clear() error {
+	m.cleared = true
+	return m.err
+}
+
+func TestHistoryProxy_Search(t *testing.T) {
+	t.Run("successful search", func(t *testing.T) {
+		provider := &MockHistoryProvider{
+			entries: []models.HistoryEntry{{ID: "h1", Query: "db.orders.find()"}},
+		}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Search(models.HistoryFilter{Text: "orders", ServerID: "prod"})
+
+		assert.True(t, result.IsSuccess)
+		assert.Len(t, result.Data, 1)
+		assert.Equal(t, "orders", provider.filter.Text)
+		assert.Equal(t, "prod", provider.filter.ServerID)
+	})
+
+	t.Run("search error", func(t *testing.T) {
+		provider := &MockHistoryProvider{err: assert.AnError}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Search(models.HistoryFilter{})
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
+
+func TestHistoryProxy_Delete(t *testing.T) {
+	t.Run("successful delete", func(t *testing.T) {
+		provider := &MockHistoryProvider{}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Delete("h1")
+
+		assert.True(t, result.IsSuccess)
+		assert.Equal(t, "h1", provider.deleted)
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		provider := &MockHistoryProvider{err: assert.AnError}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Delete("h1")
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
+
+func TestHistoryProxy_Clear(t *testing.T) {
+	t.Run("successful clear", func(t *testing.T) {
+		provider := &MockHistoryProvider{}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Clear()
+
+		assert.True(t, result.IsSuccess)
+		assert.True(t, provider.cleared)
+	})
+
+	t.Run("clear error", func(t *testing.T) {
+		provider := &MockHistoryProvider{err: assert.AnError}
+		proxy := NewHistoryProxy(testLogger(), provider)
+
+		result := proxy.Clear()
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
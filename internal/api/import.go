@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type ImportProvider interface {
+	Preview(req models.ImportRequest, limit int) (models.ImportPreview, error)
+	Import(req models.ImportRequest) (models.ImportResult, error)
+	Cancel(importID string)
+}
+
+type ImportProxy struct {
+	log      *slog.Logger
+	provider ImportProvider
+}
+
+func NewImportProxy(log *slog.Logger, provider ImportProvider) *ImportProxy {
+	return &ImportProxy{log: log, provider: provider}
+}
+
+// PreviewImport parses the first limit documents of the file without writing
+// anything.
+func (ip *ImportProxy) PreviewImport(req models.ImportRequest, limit int) Result[models.ImportPreview] {
+	preview, err := ip.provider.Preview(req, limit)
+	if err != nil {
+		logFail(ip.log, "PreviewImport", err)
+		return FailResult[models.ImportPreview](err)
+	}
+	return SuccessResult(preview)
+}
+
+// Import reads the file into the collection, reporting progress as
+// import-progress events.
+func (ip *ImportProxy) Import(req models.ImportRequest) Result[models.ImportResult] {
+	result, err := ip.provider.Import(req)
+	if err != nil {
+		logFail(ip.log, "Import", err)
+		return FailResult[models.ImportResult](err)
+	}
+	return SuccessResult(result)
+}
+
+func (ip *ImportProxy) CancelImport(importID string) EmptyResult {
+	ip.provider.Cancel(importID)
+	return Success()
+}
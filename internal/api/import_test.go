@@ -0,0 +1,86 @@
+package api
+
+import (
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockImportProvider struct {
+	err       error
+	preview   models.ImportPreview
+	result    models.ImportResult
+	cancelled string
+}
+
+func (m *MockImportProvider) Preview(_ models.ImportRequest, _ int) (models.ImportPreview, error) {
+	if m.err != nil {
+		return models.ImportPreview{}, m.err
+	}
+	return m.preview, nil
+}
+
+func (m *MockImportProvider) Import(_ models.ImportRequest) (models.ImportResult, error) {
+	if m.err != nil {
+		return models.ImportResult{}, m.err
+	}
+	return m.result, nil
+}
+
+func (m *MockImportProvider) Cancel(importID string) {
+	m.cancelled = importID
+}
+
+func TestImportProxy_PreviewImport(t *testing.T) {
+	t.Run("successful preview", func(t *testing.T) {
+		provider := &MockImportProvider{preview: models.ImportPreview{Headers: []string{"a"}, Documents: []string{`{"a":1}`}}}
+		proxy := NewImportProxy(testLogger(), provider)
+
+		result := proxy.PreviewImport(models.ImportRequest{}, 10)
+
+		assert.True(t, result.IsSuccess)
+		assert.Equal(t, []string{"a"}, result.Data.Headers)
+	})
+
+	t.Run("preview error", func(t *testing.T) {
+		proxy := NewImportProxy(testLogger(), &MockImportProvider{err: assert.AnError})
+
+		result := proxy.PreviewImport(models.ImportRequest{}, 10)
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
+
+func TestImportProxy_Import(t *testing.T) {
+	t.Run("successful import", func(t *testing.T) {
+		provider := &MockImportProvider{result: models.ImportResult{Rows: 3, Inserted: 2, Rejected: 1}}
+		proxy := NewImportProxy(testLogger(), provider)
+
+		result := proxy.Import(models.ImportRequest{})
+
+		assert.True(t, result.IsSuccess)
+		assert.Equal(t, int64(2), result.Data.Inserted)
+	})
+
+	t.Run("import error", func(t *testing.T) {
+		proxy := NewImportProxy(testLogger(), &MockImportProvider{err: assert.AnError})
+
+		result := proxy.Import(models.ImportRequest{})
+
+		assert.False(t, result.IsSuccess)
+		assert.NotEmpty(t, result.ErrorCode)
+	})
+}
+
+func TestImportProxy_CancelImport(t *testing.T) {
+	provider := &MockImportProvider{}
+	proxy := NewImportProxy(testLogger(), provider)
+
+	result := proxy.CancelImport("imp-1")
+
+	assert.True(t, result.IsSuccess)
+	assert.Equal(t, "imp-1", provider.cancelled)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type MaskingProvider interface {
+	Check(profile models.MaskingProfile, schema models.CollectionSchema) []models.MaskingIssue
+}
+
+type MaskingProxy struct {
+	log      *slog.Logger
+	provider MaskingProvider
+}
+
+func NewMaskingProxy(log *slog.Logger, provider MaskingProvider) *MaskingProxy {
+	return &MaskingProxy{log: log, provider: provider}
+}
+
+// CheckMaskingProfile lists what is wrong with a masking profile, including
+// rules whose paths the sampled schema does not have.
+func (mp *MaskingProxy) CheckMaskingProfile(profile models.MaskingProfile, schema models.CollectionSchema) Result[[]models.MaskingIssue] {
+	return SuccessResult(mp.provider.Check(profile, schema))
+}
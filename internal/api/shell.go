@@ -36,6 +36,16 @@ func (sp *ShellProxy) CancelQuery(serverID string, queryID string) EmptyResult {
 	return Success()
 }
 
+// AnswerPrompt replies to a prompt() or confirm() a running script is waiting
+// on. ok is false when the user dismissed the question.
+func (sp *ShellProxy) AnswerPrompt(promptID string, value string, ok bool) EmptyResult {
+	if err := sp.provider.AnswerPrompt(promptID, value, ok); err != nil {
+		logFail(sp.log, "AnswerPrompt", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
 func (sp *ShellProxy) CheckMongosh() Result[bool] {
 	return SuccessResult(sp.provider.CheckMongosh())
 }
@@ -67,3 +77,47 @@ func (sp *ShellProxy) CountForPage(serverID, dbName string, pc models.PageContex
 	}
 	return SuccessResult(CountResponse{Count: count, Estimated: estimated})
 }
+
+// FetchValue loads a value a result left out of an oversized document. id is
+// the document's _id as canonical Extended JSON and path the keys leading to
+// the value, as its marker gives them, empty for the whole document. The
+// value is the result's only document.
+func (sp *ShellProxy) FetchValue(serverID, dbName, collection, id string, path []string) Result[models.QueryResult] {
+	res, err := sp.provider.FetchValue(serverID, dbName, collection, id, path)
+	if err != nil {
+		logFail(sp.log, "FetchValue", err)
+		return FailResult[models.QueryResult](err)
+	}
+	return SuccessResult(res)
+}
+
+// InsertDocument inserts one document from the results view, written in
+// shell syntax. Like the other document edits it is audited whichever engine
+// runs the query tab's scripts.
+func (sp *ShellProxy) InsertDocument(serverID, dbName, collection, document string) EmptyResult {
+	if err := sp.provider.InsertDocument(serverID, dbName, collection, document); err != nil {
+		logFail(sp.log, "InsertDocument", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
+// ReplaceDocument replaces the document whose _id is id, in canonical
+// Extended JSON, with document, written in shell syntax.
+func (sp *ShellProxy) ReplaceDocument(serverID, dbName, collection, id, document string) EmptyResult {
+	if err := sp.provider.ReplaceDocument(serverID, dbName, collection, id, document); err != nil {
+		logFail(sp.log, "ReplaceDocument", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
+// DeleteDocument deletes the document whose _id is id, in canonical Extended
+// JSON.
+func (sp *ShellProxy) DeleteDocument(serverID, dbName, collection, id string) EmptyResult {
+	if err := sp.provider.DeleteDocument(serverID, dbName, collection, id); err != nil {
+		logFail(sp.log, "DeleteDocument", err)
+		return Fail(err)
+	}
+	return Success()
+}
@@ -13,6 +13,7 @@ type MockShellProvider struct {
 	executeErr   error
 	queryResult  models.QueryResult
 	mongoshAvail bool
+	answerErr    error
 }
 
 func (m *MockShellProvider) ExecuteQuery(serverID, queryID, dbName, query, scriptPath string) (models.QueryResult, error) {
@@ -24,6 +25,10 @@ func (m *MockShellProvider) ExecuteQuery(serverID, queryID, dbName, query, scrip
 
 func (m *MockShellProvider) CancelQuery(serverID, queryID string) {}
 
+func (m *MockShellProvider) AnswerPrompt(promptID, value string, ok bool) error {
+	return m.answerErr
+}
+
 func (m *MockShellProvider) CheckMongosh() bool {
 	return m.mongoshAvail
 }
@@ -44,6 +49,25 @@ func (m *MockShellProvider) CountForPage(serverID, dbName string, pc models.Page
 	return 0, false, nil
 }
 
+func (m *MockShellProvider) FetchValue(serverID, dbName, collection, id string, path []string) (models.QueryResult, error) {
+	if m.executeErr != nil {
+		return models.QueryResult{}, m.executeErr
+	}
+	return m.queryResult, nil
+}
+
+func (m *MockShellProvider) InsertDocument(serverID, dbName, collection, document string) error {
+	return m.executeErr
+}
+
+func (m *MockShellProvider) ReplaceDocument(serverID, dbName, collection, id, document string) error {
+	return m.executeErr
+}
+
+func (m *MockShellProvider) DeleteDocument(serverID, dbName, collection, id string) error {
+	return m.executeErr
+}
+
 func TestShellProxy_ExecuteQuery(t *testing.T) {
 	t.Run("successful query", func(t *testing.T) {
 		provider := &MockShellProvider{
@@ -75,6 +99,21 @@ func TestShellProxy_CancelQuery(t *testing.T) {
 	})
 }
 
+func TestShellProxy_AnswerPrompt(t *testing.T) {
+	t.Run("answered", func(t *testing.T) {
+		proxy := NewShellProxy(testLogger(), &MockShellProvider{})
+		result := proxy.AnswerPrompt("p1", "yes", true)
+		assert.True(t, result.IsSuccess)
+	})
+
+	t.Run("no waiting script", func(t *testing.T) {
+		provider := &MockShellProvider{answerErr: errors.New("no script is waiting")}
+		proxy := NewShellProxy(testLogger(), provider)
+		result := proxy.AnswerPrompt("p1", "yes", true)
+		assert.False(t, result.IsSuccess)
+	})
+}
+
 func TestShellProxy_FetchPage(t *testing.T) {
 	t.Run("successful page", func(t *testing.T) {
 		provider := &MockShellProvider{
@@ -110,6 +149,42 @@ func TestShellProxy_CountForPage(t *testing.T) {
 	})
 }
 
+func TestShellProxy_FetchValue(t *testing.T) {
+	t.Run("successful fetch", func(t *testing.T) {
+		provider := &MockShellProvider{
+			queryResult: models.QueryResult{Documents: []any{"a long string"}, Single: true},
+		}
+		proxy := NewShellProxy(testLogger(), provider)
+		result := proxy.FetchValue("1", "db1", "c", `{"$oid":"650000000000000000000001"}`, []string{"body"})
+		assert.True(t, result.IsSuccess)
+		assert.Equal(t, []any{"a long string"}, result.Data.Documents)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		provider := &MockShellProvider{executeErr: errors.New("boom")}
+		proxy := NewShellProxy(testLogger(), provider)
+		result := proxy.FetchValue("1", "db1", "c", `1`, []string{"body"})
+		assert.False(t, result.IsSuccess)
+	})
+}
+
+func TestShellProxy_DocumentEdits(t *testing.T) {
+	id := `{"$oid":"650000000000000000000001"}`
+	edits := map[string]func(*ShellProxy) EmptyResult{
+		"insert":  func(p *ShellProxy) EmptyResult { return p.InsertDocument("1", "db1", "c", `{ a: 1 }`) },
+		"replace": func(p *ShellProxy) EmptyResult { return p.ReplaceDocument("1", "db1", "c", id, `{ a: 2 }`) },
+		"delete":  func(p *ShellProxy) EmptyResult { return p.DeleteDocument("1", "db1", "c", id) },
+	}
+	for name, edit := range edits {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, edit(NewShellProxy(testLogger(), &MockShellProvider{})).IsSuccess)
+
+			failing := &MockShellProvider{executeErr: errors.New("not authorized")}
+			assert.False(t, edit(NewShellProxy(testLogger(), failing)).IsSuccess)
+		})
+	}
+}
+
 func TestShellProxy_CheckMongosh(t *testing.T) {
 	t.Run("mongosh available", func(t *testing.T) {
 		provider := &MockShellProvider{mongoshAvail: true}
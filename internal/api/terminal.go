@@ -0,0 +1,62 @@
+package api
+
+import (
+	"log/slog"
+)
+
+// TerminalProxy exposes interactive mongosh sessions. A session's output and
+// exit arrive as terminal-output and terminal-exit events.
+type TerminalProxy struct {
+	log      *slog.Logger
+	provider TerminalProvider
+}
+
+type TerminalProvider interface {
+	Open(serverID string, cols, rows uint16) (string, error)
+	Write(sessionID, data string) error
+	Resize(sessionID string, cols, rows uint16) error
+	Close(sessionID string) error
+}
+
+func NewTerminalProxy(log *slog.Logger, provider TerminalProvider) *TerminalProxy {
+	return &TerminalProxy{
+		log:      log,
+		provider: provider,
+	}
+}
+
+// Open starts mongosh for a connected server in a terminal cols wide and
+// rows high, and returns the session's ID.
+func (p *TerminalProxy) Open(serverID string, cols uint16, rows uint16) Result[string] {
+	sessionID, err := p.provider.Open(serverID, cols, rows)
+	if err != nil {
+		logFail(p.log, "Open", err)
+		return FailResult[string](err)
+	}
+	return SuccessResult(sessionID)
+}
+
+// Write sends keystrokes to a session.
+func (p *TerminalProxy) Write(sessionID string, data string) EmptyResult {
+	if err := p.provider.Write(sessionID, data); err != nil {
+		logFail(p.log, "Write", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
+func (p *TerminalProxy) Resize(sessionID string, cols uint16, rows uint16) EmptyResult {
+	if err := p.provider.Resize(sessionID, cols, rows); err != nil {
+		logFail(p.log, "Resize", err)
+		return Fail(err)
+	}
+	return Success()
+}
+
+func (p *TerminalProxy) Close(sessionID string) EmptyResult {
+	if err := p.provider.Close(sessionID); err != nil {
+		logFail(p.log, "Close", err)
+		return Fail(err)
+	}
+	return Success()
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockTerminalProvider struct {
+	openErr   error
+	writeErr  error
+	resizeErr error
+	closeErr  error
+	written   string
+}
+
+func (m *MockTerminalProvider) Open(serverID string, cols, rows uint16) (string, error) {
+	if m.openErr != nil {
+		return "", m.openErr
+	}
+	return "session-1", nil
+}
+
+func (m *MockTerminalProvider) Write(sessionID, data string) error {
+	m.written += data
+	return m.writeErr
+}
+
+func (m *MockTerminalProvider) Resize(sessionID string, cols, rows uint16) error {
+	return m.resizeErr
+}
+
+func (m *MockTerminalProvider) Close(sessionID string) error {
+	return m.closeErr
+}
+
+func TestTerminalProxy_Open(t *testing.T) {
+	log := slog.New(slog.Default().Handler())
+
+	proxy := NewTerminalProxy(log, &MockTerminalProvider{})
+	result := proxy.Open("srv", 80, 24)
+	assert.True(t, result.IsSuccess)
+	assert.Equal(t, "session-1", result.Data)
+
+	proxy = NewTerminalProxy(log, &MockTerminalProvider{openErr: errors.New("not connected to server srv")})
+	result = proxy.Open("srv", 80, 24)
+	assert.False(t, result.IsSuccess)
+	assert.Contains(t, result.ErrorDetail, "not connected")
+}
+
+func TestTerminalProxy_WriteResizeClose(t *testing.T) {
+	log := slog.New(slog.Default().Handler())
+
+	provider := &MockTerminalProvider{}
+	proxy := NewTerminalProxy(log, provider)
+	assert.True(t, proxy.Write("session-1", "show dbs\r").IsSuccess)
+	assert.Equal(t, "show dbs\r", provider.written)
+	assert.True(t, proxy.Resize("session-1", 100, 40).IsSuccess)
+	assert.True(t, proxy.Close("session-1").IsSuccess)
+
+	failing := errors.New("no terminal session session-1")
+	proxy = NewTerminalProxy(log, &MockTerminalProvider{writeErr: failing, resizeErr: failing, closeErr: failing})
+	assert.False(t, proxy.Write("session-1", "x").IsSuccess)
+	assert.False(t, proxy.Resize("session-1", 100, 40).IsSuccess)
+	assert.False(t, proxy.Close("session-1").IsSuccess)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+type TransferProvider interface {
+	Transfer(req models.TransferRequest) (models.TransferResult, error)
+	Resume(transferID string) (models.TransferResult, error)
+	Cancel(transferID string)
+	Interrupted() ([]models.TransferCheckpoint, error)
+	Discard(transferID string) error
+}
+
+type TransferProxy struct {
+	log      *slog.Logger
+	provider TransferProvider
+}
+
+func NewTransferProxy(log *slog.Logger, provider TransferProvider) *TransferProxy {
+	return &TransferProxy{log: log, provider: provider}
+}
+
+// Transfer copies collections from one server and database to another,
+// reporting progress as transfer-progress events.
+func (tp *TransferProxy) Transfer(req models.TransferRequest) Result[models.TransferResult] {
+	result, err := tp.provider.Transfer(req)
+	if err != nil {
+		logFail(tp.log, "Transfer", err)
+		return FailResult[models.TransferResult](err)
+	}
+	return SuccessResult(result)
+}
+
+// ResumeTransfer carries on a cancelled or failed transfer from where it
+// stopped.
+func (tp *TransferProxy) ResumeTransfer(transferID string) Result[models.TransferResult] {
+	result, err := tp.provider.Resume(transferID)
+	if err != nil {
+		logFail(tp.log, "ResumeTransfer", err)
+		return FailResult[models.TransferResult](err)
+	}
+	return SuccessResult(result)
+}
+
+func (tp *TransferProxy) CancelTransfer(transferID string) EmptyResult {
+	tp.provider.Cancel(transferID)
+	return Success()
+}
+
+func (tp *TransferProxy) ListInterruptedTransfers() Result[[]models.TransferCheckpoint] {
+	checkpoints, err := tp.provider.Interrupted()
+	if err != nil {
+		logFail(tp.log, "ListInterruptedTransfers", err)
+		return FailResult[[]models.TransferCheckpoint](err)
+	}
+	return SuccessResult(checkpoints)
+}
+
+func (tp *TransferProxy) DiscardTransfer(transferID string) EmptyResult {
+	if err := tp.provider.Discard(transferID); err != nil {
+		logFail(tp.log, "DiscardTransfer", err)
+		return Fail(err)
+	}
+	return Success()
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"vervet/internal/api"
+	"vervet/internal/audit"
+	"vervet/internal/backup"
 	"vervet/internal/clientregistry"
 	"vervet/internal/collections"
 	"vervet/internal/connectionStrings"
@@ -15,13 +17,19 @@ import (
 	"vervet/internal/databases"
 	"vervet/internal/export"
 	"vervet/internal/files"
+	"vervet/internal/generator"
+	"vervet/internal/history"
+	"vervet/internal/importer"
 	"vervet/internal/indexes"
+	"vervet/internal/masking"
 	"vervet/internal/models"
 	"vervet/internal/oidc"
 	"vervet/internal/queryexecutor"
 	"vervet/internal/servers"
 	"vervet/internal/settings"
 	"vervet/internal/system"
+	"vervet/internal/terminals"
+	"vervet/internal/transfer"
 	"vervet/internal/updates"
 	"vervet/internal/workspaces"
 
@@ -62,6 +70,14 @@ type App struct {
 	UpdatesProxy     *api.UpdatesProxy
 	ExportProxy      *api.ExportProxy
 	OIDCProxy        *api.OIDCProxy
+	TerminalProxy    *api.TerminalProxy
+	HistoryProxy     *api.HistoryProxy
+	AuditProxy       *api.AuditProxy
+	ImportProxy      *api.ImportProxy
+	BackupProxy      *api.BackupProxy
+	TransferProxy    *api.TransferProxy
+	MaskingProxy     *api.MaskingProxy
+	GenerateProxy    *api.GenerateProxy
 
 	serverService      *servers.ServerService
 	registry           *clientregistry.ClientRegistry
@@ -70,11 +86,17 @@ type App struct {
 	indexService       *indexes.IndexService
 	collectionsService *collections.CollectionsService
 	queryExecutor      *queryexecutor.QueryExecutor
+	terminalManager    *terminals.Manager
+	historyService     *history.Service
 	tokenManager       *oidc.TokenManager
 	settingsService    settings.Service
 	systemService      *system.Service
 	filesService       *files.Service
 	exportService      *export.Service
+	importService      *importer.Service
+	backupService      *backup.Service
+	transferService    *transfer.Service
+	generatorService   *generator.Service
 	updatesService     *updates.Service
 	updatesEmitter     *updates.WailsEmitter
 	updatesOpener      *updates.BrowserOpener
@@ -104,17 +126,51 @@ func NewApp(log *slog.Logger, settingsService settings.Service, version string)
 		Settings:       updates.NewSettingsAdapter(settingsService),
 		Emitter:        updatesEmitter,
 	})
-	queryExecutor := queryexecutor.NewQueryExecutor(log, registry, connectionStringsStore, settingsService)
-	systemService := system.NewSystemService(log)
-	fontService := system.NewFontService(log)
-	filesService := files.NewService(log)
-	exportService := export.NewService(log, &filesServiceSaveDialogAdapter{svc: filesService})
 	workspaceStore, err := workspaces.NewStore(log)
 	if err != nil {
 		log.Error("Failed to initialize workspace store", slog.Any("error", err))
 		panic(fmt.Errorf("failed to initialize workspace store: %w", err))
 	}
 	workspaceService := workspaces.NewService(log, workspaceStore)
+	historyStore, err := history.NewStore(log)
+	if err != nil {
+		log.Error("Failed to initialize history store", slog.Any("error", err))
+		panic(fmt.Errorf("failed to initialize history store: %w", err))
+	}
+	historyService := history.NewService(log, historyStore)
+	queryExecutor := queryexecutor.NewQueryExecutor(log, registry, connectionStringsStore, settingsService, workspaceService, serverService, connectionManager)
+	queryExecutor.SetHistory(historyService)
+	terminalManager := terminals.NewManager(log, registry, connectionStringsStore)
+	systemService := system.NewSystemService(log)
+	fontService := system.NewFontService(log)
+	filesService := files.NewService(log)
+	exportService := export.NewService(log, &filesServiceSaveDialogAdapter{svc: filesService}, queryExecutor)
+	auditFile, err := audit.NewFile()
+	if err != nil {
+		log.Error("Failed to initialize audit log", slog.Any("error", err))
+		panic(fmt.Errorf("failed to initialize audit log: %w", err))
+	}
+	auditService := audit.NewService(log, auditFile, settingsService, serverService, filesService)
+	queryExecutor.SetAuditor(auditService)
+	databasesService.SetAuditor(auditService)
+	collectionsService.SetAuditor(auditService)
+	indexService.SetAuditor(auditService)
+	importService := importer.NewService(log, registry)
+	importService.SetAuditor(auditService)
+	backupService := backup.NewService(log, registry)
+	backupService.SetAuditor(auditService)
+	transferStore, err := transfer.NewStore(log)
+	if err != nil {
+		log.Error("Failed to initialize transfer store", slog.Any("error", err))
+		panic(fmt.Errorf("failed to initialize transfer store: %w", err))
+	}
+	transferService := transfer.NewService(log, registry, transferStore)
+	transferService.SetAuditor(auditService)
+	maskingService := masking.NewService(log, settingsService)
+	exportService.SetMasking(maskingService)
+	transferService.SetMasking(maskingService)
+	generatorService := generator.NewService(log, registry)
+	generatorService.SetAuditor(auditService)
 
 	return &App{
 		log:                log,
@@ -125,11 +181,17 @@ func NewApp(log *slog.Logger, settingsService settings.Service, version string)
 		indexService:       indexService,
 		collectionsService: collectionsService,
 		queryExecutor:      queryExecutor,
+		terminalManager:    terminalManager,
+		historyService:     historyService,
 		tokenManager:       tokenManager,
 		settingsService:    settingsService,
 		systemService:      systemService,
 		filesService:       filesService,
 		exportService:      exportService,
+		importService:      importService,
+		backupService:      backupService,
+		transferService:    transferService,
+		generatorService:   generatorService,
 		ServersProxy:       api.NewServersProxy(log, serverService),
 		ConnectionsProxy:   api.NewConnectionsProxy(log, connectionManager),
 		DatabasesProxy:     api.NewDatabasesProxy(log, databasesService),
@@ -142,6 +204,14 @@ func NewApp(log *slog.Logger, settingsService settings.Service, version string)
 		WorkspacesProxy:    api.NewWorkspacesProxy(log, workspaceService, settingsService),
 		ExportProxy:        api.NewExportProxy(log, exportService),
 		OIDCProxy:          api.NewOIDCProxy(log, tokenManager),
+		TerminalProxy:      api.NewTerminalProxy(log, terminalManager),
+		HistoryProxy:       api.NewHistoryProxy(log, historyService),
+		AuditProxy:         api.NewAuditProxy(log, auditService),
+		ImportProxy:        api.NewImportProxy(log, importService),
+		BackupProxy:        api.NewBackupProxy(log, backupService),
+		TransferProxy:      api.NewTransferProxy(log, transferService),
+		MaskingProxy:       api.NewMaskingProxy(log, maskingService),
+		GenerateProxy:      api.NewGenerateProxy(log, generatorService),
 		UpdatesProxy:       api.NewUpdatesProxy(log, updatesService, updatesOpener),
 		appVersion:         version,
 		updatesService:     updatesService,
@@ -179,6 +249,7 @@ func (a *App) Startup(ctx context.Context) {
 	a.indexService.Init(ctx)
 	a.collectionsService.Init(ctx)
 	a.queryExecutor.Init(ctx)
+	a.terminalManager.Init(ctx)
 
 	err = a.settingsService.Init(ctx)
 	if err != nil {
@@ -188,6 +259,10 @@ func (a *App) Startup(ctx context.Context) {
 
 	a.filesService.Init(ctx)
 	a.exportService.Init(ctx)
+	a.importService.Init(ctx)
+	a.backupService.Init(ctx)
+	a.transferService.Init(ctx)
+	a.generatorService.Init(ctx)
 	a.systemService.Init(ctx)
 	a.WorkspacesProxy.Init(ctx)
 
@@ -266,6 +341,14 @@ func (a *App) Shutdown(ctx context.Context) {
 	// Cancel any in-flight queries
 	a.queryExecutor.CloseAll()
 
+	// End any open mongosh terminals
+	a.terminalManager.CloseAll()
+
+	// Write the query history, including the queries just cancelled
+	if err := a.historyService.Flush(); err != nil {
+		a.log.Error("Failed to save query history", slog.Any("error", err))
+	}
+
 	// Disconnect all MongoDB connections
 	err := a.connectionManager.DisconnectAll()
 	if err != nil {
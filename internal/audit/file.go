@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"vervet/internal/infrastructure"
+)
+
+// LogFile is where the audit log lives. It is only ever appended to: unlike
+// the app's other stores, it is never rewritten.
+type LogFile interface {
+	// Open reads the log from the start. A log that does not exist yet reads
+	// as empty.
+	Open() (io.ReadCloser, error)
+	// Append writes line to the end of the log and syncs it to disk.
+	Append(line []byte) error
+}
+
+type file struct {
+	path string
+}
+
+// NewFile returns the log kept in audit.ndjson in the config directory.
+func NewFile() (LogFile, error) {
+	path, err := infrastructure.ConfigPath("audit.ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("error locating audit log: %w", err)
+	}
+	return &file{path: path}, nil
+}
+
+func (f *file) Open() (io.ReadCloser, error) {
+	r, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	return r, nil
+}
+
+func (f *file) Append(line []byte) error {
+	w, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	if _, err := w.Write(line); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	if err := w.Sync(); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return w.Close()
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"log/slog"
+
+	"vervet/internal/models"
+)
+
+// Recorder is where a service records the changes it makes; *Service is one.
+type Recorder interface {
+	Record(action models.AuditAction) error
+}
+
+// RecordOrLog records action on r, marking it failed with err when err is
+// not nil. A nil r records nothing. By the time a change is audited it has
+// already been made, so an entry that cannot be written is logged rather
+// than returned.
+func RecordOrLog(log *slog.Logger, r Recorder, action models.AuditAction, err error) {
+	if r == nil {
+		return
+	}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	if err := r.Record(action); err != nil {
+		log.Error("Failed to record audit entry", slog.String("operation", action.Operation), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"vervet/internal/models"
+)
+
+// redactedValue replaces every value in arguments redacted with
+// AuditRedactValues.
+const redactedValue = `"<redacted>"`
+
+// encodeArguments encodes an action's arguments as relaxed Extended JSON and
+// redacts them per mode. Nil arguments, or mode AuditRedactAll, give nil.
+func encodeArguments(args any, mode string) (json.RawMessage, error) {
+	if args == nil || mode == models.AuditRedactAll {
+		return nil, nil
+	}
+	raw, err := extJSON(args)
+	if err != nil {
+		return nil, err
+	}
+	if mode == models.AuditRedactNone {
+		return raw, nil
+	}
+	return redactValues(raw)
+}
+
+// extJSON encodes v, which may be any value a BSON document can hold, as
+// relaxed Extended JSON. Wrapping it in a document lets arrays and scalars
+// through as well as documents, with key order kept.
+func extJSON(v any) (json.RawMessage, error) {
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audit arguments: %w", err)
+	}
+	var wrapper struct {
+		V json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("error encoding audit arguments: %w", err)
+	}
+	return wrapper.V, nil
+}
+
+// redactValues copies raw with every string, number, boolean and null
+// replaced, keeping object keys, their order, and array lengths. Keys are
+// what operators and field names are written as, so the shape of a filter or
+// update survives: {"age": {"$gt": "<redacted>"}}.
+func redactValues(raw json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	var buf bytes.Buffer
+	if err := redactNext(dec, &buf); err != nil {
+		return nil, fmt.Errorf("error redacting audit arguments: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func redactNext(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		buf.WriteString(redactedValue)
+		return nil
+	}
+	switch delim {
+	case '{':
+		buf.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			k, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.Write(k)
+			buf.WriteByte(':')
+			if err := redactNext(dec, buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case '[':
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := redactNext(dec, buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	}
+	// The closing delimiter.
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,298 @@
+// Package audit keeps an append-only, hash-chained log of every change
+// Vervet makes to a server, for reviewing who changed what and proving the
+// log has not been edited since.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"vervet/internal/api"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+)
+
+// genesisHash is the PrevHash of the first entry in the log.
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// maxArgumentBytes is the most encoded arguments one entry records. An
+// insertMany of a large batch is logged with its size instead.
+const maxArgumentBytes = 64 << 10
+
+// SettingsProvider gives the service the redaction setting.
+type SettingsProvider interface {
+	GetSettings() (models.Settings, error)
+}
+
+// ServerDirectory names the servers entries are recorded against.
+type ServerDirectory interface {
+	GetServers() ([]models.RegisteredServer, error)
+}
+
+// SaveDialog asks where to export the log to.
+type SaveDialog interface {
+	SaveFile(title *string, name *string, filters []api.FileFilter) (string, error)
+}
+
+type Service struct {
+	log      *slog.Logger
+	file     LogFile
+	settings SettingsProvider
+	servers  ServerDirectory
+	dialog   SaveDialog
+	now      func() time.Time
+
+	mu sync.Mutex
+	// seq and lastHash continue the chain from the newest entry, once loaded.
+	seq      int64
+	lastHash string
+	loaded   bool
+}
+
+func NewService(log *slog.Logger, file LogFile, settings SettingsProvider, servers ServerDirectory, dialog SaveDialog) *Service {
+	return &Service{
+		log:      log.With(slog.String(logging.SourceKey, "AuditService")),
+		file:     file,
+		settings: settings,
+		servers:  servers,
+		dialog:   dialog,
+		now:      time.Now,
+	}
+}
+
+// Record appends action to the log, chained to the entry before it.
+func (s *Service) Record(action models.AuditAction) error {
+	entry := models.AuditEntry{
+		Time:          s.now().UTC().Format(time.RFC3339Nano),
+		ServerID:      action.ServerID,
+		ServerName:    s.serverName(action.ServerID),
+		Namespace:     namespace(action.Database, action.Collection),
+		Operation:     action.Operation,
+		Source:        action.Source,
+		Target:        action.Target,
+		AffectedCount: action.AffectedCount,
+		Error:         action.Error,
+	}
+	args, err := encodeArguments(action.Arguments, s.redaction())
+	if err != nil {
+		// Still record that the action happened.
+		s.log.Warn("Failed to encode audit arguments", slog.String("operation", action.Operation), slog.Any("error", err))
+	}
+	if len(args) > maxArgumentBytes {
+		entry.ArgumentsSize = len(args)
+		args = nil
+	}
+	entry.Arguments = args
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	entry.Seq = s.seq + 1
+	entry.PrevHash = s.lastHash
+	entry.Hash, err = hashEntry(entry)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding audit entry: %w", err)
+	}
+	if err := s.file.Append(append(line, '\n')); err != nil {
+		return err
+	}
+	s.seq = entry.Seq
+	s.lastHash = entry.Hash
+	return nil
+}
+
+// redaction is the configured redaction mode. Settings that cannot be read
+// redact values, the default.
+func (s *Service) redaction() string {
+	cfg, err := s.settings.GetSettings()
+	if err != nil {
+		return models.AuditRedactValues
+	}
+	cfg.Audit.Normalize()
+	return cfg.Audit.Redaction
+}
+
+// serverName is the registered name of serverID, or empty when it cannot be
+// found; the entry keeps the ID either way.
+func (s *Service) serverName(serverID string) string {
+	servers, err := s.servers.GetServers()
+	if err != nil {
+		return ""
+	}
+	for _, server := range servers {
+		if server.ID == serverID {
+			return server.Name
+		}
+	}
+	return ""
+}
+
+func namespace(db, coll string) string {
+	if coll == "" {
+		return db
+	}
+	return db + "." + coll
+}
+
+// hashEntry is the SHA-256 of entry's PrevHash and its JSON encoding without
+// a Hash, hex-encoded.
+func hashEntry(entry models.AuditEntry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("error hashing audit entry: %w", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(entry.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// load finds where the chain continues from the first time an entry is
+// written. Callers must hold s.mu.
+func (s *Service) load() error {
+	if s.loaded {
+		return nil
+	}
+	s.seq = 0
+	s.lastHash = genesisHash
+	err := s.scan(func(_ int64, entry models.AuditEntry, err error) bool {
+		// Continue from the newest readable entry. A damaged log still takes
+		// new entries; Verify reports where it is damaged.
+		if err == nil {
+			s.seq = entry.Seq
+			s.lastHash = entry.Hash
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	s.loaded = true
+	return nil
+}
+
+// scan calls fn with each line of the log, numbered from 1, until fn returns
+// false. err is set for a line that is not an entry. Callers must hold s.mu.
+func (s *Service) scan(fn func(line int64, entry models.AuditEntry, err error) bool) error {
+	r, err := s.file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	for n := int64(1); ; n++ {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("error reading audit log: %w", readErr)
+		}
+		line = bytes.TrimRight(line, "\n")
+		if len(line) > 0 {
+			var entry models.AuditEntry
+			err := json.Unmarshal(line, &entry)
+			if !fn(n, entry, err) {
+				return nil
+			}
+		}
+		if readErr != nil {
+			return nil
+		}
+	}
+}
+
+// Verify walks the log and checks every entry follows the one before it:
+// numbered one higher, carrying its hash, and hashing to its own Hash.
+func (s *Service) Verify() (models.AuditVerification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := models.AuditVerification{Valid: true}
+	prevHash := genesisHash
+	err := s.scan(func(line int64, entry models.AuditEntry, err error) bool {
+		reason := ""
+		switch {
+		case err != nil:
+			reason = "not a valid audit entry"
+		case entry.Seq != result.Entries+1:
+			reason = fmt.Sprintf("expected entry %d, found %d", result.Entries+1, entry.Seq)
+		case entry.PrevHash != prevHash:
+			reason = "does not follow the entry before it"
+		default:
+			if hash, err := hashEntry(entry); err != nil || hash != entry.Hash {
+				reason = "has been changed since it was written"
+			}
+		}
+		if reason != "" {
+			result.Valid = false
+			result.BrokenAt = line
+			result.Reason = reason
+			return false
+		}
+		result.Entries++
+		prevHash = entry.Hash
+		return true
+	})
+	if err != nil {
+		return models.AuditVerification{}, err
+	}
+	if result.Valid && result.Entries > 0 {
+		result.LastHash = prevHash
+	}
+	return result, nil
+}
+
+// Export copies the log, as it is, to a file the user picks. It returns the
+// path written, empty when the user cancelled.
+func (s *Service) Export() (string, error) {
+	title := "Export audit log"
+	name := fmt.Sprintf("vervet-audit-%s.ndjson", s.now().Format("2006-01-02"))
+	path, err := s.dialog.SaveFile(&title, &name, []api.FileFilter{
+		{DisplayName: "NDJSON (*.ndjson)", Pattern: "*.ndjson"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error exporting audit log: %w", err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("error exporting audit log: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("error exporting audit log: %w", err)
+	}
+	return path, nil
+}
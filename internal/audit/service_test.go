@@ -0,0 +1,348 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"vervet/internal/api"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type memoryFile struct {
+	data bytes.Buffer
+}
+
+func (f *memoryFile) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.data.Bytes())), nil
+}
+
+func (f *memoryFile) Append(line []byte) error {
+	f.data.Write(line)
+	return nil
+}
+
+func (f *memoryFile) lines() []string {
+	return strings.Split(strings.TrimSuffix(f.data.String(), "\n"), "\n")
+}
+
+func (f *memoryFile) setLines(lines []string) {
+	f.data.Reset()
+	f.data.WriteString(strings.Join(lines, "\n") + "\n")
+}
+
+type settingsStub struct {
+	redaction string
+}
+
+func (s *settingsStub) GetSettings() (models.Settings, error) {
+	return models.Settings{Audit: models.AuditSettings{Redaction: s.redaction}}, nil
+}
+
+type serversStub struct{}
+
+func (serversStub) GetServers() ([]models.RegisteredServer, error) {
+	return []models.RegisteredServer{{ID: "srv-1", Name: "Production"}}, nil
+}
+
+type dialogStub struct {
+	path string
+	err  error
+}
+
+func (d *dialogStub) SaveFile(_ *string, _ *string, _ []api.FileFilter) (string, error) {
+	return d.path, d.err
+}
+
+func newTestService(redaction string) (*Service, *memoryFile) {
+	file := &memoryFile{}
+	svc := NewService(slog.Default(), file, &settingsStub{redaction: redaction}, serversStub{}, &dialogStub{})
+	svc.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return svc, file
+}
+
+func decodeEntries(t *testing.T, file *memoryFile) []models.AuditEntry {
+	t.Helper()
+	var entries []models.AuditEntry
+	for _, line := range file.lines() {
+		var e models.AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func updateAction() models.AuditAction {
+	return models.AuditAction{
+		ServerID:   "srv-1",
+		Database:   "shop",
+		Collection: "orders",
+		Operation:  "updateMany",
+		Source:     models.AuditSourceScript,
+		Arguments: bson.A{
+			bson.D{{Key: "status", Value: "open"}, {Key: "total", Value: bson.D{{Key: "$gt", Value: int32(100)}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "closed"}}}},
+		},
+		AffectedCount: 3,
+	}
+}
+
+func TestRecord_WritesChainedEntries(t *testing.T) {
+	svc, file := newTestService(models.AuditRedactNone)
+
+	require.NoError(t, svc.Record(updateAction()))
+	require.NoError(t, svc.Record(models.AuditAction{
+		ServerID:  "srv-1",
+		Database:  "shop",
+		Operation: "dropDatabase",
+		Source:    models.AuditSourceApp,
+	}))
+
+	entries := decodeEntries(t, file)
+	require.Len(t, entries, 2)
+
+	first := entries[0]
+	assert.Equal(t, int64(1), first.Seq)
+	assert.Equal(t, "2026-03-01T12:00:00Z", first.Time)
+	assert.Equal(t, "Production", first.ServerName)
+	assert.Equal(t, "shop.orders", first.Namespace)
+	assert.Equal(t, "updateMany", first.Operation)
+	assert.Equal(t, int64(3), first.AffectedCount)
+	assert.Equal(t, genesisHash, first.PrevHash)
+	assert.JSONEq(t, `[{"status":"open","total":{"$gt":100}},{"$set":{"status":"closed"}}]`, string(first.Arguments))
+
+	second := entries[1]
+	assert.Equal(t, int64(2), second.Seq)
+	assert.Equal(t, "shop", second.Namespace)
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.NotEqual(t, first.Hash, second.Hash)
+}
+
+func TestRecord_Redaction(t *testing.T) {
+	t.Run("values keeps keys and operators", func(t *testing.T) {
+		svc, file := newTestService(models.AuditRedactValues)
+		require.NoError(t, svc.Record(updateAction()))
+
+		args := string(decodeEntries(t, file)[0].Arguments)
+		assert.JSONEq(t,
+			`[{"status":"<redacted>","total":{"$gt":"<redacted>"}},{"$set":{"status":"<redacted>"}}]`,
+			args)
+	})
+
+	t.Run("all leaves arguments out", func(t *testing.T) {
+		svc, file := newTestService(models.AuditRedactAll)
+		require.NoError(t, svc.Record(updateAction()))
+
+		entry := decodeEntries(t, file)[0]
+		assert.Nil(t, entry.Arguments)
+		assert.Equal(t, int64(3), entry.AffectedCount)
+	})
+
+	t.Run("unknown setting redacts values", func(t *testing.T) {
+		svc, file := newTestService("")
+		require.NoError(t, svc.Record(updateAction()))
+
+		assert.NotContains(t, file.data.String(), "closed")
+	})
+}
+
+func TestRecord_LargeArgumentsAreLeftOut(t *testing.T) {
+	svc, file := newTestService(models.AuditRedactNone)
+	action := updateAction()
+	action.Operation = "insertOne"
+	action.Arguments = bson.A{bson.D{{Key: "blob", Value: strings.Repeat("x", maxArgumentBytes)}}}
+
+	require.NoError(t, svc.Record(action))
+
+	entry := decodeEntries(t, file)[0]
+	assert.Nil(t, entry.Arguments)
+	assert.Greater(t, entry.ArgumentsSize, maxArgumentBytes)
+}
+
+func TestRecord_ContinuesAnExistingLog(t *testing.T) {
+	svc, file := newTestService(models.AuditRedactValues)
+	require.NoError(t, svc.Record(updateAction()))
+
+	// A new session starts from what is on disk.
+	next := NewService(slog.Default(), file, &settingsStub{}, serversStub{}, &dialogStub{})
+	require.NoError(t, next.Record(updateAction()))
+
+	entries := decodeEntries(t, file)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(2), entries[1].Seq)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+
+	result, err := next.Verify()
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
+
+func TestVerify(t *testing.T) {
+	record := func(t *testing.T, n int) (*Service, *memoryFile) {
+		svc, file := newTestService(models.AuditRedactNone)
+		for range n {
+			require.NoError(t, svc.Record(updateAction()))
+		}
+		return svc, file
+	}
+
+	t.Run("empty log", func(t *testing.T) {
+		svc, _ := newTestService(models.AuditRedactNone)
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditVerification{Valid: true}, result)
+	})
+
+	t.Run("intact log", func(t *testing.T) {
+		svc, file := record(t, 3)
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(3), result.Entries)
+		assert.Equal(t, decodeEntries(t, file)[2].Hash, result.LastHash)
+	})
+
+	t.Run("changed entry", func(t *testing.T) {
+		svc, file := record(t, 3)
+		lines := file.lines()
+		lines[1] = strings.Replace(lines[1], `"affectedCount":3`, `"affectedCount":0`, 1)
+		file.setLines(lines)
+
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.BrokenAt)
+		assert.Equal(t, "has been changed since it was written", result.Reason)
+	})
+
+	t.Run("removed entry", func(t *testing.T) {
+		svc, file := record(t, 3)
+		lines := file.lines()
+		file.setLines([]string{lines[0], lines[2]})
+
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.BrokenAt)
+		assert.Equal(t, int64(1), result.Entries)
+	})
+
+	t.Run("rewritten chain", func(t *testing.T) {
+		svc, file := record(t, 2)
+		lines := file.lines()
+		// Changing an entry and rehashing it still breaks the link to the next.
+		var e models.AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+		e.AffectedCount = 0
+		e.Hash, _ = hashEntry(e)
+		b, _ := json.Marshal(e)
+		lines[0] = string(b)
+		file.setLines(lines)
+
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.BrokenAt)
+		assert.Equal(t, "does not follow the entry before it", result.Reason)
+	})
+
+	t.Run("garbled line", func(t *testing.T) {
+		svc, file := record(t, 2)
+		file.data.WriteString("{not json\n")
+
+		result, err := svc.Verify()
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(3), result.BrokenAt)
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("copies the log", func(t *testing.T) {
+		svc, file := newTestService(models.AuditRedactNone)
+		require.NoError(t, svc.Record(updateAction()))
+		path := filepath.Join(t.TempDir(), "audit.ndjson")
+		svc.dialog = &dialogStub{path: path}
+
+		got, err := svc.Export()
+		require.NoError(t, err)
+		assert.Equal(t, path, got)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, file.data.String(), string(data))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		svc, _ := newTestService(models.AuditRedactNone)
+		got, err := svc.Export()
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("dialog error", func(t *testing.T) {
+		svc, _ := newTestService(models.AuditRedactNone)
+		svc.dialog = &dialogStub{err: errors.New("no window")}
+		_, err := svc.Export()
+		assert.Error(t, err)
+	})
+}
+
+func TestFile_AppendsAndReads(t *testing.T) {
+	f := &file{path: filepath.Join(t.TempDir(), "audit.ndjson")}
+
+	r, err := f.Open()
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	assert.Empty(t, data, "a missing log reads as empty")
+
+	require.NoError(t, f.Append([]byte("one\n")))
+	require.NoError(t, f.Append([]byte("two\n")))
+	r, err = f.Open()
+	require.NoError(t, err)
+	defer r.Close()
+	data, _ = io.ReadAll(r)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
+
+type recorderStub struct {
+	actions []models.AuditAction
+	err     error
+}
+
+func (r *recorderStub) Record(action models.AuditAction) error {
+	r.actions = append(r.actions, action)
+	return r.err
+}
+
+func TestRecordOrLog(t *testing.T) {
+	t.Run("marks a failed change", func(t *testing.T) {
+		r := &recorderStub{}
+		RecordOrLog(slog.Default(), r, models.AuditAction{Operation: "dropDatabase"}, errors.New("not authorized"))
+		require.Len(t, r.actions, 1)
+		assert.Equal(t, "not authorized", r.actions[0].Error)
+	})
+
+	t.Run("nil recorder", func(t *testing.T) {
+		var r Recorder
+		RecordOrLog(slog.Default(), r, models.AuditAction{Operation: "dropDatabase"}, nil)
+	})
+
+	t.Run("a log that cannot be written is logged", func(t *testing.T) {
+		var logged bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&logged, nil))
+		RecordOrLog(log, &recorderStub{err: errors.New("disk full")}, models.AuditAction{Operation: "import"}, nil)
+		assert.Contains(t, logged.String(), "disk full")
+		assert.Contains(t, logged.String(), "operation=import")
+	})
+}
@@ -0,0 +1,248 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// The archive layout is mongodump --archive's, so mongorestore can read what
+// Dump writes and Restore can read what mongodump writes:
+//
+//	magic number
+//	prelude document
+//	one document per collection and view: its name and metadata
+//	terminator
+//	blocks, each a namespace header followed by documents and a terminator
+//
+// mongodump interleaves blocks of several collections. Each collection ends
+// with a header marked EOF that carries the CRC-64 of its documents.
+const (
+	archiveMagic         uint32 = 0x8199e26d
+	archiveFormatVersion        = "0.1"
+	// maxBlockSize is the largest document an archive can hold: the server's
+	// 16 MiB limit and the headroom mongodump allows over it. A larger size
+	// is a corrupt archive, not something to allocate.
+	maxBlockSize = 16<<20 + 16<<10
+)
+
+var (
+	archiveTerminator = []byte{0xff, 0xff, 0xff, 0xff}
+	crcTable          = crc64.MakeTable(crc64.ECMA)
+)
+
+// archivePrelude is the document after the magic number.
+type archivePrelude struct {
+	ConcurrentCollections int32  `bson:"concurrent_collections"`
+	FormatVersion         string `bson:"version"`
+	ServerVersion         string `bson:"server_version"`
+	ToolVersion           string `bson:"tool_version"`
+}
+
+// archiveNamespace names a collection or view in the prelude. Metadata is
+// the text of its .metadata.json file in a directory dump.
+type archiveNamespace struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"collection"`
+	Metadata   string `bson:"metadata"`
+	// Size only drives mongorestore's progress bar.
+	Size int    `bson:"size"`
+	Type string `bson:"type"`
+}
+
+// archiveHeader starts a block of one collection's documents.
+type archiveHeader struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"collection"`
+	EOF        bool   `bson:"EOF"`
+	CRC        int64  `bson:"CRC"`
+}
+
+// archiveWriter writes an archive one collection at a time.
+type archiveWriter struct {
+	w   io.Writer
+	crc hash.Hash64
+}
+
+// newArchiveWriter writes the magic number and prelude to w.
+func newArchiveWriter(w io.Writer, prelude archivePrelude, namespaces []archiveNamespace) (*archiveWriter, error) {
+	if err := binary.Write(w, binary.LittleEndian, archiveMagic); err != nil {
+		return nil, err
+	}
+	if err := writeBSON(w, prelude); err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		if err := writeBSON(w, ns); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.Write(archiveTerminator); err != nil {
+		return nil, err
+	}
+	return &archiveWriter{w: w}, nil
+}
+
+// begin starts the documents of db.coll.
+func (a *archiveWriter) begin(db, coll string) error {
+	a.crc = crc64.New(crcTable)
+	return writeBSON(a.w, archiveHeader{Database: db, Collection: coll})
+}
+
+func (a *archiveWriter) write(doc bson.Raw) error {
+	_, _ = a.crc.Write(doc)
+	_, err := a.w.Write(doc)
+	return err
+}
+
+// end closes the block begin started and marks db.coll complete.
+func (a *archiveWriter) end(db, coll string) error {
+	if _, err := a.w.Write(archiveTerminator); err != nil {
+		return err
+	}
+	eof := archiveHeader{Database: db, Collection: coll, EOF: true, CRC: int64(a.crc.Sum64())}
+	if err := writeBSON(a.w, eof); err != nil {
+		return err
+	}
+	_, err := a.w.Write(archiveTerminator)
+	return err
+}
+
+func writeBSON(w io.Writer, v any) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// archiveReader reads an archive: the prelude first, then the documents.
+type archiveReader struct {
+	r *bufio.Reader
+}
+
+// newArchiveReader checks the magic number and reads the prelude.
+func newArchiveReader(r io.Reader) (*archiveReader, archivePrelude, []archiveNamespace, error) {
+	a := &archiveReader{r: bufio.NewReader(r)}
+	var magic uint32
+	if err := binary.Read(a.r, binary.LittleEndian, &magic); err != nil || magic != archiveMagic {
+		return nil, archivePrelude{}, nil, errors.New("not a mongodump archive")
+	}
+
+	var prelude archivePrelude
+	block, err := a.block()
+	if err == nil && block == nil {
+		err = errors.New("missing prelude")
+	}
+	if err == nil {
+		err = bson.Unmarshal(block, &prelude)
+	}
+	if err != nil {
+		return nil, archivePrelude{}, nil, fmt.Errorf("error reading archive prelude: %w", err)
+	}
+
+	var namespaces []archiveNamespace
+	for {
+		block, err := a.block()
+		if err != nil {
+			return nil, archivePrelude{}, nil, fmt.Errorf("error reading archive prelude: %w", err)
+		}
+		if block == nil {
+			break
+		}
+		var ns archiveNamespace
+		if err := bson.Unmarshal(block, &ns); err != nil {
+			return nil, archivePrelude{}, nil, fmt.Errorf("error reading archive prelude: %w", err)
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return a, prelude, namespaces, nil
+}
+
+// documents calls fn with every document in the archive and the namespace it
+// belongs to, in archive order. A collection whose documents do not match
+// its CRC is an error, once all of them have been passed to fn.
+func (a *archiveReader) documents(fn func(db, coll string, doc bson.Raw) error) error {
+	crcs := map[string]hash.Hash64{}
+	for {
+		block, err := a.block()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return errors.New("corrupt archive: a terminator where a header belongs")
+		}
+		var h archiveHeader
+		if err := bson.Unmarshal(block, &h); err != nil {
+			return fmt.Errorf("corrupt archive: %w", err)
+		}
+		ns := h.Database + "." + h.Collection
+		crc, ok := crcs[ns]
+		if !ok {
+			crc = crc64.New(crcTable)
+			crcs[ns] = crc
+		}
+		if h.EOF && int64(crc.Sum64()) != h.CRC {
+			return fmt.Errorf("corrupt archive: checksum mismatch in %s", ns)
+		}
+
+		for {
+			doc, err := a.block()
+			if err != nil {
+				return unexpectedEOF(err)
+			}
+			if doc == nil {
+				break
+			}
+			if h.EOF {
+				return fmt.Errorf("corrupt archive: documents after the end of %s", ns)
+			}
+			_, _ = crc.Write(doc)
+			if err := fn(h.Database, h.Collection, doc); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// block reads the next document, or returns nil for a terminator. io.EOF
+// means the archive ended cleanly between blocks.
+func (a *archiveReader) block() (bson.Raw, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(a.r, size[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, unexpectedEOF(err)
+	}
+	n := int32(binary.LittleEndian.Uint32(size[:]))
+	if n == -1 {
+		return nil, nil
+	}
+	if n < 5 || n > maxBlockSize {
+		return nil, fmt.Errorf("corrupt archive: document of %d bytes", n)
+	}
+	doc := make([]byte, n)
+	copy(doc, size[:])
+	if _, err := io.ReadFull(a.r, doc[4:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return doc, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.New("the archive is truncated")
+	}
+	return err
+}
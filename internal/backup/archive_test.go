@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func rawDoc(t *testing.T, d bson.D) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(d)
+	require.NoError(t, err)
+	return data
+}
+
+type archived struct {
+	ns  string
+	doc bson.Raw
+}
+
+func readAll(t *testing.T, data []byte) ([]archiveNamespace, []archived, error) {
+	t.Helper()
+	r, prelude, namespaces, err := newArchiveReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, archiveFormatVersion, prelude.FormatVersion)
+	var docs []archived
+	err = r.documents(func(db, coll string, doc bson.Raw) error {
+		docs = append(docs, archived{ns: db + "." + coll, doc: doc})
+		return nil
+	})
+	return namespaces, docs, err
+}
+
+func writeArchive(t *testing.T, colls map[string][]bson.Raw, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	namespaces := make([]archiveNamespace, len(order))
+	for i, c := range order {
+		namespaces[i] = archiveNamespace{Database: "shop", Collection: c, Metadata: `{"options":{}}`, Type: typeCollection}
+	}
+	w, err := newArchiveWriter(&buf, archivePrelude{ConcurrentCollections: 1, FormatVersion: archiveFormatVersion}, namespaces)
+	require.NoError(t, err)
+	for _, c := range order {
+		require.NoError(t, w.begin("shop", c))
+		for _, doc := range colls[c] {
+			require.NoError(t, w.write(doc))
+		}
+		require.NoError(t, w.end("shop", c))
+	}
+	return buf.Bytes()
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	orders := []bson.Raw{
+		rawDoc(t, bson.D{{Key: "_id", Value: 1}, {Key: "total", Value: 9.5}}),
+		rawDoc(t, bson.D{{Key: "_id", Value: 2}, {Key: "total", Value: 12.0}}),
+	}
+	data := writeArchive(t, map[string][]bson.Raw{"orders": orders}, "orders", "empty")
+
+	assert.Equal(t, []byte{0x6d, 0xe2, 0x99, 0x81}, data[:4], "magic number, little-endian")
+
+	namespaces, docs, err := readAll(t, data)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 2)
+	assert.Equal(t, "orders", namespaces[0].Collection)
+	assert.Equal(t, `{"options":{}}`, namespaces[0].Metadata)
+	assert.Equal(t, "empty", namespaces[1].Collection)
+	assert.Equal(t, []archived{{"shop.orders", orders[0]}, {"shop.orders", orders[1]}}, docs)
+}
+
+func TestArchive_InterleavedBlocks(t *testing.T) {
+	// mongodump writes several collections at once, switching between them.
+	a := rawDoc(t, bson.D{{Key: "_id", Value: "a"}})
+	b := rawDoc(t, bson.D{{Key: "_id", Value: "b"}})
+	var buf bytes.Buffer
+	w, err := newArchiveWriter(&buf, archivePrelude{FormatVersion: archiveFormatVersion}, nil)
+	require.NoError(t, err)
+	crcA, crcB := crcOf(a, a), crcOf(b)
+	for _, step := range []any{
+		archiveHeader{Database: "db", Collection: "a"}, a, archiveTerminator,
+		archiveHeader{Database: "db", Collection: "b"}, b, archiveTerminator,
+		archiveHeader{Database: "db", Collection: "a"}, a, archiveTerminator,
+		archiveHeader{Database: "db", Collection: "b", EOF: true, CRC: crcB}, archiveTerminator,
+		archiveHeader{Database: "db", Collection: "a", EOF: true, CRC: crcA}, archiveTerminator,
+	} {
+		switch v := step.(type) {
+		case archiveHeader:
+			require.NoError(t, writeBSON(w.w, v))
+		case bson.Raw:
+			buf.Write(v)
+		case []byte:
+			buf.Write(v)
+		}
+	}
+
+	_, docs, err := readAll(t, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []archived{{"db.a", a}, {"db.b", b}, {"db.a", a}}, docs)
+}
+
+func crcOf(docs ...bson.Raw) int64 {
+	w := &archiveWriter{w: &bytes.Buffer{}}
+	_ = w.begin("", "")
+	for _, d := range docs {
+		_ = w.write(d)
+	}
+	return int64(w.crc.Sum64())
+}
+
+func TestArchive_Corrupt(t *testing.T) {
+	doc := rawDoc(t, bson.D{{Key: "_id", Value: 1}})
+	data := writeArchive(t, map[string][]bson.Raw{"c": {doc}}, "c")
+
+	t.Run("not an archive", func(t *testing.T) {
+		_, _, _, err := newArchiveReader(bytes.NewReader([]byte(`{"a":1}`)))
+		assert.ErrorContains(t, err, "not a mongodump archive")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, _, err := readAll(t, data[:len(data)-10])
+		assert.ErrorContains(t, err, "truncated")
+	})
+
+	t.Run("changed document", func(t *testing.T) {
+		changed := bytes.Clone(data)
+		i := bytes.Index(changed, doc)
+		changed[i+len(doc)-2]++ // the int32 value of _id
+		_, _, err := readAll(t, changed)
+		assert.ErrorContains(t, err, "checksum mismatch in shop.c")
+	})
+
+	t.Run("oversized document", func(t *testing.T) {
+		changed := bytes.Clone(data)
+		i := bytes.Index(changed, doc)
+		binary.LittleEndian.PutUint32(changed[i:], 1<<30)
+		_, _, err := readAll(t, changed)
+		assert.ErrorContains(t, err, "corrupt archive: document of 1073741824 bytes")
+	})
+}
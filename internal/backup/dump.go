@@ -0,0 +1,299 @@
+package backup
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// toolVersion is what an archive's prelude says wrote it.
+const toolVersion = "vervet"
+
+// Dump writes the request's collections and views, with their options and
+// indexes, as a directory dump or an archive. A cancelled dump removes the
+// files it wrote and returns a result marked Cancelled.
+func (s *Service) Dump(req models.DumpRequest) (models.BackupResult, error) {
+	if err := validateDump(req); err != nil {
+		return models.BackupResult{}, err
+	}
+	client, err := s.clients.GetClient(req.ServerID)
+	if err != nil {
+		return models.BackupResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.register(req.DumpID, cancel)
+	defer s.unregister(req.DumpID)
+
+	db := client.Database(req.Database)
+	colls, err := listCollections(ctx, db, req.Collections)
+	if err != nil {
+		return models.BackupResult{}, err
+	}
+
+	d := &dump{
+		ctx:      ctx,
+		req:      req,
+		db:       db,
+		colls:    colls,
+		progress: newProgress(s, req.DumpID, len(colls)),
+		result:   models.BackupResult{Path: req.Path, Collections: []models.BackupCollection{}},
+	}
+	if req.Format == models.BackupArchive {
+		err = d.archive()
+	} else {
+		err = d.directory()
+	}
+	if err != nil {
+		d.removeFiles()
+		if ctx.Err() != nil {
+			s.log.Info("Dump cancelled", slog.String("path", req.Path))
+			return models.BackupResult{Path: req.Path, Collections: []models.BackupCollection{}, Cancelled: true}, nil
+		}
+		return models.BackupResult{}, err
+	}
+	return d.result, nil
+}
+
+func validateDump(req models.DumpRequest) error {
+	if req.ServerID == "" || req.Database == "" {
+		return errors.New("a dump needs a server and database")
+	}
+	if req.Path == "" {
+		return errors.New("no location chosen for the dump")
+	}
+	return validateFormat(req.Format)
+}
+
+// listCollections returns the metadata of the named collections and views,
+// or of all of them when names is empty, sorted by name. System collections
+// are left out, as mongodump leaves them out.
+func listCollections(ctx context.Context, db *mongo.Database, names []string) ([]metadata, error) {
+	filter := bson.D{}
+	if len(names) > 0 {
+		filter = bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: names}}}}
+	}
+	specs, err := db.ListCollectionSpecifications(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing collections: %w", err)
+	}
+
+	var colls []metadata
+	for _, spec := range specs {
+		if strings.HasPrefix(spec.Name, "system.") {
+			continue
+		}
+		m := metadata{CollectionName: spec.Name, Type: spec.Type}
+		if len(spec.Options) > 0 {
+			if err := bson.Unmarshal(spec.Options, &m.Options); err != nil {
+				return nil, fmt.Errorf("error reading options of %s: %w", spec.Name, err)
+			}
+		}
+		if spec.UUID != nil {
+			m.UUID = hex.EncodeToString(spec.UUID.Data)
+		}
+		if m.Type == typeTimeseries {
+			// Dumped as the measurements the collection returns rather than
+			// its buckets, so a restore recreates it from its timeseries
+			// options and inserts them like any other collection's.
+			m.Type = typeCollection
+		}
+		if !m.isView() {
+			cur, err := db.Collection(spec.Name).Indexes().List(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error listing indexes of %s: %w", spec.Name, err)
+			}
+			if err := cur.All(ctx, &m.Indexes); err != nil {
+				return nil, fmt.Errorf("error listing indexes of %s: %w", spec.Name, err)
+			}
+		}
+		colls = append(colls, m)
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(colls, func(m metadata) bool { return m.CollectionName == name }) {
+			return nil, fmt.Errorf("%s.%s does not exist", db.Name(), name)
+		}
+	}
+	slices.SortFunc(colls, func(a, b metadata) int { return strings.Compare(a.CollectionName, b.CollectionName) })
+	return colls, nil
+}
+
+// dump is the state of one dump.
+type dump struct {
+	ctx      context.Context
+	req      models.DumpRequest
+	db       *mongo.Database
+	colls    []metadata
+	progress *progress
+	result   models.BackupResult
+	files    []string // written so far, removed if the dump fails
+}
+
+// directory writes <path>/<database>/<collection>.metadata.json, and
+// <collection>.bson for each collection.
+func (d *dump) directory() error {
+	dir := filepath.Join(d.req.Path, d.req.Database)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	for _, m := range d.colls {
+		meta, err := marshalMetadata(m)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, fileName(m.CollectionName, metadataSuffix, d.req.Gzip))
+		if err := d.writeFile(path, func(w io.Writer) error {
+			_, err := w.Write(meta)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		var n int64
+		if !m.isView() {
+			path := filepath.Join(dir, fileName(m.CollectionName, dataSuffix, d.req.Gzip))
+			if err := d.writeFile(path, func(w io.Writer) error {
+				n, err = d.copyDocuments(m, func(doc bson.Raw) error {
+					_, err := w.Write(doc)
+					return err
+				})
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+		d.finish(m, n)
+	}
+	return nil
+}
+
+// writeFile creates path and has write fill it.
+func (d *dump) writeFile(path string, write func(w io.Writer) error) error {
+	out, err := createFile(path, d.req.Gzip)
+	if err != nil {
+		return err
+	}
+	d.files = append(d.files, path)
+	err = write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// archive writes every collection to one file, one after another.
+func (d *dump) archive() error {
+	namespaces := make([]archiveNamespace, len(d.colls))
+	for i, m := range d.colls {
+		meta, err := marshalMetadata(m)
+		if err != nil {
+			return err
+		}
+		namespaces[i] = archiveNamespace{
+			Database:   d.req.Database,
+			Collection: m.CollectionName,
+			Metadata:   string(meta),
+			Type:       m.Type,
+		}
+	}
+	prelude := archivePrelude{
+		ConcurrentCollections: 1,
+		FormatVersion:         archiveFormatVersion,
+		ServerVersion:         d.serverVersion(),
+		ToolVersion:           toolVersion,
+	}
+
+	return d.writeFile(d.req.Path, func(w io.Writer) error {
+		a, err := newArchiveWriter(w, prelude, namespaces)
+		if err != nil {
+			return err
+		}
+		for _, m := range d.colls {
+			var n int64
+			if !m.isView() {
+				if err := a.begin(d.req.Database, m.CollectionName); err != nil {
+					return err
+				}
+				if n, err = d.copyDocuments(m, a.write); err != nil {
+					return err
+				}
+				if err := a.end(d.req.Database, m.CollectionName); err != nil {
+					return err
+				}
+			}
+			d.finish(m, n)
+		}
+		return nil
+	})
+}
+
+// copyDocuments passes every document in the collection to write.
+func (d *dump) copyDocuments(m metadata, write func(doc bson.Raw) error) (int64, error) {
+	ns := d.req.Database + "." + m.CollectionName
+	coll := d.db.Collection(m.CollectionName)
+	total, _ := coll.EstimatedDocumentCount(d.ctx)
+	d.progress.update(ns, 0, total)
+
+	cur, err := coll.Find(d.ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", ns, err)
+	}
+	defer cur.Close(context.Background())
+
+	var n int64
+	for cur.Next(d.ctx) {
+		if err := write(cur.Current); err != nil {
+			return n, fmt.Errorf("failed to write %s: %w", ns, err)
+		}
+		n++
+		d.progress.update(ns, n, total)
+		// The cursor only sees ctx when it fetches the next batch.
+		if err := d.ctx.Err(); err != nil {
+			return n, err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return n, fmt.Errorf("error reading %s: %w", ns, err)
+	}
+	return n, nil
+}
+
+func (d *dump) finish(m metadata, documents int64) {
+	d.result.Collections = append(d.result.Collections, models.BackupCollection{
+		Namespace: d.req.Database + "." + m.CollectionName,
+		Type:      m.Type,
+		Documents: documents,
+		Indexes:   len(m.Indexes),
+	})
+	d.progress.done()
+}
+
+// serverVersion is the server's version for the archive prelude, or empty
+// when the server won't say.
+func (d *dump) serverVersion() string {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	_ = d.db.Client().Database("admin").RunCommand(d.ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info)
+	return info.Version
+}
+
+func (d *dump) removeFiles() {
+	for _, path := range d.files {
+		_ = os.Remove(path)
+	}
+}
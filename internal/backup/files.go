@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Suffixes of the files in a directory dump. Gzipped files add gzipSuffix.
+const (
+	dataSuffix     = ".bson"
+	metadataSuffix = ".metadata.json"
+	gzipSuffix     = ".gz"
+)
+
+// fileName is the file name for a collection, escaped so names holding a
+// path separator stay in their database's directory.
+func fileName(collection, suffix string, gz bool) string {
+	name := url.PathEscape(collection) + suffix
+	if gz {
+		name += gzipSuffix
+	}
+	return name
+}
+
+// parseFileName is the inverse of fileName. ok is false for a file that is
+// not part of a dump.
+func parseFileName(name string) (collection, suffix string, ok bool) {
+	base := strings.TrimSuffix(name, gzipSuffix)
+	for _, suffix := range []string{metadataSuffix, dataSuffix} {
+		if escaped, found := strings.CutSuffix(base, suffix); found && escaped != "" {
+			collection, err := url.PathUnescape(escaped)
+			if err != nil {
+				return "", "", false
+			}
+			return collection, suffix, true
+		}
+	}
+	return "", "", false
+}
+
+// outFile is a buffered file being written, gzip-compressed or not.
+type outFile struct {
+	f   *os.File
+	buf *bufio.Writer
+	gz  *gzip.Writer
+	w   io.Writer
+}
+
+func createFile(path string, gz bool) (*outFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	o := &outFile{f: f, buf: bufio.NewWriterSize(f, 256*1024)}
+	o.w = o.buf
+	if gz {
+		o.gz = gzip.NewWriter(o.buf)
+		o.w = o.gz
+	}
+	return o, nil
+}
+
+func (o *outFile) Write(p []byte) (int, error) {
+	return o.w.Write(p)
+}
+
+// Close finishes the file. The file is closed even when finishing fails.
+func (o *outFile) Close() error {
+	var err error
+	if o.gz != nil {
+		err = o.gz.Close()
+	}
+	if err == nil {
+		err = o.buf.Flush()
+	}
+	if cerr := o.f.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// inFile is a file being read, decompressed when it starts with the gzip
+// magic number, whatever its name.
+type inFile struct {
+	io.Reader
+	f *os.File
+}
+
+func openFile(path string) (*inFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+	buf := bufio.NewReaderSize(f, 256*1024)
+	in := &inFile{Reader: buf, f: f}
+	if magic, _ := buf.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		in.Reader = bufio.NewReader(gz)
+	}
+	return in, nil
+}
+
+func (i *inFile) Close() error {
+	return i.f.Close()
+}
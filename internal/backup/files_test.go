@@ -0,0 +1,57 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "orders.bson", fileName("orders", dataSuffix, false))
+	assert.Equal(t, "orders.metadata.json.gz", fileName("orders", metadataSuffix, true))
+	assert.Equal(t, "a%2Fb.bson", fileName("a/b", dataSuffix, false))
+
+	for _, name := range []string{"orders.bson", "orders.metadata.json.gz", "a%2Fb.bson"} {
+		coll, _, ok := parseFileName(name)
+		require.True(t, ok, name)
+		assert.Contains(t, []string{"orders", "a/b"}, coll)
+	}
+
+	coll, suffix, ok := parseFileName("a%2Fb.metadata.json")
+	assert.True(t, ok)
+	assert.Equal(t, "a/b", coll)
+	assert.Equal(t, metadataSuffix, suffix)
+
+	for _, name := range []string{"prelude.json", ".bson", "notes.txt"} {
+		_, _, ok := parseFileName(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestFiles_GzipIsDetected(t *testing.T) {
+	dir := t.TempDir()
+	for _, gz := range []bool{false, true} {
+		// The name says nothing: the content decides.
+		path := filepath.Join(dir, "data")
+		out, err := createFile(path, gz)
+		require.NoError(t, err)
+		_, err = out.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, out.Close())
+
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, !gz, string(raw) == "hello")
+
+		in, err := openFile(path)
+		require.NoError(t, err)
+		data, err := io.ReadAll(in)
+		require.NoError(t, err)
+		require.NoError(t, in.Close())
+		assert.Equal(t, "hello", string(data))
+	}
+}
@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Collection types as listCollections and mongodump name them.
+const (
+	typeCollection = "collection"
+	typeView       = "view"
+	typeTimeseries = "timeseries"
+)
+
+// bucketsPrefix starts the name of the collection the server keeps a
+// time-series collection's measurements in, and mongodump dumps them from.
+const bucketsPrefix = "system.buckets."
+
+// metadata is a collection's .metadata.json: what restore needs to recreate
+// it before loading its documents.
+type metadata struct {
+	Options        bson.D   `bson:"options"`
+	Indexes        []bson.D `bson:"indexes"`
+	UUID           string   `bson:"uuid,omitempty"`
+	CollectionName string   `bson:"collectionName"`
+	Type           string   `bson:"type,omitempty"`
+}
+
+// marshalMetadata writes m as canonical Extended JSON, as mongodump does.
+func marshalMetadata(m metadata) ([]byte, error) {
+	if m.Options == nil {
+		m.Options = bson.D{}
+	}
+	if m.Indexes == nil {
+		m.Indexes = []bson.D{}
+	}
+	data, err := bson.MarshalExtJSON(m, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("error writing metadata of %s: %w", m.CollectionName, err)
+	}
+	return data, nil
+}
+
+func unmarshalMetadata(data []byte) (metadata, error) {
+	var m metadata
+	if err := bson.UnmarshalExtJSON(data, false, &m); err != nil {
+		return metadata{}, fmt.Errorf("error reading metadata: %w", err)
+	}
+	if m.Type == "" {
+		// Older mongodump versions only wrote metadata for collections and
+		// views, telling them apart by the options.
+		m.Type = typeCollection
+		if _, ok := lookup(m.Options, "viewOn"); ok {
+			m.Type = typeView
+		}
+	}
+	return m, nil
+}
+
+// isView reports whether m describes a view, which has no documents or
+// indexes of its own.
+func (m metadata) isView() bool {
+	return m.Type == typeView
+}
+
+func lookup(d bson.D, key string) (any, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
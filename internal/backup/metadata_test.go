@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMetadata_RoundTrip(t *testing.T) {
+	m := metadata{
+		Options: bson.D{{Key: "capped", Value: true}, {Key: "size", Value: int64(4096)}},
+		Indexes: []bson.D{
+			{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}}, {Key: "name", Value: "_id_"}},
+		},
+		UUID:           "0123456789abcdef0123456789abcdef",
+		CollectionName: "events",
+		Type:           typeCollection,
+	}
+
+	data, err := marshalMetadata(m)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"size":{"$numberLong":"4096"}`, "canonical Extended JSON")
+
+	back, err := unmarshalMetadata(data)
+	require.NoError(t, err)
+	assert.Equal(t, m, back)
+}
+
+func TestMetadata_EmptyFieldsAreWritten(t *testing.T) {
+	data, err := marshalMetadata(metadata{CollectionName: "c", Type: typeCollection})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"options":{},"indexes":[],"collectionName":"c","type":"collection"}`, string(data))
+}
+
+func TestMetadata_TypeFromOlderDumps(t *testing.T) {
+	view, err := unmarshalMetadata([]byte(`{"options":{"viewOn":"orders","pipeline":[]},"indexes":[]}`))
+	require.NoError(t, err)
+	assert.True(t, view.isView())
+
+	coll, err := unmarshalMetadata([]byte(`{"options":{},"indexes":[]}`))
+	require.NoError(t, err)
+	assert.Equal(t, typeCollection, coll.Type)
+}
@@ -0,0 +1,512 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"vervet/internal/audit"
+	"vervet/internal/models"
+	"vervet/internal/mongowrite"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// dumped is a collection or view in a dump.
+type dumped struct {
+	db   string
+	meta metadata
+	// dataPath is its .bson file in a directory dump. Empty for views and
+	// for archives, whose documents are read in one pass over the file.
+	dataPath string
+	// bucketsPath is the system.buckets .bson file mongodump writes a
+	// time-series collection's documents to, in a directory dump.
+	bucketsPath string
+}
+
+func (d dumped) namespace() string {
+	return d.db + "." + d.meta.CollectionName
+}
+
+// Restore recreates the dump's collections with their options, loads their
+// documents, builds their indexes and then recreates its views, in the order
+// mongorestore does. Documents the server refuses are counted against their
+// collection and do not stop the restore; an error means the dump or the
+// server failed and the restore stopped part way. What it wrote before then
+// stays written, as it does when the restore is cancelled.
+func (s *Service) Restore(req models.RestoreRequest) (models.BackupResult, error) {
+	if err := validateRestore(req); err != nil {
+		return models.BackupResult{}, err
+	}
+
+	var (
+		namespaces []dumped
+		archive    *archiveReader
+		err        error
+	)
+	if req.Format == models.BackupArchive {
+		in, err := openFile(req.Path)
+		if err != nil {
+			return models.BackupResult{}, err
+		}
+		defer in.Close()
+		archive, namespaces, err = readArchiveNamespaces(in)
+		if err != nil {
+			return models.BackupResult{}, err
+		}
+	} else if namespaces, err = scanDirectory(req.Path); err != nil {
+		return models.BackupResult{}, err
+	}
+	if err := checkTarget(req, namespaces); err != nil {
+		return models.BackupResult{}, err
+	}
+
+	client, err := s.clients.GetClient(req.ServerID)
+	if err != nil {
+		return models.BackupResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.register(req.RestoreID, cancel)
+	defer s.unregister(req.RestoreID)
+
+	r := newRestore(ctx, req, client, namespaces, newProgress(s, req.RestoreID, len(namespaces)))
+	err = r.run(archive)
+	result := models.BackupResult{Path: req.Path, Collections: r.results}
+	if err != nil && ctx.Err() != nil {
+		result.Cancelled = true
+		err = nil
+	}
+	r.progress.send(true)
+
+	s.audit(req, r, err)
+	if err != nil {
+		return result, fmt.Errorf("restore stopped: %w", err)
+	}
+	return result, nil
+}
+
+func validateRestore(req models.RestoreRequest) error {
+	if req.ServerID == "" {
+		return errors.New("a restore needs a server")
+	}
+	if req.Path == "" {
+		return errors.New("no dump chosen to restore")
+	}
+	return validateFormat(req.Format)
+}
+
+// checkTarget refuses to restore a dump of several databases into one.
+func checkTarget(req models.RestoreRequest, namespaces []dumped) error {
+	if len(namespaces) == 0 {
+		return fmt.Errorf("no collections found in %s", req.Path)
+	}
+	if req.Database == "" {
+		return nil
+	}
+	for _, ns := range namespaces[1:] {
+		if ns.db != namespaces[0].db {
+			return errors.New("the dump holds more than one database, so cannot be restored into one")
+		}
+	}
+	return nil
+}
+
+// readArchiveNamespaces reads an archive's prelude, leaving the reader at its
+// documents.
+func readArchiveNamespaces(r io.Reader) (*archiveReader, []dumped, error) {
+	archive, _, entries, err := newArchiveReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var namespaces []dumped
+	for _, e := range entries {
+		// A time-series collection's buckets are restored with it.
+		if strings.HasPrefix(e.Collection, "system.") {
+			continue
+		}
+		meta, err := unmarshalMetadata([]byte(e.Metadata))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", e.Database, e.Collection, err)
+		}
+		meta.CollectionName = e.Collection
+		namespaces = append(namespaces, dumped{db: e.Database, meta: meta})
+	}
+	return archive, namespaces, nil
+}
+
+// scanDirectory finds the collections in a directory dump. path is either
+// the top of the dump, holding a directory per database, or one database's
+// directory.
+func scanDirectory(path string) ([]dumped, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if slices.ContainsFunc(entries, isMetadataFile) {
+		return scanDatabase(path, filepath.Base(path))
+	}
+	var namespaces []dumped
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		found, err := scanDatabase(filepath.Join(path, e.Name()), e.Name())
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, found...)
+	}
+	return namespaces, nil
+}
+
+func isMetadataFile(e os.DirEntry) bool {
+	_, suffix, ok := parseFileName(e.Name())
+	return ok && !e.IsDir() && suffix == metadataSuffix
+}
+
+// scanDatabase reads the metadata of every collection in one database's
+// directory. A .bson file without metadata is restored as a plain
+// collection, as mongorestore does.
+func scanDatabase(dir, db string) ([]dumped, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	byName := map[string]*dumped{}
+	get := func(coll string) *dumped {
+		if byName[coll] == nil {
+			byName[coll] = &dumped{db: db, meta: metadata{CollectionName: coll, Type: typeCollection}}
+		}
+		return byName[coll]
+	}
+	for _, e := range entries {
+		coll, suffix, ok := parseFileName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if name, found := strings.CutPrefix(coll, bucketsPrefix); found && suffix == dataSuffix {
+			get(name).bucketsPath = path
+			continue
+		}
+		if strings.HasPrefix(coll, "system.") {
+			continue
+		}
+		if suffix == dataSuffix {
+			get(coll).dataPath = path
+			continue
+		}
+		meta, err := readMetadataFile(path)
+		if err != nil {
+			return nil, err
+		}
+		meta.CollectionName = coll
+		get(coll).meta = meta
+	}
+
+	namespaces := make([]dumped, 0, len(byName))
+	for _, ns := range byName {
+		if ns.meta.isView() {
+			ns.dataPath = ""
+		}
+		if ns.bucketsPath != "" && ns.meta.Type != typeTimeseries {
+			return nil, fmt.Errorf("%s holds time-series buckets, but %s.%s is not a time-series collection",
+				ns.bucketsPath, db, ns.meta.CollectionName)
+		}
+		namespaces = append(namespaces, *ns)
+	}
+	slices.SortFunc(namespaces, func(a, b dumped) int {
+		return strings.Compare(a.meta.CollectionName, b.meta.CollectionName)
+	})
+	return namespaces, nil
+}
+
+func readMetadataFile(path string) (metadata, error) {
+	in, err := openFile(path)
+	if err != nil {
+		return metadata{}, err
+	}
+	defer in.Close()
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return metadata{}, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	meta, err := unmarshalMetadata(data)
+	if err != nil {
+		return metadata{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// restore is the state of one restore.
+type restore struct {
+	ctx        context.Context
+	req        models.RestoreRequest
+	client     *mongo.Client
+	namespaces []dumped
+	targets    map[string]*target // by namespace in the dump
+	results    []models.BackupCollection
+	progress   *progress
+}
+
+// target is where one dumped collection or view is restored to.
+type target struct {
+	dumped
+	coll   *mongo.Collection
+	result int // index into restore.results
+	// buckets is where a time-series collection's buckets go, as mongodump
+	// dumps them, after the collection is created.
+	buckets *target
+
+	batch      []any
+	batchBytes int
+}
+
+func newRestore(ctx context.Context, req models.RestoreRequest, client *mongo.Client, namespaces []dumped, p *progress) *restore {
+	r := &restore{
+		ctx:        ctx,
+		req:        req,
+		client:     client,
+		namespaces: namespaces,
+		targets:    make(map[string]*target, len(namespaces)),
+		results:    make([]models.BackupCollection, 0, len(namespaces)),
+		progress:   p,
+	}
+	for _, ns := range namespaces {
+		db := ns.db
+		if req.Database != "" {
+			db = req.Database
+		}
+		t := &target{
+			dumped: ns,
+			coll:   client.Database(db).Collection(ns.meta.CollectionName),
+			result: len(r.results),
+		}
+		r.targets[ns.namespace()] = t
+		if ns.meta.Type == typeTimeseries {
+			buckets := ns
+			buckets.dataPath = ns.bucketsPath
+			t.buckets = &target{
+				dumped: buckets,
+				coll:   client.Database(db).Collection(bucketsPrefix + ns.meta.CollectionName),
+				result: t.result,
+			}
+			r.targets[ns.db+"."+bucketsPrefix+ns.meta.CollectionName] = t.buckets
+		}
+		r.results = append(r.results, models.BackupCollection{
+			Namespace: db + "." + ns.meta.CollectionName,
+			Type:      ns.meta.Type,
+		})
+	}
+	return r
+}
+
+// run restores collections before views, which may be defined on them, and
+// builds indexes after the documents are in, when building is quickest.
+func (r *restore) run(archive *archiveReader) error {
+	collections, views := r.split()
+	for _, t := range collections {
+		if err := r.create(t); err != nil {
+			return err
+		}
+	}
+
+	if archive != nil {
+		err := archive.documents(func(db, coll string, doc bson.Raw) error {
+			t := r.targets[db+"."+coll]
+			if t == nil && strings.HasPrefix(coll, "system.") && !strings.HasPrefix(coll, bucketsPrefix) {
+				// System collections are left out, as a dump leaves them out.
+				return nil
+			}
+			if t == nil || t.meta.isView() {
+				return fmt.Errorf("the archive holds documents for %s.%s but no collection for them", db, coll)
+			}
+			return r.add(t, doc)
+		})
+		if err != nil {
+			return err
+		}
+		for _, t := range collections {
+			if err := r.flush(t); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, t := range collections {
+			if err := r.load(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, t := range collections {
+		if err := r.createIndexes(t); err != nil {
+			return err
+		}
+		r.progress.done()
+	}
+	for _, t := range views {
+		if err := r.create(t); err != nil {
+			return err
+		}
+		r.progress.done()
+	}
+	return nil
+}
+
+func (r *restore) split() (collections, views []*target) {
+	for _, ns := range r.namespaces {
+		t := r.targets[ns.namespace()]
+		if t.meta.isView() {
+			views = append(views, t)
+		} else {
+			collections = append(collections, t)
+		}
+	}
+	return collections, views
+}
+
+// create creates the collection or view with its dumped options, dropping
+// it first if the request says to. One that already exists is kept.
+func (r *restore) create(t *target) error {
+	if r.req.Drop {
+		if err := t.coll.Drop(r.ctx); err != nil {
+			return fmt.Errorf("error dropping %s: %w", r.name(t), err)
+		}
+	}
+	cmd := append(bson.D{{Key: "create", Value: t.meta.CollectionName}}, t.meta.Options...)
+	err := t.coll.Database().RunCommand(r.ctx, cmd).Err()
+	if mongowrite.NamespaceExists(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", r.name(t), err)
+	}
+	return nil
+}
+
+// load inserts the documents of a directory dump's .bson file, and a
+// time-series collection's buckets.
+func (r *restore) load(t *target) error {
+	if t.buckets != nil {
+		if err := r.load(t.buckets); err != nil {
+			return err
+		}
+	}
+	if t.dataPath == "" {
+		return nil
+	}
+	in, err := openFile(t.dataPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	for {
+		doc, err := bson.ReadDocument(in)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", t.dataPath, err)
+		}
+		if err := r.add(t, doc); err != nil {
+			return err
+		}
+	}
+	return r.flush(t)
+}
+
+func (r *restore) add(t *target, doc bson.Raw) error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	t.batch = append(t.batch, doc)
+	t.batchBytes += len(doc)
+	if len(t.batch) >= restoreBatchSize || t.batchBytes >= restoreBatchBytes {
+		return r.flush(t)
+	}
+	return nil
+}
+
+// flush inserts the target's batch. Documents the server refuses are counted;
+// any other failure stops the restore.
+func (r *restore) flush(t *target) error {
+	if t.buckets != nil {
+		if err := r.flush(t.buckets); err != nil {
+			return err
+		}
+	}
+	if len(t.batch) == 0 {
+		return nil
+	}
+	batch := t.batch
+	t.batch, t.batchBytes = nil, 0
+
+	_, err := t.coll.InsertMany(r.ctx, batch, options.InsertMany().SetOrdered(false))
+	failed, err := mongowrite.WriteErrors(err)
+	if err != nil {
+		return fmt.Errorf("error writing to %s: %w", r.name(t), err)
+	}
+	res := &r.results[t.result]
+	res.Documents += int64(len(batch) - len(failed))
+	res.Failed += int64(len(failed))
+	if len(failed) > 0 && res.FirstError == "" {
+		res.FirstError = failed[0].Message
+	}
+	r.progress.update(res.Namespace, res.Documents+res.Failed, 0)
+	return nil
+}
+
+// createIndexes builds the dumped indexes. An index the server refuses, such
+// as a unique one the restored documents break, is reported against the
+// collection and does not stop the restore.
+func (r *restore) createIndexes(t *target) error {
+	specs := mongowrite.IndexesToCreate(t.meta.Indexes)
+	res := &r.results[t.result]
+	if len(specs) > 0 {
+		cmd := bson.D{{Key: "createIndexes", Value: t.meta.CollectionName}, {Key: "indexes", Value: specs}}
+		if err := t.coll.Database().RunCommand(r.ctx, cmd).Err(); err != nil {
+			if r.ctx.Err() != nil {
+				return r.ctx.Err()
+			}
+			if res.FirstError == "" {
+				res.FirstError = "error creating indexes: " + err.Error()
+			}
+			return nil
+		}
+	}
+	res.Indexes = len(t.meta.Indexes)
+	return nil
+}
+
+func (r *restore) name(t *target) string {
+	return r.results[t.result].Namespace
+}
+
+// audit records each restored collection and view as a change.
+func (s *Service) audit(req models.RestoreRequest, r *restore, err error) {
+	if s.auditor == nil {
+		return
+	}
+	for _, res := range r.results {
+		db, coll, _ := strings.Cut(res.Namespace, ".")
+		audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+			ServerID:      req.ServerID,
+			Database:      db,
+			Collection:    coll,
+			Operation:     "restore",
+			Source:        models.AuditSourceApp,
+			Target:        req.Path,
+			AffectedCount: res.Documents,
+		}, err)
+	}
+}
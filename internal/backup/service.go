@@ -0,0 +1,152 @@
+// Package backup dumps databases to, and restores them from, the directory
+// layout and archive format of mongodump and mongorestore, through the
+// driver rather than the database tools.
+package backup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"vervet/internal/logging"
+	"vervet/internal/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// BackupProgressEvent carries a models.BackupProgress from a running
+	// dump or restore.
+	BackupProgressEvent = "backup-progress"
+
+	// restoreBatchSize and restoreBatchBytes bound each insert a restore
+	// sends, whichever is reached first.
+	restoreBatchSize  = 1000
+	restoreBatchBytes = 16 * 1024 * 1024
+)
+
+// progressInterval is the least time between progress events for one run.
+const progressInterval = 100 * time.Millisecond
+
+// ClientProvider provides access to active MongoDB connections
+type ClientProvider interface {
+	GetClient(serverID string) (*mongo.Client, error)
+}
+
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
+// Service dumps and restores databases.
+type Service struct {
+	mu      sync.Mutex
+	log     *slog.Logger
+	ctx     context.Context
+	clients ClientProvider
+	auditor Auditor
+	cancels map[string]context.CancelFunc // dump or restore ID -> cancel for a running one
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
+}
+
+func NewService(log *slog.Logger, clients ClientProvider) *Service {
+	return &Service{
+		log:     log.With(slog.String(logging.SourceKey, "BackupService")),
+		clients: clients,
+		cancels: make(map[string]context.CancelFunc),
+		emit:    runtime.EventsEmit,
+	}
+}
+
+// Init stores the Wails application context, used as the parent for dumps
+// and restores.
+func (s *Service) Init(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// SetAuditor makes the service audit every restore it runs. Dumps only read,
+// so are not audited.
+func (s *Service) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
+// Cancel stops a running dump or restore. A dump removes the files it wrote;
+// a restore keeps what it already wrote.
+func (s *Service) Cancel(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+}
+
+func (s *Service) register(id string, cancel context.CancelFunc) {
+	if id == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[id] = cancel
+}
+
+func (s *Service) unregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, id)
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case models.BackupDirectory, models.BackupArchive:
+		return nil
+	default:
+		return errors.New("unsupported backup format: " + format)
+	}
+}
+
+// progress reports how far a dump or restore has got.
+type progress struct {
+	svc      *Service
+	id       string
+	current  models.BackupProgress
+	lastSent time.Time
+}
+
+func newProgress(svc *Service, id string, collections int) *progress {
+	return &progress{svc: svc, id: id, current: models.BackupProgress{ID: id, Collections: collections}}
+}
+
+// update reports documents done of total in namespace.
+func (p *progress) update(namespace string, documents, total int64) {
+	p.current.Namespace = namespace
+	p.current.Documents = documents
+	p.current.Total = total
+	p.send(false)
+}
+
+// done reports a collection or view finished.
+func (p *progress) done() {
+	p.current.CollectionsDone++
+	p.send(true)
+}
+
+// send emits a progress event, dropping those that come sooner than
+// progressInterval after the last unless final.
+func (p *progress) send(final bool) {
+	if p.id == "" {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(p.lastSent) < progressInterval {
+		return
+	}
+	p.lastSent = now
+	p.svc.emit(p.svc.ctx, BackupProgressEvent, p.current)
+}
@@ -0,0 +1,317 @@
+//go:build integration
+
+package backup
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"vervet/internal/models"
+)
+
+var (
+	testClient    *mongo.Client
+	testContainer *mongodb.MongoDBContainer
+)
+
+type stubProvider struct {
+	client *mongo.Client
+}
+
+func (s stubProvider) GetClient(string) (*mongo.Client, error) {
+	return s.client, nil
+}
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (r *recordingAuditor) Record(action models.AuditAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	os.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
+
+	container, err := mongodb.Run(ctx, "mongo:7")
+	if err != nil {
+		log.Fatalf("start container: %v", err)
+	}
+	testContainer = container
+	defer func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			log.Printf("terminate: %v", err)
+		}
+	}()
+
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		log.Fatalf("conn string: %v", err)
+	}
+
+	testClient, err = mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer testClient.Disconnect(ctx)
+
+	os.Exit(m.Run())
+}
+
+func newService(t *testing.T) (*Service, *[]models.BackupProgress) {
+	t.Helper()
+	svc := NewService(slog.Default(), stubProvider{client: testClient})
+	svc.Init(context.Background())
+	var events []models.BackupProgress
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events = append(events, data[0].(models.BackupProgress))
+	}
+	return svc, &events
+}
+
+// seed creates a database with a capped collection, an indexed collection
+// and a view on it.
+func seed(t *testing.T, dbName string) {
+	t.Helper()
+	ctx := context.Background()
+	db := testClient.Database(dbName)
+	require.NoError(t, db.Drop(ctx))
+	t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+	require.NoError(t, db.CreateCollection(ctx, "log", options.CreateCollection().SetCapped(true).SetSizeInBytes(1<<20)))
+	_, err := db.Collection("log").InsertOne(ctx, bson.D{{Key: "msg", Value: "started"}})
+	require.NoError(t, err)
+
+	orders := make([]any, 2500)
+	for i := range orders {
+		orders[i] = bson.D{{Key: "_id", Value: i}, {Key: "sku", Value: "sku-" + string(rune('a'+i%26))}, {Key: "total", Value: float64(i) / 4}}
+	}
+	_, err = db.Collection("orders").InsertMany(ctx, orders)
+	require.NoError(t, err)
+	_, err = db.Collection("orders").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "sku", Value: 1}, {Key: "total", Value: -1}},
+		Options: options.Index().SetName("sku_total"),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.CreateView(ctx, "big", "orders", bson.A{bson.D{{Key: "$match", Value: bson.D{{Key: "total", Value: bson.D{{Key: "$gt", Value: 500}}}}}}}))
+}
+
+func TestDumpAndRestore(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		format string
+		gzip   bool
+	}{
+		{"directory", models.BackupDirectory, false},
+		{"directory gzip", models.BackupDirectory, true},
+		{"archive", models.BackupArchive, false},
+		{"archive gzip", models.BackupArchive, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			seed(t, "backup_src")
+			svc, events := newService(t)
+
+			path := t.TempDir()
+			if tc.format == models.BackupArchive {
+				path = filepath.Join(path, "backup.archive")
+			}
+			dumped, err := svc.Dump(models.DumpRequest{
+				DumpID: "d1", ServerID: "srv", Database: "backup_src", Path: path, Format: tc.format, Gzip: tc.gzip,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []models.BackupCollection{
+				{Namespace: "backup_src.big", Type: "view"},
+				{Namespace: "backup_src.log", Type: "collection", Documents: 1, Indexes: 1},
+				{Namespace: "backup_src.orders", Type: "collection", Documents: 2500, Indexes: 2},
+			}, dumped.Collections)
+			require.NotEmpty(t, *events)
+			last := (*events)[len(*events)-1]
+			assert.Equal(t, 3, last.CollectionsDone)
+
+			if tc.format == models.BackupDirectory {
+				suffix := ""
+				if tc.gzip {
+					suffix = ".gz"
+				}
+				for _, f := range []string{"orders.bson", "orders.metadata.json", "log.bson", "big.metadata.json"} {
+					assert.FileExists(t, filepath.Join(path, "backup_src", f+suffix))
+				}
+				assert.NoFileExists(t, filepath.Join(path, "backup_src", "big.bson"+suffix))
+			}
+
+			auditor := &recordingAuditor{}
+			svc.SetAuditor(auditor)
+			restored, err := svc.Restore(models.RestoreRequest{
+				RestoreID: "r1", ServerID: "srv", Path: path, Format: tc.format, Database: "backup_dst", Drop: true,
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = testClient.Database("backup_dst").Drop(context.Background()) })
+			assert.Equal(t, []models.BackupCollection{
+				{Namespace: "backup_dst.big", Type: "view"},
+				{Namespace: "backup_dst.log", Type: "collection", Documents: 1, Indexes: 1},
+				{Namespace: "backup_dst.orders", Type: "collection", Documents: 2500, Indexes: 2},
+			}, restored.Collections)
+			assert.Len(t, auditor.actions, 3)
+
+			dst := testClient.Database("backup_dst")
+			specs, err := dst.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: "log"}})
+			require.NoError(t, err)
+			require.Len(t, specs, 1)
+			capped, err := specs[0].Options.LookupErr("capped")
+			require.NoError(t, err)
+			assert.True(t, capped.Boolean())
+
+			indexes, err := dst.Collection("orders").Indexes().ListSpecifications(ctx)
+			require.NoError(t, err)
+			var names []string
+			for _, idx := range indexes {
+				names = append(names, idx.Name)
+			}
+			assert.ElementsMatch(t, []string{"_id_", "sku_total"}, names)
+
+			n, err := dst.Collection("big").CountDocuments(ctx, bson.D{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(499), n, "the view works on the restored data")
+		})
+	}
+}
+
+func TestRestore_Merge(t *testing.T) {
+	ctx := context.Background()
+	seed(t, "backup_merge")
+	svc, _ := newService(t)
+	path := t.TempDir()
+	_, err := svc.Dump(models.DumpRequest{ServerID: "srv", Database: "backup_merge", Collections: []string{"orders"}, Path: path, Format: models.BackupDirectory})
+	require.NoError(t, err)
+
+	orders := testClient.Database("backup_merge").Collection("orders")
+	_, err = orders.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$gte", Value: 2000}}}})
+	require.NoError(t, err)
+
+	result, err := svc.Restore(models.RestoreRequest{ServerID: "srv", Path: filepath.Join(path, "backup_merge"), Format: models.BackupDirectory})
+	require.NoError(t, err)
+	require.Len(t, result.Collections, 1)
+	assert.Equal(t, int64(500), result.Collections[0].Documents)
+	assert.Equal(t, int64(2000), result.Collections[0].Failed)
+	assert.Contains(t, result.Collections[0].FirstError, "duplicate key")
+
+	n, err := orders.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), n)
+}
+
+func TestDump_MissingCollection(t *testing.T) {
+	seed(t, "backup_missing")
+	svc, _ := newService(t)
+	_, err := svc.Dump(models.DumpRequest{ServerID: "srv", Database: "backup_missing", Collections: []string{"nope"}, Path: t.TempDir(), Format: models.BackupDirectory})
+	assert.ErrorContains(t, err, "backup_missing.nope does not exist")
+}
+
+func TestDump_Cancelled(t *testing.T) {
+	seed(t, "backup_cancel")
+	svc, _ := newService(t)
+	path := filepath.Join(t.TempDir(), "backup.archive")
+	svc.emit = func(context.Context, string, ...interface{}) { svc.Cancel("d1") }
+
+	result, err := svc.Dump(models.DumpRequest{DumpID: "d1", ServerID: "srv", Database: "backup_cancel", Path: path, Format: models.BackupArchive})
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.NoFileExists(t, path)
+}
+
+// containerExec runs cmd in the test container and returns its output.
+func containerExec(t *testing.T, cmd ...string) string {
+	t.Helper()
+	code, output, err := testContainer.Exec(context.Background(), cmd, tcexec.Multiplexed())
+	require.NoError(t, err)
+	text, err := io.ReadAll(output)
+	require.NoError(t, err)
+	require.Zero(t, code, "%v: %s", cmd, text)
+	return string(text)
+}
+
+// copyFromContainer copies the files under src in the test container to dst.
+func copyFromContainer(t *testing.T, src, dst string) {
+	t.Helper()
+	for _, file := range strings.Fields(containerExec(t, "find", src, "-type", "f")) {
+		rel, err := filepath.Rel(src, file)
+		require.NoError(t, err)
+		in, err := testContainer.CopyFileFromContainer(context.Background(), file)
+		require.NoError(t, err)
+		data, err := io.ReadAll(in)
+		in.Close()
+		require.NoError(t, err)
+		path := filepath.Join(dst, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+}
+
+func TestRestore_MongodumpTimeseries(t *testing.T) {
+	// mongodump writes a time-series collection's metadata under its own
+	// name but its documents from its buckets collection.
+	ctx := context.Background()
+	src := testClient.Database("backup_ts")
+	require.NoError(t, src.Drop(ctx))
+	t.Cleanup(func() { _ = src.Drop(context.Background()) })
+	require.NoError(t, src.CreateCollection(ctx, "readings",
+		options.CreateCollection().SetTimeSeriesOptions(options.TimeSeries().SetTimeField("t").SetMetaField("sensor"))))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	readings := make([]any, 300)
+	for i := range readings {
+		readings[i] = bson.D{{Key: "t", Value: start.Add(time.Duration(i) * time.Minute)}, {Key: "sensor", Value: i % 3}, {Key: "v", Value: i}}
+	}
+	_, err := src.Collection("readings").InsertMany(ctx, readings)
+	require.NoError(t, err)
+
+	out := t.TempDir()
+	containerExec(t, "rm", "-rf", "/tmp/mongodump")
+	containerExec(t, "mongodump", "--quiet", "--db=backup_ts", "--out=/tmp/mongodump/dir")
+	containerExec(t, "mongodump", "--quiet", "--db=backup_ts", "--archive=/tmp/mongodump/ts.archive")
+	copyFromContainer(t, "/tmp/mongodump", out)
+	require.FileExists(t, filepath.Join(out, "dir", "backup_ts", "system.buckets.readings.bson"))
+
+	for _, tc := range []struct {
+		name   string
+		format string
+		path   string
+	}{
+		{"directory", models.BackupDirectory, filepath.Join(out, "dir", "backup_ts")},
+		{"archive", models.BackupArchive, filepath.Join(out, "ts.archive")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _ := newService(t)
+			result, err := svc.Restore(models.RestoreRequest{ServerID: "srv", Path: tc.path, Format: tc.format, Database: "backup_ts_dst", Drop: true})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = testClient.Database("backup_ts_dst").Drop(context.Background()) })
+			require.Len(t, result.Collections, 1)
+			assert.Equal(t, "timeseries", result.Collections[0].Type)
+			assert.NotZero(t, result.Collections[0].Documents, "buckets restored")
+			assert.Zero(t, result.Collections[0].Failed)
+
+			n, err := testClient.Database("backup_ts_dst").Collection("readings").CountDocuments(ctx, bson.D{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(readings)), n)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package backup
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump_Validation(t *testing.T) {
+	svc := NewService(slog.Default(), nil)
+	base := models.DumpRequest{ServerID: "srv", Database: "db", Path: t.TempDir(), Format: models.BackupDirectory}
+
+	noDatabase := base
+	noDatabase.Database = ""
+	_, err := svc.Dump(noDatabase)
+	assert.ErrorContains(t, err, "needs a server and database")
+
+	noPath := base
+	noPath.Path = ""
+	_, err = svc.Dump(noPath)
+	assert.ErrorContains(t, err, "no location")
+
+	badFormat := base
+	badFormat.Format = "tar"
+	_, err = svc.Dump(badFormat)
+	assert.ErrorContains(t, err, "unsupported backup format")
+}
+
+func TestRestore_Validation(t *testing.T) {
+	svc := NewService(slog.Default(), nil)
+
+	_, err := svc.Restore(models.RestoreRequest{ServerID: "srv", Format: models.BackupDirectory})
+	assert.ErrorContains(t, err, "no dump chosen")
+
+	_, err = svc.Restore(models.RestoreRequest{ServerID: "srv", Path: t.TempDir(), Format: models.BackupDirectory})
+	assert.ErrorContains(t, err, "no collections found")
+
+	_, err = svc.Restore(models.RestoreRequest{ServerID: "srv", Path: writeTemp(t, "x.archive", "nope"), Format: models.BackupArchive})
+	assert.ErrorContains(t, err, "not a mongodump archive")
+}
+
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestScanDirectory(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("prelude.json", `{}`)
+	write("shop/orders.metadata.json", `{"options":{},"indexes":[],"collectionName":"orders","type":"collection"}`)
+	write("shop/orders.bson", "")
+	write("shop/big.metadata.json", `{"options":{"viewOn":"orders","pipeline":[]},"indexes":[],"collectionName":"big","type":"view"}`)
+	write("shop/loose.bson", "")
+	write("shop/system.views.bson", "")
+	write("crm/people.metadata.json", `{"options":{},"indexes":[]}`)
+	// mongodump keeps a time-series collection's documents in its buckets.
+	write("shop/readings.metadata.json", `{"options":{"timeseries":{"timeField":"t"}},"indexes":[],"collectionName":"readings","type":"timeseries"}`)
+	write("shop/system.buckets.readings.bson", "")
+	write("shop/system.buckets.readings.metadata.json", `{"options":{},"indexes":[]}`)
+
+	t.Run("top of the dump", func(t *testing.T) {
+		found, err := scanDirectory(root)
+		require.NoError(t, err)
+		var names []string
+		for _, d := range found {
+			names = append(names, d.namespace())
+		}
+		assert.Equal(t, []string{"crm.people", "shop.big", "shop.loose", "shop.orders", "shop.readings"}, names)
+
+		byName := map[string]dumped{}
+		for _, d := range found {
+			byName[d.namespace()] = d
+		}
+		assert.Empty(t, byName["crm.people"].dataPath)
+		assert.True(t, byName["shop.big"].meta.isView())
+		assert.Equal(t, typeCollection, byName["shop.loose"].meta.Type, "data without metadata is a plain collection")
+		assert.Equal(t, filepath.Join(root, "shop", "orders.bson"), byName["shop.orders"].dataPath)
+		assert.Empty(t, byName["shop.readings"].dataPath)
+		assert.Equal(t, filepath.Join(root, "shop", "system.buckets.readings.bson"), byName["shop.readings"].bucketsPath)
+	})
+
+	t.Run("buckets of a plain collection", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "system.buckets.orders.bson"), nil, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.metadata.json"), []byte(`{"options":{},"indexes":[],"collectionName":"orders","type":"collection"}`), 0o600))
+		_, err := scanDirectory(dir)
+		assert.ErrorContains(t, err, "is not a time-series collection")
+	})
+
+	t.Run("one database", func(t *testing.T) {
+		found, err := scanDirectory(filepath.Join(root, "crm"))
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "crm.people", found[0].namespace())
+	})
+
+	t.Run("several databases into one", func(t *testing.T) {
+		found, err := scanDirectory(root)
+		require.NoError(t, err)
+		err = checkTarget(models.RestoreRequest{Path: root, Database: "copy"}, found)
+		assert.ErrorContains(t, err, "more than one database")
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"vervet/internal/audit"
 	"vervet/internal/models"
 	"vervet/internal/schema"
 
@@ -23,11 +24,18 @@ type ClientProvider interface {
 	GetClient(serverID string) (*mongo.Client, error)
 }
 
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
 // CollectionsService handles operations on MongoDB collections
 type CollectionsService struct {
 	log     *slog.Logger
 	ctx     context.Context
 	clients ClientProvider
+	auditor Auditor
 }
 
 func NewCollectionsService(log *slog.Logger, clients ClientProvider) *CollectionsService {
@@ -41,6 +49,12 @@ func (s *CollectionsService) Init(ctx context.Context) {
 	s.ctx = ctx
 }
 
+// SetAuditor makes the service audit every collection it creates, renames or
+// drops.
+func (s *CollectionsService) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
 func (s *CollectionsService) GetServerStatistics(serverID string) (map[string]any, error) {
 	client, err := s.clients.GetClient(serverID)
 	if err != nil {
@@ -231,6 +245,7 @@ func (s *CollectionsService) CreateCollection(serverID, dbName, collectionName s
 	defer cancel()
 
 	err = client.Database(dbName).CreateCollection(ctx, collectionName)
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{ServerID: serverID, Database: dbName, Collection: collectionName, Operation: "createCollection", Source: models.AuditSourceApp}, err)
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
@@ -268,6 +283,14 @@ func (s *CollectionsService) RenameCollection(serverID, dbName, oldName, newName
 		{Key: "to", Value: dbName + "." + newName},
 	}
 	err = client.Database("admin").RunCommand(ctx, cmd).Err()
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+		ServerID:   serverID,
+		Database:   dbName,
+		Collection: oldName,
+		Operation:  "renameCollection",
+		Source:     models.AuditSourceApp,
+		Target:     dbName + "." + newName,
+	}, err)
 	if err != nil {
 		return fmt.Errorf("failed to rename collection: %w", err)
 	}
@@ -285,6 +308,7 @@ func (s *CollectionsService) DropCollection(serverID, dbName, collectionName str
 	defer cancel()
 
 	err = client.Database(dbName).Collection(collectionName).Drop(ctx)
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{ServerID: serverID, Database: dbName, Collection: collectionName, Operation: "dropCollection", Source: models.AuditSourceApp}, err)
 	if err != nil {
 		return fmt.Errorf("failed to drop collection: %w", err)
 	}
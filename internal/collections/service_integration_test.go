@@ -298,3 +298,35 @@ func TestIntegration_GetCollections_ExcludesViews(t *testing.T) {
 	assert.Contains(t, names, "base")
 	assert.NotContains(t, names, "excluded_view")
 }
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (a *recordingAuditor) Record(action models.AuditAction) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func TestIntegration_CollectionChanges_AreAudited(t *testing.T) {
+	db := "coll_audited"
+	seedColl(t, db, "orders")
+	auditor := &recordingAuditor{}
+	svc := newService(t)
+	svc.SetAuditor(auditor)
+
+	require.NoError(t, svc.CreateCollection("srv", db, "invoices"))
+	require.NoError(t, svc.RenameCollection("srv", db, "orders", "archive"))
+	require.NoError(t, svc.DropCollection("srv", db, "invoices"))
+	require.Error(t, svc.RenameCollection("srv", db, "missing", "other"))
+
+	ops := make([]string, len(auditor.actions))
+	for i, a := range auditor.actions {
+		ops[i] = a.Operation
+		assert.Equal(t, models.AuditSourceApp, a.Source)
+	}
+	assert.Equal(t, []string{"createCollection", "renameCollection", "dropCollection", "renameCollection"}, ops)
+	assert.Equal(t, db+".archive", auditor.actions[1].Target)
+	assert.Empty(t, auditor.actions[1].Error)
+	assert.NotEmpty(t, auditor.actions[3].Error, "failed changes are audited too")
+}
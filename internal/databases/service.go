@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"slices"
 	"time"
+	"vervet/internal/audit"
 	"vervet/internal/logging"
+	"vervet/internal/models"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -20,11 +22,18 @@ type ClientProvider interface {
 	GetClient(serverID string) (*mongo.Client, error)
 }
 
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
 // DatabasesService handles database-level operations
 type DatabasesService struct {
 	ctx     context.Context
 	log     *slog.Logger
 	clients ClientProvider
+	auditor Auditor
 }
 
 func NewDatabasesService(log *slog.Logger, clients ClientProvider) *DatabasesService {
@@ -38,6 +47,11 @@ func (s *DatabasesService) Init(ctx context.Context) {
 	s.ctx = ctx
 }
 
+// SetAuditor makes the service audit every database it drops.
+func (s *DatabasesService) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
 func (s *DatabasesService) GetDatabases(serverID string) ([]string, error) {
 	client, err := s.clients.GetClient(serverID)
 	if err != nil {
@@ -85,6 +99,7 @@ func (s *DatabasesService) DropDatabase(serverID, dbName string) error {
 	defer cancel()
 
 	err = client.Database(dbName).Drop(ctx)
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{ServerID: serverID, Database: dbName, Operation: "dropDatabase", Source: models.AuditSourceApp}, err)
 	if err != nil {
 		return fmt.Errorf("failed to drop database: %w", err)
 	}
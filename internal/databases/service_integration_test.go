@@ -17,6 +17,8 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"vervet/internal/models"
 )
 
 var testClient *mongo.Client
@@ -124,3 +126,29 @@ func TestIntegration_DropDatabase_NonExistentIsNoError(t *testing.T) {
 	// MongoDB treats dropping an absent database as success.
 	assert.NoError(t, newService(t).DropDatabase("srv", "never_existed_xyz"))
 }
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (a *recordingAuditor) Record(action models.AuditAction) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func TestIntegration_DropDatabase_IsAudited(t *testing.T) {
+	seed(t, "db_audited_drop")
+	auditor := &recordingAuditor{}
+	svc := newService(t)
+	svc.SetAuditor(auditor)
+
+	require.NoError(t, svc.DropDatabase("srv", "db_audited_drop"))
+
+	require.Len(t, auditor.actions, 1)
+	assert.Equal(t, models.AuditAction{
+		ServerID:  "srv",
+		Database:  "db_audited_drop",
+		Operation: "dropDatabase",
+		Source:    models.AuditSourceApp,
+	}, auditor.actions[0])
+}
@@ -8,18 +8,17 @@ import (
 )
 
 func serializeCSV(docs []bson.M, columns []string, opts CSVOptions) ([]byte, error) {
-	// Flatten each doc into path→value pairs.
-	rows := make([]map[string]string, len(docs))
-	for i, d := range docs {
-		pairs, err := flattenDoc(d)
+	// Flatten each doc into path→value pairs, one set per row.
+	f := newFlattener(opts)
+	rows := make([]map[string]string, 0, len(docs))
+	for _, d := range docs {
+		docRows, err := f.rows(d)
 		if err != nil {
 			return nil, err
 		}
-		m := make(map[string]string, len(pairs))
-		for _, p := range pairs {
-			m[p.path] = p.value
+		for _, pairs := range docRows {
+			rows = append(rows, pairsToRow(pairs))
 		}
-		rows[i] = m
 	}
 
 	// Derive header: explicit columns win; otherwise sorted union of keys.
@@ -77,3 +76,11 @@ func serializeCSV(docs []bson.M, columns []string, opts CSVOptions) ([]byte, err
 	}
 	return buf.Bytes(), nil
 }
+
+func pairsToRow(pairs []flatPair) map[string]string {
+	row := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		row[p.path] = p.value
+	}
+	return row
+}
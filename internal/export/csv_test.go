@@ -114,3 +114,12 @@ func TestSerializeCSV_DefaultSeparatorIsComma(t *testing.T) {
 	firstLine := strings.SplitN(out, "\n", 2)[0]
 	assert.Contains(t, firstLine, "a,b")
 }
+
+func TestSerializeCSV_UnwoundArraysRepeatTheDocument(t *testing.T) {
+	docs := []bson.M{
+		{"order": "o-1", "items": bson.A{bson.M{"sku": "A1"}, bson.M{"sku": "B2"}}},
+		{"order": "o-2", "items": bson.A{}},
+	}
+	out := serializeCSVHelper(t, docs, CSVOptions{IncludeHeader: true, Arrays: ArrayUnwind, UnwindPath: "items"})
+	assert.Equal(t, "items,items.sku,order\n,A1,o-1\n,B2,o-1\n,,o-2\n", out)
+}
@@ -7,6 +7,9 @@ import (
 )
 
 func Serialize(docs []bson.M, opts Options) ([]byte, error) {
+	if opts.Mask != nil {
+		opts.Mask.MaskMaps(docs)
+	}
 	switch opts.Format {
 	case FormatJSON:
 		return serializeJSON(docs)
@@ -14,6 +17,10 @@ func Serialize(docs []bson.M, opts Options) ([]byte, error) {
 		return serializeNDJSON(docs)
 	case FormatCSV:
 		return serializeCSV(docs, opts.Columns, opts.CSV)
+	case FormatXLSX:
+		return serializeXLSX(docs, opts.Columns)
+	case FormatMarkdown, FormatHTML, FormatSQL:
+		return serializeTable(docs, opts)
 	default:
 		return nil, fmt.Errorf("unknown format %q", opts.Format)
 	}
@@ -0,0 +1,381 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fileDocWriter writes an export to path, compressed as opts.Compression
+// asks. When opts.Split is set it starts a new numbered file whenever the
+// current one reaches a limit, each with its own writer, so every file is a
+// complete export: a JSON array, a CSV with a header, a workbook.
+type fileDocWriter struct {
+	path  string
+	opts  Options
+	parts []string // every file created, removed by discard unless Close succeeded
+	cur   *filePart
+	done  bool
+
+	// When the files' columns come from the documents, a split export
+	// spools every document first so that all its files share them.
+	spool    docWriter // shaped, behind the masking when there is some
+	shaped   *shapeSpool
+	partOpts Options // what each file is written with
+}
+
+// filePart is one open file and the writers stacked on it.
+type filePart struct {
+	f    *os.File
+	buf  *bufio.Writer
+	zip  io.WriteCloser // nil when uncompressed
+	out  *countingWriter
+	w    docWriter
+	docs int64
+	read int64 // BSON bytes of the documents written
+}
+
+func newFileDocWriter(path string, opts Options) (*fileDocWriter, error) {
+	// Fail on bad options before creating anything.
+	w, err := newDocWriter(io.Discard, opts)
+	if err != nil {
+		return nil, err
+	}
+	discard(w)
+	switch opts.Compression {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression %q", opts.Compression)
+	}
+	f := &fileDocWriter{path: path, opts: opts, partOpts: opts}
+	if opts.Split.enabled() && sharesShape(opts) {
+		if f.shaped, err = newShapeSpool(opts); err != nil {
+			return nil, err
+		}
+		f.spool = f.shaped
+		if opts.Mask != nil {
+			f.spool = &maskingWriter{next: f.shaped, mask: opts.Mask}
+		}
+	}
+	return f, nil
+}
+
+// outputPath is the file an export to path writes first: path itself, or its
+// first numbered part when the export is split.
+func outputPath(path string, opts Options) string {
+	if !opts.Split.enabled() {
+		return path
+	}
+	return partPath(path, 1, opts.Compression)
+}
+
+// partPath numbers path ahead of its extensions: orders.ndjson.gz becomes
+// orders-001.ndjson.gz.
+func partPath(path string, n int, c Compression) string {
+	dir, name := filepath.Split(path)
+	ext := ""
+	if ce := c.extension(); ce != "" && strings.HasSuffix(name, ce) {
+		name, ext = strings.TrimSuffix(name, ce), ce
+	}
+	ext = filepath.Ext(name) + ext
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return filepath.Join(dir, fmt.Sprintf("%s-%03d%s", name, n, ext))
+}
+
+func compressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressNone:
+		return nil, nil
+	case CompressGzip:
+		return gzip.NewWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression %q", c)
+	}
+}
+
+func (f *fileDocWriter) Write(doc bson.Raw) error {
+	if f.spool != nil {
+		return f.spool.Write(doc)
+	}
+	return f.write(doc)
+}
+
+func (f *fileDocWriter) write(doc bson.Raw) error {
+	if f.cur == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if err := f.cur.w.Write(doc); err != nil {
+		return err
+	}
+	f.cur.docs++
+	f.cur.read += int64(len(doc))
+	if f.full() {
+		return f.closePart()
+	}
+	return nil
+}
+
+// full reports whether the open file has reached a split limit.
+func (f *fileDocWriter) full() bool {
+	split := f.opts.Split
+	if split.Documents > 0 && f.cur.docs >= split.Documents {
+		return true
+	}
+	if split.Bytes <= 0 {
+		return false
+	}
+	size := f.cur.out.n
+	if writesAtEnd(f.opts) {
+		size = f.cur.read
+	}
+	return size >= split.Bytes
+}
+
+// writesAtEnd reports whether a format holds everything back until Close, to
+// work out its columns or build a workbook.
+func writesAtEnd(opts Options) bool {
+	switch opts.Format {
+	case FormatCSV:
+		return len(opts.Columns) == 0
+	case FormatXLSX, FormatMarkdown, FormatHTML, FormatSQL:
+		return true
+	default:
+		return false
+	}
+}
+
+// sharesShape reports whether a split export's files take their columns from
+// the documents: the header when none were chosen, and a table's column
+// types. Each file working them out from its own share would give files
+// with different columns.
+func sharesShape(opts Options) bool {
+	switch opts.Format {
+	case FormatCSV, FormatXLSX:
+		return len(opts.Columns) == 0
+	case FormatMarkdown, FormatHTML, FormatSQL:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *fileDocWriter) open() error {
+	path := f.path
+	if f.opts.Split.enabled() {
+		path = partPath(f.path, len(f.parts)+1, f.opts.Compression)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	f.parts = append(f.parts, path)
+
+	p := &filePart{f: file, buf: bufio.NewWriterSize(file, 256*1024)}
+	var dst io.Writer = p.buf
+	if p.zip, err = compressor(p.buf, f.opts.Compression); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if p.zip != nil {
+		dst = p.zip
+	}
+	p.out = &countingWriter{w: dst}
+	if p.w, err = newDocWriter(p.out, f.partOpts); err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.cur = p
+	return nil
+}
+
+// closePart finishes the open file.
+func (f *fileDocWriter) closePart() error {
+	p := f.cur
+	f.cur = nil
+	err := p.w.Close()
+	discard(p.w)
+	if err == nil && p.zip != nil {
+		err = p.zip.Close()
+	}
+	if err == nil {
+		err = p.buf.Flush()
+	}
+	if cerr := p.f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write file: %w", cerr)
+	}
+	return err
+}
+
+func (f *fileDocWriter) Close() error {
+	if f.spool != nil {
+		if err := f.writeSpooled(); err != nil {
+			return err
+		}
+	}
+	// An export of nothing still writes a file, such as an empty JSON array.
+	if f.cur == nil && len(f.parts) == 0 {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if f.cur != nil {
+		if err := f.closePart(); err != nil {
+			return err
+		}
+	}
+	f.done = true
+	return nil
+}
+
+// writeSpooled writes the spooled documents to their files, every file with
+// the columns of the whole export. They are already masked.
+func (f *fileDocWriter) writeSpooled() error {
+	defer f.shaped.discard()
+	if err := f.spool.Close(); err != nil {
+		return err
+	}
+	f.spool = nil
+	if _, err := f.shaped.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	f.partOpts.Mask = nil
+	f.partOpts.shape = f.shaped.shape
+	if len(f.partOpts.Columns) == 0 {
+		for _, c := range f.shaped.shape.columns(nil) {
+			f.partOpts.Columns = append(f.partOpts.Columns, c.path)
+		}
+	}
+	r := bufio.NewReader(f.shaped.spool)
+	for {
+		doc, err := bson.ReadDocument(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %w", err)
+		}
+		if err := f.write(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// discard removes every file written, unless Close succeeded. Safe to call
+// more than once.
+func (f *fileDocWriter) discard() {
+	if f.shaped != nil {
+		f.shaped.discard()
+	}
+	if f.cur != nil {
+		discard(f.cur.w)
+		if f.cur.zip != nil {
+			_ = f.cur.zip.Close()
+		}
+		_ = f.cur.f.Close()
+		f.cur = nil
+	}
+	if f.done {
+		return
+	}
+	for _, p := range f.parts {
+		_ = os.Remove(p)
+	}
+	f.parts = nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// shapeSpool holds back a split export whose files take their columns from
+// the documents. It spools the documents to a temporary file as BSON and
+// gathers the field paths, and their kinds, that the format would make
+// columns of.
+type shapeSpool struct {
+	format Format
+	flat   flattener
+	spool  *os.File
+	buf    *bufio.Writer
+	shape  tableShape
+}
+
+func newShapeSpool(opts Options) (*shapeSpool, error) {
+	spool, err := os.CreateTemp("", "vervet-export-*.bson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return &shapeSpool{
+		format: opts.Format,
+		flat:   newFlattener(opts.CSV),
+		spool:  spool,
+		buf:    bufio.NewWriter(spool),
+		shape:  tableShape{},
+	}, nil
+}
+
+func (s *shapeSpool) Write(doc bson.Raw) error {
+	switch s.format {
+	case FormatCSV:
+		rows, err := flattenRaw(doc, s.flat)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for k := range row {
+				if _, ok := s.shape[k]; !ok {
+					s.shape[k] = kindNone
+				}
+			}
+		}
+	case FormatXLSX:
+		pairs, err := flattenRawPairs(doc)
+		if err != nil {
+			return err
+		}
+		s.shape.add(pairs)
+	default:
+		pairs, err := tablePairsRaw(doc)
+		if err != nil {
+			return err
+		}
+		s.shape.add(pairs)
+	}
+	_, err := s.buf.Write(doc)
+	return err
+}
+
+// Close flushes the spool, leaving it for the files to be written from.
+func (s *shapeSpool) Close() error {
+	return s.buf.Flush()
+}
+
+// discard removes the temporary file. Safe to call more than once.
+func (s *shapeSpool) discard() {
+	if s.spool == nil {
+		return
+	}
+	_ = s.spool.Close()
+	_ = os.Remove(s.spool.Name())
+	s.spool = nil
+}
@@ -0,0 +1,203 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func numberedDocs(t *testing.T, n int) []bson.Raw {
+	t.Helper()
+	docs := make([]bson.D, n)
+	for i := range docs {
+		docs[i] = bson.D{{Key: "n", Value: int32(i)}}
+	}
+	return rawDocs(t, docs...)
+}
+
+func writeFiles(t *testing.T, path string, opts Options, docs []bson.Raw) {
+	t.Helper()
+	w, err := newFileDocWriter(path, opts)
+	require.NoError(t, err)
+	defer w.discard()
+	for _, d := range docs {
+		require.NoError(t, w.Write(d))
+	}
+	require.NoError(t, w.Close())
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPartPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("dir", "orders-001.ndjson"), partPath(filepath.Join("dir", "orders.ndjson"), 1, CompressNone))
+	assert.Equal(t, filepath.Join("dir", "orders-012.ndjson.gz"), partPath(filepath.Join("dir", "orders.ndjson.gz"), 12, CompressGzip))
+	assert.Equal(t, "orders-002.csv.zst", partPath("orders.csv.zst", 2, CompressZstd))
+	assert.Equal(t, "orders.csv-003.gz", partPath("orders.csv.gz", 3, CompressNone), "only the chosen compression's extension is kept")
+	assert.Equal(t, "orders-1000", partPath("orders", 1000, CompressNone))
+}
+
+func TestFileDocWriter_Plain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.ndjson")
+
+	writeFiles(t, path, Options{Format: FormatNDJSON}, numberedDocs(t, 2))
+
+	assert.Equal(t, "{\"n\":0}\n{\"n\":1}\n", readFile(t, path))
+	assert.Equal(t, path, outputPath(path, Options{}))
+}
+
+func TestFileDocWriter_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	want := "{\"n\":0}\n{\"n\":1}\n"
+
+	t.Run("gzip", func(t *testing.T) {
+		path := filepath.Join(dir, "out.ndjson.gz")
+		writeFiles(t, path, Options{Format: FormatNDJSON, Compression: CompressGzip}, numberedDocs(t, 2))
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	})
+
+	t.Run("zstd", func(t *testing.T) {
+		path := filepath.Join(dir, "out.ndjson.zst")
+		writeFiles(t, path, Options{Format: FormatNDJSON, Compression: CompressZstd}, numberedDocs(t, 2))
+
+		r, err := zstd.NewReader(bytes.NewReader([]byte(readFile(t, path))))
+		require.NoError(t, err)
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	})
+}
+
+func TestFileDocWriter_SplitByDocuments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+	opts := Options{Format: FormatJSON, Split: SplitOptions{Documents: 2}}
+
+	writeFiles(t, path, opts, numberedDocs(t, 5))
+
+	// Each file is a complete JSON array.
+	assert.Equal(t, "[\n  {\n    \"n\": 0\n  },\n  {\n    \"n\": 1\n  }\n]", readFile(t, filepath.Join(dir, "out-001.json")))
+	assert.Equal(t, "[\n  {\n    \"n\": 4\n  }\n]", readFile(t, filepath.Join(dir, "out-003.json")))
+	assert.NoFileExists(t, filepath.Join(dir, "out-004.json"))
+	assert.NoFileExists(t, path)
+	assert.Equal(t, filepath.Join(dir, "out-001.json"), outputPath(path, opts))
+}
+
+func TestFileDocWriter_SplitByBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("counts the output", func(t *testing.T) {
+		// Each line is 8 bytes, so a file closes after its second.
+		path := filepath.Join(dir, "out.ndjson")
+		writeFiles(t, path, Options{Format: FormatNDJSON, Split: SplitOptions{Bytes: 16}}, numberedDocs(t, 3))
+
+		assert.Equal(t, "{\"n\":0}\n{\"n\":1}\n", readFile(t, filepath.Join(dir, "out-001.ndjson")))
+		assert.Equal(t, "{\"n\":2}\n", readFile(t, filepath.Join(dir, "out-002.ndjson")))
+	})
+
+	t.Run("counts the documents for formats that write at the end", func(t *testing.T) {
+		// Each document is 12 bytes of BSON.
+		path := filepath.Join(dir, "out.csv")
+		opts := Options{Format: FormatCSV, CSV: CSVOptions{IncludeHeader: true}, Split: SplitOptions{Bytes: 24}}
+		writeFiles(t, path, opts, numberedDocs(t, 3))
+
+		assert.Equal(t, "n\n0\n1\n", readFile(t, filepath.Join(dir, "out-001.csv")))
+		assert.Equal(t, "n\n2\n", readFile(t, filepath.Join(dir, "out-002.csv")))
+	})
+}
+
+func TestFileDocWriter_SplitPartsShareColumns(t *testing.T) {
+	dir := t.TempDir()
+	docs := rawDocs(t,
+		bson.D{{Key: "b", Value: int32(1)}},
+		bson.D{{Key: "a", Value: "x"}, {Key: "b", Value: "two"}},
+	)
+	opts := Options{Split: SplitOptions{Documents: 1}}
+
+	t.Run("csv header", func(t *testing.T) {
+		path := filepath.Join(dir, "out.csv")
+		opts := opts
+		opts.Format, opts.CSV = FormatCSV, CSVOptions{IncludeHeader: true}
+		writeFiles(t, path, opts, docs)
+
+		assert.Equal(t, "a,b\n,1\n", readFile(t, filepath.Join(dir, "out-001.csv")))
+		assert.Equal(t, "a,b\nx,two\n", readFile(t, filepath.Join(dir, "out-002.csv")))
+	})
+
+	t.Run("sql column types", func(t *testing.T) {
+		path := filepath.Join(dir, "out.sql")
+		opts := opts
+		opts.Format, opts.SQL = FormatSQL, SQLOptions{Dialect: DialectPostgres}
+		writeFiles(t, path, opts, docs)
+
+		for _, part := range []string{"out-001.sql", "out-002.sql"} {
+			assert.Contains(t, readFile(t, filepath.Join(dir, part)), `"a" TEXT,
+  "b" TEXT`, part)
+		}
+	})
+}
+
+func TestFileDocWriter_EmptyExportWritesOneFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	writeFiles(t, path, Options{Format: FormatJSON, Split: SplitOptions{Documents: 10}}, nil)
+
+	assert.Equal(t, "[]", readFile(t, filepath.Join(filepath.Dir(path), "out-001.json")))
+}
+
+func TestFileDocWriter_DiscardRemovesEveryPart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.ndjson")
+	w, err := newFileDocWriter(path, Options{Format: FormatNDJSON, Split: SplitOptions{Documents: 1}})
+	require.NoError(t, err)
+	for _, d := range numberedDocs(t, 3) {
+		require.NoError(t, w.Write(d))
+	}
+
+	w.discard()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFileDocWriter_BadOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+
+	_, err := newFileDocWriter(path, Options{Format: FormatNDJSON, Compression: "lz4"})
+	assert.ErrorContains(t, err, `unknown compression "lz4"`)
+
+	_, err = newFileDocWriter(path, Options{Format: "xml"})
+	assert.Error(t, err)
+	assert.NoFileExists(t, path)
+}
+
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	c := &countingWriter{w: &buf}
+	_, err := io.WriteString(c, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), c.n)
+	assert.Equal(t, "hello", buf.String())
+}
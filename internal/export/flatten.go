@@ -1,10 +1,12 @@
 package export
 
 import (
+	"encoding/base64"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -12,83 +14,250 @@ import (
 type flatPair struct {
 	path  string
 	value string
+	// raw is the leaf value value was formatted from, for writers that keep
+	// native types.
+	raw any
+}
+
+// flattener turns documents into rows of dot-path/value pairs. The zero value
+// keeps arrays as Extended JSON and writes special BSON types as relaxed
+// Extended JSON.
+type flattener struct {
+	arrays     ArrayMode
+	delimiter  string
+	unwindPath string
+	values     ValueMode
+}
+
+func newFlattener(opts CSVOptions) flattener {
+	delimiter := opts.ArrayDelimiter
+	if delimiter == "" {
+		delimiter = DefaultArrayDelimiter
+	}
+	return flattener{
+		arrays:     opts.Arrays,
+		delimiter:  delimiter,
+		unwindPath: opts.UnwindPath,
+		values:     opts.Values,
+	}
 }
 
 // flattenDoc converts a BSON document into a flat list of dot-path/value pairs.
 // Nested objects are recursed with dot-joined paths. Arrays and BSON special types
-// are serialised as canonical Extended JSON strings. Keys at each level are sorted
+// are serialised as relaxed Extended JSON strings. Keys at each level are sorted
 // alphabetically for deterministic output.
 func flattenDoc(doc bson.M) ([]flatPair, error) {
-	return walkDoc(doc, "")
+	rows, err := flattener{}.rows(doc)
+	if err != nil {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// rows flattens doc into one row, or, when an array is unwound, one row per
+// element of the array at unwindPath. Only that one array is unwound, as
+// unwinding several would give a row for every combination of their
+// elements, which grows with the product of their lengths.
+func (f flattener) rows(doc bson.M) ([][]flatPair, error) {
+	return f.walkDoc(doc, "")
 }
 
-func walkDoc(doc bson.M, prefix string) ([]flatPair, error) {
-	keys := sortedKeys(doc)
-	var pairs []flatPair
-	for _, k := range keys {
+func (f flattener) walkDoc(doc bson.M, prefix string) ([][]flatPair, error) {
+	rows := [][]flatPair{nil}
+	for _, k := range sortedKeys(doc) {
 		path := k
 		if prefix != "" {
 			path = prefix + "." + k
 		}
-		nested, err := walkValue(path, doc[k])
+		alts, err := f.walkValue(path, doc[k])
 		if err != nil {
 			return nil, err
 		}
-		pairs = append(pairs, nested...)
+		rows = cross(rows, alts)
 	}
-	return pairs, nil
+	return rows, nil
 }
 
-func walkValue(path string, v any) ([]flatPair, error) {
-	switch val := v.(type) {
-	case nil:
-		return []flatPair{{path: path, value: ""}}, nil
-
-	case string:
-		return []flatPair{{path: path, value: val}}, nil
-
-	case bool:
-		return []flatPair{{path: path, value: strconv.FormatBool(val)}}, nil
+// cross appends every alternative to every row.
+func cross(rows, alts [][]flatPair) [][]flatPair {
+	if len(alts) == 1 {
+		for i := range rows {
+			rows[i] = append(rows[i], alts[0]...)
+		}
+		return rows
+	}
+	out := make([][]flatPair, 0, len(rows)*len(alts))
+	for _, r := range rows {
+		for _, a := range alts {
+			row := make([]flatPair, 0, len(r)+len(a))
+			row = append(append(row, r...), a...)
+			out = append(out, row)
+		}
+	}
+	return out
+}
 
-	case int:
-		return []flatPair{{path: path, value: strconv.FormatInt(int64(val), 10)}}, nil
+// walkValue flattens the value at path. It returns one list of pairs, or
+// one per element of an unwound array.
+func (f flattener) walkValue(path string, v any) ([][]flatPair, error) {
+	switch val := v.(type) {
+	case bson.M:
+		return f.walkDoc(val, path)
 
-	case int32:
-		return []flatPair{{path: path, value: strconv.FormatInt(int64(val), 10)}}, nil
+	case bson.D:
+		m := make(bson.M, len(val))
+		for _, e := range val {
+			m[e.Key] = e.Value
+		}
+		return f.walkDoc(m, path)
 
-	case int64:
-		return []flatPair{{path: path, value: strconv.FormatInt(val, 10)}}, nil
+	case bson.A:
+		return f.walkArray(path, val)
 
-	case float32:
-		return []flatPair{{path: path, value: strconv.FormatFloat(float64(val), 'f', -1, 64)}}, nil
+	default:
+		s, err := f.format(val)
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", path, err)
+		}
+		return [][]flatPair{{{path: path, value: s, raw: v}}}, nil
+	}
+}
 
-	case float64:
-		return []flatPair{{path: path, value: strconv.FormatFloat(val, 'f', -1, 64)}}, nil
+func (f flattener) walkArray(path string, arr bson.A) ([][]flatPair, error) {
+	switch {
+	case f.arrays == ArrayUnwind && f.unwindPath == path:
+		if len(arr) == 0 {
+			// Like $unwind with preserveNullAndEmptyArrays, so no document
+			// drops out of the export.
+			return [][]flatPair{{{path: path}}}, nil
+		}
+		var alts [][]flatPair
+		for _, el := range arr {
+			elAlts, err := f.walkValue(path, el)
+			if err != nil {
+				return nil, err
+			}
+			alts = append(alts, elAlts...)
+		}
+		return alts, nil
 
-	case bson.M:
-		return walkDoc(val, path)
+	case f.arrays == ArrayIndexed:
+		rows := [][]flatPair{nil}
+		for i, el := range arr {
+			alts, err := f.walkValue(path+"."+strconv.Itoa(i), el)
+			if err != nil {
+				return nil, err
+			}
+			rows = cross(rows, alts)
+		}
+		return rows, nil
 
-	case bson.A:
-		s, err := marshalAsEJSONString(val)
-		if err != nil {
-			return nil, fmt.Errorf("path %q: %w", path, err)
+	case f.arrays == ArrayJoin && scalarArray(arr):
+		parts := make([]string, len(arr))
+		for i, el := range arr {
+			s, err := f.format(el)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", path, err)
+			}
+			parts[i] = s
 		}
-		return []flatPair{{path: path, value: s}}, nil
+		return [][]flatPair{{{path: path, value: strings.Join(parts, f.delimiter), raw: arr}}}, nil
 
 	default:
-		// BSON special types (ObjectID, DateTime, Decimal128, Binary, etc.)
-		s, err := marshalAsEJSONString(val)
+		s, err := f.ejson(arr)
 		if err != nil {
 			return nil, fmt.Errorf("path %q: %w", path, err)
 		}
-		return []flatPair{{path: path, value: s}}, nil
+		return [][]flatPair{{{path: path, value: s, raw: arr}}}, nil
+	}
+}
+
+// scalarArray reports whether arr holds no documents or arrays, so its
+// elements can be joined into one cell.
+func scalarArray(arr bson.A) bool {
+	for _, el := range arr {
+		switch el.(type) {
+		case bson.M, bson.D, bson.A:
+			return false
+		}
+	}
+	return true
+}
+
+// format writes a leaf value as cell text.
+func (f flattener) format(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 64), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	}
+	if f.values == ValuePlain {
+		if s, ok := plainValue(v); ok {
+			return s, nil
+		}
+	}
+	// BSON special types (ObjectID, DateTime, Decimal128, Binary, etc.)
+	return f.ejson(v)
+}
+
+func (f flattener) ejson(v any) (string, error) {
+	return marshalAsEJSONString(v, f.values == ValueCanonical)
+}
+
+// plainValue writes the BSON types spreadsheets have no use for as Extended
+// JSON in their everyday form: an ObjectId as hex, a date in ISO 8601. ok
+// is false for types without one, which stay Extended JSON.
+func plainValue(v any) (s string, ok bool) {
+	switch val := v.(type) {
+	case bson.ObjectID:
+		return val.Hex(), true
+	case bson.DateTime:
+		return val.Time().UTC().Format(time.RFC3339Nano), true
+	case bson.Decimal128:
+		return val.String(), true
+	case bson.Timestamp:
+		return time.Unix(int64(val.T), 0).UTC().Format(time.RFC3339), true
+	case bson.Binary:
+		if val.Subtype == bson.TypeBinaryUUID && len(val.Data) == 16 {
+			d := val.Data
+			return fmt.Sprintf("%x-%x-%x-%x-%x", d[0:4], d[4:6], d[6:8], d[8:10], d[10:16]), true
+		}
+		return base64.StdEncoding.EncodeToString(val.Data), true
+	case bson.Regex:
+		return "/" + val.Pattern + "/" + val.Options, true
+	case bson.JavaScript:
+		return string(val), true
+	case bson.Symbol:
+		return string(val), true
+	case bson.Null, bson.Undefined:
+		return "", true
+	case bson.MinKey:
+		return "MinKey", true
+	case bson.MaxKey:
+		return "MaxKey", true
+	default:
+		return "", false
 	}
 }
 
 // marshalAsEJSONString serialises an arbitrary BSON-compatible value as its
-// canonical Extended JSON text representation (without surrounding braces).
-func marshalAsEJSONString(v any) (string, error) {
-	data, err := bson.MarshalExtJSON(bson.M{"v": v}, false, false)
+// Extended JSON text representation (without surrounding braces), relaxed
+// unless canonical is set.
+func marshalAsEJSONString(v any, canonical bool) (string, error) {
+	data, err := bson.MarshalExtJSON(bson.M{"v": v}, canonical, false)
 	if err != nil {
 		return "", fmt.Errorf("marshal ext-json: %w", err)
 	}
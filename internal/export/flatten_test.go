@@ -2,6 +2,7 @@ package export
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -85,3 +86,143 @@ func pairsToMap(pairs []flatPair) map[string]string {
 	}
 	return m
 }
+
+func flattenRows(t *testing.T, opts CSVOptions, doc bson.M) []map[string]string {
+	t.Helper()
+	rows, err := newFlattener(opts).rows(doc)
+	require.NoError(t, err)
+	out := make([]map[string]string, len(rows))
+	for i, r := range rows {
+		out[i] = pairsToMap(r)
+	}
+	return out
+}
+
+func TestFlattenDoc_NestedBSONDUsesDotPaths(t *testing.T) {
+	doc := bson.M{"address": bson.D{{Key: "city", Value: "Paris"}}}
+	pairs, err := flattenDoc(doc)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"address.city": "Paris"}, pairsToMap(pairs))
+}
+
+func TestFlattener_IndexedArrays(t *testing.T) {
+	doc := bson.M{
+		"tags":  bson.A{"admin", "ops"},
+		"items": bson.A{bson.D{{Key: "sku", Value: "A1"}}, bson.D{{Key: "sku", Value: "B2"}}},
+		"empty": bson.A{},
+	}
+
+	rows := flattenRows(t, CSVOptions{Arrays: ArrayIndexed}, doc)
+
+	assert.Equal(t, []map[string]string{{
+		"tags.0":      "admin",
+		"tags.1":      "ops",
+		"items.0.sku": "A1",
+		"items.1.sku": "B2",
+	}}, rows)
+}
+
+func TestFlattener_JoinedArrays(t *testing.T) {
+	doc := bson.M{
+		"tags":  bson.A{"admin", "ops", int32(3)},
+		"items": bson.A{bson.M{"sku": "A1"}},
+	}
+
+	t.Run("default delimiter", func(t *testing.T) {
+		rows := flattenRows(t, CSVOptions{Arrays: ArrayJoin}, doc)
+		assert.Equal(t, "admin;ops;3", rows[0]["tags"])
+		assert.Equal(t, `[{"sku":"A1"}]`, rows[0]["items"], "arrays of documents stay Extended JSON")
+	})
+
+	t.Run("chosen delimiter", func(t *testing.T) {
+		rows := flattenRows(t, CSVOptions{Arrays: ArrayJoin, ArrayDelimiter: " | "}, doc)
+		assert.Equal(t, "admin | ops | 3", rows[0]["tags"])
+	})
+}
+
+func TestFlattener_UnwindChosenPath(t *testing.T) {
+	doc := bson.M{
+		"order": "o-1",
+		"items": bson.A{bson.M{"sku": "A1", "qty": int32(2)}, bson.M{"sku": "B2", "qty": int32(1)}},
+		"tags":  bson.A{"gift"},
+	}
+
+	rows := flattenRows(t, CSVOptions{Arrays: ArrayUnwind, UnwindPath: "items"}, doc)
+
+	assert.Equal(t, []map[string]string{
+		{"order": "o-1", "items.sku": "A1", "items.qty": "2", "tags": `["gift"]`},
+		{"order": "o-1", "items.sku": "B2", "items.qty": "1", "tags": `["gift"]`},
+	}, rows)
+}
+
+func TestFlattener_UnwindOneArrayOnly(t *testing.T) {
+	doc := bson.M{
+		"a": bson.A{"x", "y"},
+		"b": bson.A{int32(1), int32(2)},
+		"c": bson.A{},
+	}
+
+	rows := flattenRows(t, CSVOptions{Arrays: ArrayUnwind, UnwindPath: "a"}, doc)
+	assert.Equal(t, []map[string]string{
+		{"a": "x", "b": "[1,2]", "c": "[]"},
+		{"a": "y", "b": "[1,2]", "c": "[]"},
+	}, rows, "no cross product with the other arrays")
+
+	rows = flattenRows(t, CSVOptions{Arrays: ArrayUnwind, UnwindPath: "c"}, doc)
+	assert.Len(t, rows, 1, "an empty array keeps its document")
+	assert.Equal(t, "", rows[0]["c"])
+}
+
+func TestFlattener_ValueModes(t *testing.T) {
+	oid, err := bson.ObjectIDFromHex("65f0a1b2c3d4e5f601234567")
+	require.NoError(t, err)
+	when := bson.NewDateTimeFromTime(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC))
+	dec, err := bson.ParseDecimal128("12.50")
+	require.NoError(t, err)
+	uuid := bson.Binary{Subtype: bson.TypeBinaryUUID, Data: []byte{
+		0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0,
+	}}
+	doc := bson.M{"_id": oid, "at": when, "price": dec, "uuid": uuid, "big": int64(7), "ids": bson.A{oid}}
+
+	tests := []struct {
+		mode ValueMode
+		want map[string]string
+	}{
+		{"", map[string]string{
+			"_id":   `{"$oid":"65f0a1b2c3d4e5f601234567"}`,
+			"at":    `{"$date":"2026-03-01T09:30:00Z"}`,
+			"price": `{"$numberDecimal":"12.50"}`,
+			"uuid":  `{"$binary":{"base64":"EjRWeJq83vASNFZ4mrze8A==","subType":"04"}}`,
+			"big":   "7",
+			"ids":   `[{"$oid":"65f0a1b2c3d4e5f601234567"}]`,
+		}},
+		{ValueCanonical, map[string]string{
+			"_id":   `{"$oid":"65f0a1b2c3d4e5f601234567"}`,
+			"at":    `{"$date":{"$numberLong":"1772357400000"}}`,
+			"price": `{"$numberDecimal":"12.50"}`,
+			"uuid":  `{"$binary":{"base64":"EjRWeJq83vASNFZ4mrze8A==","subType":"04"}}`,
+			"big":   "7",
+			"ids":   `[{"$oid":"65f0a1b2c3d4e5f601234567"}]`,
+		}},
+		{ValuePlain, map[string]string{
+			"_id":   "65f0a1b2c3d4e5f601234567",
+			"at":    "2026-03-01T09:30:00Z",
+			"price": "12.50",
+			"uuid":  "12345678-9abc-def0-1234-56789abcdef0",
+			"big":   "7",
+			"ids":   `[{"$oid":"65f0a1b2c3d4e5f601234567"}]`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			rows := flattenRows(t, CSVOptions{Values: tt.mode}, doc)
+			assert.Equal(t, tt.want, rows[0])
+		})
+	}
+
+	t.Run("plain values in joined arrays", func(t *testing.T) {
+		rows := flattenRows(t, CSVOptions{Values: ValuePlain, Arrays: ArrayJoin}, doc)
+		assert.Equal(t, "65f0a1b2c3d4e5f601234567", rows[0]["ids"])
+	})
+}
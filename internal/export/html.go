@@ -0,0 +1,106 @@
+package export
+
+import (
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// htmlStyle is the page's only styling, inline so the file stands alone.
+const htmlStyle = `body{font-family:system-ui,sans-serif;font-size:14px;margin:16px}
+table{border-collapse:collapse}
+th,td{border:1px solid #d0d7de;padding:4px 8px;text-align:left;vertical-align:top}
+th{background:#f6f8fa;position:sticky;top:0}
+td.num,th.num{text-align:right;font-variant-numeric:tabular-nums}
+td.null{color:#8c959f;font-style:italic}
+td.bool{color:#0550ae}
+td.id,td code{font-family:ui-monospace,monospace;font-size:12px}
+td code{white-space:pre-wrap}`
+
+// htmlTable writes a self-contained HTML page holding one table. Cells are
+// formatted for their type: numbers right-aligned, dates readable, nulls
+// greyed and arrays as code.
+type htmlTable struct {
+	w     io.Writer
+	title string
+	b     strings.Builder
+}
+
+func (h *htmlTable) begin(columns []tableColumn) error {
+	h.b.Reset()
+	h.b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>")
+	h.b.WriteString(html.EscapeString(h.title))
+	h.b.WriteString("</title>\n<style>\n")
+	h.b.WriteString(htmlStyle)
+	h.b.WriteString("\n</style>\n</head>\n<body>\n<table>\n<thead>\n<tr>")
+	for _, c := range columns {
+		if isNumber(c.kind) {
+			h.b.WriteString(`<th class="num">`)
+		} else {
+			h.b.WriteString("<th>")
+		}
+		h.b.WriteString(html.EscapeString(c.path))
+		h.b.WriteString("</th>")
+	}
+	h.b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	return h.flush()
+}
+
+func (h *htmlTable) row(cells []*flatPair) error {
+	h.b.Reset()
+	h.b.WriteString("<tr>")
+	for _, c := range cells {
+		h.cell(c)
+	}
+	h.b.WriteString("</tr>\n")
+	return h.flush()
+}
+
+func (h *htmlTable) cell(c *flatPair) {
+	if c == nil {
+		h.b.WriteString("<td></td>")
+		return
+	}
+	text := html.EscapeString(c.value)
+	switch v := c.raw.(type) {
+	case nil, bson.Null, bson.Undefined:
+		h.b.WriteString(`<td class="null">null</td>`)
+	case int, int32, int64, float32, float64, bson.Decimal128:
+		h.b.WriteString(`<td class="num">` + text + "</td>")
+	case bool:
+		h.b.WriteString(`<td class="bool">` + text + "</td>")
+	case bson.DateTime:
+		t := v.Time().UTC()
+		h.b.WriteString(`<td class="date"><time datetime="` + t.Format(time.RFC3339Nano) + `">` +
+			readableTime(t) + "</time></td>")
+	case bson.ObjectID:
+		h.b.WriteString(`<td class="id">` + text + "</td>")
+	case bson.A:
+		h.b.WriteString("<td><code>" + text + "</code></td>")
+	default:
+		h.b.WriteString("<td>" + text + "</td>")
+	}
+}
+
+// readableTime shows a date to the second, or to the millisecond when it
+// has them.
+func readableTime(t time.Time) string {
+	if t.Nanosecond() != 0 {
+		return t.Format("2006-01-02 15:04:05.000") + " UTC"
+	}
+	return t.Format("2006-01-02 15:04:05") + " UTC"
+}
+
+func (h *htmlTable) end() error {
+	h.b.Reset()
+	h.b.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+	return h.flush()
+}
+
+func (h *htmlTable) flush() error {
+	_, err := io.WriteString(h.w, h.b.String())
+	return err
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSerializeHTML(t *testing.T) {
+	when := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	id, err := bson.ObjectIDFromHex("65f0a1b2c3d4e5f601234567")
+	require.NoError(t, err)
+	docs := []bson.M{{
+		"_id":    id,
+		"name":   "<Ada & co>",
+		"age":    int32(36),
+		"active": true,
+		"joined": bson.NewDateTimeFromTime(when),
+		"left":   nil,
+		"tags":   bson.A{"x"},
+	}}
+
+	out, err := Serialize(docs, Options{Format: FormatHTML, Table: "people"})
+	require.NoError(t, err)
+	page := string(out)
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>\n"))
+	assert.Contains(t, page, "<title>people</title>")
+	assert.Contains(t, page, "<style>")
+	assert.Contains(t, page, `<tr><th>_id</th><th>active</th><th class="num">age</th><th>joined</th><th>left</th><th>name</th><th>tags</th></tr>`)
+	assert.Contains(t, page, `<td class="id">65f0a1b2c3d4e5f601234567</td>`)
+	assert.Contains(t, page, `<td class="bool">true</td>`)
+	assert.Contains(t, page, `<td class="num">36</td>`)
+	assert.Contains(t, page, `<td class="date"><time datetime="2026-03-01T09:30:00Z">2026-03-01 09:30:00 UTC</time></td>`)
+	assert.Contains(t, page, `<td class="null">null</td>`)
+	assert.Contains(t, page, `<td>&lt;Ada &amp; co&gt;</td>`)
+	assert.Contains(t, page, `<td><code>[&#34;x&#34;]</code></td>`)
+	assert.True(t, strings.HasSuffix(page, "</table>\n</body>\n</html>\n"))
+}
+
+func TestSerializeHTML_MissingFieldIsEmpty(t *testing.T) {
+	out, err := Serialize([]bson.M{{"a": 1}, {"b": 2}}, Options{Format: FormatHTML})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "<title>export</title>")
+	assert.Contains(t, string(out), `<tr><td class="num">1</td><td></td></tr>`)
+	assert.Contains(t, string(out), `<tr><td></td><td class="num">2</td></tr>`)
+}
+
+func TestReadableTime(t *testing.T) {
+	assert.Equal(t, "2026-03-01 09:30:00 UTC", readableTime(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "2026-03-01 09:30:00.250 UTC", readableTime(time.Date(2026, 3, 1, 9, 30, 0, 250e6, time.UTC)))
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"io"
+	"strings"
+)
+
+// markdownTable writes a GitHub Flavored Markdown table. Number columns are
+// right-aligned.
+type markdownTable struct {
+	w io.Writer
+	// empty is set when there are no columns, which a table can't have.
+	empty bool
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+func (m *markdownTable) begin(columns []tableColumn) error {
+	if len(columns) == 0 {
+		m.empty = true
+		return nil
+	}
+	var b strings.Builder
+	for _, c := range columns {
+		b.WriteString("| ")
+		b.WriteString(markdownEscaper.Replace(c.path))
+		b.WriteByte(' ')
+	}
+	b.WriteString("|\n")
+	for _, c := range columns {
+		if isNumber(c.kind) {
+			b.WriteString("| ---: ")
+		} else {
+			b.WriteString("| --- ")
+		}
+	}
+	b.WriteString("|\n")
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownTable) row(cells []*flatPair) error {
+	if m.empty {
+		return nil
+	}
+	var b strings.Builder
+	for _, c := range cells {
+		b.WriteString("| ")
+		if c != nil {
+			b.WriteString(markdownEscaper.Replace(c.value))
+		}
+		b.WriteByte(' ')
+	}
+	b.WriteString("|\n")
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownTable) end() error {
+	return nil
+}
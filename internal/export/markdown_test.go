@@ -0,0 +1,39 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSerializeMarkdown(t *testing.T) {
+	docs := []bson.M{
+		{"name": "Ada", "age": int32(36), "tags": bson.A{"x", "y"}},
+		{"name": "Grace|Hopper", "note": "line one\nline two"},
+	}
+
+	out, err := Serialize(docs, Options{Format: FormatMarkdown})
+	require.NoError(t, err)
+
+	assert.Equal(t, "| age | name | note | tags |\n"+
+		"| ---: | --- | --- | --- |\n"+
+		`| 36 | Ada |  | ["x","y"] |`+"\n"+
+		`|  | Grace\|Hopper | line one<br>line two |  |`+"\n", string(out))
+}
+
+func TestSerializeMarkdown_ChosenColumns(t *testing.T) {
+	docs := []bson.M{{"a": 1, "b": "x|y", "c": true}}
+
+	out, err := Serialize(docs, Options{Format: FormatMarkdown, Columns: []string{"b", "missing"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "| b | missing |\n| --- | --- |\n| x\\|y |  |\n", string(out))
+}
+
+func TestSerializeMarkdown_NoColumns(t *testing.T) {
+	out, err := Serialize(nil, Options{Format: FormatMarkdown})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
@@ -1,21 +1,137 @@
 package export
 
+import "vervet/internal/masking"
+
 type Format string
 
 const (
 	FormatCSV    Format = "csv"
 	FormatJSON   Format = "json"
 	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+
+	// FormatMarkdown writes a GitHub-flavoured Markdown table.
+	FormatMarkdown Format = "markdown"
+	// FormatHTML writes a self-contained HTML page holding one table.
+	FormatHTML Format = "html"
+	// FormatSQL writes a CREATE TABLE statement and an INSERT per document.
+	FormatSQL Format = "sql"
+)
+
+// ArrayMode is how a CSV export writes arrays.
+type ArrayMode string
+
+const (
+	// ArrayEJSON writes an array as one Extended JSON cell. The default.
+	ArrayEJSON ArrayMode = "ejson"
+	// ArrayIndexed gives each element its own column: tags.0, tags.1.
+	ArrayIndexed ArrayMode = "indexed"
+	// ArrayJoin writes the elements of an array of scalars as one cell,
+	// separated by ArrayDelimiter. Arrays holding documents or arrays stay
+	// Extended JSON.
+	ArrayJoin ArrayMode = "join"
+	// ArrayUnwind writes a row per element of the array at UnwindPath,
+	// repeating the rest of the document. Other arrays stay Extended JSON.
+	ArrayUnwind ArrayMode = "unwind"
+)
+
+// DefaultArrayDelimiter separates joined array elements when no delimiter is
+// chosen.
+const DefaultArrayDelimiter = ";"
+
+// ValueMode is how a CSV export writes BSON types with no plain JSON
+// equivalent, such as ObjectId and Date.
+type ValueMode string
+
+const (
+	// ValueRelaxed writes relaxed Extended JSON: {"$oid":"..."},
+	// {"$date":"2026-03-01T09:30:00Z"}. The default.
+	ValueRelaxed ValueMode = "relaxed"
+	// ValueCanonical writes canonical Extended JSON:
+	// {"$date":{"$numberLong":"..."}}.
+	ValueCanonical ValueMode = "canonical"
+	// ValuePlain writes the value alone: ObjectId hex, ISO 8601 dates,
+	// decimal strings.
+	ValuePlain ValueMode = "plain"
 )
 
 type CSVOptions struct {
 	Separator     rune
 	IncludeHeader bool
 	UTF8BOM       bool
+
+	Arrays         ArrayMode // empty means ArrayEJSON
+	ArrayDelimiter string    // for ArrayJoin; empty means DefaultArrayDelimiter
+	UnwindPath     string    // for ArrayUnwind, which needs it
+	Values         ValueMode // empty means ValueRelaxed
+}
+
+// SQLDialect is the database a SQL export's statements are written for.
+type SQLDialect string
+
+const (
+	DialectPostgres  SQLDialect = "postgres"
+	DialectMySQL     SQLDialect = "mysql"
+	DialectSQLite    SQLDialect = "sqlite"
+	DialectSQLServer SQLDialect = "sqlserver"
+)
+
+type SQLOptions struct {
+	Dialect SQLDialect // empty means DialectPostgres
+}
+
+// Compression is how an export's files are compressed.
+type Compression string
+
+const (
+	CompressNone Compression = ""
+	CompressGzip Compression = "gzip"
+	CompressZstd Compression = "zstd"
+)
+
+// extension is the suffix a file compressed this way ends with.
+func (c Compression) extension() string {
+	switch c {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// SplitOptions splits an export into numbered files, each a complete export
+// of its share of the documents. A file ends at whichever limit it reaches
+// first.
+type SplitOptions struct {
+	Documents int64 // documents per file; 0 means no limit
+	// Bytes ends a file once this much has been written to it, before
+	// compression. Formats that only write when a file is finished count the
+	// documents' BSON size instead. 0 means no limit.
+	Bytes int64
+}
+
+func (s SplitOptions) enabled() bool {
+	return s.Documents > 0 || s.Bytes > 0
 }
 
 type Options struct {
 	Format  Format
 	Columns []string   // optional dot-paths; nil means "derive from docs"
 	CSV     CSVOptions // ignored when Format != FormatCSV
+	SQL     SQLOptions // ignored when Format != FormatSQL
+	// Table names the SQL table and titles the HTML page; empty means
+	// "export".
+	Table string
+	// Mask, when set, masks the documents before they are written.
+	Mask *masking.Masker
+
+	// Compression and Split apply to files, not to Serialize.
+	Compression Compression
+	Split       SplitOptions
+
+	// shape, set for the files of a split export, is the whole export's
+	// columns and their kinds, so every file's table agrees.
+	shape tableShape
 }
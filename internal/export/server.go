@@ -0,0 +1,206 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"vervet/internal/api"
+	"vervet/internal/models"
+	"vervet/internal/pipeline"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ExportProgressEvent carries a models.ExportProgress from a running server
+// export.
+const ExportProgressEvent = "export-progress"
+
+// progressInterval is the least time between progress events for one export.
+const progressInterval = 100 * time.Millisecond
+
+// CursorSource opens cursors on a connected server.
+// Implemented by queryexecutor.QueryExecutor.
+type CursorSource interface {
+	OpenPageCursor(ctx context.Context, serverID, dbName string, pc models.PageContext) (*mongo.Cursor, error)
+	OpenAggregateCursor(ctx context.Context, serverID, dbName, collection string, pipeline bson.A) (*mongo.Cursor, error)
+	CountForPage(serverID, dbName string, pc models.PageContext) (int64, bool, error)
+}
+
+// cursor is the part of *mongo.Cursor an export reads through.
+type cursor interface {
+	Next(ctx context.Context) bool
+	Decode(val any) error
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// Cancel stops a running server export. The partly written file is removed.
+func (s *Service) Cancel(exportID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[exportID]; ok {
+		cancel()
+	}
+}
+
+// exportFromServer streams everything req's find or pipeline returns to a
+// user-chosen file. A cancelled export returns an empty path, like a
+// cancelled save dialog.
+func (s *Service) exportFromServer(req api.ExportRequest) (string, error) {
+	if s.source == nil {
+		return "", errors.New("server export is not available")
+	}
+	if req.PageContext == nil && req.Pipeline == "" {
+		return "", errors.New("a server export needs a query or a pipeline")
+	}
+	var stages bson.A
+	if req.PageContext == nil {
+		var err error
+		if stages, err = pipeline.Parse(req.Pipeline); err != nil {
+			return "", err
+		}
+	}
+	opts, err := s.options(req)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := s.choosePath(req, opts)
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.register(req.ExportID, cancel)
+	defer s.unregister(req.ExportID)
+
+	var cur cursor
+	var total atomic.Int64
+	if req.PageContext != nil {
+		cur, err = s.source.OpenPageCursor(ctx, req.ServerID, req.Database, *req.PageContext)
+		// Counting can take as long as reading on a large filtered
+		// collection, so the total arrives in a later progress event.
+		go func() {
+			if n, _, err := s.source.CountForPage(req.ServerID, req.Database, *req.PageContext); err == nil {
+				total.Store(n)
+			}
+		}()
+	} else {
+		cur, err = s.source.OpenAggregateCursor(ctx, req.ServerID, req.Database, req.CollectionName, stages)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer cur.Close(context.Background())
+
+	var throttle progressThrottle
+	n, err := s.streamToFile(ctx, cur, path, opts, func(written int64, final bool) {
+		if throttle.due(final) {
+			s.sendProgress(req.ExportID, written, total.Load())
+		}
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			s.log.Info("Export cancelled", slog.String("path", path), slog.Int64("documents", n))
+			return "", nil
+		}
+		return "", err
+	}
+	return outputPath(path, opts), nil
+}
+
+// streamToFile writes every document cur returns to path, or to numbered
+// files beside it when opts.Split is set. On failure every file written is
+// removed. progress is called as documents are written, and once more at the
+// end.
+func (s *Service) streamToFile(ctx context.Context, cur cursor, path string, opts Options, progress func(written int64, final bool)) (int64, error) {
+	w, err := newFileDocWriter(path, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer w.discard()
+
+	n, err := writeAll(ctx, cur, w, progress)
+	if err == nil {
+		err = w.Close()
+	}
+	progress(n, true)
+	return n, err
+}
+
+func writeAll(ctx context.Context, cur cursor, w docWriter, progress func(written int64, final bool)) (int64, error) {
+	var n int64
+	for cur.Next(ctx) {
+		var doc bson.Raw
+		if err := cur.Decode(&doc); err != nil {
+			return n, fmt.Errorf("failed to read document %d: %w", n+1, err)
+		}
+		if err := w.Write(doc); err != nil {
+			return n, fmt.Errorf("failed to write document %d: %w", n+1, err)
+		}
+		n++
+		progress(n, false)
+		// The cursor only sees ctx when it fetches the next batch.
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return n, fmt.Errorf("failed reading from the server: %w", err)
+	}
+	return n, nil
+}
+
+// discard drops whatever temporary files a writer kept.
+func discard(w docWriter) {
+	if d, ok := w.(interface{ discard() }); ok {
+		d.discard()
+	}
+}
+
+func (s *Service) register(exportID string, cancel context.CancelFunc) {
+	if exportID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[exportID] = cancel
+}
+
+func (s *Service) unregister(exportID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, exportID)
+}
+
+// progressThrottle drops progress reports that come sooner than
+// progressInterval after the last one sent, except the final one.
+type progressThrottle struct {
+	lastSent time.Time
+}
+
+func (p *progressThrottle) due(final bool) bool {
+	now := time.Now()
+	if !final && now.Sub(p.lastSent) < progressInterval {
+		return false
+	}
+	p.lastSent = now
+	return true
+}
+
+func (s *Service) sendProgress(exportID string, written, total int64) {
+	if exportID == "" {
+		return
+	}
+	s.emit(s.ctx, ExportProgressEvent, models.ExportProgress{
+		ExportID:  exportID,
+		Documents: written,
+		Total:     total,
+	})
+}
@@ -0,0 +1,166 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vervet/internal/api"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type stubSource struct {
+	docs     []any
+	count    int64
+	pc       *models.PageContext
+	pipeline bson.A
+}
+
+func (s *stubSource) OpenPageCursor(_ context.Context, _, _ string, pc models.PageContext) (*mongo.Cursor, error) {
+	s.pc = &pc
+	return mongo.NewCursorFromDocuments(s.docs, nil, nil)
+}
+
+func (s *stubSource) OpenAggregateCursor(_ context.Context, _, _, _ string, pipeline bson.A) (*mongo.Cursor, error) {
+	s.pipeline = pipeline
+	return mongo.NewCursorFromDocuments(s.docs, nil, nil)
+}
+
+func (s *stubSource) CountForPage(_, _ string, _ models.PageContext) (int64, bool, error) {
+	return s.count, false, nil
+}
+
+func newServerExportService(t *testing.T, source CursorSource, path string) (*Service, *[]models.ExportProgress) {
+	t.Helper()
+	svc := buildTestService(&mockSaveDialog{path: path}, newMockFileWriter())
+	svc.ctx = context.Background()
+	svc.source = source
+	svc.cancels = make(map[string]context.CancelFunc)
+	var events []models.ExportProgress
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events = append(events, data[0].(models.ExportProgress))
+	}
+	return svc, &events
+}
+
+func manyDocs(n int) []any {
+	docs := make([]any, n)
+	for i := range docs {
+		docs[i] = bson.D{{Key: "n", Value: int32(i)}}
+	}
+	return docs
+}
+
+func TestService_ServerExport_Query(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.ndjson")
+	source := &stubSource{docs: manyDocs(3), count: 3}
+	svc, events := newServerExportService(t, source, path)
+
+	got, err := svc.Export(api.ExportRequest{
+		Format:      "ndjson",
+		ServerID:    "srv",
+		Database:    "shop",
+		PageContext: &models.PageContext{Collection: "orders"},
+		ExportID:    "exp-1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, path, got)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":0}\n{\"n\":1}\n{\"n\":2}\n", string(data))
+	assert.Equal(t, "orders", source.pc.Collection)
+
+	require.NotEmpty(t, *events)
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, "exp-1", last.ExportID)
+	assert.Equal(t, int64(3), last.Documents)
+}
+
+func TestService_ServerExport_Pipeline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	source := &stubSource{docs: manyDocs(2)}
+	svc, _ := newServerExportService(t, source, path)
+
+	_, err := svc.Export(api.ExportRequest{
+		Format:         "csv",
+		CSV:            &api.ExportCSVOptions{IncludeHeader: true},
+		ServerID:       "srv",
+		Database:       "shop",
+		CollectionName: "orders",
+		Pipeline:       `[{"$match":{"status":"open"}}]`,
+	})
+
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "n\n0\n1\n", string(data))
+	assert.Len(t, source.pipeline, 1)
+}
+
+func TestService_ServerExport_SplitAndCompressed(t *testing.T) {
+	dir := t.TempDir()
+	svc, _ := newServerExportService(t, &stubSource{docs: manyDocs(3)}, filepath.Join(dir, "out.ndjson.gz"))
+
+	got, err := svc.Export(api.ExportRequest{
+		Format:      "ndjson",
+		ServerID:    "srv",
+		PageContext: &models.PageContext{Collection: "c"},
+		Compression: "gzip",
+		Split:       &api.ExportSplitOptions{Documents: 2},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "out-001.ndjson.gz"), got)
+	assert.FileExists(t, filepath.Join(dir, "out-002.ndjson.gz"))
+	assert.NoFileExists(t, filepath.Join(dir, "out-003.ndjson.gz"))
+}
+
+func TestService_ServerExport_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("nothing to export", func(t *testing.T) {
+		svc, _ := newServerExportService(t, &stubSource{}, filepath.Join(dir, "a.json"))
+		_, err := svc.Export(api.ExportRequest{Format: "json", ServerID: "srv"})
+		assert.ErrorContains(t, err, "needs a query or a pipeline")
+	})
+
+	t.Run("bad pipeline", func(t *testing.T) {
+		svc, _ := newServerExportService(t, &stubSource{}, filepath.Join(dir, "b.json"))
+		_, err := svc.Export(api.ExportRequest{Format: "json", ServerID: "srv", Pipeline: `{"$match":{}}`})
+		assert.ErrorContains(t, err, "array of stages")
+	})
+
+	t.Run("save dialog cancelled", func(t *testing.T) {
+		svc, _ := newServerExportService(t, &stubSource{docs: manyDocs(1)}, "")
+		got, err := svc.Export(api.ExportRequest{Format: "json", ServerID: "srv", PageContext: &models.PageContext{Collection: "c"}})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestService_ServerExport_Cancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	svc, _ := newServerExportService(t, &stubSource{docs: manyDocs(50)}, path)
+	svc.emit = func(_ context.Context, _ string, _ ...interface{}) {
+		svc.Cancel("exp-cancel")
+	}
+
+	got, err := svc.Export(api.ExportRequest{
+		Format:      "json",
+		ServerID:    "srv",
+		PageContext: &models.PageContext{Collection: "c"},
+		ExportID:    "exp-cancel",
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, got, "a cancelled export reports no file")
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "the partial file is removed")
+}
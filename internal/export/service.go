@@ -3,11 +3,17 @@ package export
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
 
 	"vervet/internal/api"
+	"vervet/internal/masking"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -35,48 +41,156 @@ func (w *osFileWriter) WriteFile(path string, data []byte) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
+// MaskerSource compiles saved masking profiles by name.
+// Implemented by masking.Service.
+type MaskerSource interface {
+	Masker(name string) (*masking.Masker, error)
+}
+
 // Service orchestrates export: serialize docs then save via dialog.
 type Service struct {
 	log        *slog.Logger
+	ctx        context.Context
 	dialog     SaveDialog
 	fileWriter fileWriter
+	source     CursorSource
+	masks      MaskerSource
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // exportID -> cancel for a running server export
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
 }
 
 // NewService constructs a production Service. The files.Service satisfies
-// SaveDialog via an adapter because its SaveFile uses api.FileFilter.
-func NewService(log *slog.Logger, dialog SaveDialog) *Service {
+// SaveDialog via an adapter because its SaveFile uses api.FileFilter. source
+// opens the cursors server exports read from.
+func NewService(log *slog.Logger, dialog SaveDialog, source CursorSource) *Service {
 	return &Service{
 		log:        log.With(slog.String("source", "ExportService")),
 		dialog:     dialog,
 		fileWriter: &osFileWriter{},
+		source:     source,
+		cancels:    make(map[string]context.CancelFunc),
+		emit:       runtime.EventsEmit,
 	}
 }
 
-// Init satisfies the service lifecycle interface. ExportService has no
-// context-dependent state of its own; the underlying dialog is initialised by
-// files.Service independently.
-func (s *Service) Init(_ context.Context) {
+// Init stores the Wails application context, used as the parent for server
+// exports. The underlying dialog is initialised by files.Service
+// independently.
+func (s *Service) Init(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// SetMasking lets requests name a saved masking profile to apply.
+func (s *Service) SetMasking(masks MaskerSource) {
+	s.masks = masks
 }
 
-// Export serializes docs from req.EJSON and writes them to a user-chosen path.
+// Export writes documents to a user-chosen path: those in req.EJSON, or, when
+// req.ServerID is set, everything the request's query matches on the server.
 // Returns the path written (empty string if user cancelled).
 func (s *Service) Export(req api.ExportRequest) (string, error) {
+	if req.ServerID != "" {
+		return s.exportFromServer(req)
+	}
+
+	opts, err := s.options(req)
+	if err != nil {
+		return "", err
+	}
+	if opts.Compression != CompressNone || opts.Split.enabled() {
+		return s.exportToFiles(req, opts)
+	}
+
 	docs, err := parseEJSON(req.EJSON)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse EJSON: %w", err)
 	}
 
-	opts, err := buildOptions(req)
+	data, err := Serialize(docs, opts)
 	if err != nil {
+		return "", fmt.Errorf("failed to serialize: %w", err)
+	}
+
+	path, err := s.choosePath(req, opts)
+	if err != nil || path == "" {
 		return "", err
 	}
 
+	if err := s.fileWriter.WriteFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return path, nil
+}
+
+// exportToFiles writes the documents in req.EJSON the way a server export
+// does, so they can be compressed and split.
+func (s *Service) exportToFiles(req api.ExportRequest, opts Options) (string, error) {
+	docs, err := parseEJSONRaw(req.EJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse EJSON: %w", err)
+	}
+
+	path, err := s.choosePath(req, opts)
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if _, err := s.streamToFile(context.Background(), &docsCursor{docs: docs}, path, opts, func(int64, bool) {}); err != nil {
+		return "", err
+	}
+	return outputPath(path, opts), nil
+}
+
+// Render serializes the documents in req.EJSON and returns them as a string,
+// for copying to the clipboard. Server exports and workbooks can't be
+// rendered, and compression and splitting are ignored.
+func (s *Service) Render(req api.ExportRequest) (string, error) {
+	if req.ServerID != "" {
+		return "", errors.New("only loaded results can be copied")
+	}
+	opts, err := s.options(req)
+	if err != nil {
+		return "", err
+	}
+	if opts.Format == FormatXLSX {
+		return "", errors.New("xlsx exports can't be copied as text")
+	}
+
+	docs, err := parseEJSON(req.EJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse EJSON: %w", err)
+	}
 	data, err := Serialize(docs, opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to serialize: %w", err)
 	}
+	return string(data), nil
+}
 
-	filters := filtersFor(opts.Format)
+// options builds the request's Options, with its masking profile.
+func (s *Service) options(req api.ExportRequest) (Options, error) {
+	opts, err := buildOptions(req)
+	if err != nil || req.MaskingProfile == "" {
+		return opts, err
+	}
+	if s.masks == nil {
+		return Options{}, errors.New("masking is not available")
+	}
+	if opts.Mask, err = s.masks.Masker(req.MaskingProfile); err != nil {
+		return Options{}, err
+	}
+	return opts, nil
+}
+
+// choosePath asks where to save the export. Empty means the user cancelled.
+func (s *Service) choosePath(req api.ExportRequest, opts Options) (string, error) {
+	filters := filtersFor(opts.Format, opts.Compression)
 	title := "Export results"
 	defaultFilename := req.DefaultFilename
 
@@ -84,15 +198,6 @@ func (s *Service) Export(req api.ExportRequest) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to open save dialog: %w", err)
 	}
-
-	if path == "" {
-		return "", nil
-	}
-
-	if err := s.fileWriter.WriteFile(path, data); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
-	}
-
 	return path, nil
 }
 
@@ -115,6 +220,55 @@ func parseEJSON(raw string) ([]bson.M, error) {
 	return docs, nil
 }
 
+// parseEJSONRaw decodes a JSON array of EJSON-encoded documents, keeping each
+// document's key order.
+func parseEJSONRaw(raw string) ([]bson.Raw, error) {
+	var rawDocs []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &rawDocs); err != nil {
+		return nil, err
+	}
+
+	docs := make([]bson.Raw, len(rawDocs))
+	for i, r := range rawDocs {
+		var d bson.D
+		if err := bson.UnmarshalExtJSON(r, false, &d); err != nil {
+			return nil, fmt.Errorf("doc[%d]: %w", i, err)
+		}
+		data, err := bson.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("doc[%d]: %w", i, err)
+		}
+		docs[i] = data
+	}
+
+	return docs, nil
+}
+
+// docsCursor reads documents already in memory, as a server export reads a
+// cursor.
+type docsCursor struct {
+	docs []bson.Raw
+	next int
+}
+
+func (c *docsCursor) Next(context.Context) bool {
+	c.next++
+	return c.next <= len(c.docs)
+}
+
+func (c *docsCursor) Decode(val any) error {
+	raw, ok := val.(*bson.Raw)
+	if !ok {
+		return fmt.Errorf("cannot decode into %T", val)
+	}
+	*raw = c.docs[c.next-1]
+	return nil
+}
+
+func (c *docsCursor) Err() error { return nil }
+
+func (c *docsCursor) Close(context.Context) error { return nil }
+
 // buildOptions maps the api.ExportRequest fields to export.Options.
 func buildOptions(req api.ExportRequest) (Options, error) {
 	var format Format
@@ -125,13 +279,53 @@ func buildOptions(req api.ExportRequest) (Options, error) {
 		format = FormatJSON
 	case "ndjson":
 		format = FormatNDJSON
+	case "xlsx":
+		format = FormatXLSX
+	case "markdown":
+		format = FormatMarkdown
+	case "html":
+		format = FormatHTML
+	case "sql":
+		format = FormatSQL
 	default:
 		return Options{}, fmt.Errorf("unknown format %q", req.Format)
 	}
 
+	compression := Compression(req.Compression)
+	switch compression {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return Options{}, fmt.Errorf("unknown compression %q", req.Compression)
+	}
+
 	opts := Options{
-		Format:  format,
-		Columns: req.Columns,
+		Format:      format,
+		Columns:     req.Columns,
+		Table:       req.CollectionName,
+		Compression: compression,
+	}
+
+	if req.Split != nil {
+		if req.Split.Documents < 0 || req.Split.Megabytes < 0 {
+			return Options{}, errors.New("a split needs a positive number of documents or megabytes")
+		}
+		opts.Split = SplitOptions{
+			Documents: req.Split.Documents,
+			Bytes:     req.Split.Megabytes * 1024 * 1024,
+		}
+	}
+
+	if req.SQL != nil {
+		dialect := SQLDialect(req.SQL.Dialect)
+		switch dialect {
+		case "", DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer:
+		default:
+			return Options{}, fmt.Errorf("unknown SQL dialect %q", req.SQL.Dialect)
+		}
+		opts.SQL = SQLOptions{Dialect: dialect}
+		if req.SQL.Table != "" {
+			opts.Table = req.SQL.Table
+		}
 	}
 
 	if req.CSV != nil {
@@ -140,26 +334,64 @@ func buildOptions(req api.ExportRequest) (Options, error) {
 			runes := []rune(req.CSV.Separator)
 			sep = runes[0]
 		}
+		arrays := ArrayMode(req.CSV.Arrays)
+		switch arrays {
+		case "", ArrayEJSON, ArrayIndexed, ArrayJoin:
+		case ArrayUnwind:
+			if strings.TrimSpace(req.CSV.UnwindPath) == "" {
+				return Options{}, errors.New("a row per array element needs the path of the array to unwind")
+			}
+		default:
+			return Options{}, fmt.Errorf("unknown array mode %q", req.CSV.Arrays)
+		}
+		values := ValueMode(req.CSV.Values)
+		switch values {
+		case "", ValueRelaxed, ValueCanonical, ValuePlain:
+		default:
+			return Options{}, fmt.Errorf("unknown value mode %q", req.CSV.Values)
+		}
 		opts.CSV = CSVOptions{
-			Separator:     sep,
-			IncludeHeader: req.CSV.IncludeHeader,
-			UTF8BOM:       req.CSV.UTF8BOM,
+			Separator:      sep,
+			IncludeHeader:  req.CSV.IncludeHeader,
+			UTF8BOM:        req.CSV.UTF8BOM,
+			Arrays:         arrays,
+			ArrayDelimiter: req.CSV.ArrayDelimiter,
+			UnwindPath:     strings.TrimSpace(req.CSV.UnwindPath),
+			Values:         values,
 		}
 	}
 
 	return opts, nil
 }
 
-// filtersFor returns FileFilter entries appropriate for the given format.
-func filtersFor(f Format) []FileFilter {
+// filtersFor returns FileFilter entries appropriate for the given format and
+// compression.
+func filtersFor(f Format, c Compression) []FileFilter {
+	var name, pattern string
 	switch f {
 	case FormatCSV:
-		return []FileFilter{{DisplayName: "CSV files (*.csv)", Pattern: "*.csv"}}
+		name, pattern = "CSV files", "*.csv"
 	case FormatJSON:
-		return []FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}}
+		name, pattern = "JSON files", "*.json"
 	case FormatNDJSON:
-		return []FileFilter{{DisplayName: "NDJSON files (*.ndjson)", Pattern: "*.ndjson"}}
+		name, pattern = "NDJSON files", "*.ndjson"
+	case FormatXLSX:
+		name, pattern = "Excel workbooks", "*.xlsx"
+	case FormatMarkdown:
+		name, pattern = "Markdown files", "*.md"
+	case FormatHTML:
+		name, pattern = "HTML files", "*.html"
+	case FormatSQL:
+		name, pattern = "SQL scripts", "*.sql"
 	default:
 		return nil
 	}
+	switch c {
+	case CompressGzip:
+		name = "Gzipped " + name
+	case CompressZstd:
+		name = "Zstandard-compressed " + name
+	}
+	pattern += c.extension()
+	return []FileFilter{{DisplayName: name + " (" + pattern + ")", Pattern: pattern}}
 }
@@ -1,14 +1,18 @@
 package export
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"vervet/internal/api"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,11 +20,13 @@ import (
 // --- mock SaveDialog ---
 
 type mockSaveDialog struct {
-	path string
-	err  error
+	path    string
+	err     error
+	filters []FileFilter
 }
 
-func (m *mockSaveDialog) SaveFile(_ *string, _ *string, _ []FileFilter) (string, error) {
+func (m *mockSaveDialog) SaveFile(_ *string, _ *string, filters []FileFilter) (string, error) {
+	m.filters = filters
 	return m.path, m.err
 }
 
@@ -152,3 +158,145 @@ func TestService_CSVTabSeparator(t *testing.T) {
 	content := string(data)
 	assert.True(t, strings.Contains(content, "a\tb"), "expected tab-separated content, got: %q", content)
 }
+
+func TestService_CSVArrayAndValueModes(t *testing.T) {
+	dialog := &mockSaveDialog{path: "/tmp/results.csv"}
+	writer := newMockFileWriter()
+	svc := buildTestService(dialog, writer)
+
+	req := api.ExportRequest{
+		Format: "csv",
+		EJSON:  `[{"_id":{"$oid":"65f0a1b2c3d4e5f601234567"},"tags":["a","b"]}]`,
+		CSV: &api.ExportCSVOptions{
+			Separator:     ",",
+			IncludeHeader: true,
+			Arrays:        "join",
+			Values:        "plain",
+		},
+	}
+
+	_, err := svc.Export(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "_id,tags\n65f0a1b2c3d4e5f601234567,a;b\n", string(writer.written["/tmp/results.csv"]))
+}
+
+func TestService_UnknownCSVModes(t *testing.T) {
+	svc := buildTestService(&mockSaveDialog{path: "/tmp/results.csv"}, newMockFileWriter())
+
+	_, err := svc.Export(api.ExportRequest{Format: "csv", EJSON: `[]`, CSV: &api.ExportCSVOptions{Arrays: "explode"}})
+	assert.ErrorContains(t, err, "unknown array mode")
+
+	_, err = svc.Export(api.ExportRequest{Format: "csv", EJSON: `[]`, CSV: &api.ExportCSVOptions{Values: "fancy"}})
+	assert.ErrorContains(t, err, "unknown value mode")
+
+	_, err = svc.Export(api.ExportRequest{Format: "csv", EJSON: `[]`, CSV: &api.ExportCSVOptions{Arrays: "unwind", UnwindPath: " "}})
+	assert.ErrorContains(t, err, "needs the path of the array to unwind")
+}
+
+func TestService_SQLOptions(t *testing.T) {
+	writer := newMockFileWriter()
+	dialog := &mockSaveDialog{path: "/tmp/people.sql"}
+	svc := buildTestService(dialog, writer)
+
+	_, err := svc.Export(api.ExportRequest{
+		Format:         "sql",
+		EJSON:          `[{"a":1}]`,
+		CollectionName: "people",
+		SQL:            &api.ExportSQLOptions{Dialect: "sqlite", Table: "staff"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []FileFilter{{DisplayName: "SQL scripts (*.sql)", Pattern: "*.sql"}}, dialog.filters)
+	assert.Equal(t, "CREATE TABLE \"staff\" (\n  \"a\" INTEGER\n);\nINSERT INTO \"staff\" (\"a\") VALUES (1);\n",
+		string(writer.written["/tmp/people.sql"]))
+
+	_, err = svc.Export(api.ExportRequest{Format: "sql", EJSON: `[]`, SQL: &api.ExportSQLOptions{Dialect: "oracle"}})
+	assert.ErrorContains(t, err, "unknown SQL dialect")
+}
+
+func TestService_Render(t *testing.T) {
+	svc := buildTestService(&mockSaveDialog{}, newMockFileWriter())
+
+	out, err := svc.Render(api.ExportRequest{Format: "markdown", EJSON: `[{"a":1}]`})
+	require.NoError(t, err)
+	assert.Equal(t, "| a |\n| ---: |\n| 1 |\n", out)
+
+	out, err = svc.Render(api.ExportRequest{Format: "html", EJSON: `[{"a":1}]`, CollectionName: "people"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "<title>people</title>")
+
+	_, err = svc.Render(api.ExportRequest{Format: "xlsx", EJSON: `[{"a":1}]`})
+	assert.ErrorContains(t, err, "can't be copied")
+
+	_, err = svc.Render(api.ExportRequest{Format: "json", ServerID: "srv"})
+	assert.ErrorContains(t, err, "only loaded results")
+}
+
+func TestService_CompressedLoadedExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.ndjson.zst")
+	dialog := &mockSaveDialog{path: path}
+	writer := newMockFileWriter()
+	svc := buildTestService(dialog, writer)
+
+	got, err := svc.Export(api.ExportRequest{
+		Format:      "ndjson",
+		EJSON:       `[{"z":1,"a":2}]`,
+		Compression: "zstd",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, path, got)
+	assert.Empty(t, writer.written, "compressed exports are streamed to disk")
+	assert.Equal(t, []FileFilter{{DisplayName: "Zstandard-compressed NDJSON files (*.ndjson.zst)", Pattern: "*.ndjson.zst"}}, dialog.filters)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	r, err := zstd.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer r.Close()
+	plain, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"z\":1,\"a\":2}\n", string(plain), "keys keep their order")
+}
+
+func TestService_SplitLoadedExport(t *testing.T) {
+	dir := t.TempDir()
+	svc := buildTestService(&mockSaveDialog{path: filepath.Join(dir, "results.csv")}, newMockFileWriter())
+
+	got, err := svc.Export(api.ExportRequest{
+		Format: "csv",
+		EJSON:  `[{"a":1},{"a":2},{"a":3}]`,
+		CSV:    &api.ExportCSVOptions{IncludeHeader: true},
+		Split:  &api.ExportSplitOptions{Documents: 2},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "results-001.csv"), got)
+	second, err := os.ReadFile(filepath.Join(dir, "results-002.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n3\n", string(second))
+}
+
+func TestBuildOptions_CompressionAndSplit(t *testing.T) {
+	opts, err := buildOptions(api.ExportRequest{
+		Format:      "json",
+		Compression: "gzip",
+		Split:       &api.ExportSplitOptions{Megabytes: 5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, CompressGzip, opts.Compression)
+	assert.Equal(t, SplitOptions{Bytes: 5 * 1024 * 1024}, opts.Split)
+
+	_, err = buildOptions(api.ExportRequest{Format: "json", Compression: "brotli"})
+	assert.ErrorContains(t, err, "unknown compression")
+
+	_, err = buildOptions(api.ExportRequest{Format: "json", Split: &api.ExportSplitOptions{Documents: -1}})
+	assert.ErrorContains(t, err, "positive number")
+}
+
+func TestFiltersFor_Compressed(t *testing.T) {
+	assert.Equal(t, []FileFilter{{DisplayName: "CSV files (*.csv)", Pattern: "*.csv"}}, filtersFor(FormatCSV, CompressNone))
+	assert.Equal(t, []FileFilter{{DisplayName: "Gzipped CSV files (*.csv.gz)", Pattern: "*.csv.gz"}}, filtersFor(FormatCSV, CompressGzip))
+	assert.Nil(t, filtersFor("xml", CompressGzip))
+}
@@ -0,0 +1,171 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// sqlDialect is how one database spells what a SQL export writes.
+type sqlDialect struct {
+	// types maps each column kind to a column type. kindNone columns, which
+	// held only nulls, get the text type.
+	types        map[columnKind]string
+	quoteIdent   func(name string) string
+	quoteString  func(s string) string
+	true, false_ string
+}
+
+func quoteWith(open, close string) func(string) string {
+	return func(name string) string {
+		return open + strings.ReplaceAll(name, close, close+close) + close
+	}
+}
+
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+var sqlDialects = map[SQLDialect]sqlDialect{
+	DialectPostgres: {
+		types: map[columnKind]string{
+			kindInt: "BIGINT", kindFloat: "DOUBLE PRECISION", kindDecimal: "NUMERIC",
+			kindBool: "BOOLEAN", kindDate: "TIMESTAMP(3)", kindText: "TEXT",
+		},
+		quoteIdent:  quoteWith(`"`, `"`),
+		quoteString: quoteSQLString,
+		true:        "TRUE", false_: "FALSE",
+	},
+	DialectMySQL: {
+		types: map[columnKind]string{
+			kindInt: "BIGINT", kindFloat: "DOUBLE", kindDecimal: "DECIMAL(65,30)",
+			kindBool: "BOOLEAN", kindDate: "DATETIME(3)", kindText: "LONGTEXT",
+		},
+		quoteIdent: quoteWith("`", "`"),
+		// MySQL reads backslashes in strings as escapes.
+		quoteString: func(s string) string {
+			return quoteSQLString(strings.ReplaceAll(s, `\`, `\\`))
+		},
+		true: "TRUE", false_: "FALSE",
+	},
+	DialectSQLite: {
+		types: map[columnKind]string{
+			kindInt: "INTEGER", kindFloat: "REAL", kindDecimal: "NUMERIC",
+			kindBool: "INTEGER", kindDate: "TEXT", kindText: "TEXT",
+		},
+		quoteIdent:  quoteWith(`"`, `"`),
+		quoteString: quoteSQLString,
+		true:        "1", false_: "0",
+	},
+	DialectSQLServer: {
+		types: map[columnKind]string{
+			kindInt: "BIGINT", kindFloat: "FLOAT", kindDecimal: "DECIMAL(38,10)",
+			kindBool: "BIT", kindDate: "DATETIME2(3)", kindText: "NVARCHAR(MAX)",
+		},
+		quoteIdent: quoteWith("[", "]"),
+		quoteString: func(s string) string {
+			return "N" + quoteSQLString(s)
+		},
+		true: "1", false_: "0",
+	},
+}
+
+// sqlTable writes a CREATE TABLE statement with a column per field path,
+// typed from the values seen, then an INSERT statement per document.
+type sqlTable struct {
+	w       io.Writer
+	table   string
+	dialect sqlDialect
+	columns []tableColumn
+	insert  string // the statement up to VALUES
+}
+
+func newSQLTable(w io.Writer, table string, dialect SQLDialect) (*sqlTable, error) {
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+	d, ok := sqlDialects[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unknown SQL dialect %q", dialect)
+	}
+	return &sqlTable{w: w, table: table, dialect: d}, nil
+}
+
+func (s *sqlTable) begin(columns []tableColumn) error {
+	s.columns = columns
+	if len(columns) == 0 {
+		return nil
+	}
+	table := s.dialect.quoteIdent(s.table)
+	var b strings.Builder
+	b.WriteString("CREATE TABLE " + table + " (\n")
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = s.dialect.quoteIdent(c.path)
+		kind := c.kind
+		if kind == kindNone {
+			kind = kindText
+		}
+		b.WriteString("  " + names[i] + " " + s.dialect.types[kind])
+		if i < len(columns)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(");\n")
+	s.insert = "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES ("
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+func (s *sqlTable) row(cells []*flatPair) error {
+	if len(s.columns) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(s.insert)
+	for i, c := range cells {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(s.literal(c, s.columns[i].kind))
+	}
+	b.WriteString(");\n")
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+// literal writes a cell as a value for a column of kind.
+func (s *sqlTable) literal(c *flatPair, kind columnKind) string {
+	if c == nil || valueKind(c.raw) == kindNone {
+		return "NULL"
+	}
+	switch kind {
+	case kindInt:
+		return c.value
+	case kindFloat, kindDecimal:
+		// SQL has no NaN or infinity.
+		f, err := strconv.ParseFloat(c.value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "NULL"
+		}
+		return c.value
+	case kindBool:
+		if c.raw.(bool) {
+			return s.dialect.true
+		}
+		return s.dialect.false_
+	case kindDate:
+		return s.dialect.quoteString(c.raw.(bson.DateTime).Time().UTC().Format("2006-01-02 15:04:05.000"))
+	default:
+		return s.dialect.quoteString(c.value)
+	}
+}
+
+func (s *sqlTable) end() error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func sqlDocs() []bson.M {
+	when := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	return []bson.M{
+		{"n": int32(1), "name": "O'Brien", "active": true, "joined": bson.NewDateTimeFromTime(when)},
+		{"n": 2.5, "name": `back\slash`, "active": false, "extra": nil},
+	}
+}
+
+func TestSerializeSQL_Postgres(t *testing.T) {
+	out, err := Serialize(sqlDocs(), Options{Format: FormatSQL, Table: "people"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `CREATE TABLE "people" (
+  "active" BOOLEAN,
+  "extra" TEXT,
+  "joined" TIMESTAMP(3),
+  "n" DOUBLE PRECISION,
+  "name" TEXT
+);
+INSERT INTO "people" ("active", "extra", "joined", "n", "name") VALUES (TRUE, NULL, '2026-03-01 09:30:00.000', 1, 'O''Brien');
+INSERT INTO "people" ("active", "extra", "joined", "n", "name") VALUES (FALSE, NULL, NULL, 2.5, 'back\slash');
+`, string(out))
+}
+
+func TestSerializeSQL_Dialects(t *testing.T) {
+	tests := []struct {
+		dialect SQLDialect
+		create  string
+		insert  string
+	}{
+		{
+			DialectMySQL,
+			"CREATE TABLE `my``table` (\n  `active` BOOLEAN,\n  `extra` LONGTEXT,\n  `joined` DATETIME(3),\n  `n` DOUBLE,\n  `name` LONGTEXT\n);\n",
+			"VALUES (FALSE, NULL, NULL, 2.5, 'back\\\\slash');\n",
+		},
+		{
+			DialectSQLite,
+			"CREATE TABLE \"my`table\" (\n  \"active\" INTEGER,\n  \"extra\" TEXT,\n  \"joined\" TEXT,\n  \"n\" REAL,\n  \"name\" TEXT\n);\n",
+			"VALUES (0, NULL, NULL, 2.5, 'back\\slash');\n",
+		},
+		{
+			DialectSQLServer,
+			"CREATE TABLE [my`table] (\n  [active] BIT,\n  [extra] NVARCHAR(MAX),\n  [joined] DATETIME2(3),\n  [n] FLOAT,\n  [name] NVARCHAR(MAX)\n);\n",
+			"VALUES (0, NULL, NULL, 2.5, N'back\\slash');\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			out, err := Serialize(sqlDocs(), Options{Format: FormatSQL, Table: "my`table", SQL: SQLOptions{Dialect: tt.dialect}})
+			require.NoError(t, err)
+
+			assert.Contains(t, string(out), tt.create)
+			assert.Contains(t, string(out), tt.insert)
+		})
+	}
+}
+
+func TestSerializeSQL_ValuesInTextColumns(t *testing.T) {
+	// A column holding more than one kind is text, and every value in it
+	// is quoted.
+	docs := []bson.M{{"v": int32(1)}, {"v": "one"}, {"v": bson.A{1, 2}}}
+
+	out, err := Serialize(docs, Options{Format: FormatSQL})
+	require.NoError(t, err)
+
+	assert.Equal(t, `CREATE TABLE "export" (
+  "v" TEXT
+);
+INSERT INTO "export" ("v") VALUES ('1');
+INSERT INTO "export" ("v") VALUES ('one');
+INSERT INTO "export" ("v") VALUES ('[1,2]');
+`, string(out))
+}
+
+func TestSerializeSQL_NonFiniteNumbersAreNull(t *testing.T) {
+	docs := []bson.M{{"v": math.NaN()}, {"v": math.Inf(1)}, {"v": 1.5}}
+
+	out, err := Serialize(docs, Options{Format: FormatSQL})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), `VALUES (NULL);
+INSERT INTO "export" ("v") VALUES (NULL);
+INSERT INTO "export" ("v") VALUES (1.5);`)
+}
+
+func TestSerializeSQL_UnknownDialect(t *testing.T) {
+	_, err := Serialize(sqlDocs(), Options{Format: FormatSQL, SQL: SQLOptions{Dialect: "oracle"}})
+	assert.ErrorContains(t, err, `unknown SQL dialect "oracle"`)
+}
@@ -0,0 +1,334 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"vervet/internal/masking"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// docWriter writes documents to a file one at a time, so an export holds one
+// document in memory however many it writes.
+type docWriter interface {
+	Write(doc bson.Raw) error
+	// Close finishes the file: the closing bracket of a JSON array, the rows
+	// of a CSV file whose header had to wait for the last document.
+	Close() error
+}
+
+func newDocWriter(w io.Writer, opts Options) (docWriter, error) {
+	dw, err := newFormatWriter(w, opts)
+	if err != nil || opts.Mask == nil {
+		return dw, err
+	}
+	return &maskingWriter{next: dw, mask: opts.Mask}, nil
+}
+
+func newFormatWriter(w io.Writer, opts Options) (docWriter, error) {
+	switch opts.Format {
+	case FormatJSON:
+		return &jsonDocWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonDocWriter{w: w}, nil
+	case FormatCSV:
+		if len(opts.Columns) > 0 {
+			return newCSVDocWriter(w, opts.Columns, opts.CSV)
+		}
+		return newSpoolingCSVWriter(w, opts.CSV)
+	case FormatXLSX:
+		return newXLSXDocWriter(w, opts.Columns)
+	case FormatMarkdown, FormatHTML, FormatSQL:
+		return newTableDocWriter(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown format %q", opts.Format)
+	}
+}
+
+// maskBatchSize is how many documents a masked export masks together. Shuffle
+// rules only exchange values within a batch.
+const maskBatchSize = 1000
+
+// maskingWriter masks documents a batch at a time on their way to next.
+type maskingWriter struct {
+	next  docWriter
+	mask  *masking.Masker
+	batch []bson.Raw
+}
+
+func (m *maskingWriter) Write(doc bson.Raw) error {
+	// The cursor may reuse doc's bytes for the next document.
+	m.batch = append(m.batch, slices.Clone(doc))
+	if len(m.batch) < maskBatchSize {
+		return nil
+	}
+	return m.flush()
+}
+
+func (m *maskingWriter) flush() error {
+	masked, err := m.mask.Mask(m.batch)
+	if err != nil {
+		return err
+	}
+	m.batch = m.batch[:0]
+	for _, doc := range masked {
+		if err := m.next.Write(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *maskingWriter) Close() error {
+	if err := m.flush(); err != nil {
+		return err
+	}
+	return m.next.Close()
+}
+
+func (m *maskingWriter) discard() {
+	discard(m.next)
+}
+
+type ndjsonDocWriter struct {
+	w io.Writer
+}
+
+func (n *ndjsonDocWriter) Write(doc bson.Raw) error {
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	if _, err := n.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (n *ndjsonDocWriter) Close() error {
+	return nil
+}
+
+// jsonDocWriter writes the same pretty-printed array serializeJSONImpl does,
+// indenting each document as it comes. Keys keep their order in the document.
+type jsonDocWriter struct {
+	w     io.Writer
+	count int
+	buf   bytes.Buffer
+}
+
+func (j *jsonDocWriter) Write(doc bson.Raw) error {
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	j.buf.Reset()
+	if j.count == 0 {
+		j.buf.WriteString("[\n  ")
+	} else {
+		j.buf.WriteString(",\n  ")
+	}
+	if err := json.Indent(&j.buf, data, "  ", "  "); err != nil {
+		return err
+	}
+	j.count++
+	_, err = j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonDocWriter) Close() error {
+	closing := "\n]"
+	if j.count == 0 {
+		closing = "[]"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// csvDocWriter writes rows under a header known before the first document.
+type csvDocWriter struct {
+	w       *csv.Writer
+	columns []string
+	flat    flattener
+}
+
+func newCSVDocWriter(w io.Writer, columns []string, opts CSVOptions) (*csvDocWriter, error) {
+	cw, err := startCSV(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.IncludeHeader {
+		if err := cw.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	return &csvDocWriter{w: cw, columns: columns, flat: newFlattener(opts)}, nil
+}
+
+// startCSV writes the BOM, if wanted, and returns a writer using the chosen
+// separator.
+func startCSV(w io.Writer, opts CSVOptions) (*csv.Writer, error) {
+	if opts.UTF8BOM {
+		if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+			return nil, err
+		}
+	}
+	cw := csv.NewWriter(w)
+	if opts.Separator != 0 {
+		cw.Comma = opts.Separator
+	}
+	return cw, nil
+}
+
+func (c *csvDocWriter) Write(doc bson.Raw) error {
+	rows, err := flattenRaw(doc, c.flat)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := c.w.Write(csvRecord(row, c.columns)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvDocWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func csvRecord(row map[string]string, columns []string) []string {
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = row[col]
+	}
+	return record
+}
+
+// flattenRaw flattens doc into the CSV rows f makes of it.
+func flattenRaw(doc bson.Raw, f flattener) ([]map[string]string, error) {
+	var m bson.M
+	if err := bson.Unmarshal(doc, &m); err != nil {
+		return nil, err
+	}
+	docRows, err := f.rows(m)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]string, len(docRows))
+	for i, pairs := range docRows {
+		rows[i] = pairsToRow(pairs)
+	}
+	return rows, nil
+}
+
+func flattenRawPairs(doc bson.Raw) ([]flatPair, error) {
+	var m bson.M
+	if err := bson.Unmarshal(doc, &m); err != nil {
+		return nil, err
+	}
+	return flattenDoc(m)
+}
+
+// spoolingCSVWriter handles a CSV export without chosen columns. Its header
+// is every field path in every document, which is only known at the end, so
+// flattened rows go to a temporary file first and are written out on Close.
+// Memory holds the set of field paths, not the rows.
+type spoolingCSVWriter struct {
+	out   io.Writer
+	opts  CSVOptions
+	spool *os.File
+	enc   *json.Encoder
+	buf   *bufio.Writer
+	paths map[string]struct{}
+	flat  flattener
+}
+
+func newSpoolingCSVWriter(w io.Writer, opts CSVOptions) (*spoolingCSVWriter, error) {
+	spool, err := os.CreateTemp("", "vervet-export-*.ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	buf := bufio.NewWriter(spool)
+	return &spoolingCSVWriter{
+		out:   w,
+		opts:  opts,
+		spool: spool,
+		buf:   buf,
+		enc:   json.NewEncoder(buf),
+		paths: make(map[string]struct{}),
+		flat:  newFlattener(opts),
+	}, nil
+}
+
+func (s *spoolingCSVWriter) Write(doc bson.Raw) error {
+	rows, err := flattenRaw(doc, s.flat)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for k := range row {
+			s.paths[k] = struct{}{}
+		}
+		if err := s.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *spoolingCSVWriter) Close() error {
+	defer s.discard()
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(s.paths))
+	for k := range s.paths {
+		columns = append(columns, k)
+	}
+	sortStrings(columns)
+
+	w, err := newCSVDocWriter(s.out, columns, s.opts)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bufio.NewReader(s.spool))
+	for {
+		var row map[string]string
+		err := dec.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %w", err)
+		}
+		if err := w.w.Write(csvRecord(row, columns)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// discard removes the temporary file. Safe to call more than once, and
+// after Close.
+func (s *spoolingCSVWriter) discard() {
+	if s.spool == nil {
+		return
+	}
+	_ = s.spool.Close()
+	_ = os.Remove(s.spool.Name())
+	s.spool = nil
+}
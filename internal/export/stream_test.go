@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"vervet/internal/masking"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func rawDocs(t *testing.T, docs ...bson.D) []bson.Raw {
+	t.Helper()
+	out := make([]bson.Raw, len(docs))
+	for i, d := range docs {
+		data, err := bson.Marshal(d)
+		require.NoError(t, err)
+		out[i] = data
+	}
+	return out
+}
+
+func writeDocs(t *testing.T, opts Options, docs []bson.Raw) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newDocWriter(&buf, opts)
+	require.NoError(t, err)
+	defer discard(w)
+	for _, d := range docs {
+		require.NoError(t, w.Write(d))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+// sampleDocs keeps keys in alphabetical order so the streamed output, which
+// preserves document order, lines up with Serialize, which sorts map keys.
+func sampleDocs() []bson.D {
+	return []bson.D{
+		{{Key: "address", Value: bson.D{{Key: "city", Value: "London"}}}, {Key: "name", Value: "Ada"}},
+		{{Key: "age", Value: int32(85)}, {Key: "name", Value: "Grace"}, {Key: "tags", Value: bson.A{"navy"}}},
+	}
+}
+
+// decoded returns the documents as the UI export path sees them.
+func decoded(t *testing.T, raws []bson.Raw) []bson.M {
+	t.Helper()
+	out := make([]bson.M, len(raws))
+	for i, r := range raws {
+		require.NoError(t, bson.Unmarshal(r, &out[i]))
+	}
+	return out
+}
+
+func TestDocWriter_MatchesSerialize(t *testing.T) {
+	raws := rawDocs(t, sampleDocs()...)
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"csv with derived columns", Options{Format: FormatCSV, CSV: CSVOptions{IncludeHeader: true, UTF8BOM: true}}},
+		{"csv with chosen columns", Options{Format: FormatCSV, Columns: []string{"name", "age"}, CSV: CSVOptions{Separator: ';', IncludeHeader: true}}},
+		{"markdown", Options{Format: FormatMarkdown}},
+		{"html", Options{Format: FormatHTML, Table: "people"}},
+		{"sql", Options{Format: FormatSQL, Columns: []string{"name", "age"}, SQL: SQLOptions{Dialect: DialectMySQL}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Serialize(decoded(t, raws), tt.opts)
+			require.NoError(t, err)
+
+			got := writeDocs(t, tt.opts, raws)
+
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestDocWriter_NDJSON(t *testing.T) {
+	raws := rawDocs(t, sampleDocs()...)
+
+	got := writeDocs(t, Options{Format: FormatNDJSON}, raws)
+
+	assert.Equal(t, "{\"address\":{\"city\":\"London\"},\"name\":\"Ada\"}\n"+
+		"{\"age\":85,\"name\":\"Grace\",\"tags\":[\"navy\"]}\n", got)
+}
+
+func TestDocWriter_JSON(t *testing.T) {
+	t.Run("pretty-printed array", func(t *testing.T) {
+		raws := rawDocs(t, bson.D{{Key: "a", Value: int32(1)}}, bson.D{{Key: "b", Value: "x"}})
+		want, err := Serialize(decoded(t, raws), Options{Format: FormatJSON})
+		require.NoError(t, err)
+
+		got := writeDocs(t, Options{Format: FormatJSON}, raws)
+
+		assert.Equal(t, string(want), got)
+	})
+
+	t.Run("keeps key order", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{{Key: "z", Value: 1}, {Key: "a", Value: 2}})
+		require.NoError(t, err)
+
+		got := writeDocs(t, Options{Format: FormatJSON}, []bson.Raw{doc})
+
+		assert.Equal(t, "[\n  {\n    \"z\": 1,\n    \"a\": 2\n  }\n]", got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "[]", writeDocs(t, Options{Format: FormatJSON}, nil))
+	})
+}
+
+func TestDocWriter_UnknownFormat(t *testing.T) {
+	_, err := newDocWriter(&bytes.Buffer{}, Options{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestDocWriter_CSVArrayModes(t *testing.T) {
+	raws := rawDocs(t,
+		bson.D{{Key: "n", Value: int32(1)}, {Key: "tags", Value: bson.A{"a", "b"}}},
+		bson.D{{Key: "n", Value: int32(2)}, {Key: "tags", Value: bson.A{"c"}}},
+	)
+	for _, csvOpts := range []CSVOptions{
+		{IncludeHeader: true, Arrays: ArrayIndexed},
+		{IncludeHeader: true, Arrays: ArrayJoin, ArrayDelimiter: "/"},
+		{IncludeHeader: true, Arrays: ArrayUnwind, UnwindPath: "tags"},
+	} {
+		t.Run(string(csvOpts.Arrays), func(t *testing.T) {
+			opts := Options{Format: FormatCSV, CSV: csvOpts}
+			want, err := Serialize(decoded(t, raws), opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(want), writeDocs(t, opts, raws))
+		})
+	}
+}
+
+func TestDocWriter_Masked(t *testing.T) {
+	mask, err := masking.New(models.MaskingProfile{
+		Name:  "p",
+		Rules: []models.MaskingRule{{Path: "name", Strategy: models.MaskRedact}},
+	})
+	require.NoError(t, err)
+	docs := make([]bson.D, maskBatchSize+1)
+	for i := range docs {
+		docs[i] = bson.D{{Key: "name", Value: "Ada"}}
+	}
+
+	out := writeDocs(t, Options{Format: FormatNDJSON, Mask: mask}, rawDocs(t, docs...))
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, len(docs), "the last, partial batch is written on close")
+	for _, line := range lines {
+		assert.Equal(t, `{"name":"<redacted>"}`, line)
+	}
+
+	serialized, err := Serialize([]bson.M{{"name": "Ada"}}, Options{Format: FormatNDJSON, Mask: mask})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"<redacted>"}`+"\n", string(serialized))
+}
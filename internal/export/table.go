@@ -0,0 +1,283 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// columnKind is the type a table column holds, inferred from every value
+// written under it.
+type columnKind int
+
+const (
+	kindNone columnKind = iota // only nulls, or nothing, seen
+	kindInt
+	kindFloat
+	kindDecimal
+	kindBool
+	kindDate
+	kindText
+)
+
+// valueKind is the kind of one flattened value.
+func valueKind(v any) columnKind {
+	switch v.(type) {
+	case nil, bson.Null, bson.Undefined:
+		return kindNone
+	case int, int32, int64:
+		return kindInt
+	case float32, float64:
+		return kindFloat
+	case bson.Decimal128:
+		return kindDecimal
+	case bool:
+		return kindBool
+	case bson.DateTime:
+		return kindDate
+	default:
+		return kindText
+	}
+}
+
+// mergeKinds is the kind of a column holding values of both kinds: the wider
+// number for mixed numbers, otherwise text.
+func mergeKinds(a, b columnKind) columnKind {
+	switch {
+	case a == b || b == kindNone:
+		return a
+	case a == kindNone:
+		return b
+	case isNumber(a) && isNumber(b):
+		return max(a, b)
+	default:
+		return kindText
+	}
+}
+
+func isNumber(k columnKind) bool {
+	return k == kindInt || k == kindFloat || k == kindDecimal
+}
+
+type tableColumn struct {
+	path string
+	kind columnKind
+}
+
+// tableShape tracks every field path seen and the kind of its values.
+type tableShape map[string]columnKind
+
+func (s tableShape) add(pairs []flatPair) {
+	for _, p := range pairs {
+		s[p.path] = mergeKinds(s[p.path], valueKind(p.raw))
+	}
+}
+
+// columns is the table's header: the chosen columns, or the sorted union of
+// field paths.
+func (s tableShape) columns(chosen []string) []tableColumn {
+	paths := chosen
+	if len(paths) == 0 {
+		paths = make([]string, 0, len(s))
+		for p := range s {
+			paths = append(paths, p)
+		}
+		sortStrings(paths)
+	}
+	columns := make([]tableColumn, len(paths))
+	for i, p := range paths {
+		columns[i] = tableColumn{path: p, kind: s[p]}
+	}
+	return columns
+}
+
+// tableFormat writes a table: the header, a row per document, and whatever
+// closes it. A row's cells line up with the columns; nil is a field the
+// document doesn't have.
+type tableFormat interface {
+	begin(columns []tableColumn) error
+	row(cells []*flatPair) error
+	end() error
+}
+
+func newTableFormat(w io.Writer, opts Options) (tableFormat, error) {
+	switch opts.Format {
+	case FormatMarkdown:
+		return &markdownTable{w: w}, nil
+	case FormatHTML:
+		return &htmlTable{w: w, title: tableName(opts)}, nil
+	case FormatSQL:
+		return newSQLTable(w, tableName(opts), opts.SQL.Dialect)
+	default:
+		return nil, fmt.Errorf("unknown format %q", opts.Format)
+	}
+}
+
+// tableName is what the export calls its table, "export" when unnamed.
+func tableName(opts Options) string {
+	if opts.Table == "" {
+		return "export"
+	}
+	return opts.Table
+}
+
+// tablePairs flattens a document for a table: arrays as Extended JSON and
+// other BSON types as plain text, each keeping its raw value for the
+// formats that use its type.
+func tablePairs(doc bson.M) ([]flatPair, error) {
+	rows, err := flattener{values: ValuePlain}.rows(doc)
+	if err != nil {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+func tablePairsRaw(doc bson.Raw) ([]flatPair, error) {
+	var m bson.M
+	if err := bson.Unmarshal(doc, &m); err != nil {
+		return nil, err
+	}
+	return tablePairs(m)
+}
+
+func tableCells(pairs []flatPair, columns []tableColumn) []*flatPair {
+	index := make(map[string]int, len(pairs))
+	for i, p := range pairs {
+		index[p.path] = i
+	}
+	cells := make([]*flatPair, len(columns))
+	for i, c := range columns {
+		if j, ok := index[c.path]; ok {
+			cells[i] = &pairs[j]
+		}
+	}
+	return cells
+}
+
+func serializeTable(docs []bson.M, opts Options) ([]byte, error) {
+	rows := make([][]flatPair, len(docs))
+	shape := tableShape{}
+	for i, d := range docs {
+		pairs, err := tablePairs(d)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = pairs
+		shape.add(pairs)
+	}
+
+	var buf bytes.Buffer
+	table, err := newTableFormat(&buf, opts)
+	if err != nil {
+		return nil, err
+	}
+	columns := shape.columns(opts.Columns)
+	if err := table.begin(columns); err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		if err := table.row(tableCells(r, columns)); err != nil {
+			return nil, err
+		}
+	}
+	if err := table.end(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tableDocWriter streams a Markdown, HTML or SQL export. The header and the
+// column types depend on every document, so documents are spooled to a
+// temporary file as BSON and written out on Close.
+type tableDocWriter struct {
+	out   io.Writer
+	opts  Options
+	spool *os.File
+	buf   *bufio.Writer
+	shape tableShape
+}
+
+func newTableDocWriter(w io.Writer, opts Options) (*tableDocWriter, error) {
+	// Fail on an unknown dialect before reading anything.
+	if _, err := newTableFormat(io.Discard, opts); err != nil {
+		return nil, err
+	}
+	spool, err := os.CreateTemp("", "vervet-export-*.bson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return &tableDocWriter{
+		out:   w,
+		opts:  opts,
+		spool: spool,
+		buf:   bufio.NewWriter(spool),
+		shape: tableShape{},
+	}, nil
+}
+
+func (t *tableDocWriter) Write(doc bson.Raw) error {
+	pairs, err := tablePairsRaw(doc)
+	if err != nil {
+		return err
+	}
+	t.shape.add(pairs)
+	_, err = t.buf.Write(doc)
+	return err
+}
+
+func (t *tableDocWriter) Close() error {
+	defer t.discard()
+	if err := t.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := t.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	table, err := newTableFormat(t.out, t.opts)
+	if err != nil {
+		return err
+	}
+	shape := t.shape
+	if t.opts.shape != nil {
+		shape = t.opts.shape
+	}
+	columns := shape.columns(t.opts.Columns)
+	if err := table.begin(columns); err != nil {
+		return err
+	}
+	r := bufio.NewReader(t.spool)
+	for {
+		doc, err := bson.ReadDocument(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %w", err)
+		}
+		pairs, err := tablePairsRaw(doc)
+		if err != nil {
+			return err
+		}
+		if err := table.row(tableCells(pairs, columns)); err != nil {
+			return err
+		}
+	}
+	return table.end()
+}
+
+// discard removes the temporary file. Safe to call more than once, and
+// after Close.
+func (t *tableDocWriter) discard() {
+	if t.spool == nil {
+		return
+	}
+	_ = t.spool.Close()
+	_ = os.Remove(t.spool.Name())
+	t.spool = nil
+}
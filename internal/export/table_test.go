@@ -0,0 +1,44 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMergeKinds(t *testing.T) {
+	tests := []struct {
+		a, b, want columnKind
+	}{
+		{kindNone, kindInt, kindInt},
+		{kindDate, kindNone, kindDate},
+		{kindInt, kindFloat, kindFloat},
+		{kindDecimal, kindInt, kindDecimal},
+		{kindBool, kindBool, kindBool},
+		{kindInt, kindBool, kindText},
+		{kindDate, kindText, kindText},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, mergeKinds(tt.a, tt.b), "%v + %v", tt.a, tt.b)
+	}
+}
+
+func TestTableShape_Columns(t *testing.T) {
+	shape := tableShape{}
+	for _, d := range []bson.M{
+		{"b": int32(1), "a": bson.M{"c": "x"}},
+		{"b": 2.5, "d": nil},
+	} {
+		pairs, err := tablePairs(d)
+		assert.NoError(t, err)
+		shape.add(pairs)
+	}
+
+	assert.Equal(t, []tableColumn{
+		{path: "a.c", kind: kindText},
+		{path: "b", kind: kindFloat},
+		{path: "d", kind: kindNone},
+	}, shape.columns(nil))
+	assert.Equal(t, []tableColumn{{path: "b", kind: kindFloat}, {path: "z"}}, shape.columns([]string{"b", "z"}))
+}
@@ -0,0 +1,313 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// xlsxMaxRows is how many rows fit on one worksheet, the header included.
+// Rows past it go to another sheet. A variable so tests can lower it.
+var xlsxMaxRows = excelize.TotalRows
+
+const (
+	// Column widths are in characters, clamped so one long value doesn't
+	// make a column wider than the screen.
+	xlsxMinWidth = 8
+	xlsxMaxWidth = 60
+)
+
+func serializeXLSX(docs []bson.M, columns []string) ([]byte, error) {
+	rows := make([][]flatPair, len(docs))
+	for i, d := range docs {
+		pairs, err := flattenDoc(d)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = pairs
+	}
+
+	widths := newColumnWidths()
+	for _, r := range rows {
+		widths.add(r)
+	}
+	header := columns
+	if len(header) == 0 {
+		header = widths.paths()
+	}
+
+	book, err := newXLSXBook(header, widths.forHeader(header))
+	if err != nil {
+		return nil, err
+	}
+	defer book.close()
+	for _, r := range rows {
+		if err := book.writeRow(r); err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	if err := book.writeTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsxBook writes rows to a workbook through excelize's stream writer, which
+// keeps memory bounded by spilling large sheets to temporary files. Every
+// sheet starts with the header row, frozen so it stays in view.
+type xlsxBook struct {
+	file        *excelize.File
+	header      []string
+	index       map[string]int
+	widths      []float64
+	headerStyle int
+
+	sheet  *excelize.StreamWriter
+	sheets int
+	row    int // last row written on the current sheet
+}
+
+func newXLSXBook(header []string, widths []float64) (*xlsxBook, error) {
+	f := excelize.NewFile()
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[h] = i
+	}
+	return &xlsxBook{file: f, header: header, index: index, widths: widths, headerStyle: style}, nil
+}
+
+func (b *xlsxBook) writeRow(pairs []flatPair) error {
+	if b.sheet == nil || b.row >= xlsxMaxRows {
+		if err := b.nextSheet(); err != nil {
+			return err
+		}
+	}
+	values := make([]any, len(b.header))
+	for _, p := range pairs {
+		if i, ok := b.index[p.path]; ok {
+			values[i] = xlsxValue(p)
+		}
+	}
+	b.row++
+	cell, err := excelize.CoordinatesToCellName(1, b.row)
+	if err != nil {
+		return err
+	}
+	return b.sheet.SetRow(cell, values)
+}
+
+// nextSheet finishes the current sheet and starts the next one: Sheet1,
+// Sheet2 and so on.
+func (b *xlsxBook) nextSheet() error {
+	if b.sheet != nil {
+		if err := b.sheet.Flush(); err != nil {
+			return err
+		}
+	}
+	b.sheets++
+	name := fmt.Sprintf("Sheet%d", b.sheets)
+	if b.sheets > 1 {
+		if _, err := b.file.NewSheet(name); err != nil {
+			return err
+		}
+	}
+	sw, err := b.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	for i, w := range b.widths {
+		if err := sw.SetColWidth(i+1, i+1, w); err != nil {
+			return err
+		}
+	}
+	if len(b.header) > 0 {
+		if err := sw.SetPanes(&excelize.Panes{
+			Freeze:      true,
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
+		}); err != nil {
+			return err
+		}
+	}
+	header := make([]any, len(b.header))
+	for i, h := range b.header {
+		header[i] = excelize.Cell{StyleID: b.headerStyle, Value: h}
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+	b.sheet, b.row = sw, 1
+	return nil
+}
+
+// writeTo finishes the workbook and writes it to w. A book with no rows
+// still gets a sheet with the header.
+func (b *xlsxBook) writeTo(w io.Writer) error {
+	if b.sheet == nil {
+		if err := b.nextSheet(); err != nil {
+			return err
+		}
+	}
+	if err := b.sheet.Flush(); err != nil {
+		return err
+	}
+	_, err := b.file.WriteTo(w)
+	return err
+}
+
+// close removes the stream writers' temporary files.
+func (b *xlsxBook) close() {
+	_ = b.file.Close()
+}
+
+// xlsxValue is the cell value for a flattened field: numbers, booleans and
+// dates keep their type so spreadsheets can sort and sum them; everything
+// else is the text the CSV export would write.
+func xlsxValue(p flatPair) any {
+	switch v := p.raw.(type) {
+	case nil:
+		return nil
+	case int, int32, int64, float32, float64, bool:
+		return v
+	case bson.DateTime:
+		return v.Time().UTC()
+	default:
+		return p.value
+	}
+}
+
+// columnWidths tracks every field path seen and the widest value under it.
+type columnWidths map[string]int
+
+func newColumnWidths() columnWidths {
+	return columnWidths{}
+}
+
+func (c columnWidths) add(pairs []flatPair) {
+	for _, p := range pairs {
+		n := utf8.RuneCountInString(p.value)
+		if _, ok := p.raw.(bson.DateTime); ok {
+			n = len("2006-01-02 15:04")
+		}
+		c[p.path] = max(c[p.path], n)
+	}
+}
+
+// paths returns the sorted union of field paths, the header when no columns
+// were chosen.
+func (c columnWidths) paths() []string {
+	paths := make([]string, 0, len(c))
+	for p := range c {
+		paths = append(paths, p)
+	}
+	sortStrings(paths)
+	return paths
+}
+
+func (c columnWidths) forHeader(header []string) []float64 {
+	widths := make([]float64, len(header))
+	for i, h := range header {
+		n := max(c[h], utf8.RuneCountInString(h)) + 2
+		widths[i] = float64(min(max(n, xlsxMinWidth), xlsxMaxWidth))
+	}
+	return widths
+}
+
+// xlsxDocWriter streams an XLSX export. Column widths, and the header when
+// no columns were chosen, depend on every document, so documents are spooled
+// to a temporary file as BSON and written to the workbook on Close.
+type xlsxDocWriter struct {
+	out     io.Writer
+	columns []string
+	spool   *os.File
+	buf     *bufio.Writer
+	widths  columnWidths
+}
+
+func newXLSXDocWriter(w io.Writer, columns []string) (*xlsxDocWriter, error) {
+	spool, err := os.CreateTemp("", "vervet-export-*.bson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return &xlsxDocWriter{
+		out:     w,
+		columns: columns,
+		spool:   spool,
+		buf:     bufio.NewWriter(spool),
+		widths:  newColumnWidths(),
+	}, nil
+}
+
+func (x *xlsxDocWriter) Write(doc bson.Raw) error {
+	pairs, err := flattenRawPairs(doc)
+	if err != nil {
+		return err
+	}
+	x.widths.add(pairs)
+	_, err = x.buf.Write(doc)
+	return err
+}
+
+func (x *xlsxDocWriter) Close() error {
+	defer x.discard()
+	if err := x.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := x.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := x.columns
+	if len(header) == 0 {
+		header = x.widths.paths()
+	}
+	book, err := newXLSXBook(header, x.widths.forHeader(header))
+	if err != nil {
+		return err
+	}
+	defer book.close()
+
+	r := bufio.NewReader(x.spool)
+	for {
+		doc, err := bson.ReadDocument(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read temporary file: %w", err)
+		}
+		pairs, err := flattenRawPairs(doc)
+		if err != nil {
+			return err
+		}
+		if err := book.writeRow(pairs); err != nil {
+			return err
+		}
+	}
+	return book.writeTo(x.out)
+}
+
+// discard removes the temporary file. Safe to call more than once, and
+// after Close.
+func (x *xlsxDocWriter) discard() {
+	if x.spool == nil {
+		return
+	}
+	_ = x.spool.Close()
+	_ = os.Remove(x.spool.Name())
+	x.spool = nil
+}
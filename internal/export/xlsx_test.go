@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"vervet/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func openXLSX(t *testing.T, data []byte) *excelize.File {
+	t.Helper()
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestSerializeXLSX_NativeCellTypes(t *testing.T) {
+	when := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	docs := []bson.M{{
+		"name":   "Ada",
+		"age":    int32(36),
+		"score":  9.5,
+		"active": true,
+		"joined": bson.NewDateTimeFromTime(when),
+		"id":     bson.ObjectID{0x65},
+	}}
+
+	out, err := Serialize(docs, Options{Format: FormatXLSX})
+	require.NoError(t, err)
+	f := openXLSX(t, out)
+
+	rows, err := f.GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active", "age", "id", "joined", "name", "score"}, rows[0])
+
+	for cell, want := range map[string]excelize.CellType{
+		"A2": excelize.CellTypeBool,
+		"B2": excelize.CellTypeUnset, // numbers carry no type attribute
+		"C2": excelize.CellTypeInlineString,
+		"E2": excelize.CellTypeInlineString,
+		"F2": excelize.CellTypeUnset,
+	} {
+		got, err := f.GetCellType("Sheet1", cell)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, cell)
+	}
+
+	age, err := f.GetCellValue("Sheet1", "B2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "36", age)
+
+	joined, err := f.GetCellValue("Sheet1", "D2")
+	require.NoError(t, err)
+	assert.Equal(t, "3/1/26 09:30", joined, "dates get a date format")
+
+	id, err := f.GetCellValue("Sheet1", "C2")
+	require.NoError(t, err)
+	assert.Equal(t, `{"$oid":"650000000000000000000000"}`, id)
+}
+
+func TestSerializeXLSX_ChosenColumnsAndMissingFields(t *testing.T) {
+	docs := []bson.M{{"a": int32(1), "b": "x"}, {"a": int32(2)}}
+
+	out, err := Serialize(docs, Options{Format: FormatXLSX, Columns: []string{"b", "a"}})
+	require.NoError(t, err)
+
+	rows, err := openXLSX(t, out).GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"b", "a"}, {"x", "1"}, {"", "2"}}, rows)
+}
+
+func TestSerializeXLSX_FreezesHeaderAndSizesColumns(t *testing.T) {
+	docs := []bson.M{{"id": int32(1), "description": "a fairly long piece of text for one cell"}}
+
+	out, err := Serialize(docs, Options{Format: FormatXLSX})
+	require.NoError(t, err)
+	f := openXLSX(t, out)
+
+	panes, err := f.GetPanes("Sheet1")
+	require.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 1, panes.YSplit)
+	assert.Equal(t, "A2", panes.TopLeftCell)
+
+	wide, err := f.GetColWidth("Sheet1", "A")
+	require.NoError(t, err)
+	narrow, err := f.GetColWidth("Sheet1", "B")
+	require.NoError(t, err)
+	assert.Equal(t, float64(42), wide)
+	assert.Equal(t, float64(xlsxMinWidth), narrow)
+}
+
+func TestSerializeXLSX_SplitsSheetsPastTheRowLimit(t *testing.T) {
+	defer func(n int) { xlsxMaxRows = n }(xlsxMaxRows)
+	xlsxMaxRows = 3 // a header and two documents per sheet
+
+	docs := make([]bson.M, 5)
+	for i := range docs {
+		docs[i] = bson.M{"n": int32(i)}
+	}
+
+	out, err := Serialize(docs, Options{Format: FormatXLSX})
+	require.NoError(t, err)
+	f := openXLSX(t, out)
+
+	assert.Equal(t, []string{"Sheet1", "Sheet2", "Sheet3"}, f.GetSheetList())
+	for sheet, want := range map[string][][]string{
+		"Sheet1": {{"n"}, {"0"}, {"1"}},
+		"Sheet2": {{"n"}, {"2"}, {"3"}},
+		"Sheet3": {{"n"}, {"4"}},
+	} {
+		rows, err := f.GetRows(sheet)
+		require.NoError(t, err)
+		assert.Equal(t, want, rows, sheet)
+	}
+}
+
+func TestSerializeXLSX_Empty(t *testing.T) {
+	out, err := Serialize(nil, Options{Format: FormatXLSX, Columns: []string{"a"}})
+	require.NoError(t, err)
+
+	rows, err := openXLSX(t, out).GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a"}}, rows)
+}
+
+func TestXLSXDocWriter_MatchesSerialize(t *testing.T) {
+	raws := rawDocs(t, sampleDocs()...)
+	want, err := Serialize(decoded(t, raws), Options{Format: FormatXLSX})
+	require.NoError(t, err)
+
+	got := writeDocs(t, Options{Format: FormatXLSX}, raws)
+
+	wantRows, err := openXLSX(t, want).GetRows("Sheet1")
+	require.NoError(t, err)
+	gotRows, err := openXLSX(t, []byte(got)).GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, wantRows, gotRows)
+}
+
+func TestXLSXDocWriter_RemovesSpool(t *testing.T) {
+	w, err := newXLSXDocWriter(&bytes.Buffer{}, nil)
+	require.NoError(t, err)
+	spool := w.spool.Name()
+	require.NoError(t, w.Write(rawDocs(t, bson.D{{Key: "a", Value: 1}})[0]))
+
+	require.NoError(t, w.Close())
+
+	_, statErr := os.Stat(spool)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestService_XLSXExport(t *testing.T) {
+	dialog := &mockSaveDialog{path: filepath.Join(t.TempDir(), "out.xlsx")}
+	writer := newMockFileWriter()
+	svc := buildTestService(dialog, writer)
+
+	_, err := svc.Export(api.ExportRequest{Format: "xlsx", EJSON: `[{"a":1}]`})
+
+	require.NoError(t, err)
+	assert.Equal(t, []FileFilter{{DisplayName: "Excel workbooks (*.xlsx)", Pattern: "*.xlsx"}}, dialog.filters)
+	rows, err := openXLSX(t, writer.written[dialog.path]).GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a"}, {"1"}}, rows)
+}
@@ -42,6 +42,19 @@ func (s *Service) SelectFile(title string, filters []api.FileFilter) (string, er
 	return filepath, nil
 }
 
+func (s *Service) SelectDirectory(title string) (string, error) {
+	path, err := runtime.OpenDirectoryDialog(s.ctx, runtime.OpenDialogOptions{
+		Title:                title,
+		ShowHiddenFiles:      true,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to select directory: %w", err)
+	}
+
+	return path, nil
+}
+
 func (s *Service) SaveFile(title *string, name *string, filters []api.FileFilter) (string, error) {
 	runtimeFilters := toRuntimeFilters(filters)
 
@@ -0,0 +1,237 @@
+// Package generator makes up documents like those of a sampled collection:
+// with the same fields, present as often, holding the same types in the
+// same ranges and lengths.
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Generator makes documents from one sampled schema.
+type Generator struct {
+	fields    []field
+	overrides map[string]*override
+	// added are overrides for paths the sample doesn't have, as keys.
+	added [][]string
+	rng   *mathrand.Rand
+}
+
+// field is a sampled field, ready to generate.
+type field struct {
+	name string
+	// path is the field's dot path with arrays looked through, as overrides
+	// name it.
+	path string
+	// presence is the share of the documents holding the field that have it.
+	presence float64
+	types    []fieldType
+	total    int
+}
+
+// fieldType is one type a field was seen holding, and how often.
+type fieldType struct {
+	name  string
+	count int
+	// lo and hi are the range of numbers, or of dates in milliseconds.
+	lo, hi   float64
+	hasRange bool
+	places   int
+	// minLen and maxLen are the length of strings, or of arrays.
+	minLen, maxLen int
+	hasLen         bool
+	children       []field
+	elem           *field
+}
+
+// New prepares to generate documents like those schema sampled. A seed
+// other than zero makes the same documents each time, but for ObjectIds,
+// which are always new so inserting them twice doesn't collide.
+func New(schema models.CollectionSchema, overrides []models.GenerateOverride, seed uint64) (*Generator, error) {
+	compiled, err := compileOverrides(overrides)
+	if err != nil {
+		return nil, err
+	}
+	if schema.SampledCount == 0 && len(overrides) == 0 {
+		return nil, errors.New("the collection has no documents to generate more like")
+	}
+	if seed == 0 {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, fmt.Errorf("error seeding the generator: %w", err)
+		}
+		seed = binary.LittleEndian.Uint64(b[:])
+	}
+	g := &Generator{
+		fields:    compileFields(schema.Fields, schema.SampledCount, ""),
+		overrides: compiled,
+		rng:       mathrand.New(mathrand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+	}
+	// _id leads, as the server would put it.
+	slices.SortStableFunc(g.fields, func(a, b field) int {
+		return boolRank(b.name == "_id") - boolRank(a.name == "_id")
+	})
+
+	sampled := map[string]bool{}
+	collectPaths(g.fields, sampled)
+	for _, o := range overrides {
+		if !sampled[o.Path] && o.Kind != models.OverrideOmit {
+			g.added = append(g.added, strings.Split(o.Path, "."))
+		}
+	}
+	return g, nil
+}
+
+// Next makes a document.
+func (g *Generator) Next() bson.D {
+	doc := g.document(g.fields)
+	for _, keys := range g.added {
+		doc = g.add(doc, keys, g.overrides[strings.Join(keys, ".")])
+	}
+	return doc
+}
+
+func (g *Generator) document(fields []field) bson.D {
+	doc := make(bson.D, 0, len(fields))
+	for _, f := range fields {
+		if o, ok := g.overrides[f.path]; ok {
+			if !o.omit {
+				doc = append(doc, bson.E{Key: f.name, Value: o.value(g)})
+			}
+			continue
+		}
+		if g.rng.Float64() >= f.presence {
+			continue
+		}
+		doc = append(doc, bson.E{Key: f.name, Value: g.value(f)})
+	}
+	return doc
+}
+
+// add sets the field at keys, which the sample doesn't have, making the
+// documents on the way. Arrays on the way have it set in each of their
+// documents.
+func (g *Generator) add(doc bson.D, keys []string, o *override) bson.D {
+	for i := range doc {
+		if doc[i].Key != keys[0] {
+			continue
+		}
+		if len(keys) == 1 {
+			doc[i].Value = o.value(g)
+			return doc
+		}
+		switch v := doc[i].Value.(type) {
+		case bson.D:
+			doc[i].Value = g.add(v, keys[1:], o)
+		case bson.A:
+			for j, elem := range v {
+				if d, ok := elem.(bson.D); ok {
+					v[j] = g.add(d, keys[1:], o)
+				}
+			}
+		}
+		return doc
+	}
+	if len(keys) == 1 {
+		return append(doc, bson.E{Key: keys[0], Value: o.value(g)})
+	}
+	return append(doc, bson.E{Key: keys[0], Value: g.add(bson.D{}, keys[1:], o)})
+}
+
+// compileFields prepares the fields of documents that were seen parentCount
+// times.
+func compileFields(infos []models.FieldInfo, parentCount int, parentPath string) []field {
+	fields := make([]field, 0, len(infos))
+	for _, info := range infos {
+		if info.Name == "[]" {
+			continue
+		}
+		path := info.Name
+		if parentPath != "" {
+			path = parentPath + "." + info.Name
+		}
+		f := compileField(info, path)
+		f.presence = 1
+		if parentCount > 0 {
+			f.presence = min(float64(info.Count)/float64(parentCount), 1)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func compileField(info models.FieldInfo, path string) field {
+	f := field{name: info.Name, path: path}
+	var elem *models.FieldInfo
+	for i := range info.Children {
+		if info.Children[i].Name == "[]" {
+			elem = &info.Children[i]
+		}
+	}
+	for _, ts := range info.Types {
+		t := fieldType{name: ts.Type, count: ts.Count}
+		if ts.Min != nil && ts.Max != nil {
+			lo, errLo := strconv.ParseFloat(*ts.Min, 64)
+			hi, errHi := strconv.ParseFloat(*ts.Max, 64)
+			if errLo == nil && errHi == nil {
+				t.lo, t.hi, t.hasRange = lo, hi, true
+				t.places = max(decimalPlaces(*ts.Min), decimalPlaces(*ts.Max))
+			}
+		}
+		if ts.MinLen != nil && ts.MaxLen != nil {
+			t.minLen, t.maxLen, t.hasLen = *ts.MinLen, *ts.MaxLen, true
+		}
+		switch ts.Type {
+		case "object":
+			t.children = compileFields(info.Children, ts.Count, path)
+		case "array":
+			if elem != nil {
+				e := compileField(*elem, path)
+				e.presence = 1
+				t.elem = &e
+			}
+		}
+		f.types = append(f.types, t)
+		f.total += ts.Count
+	}
+	return f
+}
+
+func collectPaths(fields []field, into map[string]bool) {
+	for _, f := range fields {
+		into[f.path] = true
+		for _, t := range f.types {
+			collectPaths(t.children, into)
+			if t.elem != nil {
+				collectPaths([]field{*t.elem}, into)
+			}
+		}
+	}
+}
+
+// decimalPlaces counts the digits after the point in a number as the
+// schema writes it, up to 6.
+func decimalPlaces(s string) int {
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return 0
+	}
+	return min(len(s)-i-1, 6)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// people is a schema like one sampled from 100 documents of people.
+func people() models.CollectionSchema {
+	return models.CollectionSchema{
+		SampledCount: 100,
+		Fields: []models.FieldInfo{
+			{Path: "age", Name: "age", Count: 100, Types: []models.TypeStat{
+				{Type: "int", Count: 100, Min: ptr("18"), Max: ptr("65")},
+			}},
+			{Path: "nickname", Name: "nickname", Count: 25, Types: []models.TypeStat{
+				{Type: "string", Count: 25, MinLen: ptr(3), MaxLen: ptr(8)},
+			}},
+			{Path: "score", Name: "score", Count: 100, Types: []models.TypeStat{
+				{Type: "double", Count: 75, Min: ptr("0.5"), Max: ptr("9.75")},
+				{Type: "null", Count: 25},
+			}},
+			{Path: "joined", Name: "joined", Count: 100, Types: []models.TypeStat{
+				{Type: "date", Count: 100, Min: ptr("1577836800000"), Max: ptr("1609459200000")},
+			}},
+			{Path: "_id", Name: "_id", Count: 100, Types: []models.TypeStat{{Type: "objectId", Count: 100}}},
+			{Path: "address", Name: "address", Count: 100, Types: []models.TypeStat{{Type: "object", Count: 100}},
+				Children: []models.FieldInfo{
+					{Path: "address.city", Name: "city", Count: 100, Types: []models.TypeStat{
+						{Type: "string", Count: 100, MinLen: ptr(4), MaxLen: ptr(12)},
+					}},
+				}},
+			{Path: "tags", Name: "tags", Count: 100, Types: []models.TypeStat{
+				{Type: "array", Count: 100, MinLen: ptr(1), MaxLen: ptr(3)},
+			}, Children: []models.FieldInfo{
+				{Path: "tags[]", Name: "[]", Count: 200, Types: []models.TypeStat{
+					{Type: "object", Count: 200},
+				}, Children: []models.FieldInfo{
+					{Path: "tags[].label", Name: "label", Count: 200, Types: []models.TypeStat{
+						{Type: "string", Count: 200, MinLen: ptr(2), MaxLen: ptr(5)},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+func generate(t *testing.T, schema models.CollectionSchema, overrides []models.GenerateOverride, n int) []bson.D {
+	t.Helper()
+	g, err := New(schema, overrides, 42)
+	require.NoError(t, err)
+	docs := make([]bson.D, n)
+	for i := range docs {
+		docs[i] = g.Next()
+	}
+	return docs
+}
+
+func get(doc bson.D, key string) (any, bool) {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+func TestGenerator_FollowsSchema(t *testing.T) {
+	docs := generate(t, people(), nil, 2000)
+	joinedLo := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	joinedHi := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var nicknames, nullScores int
+	for _, doc := range docs {
+		require.Equal(t, "_id", doc[0].Key)
+		assert.IsType(t, bson.ObjectID{}, doc[0].Value)
+
+		age, ok := get(doc, "age")
+		require.True(t, ok, "age is in every sampled document")
+		require.IsType(t, int32(0), age)
+		assert.GreaterOrEqual(t, age.(int32), int32(18))
+		assert.LessOrEqual(t, age.(int32), int32(65))
+
+		if nickname, ok := get(doc, "nickname"); ok {
+			nicknames++
+			n := len(nickname.(string))
+			assert.True(t, n >= 3 && n <= 8, "nickname %q", nickname)
+			assert.False(t, strings.HasSuffix(nickname.(string), " "))
+		}
+
+		switch score, _ := get(doc, "score"); v := score.(type) {
+		case nil:
+			nullScores++
+		case float64:
+			assert.True(t, v >= 0.5 && v <= 9.75, "score %v", v)
+			assert.Equal(t, v, float64(int(v*100+0.5))/100, "score keeps two places")
+		default:
+			t.Fatalf("score is a %T", score)
+		}
+
+		joined, _ := get(doc, "joined")
+		when := joined.(bson.DateTime).Time()
+		assert.False(t, when.Before(joinedLo) || when.After(joinedHi), "joined %v", when)
+
+		address, _ := get(doc, "address")
+		city, ok := get(address.(bson.D), "city")
+		require.True(t, ok)
+		assert.True(t, len(city.(string)) >= 4 && len(city.(string)) <= 12)
+
+		tags, _ := get(doc, "tags")
+		require.IsType(t, bson.A{}, tags)
+		assert.True(t, len(tags.(bson.A)) >= 1 && len(tags.(bson.A)) <= 3)
+		for _, tag := range tags.(bson.A) {
+			_, ok := get(tag.(bson.D), "label")
+			assert.True(t, ok)
+		}
+	}
+	assert.InDelta(t, 500, nicknames, 100, "nickname is in a quarter of the documents")
+	assert.InDelta(t, 500, nullScores, 100, "a quarter of the scores are null")
+}
+
+func TestGenerator_SeedRepeats(t *testing.T) {
+	strip := func(docs []bson.D) []bson.D {
+		for _, doc := range docs {
+			doc[0].Value = nil
+		}
+		return docs
+	}
+	first := strip(generate(t, people(), nil, 20))
+	second := strip(generate(t, people(), nil, 20))
+	assert.Equal(t, first, second)
+}
+
+func TestGenerator_NameHints(t *testing.T) {
+	schema := models.CollectionSchema{SampledCount: 1, Fields: []models.FieldInfo{
+		{Path: "email", Name: "email", Count: 1, Types: []models.TypeStat{
+			{Type: "string", Count: 1, MinLen: ptr(3), MaxLen: ptr(3)},
+		}},
+	}}
+	doc := generate(t, schema, nil, 1)[0]
+	email, _ := get(doc, "email")
+	assert.Contains(t, email, "@")
+}
+
+func TestGenerator_Overrides(t *testing.T) {
+	overrides := []models.GenerateOverride{
+		{Path: "age", Kind: models.OverrideConstant, Values: []string{`{"$numberLong":"7"}`}},
+		{Path: "nickname", Kind: models.OverrideOmit},
+		{Path: "score", Kind: models.OverrideChoice, Values: []string{`1`, `"none"`}},
+		{Path: "joined", Kind: models.OverrideRange, Min: "2024-01-01T00:00:00Z", Max: "2024-01-02T00:00:00Z"},
+		{Path: "address.city", Kind: models.OverrideFake, Fake: models.FakeAddress},
+		{Path: "tags.label", Kind: models.OverrideSequence, Min: "10"},
+		{Path: "status", Kind: models.OverrideConstant, Values: []string{`"active"`}},
+		{Path: "meta.rank", Kind: models.OverrideRange, Min: "1.5", Max: "2.5"},
+	}
+	docs := generate(t, people(), overrides, 50)
+
+	label := int32(10)
+	for _, doc := range docs {
+		age, _ := get(doc, "age")
+		assert.Equal(t, int64(7), age)
+
+		_, ok := get(doc, "nickname")
+		assert.False(t, ok)
+
+		score, _ := get(doc, "score")
+		assert.Contains(t, []any{int32(1), "none"}, score)
+
+		joined, _ := get(doc, "joined")
+		assert.Equal(t, 2024, joined.(bson.DateTime).Time().UTC().Year())
+
+		address, _ := get(doc, "address")
+		city, _ := get(address.(bson.D), "city")
+		assert.Regexp(t, `^\d+ `, city)
+
+		tags, _ := get(doc, "tags")
+		for _, tag := range tags.(bson.A) {
+			v, _ := get(tag.(bson.D), "label")
+			assert.Equal(t, label, v)
+			label++
+		}
+
+		status, _ := get(doc, "status")
+		assert.Equal(t, "active", status)
+
+		meta, ok := get(doc, "meta")
+		require.True(t, ok, "paths the sample lacks are added")
+		rank, _ := get(meta.(bson.D), "rank")
+		assert.True(t, rank.(float64) >= 1.5 && rank.(float64) <= 2.5)
+	}
+}
+
+func TestGenerator_NoSample(t *testing.T) {
+	_, err := New(models.CollectionSchema{}, nil, 1)
+	assert.ErrorContains(t, err, "no documents")
+
+	docs := generate(t, models.CollectionSchema{}, []models.GenerateOverride{
+		{Path: "n", Kind: models.OverrideSequence},
+	}, 3)
+	assert.Equal(t, []bson.D{{{Key: "n", Value: int32(1)}}, {{Key: "n", Value: int32(2)}}, {{Key: "n", Value: int32(3)}}}, docs)
+}
+
+func TestCompileOverrides_Errors(t *testing.T) {
+	cases := map[string]struct {
+		override models.GenerateOverride
+		want     string
+	}{
+		"no path":         {models.GenerateOverride{Kind: models.OverrideOmit}, "needs a path"},
+		"empty key":       {models.GenerateOverride{Path: "a..b", Kind: models.OverrideOmit}, "empty field name"},
+		"operator":        {models.GenerateOverride{Path: "$set", Kind: models.OverrideOmit}, "operator"},
+		"unknown kind":    {models.GenerateOverride{Path: "a", Kind: "shuffle"}, "unknown kind of override"},
+		"constant values": {models.GenerateOverride{Path: "a", Kind: models.OverrideConstant}, "one value"},
+		"bad json":        {models.GenerateOverride{Path: "a", Kind: models.OverrideConstant, Values: []string{"{"}}, "not an Extended JSON value"},
+		"empty choice":    {models.GenerateOverride{Path: "a", Kind: models.OverrideChoice}, "at least one value"},
+		"open range":      {models.GenerateOverride{Path: "a", Kind: models.OverrideRange, Min: "1"}, "a minimum and a maximum"},
+		"mixed range":     {models.GenerateOverride{Path: "a", Kind: models.OverrideRange, Min: "1", Max: "2024-01-01T00:00:00Z"}, "two numbers or two dates"},
+		"backwards range": {models.GenerateOverride{Path: "a", Kind: models.OverrideRange, Min: "5", Max: "1"}, "more than its maximum"},
+		"backwards dates": {models.GenerateOverride{Path: "a", Kind: models.OverrideRange, Min: "2024-02-01T00:00:00Z", Max: "2024-01-01T00:00:00Z"}, "after its maximum"},
+		"fraction start":  {models.GenerateOverride{Path: "a", Kind: models.OverrideSequence, Min: "1.5"}, "whole number"},
+		"unknown fake":    {models.GenerateOverride{Path: "a", Kind: models.OverrideFake, Fake: "iban"}, "unknown kind of fake value"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := compileOverrides([]models.GenerateOverride{tc.override})
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := compileOverrides([]models.GenerateOverride{
+		{Path: "a", Kind: models.OverrideOmit},
+		{Path: "a", Kind: models.OverrideOmit},
+	})
+	assert.ErrorContains(t, err, "override 2 (a): another override")
+}
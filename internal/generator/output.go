@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"vervet/internal/models"
+	"vervet/internal/mongowrite"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// writeNDJSON writes count documents to the file at path, one relaxed
+// Extended JSON document per line. The file is removed if writing stops
+// short.
+func writeNDJSON(ctx context.Context, g *Generator, count int, path string, report func(int64)) (result models.GenerateResult, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return result, fmt.Errorf("error creating %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("error writing %s: %w", path, cerr)
+		}
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		line, err := bson.MarshalExtJSON(g.Next(), false, false)
+		if err != nil {
+			return result, fmt.Errorf("error encoding document %d: %w", i+1, err)
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return result, fmt.Errorf("error writing %s: %w", path, err)
+		}
+		result.Generated++
+		report(result.Generated)
+	}
+	if err := w.Flush(); err != nil {
+		return result, fmt.Errorf("error writing %s: %w", path, err)
+	}
+	result.Path = path
+	return result, nil
+}
+
+// insert inserts count documents into coll in unordered batches, counting
+// those the collection refuses rather than stopping at them.
+func insert(ctx context.Context, coll *mongo.Collection, g *Generator, count int, report func(int64)) (models.GenerateResult, error) {
+	var result models.GenerateResult
+	batch := make([]any, 0, min(count, insertBatchSize))
+	for result.Generated < int64(count) {
+		batch = batch[:0]
+		for len(batch) < insertBatchSize && result.Generated+int64(len(batch)) < int64(count) {
+			batch = append(batch, g.Next())
+		}
+		_, err := coll.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+		failed, err := mongowrite.WriteErrors(err)
+		if err != nil {
+			return result, fmt.Errorf("error inserting into %s.%s: %w", coll.Database().Name(), coll.Name(), err)
+		}
+		result.Generated += int64(len(batch))
+		result.Failed += int64(len(failed))
+		if len(failed) > 0 && result.FirstError == "" {
+			result.FirstError = failed[0].Message
+		}
+		report(result.Generated)
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// override makes the values of one field in place of the sample's.
+type override struct {
+	omit  bool
+	value func(g *Generator) any
+}
+
+func compileOverrides(overrides []models.GenerateOverride) (map[string]*override, error) {
+	compiled := make(map[string]*override, len(overrides))
+	for i, o := range overrides {
+		c, err := compileOverride(o)
+		if err != nil {
+			return nil, fmt.Errorf("override %d (%s): %w", i+1, o.Path, err)
+		}
+		if _, dup := compiled[o.Path]; dup {
+			return nil, fmt.Errorf("override %d (%s): another override already sets this path", i+1, o.Path)
+		}
+		compiled[o.Path] = c
+	}
+	return compiled, nil
+}
+
+func compileOverride(o models.GenerateOverride) (*override, error) {
+	if err := checkPath(o.Path); err != nil {
+		return nil, err
+	}
+	c := &override{}
+	switch o.Kind {
+	case models.OverrideOmit:
+		c.omit = true
+	case models.OverrideConstant:
+		if len(o.Values) != 1 {
+			return nil, errors.New("a constant needs one value")
+		}
+		v, err := parseValue(o.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		c.value = func(*Generator) any { return v }
+	case models.OverrideChoice:
+		if len(o.Values) == 0 {
+			return nil, errors.New("a choice needs at least one value")
+		}
+		values := make([]any, len(o.Values))
+		for i, s := range o.Values {
+			v, err := parseValue(s)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		c.value = func(g *Generator) any { return values[g.rng.IntN(len(values))] }
+	case models.OverrideRange:
+		fn, err := compileRange(o.Min, o.Max)
+		if err != nil {
+			return nil, err
+		}
+		c.value = fn
+	case models.OverrideSequence:
+		next := int64(1)
+		if o.Min != "" {
+			start, err := strconv.ParseInt(strings.TrimSpace(o.Min), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("a sequence starts at a whole number, not %q", o.Min)
+			}
+			next = start
+		}
+		c.value = func(*Generator) any {
+			v := next
+			next++
+			return intValue(v)
+		}
+	case models.OverrideFake:
+		switch o.Fake {
+		case models.FakeName, models.FakeEmail, models.FakePhone, models.FakeAddress:
+		default:
+			return nil, fmt.Errorf("unknown kind of fake value %q", o.Fake)
+		}
+		c.value = func(g *Generator) any { return g.fake(o.Fake) }
+	default:
+		return nil, fmt.Errorf("unknown kind of override %q", o.Kind)
+	}
+	return c, nil
+}
+
+// compileRange picks numbers or dates between from and to: whole numbers
+// when both are, otherwise doubles.
+func compileRange(from, to string) (func(g *Generator) any, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return nil, errors.New("a range needs a minimum and a maximum")
+	}
+	if lo, hi, err := parseDates(from, to); err == nil {
+		if hi.Before(lo) {
+			return nil, errors.New("the range's minimum is after its maximum")
+		}
+		return func(g *Generator) any { return g.dateBetween(lo.UnixMilli(), hi.UnixMilli()) }, nil
+	}
+	lo, errLo := strconv.ParseFloat(from, 64)
+	hi, errHi := strconv.ParseFloat(to, 64)
+	if errLo != nil || errHi != nil {
+		return nil, errors.New("a range is between two numbers or two dates")
+	}
+	if hi < lo {
+		return nil, errors.New("the range's minimum is more than its maximum")
+	}
+	if !strings.ContainsAny(from+to, ".eE") {
+		return func(g *Generator) any { return intValue(g.intBetween(int64(lo), int64(hi))) }, nil
+	}
+	places := max(decimalPlaces(from), decimalPlaces(to))
+	return func(g *Generator) any { return g.floatBetween(lo, hi, places) }, nil
+}
+
+func parseDates(from, to string) (time.Time, time.Time, error) {
+	lo, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	hi, err := time.Parse(time.RFC3339, to)
+	return lo, hi, err
+}
+
+// parseValue reads one Extended JSON value.
+func parseValue(s string) (any, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"v":`+s+`}`), false, &doc); err != nil {
+		return nil, fmt.Errorf("%s is not an Extended JSON value", s)
+	}
+	return doc[0].Value, nil
+}
+
+func checkPath(path string) error {
+	if path == "" {
+		return errors.New("an override needs a path")
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return fmt.Errorf("%q has an empty field name", path)
+		}
+		if strings.HasPrefix(key, "$") {
+			return fmt.Errorf("%q names an operator, not a field", path)
+		}
+	}
+	return nil
+}
+
+// intValue is n as an int, or as a long when it doesn't fit.
+func intValue(n int64) any {
+	if n >= math.MinInt32 && n <= math.MaxInt32 {
+		return int32(n)
+	}
+	return n
+}
@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"vervet/internal/audit"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+	"vervet/internal/schema"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// GenerateProgressEvent carries a models.GenerateProgress from a
+	// running generation.
+	GenerateProgressEvent = "generate-progress"
+
+	// DefaultSampleSize is how many documents are sampled when the request
+	// does not say.
+	DefaultSampleSize = 1000
+	// insertBatchSize is how many documents each insert sends.
+	insertBatchSize = 1000
+)
+
+// progressInterval is the least time between progress events for one run.
+const progressInterval = 100 * time.Millisecond
+
+// ClientProvider provides access to active MongoDB connections
+type ClientProvider interface {
+	GetClient(serverID string) (*mongo.Client, error)
+}
+
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
+// Service generates documents like those of a sampled collection.
+type Service struct {
+	mu      sync.Mutex
+	log     *slog.Logger
+	ctx     context.Context
+	clients ClientProvider
+	auditor Auditor
+	cancels map[string]context.CancelFunc // generateID -> cancel for a running generation
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
+}
+
+func NewService(log *slog.Logger, clients ClientProvider) *Service {
+	return &Service{
+		log:     log.With(slog.String(logging.SourceKey, "GeneratorService")),
+		clients: clients,
+		cancels: make(map[string]context.CancelFunc),
+		emit:    runtime.EventsEmit,
+	}
+}
+
+// Init stores the Wails application context, used as the parent for
+// generations.
+func (s *Service) Init(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// SetAuditor makes the service audit every collection it inserts into.
+func (s *Service) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
+// Cancel stops a running generation. A file being written is removed;
+// documents already inserted stay.
+func (s *Service) Cancel(generateID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[generateID]; ok {
+		cancel()
+	}
+}
+
+func (s *Service) register(generateID string, cancel context.CancelFunc) {
+	if generateID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[generateID] = cancel
+}
+
+func (s *Service) unregister(generateID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, generateID)
+}
+
+// Generate samples the request's collection and makes Count documents like
+// its own, inserting them into the target collection or writing them to an
+// NDJSON file.
+func (s *Service) Generate(req models.GenerateRequest) (models.GenerateResult, error) {
+	req = withDefaults(req)
+	if err := validate(req); err != nil {
+		return models.GenerateResult{}, err
+	}
+	source, err := s.clients.GetClient(req.ServerID)
+	if err != nil {
+		return models.GenerateResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.register(req.GenerateID, cancel)
+	defer s.unregister(req.GenerateID)
+
+	sampled, err := schema.Sample(ctx, source, req.Database, req.Collection, req.SampleSize)
+	if err != nil {
+		return models.GenerateResult{}, err
+	}
+	g, err := New(sampled, req.Overrides, req.Seed)
+	if err != nil {
+		return models.GenerateResult{}, err
+	}
+
+	p := &progress{svc: s, current: models.GenerateProgress{GenerateID: req.GenerateID, Total: int64(req.Count)}}
+	var result models.GenerateResult
+	if req.Output == models.GenerateNDJSON {
+		result, err = writeNDJSON(ctx, g, req.Count, req.Path, p.update)
+	} else {
+		var target *mongo.Client
+		if target, err = s.clients.GetClient(req.TargetServerID); err != nil {
+			return models.GenerateResult{}, err
+		}
+		coll := target.Database(req.TargetDatabase).Collection(req.TargetCollection)
+		result, err = insert(ctx, coll, g, req.Count, p.update)
+		s.audit(req, result, err)
+	}
+	p.send(true)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			s.log.Info("Generation cancelled", slog.Int64("generated", result.Generated))
+			result.Cancelled = true
+			return result, nil
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func withDefaults(req models.GenerateRequest) models.GenerateRequest {
+	if req.SampleSize <= 0 {
+		req.SampleSize = DefaultSampleSize
+	}
+	if req.TargetServerID == "" {
+		req.TargetServerID = req.ServerID
+	}
+	if req.TargetDatabase == "" {
+		req.TargetDatabase = req.Database
+	}
+	return req
+}
+
+func validate(req models.GenerateRequest) error {
+	if req.ServerID == "" || req.Database == "" || req.Collection == "" {
+		return errors.New("generating needs a collection to sample")
+	}
+	if req.Count <= 0 {
+		return errors.New("choose how many documents to generate")
+	}
+	if _, err := compileOverrides(req.Overrides); err != nil {
+		return err
+	}
+	switch req.Output {
+	case models.GenerateNDJSON:
+		if req.Path == "" {
+			return errors.New("no file chosen for the documents")
+		}
+	case models.GenerateInsert:
+		if req.TargetCollection == "" {
+			return errors.New("choose a collection to insert the documents into")
+		}
+		if req.TargetServerID == req.ServerID && req.TargetDatabase == req.Database &&
+			req.TargetCollection == req.Collection {
+			return errors.New("generated documents can't go into the collection they are modelled on")
+		}
+	default:
+		return errors.New("unsupported output: " + req.Output)
+	}
+	return nil
+}
+
+// audit records the documents inserted as one change.
+func (s *Service) audit(req models.GenerateRequest, result models.GenerateResult, err error) {
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+		ServerID:      req.TargetServerID,
+		Database:      req.TargetDatabase,
+		Collection:    req.TargetCollection,
+		Operation:     "generate",
+		Source:        models.AuditSourceApp,
+		Target:        req.Database + "." + req.Collection,
+		AffectedCount: result.Generated - result.Failed,
+	}, err)
+}
+
+// progress reports how far a generation has got.
+type progress struct {
+	svc      *Service
+	current  models.GenerateProgress
+	lastSent time.Time
+}
+
+func (p *progress) update(generated int64) {
+	p.current.Generated = generated
+	p.send(false)
+}
+
+// send emits a progress event, dropping those that come sooner than
+// progressInterval after the last unless final.
+func (p *progress) send(final bool) {
+	if p.current.GenerateID == "" {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(p.lastSent) < progressInterval {
+		return
+	}
+	p.lastSent = now
+	p.svc.emit(p.svc.ctx, GenerateProgressEvent, p.current)
+}
@@ -0,0 +1,128 @@
+//go:build integration
+
+package generator
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"vervet/internal/models"
+)
+
+var testClient *mongo.Client
+
+type stubProvider struct {
+	client *mongo.Client
+}
+
+func (s stubProvider) GetClient(string) (*mongo.Client, error) {
+	return s.client, nil
+}
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (r *recordingAuditor) Record(action models.AuditAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	os.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
+
+	container, err := mongodb.Run(ctx, "mongo:7")
+	if err != nil {
+		log.Fatalf("start container: %v", err)
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			log.Printf("terminate: %v", err)
+		}
+	}()
+
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		log.Fatalf("conn string: %v", err)
+	}
+
+	testClient, err = mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer testClient.Disconnect(ctx)
+
+	os.Exit(m.Run())
+}
+
+func TestGenerate_Insert(t *testing.T) {
+	ctx := context.Background()
+	db := testClient.Database("generate_it")
+	require.NoError(t, db.Drop(ctx))
+	t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+	people := make([]any, 200)
+	for i := range people {
+		doc := bson.D{{Key: "age", Value: int32(20 + i%40)}, {Key: "email", Value: "someone@example.com"}}
+		if i%2 == 0 {
+			doc = append(doc, bson.E{Key: "nickname", Value: "nick"})
+		}
+		people[i] = doc
+	}
+	_, err := db.Collection("people").InsertMany(ctx, people)
+	require.NoError(t, err)
+
+	svc := NewService(slog.Default(), stubProvider{client: testClient})
+	svc.Init(ctx)
+	var events []models.GenerateProgress
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events = append(events, data[0].(models.GenerateProgress))
+	}
+	auditor := &recordingAuditor{}
+	svc.SetAuditor(auditor)
+
+	result, err := svc.Generate(models.GenerateRequest{
+		GenerateID: "g1", ServerID: "srv", Database: "generate_it", Collection: "people",
+		Count: 2500, Output: models.GenerateInsert, TargetCollection: "people_fake",
+		Overrides: []models.GenerateOverride{{Path: "source", Kind: models.OverrideConstant, Values: []string{`"generated"`}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.GenerateResult{Generated: 2500}, result)
+
+	fake := db.Collection("people_fake")
+	n, err := fake.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), n)
+
+	n, err = fake.CountDocuments(ctx, bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: 20}, {Key: "$lte", Value: 59}}}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), n, "ages stay in the sampled range")
+
+	n, err = fake.CountDocuments(ctx, bson.D{{Key: "source", Value: "generated"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), n)
+
+	n, err = fake.CountDocuments(ctx, bson.D{{Key: "nickname", Value: bson.D{{Key: "$exists", Value: true}}}})
+	require.NoError(t, err)
+	assert.InDelta(t, 1250, n, 200, "nickname is in about half")
+
+	require.NotEmpty(t, events)
+	assert.Equal(t, int64(2500), events[len(events)-1].Generated)
+
+	require.Len(t, auditor.actions, 1)
+	assert.Equal(t, "generate", auditor.actions[0].Operation)
+	assert.Equal(t, "people_fake", auditor.actions[0].Collection)
+	assert.Equal(t, int64(2500), auditor.actions[0].AffectedCount)
+}
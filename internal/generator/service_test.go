@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Validation(t *testing.T) {
+	svc := NewService(slog.Default(), nil)
+	base := models.GenerateRequest{
+		ServerID: "srv", Database: "db", Collection: "people", Count: 10,
+		Output: models.GenerateInsert, TargetCollection: "people_fake",
+	}
+
+	noCollection := base
+	noCollection.Collection = ""
+	_, err := svc.Generate(noCollection)
+	assert.ErrorContains(t, err, "needs a collection to sample")
+
+	noCount := base
+	noCount.Count = 0
+	_, err = svc.Generate(noCount)
+	assert.ErrorContains(t, err, "how many documents")
+
+	badOverride := base
+	badOverride.Overrides = []models.GenerateOverride{{Path: "age", Kind: models.OverrideRange}}
+	_, err = svc.Generate(badOverride)
+	assert.ErrorContains(t, err, "override 1 (age)")
+
+	noTarget := base
+	noTarget.TargetCollection = ""
+	_, err = svc.Generate(noTarget)
+	assert.ErrorContains(t, err, "collection to insert")
+
+	sameTarget := base
+	sameTarget.TargetCollection = "people"
+	_, err = svc.Generate(sameTarget)
+	assert.ErrorContains(t, err, "modelled on")
+
+	noPath := base
+	noPath.Output = models.GenerateNDJSON
+	_, err = svc.Generate(noPath)
+	assert.ErrorContains(t, err, "no file chosen")
+
+	badOutput := base
+	badOutput.Output = "csv"
+	_, err = svc.Generate(badOutput)
+	assert.ErrorContains(t, err, "unsupported output")
+}
+
+func TestWithDefaults(t *testing.T) {
+	req := withDefaults(models.GenerateRequest{ServerID: "srv", Database: "db"})
+	assert.Equal(t, DefaultSampleSize, req.SampleSize)
+	assert.Equal(t, "srv", req.TargetServerID)
+	assert.Equal(t, "db", req.TargetDatabase)
+
+	req = withDefaults(models.GenerateRequest{ServerID: "srv", Database: "db", SampleSize: 50, TargetServerID: "other", TargetDatabase: "test"})
+	assert.Equal(t, 50, req.SampleSize)
+	assert.Equal(t, "other", req.TargetServerID)
+	assert.Equal(t, "test", req.TargetDatabase)
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	g, err := New(people(), nil, 7)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "people.ndjson")
+
+	var reported int64
+	result, err := writeNDJSON(context.Background(), g, 5, path, func(n int64) { reported = n })
+	require.NoError(t, err)
+	assert.Equal(t, models.GenerateResult{Generated: 5, Path: path}, result)
+	assert.Equal(t, int64(5), reported)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 5)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, `{"_id":{"$oid":`), line)
+	}
+}
+
+func TestWriteNDJSON_CancelledRemovesFile(t *testing.T) {
+	g, err := New(people(), nil, 7)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "people.ndjson")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = writeNDJSON(ctx, g, 5, path, func(int64) {})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, path)
+}
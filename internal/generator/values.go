@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"vervet/internal/masking"
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Ranges for types the sample gave none for.
+const (
+	defaultNumberMax = 1000
+	defaultStringMax = 24
+	defaultArrayMax  = 3
+)
+
+var words = strings.Fields(`
+	lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut
+	labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris
+	nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit esse
+	cillum fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui
+	officia deserunt mollit anim id est laborum`)
+
+// value makes a value for f, of one of the types it was seen holding, as
+// often as it was seen holding it.
+func (g *Generator) value(f field) any {
+	if f.total == 0 {
+		return nil
+	}
+	n := g.rng.IntN(f.total)
+	t := f.types[len(f.types)-1]
+	for _, candidate := range f.types {
+		if n < candidate.count {
+			t = candidate
+			break
+		}
+		n -= candidate.count
+	}
+
+	switch t.name {
+	case "bool":
+		return g.rng.IntN(2) == 1
+	case "int":
+		lo, hi := g.numberRange(t)
+		return int32(g.intBetween(int64(math.Ceil(lo)), int64(math.Floor(hi))))
+	case "long":
+		lo, hi := g.numberRange(t)
+		return g.intBetween(int64(math.Ceil(lo)), int64(math.Floor(hi)))
+	case "double":
+		lo, hi := g.numberRange(t)
+		return g.floatBetween(lo, hi, t.places)
+	case "decimal":
+		d, err := bson.ParseDecimal128(fmt.Sprintf("%.2f", g.floatBetween(0, defaultNumberMax, 2)))
+		if err != nil {
+			return nil
+		}
+		return d
+	case "date":
+		if !t.hasRange {
+			return bson.NewDateTimeFromTime(time.Now())
+		}
+		return g.dateBetween(int64(t.lo), int64(t.hi))
+	case "objectId":
+		return bson.NewObjectID()
+	case "string":
+		lo, hi := t.minLen, t.maxLen
+		if !t.hasLen {
+			hi = defaultStringMax
+		}
+		return g.text(f.name, lo, hi)
+	case "binary":
+		data := make([]byte, 16)
+		for i := range data {
+			data[i] = byte(g.rng.UintN(256))
+		}
+		return bson.Binary{Data: data}
+	case "array":
+		lo, hi := t.minLen, t.maxLen
+		if !t.hasLen {
+			hi = defaultArrayMax
+		}
+		a := make(bson.A, g.intBetween(int64(lo), int64(hi)))
+		for i := range a {
+			if t.elem != nil {
+				a[i] = g.value(*t.elem)
+			}
+		}
+		return a
+	case "object":
+		return g.document(t.children)
+	default:
+		// null, and types such as regex that there is nothing sensible to
+		// make up.
+		return nil
+	}
+}
+
+func (g *Generator) numberRange(t fieldType) (float64, float64) {
+	if !t.hasRange {
+		return 0, defaultNumberMax
+	}
+	return t.lo, t.hi
+}
+
+// text makes a string for the field called name: a fake name, email, phone
+// number or address when the name suggests one, otherwise words between lo
+// and hi characters long.
+func (g *Generator) text(name string, lo, hi int) string {
+	if kind := fakeKind(name); kind != "" {
+		return g.fake(kind)
+	}
+	length := int(g.intBetween(int64(lo), int64(hi)))
+	var b strings.Builder
+	for b.Len() < length {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(words[g.rng.IntN(len(words))])
+	}
+	s := b.String()[:length]
+	// Don't end on the space between two words.
+	if strings.HasSuffix(s, " ") {
+		s = s[:len(s)-1] + words[g.rng.IntN(len(words))][:1]
+	}
+	return s
+}
+
+// fakeKind is the kind of fake value a field's name asks for, if any.
+func fakeKind(name string) string {
+	n := strings.ToLower(name)
+	switch {
+	case strings.Contains(n, "email"):
+		return models.FakeEmail
+	case strings.Contains(n, "phone") || strings.Contains(n, "mobile"):
+		return models.FakePhone
+	case strings.Contains(n, "address") || strings.Contains(n, "street"):
+		return models.FakeAddress
+	case n == "name" || n == "fullname" || n == "full_name" || n == "customername" ||
+		n == "contactname" || n == "displayname":
+		return models.FakeName
+	}
+	return ""
+}
+
+func (g *Generator) fake(kind string) string {
+	return masking.Fake(kind, g.rng.Uint64())
+}
+
+// intBetween picks a whole number from lo to hi, both included.
+func (g *Generator) intBetween(lo, hi int64) int64 {
+	if hi <= lo {
+		return lo
+	}
+	span := uint64(hi - lo)
+	if span == math.MaxUint64 {
+		return int64(g.rng.Uint64())
+	}
+	return lo + int64(g.rng.Uint64N(span+1))
+}
+
+// floatBetween picks a number from lo to hi, rounded to places.
+func (g *Generator) floatBetween(lo, hi float64, places int) float64 {
+	v := lo + g.rng.Float64()*(hi-lo)
+	scale := math.Pow10(places)
+	v = math.Round(v*scale) / scale
+	return min(max(v, lo), hi)
+}
+
+func (g *Generator) dateBetween(lo, hi int64) bson.DateTime {
+	return bson.DateTime(g.intBetween(lo, hi))
+}
@@ -0,0 +1,232 @@
+// Package history keeps a local record of the queries run through the app, so
+// they can be searched and run again.
+package history
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vervet/internal/logging"
+	"vervet/internal/models"
+)
+
+const (
+	// MaxEntries is how many executions the history keeps. Recording one
+	// more drops the oldest.
+	MaxEntries = 2000
+	// maxBytes bounds the text the history holds across all entries, so a
+	// run of very long scripts cannot make the file huge.
+	maxBytes = 16 << 20
+	// maxQueryBytes is the longest query kept whole. Longer ones keep their
+	// start, marked QueryTruncated.
+	maxQueryBytes = 64 << 10
+	// defaultSearchLimit caps a search that does not set its own limit.
+	defaultSearchLimit = 500
+	// defaultSaveDelay is how long Record waits before writing the history,
+	// so a burst of queries writes the file once.
+	defaultSaveDelay = 2 * time.Second
+)
+
+type Service struct {
+	log   *slog.Logger
+	store HistoryStore
+
+	mu sync.Mutex
+	// entries is the history, oldest first, once loaded.
+	entries []models.HistoryEntry
+	loaded  bool
+	// dirty is set when entries has changes the store does not have yet.
+	dirty bool
+	// timer is the pending background save, if any.
+	timer     *time.Timer
+	saveDelay time.Duration
+
+	// saveMu keeps saves in order, so an older snapshot never overwrites a
+	// newer one.
+	saveMu sync.Mutex
+}
+
+func NewService(log *slog.Logger, store HistoryStore) *Service {
+	return &Service{
+		log:       log.With(slog.String(logging.SourceKey, "HistoryService")),
+		store:     store,
+		saveDelay: defaultSaveDelay,
+	}
+}
+
+// load reads the history from the store the first time it is needed.
+// Callers must hold s.mu.
+func (s *Service) load() error {
+	if s.loaded {
+		return nil
+	}
+	entries, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+	s.entries = entries
+	s.loaded = true
+	return nil
+}
+
+// Record adds a query execution to the history, dropping the oldest entries
+// once the history is over its bounds. The store is written shortly after in
+// the background; Flush writes it at once.
+func (s *Service) Record(entry models.HistoryEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if len(entry.Query) > maxQueryBytes {
+		// Cutting mid-character leaves a broken last rune; drop it.
+		entry.Query = strings.ToValidUTF8(entry.Query[:maxQueryBytes], "")
+		entry.QueryTruncated = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.entries = trim(append(s.entries, entry))
+	s.dirty = true
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.saveDelay, s.saveLater)
+	}
+	return nil
+}
+
+func (s *Service) saveLater() {
+	if err := s.Flush(); err != nil {
+		s.log.Warn("Failed to save query history", slog.Any("error", err))
+	}
+}
+
+// Flush writes any unsaved changes to the store.
+func (s *Service) Flush() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	snapshot := slices.Clone(s.entries)
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := s.store.Save(snapshot); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// trim drops the oldest entries until entries is within MaxEntries and
+// maxBytes.
+func trim(entries []models.HistoryEntry) []models.HistoryEntry {
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	total := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		total += entrySize(entries[i])
+		if total > maxBytes {
+			return entries[i+1:]
+		}
+	}
+	return entries
+}
+
+func entrySize(e models.HistoryEntry) int {
+	return len(e.Query) + len(e.ScriptPath) + len(e.Database) + len(e.ServerID) + len(e.ID)
+}
+
+// Search returns the entries filter matches, newest first.
+func (s *Service) Search(filter models.HistoryFilter) ([]models.HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	words := strings.Fields(strings.ToLower(filter.Text))
+
+	found := make([]models.HistoryEntry, 0)
+	for i := len(s.entries) - 1; i >= 0 && len(found) < limit; i-- {
+		if matches(s.entries[i], filter, words) {
+			found = append(found, s.entries[i])
+		}
+	}
+	return found, nil
+}
+
+// matches reports whether e passes filter. Every one of words must appear
+// somewhere in the entry's query, database or script path.
+func matches(e models.HistoryEntry, filter models.HistoryFilter, words []string) bool {
+	if filter.ServerID != "" && e.ServerID != filter.ServerID {
+		return false
+	}
+	if filter.From > 0 && e.StartedAt < filter.From {
+		return false
+	}
+	if filter.To > 0 && e.StartedAt > filter.To {
+		return false
+	}
+	if len(words) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(e.Query + "\n" + e.Database + "\n" + e.ScriptPath)
+	for _, word := range words {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete removes one entry from the history.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	if err := s.load(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	idx := slices.IndexFunc(s.entries, func(e models.HistoryEntry) bool {
+		return e.ID == id
+	})
+	if idx == -1 {
+		s.mu.Unlock()
+		return fmt.Errorf("history entry not found: %s", id)
+	}
+	s.entries = slices.Delete(s.entries, idx, idx+1)
+	s.dirty = true
+	s.mu.Unlock()
+	return s.Flush()
+}
+
+// Clear empties the history.
+func (s *Service) Clear() error {
+	s.mu.Lock()
+	s.entries = nil
+	s.loaded = true
+	s.dirty = true
+	s.mu.Unlock()
+	return s.Flush()
+}
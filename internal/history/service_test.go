@@ -0,0 +1,249 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStore struct {
+	mu      sync.Mutex
+	entries []models.HistoryEntry
+	loads   int
+	saves   int
+	loadErr error
+}
+
+func (m *mockStore) Load() ([]models.HistoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads++
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	return m.entries, nil
+}
+
+func (m *mockStore) saveCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saves
+}
+
+func (m *mockStore) Save(entries []models.HistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saves++
+	m.entries = append([]models.HistoryEntry(nil), entries...)
+	return nil
+}
+
+// newTestService returns a service whose background save never fires, so
+// tests see the store change only when they call Flush.
+func newTestService() (*Service, *mockStore) {
+	store := &mockStore{}
+	svc := NewService(slog.Default(), store)
+	svc.saveDelay = time.Hour
+	return svc, store
+}
+
+func entry(serverID, query string, startedAt int64) models.HistoryEntry {
+	return models.HistoryEntry{
+		ServerID:  serverID,
+		Database:  "shop",
+		Query:     query,
+		Engine:    "builtin",
+		StartedAt: startedAt,
+		Outcome:   models.HistorySucceeded,
+	}
+}
+
+func TestRecord_PersistsEntries(t *testing.T) {
+	svc, store := newTestService()
+
+	require.NoError(t, svc.Record(entry("prod", "db.orders.find()", 1000)))
+	require.NoError(t, svc.Record(entry("prod", "db.users.find()", 2000)))
+	require.NoError(t, svc.Flush())
+
+	require.Len(t, store.entries, 2)
+	assert.NotEmpty(t, store.entries[0].ID)
+	assert.NotEqual(t, store.entries[0].ID, store.entries[1].ID)
+	assert.Equal(t, 1, store.loads, "the history is read once")
+}
+
+func TestRecord_DropsOldestOverMaxEntries(t *testing.T) {
+	svc, store := newTestService()
+	for i := range MaxEntries + 5 {
+		require.NoError(t, svc.Record(entry("s", fmt.Sprintf("q%d", i), int64(i))))
+	}
+	require.NoError(t, svc.Flush())
+
+	require.Len(t, store.entries, MaxEntries)
+	assert.Equal(t, "q5", store.entries[0].Query)
+	assert.Equal(t, fmt.Sprintf("q%d", MaxEntries+4), store.entries[MaxEntries-1].Query)
+}
+
+func TestRecord_DropsOldestOverMaxBytes(t *testing.T) {
+	svc, store := newTestService()
+	query := strings.Repeat("x", maxQueryBytes)
+	n := maxBytes/maxQueryBytes + 3
+	for i := range n {
+		require.NoError(t, svc.Record(entry("s", query, int64(i))))
+	}
+	require.NoError(t, svc.Flush())
+
+	assert.Less(t, len(store.entries), n)
+	total := 0
+	for _, e := range store.entries {
+		total += entrySize(e)
+	}
+	assert.LessOrEqual(t, total, maxBytes)
+	assert.Equal(t, int64(n-1), store.entries[len(store.entries)-1].StartedAt, "the newest entry is kept")
+}
+
+func TestRecord_TruncatesLongQueries(t *testing.T) {
+	svc, store := newTestService()
+	// A multi-byte character straddles the cut.
+	query := strings.Repeat("a", maxQueryBytes-1) + "é" + "tail"
+
+	require.NoError(t, svc.Record(entry("s", query, 1)))
+	require.NoError(t, svc.Flush())
+
+	got := store.entries[0]
+	assert.True(t, got.QueryTruncated)
+	assert.Equal(t, strings.Repeat("a", maxQueryBytes-1), got.Query)
+}
+
+func TestRecord_LoadError(t *testing.T) {
+	store := &mockStore{loadErr: errors.New("disk gone")}
+	svc := NewService(slog.Default(), store)
+
+	assert.Error(t, svc.Record(entry("s", "q", 1)))
+	require.NoError(t, svc.Flush())
+	assert.Zero(t, store.saves)
+}
+
+func TestRecord_SavesInTheBackground(t *testing.T) {
+	store := &mockStore{}
+	svc := NewService(slog.Default(), store)
+	svc.saveDelay = 20 * time.Millisecond
+
+	for i := range 10 {
+		require.NoError(t, svc.Record(entry("s", fmt.Sprintf("q%d", i), int64(i))))
+	}
+	assert.Zero(t, store.saveCount(), "Record does not write the store itself")
+
+	assert.Eventually(t, func() bool { return store.saveCount() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, store.saveCount(), "a burst of records is written once")
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Len(t, store.entries, 10)
+}
+
+func TestFlush_NothingToSave(t *testing.T) {
+	svc, store := newTestService()
+	require.NoError(t, svc.Flush())
+	assert.Zero(t, store.saves)
+}
+
+func TestSearch(t *testing.T) {
+	svc, _ := newTestService()
+	require.NoError(t, svc.Record(entry("prod", "db.orders.find({status: 'open'})", 1000)))
+	require.NoError(t, svc.Record(entry("staging", "db.orders.deleteMany({})", 2000)))
+	require.NoError(t, svc.Record(entry("prod", "db.Users.updateOne({}, {})", 3000)))
+
+	queries := func(found []models.HistoryEntry) []string {
+		out := make([]string, len(found))
+		for i, e := range found {
+			out[i] = e.Query
+		}
+		return out
+	}
+
+	t.Run("everything, newest first", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"db.Users.updateOne({}, {})",
+			"db.orders.deleteMany({})",
+			"db.orders.find({status: 'open'})",
+		}, queries(found))
+	})
+
+	t.Run("by server", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{ServerID: "prod"})
+		require.NoError(t, err)
+		assert.Len(t, found, 2)
+	})
+
+	t.Run("by text, ignoring case, every word", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{Text: "USERS update"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db.Users.updateOne({}, {})"}, queries(found))
+
+		found, err = svc.Search(models.HistoryFilter{Text: "orders open"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db.orders.find({status: 'open'})"}, queries(found))
+	})
+
+	t.Run("by database", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{Text: "shop"})
+		require.NoError(t, err)
+		assert.Len(t, found, 3)
+	})
+
+	t.Run("by time", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{From: 1500, To: 2500})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db.orders.deleteMany({})"}, queries(found))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db.Users.updateOne({}, {})"}, queries(found))
+	})
+
+	t.Run("no match is an empty list", func(t *testing.T) {
+		found, err := svc.Search(models.HistoryFilter{Text: "nothing like this"})
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+		assert.Empty(t, found)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	svc, store := newTestService()
+	require.NoError(t, svc.Record(entry("s", "first", 1)))
+	require.NoError(t, svc.Record(entry("s", "second", 2)))
+	require.NoError(t, svc.Flush())
+
+	require.NoError(t, svc.Delete(store.entries[0].ID))
+	require.Len(t, store.entries, 1)
+	assert.Equal(t, "second", store.entries[0].Query)
+
+	assert.Error(t, svc.Delete("missing"))
+}
+
+func TestClear(t *testing.T) {
+	svc, store := newTestService()
+	require.NoError(t, svc.Record(entry("s", "q", 1)))
+
+	require.NoError(t, svc.Clear())
+	assert.Empty(t, store.entries)
+
+	found, err := svc.Search(models.HistoryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
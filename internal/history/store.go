@@ -0,0 +1,64 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"vervet/internal/infrastructure"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+)
+
+// HistoryStore persists the query history, oldest entry first.
+type HistoryStore interface {
+	Load() ([]models.HistoryEntry, error)
+	Save(entries []models.HistoryEntry) error
+}
+
+type store struct {
+	cfgStore infrastructure.Store
+	log      *slog.Logger
+}
+
+// NewStore returns the store kept in history.json in the config directory.
+// It is JSON rather than YAML like the other stores: it is never edited by
+// hand, and it is rewritten after every query.
+func NewStore(log *slog.Logger) (*store, error) {
+	logger := log.With(slog.String(logging.SourceKey, "HistoryStore"))
+	cfgStore, err := infrastructure.NewStore("history.json", logger)
+	if err != nil {
+		return nil, fmt.Errorf("error loading query history: %w", err)
+	}
+	return &store{cfgStore: cfgStore, log: logger}, nil
+}
+
+// Load reads the history. A file that cannot be parsed is logged and treated
+// as empty: the history is a convenience, and losing it must not stop queries
+// from being recorded.
+func (s *store) Load() ([]models.HistoryEntry, error) {
+	b, err := s.cfgStore.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error loading query history: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var entries []models.HistoryEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		s.log.Warn("Query history is corrupted, starting a new one", slog.Any("error", err))
+		return nil, nil
+	}
+	return entries, nil
+}
+
+func (s *store) Save(entries []models.HistoryEntry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error marshalling query history: %w", err)
+	}
+	if err := s.cfgStore.Save(b); err != nil {
+		return fmt.Errorf("error saving query history: %w", err)
+	}
+	return nil
+}
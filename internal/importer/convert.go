@@ -0,0 +1,301 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// numberPattern matches what the CSV export writes for numbers. Leading zeros
+// are left out so codes like "007" stay strings.
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// dateLayouts are the date formats a date column accepts, tried in order.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// convertCell turns one CSV cell into a value of the column's type. A blank
+// cell is an empty string in auto and string columns and null in the others.
+func convertCell(cell, typ string) (any, error) {
+	switch typ {
+	case "", models.ImportTypeAuto:
+		return autoValue(cell), nil
+	case models.ImportTypeString:
+		return cell, nil
+	}
+
+	if cell == "" {
+		return nil, nil
+	}
+	switch typ {
+	case models.ImportTypeNumber:
+		return parseNumber(cell)
+	case models.ImportTypeBoolean:
+		b, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", cell)
+		}
+		return b, nil
+	case models.ImportTypeDate:
+		return parseDate(cell)
+	case models.ImportTypeObjectID:
+		return parseObjectID(cell)
+	case models.ImportTypeEJSON:
+		return parseEJSONValue(cell)
+	default:
+		return nil, fmt.Errorf("unknown column type %q", typ)
+	}
+}
+
+// autoValue reads back the cells flattened exports write: booleans, numbers,
+// and Extended JSON for arrays and BSON types. Anything else is a string.
+// Indexed array columns are put back together by setPath.
+func autoValue(cell string) any {
+	switch cell {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if numberPattern.MatchString(cell) {
+		if n, err := parseNumber(cell); err == nil {
+			return n
+		}
+	}
+	if strings.HasPrefix(cell, `{"$`) || strings.HasPrefix(cell, "[") {
+		if v, err := parseEJSONValue(cell); err == nil {
+			return v
+		}
+	}
+	return cell
+}
+
+// parseNumber keeps whole numbers as integers, int32 where they fit, and
+// reads anything else as a double.
+func parseNumber(cell string) (any, error) {
+	s := strings.TrimSpace(cell)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n == int64(int32(n)) {
+			return int32(n), nil
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", cell)
+	}
+	return f, nil
+}
+
+// parseDate accepts ISO 8601 dates and times, milliseconds since the epoch,
+// and Extended JSON dates. Times without a zone are UTC.
+func parseDate(cell string) (any, error) {
+	s := strings.TrimSpace(cell)
+	if strings.HasPrefix(s, "{") {
+		v, err := parseEJSONValue(s)
+		if err == nil {
+			if d, ok := v.(bson.DateTime); ok {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a date", cell)
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return bson.DateTime(ms), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return bson.NewDateTimeFromTime(t), nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a date", cell)
+}
+
+// parseObjectID accepts a hex ObjectId or its Extended JSON form, which is
+// what the CSV export writes.
+func parseObjectID(cell string) (any, error) {
+	s := strings.TrimSpace(cell)
+	if strings.HasPrefix(s, "{") {
+		v, err := parseEJSONValue(s)
+		if err == nil {
+			if id, ok := v.(bson.ObjectID); ok {
+				return id, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not an ObjectId", cell)
+	}
+	id, err := bson.ObjectIDFromHex(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an ObjectId", cell)
+	}
+	return id, nil
+}
+
+// parseEJSONValue reads any Extended JSON value, not only documents, by
+// wrapping it in one.
+func parseEJSONValue(cell string) (any, error) {
+	var wrapper bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"v":`+cell+`}`), false, &wrapper); err != nil || len(wrapper) != 1 {
+		return nil, fmt.Errorf("%q is not valid Extended JSON", cell)
+	}
+	return wrapper[0].Value, nil
+}
+
+// setPath stores value at a dot path, creating the documents on the way. It
+// is the inverse of the export's flattening: "address.city" and
+// "address.zip" rebuild one address document, and "tags.0" and "tags.1",
+// which indexed array columns write, rebuild the tags array.
+func setPath(doc *bson.D, path string, value any) error {
+	parts := strings.Split(path, ".")
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("%q is not a valid field path", path)
+		}
+	}
+	updated, err := setParts(*doc, parts, path, value)
+	if err != nil {
+		return err
+	}
+	*doc = updated
+	return nil
+}
+
+func setParts(doc bson.D, parts []string, path string, value any) (bson.D, error) {
+	key := parts[0]
+	idx := indexOf(doc, key)
+	if len(parts) == 1 {
+		if idx >= 0 {
+			return nil, fmt.Errorf("field %q is set by more than one column", path)
+		}
+		return append(doc, bson.E{Key: key, Value: value}), nil
+	}
+
+	if idx < 0 {
+		doc = append(doc, bson.E{Key: key, Value: newContainer(parts[1])})
+		idx = len(doc) - 1
+	}
+	nested, err := setIn(doc[idx].Value, parts[1:], path, value)
+	if err != nil {
+		return nil, err
+	}
+	doc[idx].Value = nested
+	return doc, nil
+}
+
+// setIn stores value at parts within container: a document, or an array
+// when parts starts with an index.
+func setIn(container any, parts []string, path string, value any) (any, error) {
+	switch c := container.(type) {
+	case bson.D:
+		return setParts(c, parts, path, value)
+	case bson.A:
+		if i, ok := arrayIndex(parts[0]); ok {
+			return setElement(c, i, parts, path, value)
+		}
+	}
+	prefix := strings.TrimSuffix(path, "."+strings.Join(parts, "."))
+	return nil, fmt.Errorf("field %q conflicts with %q", path, prefix)
+}
+
+// setElement stores value at parts within element i of arr, padding any gap
+// before it with nulls, as the columns needn't come in index order.
+func setElement(arr bson.A, i int, parts []string, path string, value any) (bson.A, error) {
+	for len(arr) <= i {
+		arr = append(arr, nil)
+	}
+	if len(parts) == 1 {
+		if arr[i] != nil {
+			return nil, fmt.Errorf("field %q is set by more than one column", path)
+		}
+		arr[i] = value
+		return arr, nil
+	}
+	elem := arr[i]
+	if elem == nil {
+		elem = newContainer(parts[1])
+	}
+	elem, err := setIn(elem, parts[1:], path, value)
+	if err != nil {
+		return nil, err
+	}
+	arr[i] = elem
+	return arr, nil
+}
+
+// maxArrayIndex bounds the index a path may give, so a stray numeric field
+// name can't pad an array to millions of nulls.
+const maxArrayIndex = 100_000
+
+// newContainer is what a path segment is stored in: an array when the next
+// segment is an index, otherwise a document.
+func newContainer(next string) any {
+	if _, ok := arrayIndex(next); ok {
+		return bson.A{}
+	}
+	return bson.D{}
+}
+
+// arrayIndex reads a path segment that is all digits, as the export writes
+// array indexes, without leading zeros.
+func arrayIndex(key string) (int, bool) {
+	if key != "0" && strings.HasPrefix(key, "0") {
+		return 0, false
+	}
+	for _, r := range key {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i > maxArrayIndex {
+		return 0, false
+	}
+	return i, true
+}
+
+func indexOf(doc bson.D, key string) int {
+	for i, e := range doc {
+		if e.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookupPath returns the value at a dot path, used to build upsert filters.
+// Index segments reach into arrays, as setPath builds them.
+func lookupPath(doc bson.D, path string) (any, error) {
+	var current any = doc
+	for _, key := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case bson.D:
+			idx := indexOf(c, key)
+			if idx < 0 {
+				return nil, errMissingKey
+			}
+			current = c[idx].Value
+		case bson.A:
+			i, ok := arrayIndex(key)
+			if !ok || i >= len(c) {
+				return nil, errMissingKey
+			}
+			current = c[i]
+		default:
+			return nil, errMissingKey
+		}
+	}
+	return current, nil
+}
+
+var errMissingKey = errors.New("missing")
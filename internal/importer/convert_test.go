@@ -0,0 +1,165 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestConvertCell_Auto(t *testing.T) {
+	id := bson.NewObjectID()
+	tests := []struct {
+		name string
+		cell string
+		want any
+	}{
+		{"empty", "", ""},
+		{"text", "hello", "hello"},
+		{"true", "true", true},
+		{"false", "false", false},
+		{"int", "42", int32(42)},
+		{"negative", "-7", int32(-7)},
+		{"long", "9000000000", int64(9000000000)},
+		{"double", "1.5", 1.5},
+		{"leading zero stays text", "007", "007"},
+		{"array", `[1,"a"]`, bson.A{int32(1), "a"}},
+		{"objectId", `{"$oid":"` + id.Hex() + `"}`, id},
+		{"broken ejson stays text", `{"$oid":`, `{"$oid":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertCell(tt.cell, models.ImportTypeAuto)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertCell_Typed(t *testing.T) {
+	id := bson.NewObjectID()
+	day := bson.NewDateTimeFromTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name string
+		cell string
+		typ  string
+		want any
+	}{
+		{"string keeps numbers", "007", models.ImportTypeString, "007"},
+		{"number", "3.25", models.ImportTypeNumber, 3.25},
+		{"number with leading zero", "007", models.ImportTypeNumber, int32(7)},
+		{"boolean", "TRUE", models.ImportTypeBoolean, true},
+		{"date", "2026-03-01", models.ImportTypeDate, day},
+		{"date time", "2026-03-01T00:00:00Z", models.ImportTypeDate, day},
+		{"date millis", "1772323200000", models.ImportTypeDate, day},
+		{"date ejson", `{"$date":"2026-03-01T00:00:00Z"}`, models.ImportTypeDate, day},
+		{"objectId hex", id.Hex(), models.ImportTypeObjectID, id},
+		{"objectId ejson", `{"$oid":"` + id.Hex() + `"}`, models.ImportTypeObjectID, id},
+		{"ejson", `{"$numberLong":"5"}`, models.ImportTypeEJSON, int64(5)},
+		{"blank typed cell is null", "", models.ImportTypeNumber, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertCell(tt.cell, tt.typ)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertCell_Errors(t *testing.T) {
+	tests := []struct {
+		cell string
+		typ  string
+	}{
+		{"abc", models.ImportTypeNumber},
+		{"maybe", models.ImportTypeBoolean},
+		{"yesterday", models.ImportTypeDate},
+		{"xyz", models.ImportTypeObjectID},
+		{"{nope", models.ImportTypeEJSON},
+		{"x", "currency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			_, err := convertCell(tt.cell, tt.typ)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	t.Run("rebuilds nested documents", func(t *testing.T) {
+		doc := bson.D{}
+		require.NoError(t, setPath(&doc, "name", "Ada"))
+		require.NoError(t, setPath(&doc, "address.city", "London"))
+		require.NoError(t, setPath(&doc, "address.geo.lat", 51.5))
+		require.NoError(t, setPath(&doc, "address.zip", "N1"))
+
+		assert.Equal(t, bson.D{
+			{Key: "name", Value: "Ada"},
+			{Key: "address", Value: bson.D{
+				{Key: "city", Value: "London"},
+				{Key: "geo", Value: bson.D{{Key: "lat", Value: 51.5}}},
+				{Key: "zip", Value: "N1"},
+			}},
+		}, doc)
+	})
+
+	t.Run("field set twice", func(t *testing.T) {
+		doc := bson.D{}
+		require.NoError(t, setPath(&doc, "a.b", 1))
+		assert.ErrorContains(t, setPath(&doc, "a.b", 2), "more than one column")
+	})
+
+	t.Run("value in the way", func(t *testing.T) {
+		doc := bson.D{}
+		require.NoError(t, setPath(&doc, "a", 1))
+		assert.ErrorContains(t, setPath(&doc, "a.b", 2), `conflicts with "a"`)
+	})
+
+	t.Run("empty segment", func(t *testing.T) {
+		doc := bson.D{}
+		assert.Error(t, setPath(&doc, "a..b", 1))
+	})
+
+	t.Run("index segments rebuild arrays", func(t *testing.T) {
+		doc := bson.D{}
+		require.NoError(t, setPath(&doc, "tags.1", "b"))
+		require.NoError(t, setPath(&doc, "tags.0", "a"))
+		require.NoError(t, setPath(&doc, "items.0.sku", "X1"))
+		require.NoError(t, setPath(&doc, "items.2.sku", "X3"))
+		require.NoError(t, setPath(&doc, "code.007", 1))
+
+		assert.Equal(t, bson.D{
+			{Key: "tags", Value: bson.A{"a", "b"}},
+			{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "X1"}}, nil, bson.D{{Key: "sku", Value: "X3"}}}},
+			{Key: "code", Value: bson.D{{Key: "007", Value: 1}}},
+		}, doc, "gaps are null, and leading zeros are field names")
+		assert.ErrorContains(t, setPath(&doc, "tags.0", "c"), "more than one column")
+		assert.ErrorContains(t, setPath(&doc, "tags.x", "c"), `conflicts with "tags"`)
+	})
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := bson.D{{Key: "a", Value: bson.D{{Key: "b", Value: "x"}}}, {Key: "c", Value: 1}}
+
+	v, err := lookupPath(doc, "a.b")
+	require.NoError(t, err)
+	assert.Equal(t, "x", v)
+
+	_, err = lookupPath(doc, "a.z")
+	assert.Error(t, err)
+	_, err = lookupPath(doc, "c.d")
+	assert.Error(t, err)
+
+	doc = bson.D{{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "X1"}}}}}
+	v, err = lookupPath(doc, "items.0.sku")
+	require.NoError(t, err)
+	assert.Equal(t, "X1", v)
+	_, err = lookupPath(doc, "items.1.sku")
+	assert.Error(t, err)
+}
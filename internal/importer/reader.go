@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// documentReader reads a file one document at a time. Next returns io.EOF at
+// the end of the file and a *rowError for a row that cannot be imported but
+// does not stop the rest; any other error ends the import.
+type documentReader interface {
+	Next() (row int64, doc bson.D, err error)
+}
+
+// rowError rejects a single row.
+type rowError struct {
+	row int64
+	err error
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.row, e.err)
+}
+
+func (e *rowError) Unwrap() error {
+	return e.err
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// skipBOM drops the byte order mark spreadsheet applications put at the start
+// of UTF-8 files.
+func skipBOM(r *bufio.Reader) {
+	if b, err := r.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		_, _ = r.Discard(len(utf8BOM))
+	}
+}
+
+func newReader(format string, r io.Reader, opts models.ImportCSVOptions) (documentReader, error) {
+	br := bufio.NewReader(r)
+	skipBOM(br)
+	switch format {
+	case models.ImportNDJSON:
+		return &ndjsonReader{r: br}, nil
+	case models.ImportJSON:
+		return newJSONReader(br)
+	case models.ImportCSV:
+		return newCSVReader(br, opts)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q", format)
+	}
+}
+
+// parseDocument reads one Extended JSON document, canonical or relaxed.
+func parseDocument(data []byte) (bson.D, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+		return nil, fmt.Errorf("not a valid Extended JSON document: %w", err)
+	}
+	return doc, nil
+}
+
+// ndjsonReader reads one document per line, skipping blank lines. A line that
+// does not parse only rejects that line.
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int64
+}
+
+func (n *ndjsonReader) Next() (int64, bson.D, error) {
+	for {
+		data, err := n.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return 0, nil, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, nil, err
+		}
+		n.line++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		doc, perr := parseDocument(data)
+		if perr != nil {
+			return n.line, nil, &rowError{row: n.line, err: perr}
+		}
+		return n.line, doc, nil
+	}
+}
+
+// jsonReader reads either a top-level array of documents, as the JSON export
+// writes, or documents one after another. A document that is not an object
+// only rejects that document; broken JSON ends the import, as there is no
+// telling where the next document starts.
+type jsonReader struct {
+	dec     *json.Decoder
+	inArray bool
+	done    bool
+	index   int64
+}
+
+func newJSONReader(r *bufio.Reader) (*jsonReader, error) {
+	first, err := firstNonSpace(r)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	j := &jsonReader{dec: json.NewDecoder(r)}
+	if first == '[' {
+		if _, err := j.dec.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		j.inArray = true
+	}
+	return j, nil
+}
+
+// firstNonSpace returns the first byte that is not white space without
+// consuming it.
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = r.Discard(1)
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+func (j *jsonReader) Next() (int64, bson.D, error) {
+	if j.done {
+		return 0, nil, io.EOF
+	}
+	if j.inArray && !j.dec.More() {
+		j.done = true
+		if _, err := j.dec.Token(); err != nil {
+			return 0, nil, fmt.Errorf("invalid JSON after document %d: %w", j.index, err)
+		}
+		return 0, nil, io.EOF
+	}
+	var raw json.RawMessage
+	if err := j.dec.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) && !j.inArray {
+			j.done = true
+			return 0, nil, io.EOF
+		}
+		return 0, nil, fmt.Errorf("invalid JSON after document %d: %w", j.index, err)
+	}
+	j.index++
+	doc, err := parseDocument(raw)
+	if err != nil {
+		return j.index, nil, &rowError{row: j.index, err: err}
+	}
+	return j.index, doc, nil
+}
+
+// csvReader turns each record into a document through the column mapping.
+type csvReader struct {
+	r       *csv.Reader
+	headers []string
+	columns []models.ImportColumn
+	blanks  bool
+	// pending is the first record of a file without a header row, read to
+	// count its columns.
+	pending []string
+}
+
+func newCSVReader(r io.Reader, opts models.ImportCSVOptions) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	if opts.Separator != "" {
+		sep, _ := utf8.DecodeRuneInString(opts.Separator)
+		cr.Comma = sep
+	}
+
+	c := &csvReader{r: cr, blanks: opts.IgnoreBlanks}
+	first, err := cr.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error reading first row: %w", err)
+	}
+	if opts.HasHeader {
+		c.headers = first
+	} else {
+		for i := range first {
+			c.headers = append(c.headers, "field"+strconv.Itoa(i+1))
+		}
+		c.pending = first
+	}
+
+	c.columns = opts.Columns
+	if len(c.columns) == 0 {
+		for _, h := range c.headers {
+			c.columns = append(c.columns, models.ImportColumn{Field: h, Type: models.ImportTypeAuto})
+		}
+	}
+	return c, nil
+}
+
+func (c *csvReader) Headers() []string {
+	return c.headers
+}
+
+func (c *csvReader) Next() (int64, bson.D, error) {
+	var (
+		record []string
+		err    error
+	)
+	if c.pending != nil {
+		record, c.pending = c.pending, nil
+	} else {
+		record, err = c.r.Read()
+	}
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return int64(perr.Line), nil, &rowError{row: int64(perr.Line), err: perr.Err}
+		}
+		return 0, nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+	row := int64(line)
+
+	doc, err := c.document(record)
+	if err != nil {
+		return row, nil, &rowError{row: row, err: err}
+	}
+	return row, doc, nil
+}
+
+func (c *csvReader) document(record []string) (bson.D, error) {
+	if len(record) > len(c.columns) {
+		return nil, fmt.Errorf("has %d columns, expected at most %d", len(record), len(c.columns))
+	}
+	doc := bson.D{}
+	for i, cell := range record {
+		col := c.columns[i]
+		if col.Field == "" || col.Type == models.ImportTypeSkip {
+			continue
+		}
+		if cell == "" && c.blanks {
+			continue
+		}
+		v, err := convertCell(cell, col.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", col.Field, err)
+		}
+		if err := setPath(&doc, col.Field, v); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
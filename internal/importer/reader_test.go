@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"vervet/internal/export"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type readResult struct {
+	docs   []bson.D
+	rows   []int64
+	errors []*rowError
+}
+
+func readAll(t *testing.T, format, input string, opts models.ImportCSVOptions) readResult {
+	t.Helper()
+	r, err := newReader(format, strings.NewReader(input), opts)
+	require.NoError(t, err)
+	var res readResult
+	for {
+		row, doc, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+		var rerr *rowError
+		if errors.As(err, &rerr) {
+			res.errors = append(res.errors, rerr)
+			continue
+		}
+		require.NoError(t, err)
+		res.docs = append(res.docs, doc)
+		res.rows = append(res.rows, row)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := "{\"a\":1}\n\n{\"b\":{\"$numberLong\":\"2\"}}\nnot json\n{\"c\":3}"
+
+	res := readAll(t, models.ImportNDJSON, input, models.ImportCSVOptions{})
+
+	require.Len(t, res.docs, 3)
+	assert.Equal(t, bson.D{{Key: "b", Value: int64(2)}}, res.docs[1])
+	assert.Equal(t, []int64{1, 3, 5}, res.rows)
+	require.Len(t, res.errors, 1)
+	assert.Equal(t, int64(4), res.errors[0].row)
+}
+
+func TestJSONReader(t *testing.T) {
+	t.Run("array", func(t *testing.T) {
+		res := readAll(t, models.ImportJSON, "\ufeff [{\"a\":1}, 5, {\"b\":2}]", models.ImportCSVOptions{})
+		require.Len(t, res.docs, 2)
+		assert.Equal(t, []int64{1, 3}, res.rows)
+		require.Len(t, res.errors, 1)
+		assert.Equal(t, int64(2), res.errors[0].row)
+	})
+
+	t.Run("concatenated documents", func(t *testing.T) {
+		res := readAll(t, models.ImportJSON, "{\"a\":1}\n{\"a\":2}\n", models.ImportCSVOptions{})
+		assert.Len(t, res.docs, 2)
+	})
+
+	t.Run("empty array", func(t *testing.T) {
+		res := readAll(t, models.ImportJSON, "[]", models.ImportCSVOptions{})
+		assert.Empty(t, res.docs)
+	})
+
+	t.Run("broken json stops", func(t *testing.T) {
+		r, err := newReader(models.ImportJSON, strings.NewReader(`[{"a":1}, {"a":`), models.ImportCSVOptions{})
+		require.NoError(t, err)
+		_, _, err = r.Next()
+		require.NoError(t, err)
+		_, _, err = r.Next()
+		var rerr *rowError
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &rerr))
+	})
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Run("header names fields", func(t *testing.T) {
+		input := "name,address.city,age,active\nAda,London,36,true\n"
+		res := readAll(t, models.ImportCSV, input, models.ImportCSVOptions{HasHeader: true})
+
+		require.Len(t, res.docs, 1)
+		assert.Equal(t, bson.D{
+			{Key: "name", Value: "Ada"},
+			{Key: "address", Value: bson.D{{Key: "city", Value: "London"}}},
+			{Key: "age", Value: int32(36)},
+			{Key: "active", Value: true},
+		}, res.docs[0])
+		assert.Equal(t, []int64{2}, res.rows)
+	})
+
+	t.Run("column mapping and types", func(t *testing.T) {
+		input := "zip;when;ignored\n007;2026-03-01;x\n"
+		res := readAll(t, models.ImportCSV, input, models.ImportCSVOptions{
+			Separator: ";",
+			HasHeader: true,
+			Columns: []models.ImportColumn{
+				{Field: "postcode", Type: models.ImportTypeNumber},
+				{Field: "meta.created", Type: models.ImportTypeDate},
+				{Field: "", Type: models.ImportTypeAuto},
+			},
+		})
+
+		require.Len(t, res.docs, 1)
+		doc := res.docs[0]
+		require.Len(t, doc, 2)
+		assert.Equal(t, int32(7), doc[0].Value)
+		assert.IsType(t, bson.D{}, doc[1].Value)
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		r, err := newCSVReader(strings.NewReader("a,1\nb,2\n"), models.ImportCSVOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"field1", "field2"}, r.Headers())
+
+		res := readAll(t, models.ImportCSV, "a,1\nb,2\n", models.ImportCSVOptions{})
+		require.Len(t, res.docs, 2)
+		assert.Equal(t, bson.D{{Key: "field1", Value: "a"}, {Key: "field2", Value: int32(1)}}, res.docs[0])
+		assert.Equal(t, []int64{1, 2}, res.rows)
+	})
+
+	t.Run("reads back an indexed array export", func(t *testing.T) {
+		doc := bson.M{
+			"name":  "Ada",
+			"tags":  bson.A{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"},
+			"items": bson.A{bson.M{"sku": "X1", "qty": int32(2)}, bson.M{"sku": "X2", "qty": int32(1)}},
+		}
+		out, err := export.Serialize([]bson.M{doc}, export.Options{
+			Format: export.FormatCSV,
+			CSV:    export.CSVOptions{IncludeHeader: true, Arrays: export.ArrayIndexed},
+		})
+		require.NoError(t, err)
+
+		res := readAll(t, models.ImportCSV, string(out), models.ImportCSVOptions{HasHeader: true})
+		require.Len(t, res.docs, 1)
+		got := res.docs[0]
+		items, err := lookupPath(got, "items")
+		require.NoError(t, err)
+		assert.Equal(t, bson.A{
+			bson.D{{Key: "qty", Value: int32(2)}, {Key: "sku", Value: "X1"}},
+			bson.D{{Key: "qty", Value: int32(1)}, {Key: "sku", Value: "X2"}},
+		}, items)
+		tags, err := lookupPath(got, "tags")
+		require.NoError(t, err)
+		assert.Equal(t, bson.A{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}, tags, "tags.10 comes before tags.2 in the header")
+	})
+
+	t.Run("blank cells", func(t *testing.T) {
+		input := "a,b\n,x\n"
+		kept := readAll(t, models.ImportCSV, input, models.ImportCSVOptions{HasHeader: true})
+		assert.Equal(t, bson.D{{Key: "a", Value: ""}, {Key: "b", Value: "x"}}, kept.docs[0])
+
+		dropped := readAll(t, models.ImportCSV, input, models.ImportCSVOptions{HasHeader: true, IgnoreBlanks: true})
+		assert.Equal(t, bson.D{{Key: "b", Value: "x"}}, dropped.docs[0])
+	})
+
+	t.Run("rejected rows", func(t *testing.T) {
+		input := "a,b\n1,2\n1,2,3\n\"open,1\n"
+		res := readAll(t, models.ImportCSV, input, models.ImportCSVOptions{
+			HasHeader: true,
+			Columns: []models.ImportColumn{
+				{Field: "a", Type: models.ImportTypeNumber},
+				{Field: "b", Type: models.ImportTypeNumber},
+			},
+		})
+		require.Len(t, res.docs, 1)
+		require.Len(t, res.errors, 2)
+		assert.Equal(t, int64(3), res.errors[0].row)
+		assert.Contains(t, res.errors[0].Error(), "3 columns")
+	})
+
+	t.Run("type error names the column", func(t *testing.T) {
+		res := readAll(t, models.ImportCSV, "n\nabc\n", models.ImportCSVOptions{
+			HasHeader: true,
+			Columns:   []models.ImportColumn{{Field: "n", Type: models.ImportTypeNumber}},
+		})
+		require.Len(t, res.errors, 1)
+		assert.Contains(t, res.errors[0].Error(), `column "n"`)
+	})
+}
@@ -0,0 +1,425 @@
+// Package importer reads CSV, JSON and NDJSON files into a collection.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"vervet/internal/audit"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+	"vervet/internal/mongowrite"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// ImportProgressEvent carries a models.ImportProgress from a running
+	// import.
+	ImportProgressEvent = "import-progress"
+
+	// DefaultBatchSize is how many documents each write sends when the
+	// request does not say.
+	DefaultBatchSize = 1000
+	// MaxImportErrors is how many rejected rows an import reports. The rest
+	// are only counted.
+	MaxImportErrors = 1000
+	// DefaultPreviewSize is how many documents a preview parses when the
+	// caller does not say.
+	DefaultPreviewSize = 20
+)
+
+// progressInterval is the least time between progress events for one import.
+const progressInterval = 100 * time.Millisecond
+
+// ClientProvider provides access to active MongoDB connections
+type ClientProvider interface {
+	GetClient(serverID string) (*mongo.Client, error)
+}
+
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
+// Service imports files into collections.
+type Service struct {
+	mu      sync.Mutex
+	log     *slog.Logger
+	ctx     context.Context
+	clients ClientProvider
+	auditor Auditor
+	cancels map[string]context.CancelFunc // importID -> cancel for a running import
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
+}
+
+func NewService(log *slog.Logger, clients ClientProvider) *Service {
+	return &Service{
+		log:     log.With(slog.String(logging.SourceKey, "ImportService")),
+		clients: clients,
+		cancels: make(map[string]context.CancelFunc),
+		emit:    runtime.EventsEmit,
+	}
+}
+
+// Init stores the Wails application context, used as the parent for imports.
+func (s *Service) Init(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// SetAuditor makes the service audit every import it runs.
+func (s *Service) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
+// Preview parses the first limit documents of the file the way Import would,
+// without writing anything.
+func (s *Service) Preview(req models.ImportRequest, limit int) (models.ImportPreview, error) {
+	if limit <= 0 {
+		limit = DefaultPreviewSize
+	}
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return models.ImportPreview{}, fmt.Errorf("error opening import file: %w", err)
+	}
+	defer f.Close()
+
+	reader, err := newReader(req.Format, f, req.CSV)
+	if err != nil {
+		return models.ImportPreview{}, err
+	}
+
+	preview := models.ImportPreview{Documents: []string{}, Errors: []models.ImportRowError{}}
+	if c, ok := reader.(*csvReader); ok {
+		preview.Headers = c.Headers()
+	}
+	for len(preview.Documents)+len(preview.Errors) < limit {
+		_, doc, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rerr *rowError
+		if errors.As(err, &rerr) {
+			preview.Errors = append(preview.Errors, models.ImportRowError{Row: rerr.row, Error: rerr.err.Error()})
+			continue
+		}
+		if err != nil {
+			return models.ImportPreview{}, err
+		}
+		data, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return models.ImportPreview{}, fmt.Errorf("error formatting document: %w", err)
+		}
+		preview.Documents = append(preview.Documents, string(data))
+	}
+	return preview, nil
+}
+
+// Import streams the file into the collection in batches. Rows that cannot
+// be read or written are reported in the result and do not stop the import;
+// an error means the file or the server failed and the import stopped part
+// way. Rows written before then stay written.
+func (s *Service) Import(req models.ImportRequest) (models.ImportResult, error) {
+	if err := validate(req); err != nil {
+		return models.ImportResult{}, err
+	}
+	client, err := s.clients.GetClient(req.ServerID)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("error opening import file: %w", err)
+	}
+	defer f.Close()
+	var total int64
+	if info, err := f.Stat(); err == nil {
+		total = info.Size()
+	}
+	counter := &countingReader{r: f}
+
+	reader, err := newReader(req.Format, counter, req.CSV)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.register(req.ImportID, cancel)
+	defer s.unregister(req.ImportID)
+
+	r := &run{
+		svc:       s,
+		ctx:       ctx,
+		req:       req,
+		coll:      client.Database(req.Database).Collection(req.Collection),
+		counter:   counter,
+		total:     total,
+		batchSize: req.BatchSize,
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = DefaultBatchSize
+	}
+	r.result.Errors = []models.ImportRowError{}
+
+	err = r.readAll(reader)
+	if err == nil {
+		err = r.flush()
+	}
+	if err != nil && ctx.Err() != nil {
+		r.result.Cancelled = true
+		err = nil
+	}
+	r.sendProgress(true)
+
+	s.audit(req, r.result, err)
+	if err != nil {
+		return r.result, fmt.Errorf("import stopped after %d rows: %w", r.result.Rows, err)
+	}
+	return r.result, nil
+}
+
+// Cancel stops a running import after the write in flight.
+func (s *Service) Cancel(importID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[importID]; ok {
+		cancel()
+	}
+}
+
+func (s *Service) register(importID string, cancel context.CancelFunc) {
+	if importID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[importID] = cancel
+}
+
+func (s *Service) unregister(importID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, importID)
+}
+
+// audit records the import as one change.
+func (s *Service) audit(req models.ImportRequest, result models.ImportResult, err error) {
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+		ServerID:      req.ServerID,
+		Database:      req.Database,
+		Collection:    req.Collection,
+		Operation:     "import",
+		Source:        models.AuditSourceApp,
+		Target:        req.Path,
+		AffectedCount: result.Inserted + result.Updated,
+	}, err)
+}
+
+func validate(req models.ImportRequest) error {
+	if req.ServerID == "" || req.Database == "" || req.Collection == "" {
+		return errors.New("an import needs a server, database and collection")
+	}
+	if req.Path == "" {
+		return errors.New("no file chosen to import")
+	}
+	switch req.Mode {
+	case "", models.ImportInsert:
+	case models.ImportUpsert:
+		if len(req.UpsertKeys) == 0 {
+			return errors.New("upsert mode needs at least one key field")
+		}
+	default:
+		return fmt.Errorf("unsupported import mode: %q", req.Mode)
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from the file for progress. The
+// readers buffer ahead, so it runs a little in front of the rows imported.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// run is the state of one import.
+type run struct {
+	svc       *Service
+	ctx       context.Context
+	req       models.ImportRequest
+	coll      *mongo.Collection
+	counter   *countingReader
+	total     int64
+	batchSize int
+
+	batch    []bson.D
+	rows     []int64 // the file row of each document in batch
+	result   models.ImportResult
+	lastSent time.Time
+}
+
+func (r *run) readAll(reader documentReader) error {
+	for {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		row, doc, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rerr *rowError
+		if err != nil && !errors.As(err, &rerr) {
+			return err
+		}
+		r.result.Rows++
+		if rerr != nil {
+			r.reject(rerr.row, rerr.err.Error())
+			continue
+		}
+
+		if r.req.Mode == models.ImportUpsert {
+			if missing := missingKeys(doc, r.req.UpsertKeys); len(missing) > 0 {
+				r.reject(row, "missing upsert key "+strings.Join(missing, ", "))
+				continue
+			}
+		}
+		r.batch = append(r.batch, doc)
+		r.rows = append(r.rows, row)
+		if len(r.batch) >= r.batchSize {
+			if err := r.flush(); err != nil {
+				return err
+			}
+		}
+		r.sendProgress(false)
+	}
+}
+
+func (r *run) reject(row int64, message string) {
+	r.result.Rejected++
+	if len(r.result.Errors) >= MaxImportErrors {
+		r.result.ErrorsTruncated = true
+		return
+	}
+	r.result.Errors = append(r.result.Errors, models.ImportRowError{Row: row, Error: message})
+}
+
+// flush writes the batch. Documents the server rejects are reported against
+// their rows; any other failure stops the import.
+func (r *run) flush() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	batch, rows := r.batch, r.rows
+	r.batch, r.rows = nil, nil
+
+	var (
+		failed []mongo.BulkWriteError
+		err    error
+	)
+	if r.req.Mode == models.ImportUpsert {
+		failed, err = r.upsert(batch)
+	} else {
+		failed, err = r.insert(batch)
+	}
+	if err != nil {
+		return err
+	}
+	for _, we := range failed {
+		row := int64(0)
+		if we.Index >= 0 && we.Index < len(rows) {
+			row = rows[we.Index]
+		}
+		r.reject(row, we.Message)
+	}
+	r.sendProgress(false)
+	return nil
+}
+
+func (r *run) insert(batch []bson.D) ([]mongo.BulkWriteError, error) {
+	docs := make([]any, len(batch))
+	for i, d := range batch {
+		docs[i] = d
+	}
+	_, err := r.coll.InsertMany(r.ctx, docs, options.InsertMany().SetOrdered(false))
+	failed, err := mongowrite.WriteErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	r.result.Inserted += int64(len(batch) - len(failed))
+	return failed, nil
+}
+
+func (r *run) upsert(batch []bson.D) ([]mongo.BulkWriteError, error) {
+	writes := make([]mongo.WriteModel, len(batch))
+	for i, doc := range batch {
+		filter := bson.D{}
+		for _, key := range r.req.UpsertKeys {
+			v, _ := lookupPath(doc, key)
+			filter = append(filter, bson.E{Key: key, Value: v})
+		}
+		writes[i] = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true)
+	}
+	res, err := r.coll.BulkWrite(r.ctx, writes, options.BulkWrite().SetOrdered(false))
+	failed, err := mongowrite.WriteErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	if res != nil {
+		r.result.Inserted += res.UpsertedCount
+		r.result.Updated += res.ModifiedCount
+	}
+	return failed, nil
+}
+
+func missingKeys(doc bson.D, keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, err := lookupPath(doc, key); err != nil {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// sendProgress emits a progress event, dropping those that come sooner than
+// progressInterval after the last unless final.
+func (r *run) sendProgress(final bool) {
+	if r.req.ImportID == "" {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(r.lastSent) < progressInterval {
+		return
+	}
+	r.lastSent = now
+	r.svc.emit(r.svc.ctx, ImportProgressEvent, models.ImportProgress{
+		ImportID:   r.req.ImportID,
+		Rows:       r.result.Rows,
+		Inserted:   r.result.Inserted,
+		Updated:    r.result.Updated,
+		Rejected:   r.result.Rejected,
+		BytesRead:  r.counter.n,
+		TotalBytes: r.total,
+	})
+}
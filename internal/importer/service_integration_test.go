@@ -0,0 +1,209 @@
+//go:build integration
+
+package importer
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"vervet/internal/models"
+)
+
+var testClient *mongo.Client
+
+type stubProvider struct {
+	client *mongo.Client
+}
+
+func (s stubProvider) GetClient(string) (*mongo.Client, error) {
+	return s.client, nil
+}
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (r *recordingAuditor) Record(action models.AuditAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	os.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
+
+	container, err := mongodb.Run(ctx, "mongo:7")
+	if err != nil {
+		log.Fatalf("start container: %v", err)
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			log.Printf("terminate: %v", err)
+		}
+	}()
+
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		log.Fatalf("conn string: %v", err)
+	}
+
+	testClient, err = mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer testClient.Disconnect(ctx)
+
+	os.Exit(m.Run())
+}
+
+func newService(t *testing.T) (*Service, *[]models.ImportProgress) {
+	t.Helper()
+	svc := NewService(slog.Default(), stubProvider{client: testClient})
+	svc.Init(context.Background())
+	var events []models.ImportProgress
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events = append(events, data[0].(models.ImportProgress))
+	}
+	return svc, &events
+}
+
+func TestIntegration_Import_CSVInBatches(t *testing.T) {
+	ctx := context.Background()
+	db := "import_csv"
+	t.Cleanup(func() { testClient.Database(db).Drop(ctx) })
+
+	var b strings.Builder
+	b.WriteString("_id,name,address.city\n")
+	for range 25 {
+		b.WriteString(bson.NewObjectID().Hex() + ",user,London\n")
+	}
+	b.WriteString("not-an-id,user,Paris\n")
+	path := writeFile(t, "people.csv", b.String())
+
+	svc, events := newService(t)
+	auditor := &recordingAuditor{}
+	svc.SetAuditor(auditor)
+
+	result, err := svc.Import(models.ImportRequest{
+		ImportID:   "imp-1",
+		ServerID:   "srv",
+		Database:   db,
+		Collection: "people",
+		Path:       path,
+		Format:     models.ImportCSV,
+		CSV: models.ImportCSVOptions{
+			HasHeader: true,
+			Columns: []models.ImportColumn{
+				{Field: "_id", Type: models.ImportTypeObjectID},
+				{Field: "name", Type: models.ImportTypeString},
+				{Field: "address.city", Type: models.ImportTypeAuto},
+			},
+		},
+		BatchSize: 10,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(26), result.Rows)
+	assert.Equal(t, int64(25), result.Inserted)
+	assert.Equal(t, int64(1), result.Rejected)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, int64(27), result.Errors[0].Row)
+
+	var doc bson.M
+	require.NoError(t, testClient.Database(db).Collection("people").FindOne(ctx, bson.M{}).Decode(&doc))
+	assert.Equal(t, bson.M{"city": "London"}, doc["address"])
+
+	require.NotEmpty(t, *events)
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, int64(25), last.Inserted)
+	assert.Equal(t, last.TotalBytes, last.BytesRead)
+
+	require.Len(t, auditor.actions, 1)
+	assert.Equal(t, "import", auditor.actions[0].Operation)
+	assert.Equal(t, int64(25), auditor.actions[0].AffectedCount)
+}
+
+func TestIntegration_Import_DuplicateKeysAreRejectedRows(t *testing.T) {
+	ctx := context.Background()
+	db := "import_dupes"
+	t.Cleanup(func() { testClient.Database(db).Drop(ctx) })
+
+	path := writeFile(t, "data.ndjson", "{\"_id\":1}\n{\"_id\":2}\n{\"_id\":1}\n{\"_id\":3}\n")
+	svc, _ := newService(t)
+
+	result, err := svc.Import(models.ImportRequest{
+		ServerID: "srv", Database: db, Collection: "c", Path: path, Format: models.ImportNDJSON,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), result.Inserted)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, int64(3), result.Errors[0].Row)
+	assert.Contains(t, result.Errors[0].Error, "duplicate key")
+}
+
+func TestIntegration_Import_Upsert(t *testing.T) {
+	ctx := context.Background()
+	db := "import_upsert"
+	t.Cleanup(func() { testClient.Database(db).Drop(ctx) })
+	coll := testClient.Database(db).Collection("skus")
+	_, err := coll.InsertMany(ctx, []any{
+		bson.D{{Key: "sku", Value: "A1"}, {Key: "qty", Value: 1}},
+		bson.D{{Key: "sku", Value: "C3"}, {Key: "qty", Value: 2}},
+	})
+	require.NoError(t, err)
+
+	// C3 matches but is unchanged, so it is not counted as updated.
+	path := writeFile(t, "skus.json", `[{"sku":"A1","qty":5},{"sku":"B2","qty":7},{"qty":9},{"sku":"C3","qty":2}]`)
+	svc, _ := newService(t)
+
+	result, err := svc.Import(models.ImportRequest{
+		ServerID: "srv", Database: db, Collection: "skus", Path: path, Format: models.ImportJSON,
+		Mode: models.ImportUpsert, UpsertKeys: []string{"sku"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), result.Updated)
+	assert.Equal(t, int64(1), result.Inserted)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Error, "missing upsert key sku")
+
+	n, err := coll.CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	var a1 bson.M
+	require.NoError(t, coll.FindOne(ctx, bson.M{"sku": "A1"}).Decode(&a1))
+	assert.EqualValues(t, 5, a1["qty"])
+}
+
+func TestIntegration_Import_Cancel(t *testing.T) {
+	ctx := context.Background()
+	db := "import_cancel"
+	t.Cleanup(func() { testClient.Database(db).Drop(ctx) })
+
+	path := writeFile(t, "data.ndjson", strings.Repeat("{\"a\":1}\n", 100))
+	svc, _ := newService(t)
+	svc.emit = func(_ context.Context, _ string, _ ...interface{}) {
+		svc.Cancel("imp-cancel")
+	}
+
+	result, err := svc.Import(models.ImportRequest{
+		ImportID: "imp-cancel", ServerID: "srv", Database: db, Collection: "c", Path: path,
+		Format: models.ImportNDJSON, BatchSize: 10,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.Less(t, result.Inserted, int64(100))
+}
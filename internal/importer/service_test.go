@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestPreview(t *testing.T) {
+	svc := NewService(slog.Default(), nil)
+
+	t.Run("csv", func(t *testing.T) {
+		path := writeFile(t, "people.csv", "name,address.city\nAda,London\nAlan,Wilmslow\nGrace,Arlington\n")
+
+		preview, err := svc.Preview(models.ImportRequest{Path: path, Format: models.ImportCSV, CSV: models.ImportCSVOptions{HasHeader: true}}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "address.city"}, preview.Headers)
+		assert.Equal(t, []string{
+			`{"name":"Ada","address":{"city":"London"}}`,
+			`{"name":"Alan","address":{"city":"Wilmslow"}}`,
+		}, preview.Documents)
+		assert.Empty(t, preview.Errors)
+	})
+
+	t.Run("ndjson with errors", func(t *testing.T) {
+		path := writeFile(t, "data.ndjson", "{\"a\":1}\nbad\n")
+
+		preview, err := svc.Preview(models.ImportRequest{Path: path, Format: models.ImportNDJSON}, 0)
+		require.NoError(t, err)
+		assert.Nil(t, preview.Headers)
+		assert.Len(t, preview.Documents, 1)
+		require.Len(t, preview.Errors, 1)
+		assert.Equal(t, int64(2), preview.Errors[0].Row)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := svc.Preview(models.ImportRequest{Path: filepath.Join(t.TempDir(), "nope.csv"), Format: models.ImportCSV}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		path := writeFile(t, "data.xml", "<a/>")
+		_, err := svc.Preview(models.ImportRequest{Path: path, Format: "xml"}, 0)
+		assert.ErrorContains(t, err, "unsupported import format")
+	})
+}
+
+func TestImport_Validation(t *testing.T) {
+	svc := NewService(slog.Default(), nil)
+	base := models.ImportRequest{ServerID: "srv", Database: "db", Collection: "c", Path: "/tmp/x.csv", Format: models.ImportCSV}
+
+	noTarget := base
+	noTarget.Collection = ""
+	_, err := svc.Import(noTarget)
+	assert.Error(t, err)
+
+	noKeys := base
+	noKeys.Mode = models.ImportUpsert
+	_, err = svc.Import(noKeys)
+	assert.ErrorContains(t, err, "key field")
+
+	badMode := base
+	badMode.Mode = "merge"
+	_, err = svc.Import(badMode)
+	assert.ErrorContains(t, err, "unsupported import mode")
+}
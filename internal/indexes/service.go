@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"vervet/internal/audit"
 	"vervet/internal/logging"
 	"vervet/internal/models"
 
@@ -33,11 +34,18 @@ type indexStat struct {
 	} `bson:"accesses"`
 }
 
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
 // IndexService handles CRUD operations for MongoDB collection indexes
 type IndexService struct {
 	ctx     context.Context
 	log     *slog.Logger
 	clients ClientProvider
+	auditor Auditor
 }
 
 func NewIndexService(log *slog.Logger, clients ClientProvider) *IndexService {
@@ -51,6 +59,11 @@ func (s *IndexService) Init(ctx context.Context) {
 	s.ctx = ctx
 }
 
+// SetAuditor makes the service audit every index it creates, edits or drops.
+func (s *IndexService) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
 func (s *IndexService) GetIndexes(serverID, dbName, collectionName string) ([]models.Index, error) {
 	client, err := s.clients.GetClient(serverID)
 	if err != nil {
@@ -130,11 +143,22 @@ func (s *IndexService) CreateIndex(serverID, dbName, collectionName string, requ
 
 	collection := client.Database(dbName).Collection(collectionName)
 	name, err := collection.Indexes().CreateOne(s.ctx, model)
+	if name == "" {
+		name = request.Name
+	}
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+		ServerID:   serverID,
+		Database:   dbName,
+		Collection: collectionName,
+		Operation:  "createIndex",
+		Source:     models.AuditSourceApp,
+		Target:     name,
+		Arguments:  indexArguments(model, request.Name, request.Unique, request.Sparse, request.TTL),
+	}, err)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	_ = name // suppress unused variable warning
 	return nil
 }
 
@@ -146,6 +170,18 @@ func (s *IndexService) EditIndex(serverID, dbName, collectionName string, reques
 
 	collection := client.Database(dbName).Collection(collectionName)
 	newModel := s.buildIndexModel(request.Keys, request.Name, request.Unique, request.Sparse, request.TTL)
+	// An edit is one change to the log, whichever of its two steps fails.
+	defer func() {
+		audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+			ServerID:   serverID,
+			Database:   dbName,
+			Collection: collectionName,
+			Operation:  "editIndex",
+			Source:     models.AuditSourceApp,
+			Target:     request.OldName,
+			Arguments:  indexArguments(newModel, request.Name, request.Unique, request.Sparse, request.TTL),
+		}, err)
+	}()
 
 	sameNameEdit := request.Name == "" || request.Name == request.OldName
 
@@ -190,6 +226,14 @@ func (s *IndexService) DropIndex(serverID, dbName, collectionName, indexName str
 
 	collection := client.Database(dbName).Collection(collectionName)
 	err = collection.Indexes().DropOne(s.ctx, indexName)
+	audit.RecordOrLog(s.log, s.auditor, models.AuditAction{
+		ServerID:   serverID,
+		Database:   dbName,
+		Collection: collectionName,
+		Operation:  "dropIndex",
+		Source:     models.AuditSourceApp,
+		Target:     indexName,
+	}, err)
 	if err != nil {
 		return fmt.Errorf("failed to drop index: %w", err)
 	}
@@ -228,6 +272,25 @@ func (s *IndexService) buildIndexModel(keys []models.IndexKeyField, name string,
 	}
 }
 
+// indexArguments is how an index change appears in the audit log: the key
+// pattern and options, as createIndex takes them.
+func indexArguments(model mongo.IndexModel, name string, unique, sparse bool, ttl *int32) bson.A {
+	opts := bson.D{}
+	if name != "" {
+		opts = append(opts, bson.E{Key: "name", Value: name})
+	}
+	if unique {
+		opts = append(opts, bson.E{Key: "unique", Value: true})
+	}
+	if sparse {
+		opts = append(opts, bson.E{Key: "sparse", Value: true})
+	}
+	if ttl != nil {
+		opts = append(opts, bson.E{Key: "expireAfterSeconds", Value: *ttl})
+	}
+	return bson.A{model.Keys, opts}
+}
+
 // indexSizesFrom normalises the collStats "indexSizes" sub-document. The driver
 // decodes nested documents as bson.D even when the parent decodes into bson.M,
 // so accept both rather than silently reporting every index as zero bytes.
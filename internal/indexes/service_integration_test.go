@@ -281,3 +281,40 @@ func TestIntegration_GetIndexes_PropagatesProviderError(t *testing.T) {
 	_, err := svc.GetIndexes("srv", "any", "c")
 	assert.ErrorIs(t, err, assert.AnError)
 }
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (a *recordingAuditor) Record(action models.AuditAction) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func TestIntegration_IndexChanges_AreAudited(t *testing.T) {
+	db := seedIdx(t, "idx_audited")
+	auditor := &recordingAuditor{}
+	svc := newService(t)
+	svc.SetAuditor(auditor)
+
+	require.NoError(t, svc.CreateIndex("srv", db, "c", models.CreateIndexRequest{
+		Keys: []models.IndexKeyField{{Field: "email", Direction: 1}}, Unique: true,
+	}))
+	require.NoError(t, svc.EditIndex("srv", db, "c", models.EditIndexRequest{
+		OldName: "email_1", Name: "by_email", Keys: []models.IndexKeyField{{Field: "email", Direction: 1}},
+	}))
+	require.NoError(t, svc.DropIndex("srv", db, "c", "by_email"))
+
+	require.Len(t, auditor.actions, 3)
+	create := auditor.actions[0]
+	assert.Equal(t, "createIndex", create.Operation)
+	assert.Equal(t, "email_1", create.Target, "the name the server gave the index")
+	assert.Equal(t, bson.A{
+		bson.D{{Key: "email", Value: 1}},
+		bson.D{{Key: "unique", Value: true}},
+	}, create.Arguments)
+	assert.Equal(t, "editIndex", auditor.actions[1].Operation)
+	assert.Equal(t, "email_1", auditor.actions[1].Target)
+	assert.Equal(t, "dropIndex", auditor.actions[2].Operation)
+	assert.Equal(t, "by_email", auditor.actions[2].Target)
+}
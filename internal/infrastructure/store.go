@@ -96,6 +96,17 @@ func (s *cfgStore) Save(data []byte) error {
 	return nil
 }
 
+// ConfigPath returns the path of filename in the app's configuration
+// directory, creating the directory if needed. It is for files a Store does
+// not suit, such as ones that are only ever appended to.
+func ConfigPath(filename string) (string, error) {
+	configDir, err := getConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, filename), nil
+}
+
 func getConfigDirectory() (string, error) {
 
 	configHome, err := os.UserConfigDir()
@@ -0,0 +1,56 @@
+package masking
+
+import (
+	"fmt"
+	"strings"
+
+	"vervet/internal/models"
+)
+
+var (
+	firstNames = []string{
+		"Alex", "Amara", "Ben", "Chloe", "Dev", "Ella", "Finn", "Grace", "Hamza", "Isla",
+		"Jack", "Kai", "Leah", "Mason", "Nadia", "Oscar", "Priya", "Quinn", "Rosa", "Sam",
+		"Tara", "Umar", "Vera", "Will", "Yara", "Zoe",
+	}
+	lastNames = []string{
+		"Adams", "Baker", "Clarke", "Davies", "Evans", "Fisher", "Green", "Hughes", "Iqbal", "Jones",
+		"Khan", "Lewis", "Morgan", "Nowak", "Owen", "Patel", "Quinn", "Roberts", "Shah", "Turner",
+		"Usman", "Vaughan", "Walker", "Young",
+	}
+	streets = []string{
+		"Acacia", "Birch", "Chapel", "Church", "Elm", "High", "Kings", "Mill", "Park", "Queens",
+		"Station", "Victoria", "Willow",
+	}
+	streetKinds = []string{"Road", "Street", "Lane", "Avenue", "Close", "Way"}
+	towns       = []string{
+		"Ashford", "Bramley", "Carlton", "Dunmore", "Eastwick", "Fairford", "Glenbrook", "Hartley",
+		"Kingsbury", "Lowfield", "Millbrook", "Northam", "Oakham", "Redhill", "Westbury",
+	}
+)
+
+// Fake makes up a value of kind from n. The same n makes the same value.
+// Emails use example.com and phone numbers the range Ofcom keeps for
+// drama, so neither reaches anyone real.
+func Fake(kind string, n uint64) string {
+	pick := func(list []string) string {
+		s := list[n%uint64(len(list))]
+		n /= uint64(len(list))
+		return s
+	}
+	switch kind {
+	case models.FakeName:
+		return pick(firstNames) + " " + pick(lastNames)
+	case models.FakeEmail:
+		first, last := pick(firstNames), pick(lastNames)
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), n%1000)
+	case models.FakePhone:
+		return fmt.Sprintf("07700 900%03d", n%1000)
+	case models.FakeAddress:
+		number := n%200 + 1
+		n /= 200
+		return fmt.Sprintf("%d %s %s, %s", number, pick(streets), pick(streetKinds), pick(towns))
+	default:
+		return Redacted
+	}
+}
@@ -0,0 +1,167 @@
+// Package masking scrubs personal data from documents as they leave a
+// server: by hashing, redacting, shuffling or faking the values at chosen
+// paths, or moving dates, as a saved profile's rules say.
+package masking
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	mathrand "math/rand/v2"
+	"strings"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Masker applies one profile's rules.
+type Masker struct {
+	rules []rule
+	key   []byte
+	rng   *mathrand.Rand
+}
+
+type rule struct {
+	models.MaskingRule
+	keys []string
+}
+
+// New compiles a profile. A profile without a key gets a random one, so its
+// hashes, fakes and jitter only agree within what this Masker masks.
+func New(profile models.MaskingProfile) (*Masker, error) {
+	if err := Validate(profile); err != nil {
+		return nil, err
+	}
+	key := []byte(profile.Key)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("error generating a masking key: %w", err)
+		}
+	}
+	var seed [16]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("error seeding the shuffle: %w", err)
+	}
+	rng := mathrand.New(mathrand.NewPCG(binary.LittleEndian.Uint64(seed[:8]), binary.LittleEndian.Uint64(seed[8:])))
+	return newMasker(profile, key, rng), nil
+}
+
+func newMasker(profile models.MaskingProfile, key []byte, rng *mathrand.Rand) *Masker {
+	m := &Masker{key: key, rng: rng}
+	for _, r := range profile.Rules {
+		m.rules = append(m.rules, rule{MaskingRule: r, keys: strings.Split(r.Path, ".")})
+	}
+	return m
+}
+
+// Mask masks a batch of documents. Shuffle rules exchange values between
+// the documents of the batch, so bigger batches shuffle more thoroughly.
+func (m *Masker) Mask(docs []bson.Raw) ([]bson.Raw, error) {
+	decoded := make([]any, len(docs))
+	for i, raw := range docs {
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("error reading a document to mask: %w", err)
+		}
+		decoded[i] = doc
+	}
+	m.maskAll(decoded)
+	masked := make([]bson.Raw, len(docs))
+	for i, doc := range decoded {
+		b, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("error writing a masked document: %w", err)
+		}
+		masked[i] = b
+	}
+	return masked, nil
+}
+
+// MaskMaps masks documents in place, as Mask does.
+func (m *Masker) MaskMaps(docs []bson.M) {
+	decoded := make([]any, len(docs))
+	for i, doc := range docs {
+		decoded[i] = doc
+	}
+	m.maskAll(decoded)
+}
+
+func (m *Masker) maskAll(docs []any) {
+	for _, r := range m.rules {
+		switch r.Strategy {
+		case models.MaskKeep:
+		case models.MaskShuffle:
+			m.shuffle(docs, r.keys)
+		default:
+			fn := m.strategy(r.MaskingRule)
+			for i := range docs {
+				docs[i] = apply(docs[i], r.keys, fn)
+			}
+		}
+	}
+}
+
+// shuffle deals the values at keys back out to the documents in a random
+// order. Documents without the field stay without it.
+func (m *Masker) shuffle(docs []any, keys []string) {
+	var values []any
+	collect := func(v any) any {
+		values = append(values, v)
+		return v
+	}
+	for i := range docs {
+		docs[i] = apply(docs[i], keys, collect)
+	}
+	m.rng.Shuffle(len(values), func(i, j int) {
+		values[i], values[j] = values[j], values[i]
+	})
+	dealt := 0
+	deal := func(any) any {
+		v := values[dealt]
+		dealt++
+		return v
+	}
+	for i := range docs {
+		docs[i] = apply(docs[i], keys, deal)
+	}
+}
+
+// apply replaces every value at keys in v with what fn returns for it.
+// Arrays met on the way are looked through, so keys reach the field in each
+// of their documents, and an array at the end has each element replaced.
+func apply(v any, keys []string, fn func(any) any) any {
+	switch d := v.(type) {
+	case bson.D:
+		for i := range d {
+			if d[i].Key == keys[0] {
+				d[i].Value = replace(d[i].Value, keys, fn)
+			}
+		}
+	case bson.M:
+		if value, ok := d[keys[0]]; ok {
+			d[keys[0]] = replace(value, keys, fn)
+		}
+	case bson.A:
+		for i := range d {
+			d[i] = apply(d[i], keys, fn)
+		}
+	}
+	return v
+}
+
+// replace goes on from a field apply matched: deeper when keys go on,
+// otherwise replacing its value.
+func replace(v any, keys []string, fn func(any) any) any {
+	if len(keys) > 1 {
+		return apply(v, keys[1:], fn)
+	}
+	if a, ok := v.(bson.A); ok {
+		for i := range a {
+			a[i] = replace(a[i], keys, fn)
+		}
+		return a
+	}
+	return fn(v)
+}
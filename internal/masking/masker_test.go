@@ -0,0 +1,164 @@
+package masking
+
+import (
+	mathrand "math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func testMasker(t *testing.T, rules ...models.MaskingRule) *Masker {
+	t.Helper()
+	profile := models.MaskingProfile{Name: "test", Key: "secret", Rules: rules}
+	require.NoError(t, Validate(profile))
+	return newMasker(profile, []byte(profile.Key), mathrand.New(mathrand.NewPCG(1, 2)))
+}
+
+func maskOne(t *testing.T, m *Masker, doc bson.D) bson.D {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	masked, err := m.Mask([]bson.Raw{raw})
+	require.NoError(t, err)
+	var out bson.D
+	require.NoError(t, bson.Unmarshal(masked[0], &out))
+	return out
+}
+
+func TestMask_Hash(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "email", Strategy: models.MaskHash})
+	a := maskOne(t, m, bson.D{{Key: "email", Value: "ada@example.org"}, {Key: "n", Value: int32(1)}})
+	b := maskOne(t, m, bson.D{{Key: "email", Value: "ada@example.org"}})
+	c := maskOne(t, m, bson.D{{Key: "email", Value: "grace@example.org"}})
+
+	hashed := a[0].Value.(string)
+	assert.Len(t, hashed, hashLength)
+	assert.NotContains(t, hashed, "ada")
+	assert.Equal(t, hashed, b[0].Value, "the same value hashes the same way")
+	assert.NotEqual(t, hashed, c[0].Value)
+	assert.Equal(t, int32(1), a[1].Value, "other fields are left alone")
+
+	other := newMasker(models.MaskingProfile{Rules: []models.MaskingRule{m.rules[0].MaskingRule}}, []byte("other"), nil)
+	assert.NotEqual(t, hashed, maskOne(t, other, bson.D{{Key: "email", Value: "ada@example.org"}})[0].Value,
+		"a different key hashes differently")
+}
+
+func TestMask_RedactNestedAndArrays(t *testing.T) {
+	m := testMasker(t,
+		models.MaskingRule{Path: "contacts.phone", Strategy: models.MaskRedact},
+		models.MaskingRule{Path: "tags", Strategy: models.MaskRedact},
+	)
+	out := maskOne(t, m, bson.D{
+		{Key: "contacts", Value: bson.A{
+			bson.D{{Key: "phone", Value: "0123"}, {Key: "kind", Value: "home"}},
+			bson.D{{Key: "kind", Value: "work"}},
+		}},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "missing", Value: nil},
+	})
+
+	contacts := out[0].Value.(bson.A)
+	assert.Equal(t, bson.D{{Key: "phone", Value: Redacted}, {Key: "kind", Value: "home"}}, contacts[0])
+	assert.Equal(t, bson.D{{Key: "kind", Value: "work"}}, contacts[1], "a document without the field stays without it")
+	assert.Equal(t, bson.A{Redacted, Redacted}, out[1].Value)
+}
+
+func TestMask_NullsPassThrough(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "name", Strategy: models.MaskFake, Kind: models.FakeName})
+	out := maskOne(t, m, bson.D{{Key: "name", Value: nil}})
+	assert.Nil(t, out[0].Value)
+}
+
+func TestMask_Fake(t *testing.T) {
+	m := testMasker(t,
+		models.MaskingRule{Path: "name", Strategy: models.MaskFake, Kind: models.FakeName},
+		models.MaskingRule{Path: "email", Strategy: models.MaskFake, Kind: models.FakeEmail},
+		models.MaskingRule{Path: "phone", Strategy: models.MaskFake, Kind: models.FakePhone},
+		models.MaskingRule{Path: "address", Strategy: models.MaskFake, Kind: models.FakeAddress},
+	)
+	doc := bson.D{
+		{Key: "name", Value: "Ada Lovelace"},
+		{Key: "email", Value: "ada@example.org"},
+		{Key: "phone", Value: "+44 20 7946 0000"},
+		{Key: "address", Value: "12 St James's Square, London"},
+	}
+	out := maskOne(t, m, doc)
+
+	name := out[0].Value.(string)
+	assert.NotEqual(t, "Ada Lovelace", name)
+	assert.Len(t, strings.Fields(name), 2)
+	assert.Regexp(t, `^[a-z]+\.[a-z]+\d+@example\.com$`, out[1].Value)
+	assert.Regexp(t, `^07700 900\d{3}$`, out[2].Value)
+	assert.Regexp(t, `^\d+ \w+ \w+, \w+$`, out[3].Value)
+	assert.Equal(t, out, maskOne(t, m, doc), "fakes are consistent")
+}
+
+func TestMask_Jitter(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "born", Strategy: models.MaskJitter, Days: 10})
+	born := time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC)
+	out := maskOne(t, m, bson.D{{Key: "born", Value: bson.NewDateTimeFromTime(born)}})
+
+	moved := out[0].Value.(bson.DateTime).Time()
+	assert.NotEqual(t, born, moved)
+	assert.LessOrEqual(t, moved.Sub(born).Abs(), 10*24*time.Hour)
+
+	notDate := maskOne(t, m, bson.D{{Key: "born", Value: "1815"}})
+	assert.Equal(t, "1815", notDate[0].Value, "jitter leaves values that aren't dates alone")
+}
+
+func TestMask_Shuffle(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "city", Strategy: models.MaskShuffle})
+	cities := []string{"Leeds", "York", "Bath", "Hull", "Ely", "Wells", "Derby", "Truro"}
+	docs := make([]bson.Raw, len(cities)+1)
+	for i, c := range cities {
+		raw, err := bson.Marshal(bson.D{{Key: "i", Value: int32(i)}, {Key: "city", Value: c}})
+		require.NoError(t, err)
+		docs[i] = raw
+	}
+	raw, err := bson.Marshal(bson.D{{Key: "i", Value: int32(len(cities))}})
+	require.NoError(t, err)
+	docs[len(cities)] = raw
+
+	masked, err := m.Mask(docs)
+	require.NoError(t, err)
+
+	var got []string
+	moved := false
+	for i, raw := range masked[:len(cities)] {
+		city := raw.Lookup("city").StringValue()
+		got = append(got, city)
+		moved = moved || city != cities[i]
+	}
+	assert.ElementsMatch(t, cities, got, "shuffling keeps the column's values")
+	assert.True(t, moved)
+	_, err = masked[len(cities)].LookupErr("city")
+	assert.Error(t, err, "a document without the field stays without it")
+}
+
+func TestMask_Keep(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "name", Strategy: models.MaskKeep})
+	doc := bson.D{{Key: "name", Value: "Ada"}}
+	assert.Equal(t, doc, maskOne(t, m, doc))
+}
+
+func TestMaskMaps(t *testing.T) {
+	m := testMasker(t, models.MaskingRule{Path: "a.b", Strategy: models.MaskRedact})
+	docs := []bson.M{{"a": bson.M{"b": "secret", "c": "kept"}}}
+	m.MaskMaps(docs)
+	assert.Equal(t, bson.M{"a": bson.M{"b": Redacted, "c": "kept"}}, docs[0])
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(models.MaskingProfile{Name: "bad", Rules: []models.MaskingRule{{Path: "a", Strategy: "scramble"}}})
+	assert.ErrorContains(t, err, `unknown masking strategy "scramble"`)
+
+	m, err := New(models.MaskingProfile{Name: "keyless", Rules: []models.MaskingRule{{Path: "a", Strategy: models.MaskHash}}})
+	require.NoError(t, err)
+	assert.Len(t, m.key, 32, "a profile without a key gets a random one")
+}
@@ -0,0 +1,53 @@
+package masking
+
+import (
+	"fmt"
+	"log/slog"
+
+	"vervet/internal/logging"
+	"vervet/internal/models"
+)
+
+// SettingsSource provides the saved masking profiles.
+// Implemented by settings.Service.
+type SettingsSource interface {
+	GetSettings() (models.Settings, error)
+}
+
+// Service finds saved masking profiles for the exports and copies that use
+// them.
+type Service struct {
+	log      *slog.Logger
+	settings SettingsSource
+}
+
+func NewService(log *slog.Logger, settings SettingsSource) *Service {
+	return &Service{
+		log:      log.With(slog.String(logging.SourceKey, "MaskingService")),
+		settings: settings,
+	}
+}
+
+// Masker compiles the saved profile called name. No name means no masking,
+// and gives a nil Masker.
+func (s *Service) Masker(name string) (*Masker, error) {
+	if name == "" {
+		return nil, nil
+	}
+	settings, err := s.settings.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range settings.Masking.Profiles {
+		if p.Name == name {
+			return New(p)
+		}
+	}
+	return nil, fmt.Errorf("there is no masking profile named %q", name)
+}
+
+// Check lists what is wrong with profile, and which of its paths the
+// sampled schema does not have.
+func (s *Service) Check(profile models.MaskingProfile, schema models.CollectionSchema) []models.MaskingIssue {
+	return Check(profile, schema)
+}
@@ -0,0 +1,89 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Redacted replaces each value a redact rule masks.
+const Redacted = "<redacted>"
+
+// hashLength is how many hex digits of a keyed hash are kept: 128 bits,
+// plenty to keep distinct values distinct.
+const hashLength = 32
+
+// strategy gives the function a rule replaces each value with. Nulls are
+// left alone: there is nothing in them to hide.
+func (m *Masker) strategy(r models.MaskingRule) func(any) any {
+	var fn func(any) any
+	switch r.Strategy {
+	case models.MaskHash:
+		fn = func(v any) any {
+			return hex.EncodeToString(m.sum("hash", v))[:hashLength]
+		}
+	case models.MaskRedact:
+		fn = func(any) any { return Redacted }
+	case models.MaskFake:
+		fn = func(v any) any {
+			return Fake(r.Kind, binary.BigEndian.Uint64(m.sum("fake:"+r.Kind, v)))
+		}
+	case models.MaskJitter:
+		span := int64(r.Days) * int64(24*time.Hour/time.Millisecond)
+		fn = func(v any) any {
+			shift := func(ms int64) int64 {
+				n := binary.BigEndian.Uint64(m.sum("jitter", v))
+				return ms + int64(n%uint64(2*span+1)) - span
+			}
+			switch d := v.(type) {
+			case bson.DateTime:
+				return bson.DateTime(shift(int64(d)))
+			case time.Time:
+				return time.UnixMilli(shift(d.UnixMilli())).UTC()
+			default:
+				return v
+			}
+		}
+	default:
+		return func(v any) any { return v }
+	}
+	return func(v any) any {
+		if v == nil {
+			return nil
+		}
+		if _, ok := v.(bson.Null); ok {
+			return v
+		}
+		return fn(v)
+	}
+}
+
+// sum is the keyed hash of v, for purpose. The same value gives the same
+// sum under the same key, so it masks the same way wherever it appears.
+func (m *Masker) sum(purpose string, v any) []byte {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write(valueBytes(v))
+	return mac.Sum(nil)
+}
+
+// valueBytes identifies a value: a string by its text, anything else by its
+// canonical Extended JSON, so 1 and "1" differ and an ObjectId hashes the
+// same wherever it is referenced.
+func valueBytes(v any) []byte {
+	if s, ok := v.(string); ok {
+		return []byte(s)
+	}
+	b, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, true, false)
+	if err != nil {
+		return nil
+	}
+	return b
+}
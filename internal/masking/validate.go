@@ -0,0 +1,191 @@
+package masking
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"vervet/internal/models"
+)
+
+// maxSuggestionDistance is the most edits apart a sampled path can be from a
+// rule's and still be suggested as what the rule meant.
+const maxSuggestionDistance = 2
+
+// Validate checks a profile's rules make sense, whatever they will mask.
+func Validate(profile models.MaskingProfile) error {
+	if issues := problems(profile); len(issues) > 0 {
+		issue := issues[0]
+		if issue.Rule < 0 {
+			return fmt.Errorf("masking profile %q: %s", profile.Name, issue.Message)
+		}
+		return fmt.Errorf("masking profile %q, rule %d: %s", profile.Name, issue.Rule+1, issue.Message)
+	}
+	return nil
+}
+
+// ValidateProfiles checks each profile, and that no two share a name.
+func ValidateProfiles(profiles []models.MaskingProfile) error {
+	names := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		if err := Validate(p); err != nil {
+			return err
+		}
+		if names[p.Name] {
+			return fmt.Errorf("there is more than one masking profile named %q", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// Check lists a profile's problems, including rules whose paths the sampled
+// documents don't have, most likely from a misspelling.
+func Check(profile models.MaskingProfile, schema models.CollectionSchema) []models.MaskingIssue {
+	issues := problems(profile)
+	bad := make(map[int]bool, len(issues))
+	for _, issue := range issues {
+		bad[issue.Rule] = true
+	}
+
+	sampled := map[string][]string{}
+	flattenSchema(schema.Fields, sampled)
+	paths := make([]string, 0, len(sampled))
+	for p := range sampled {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	for i, r := range profile.Rules {
+		if bad[i] {
+			continue
+		}
+		types, ok := sampled[r.Path]
+		if !ok {
+			issues = append(issues, models.MaskingIssue{
+				Rule:       i,
+				Path:       r.Path,
+				Message:    "not found in the sampled documents",
+				Suggestion: closest(r.Path, paths),
+			})
+			continue
+		}
+		if r.Strategy == models.MaskJitter && !slices.Contains(types, "date") {
+			issues = append(issues, models.MaskingIssue{
+				Rule:    i,
+				Path:    r.Path,
+				Message: "holds no dates in the sampled documents, so jitter leaves it alone",
+			})
+		}
+	}
+	if issues == nil {
+		issues = []models.MaskingIssue{}
+	}
+	return issues
+}
+
+// problems lists what is wrong with a profile on its own.
+func problems(profile models.MaskingProfile) []models.MaskingIssue {
+	var issues []models.MaskingIssue
+	if strings.TrimSpace(profile.Name) == "" {
+		issues = append(issues, models.MaskingIssue{Rule: -1, Message: "a profile needs a name"})
+	}
+	seen := make(map[string]bool, len(profile.Rules))
+	for i, r := range profile.Rules {
+		report := func(message string) {
+			issues = append(issues, models.MaskingIssue{Rule: i, Path: r.Path, Message: message})
+		}
+		if err := checkPath(r.Path); err != nil {
+			report(err.Error())
+			continue
+		}
+		if seen[r.Path] {
+			report("another rule already masks this path")
+			continue
+		}
+		seen[r.Path] = true
+		switch r.Strategy {
+		case models.MaskHash, models.MaskRedact, models.MaskShuffle, models.MaskKeep:
+		case models.MaskFake:
+			switch r.Kind {
+			case models.FakeName, models.FakeEmail, models.FakePhone, models.FakeAddress:
+			default:
+				report(fmt.Sprintf("unknown kind of fake value %q", r.Kind))
+			}
+		case models.MaskJitter:
+			if r.Days <= 0 {
+				report("jitter needs a number of days")
+			}
+		default:
+			report(fmt.Sprintf("unknown masking strategy %q", r.Strategy))
+		}
+	}
+	return issues
+}
+
+func checkPath(path string) error {
+	if path == "" {
+		return errors.New("a rule needs a path")
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return fmt.Errorf("%q has an empty field name", path)
+		}
+		if strings.HasPrefix(key, "$") {
+			return fmt.Errorf("%q names an operator, not a field", path)
+		}
+	}
+	return nil
+}
+
+// flattenSchema indexes a sampled schema's fields by the dot path a rule
+// would use, with their types. The schema marks array elements with "[]",
+// which rules look through.
+func flattenSchema(fields []models.FieldInfo, into map[string][]string) {
+	for _, f := range fields {
+		path := strings.ReplaceAll(f.Path, "[]", "")
+		for _, t := range f.Types {
+			into[path] = append(into[path], t.Type)
+		}
+		if _, ok := into[path]; !ok {
+			into[path] = nil
+		}
+		flattenSchema(f.Children, into)
+	}
+}
+
+// closest finds the path most like path, ignoring case, if any is close
+// enough to be what was meant.
+func closest(path string, paths []string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, p := range paths {
+		d := distance(strings.ToLower(path), strings.ToLower(p))
+		if d < bestDistance {
+			best, bestDistance = p, d
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,93 @@
+package masking
+
+import (
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	valid := models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{
+		{Path: "email", Strategy: models.MaskHash},
+		{Path: "name", Strategy: models.MaskFake, Kind: models.FakeName},
+		{Path: "born", Strategy: models.MaskJitter, Days: 30},
+	}}
+	require.NoError(t, Validate(valid))
+
+	tests := []struct {
+		name    string
+		profile models.MaskingProfile
+		wantErr string
+	}{
+		{"no name", models.MaskingProfile{}, "a profile needs a name"},
+		{"no path", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Strategy: models.MaskHash}}}, "rule 1: a rule needs a path"},
+		{"empty segment", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "a..b", Strategy: models.MaskHash}}}, "empty field name"},
+		{"operator", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "$where", Strategy: models.MaskHash}}}, "names an operator"},
+		{"unknown strategy", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "a", Strategy: "scramble"}}}, `unknown masking strategy "scramble"`},
+		{"unknown kind", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "a", Strategy: models.MaskFake, Kind: "iban"}}}, `unknown kind of fake value "iban"`},
+		{"jitter without days", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "a", Strategy: models.MaskJitter}}}, "jitter needs a number of days"},
+		{"duplicate path", models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{
+			{Path: "a", Strategy: models.MaskHash},
+			{Path: "a", Strategy: models.MaskRedact},
+		}}, "rule 2: another rule already masks this path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, Validate(tt.profile), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	p := models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{{Path: "a", Strategy: models.MaskRedact}}}
+	assert.NoError(t, ValidateProfiles(nil))
+	assert.NoError(t, ValidateProfiles([]models.MaskingProfile{p}))
+	assert.ErrorContains(t, ValidateProfiles([]models.MaskingProfile{p, p}), `more than one masking profile named "p"`)
+}
+
+func TestCheck(t *testing.T) {
+	schema := models.CollectionSchema{Fields: []models.FieldInfo{
+		{Path: "email", Name: "email", Types: []models.TypeStat{{Type: "string"}}},
+		{Path: "createdAt", Name: "createdAt", Types: []models.TypeStat{{Type: "date"}}},
+		{Path: "contacts", Name: "contacts", Types: []models.TypeStat{{Type: "array"}}, Children: []models.FieldInfo{
+			{Path: "contacts[].phone", Name: "phone", Types: []models.TypeStat{{Type: "string"}}},
+		}},
+	}}
+	profile := models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{
+		{Path: "email", Strategy: models.MaskHash},
+		{Path: "contacts.phone", Strategy: models.MaskRedact},
+		{Path: "emial", Strategy: models.MaskHash},
+		{Path: "CreatedAt", Strategy: models.MaskJitter, Days: 5},
+		{Path: "email.address", Strategy: models.MaskFake, Kind: "iban"},
+		{Path: "email", Strategy: models.MaskJitter, Days: 5},
+		{Path: "nothingLikeIt", Strategy: models.MaskRedact},
+		{Path: "createdAt", Strategy: models.MaskJitter, Days: 5},
+	}}
+
+	issues := Check(profile, schema)
+	byRule := map[int]models.MaskingIssue{}
+	for _, issue := range issues {
+		byRule[issue.Rule] = issue
+	}
+	assert.Len(t, issues, 5)
+	assert.NotContains(t, byRule, 0)
+	assert.NotContains(t, byRule, 1, "array paths match through their elements")
+	assert.Equal(t, "email", byRule[2].Suggestion, "a misspelling is matched to the sampled path")
+	assert.Equal(t, "createdAt", byRule[3].Suggestion, "case is ignored when suggesting")
+	assert.Contains(t, byRule[4].Message, "unknown kind")
+	assert.Contains(t, byRule[5].Message, "another rule already masks")
+	assert.Equal(t, "not found in the sampled documents", byRule[6].Message)
+	assert.Empty(t, byRule[6].Suggestion)
+	assert.NotContains(t, byRule, 7)
+
+	jitter := Check(models.MaskingProfile{Name: "p", Rules: []models.MaskingRule{
+		{Path: "email", Strategy: models.MaskJitter, Days: 5},
+	}}, schema)
+	require.Len(t, jitter, 1)
+	assert.Contains(t, jitter[0].Message, "no dates")
+
+	assert.NotNil(t, Check(models.MaskingProfile{Name: "p"}, schema))
+}
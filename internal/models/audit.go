@@ -0,0 +1,71 @@
+package models
+
+import "encoding/json"
+
+// AuditAction is a change Vervet made to a server, as the code that made it
+// reports it to the audit log.
+type AuditAction struct {
+	ServerID   string
+	Database   string
+	Collection string
+	// Operation names what was done, after the shell method where there is
+	// one: "updateMany", "dropCollection", "createIndex".
+	Operation string
+	// Source is AuditSourceScript for a query tab's script and AuditSourceApp
+	// for the app's own dialogs and menus.
+	Source string
+	// Target is what the operation named besides its namespace: the new
+	// namespace of a rename, or an index name. It is never redacted.
+	Target string
+	// Arguments are what the operation was given: its filter, update,
+	// documents or index keys. They are redacted per AuditSettings before
+	// they are written.
+	Arguments     any
+	AffectedCount int64
+	// Error is why the operation failed, empty when it succeeded.
+	Error string
+}
+
+// Audit action sources.
+const (
+	AuditSourceScript = "script"
+	AuditSourceApp    = "app"
+)
+
+// AuditEntry is one line of the audit log. Each entry carries the hash of the
+// one before it, so a changed, removed or reordered entry breaks the chain.
+type AuditEntry struct {
+	Seq int64 `json:"seq"`
+	// Time is when the entry was written, RFC 3339 in UTC.
+	Time       string `json:"time"`
+	ServerID   string `json:"serverID"`
+	ServerName string `json:"serverName"`
+	// Namespace is "db.collection", or just "db" for database operations.
+	Namespace string `json:"namespace"`
+	Operation string `json:"operation"`
+	Source    string `json:"source"`
+	Target    string `json:"target,omitempty"`
+	// Arguments are the action's arguments as Extended JSON, redacted.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// ArgumentsSize is set instead of Arguments when they were too large to
+	// record, to the size they would have taken.
+	ArgumentsSize int    `json:"argumentsSize,omitempty"`
+	AffectedCount int64  `json:"affectedCount"`
+	Error         string `json:"error,omitempty"`
+	// PrevHash is the previous entry's Hash; the first entry's is 64 zeros.
+	PrevHash string `json:"prevHash"`
+	// Hash is the SHA-256 of PrevHash and the entry's other fields.
+	Hash string `json:"hash"`
+}
+
+// AuditVerification is the outcome of checking the audit log's hash chain.
+type AuditVerification struct {
+	Entries int64 `json:"entries"`
+	Valid   bool  `json:"valid"`
+	// BrokenAt is the line the chain first breaks at, counting from 1.
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// LastHash is the newest entry's hash. Keeping a copy elsewhere shows
+	// later whether entries were removed from the end of the log.
+	LastHash string `json:"lastHash,omitempty"`
+}
@@ -0,0 +1,81 @@
+package models
+
+// Backup formats, the two mongodump writes and mongorestore reads.
+const (
+	// BackupDirectory is mongodump's default layout: a directory per
+	// database holding <collection>.bson and <collection>.metadata.json.
+	BackupDirectory = "directory"
+	// BackupArchive is mongodump --archive: every collection in one file.
+	BackupArchive = "archive"
+)
+
+// DumpRequest describes a dump of one database, or some of its collections.
+type DumpRequest struct {
+	// DumpID identifies the run in progress events and to CancelBackup.
+	DumpID   string `json:"dumpId"`
+	ServerID string `json:"serverId"`
+	Database string `json:"database"`
+	// Collections limits the dump to these collections and views. Empty
+	// dumps all of them.
+	Collections []string `json:"collections"`
+	// Path is the directory to dump into, or the archive file to write.
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// Gzip compresses each file of a directory dump, or the whole archive,
+	// like mongodump --gzip.
+	Gzip bool `json:"gzip"`
+}
+
+// RestoreRequest describes a restore of a dump written by Dump or mongodump.
+type RestoreRequest struct {
+	// RestoreID identifies the run in progress events and to CancelBackup.
+	RestoreID string `json:"restoreId"`
+	ServerID  string `json:"serverId"`
+	// Path is a dump directory, either the top one or one database's, or an
+	// archive file. Gzip compression is detected.
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// Database restores into this database instead of the one dumped. Only
+	// for dumps of a single database.
+	Database string `json:"database"`
+	// Drop drops each collection and view before restoring it. Without it,
+	// documents are added to existing collections and those the server
+	// refuses, such as a duplicate _id, are counted as failed.
+	Drop bool `json:"drop"`
+}
+
+// BackupProgress reports a running dump or restore.
+type BackupProgress struct {
+	// ID is the request's DumpID or RestoreID.
+	ID string `json:"id"`
+	// Namespace is the collection being read or written, as db.collection.
+	Namespace string `json:"namespace"`
+	// Documents is how many documents of Namespace are done so far.
+	Documents int64 `json:"documents"`
+	// Total is the estimated number of documents in Namespace, or zero when
+	// it is not known.
+	Total           int64 `json:"total"`
+	CollectionsDone int   `json:"collectionsDone"`
+	Collections     int   `json:"collections"`
+}
+
+// BackupCollection is what a dump or restore did with one collection or
+// view.
+type BackupCollection struct {
+	Namespace string `json:"namespace"`
+	// Type is "collection" or "view".
+	Type      string `json:"type"`
+	Documents int64  `json:"documents"`
+	Indexes   int    `json:"indexes"`
+	// Failed counts documents a restore could not write. FirstError says
+	// why the first of them failed.
+	Failed     int64  `json:"failed"`
+	FirstError string `json:"firstError,omitempty"`
+}
+
+// BackupResult is the outcome of a dump or restore.
+type BackupResult struct {
+	Path        string             `json:"path"`
+	Collections []BackupCollection `json:"collections"`
+	Cancelled   bool               `json:"cancelled"`
+}
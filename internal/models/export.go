@@ -0,0 +1,11 @@
+package models
+
+// ExportProgress reports a running server export.
+type ExportProgress struct {
+	ExportID string `json:"exportId"`
+	// Documents is how many documents have been written so far.
+	Documents int64 `json:"documents"`
+	// Total is how many documents the export should write, or zero while it
+	// is still being counted or cannot be known, as for a pipeline.
+	Total int64 `json:"total"`
+}
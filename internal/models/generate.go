@@ -0,0 +1,91 @@
+package models
+
+// Where generated documents go.
+const (
+	// GenerateInsert inserts the documents into a collection.
+	GenerateInsert = "insert"
+	// GenerateNDJSON writes the documents to a file, one Extended JSON
+	// document per line.
+	GenerateNDJSON = "ndjson"
+)
+
+// Kinds of override: how a field's values are made instead of from the
+// sample.
+const (
+	// OverrideConstant gives every document the same value.
+	OverrideConstant = "constant"
+	// OverrideChoice picks one of the values at random.
+	OverrideChoice = "choice"
+	// OverrideRange picks a number or date between Min and Max.
+	OverrideRange = "range"
+	// OverrideSequence counts up from Min, or from 1.
+	OverrideSequence = "sequence"
+	// OverrideFake makes up a value of the kind Fake names.
+	OverrideFake = "fake"
+	// OverrideOmit leaves the field out.
+	OverrideOmit = "omit"
+)
+
+// GenerateRequest asks for documents like those in a sampled collection.
+type GenerateRequest struct {
+	// GenerateID identifies the run in progress events and to
+	// CancelGenerate.
+	GenerateID string `json:"generateId"`
+	// ServerID, Database and Collection name the collection sampled.
+	ServerID   string `json:"serverId"`
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	// SampleSize is how many documents to sample. Zero means the default.
+	SampleSize int `json:"sampleSize"`
+	// Count is how many documents to generate.
+	Count     int                `json:"count"`
+	Overrides []GenerateOverride `json:"overrides"`
+	// Seed makes the documents the same each time it is used. Zero uses a
+	// random seed.
+	Seed uint64 `json:"seed"`
+
+	Output string `json:"output"`
+	// TargetServerID and TargetDatabase default to the sampled server and
+	// database. TargetCollection must differ from the sampled one.
+	TargetServerID   string `json:"targetServerId"`
+	TargetDatabase   string `json:"targetDatabase"`
+	TargetCollection string `json:"targetCollection"`
+	// Path is the file GenerateNDJSON writes.
+	Path string `json:"path"`
+}
+
+// GenerateOverride replaces the sampled values of the field at Path, a dot
+// path that reaches into arrays. A path the sample doesn't have adds the
+// field.
+type GenerateOverride struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Values are Extended JSON: one for a constant, any number for a choice.
+	Values []string `json:"values,omitempty"`
+	// Min and Max bound a range, as numbers or RFC 3339 dates. Min starts a
+	// sequence.
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+	// Fake is the kind of fake value: FakeName, FakeEmail, FakePhone or
+	// FakeAddress.
+	Fake string `json:"fake,omitempty"`
+}
+
+// GenerateProgress reports a running generation.
+type GenerateProgress struct {
+	GenerateID string `json:"generateId"`
+	Generated  int64  `json:"generated"`
+	Total      int64  `json:"total"`
+}
+
+// GenerateResult is the outcome of a generation.
+type GenerateResult struct {
+	Generated int64 `json:"generated"`
+	// Failed counts documents the target collection refused. FirstError
+	// says why the first of them failed.
+	Failed     int64  `json:"failed"`
+	FirstError string `json:"firstError,omitempty"`
+	// Path is the file written, for GenerateNDJSON.
+	Path      string `json:"path,omitempty"`
+	Cancelled bool   `json:"cancelled"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// HistoryEntry is one query execution, as the query executor recorded it.
+type HistoryEntry struct {
+	ID         string `json:"id"`
+	ServerID   string `json:"serverID"`
+	Database   string `json:"database"`
+	ScriptPath string `json:"scriptPath,omitempty"`
+	Query      string `json:"query"`
+	// QueryTruncated marks a query too long to keep whole: only its start is
+	// stored, so it cannot be replayed.
+	QueryTruncated bool   `json:"queryTruncated,omitempty"`
+	Engine         string `json:"engine"`
+	// StartedAt is when the query started, in Unix milliseconds.
+	StartedAt   int64  `json:"startedAt"`
+	DurationMS  int64  `json:"durationMs"`
+	Outcome     string `json:"outcome"`
+	ErrorCode   string `json:"errorCode,omitempty"`
+	ResultCount int    `json:"resultCount"`
+	// ResultsPaged marks a paged find, whose ResultCount is only the size of
+	// its first page.
+	ResultsPaged bool `json:"resultsPaged,omitempty"`
+}
+
+// History entry outcomes.
+const (
+	HistorySucceeded = "succeeded"
+	HistoryFailed    = "failed"
+	HistoryCancelled = "cancelled"
+)
+
+// HistoryFilter narrows a query history search. Zero fields match every entry.
+type HistoryFilter struct {
+	// Text matches, case-insensitively, anywhere in the query, its database
+	// or its script path.
+	Text     string `json:"text"`
+	ServerID string `json:"serverID"`
+	// From and To bound when the query started, in Unix milliseconds.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Limit caps how many entries come back, newest first.
+	Limit int `json:"limit"`
+}
@@ -0,0 +1,118 @@
+package models
+
+// Import formats.
+const (
+	ImportCSV    = "csv"
+	ImportJSON   = "json"
+	ImportNDJSON = "ndjson"
+)
+
+// Import modes. Insert adds every document; upsert replaces the document
+// matching the request's UpsertKeys, inserting it when there is none.
+const (
+	ImportInsert = "insert"
+	ImportUpsert = "upsert"
+)
+
+// CSV column types. Auto reads what flattened exports write: numbers,
+// booleans and Extended JSON for arrays and BSON types, anything else as a
+// string.
+const (
+	ImportTypeAuto     = "auto"
+	ImportTypeString   = "string"
+	ImportTypeNumber   = "number"
+	ImportTypeBoolean  = "boolean"
+	ImportTypeDate     = "date"
+	ImportTypeObjectID = "objectId"
+	ImportTypeEJSON    = "ejson"
+	ImportTypeSkip     = "skip"
+)
+
+// ImportRequest describes one import of a file into a collection.
+type ImportRequest struct {
+	// ImportID identifies the run in progress events and to CancelImport.
+	ImportID   string           `json:"importId"`
+	ServerID   string           `json:"serverId"`
+	Database   string           `json:"database"`
+	Collection string           `json:"collection"`
+	Path       string           `json:"path"`
+	Format     string           `json:"format"`
+	CSV        ImportCSVOptions `json:"csv"`
+	Mode       string           `json:"mode"`
+	// UpsertKeys are the dot-path fields that identify a document in upsert
+	// mode.
+	UpsertKeys []string `json:"upsertKeys"`
+	// BatchSize is how many documents each write sends. Zero uses the
+	// default.
+	BatchSize int `json:"batchSize"`
+}
+
+// ImportCSVOptions are how a CSV file is read.
+type ImportCSVOptions struct {
+	// Separator is the field separator; the first character counts. Empty
+	// is a comma.
+	Separator string `json:"separator"`
+	// HasHeader says the first record names the columns.
+	HasHeader bool `json:"hasHeader"`
+	// IgnoreBlanks leaves empty cells out of the document instead of
+	// storing them.
+	IgnoreBlanks bool `json:"ignoreBlanks"`
+	// Columns maps the file's columns, in file order, to fields. Empty maps
+	// each column to the field its header names, read as ImportTypeAuto.
+	Columns []ImportColumn `json:"columns"`
+}
+
+// ImportColumn maps one CSV column to a document field.
+type ImportColumn struct {
+	// Field is the dot path the value is stored at, rebuilding nested
+	// documents: "address.city". Empty skips the column.
+	Field string `json:"field"`
+	Type  string `json:"type"`
+}
+
+// ImportPreview is the start of a file as the import would read it.
+type ImportPreview struct {
+	// Headers are a CSV file's column names, or "field1", "field2"... when it
+	// has no header row. Empty for JSON and NDJSON.
+	Headers []string `json:"headers"`
+	// Documents are the first parsed documents as relaxed Extended JSON.
+	Documents []string         `json:"documents"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is a row the import rejected.
+type ImportRowError struct {
+	// Row is the line of a CSV or NDJSON file, or the position of the
+	// document in a JSON file, counting from 1.
+	Row   int64  `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportProgress reports a running import.
+type ImportProgress struct {
+	ImportID string `json:"importId"`
+	// Rows is how many rows have been read, rejected ones included.
+	Rows     int64 `json:"rows"`
+	Inserted int64 `json:"inserted"`
+	Updated  int64 `json:"updated"`
+	Rejected int64 `json:"rejected"`
+	// BytesRead of TotalBytes gives how far through the file the import is.
+	BytesRead  int64 `json:"bytesRead"`
+	TotalBytes int64 `json:"totalBytes"`
+}
+
+// ImportResult is how an import ended.
+type ImportResult struct {
+	Rows     int64 `json:"rows"`
+	Inserted int64 `json:"inserted"`
+	// Updated counts the documents an upsert changed, not those it matched
+	// and left as they were.
+	Updated  int64 `json:"updated"`
+	Rejected int64 `json:"rejected"`
+	// Errors are the rejected rows, the first MaxImportErrors of them.
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errorsTruncated"`
+	// Cancelled is set when the import was stopped before the end of the
+	// file. What was written before then stays written.
+	Cancelled bool `json:"cancelled"`
+}
@@ -0,0 +1,64 @@
+package models
+
+// Masking strategies: what a rule does to the value at its path.
+const (
+	// MaskHash replaces a value with a keyed hash of it, so equal values
+	// stay equal and can still be joined on.
+	MaskHash = "hash"
+	// MaskRedact replaces a value with "<redacted>".
+	MaskRedact = "redact"
+	// MaskShuffle swaps values between documents, keeping the column's
+	// values but not which document had which.
+	MaskShuffle = "shuffle"
+	// MaskFake replaces a value with a made-up one of the rule's Kind.
+	MaskFake = "fake"
+	// MaskJitter moves a date by up to the rule's Days either way.
+	MaskJitter = "jitter"
+	// MaskKeep leaves a value as it is, marking the field as reviewed.
+	MaskKeep = "keep"
+)
+
+// Kinds of fake value.
+const (
+	FakeName    = "name"
+	FakeEmail   = "email"
+	FakePhone   = "phone"
+	FakeAddress = "address"
+)
+
+type MaskingSettings struct {
+	Profiles []MaskingProfile `json:"profiles" yaml:"profiles,omitempty"`
+}
+
+// MaskingProfile is a named set of masking rules, applied to documents as
+// they are exported or copied.
+type MaskingProfile struct {
+	Name string `json:"name" yaml:"name"`
+	// Key keys the hashes, fakes and jitter, so the same value masks the same
+	// way in every export and copy made with the profile. Empty uses a new
+	// random key each time.
+	Key   string        `json:"key" yaml:"key,omitempty"`
+	Rules []MaskingRule `json:"rules" yaml:"rules"`
+}
+
+// MaskingRule masks the value at Path, a dot path that reaches into
+// arrays: "contacts.email" masks the email of every contact.
+type MaskingRule struct {
+	Path     string `json:"path" yaml:"path"`
+	Strategy string `json:"strategy" yaml:"strategy"`
+	// Kind is the kind of fake value, for MaskFake.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Days is how far MaskJitter may move a date.
+	Days int `json:"days,omitempty" yaml:"days,omitempty"`
+}
+
+// MaskingIssue is a problem with a profile: a rule that is not valid, or a
+// path the sampled documents don't have.
+type MaskingIssue struct {
+	// Rule is the index of the rule, or -1 for the profile itself.
+	Rule    int    `json:"rule"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Suggestion is a sampled path the rule's may be a misspelling of.
+	Suggestion string `json:"suggestion,omitempty"`
+}
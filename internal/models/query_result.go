@@ -1,9 +1,13 @@
 package models
 
 // PageContext describes a paginated find() so the frontend can request
-// further pages without re-running the user's script. Emitted by the Goja
-// engine when a script's final value resolves to a find() lazy cursor.
+// further pages without re-running the user's script. Emitted by either
+// engine when a script's final value is a find() cursor; later pages are
+// always fetched through the Go driver.
 type PageContext struct {
+	// Database is the database the find ran against, when the engine knows
+	// it. Empty means the query tab's database.
+	Database   string         `json:"database,omitempty"`
 	Collection string         `json:"collection"`
 	Filter     any            `json:"filter,omitempty"`
 	Projection any            `json:"projection,omitempty"`
@@ -14,6 +18,9 @@ type PageContext struct {
 	UserSkip   int64          `json:"userSkip,omitempty"`
 	MaxTimeMS  int64          `json:"maxTimeMS,omitempty"`
 	Comment    string         `json:"comment,omitempty"`
+	// ExtendedJSON marks Filter, Projection, Sort, Hint and Collation as
+	// canonical Extended JSON, the form mongosh hands them over in.
+	ExtendedJSON bool `json:"extendedJSON,omitempty"`
 }
 
 // QueryResult holds the parsed output of a query.
@@ -0,0 +1,37 @@
+package models
+
+// Kinds of question a running script can ask with QueryPrompt.
+const (
+	PromptKindText    = "prompt"
+	PromptKindConfirm = "confirm"
+)
+
+// QueryProgress is a running script's progress(done, total, message) report.
+// Total is 0 when the script doesn't know how much work there is.
+type QueryProgress struct {
+	ServerID string  `json:"serverID"`
+	QueryID  string  `json:"queryID"`
+	Done     float64 `json:"done"`
+	Total    float64 `json:"total"`
+	Message  string  `json:"message"`
+}
+
+// QueryPrompt is a question a running script is waiting on. The frontend
+// answers it through ShellProxy.AnswerPrompt with PromptID.
+type QueryPrompt struct {
+	ServerID string `json:"serverID"`
+	QueryID  string `json:"queryID"`
+	PromptID string `json:"promptID"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Default  string `json:"default,omitempty"`
+}
+
+// QueryOutput carries lines a running script has printed since the last
+// QueryOutput for the same query. The finished QueryResult still holds the
+// whole output; these only let the tab show it as it happens.
+type QueryOutput struct {
+	ServerID string   `json:"serverID"`
+	QueryID  string   `json:"queryID"`
+	Lines    []string `json:"lines"`
+}
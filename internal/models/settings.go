@@ -15,6 +15,8 @@ type Settings struct {
 	Workspaces WorkspacesSettings `json:"workspaces" yaml:"workspaces"`
 	Updates    UpdatesSettings    `json:"updates" yaml:"updates"`
 	Logging    LoggingSettings    `json:"logging" yaml:"logging"`
+	Audit      AuditSettings      `json:"audit" yaml:"audit"`
+	Masking    MaskingSettings    `json:"masking" yaml:"masking,omitempty"`
 }
 
 type WorkspacesSettings struct {
@@ -47,6 +49,41 @@ type QuerySettings struct {
 	DefaultLimit    int    `json:"defaultLimit" yaml:"defaultLimit"`
 	DefaultPageSize int    `json:"defaultPageSize" yaml:"defaultPageSize"`
 	QueryEngine     string `json:"queryEngine" yaml:"queryEngine"`
+	ScriptSandbox   string `json:"scriptSandbox" yaml:"scriptSandbox"`
+}
+
+// Script sandbox modes for the built-in engine. Unrestricted scripts reach
+// whatever the user's account can; workspace scripts only files inside the
+// active workspace's folders and the script's own directory; read-only
+// scripts the same files, without being able to change them. Both restricted
+// modes also hide the environment from process.env.
+const (
+	ScriptSandboxUnrestricted = "unrestricted"
+	ScriptSandboxWorkspace    = "workspace"
+	ScriptSandboxReadOnly     = "readonly"
+)
+
+type AuditSettings struct {
+	Redaction string `json:"redaction" yaml:"redaction"`
+}
+
+// Audit log redaction modes. None writes operation arguments as they were
+// given; values keeps their field names and operators but replaces every value;
+// all leaves arguments out.
+const (
+	AuditRedactNone   = "none"
+	AuditRedactValues = "values"
+	AuditRedactAll    = "all"
+)
+
+// Normalize falls back to redacting values when the mode is missing or
+// unknown.
+func (a *AuditSettings) Normalize() {
+	switch a.Redaction {
+	case AuditRedactNone, AuditRedactValues, AuditRedactAll:
+	default:
+		a.Redaction = AuditRedactValues
+	}
 }
 
 type FontSettings struct {
@@ -111,6 +148,11 @@ func (q *QuerySettings) Normalize() {
 	default:
 		q.QueryEngine = "builtin"
 	}
+	switch q.ScriptSandbox {
+	case ScriptSandboxUnrestricted, ScriptSandboxWorkspace, ScriptSandboxReadOnly:
+	default:
+		q.ScriptSandbox = ScriptSandboxUnrestricted
+	}
 }
 
 type WindowState struct {
@@ -0,0 +1,17 @@
+package models
+
+// TerminalOutput is a chunk of what an interactive mongosh session has
+// written, escape sequences and all, for the frontend's terminal to draw.
+type TerminalOutput struct {
+	SessionID string `json:"sessionID"`
+	Data      string `json:"data"`
+}
+
+// TerminalExit reports that an interactive mongosh session has ended, either
+// because the user quit it or because it was closed.
+type TerminalExit struct {
+	SessionID string `json:"sessionID"`
+	ServerID  string `json:"serverID"`
+	ExitCode  int    `json:"exitCode"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,110 @@
+package models
+
+// Transfer modes: what happens to a target collection that already exists.
+const (
+	// TransferDrop drops the target collection before copying into it.
+	TransferDrop = "drop"
+	// TransferMerge keeps the target collection, replacing documents whose
+	// _id matches one copied and adding the rest.
+	TransferMerge = "merge"
+)
+
+// TransferRequest describes a copy of collections from one server and
+// database to another.
+type TransferRequest struct {
+	// TransferID identifies the transfer in progress events, to
+	// CancelTransfer, and to ResumeTransfer once interrupted.
+	TransferID     string `json:"transferId"`
+	SourceServerID string `json:"sourceServerId"`
+	SourceDatabase string `json:"sourceDatabase"`
+	TargetServerID string `json:"targetServerId"`
+	TargetDatabase string `json:"targetDatabase"`
+	// Collections are copied in order. Views are copied as collections of
+	// their results.
+	Collections []TransferCollection `json:"collections"`
+	Mode        string               `json:"mode"`
+	// CopyIndexes builds the source collection's indexes on the target.
+	CopyIndexes bool `json:"copyIndexes"`
+	// CopyOptions creates the target collection with the source's options:
+	// its validator, collation, capped size or time-series settings.
+	CopyOptions bool `json:"copyOptions"`
+	// BatchSize is how many documents each write sends. Zero means the
+	// default.
+	BatchSize int `json:"batchSize"`
+	// MaskingProfile names the saved masking profile applied to each batch
+	// before it is written. Empty copies the documents as they are.
+	MaskingProfile string `json:"maskingProfile,omitempty"`
+}
+
+// TransferCollection is one collection to copy, and which of its documents.
+type TransferCollection struct {
+	Name string `json:"name"`
+	// Target names the copy. Empty keeps the source's name.
+	Target string `json:"target,omitempty"`
+	// Filter is an Extended JSON query document choosing the documents to
+	// copy. Empty copies them all.
+	Filter string `json:"filter,omitempty"`
+	// Pipeline is an Extended JSON array of aggregation stages whose output
+	// is copied instead. It takes the place of Filter.
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// TransferProgress reports a running transfer.
+type TransferProgress struct {
+	TransferID string `json:"transferId"`
+	// Collection is the source collection being copied.
+	Collection string `json:"collection"`
+	// Copied counts the documents of Collection written so far, including
+	// those written before the transfer was resumed.
+	Copied int64 `json:"copied"`
+	// Total is how many documents Collection's filter matches, or zero until
+	// that is counted or when a pipeline makes it unknown.
+	Total           int64 `json:"total"`
+	CollectionsDone int   `json:"collectionsDone"`
+	Collections     int   `json:"collections"`
+}
+
+// TransferCollectionResult is what a transfer did with one collection.
+type TransferCollectionResult struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	Copied int64  `json:"copied"`
+	// Failed counts documents the target refused. FirstError says why the
+	// first of them failed, or why an index could not be built.
+	Failed     int64  `json:"failed"`
+	FirstError string `json:"firstError,omitempty"`
+	Indexes    int    `json:"indexes"`
+}
+
+// TransferResult is the outcome of a transfer. A cancelled or failed
+// transfer can be resumed.
+type TransferResult struct {
+	Collections []TransferCollectionResult `json:"collections"`
+	Cancelled   bool                       `json:"cancelled"`
+}
+
+// TransferCheckpoint is how far an interrupted transfer got, saved after
+// every batch so the transfer can carry on from there.
+type TransferCheckpoint struct {
+	Request     TransferRequest           `json:"request"`
+	Collections []TransferCollectionState `json:"collections"`
+	// UpdatedAt is when the checkpoint was last saved, RFC 3339 in UTC.
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// TransferCollectionState is how far one collection of a transfer got.
+type TransferCollectionState struct {
+	Name string `json:"name"`
+	// Prepared is set once the target has been dropped or created, so a
+	// resumed transfer doesn't drop what it already copied.
+	Prepared bool `json:"prepared"`
+	Done     bool `json:"done"`
+	// LastID is the _id of the last document written, as canonical
+	// Extended JSON. Documents are copied in _id order, so a resumed
+	// transfer starts after it.
+	LastID     string `json:"lastId,omitempty"`
+	Copied     int64  `json:"copied"`
+	Failed     int64  `json:"failed"`
+	FirstError string `json:"firstError,omitempty"`
+	Indexes    int    `json:"indexes"`
+}
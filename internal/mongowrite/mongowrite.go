@@ -0,0 +1,60 @@
+// Package mongowrite holds what the services that write to a server in bulk
+// (import, restore, transfer and data generation) share: telling refused
+// documents from failed writes, and recreating collections and indexes.
+package mongowrite
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// codeNamespaceExists is the server's code for creating a collection or view
+// that is already there.
+const codeNamespaceExists = 48
+
+// WriteErrors separates the documents the server refused, which the caller
+// counts and carries on after, from a failure of the write as a whole.
+func WriteErrors(err error) ([]mongo.BulkWriteError, error) {
+	if err == nil {
+		return nil, nil
+	}
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) && bwe.WriteConcernError == nil && len(bwe.WriteErrors) > 0 {
+		return bwe.WriteErrors, nil
+	}
+	return nil, err
+}
+
+// NamespaceExists reports whether err is the server refusing to create a
+// collection or view because it already exists.
+func NamespaceExists(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCode(codeNamespaceExists)
+}
+
+// IndexesToCreate gives the index specifications to build from those listed
+// for a collection: every index but the _id one, which comes with the
+// collection, without the ns field that servers before 4.4 report and newer
+// ones refuse.
+func IndexesToCreate(indexes []bson.D) []bson.D {
+	var specs []bson.D
+	for _, index := range indexes {
+		var spec bson.D
+		skip := false
+		for _, e := range index {
+			switch {
+			case e.Key == "name" && e.Value == "_id_":
+				skip = true
+			case e.Key == "ns":
+			default:
+				spec = append(spec, e)
+			}
+		}
+		if !skip {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
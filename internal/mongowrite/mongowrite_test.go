@@ -0,0 +1,52 @@
+package mongowrite
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestWriteErrors(t *testing.T) {
+	failed, err := WriteErrors(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+
+	refused := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}},
+	}}
+	failed, err = WriteErrors(fmt.Errorf("insert: %w", refused))
+	assert.NoError(t, err)
+	assert.Equal(t, refused.WriteErrors, failed)
+
+	concern := mongo.BulkWriteException{
+		WriteErrors:       refused.WriteErrors,
+		WriteConcernError: &mongo.WriteConcernError{Code: 64, Message: "waiting for replication timed out"},
+	}
+	_, err = WriteErrors(concern)
+	assert.Error(t, err, "a write concern failure stops the write")
+
+	down := errors.New("connection refused")
+	_, err = WriteErrors(down)
+	assert.Equal(t, down, err)
+}
+
+func TestNamespaceExists(t *testing.T) {
+	assert.True(t, NamespaceExists(fmt.Errorf("create: %w", mongo.CommandError{Code: 48, Message: "already exists"})))
+	assert.False(t, NamespaceExists(mongo.CommandError{Code: 26, Message: "ns not found"}))
+	assert.False(t, NamespaceExists(nil))
+}
+
+func TestIndexesToCreate(t *testing.T) {
+	indexes := []bson.D{
+		{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}, {Key: "ns", Value: "shop.orders"}},
+		{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "sku", Value: 1}}}, {Key: "name", Value: "sku_1"}, {Key: "ns", Value: "shop.orders"}, {Key: "unique", Value: true}},
+	}
+	assert.Equal(t, []bson.D{
+		{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "sku", Value: 1}}}, {Key: "name", Value: "sku_1"}, {Key: "unique", Value: true}},
+	}, IndexesToCreate(indexes))
+	assert.Empty(t, IndexesToCreate(indexes[:1]))
+}
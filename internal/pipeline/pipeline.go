@@ -0,0 +1,29 @@
+// Package pipeline reads the aggregation pipelines users give exports and
+// transfers, as Extended JSON.
+package pipeline
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Parse reads an aggregation pipeline given as an Extended JSON array of
+// stages, each a document with one field. Which stages are allowed is left
+// to the caller.
+func Parse(raw string) (bson.A, error) {
+	var wrapper bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"p":`+raw+`}`), false, &wrapper); err != nil || len(wrapper) != 1 {
+		return nil, errors.New("the pipeline must be an Extended JSON array of stages")
+	}
+	stages, ok := wrapper[0].Value.(bson.A)
+	if !ok {
+		return nil, errors.New("the pipeline must be an Extended JSON array of stages")
+	}
+	for _, stage := range stages {
+		if d, ok := stage.(bson.D); !ok || len(d) != 1 {
+			return nil, errors.New("each pipeline stage must be a document with one field")
+		}
+	}
+	return stages, nil
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParse(t *testing.T) {
+	stages, err := Parse(`[{"$match":{"_id":{"$oid":"65f000000000000000000000"}}},{"$limit":5}]`)
+	require.NoError(t, err)
+	require.Len(t, stages, 2)
+	match := stages[0].(bson.D)[0].Value.(bson.D)
+	assert.IsType(t, bson.ObjectID{}, match[0].Value)
+
+	for _, raw := range []string{`not json`, `{"$match":{}}`} {
+		_, err = Parse(raw)
+		assert.ErrorContains(t, err, "an Extended JSON array of stages", raw)
+	}
+	for _, raw := range []string{`[1]`, `[{"$match":{},"$limit":1}]`} {
+		_, err = Parse(raw)
+		assert.ErrorContains(t, err, "a document with one field", raw)
+	}
+}
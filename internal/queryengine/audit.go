@@ -0,0 +1,118 @@
+package queryengine
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"vervet/internal/models"
+)
+
+// Auditor records the changes a script makes to a server.
+type Auditor interface {
+	// Audit records action once it has been attempted. action.ServerID is
+	// empty for the server the script runs on, and the name or ID the
+	// script gave for a server it reached through Mongo() or connect().
+	Audit(action models.AuditAction)
+}
+
+// auditedMethods are the eager collection methods that change a server.
+var auditedMethods = map[string]bool{
+	"insertOne": true, "insertMany": true,
+	"updateOne": true, "updateMany": true,
+	"deleteOne": true, "deleteMany": true,
+	"replaceOne":       true,
+	"findOneAndDelete": true, "findOneAndReplace": true, "findOneAndUpdate": true,
+	"bulkWrite": true, "drop": true,
+	"createIndex": true, "createIndexes": true, "dropIndex": true, "dropIndexes": true,
+}
+
+// readOnlyCommands are the commands, in lower case, that runCommand and
+// adminCommand send without an audit entry. Any other command might change
+// the server, so it is recorded.
+var readOnlyCommands = map[string]bool{
+	"ping": true, "hello": true, "ismaster": true, "buildinfo": true, "whatsmyuri": true,
+	"serverstatus": true, "hostinfo": true, "connectionstatus": true, "connpoolstats": true,
+	"dbstats": true, "collstats": true, "datasize": true, "top": true, "lockinfo": true,
+	"listcollections": true, "listdatabases": true, "listindexes": true, "listcommands": true,
+	"find": true, "getmore": true, "count": true, "distinct": true, "explain": true,
+	"getparameter": true, "getcmdlineopts": true, "getlog": true, "currentop": true,
+	"usersinfo": true, "rolesinfo": true, "validate": true, "getdefaultrwconcern": true,
+	"replsetgetstatus": true, "replsetgetconfig": true, "listshards": true, "balancerstatus": true,
+}
+
+// commandChanges reports whether running cmd might change the server: an
+// aggregate only when it ends in $out or $merge.
+func commandChanges(cmd bson.D) bool {
+	if len(cmd) == 0 {
+		return false
+	}
+	name := strings.ToLower(cmd[0].Key)
+	if name != "aggregate" {
+		return !readOnlyCommands[name]
+	}
+	for _, e := range cmd {
+		if e.Key != "pipeline" {
+			continue
+		}
+		pipeline, _ := e.Value.(bson.A)
+		for _, stage := range pipeline {
+			if d, ok := stage.(bson.D); ok && len(d) > 0 && (d[0].Key == "$out" || d[0].Key == "$merge") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutPassword copies cmd without its pwd field, so creating a user or
+// changing a password never writes the password to the audit log.
+func withoutPassword(cmd bson.D) bson.D {
+	out := make(bson.D, 0, len(cmd))
+	for _, e := range cmd {
+		if e.Key != "pwd" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// auditCommand records cmd, sent to dbName for operation, with the password
+// of a user command left out.
+func (ec *execContext) auditCommand(dbName, coll, operation string, cmd bson.D, err error) {
+	ec.auditIn(dbName, coll, operation, []any{withoutPassword(cmd)}, 0, err)
+}
+
+// audit records operation on collection coll of ec's database, when the
+// script has an auditor. args are the script's arguments as exported from
+// goja; err is the error the operation failed with, if any.
+func (ec *execContext) audit(coll, operation string, args []any, affected int, err error) {
+	ec.auditIn(ec.dbName, coll, operation, args, affected, err)
+}
+
+// auditIn is audit for an operation on a database other than ec's own, as
+// adminCommand and the admin helpers run against admin.
+func (ec *execContext) auditIn(dbName, coll, operation string, args []any, affected int, err error) {
+	if ec.auditor == nil {
+		return
+	}
+	action := models.AuditAction{
+		ServerID:      ec.server,
+		Database:      dbName,
+		Collection:    coll,
+		Operation:     operation,
+		Source:        models.AuditSourceScript,
+		AffectedCount: int64(affected),
+	}
+	if len(args) > 0 {
+		converted := make(bson.A, len(args))
+		for i, arg := range args {
+			converted[i] = convertToBson(arg)
+		}
+		action.Arguments = converted
+	}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	ec.auditor.Audit(action)
+}
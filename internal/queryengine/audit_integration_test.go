@@ -0,0 +1,83 @@
+//go:build integration
+
+package queryengine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vervet/internal/models"
+)
+
+func TestIntegration_Audit_RecordsScriptWrites(t *testing.T) {
+	engine, db, ctx := setupScriptData(t)
+	auditor := &recordingAuditor{}
+	engine.SetAuditor(auditor)
+
+	runScript(t, engine, ctx, db, `
+		db.responses.find({ IsError: true }).toArray();
+		db.responses.updateMany({ IsError: true }, { $set: { Reviewed: true } });
+		db.responses.createIndex({ CustomerId: 1 });
+		db.getSiblingDB("`+db+`").responses.deleteOne({ IsError: false });
+		db.responses.renameCollection("archived");
+	`)
+
+	ops := make([]string, len(auditor.actions))
+	for i, a := range auditor.actions {
+		ops[i] = a.Operation
+	}
+	require.Equal(t, []string{"updateMany", "createIndex", "deleteOne", "renameCollection"}, ops, "reads are not audited")
+
+	update := auditor.actions[0]
+	assert.Equal(t, db, update.Database)
+	assert.Equal(t, "responses", update.Collection)
+	assert.Equal(t, models.AuditSourceScript, update.Source)
+	assert.Equal(t, int64(5), update.AffectedCount)
+	assert.Empty(t, update.ServerID, "the script's own server")
+	assert.Equal(t, int64(1), auditor.actions[2].AffectedCount)
+}
+
+func TestIntegration_Audit_RecordsFailedWrites(t *testing.T) {
+	engine, db, ctx := setupScriptData(t)
+	auditor := &recordingAuditor{}
+	engine.SetAuditor(auditor)
+
+	_, err := engine.ExecuteQuery(ctx, testURI, db, `db.responses.dropIndex("no_such_index")`)
+	require.Error(t, err)
+
+	require.Len(t, auditor.actions, 1)
+	assert.Equal(t, "dropIndex", auditor.actions[0].Operation)
+	assert.NotEmpty(t, auditor.actions[0].Error)
+}
+
+func TestIntegration_Audit_RecordsCommandsAndAdminHelpers(t *testing.T) {
+	engine, db, ctx := setupScriptData(t)
+	auditor := &recordingAuditor{}
+	engine.SetAuditor(auditor)
+
+	runScript(t, engine, ctx, db, `
+		db.runCommand({ ping: 1 });
+		db.adminCommand({ listDatabases: 1 });
+		db.createUser({ user: "audit_probe", pwd: "secret", roles: [] });
+		db.grantRolesToUser("audit_probe", ["read"]);
+		db.runCommand({ dropUser: "audit_probe" });
+		db.setProfilingLevel(0);
+		// A replica set member refuses compact; it is recorded either way.
+		try { db.responses.compact() } catch (e) {}
+		try { db.responses.configureQueryAnalyzer({ mode: "off" }) } catch (e) {}
+	`)
+
+	ops := make([]string, len(auditor.actions))
+	for i, a := range auditor.actions {
+		ops[i] = a.Operation
+	}
+	require.Equal(t, []string{"createUser", "grantRolesToUser", "dropUser", "setProfilingLevel", "compact", "configureQueryAnalyzer"}, ops,
+		"reads sent with runCommand and adminCommand are not audited")
+	assert.NotContains(t, fmt.Sprint(auditor.actions[0].Arguments), "secret")
+	assert.Equal(t, "responses", auditor.actions[4].Collection)
+	assert.Equal(t, "admin", auditor.actions[5].Database, "sent to admin, naming the collection")
+	assert.Equal(t, "responses", auditor.actions[5].Collection)
+}
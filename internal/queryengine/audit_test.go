@@ -0,0 +1,101 @@
+package queryengine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"vervet/internal/models"
+)
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (a *recordingAuditor) Audit(action models.AuditAction) {
+	a.actions = append(a.actions, action)
+}
+
+func TestAudit_RecordsAction(t *testing.T) {
+	auditor := &recordingAuditor{}
+	ec := &execContext{ctx: context.Background(), dbName: "shop", rt: goja.New(), auditor: auditor, server: "staging"}
+
+	ec.audit("orders", "deleteMany", []any{map[string]any{"status": "open"}}, 4, nil)
+
+	require.Len(t, auditor.actions, 1)
+	got := auditor.actions[0]
+	assert.Equal(t, "staging", got.ServerID)
+	assert.Equal(t, "shop", got.Database)
+	assert.Equal(t, "orders", got.Collection)
+	assert.Equal(t, "deleteMany", got.Operation)
+	assert.Equal(t, models.AuditSourceScript, got.Source)
+	assert.Equal(t, int64(4), got.AffectedCount)
+	assert.Equal(t, bson.A{bson.D{{Key: "status", Value: "open"}}}, got.Arguments)
+	assert.Empty(t, got.Error)
+}
+
+func TestAudit_RecordsFailure(t *testing.T) {
+	auditor := &recordingAuditor{}
+	ec := &execContext{ctx: context.Background(), dbName: "shop", rt: goja.New(), auditor: auditor}
+
+	ec.audit("", "dropDatabase", nil, 0, errors.New("not authorized"))
+
+	require.Len(t, auditor.actions, 1)
+	assert.Nil(t, auditor.actions[0].Arguments)
+	assert.Equal(t, "not authorized", auditor.actions[0].Error)
+}
+
+func TestAudit_WithoutAuditorDoesNothing(t *testing.T) {
+	ec := &execContext{ctx: context.Background(), dbName: "shop", rt: goja.New()}
+	assert.NotPanics(t, func() {
+		ec.audit("orders", "insertOne", []any{map[string]any{"a": 1}}, 1, nil)
+	})
+}
+
+func TestCommandChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  bson.D
+		want bool
+	}{
+		{"read", bson.D{{Key: "ping", Value: 1}}, false},
+		{"read in any case", bson.D{{Key: "isMaster", Value: 1}}, false},
+		{"write", bson.D{{Key: "createUser", Value: "ada"}}, true},
+		{"unknown command", bson.D{{Key: "somethingNew", Value: 1}}, true},
+		{"aggregate", bson.D{{Key: "aggregate", Value: "orders"}, {Key: "pipeline", Value: bson.A{
+			bson.D{{Key: "$match", Value: bson.D{}}},
+		}}}, false},
+		{"aggregate into a collection", bson.D{{Key: "aggregate", Value: "orders"}, {Key: "pipeline", Value: bson.A{
+			bson.D{{Key: "$match", Value: bson.D{}}},
+			bson.D{{Key: "$out", Value: "copy"}},
+		}}}, true},
+		{"empty", bson.D{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commandChanges(tt.cmd))
+		})
+	}
+}
+
+func TestAuditCommand_LeavesOutPasswords(t *testing.T) {
+	auditor := &recordingAuditor{}
+	ec := &execContext{ctx: context.Background(), dbName: "shop", rt: goja.New(), auditor: auditor}
+
+	ec.auditCommand("admin", "", "createUser", bson.D{
+		{Key: "createUser", Value: "ada"},
+		{Key: "pwd", Value: "secret"},
+		{Key: "roles", Value: bson.A{"read"}},
+	}, nil)
+
+	require.Len(t, auditor.actions, 1)
+	got := auditor.actions[0]
+	assert.Equal(t, "admin", got.Database)
+	assert.Equal(t, "createUser", got.Operation)
+	assert.Equal(t, bson.A{bson.D{{Key: "createUser", Value: "ada"}, {Key: "roles", Value: bson.A{"read"}}}}, got.Arguments)
+}
@@ -74,6 +74,10 @@ func dispatch(ctx context.Context, client *mongo.Client, dbName string, op Captu
 
 // toBsonDoc converts a map[string]any (from goja) to a bson.D, preserving key order.
 func toBsonDoc(v any) bson.D {
+	// Already BSON, as a decoded Extended JSON PageContext is.
+	if d, ok := v.(bson.D); ok {
+		return d
+	}
 	m, ok := v.(map[string]any)
 	if !ok {
 		return bson.D{}
@@ -115,31 +119,18 @@ func convertToBson(v any) any {
 	}
 }
 
-// docsToResult converts bson.M documents into a clean QueryResult by round-tripping
-// through canonical Extended JSON. Canonical mode preserves all BSON type information
-// (e.g. $numberInt, $numberLong, $numberDouble, $date, $regularExpression) so the
-// frontend can display types correctly.
-func docsToResult(docs []bson.M) models.QueryResult {
-	if len(docs) == 0 {
-		return models.QueryResult{Documents: []any{}}
+// docsToResult wraps documents as the server sent them in a QueryResult. Each
+// encodes straight from BSON to canonical Extended JSON when the result is sent
+// to the frontend, which preserves all BSON type information (e.g. $numberInt,
+// $numberLong, $numberDouble, $date, $regularExpression) and key order so the
+// frontend can display types correctly. Scripts get them decoded on the way in
+// (see normalizeForJS).
+func docsToResult(docs []bson.Raw) models.QueryResult {
+	results := make([]any, len(docs))
+	for i, doc := range docs {
+		results[i] = resultDocument{raw: doc}
 	}
-
-	cleaned := make([]any, 0, len(docs))
-	for _, doc := range docs {
-		b, err := bson.MarshalExtJSON(doc, true, false)
-		if err != nil {
-			cleaned = append(cleaned, doc)
-			continue
-		}
-		var m map[string]any
-		if err := json.Unmarshal(b, &m); err != nil {
-			cleaned = append(cleaned, doc)
-			continue
-		}
-		cleaned = append(cleaned, m)
-	}
-
-	return models.QueryResult{Documents: cleaned}
+	return models.QueryResult{Documents: results}
 }
 
 // singleToResult wraps a single value as the sole document in a QueryResult.
@@ -26,8 +26,11 @@ func dispatchFind(ctx context.Context, coll *mongo.Collection, op CapturedOp) (m
 
 	opts := options.Find()
 	if len(op.Args) > 1 && op.Args[1] != nil {
-		if projMap, ok := op.Args[1].(map[string]any); ok {
-			opts.SetProjection(toBsonDoc(projMap))
+		switch proj := op.Args[1].(type) {
+		case map[string]any:
+			opts.SetProjection(toBsonDoc(proj))
+		case bson.D:
+			opts.SetProjection(proj)
 		}
 	}
 	applyFindOptions(opts, op)
@@ -38,7 +41,7 @@ func dispatchFind(ctx context.Context, coll *mongo.Collection, op CapturedOp) (m
 	}
 	defer cursor.Close(ctx)
 
-	var results []bson.M
+	var results []bson.Raw
 	if err := cursor.All(ctx, &results); err != nil {
 		return models.QueryResult{}, fmt.Errorf("reading cursor: %w", err)
 	}
@@ -93,6 +96,8 @@ func toCollation(m map[string]any) *options.Collation {
 	}
 	if v, ok := m["strength"]; ok {
 		switch n := v.(type) {
+		case int32:
+			c.Strength = int(n)
 		case int64:
 			c.Strength = int(n)
 		case int:
@@ -187,8 +192,7 @@ func dispatchFindOne(ctx context.Context, coll *mongo.Collection, op CapturedOp)
 		filter = toBsonDoc(op.Args[0])
 	}
 
-	var result bson.M
-	err := coll.FindOne(ctx, filter).Decode(&result)
+	result, err := coll.FindOne(ctx, filter).Raw()
 	if err == mongo.ErrNoDocuments {
 		return models.QueryResult{Documents: []any{}, OperationType: "findOne"}, nil
 	}
@@ -196,7 +200,7 @@ func dispatchFindOne(ctx context.Context, coll *mongo.Collection, op CapturedOp)
 		return models.QueryResult{}, fmt.Errorf("findOne failed: %w", err)
 	}
 
-	result2 := docsToResult([]bson.M{result})
+	result2 := docsToResult([]bson.Raw{result})
 	result2.OperationType = "findOne"
 	return result2, nil
 }
@@ -233,7 +237,7 @@ func dispatchAggregate(ctx context.Context, coll *mongo.Collection, op CapturedO
 	}
 	defer cursor.Close(ctx)
 
-	var results []bson.M
+	var results []bson.Raw
 	if err := cursor.All(ctx, &results); err != nil {
 		return models.QueryResult{}, fmt.Errorf("reading aggregate cursor: %w", err)
 	}
@@ -286,8 +290,7 @@ func dispatchFindOneAndDelete(ctx context.Context, coll *mongo.Collection, op Ca
 		filter = toBsonDoc(op.Args[0])
 	}
 
-	var result bson.M
-	err := coll.FindOneAndDelete(ctx, filter).Decode(&result)
+	result, err := coll.FindOneAndDelete(ctx, filter).Raw()
 	if err == mongo.ErrNoDocuments {
 		return models.QueryResult{Documents: []any{}, OperationType: "findOneAndDelete"}, nil
 	}
@@ -295,7 +298,7 @@ func dispatchFindOneAndDelete(ctx context.Context, coll *mongo.Collection, op Ca
 		return models.QueryResult{}, fmt.Errorf("findOneAndDelete failed: %w", err)
 	}
 
-	r := docsToResult([]bson.M{result})
+	r := docsToResult([]bson.Raw{result})
 	r.OperationType = "findOneAndDelete"
 	return r, nil
 }
@@ -315,8 +318,7 @@ func dispatchFindOneAndReplace(ctx context.Context, coll *mongo.Collection, op C
 		replacement = toBsonDoc(op.Args[1])
 	}
 
-	var result bson.M
-	err := coll.FindOneAndReplace(ctx, filter, replacement).Decode(&result)
+	result, err := coll.FindOneAndReplace(ctx, filter, replacement).Raw()
 	if err == mongo.ErrNoDocuments {
 		return models.QueryResult{Documents: []any{}, OperationType: "findOneAndReplace"}, nil
 	}
@@ -324,7 +326,7 @@ func dispatchFindOneAndReplace(ctx context.Context, coll *mongo.Collection, op C
 		return models.QueryResult{}, fmt.Errorf("findOneAndReplace failed: %w", err)
 	}
 
-	r := docsToResult([]bson.M{result})
+	r := docsToResult([]bson.Raw{result})
 	r.OperationType = "findOneAndReplace"
 	return r, nil
 }
@@ -337,8 +339,7 @@ func dispatchFindOneAndUpdate(ctx context.Context, coll *mongo.Collection, op Ca
 	filter := toBsonDoc(op.Args[0])
 	update := convertToBson(op.Args[1])
 
-	var result bson.M
-	err := coll.FindOneAndUpdate(ctx, filter, update).Decode(&result)
+	result, err := coll.FindOneAndUpdate(ctx, filter, update).Raw()
 	if err == mongo.ErrNoDocuments {
 		return models.QueryResult{Documents: []any{}, OperationType: "findOneAndUpdate"}, nil
 	}
@@ -346,7 +347,7 @@ func dispatchFindOneAndUpdate(ctx context.Context, coll *mongo.Collection, op Ca
 		return models.QueryResult{}, fmt.Errorf("findOneAndUpdate failed: %w", err)
 	}
 
-	r := docsToResult([]bson.M{result})
+	r := docsToResult([]bson.Raw{result})
 	r.OperationType = "findOneAndUpdate"
 	return r, nil
 }
@@ -0,0 +1,82 @@
+package queryengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ParseDocument evaluates src, one document written in shell syntax, into
+// BSON. The BSON constructors such as ObjectId() and ISODate() are
+// available; servers are not. Fields keep the order src gives them.
+func ParseDocument(src string) (bson.D, error) {
+	rt := goja.New()
+	if err := registerBSONTypes(rt); err != nil {
+		return nil, err
+	}
+	val, err := rt.RunString("(" + src + "\n)")
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	obj, ok := val.(*goja.Object)
+	if !ok || obj.ClassName() != "Object" || isWrappedBSON(obj) {
+		return nil, errors.New("invalid document: not an object")
+	}
+	return orderedDocument(obj), nil
+}
+
+func isWrappedBSON(obj *goja.Object) bool {
+	bv := obj.Get("__bsonValue")
+	return bv != nil && !goja.IsUndefined(bv)
+}
+
+// orderedDocument converts obj to a bson.D in the order of its keys, which
+// exportValue's maps lose.
+func orderedDocument(obj *goja.Object) bson.D {
+	keys := obj.Keys()
+	doc := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		doc = append(doc, bson.E{Key: key, Value: orderedValue(obj.Get(key))})
+	}
+	return doc
+}
+
+// orderedValue converts val to BSON as convertToBson(exportValue(val))
+// does, but with objects as bson.D in the order of their keys.
+func orderedValue(val goja.Value) any {
+	obj, ok := val.(*goja.Object)
+	if !ok {
+		return convertToBson(exportValue(val))
+	}
+	// Reflected Go values, such as a document a command returned, are
+	// already BSON.
+	if exported := obj.Export(); exported != nil {
+		if _, isMap := exported.(map[string]any); !isMap && obj.ClassName() == "Object" {
+			return exported
+		}
+	}
+	switch {
+	case obj.ClassName() == "Array":
+		length := int(obj.Get("length").ToInteger())
+		arr := make(bson.A, length)
+		for i := range length {
+			arr[i] = orderedValue(obj.Get(fmt.Sprintf("%d", i)))
+		}
+		return arr
+	case obj.ClassName() == "Object" && !isWrappedBSON(obj):
+		return orderedDocument(obj)
+	default:
+		return convertToBson(exportValue(val))
+	}
+}
+
+// IDFilter matches the document whose _id is id, in canonical Extended JSON.
+func IDFilter(id string) (bson.D, error) {
+	var filter bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"_id":`+id+`}`), true, &filter); err != nil {
+		return nil, fmt.Errorf("invalid _id: %w", err)
+	}
+	return filter, nil
+}
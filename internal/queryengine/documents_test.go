@@ -0,0 +1,56 @@
+package queryengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParseDocument(t *testing.T) {
+	doc, err := ParseDocument(`{ _id: ObjectId("65a1b2c3d4e5f60718293a4b"), name: "Ada", ` +
+		`born: ISODate("1815-12-10T00:00:00Z"), tags: ["math", { z: 1, a: NumberLong("2") }], ok: true }`)
+	require.NoError(t, err)
+
+	require.Len(t, doc, 5)
+	born, ok := doc[2].Value.(time.Time)
+	require.True(t, ok, "ISODate() is a date, got %T", doc[2].Value)
+	assert.True(t, born.Equal(time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC)))
+	doc[2].Value = nil
+
+	id, _ := bson.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "Ada"},
+		{Key: "born", Value: nil},
+		{Key: "tags", Value: bson.A{"math", bson.D{{Key: "z", Value: int64(1)}, {Key: "a", Value: int64(2)}}}},
+		{Key: "ok", Value: true},
+	}, doc)
+}
+
+func TestParseDocument_KeepsFieldOrder(t *testing.T) {
+	doc, err := ParseDocument(`{ zeta: 1, alpha: 2, mid: { y: 1, b: 2 } }`)
+	require.NoError(t, err)
+	keys := make([]string, len(doc))
+	for i, e := range doc {
+		keys[i] = e.Key
+	}
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, keys)
+	assert.Equal(t, "y", doc[2].Value.(bson.D)[0].Key)
+}
+
+func TestParseDocument_Rejects(t *testing.T) {
+	for name, src := range map[string]string{
+		"syntax error":  `{ name: }`,
+		"not an object": `[1, 2]`,
+		"a BSON value":  `ObjectId()`,
+		"a string":      `"text"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseDocument(src)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -15,4 +15,11 @@ type execContext struct {
 	dbName   string
 	rt       *goja.Runtime
 	pageSize int64
+	// shell is the state mongosh shell commands share across every db the
+	// script reaches. It is nil outside ExecuteQuery.
+	shell *shellState
+	// auditor records the changes the script makes, and server is the
+	// server ec reaches as the auditor knows it: empty for the script's own.
+	auditor Auditor
+	server  string
 }
@@ -3,6 +3,7 @@ package queryengine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
@@ -23,14 +24,54 @@ import (
 type GojaEngine struct {
 	client   *mongo.Client
 	pageSize int64
+	// docLimit caps the size of each document a result displays (see
+	// resultDocument). Zero shows every document whole.
+	docLimit int
 	// scriptPath is the file the query was loaded from, empty for an unsaved
 	// tab. It gives the script __filename/__dirname and fixes the directory
 	// that load() and relative fs paths resolve against.
 	scriptPath string
+	// sandbox limits the files and environment the script can reach. The
+	// zero value leaves it unrestricted.
+	sandbox jsmodules.Sandbox
+	// servers resolves the registered servers Mongo() and connect() name.
+	// Nil limits the script to its own connection.
+	servers ServerResolver
+	// ui receives printed output and progress() reports as they happen, and
+	// answers prompt() and confirm().
+	// Nil means nobody is watching the script.
+	ui ScriptUI
+	// auditor records the changes scripts this engine runs make. Nil records
+	// nothing.
+	auditor Auditor
 }
 
 func NewGojaEngine(client *mongo.Client, pageSize int64, scriptPath string) *GojaEngine {
-	return &GojaEngine{client: client, pageSize: pageSize, scriptPath: scriptPath}
+	return &GojaEngine{client: client, pageSize: pageSize, docLimit: DefaultDocumentLimit, scriptPath: scriptPath}
+}
+
+// SetServers lets scripts this engine runs reach other registered servers
+// through Mongo() and connect().
+func (e *GojaEngine) SetServers(servers ServerResolver) {
+	e.servers = servers
+}
+
+// SetSandbox sets the policy fs, require(), load() and process.env enforce
+// for scripts this engine runs.
+func (e *GojaEngine) SetSandbox(sandbox jsmodules.Sandbox) {
+	e.sandbox = sandbox
+}
+
+// SetUI connects the scripts this engine runs to the person running them,
+// for live output, progress(), prompt() and confirm().
+func (e *GojaEngine) SetUI(ui ScriptUI) {
+	e.ui = ui
+}
+
+// SetAuditor records every change the scripts this engine runs make to a
+// server through auditor.
+func (e *GojaEngine) SetAuditor(auditor Auditor) {
+	e.auditor = auditor
 }
 
 func (e *GojaEngine) ExecuteQuery(ctx context.Context, uri, dbName, query string) (models.QueryResult, error) {
@@ -39,15 +80,15 @@ func (e *GojaEngine) ExecuteQuery(ctx context.Context, uri, dbName, query string
 	rt := goja.New()
 	// The registry is built per execution because the modules it registers
 	// close over baseDir, which differs from one script to the next.
-	registry := require.NewRegistry()
-	jsmodules.RegisterAll(registry, baseDir)
-	registry.Enable(rt)
+	registry := require.NewRegistry(require.WithLoader(jsmodules.SourceLoader(e.sandbox)))
+	jsmodules.RegisterAll(registry, baseDir, e.sandbox)
+	jsmodules.Enable(registry, rt)
 	buffer.Enable(rt)
 	process.Enable(rt)
-	if err := registerScriptEnv(rt, scriptPath, baseDir); err != nil {
+	if err := registerScriptEnv(rt, scriptPath, baseDir, e.sandbox); err != nil {
 		return models.QueryResult{}, err
 	}
-	ec := &execContext{ctx: ctx, client: e.client, dbName: dbName, rt: rt, pageSize: e.pageSize}
+	ec := &execContext{ctx: ctx, client: e.client, dbName: dbName, rt: rt, pageSize: e.pageSize, shell: &shellState{}, auditor: e.auditor}
 
 	if err := registerBSONTypes(rt); err != nil {
 		return models.QueryResult{}, err
@@ -62,7 +103,22 @@ func (e *GojaEngine) ExecuteQuery(ctx context.Context, uri, dbName, query string
 		return models.QueryResult{}, fmt.Errorf("failed to set db global: %w", err)
 	}
 
+	if err := registerMongo(ec, e.servers); err != nil {
+		return models.QueryResult{}, err
+	}
+
+	if err := registerShellHelpers(ec, baseDir, e.sandbox); err != nil {
+		return models.QueryResult{}, err
+	}
+
+	if err := registerInteraction(ec, e.ui); err != nil {
+		return models.QueryResult{}, err
+	}
+
 	out := &scriptOutput{}
+	if e.ui != nil {
+		out.onLine = e.ui.Output
+	}
 	if err := registerOutput(rt, out); err != nil {
 		return models.QueryResult{}, err
 	}
@@ -79,14 +135,45 @@ func (e *GojaEngine) ExecuteQuery(ctx context.Context, uri, dbName, query string
 			}
 		}()
 
-		val, err := rt.RunString(rewriteTopLevelDeclarations(query))
+		src, err := prepareScript(scriptPath, baseDir, query, e.sandbox)
 		if err != nil {
 			return scriptError(out, err)
 		}
 
+		// Running under the script's own path is what anchors require(): a
+		// relative module path resolves against the calling file, and for the
+		// top-level script that is the saved tab's location.
+		val, err := rt.RunScript(scriptPath, src)
+		if quit, ok := quitFrom(err); ok {
+			// quit() ends the script where it stands: whatever it printed is
+			// the result, and a non-zero exit code is a failure.
+			if quit.code != 0 {
+				return scriptError(out, fmt.Errorf("quit with exit code %d", quit.code))
+			}
+			result = models.QueryResult{RawOutput: out.text()}
+			return nil
+		}
+		if err != nil {
+			return scriptError(out, err)
+		}
+
+		// Promise jobs have all run by now, so a script ending in a promise
+		// (an async IIFE, or readline's events.once(rl, 'close')) has settled
+		// unless it waits on something that never happens. Show what it
+		// settled to, as mongosh does.
+		if p, ok := val.Export().(*goja.Promise); ok {
+			switch p.State() {
+			case goja.PromiseStateFulfilled:
+				val = p.Result()
+			case goja.PromiseStateRejected:
+				return scriptError(out, fmt.Errorf("%s", p.Result().String()))
+			}
+		}
+
 		// Check if return value is an unresolved lazy cursor
 		if cursor := extractLazyCursor(val); cursor != nil && !cursor.resolved {
 			result, retErr = cursor.execute(true)
+			result.Documents = limitDocuments(result.Documents, e.docLimit)
 			return
 		}
 
@@ -106,6 +193,16 @@ func (e *GojaEngine) ExecuteQuery(ctx context.Context, uri, dbName, query string
 	return result, err
 }
 
+// quitFrom reports whether err is the interrupt quit() stops a script with.
+func quitFrom(err error) (*scriptQuit, bool) {
+	var interrupted *goja.InterruptedError
+	if !errors.As(err, &interrupted) {
+		return nil, false
+	}
+	quit, ok := interrupted.Value().(*scriptQuit)
+	return quit, ok
+}
+
 // displayValue prepares a value returned by the script for the frontend.
 // Script-facing BSON values are objects carrying __bsonValue plus the methods
 // that make them usable in JS; handed to the UI as-is, the document would show
@@ -13,9 +13,17 @@ import (
 // Vervet has a single output pane, so both land here.
 type scriptOutput struct {
 	lines []string
+	// onLine, when set, is given each line as it is written, so the output
+	// can be shown while the script is still running.
+	onLine func(line string)
 }
 
-func (o *scriptOutput) add(line string) { o.lines = append(o.lines, line) }
+func (o *scriptOutput) add(line string) {
+	o.lines = append(o.lines, line)
+	if o.onLine != nil {
+		o.onLine(line)
+	}
+}
 
 func (o *scriptOutput) text() string { return strings.Join(o.lines, "\n") }
 
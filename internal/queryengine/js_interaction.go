@@ -0,0 +1,91 @@
+package queryengine
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"vervet/internal/models"
+
+	"github.com/dop251/goja"
+)
+
+// ScriptUI is how a running script reaches the person who ran it: printed
+// output, progress() reports and prompt()/confirm() questions. Without one,
+// output and progress are only seen once the script finishes (progress not
+// at all) and prompt and confirm fail, since nobody is there to answer.
+type ScriptUI interface {
+	// Output is given each line print, printjson or console.* writes, as it
+	// is written.
+	Output(line string)
+	Progress(done, total float64, message string)
+	// Ask blocks until the user answers or ctx ends. kind is one of the
+	// models.PromptKind* values; ok is false when the user dismissed the
+	// question instead of answering it.
+	Ask(ctx context.Context, kind, message, defaultValue string) (answer string, ok bool, err error)
+}
+
+// registerInteraction installs progress, prompt and confirm.
+func registerInteraction(ec *execContext, ui ScriptUI) error {
+	rt := ec.rt
+
+	ask := func(method, kind, message, defaultValue string) (string, bool) {
+		if ui == nil {
+			panic(rt.NewGoError(fmt.Errorf("%s: no one is available to answer", method)))
+		}
+		answer, ok, err := ui.Ask(ec.ctx, kind, message, defaultValue)
+		if err != nil {
+			panic(rt.NewGoError(fmt.Errorf("%s: %w", method, err)))
+		}
+		return answer, ok
+	}
+
+	helpers := map[string]any{
+		// progress(done, total?, message?) — total may be omitted when the
+		// amount of work isn't known up front.
+		"progress": func(call goja.FunctionCall) goja.Value {
+			if ui == nil {
+				return goja.Undefined()
+			}
+			message := ""
+			if arg := call.Argument(2); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+				message = arg.String()
+			}
+			ui.Progress(progressNumber(call.Argument(0)), progressNumber(call.Argument(1)), message)
+			return goja.Undefined()
+		},
+		// prompt(message, default?) → the answer, or null if dismissed.
+		"prompt": func(call goja.FunctionCall) goja.Value {
+			defaultValue := ""
+			if arg := call.Argument(1); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+				defaultValue = arg.String()
+			}
+			answer, ok := ask("prompt", models.PromptKindText, call.Argument(0).String(), defaultValue)
+			if !ok {
+				return goja.Null()
+			}
+			return rt.ToValue(answer)
+		},
+		// confirm(message) → true only if the user agreed.
+		"confirm": func(call goja.FunctionCall) goja.Value {
+			_, ok := ask("confirm", models.PromptKindConfirm, call.Argument(0).String(), "")
+			return rt.ToValue(ok)
+		},
+	}
+	for name, fn := range helpers {
+		if err := rt.Set(name, fn); err != nil {
+			return fmt.Errorf("failed to set %s function: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// progressNumber reads a progress() count. Anything that isn't a finite
+// number counts as 0, which also keeps the event JSON-encodable.
+func progressNumber(v goja.Value) float64 {
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,113 @@
+package queryengine
+
+import (
+	"context"
+	"testing"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type progressReport struct {
+	done, total float64
+	message     string
+}
+
+// fakeUI records output and progress and answers every question with
+// answer/ok.
+type fakeUI struct {
+	output   []string
+	progress []progressReport
+	asked    []string
+	answer   string
+	ok       bool
+	err      error
+}
+
+func (f *fakeUI) Output(line string) { f.output = append(f.output, line) }
+
+func (f *fakeUI) Progress(done, total float64, message string) {
+	f.progress = append(f.progress, progressReport{done, total, message})
+}
+
+func (f *fakeUI) Ask(_ context.Context, kind, message, defaultValue string) (string, bool, error) {
+	f.asked = append(f.asked, kind+":"+message+":"+defaultValue)
+	return f.answer, f.ok, f.err
+}
+
+func runWithUI(t *testing.T, ui ScriptUI, script string) (models.QueryResult, error) {
+	t.Helper()
+	eng := NewGojaEngine(nil, 100, "")
+	if ui != nil {
+		eng.SetUI(ui)
+	}
+	return eng.ExecuteQuery(context.Background(), "", "testdb", script)
+}
+
+func TestProgress_ReportsToUI(t *testing.T) {
+	ui := &fakeUI{}
+	_, err := runWithUI(t, ui, `for (let i = 1; i <= 3; i++) progress(i, 3, "step " + i); progress(5)`)
+	require.NoError(t, err)
+	assert.Equal(t, []progressReport{
+		{1, 3, "step 1"}, {2, 3, "step 2"}, {3, 3, "step 3"}, {5, 0, ""},
+	}, ui.progress)
+}
+
+func TestOutput_StreamsEachLineAsWritten(t *testing.T) {
+	ui := &fakeUI{}
+	result, err := runWithUI(t, ui, `print("a", 1); console.log("b"); printjson({x: 1}); progress(1)`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a 1", "b", "{\n  x: 1\n}"}, ui.output)
+	// The finished result still carries the whole output.
+	assert.Equal(t, "a 1\nb\n{\n  x: 1\n}", result.RawOutput)
+}
+
+func TestOutput_StreamedBeforeScriptFails(t *testing.T) {
+	ui := &fakeUI{}
+	_, err := runWithUI(t, ui, `print("before"); throw new Error("boom")`)
+	require.Error(t, err)
+	assert.Equal(t, []string{"before"}, ui.output)
+}
+
+func TestProgress_WithoutUIIsIgnored(t *testing.T) {
+	result, err := runWithUI(t, nil, `progress(1, 2); "ok"`)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.RawOutput)
+}
+
+func TestPrompt_ReturnsAnswer(t *testing.T) {
+	ui := &fakeUI{answer: "250", ok: true}
+	result, err := runWithUI(t, ui, `prompt("Batch size?", 100)`)
+	require.NoError(t, err)
+	assert.Equal(t, "250", result.RawOutput)
+	assert.Equal(t, []string{"prompt:Batch size?:100"}, ui.asked)
+}
+
+func TestPrompt_DismissedIsNull(t *testing.T) {
+	result, err := runWithUI(t, &fakeUI{ok: false}, `prompt("Name?") === null`)
+	require.NoError(t, err)
+	assert.Equal(t, "true", result.RawOutput)
+}
+
+func TestConfirm_ReturnsWhetherUserAgreed(t *testing.T) {
+	result, err := runWithUI(t, &fakeUI{ok: true}, `confirm("Drop it?")`)
+	require.NoError(t, err)
+	assert.Equal(t, "true", result.RawOutput)
+
+	result, err = runWithUI(t, &fakeUI{ok: false}, `confirm("Drop it?")`)
+	require.NoError(t, err)
+	assert.Equal(t, "false", result.RawOutput)
+}
+
+func TestPrompt_CancelledIsScriptError(t *testing.T) {
+	_, err := runWithUI(t, &fakeUI{err: context.Canceled}, `prompt("Name?")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "context canceled")
+}
+
+func TestPrompt_WithoutUIIsError(t *testing.T) {
+	_, err := runWithUI(t, nil, `confirm("Drop it?")`)
+	require.ErrorContains(t, err, "no one is available to answer")
+}
@@ -11,7 +11,8 @@ import (
 // the map type has zero methods (see goja runtime.go: `NumMethod() == 0`). Without
 // this, a bson.M is wrapped as an opaque object exposing "String" instead of its
 // keys. Stripping M/D/A down to method-less map[string]any / []any restores the
-// v1 reflection behaviour. Scalar BSON types (ObjectID, DateTime, ...) reflected
+// v1 reflection behaviour. Raw query result documents are decoded to the
+// Extended JSON maps scriptValue reads. Scalar BSON types (ObjectID, DateTime, ...) reflected
 // identically in v1 and v2, so they pass through untouched.
 func normalizeForJS(v any) any {
 	switch val := v.(type) {
@@ -27,6 +28,8 @@ func normalizeForJS(v any) any {
 			out[k] = normalizeForJS(elem)
 		}
 		return out
+	case resultDocument:
+		return normalizeForJS(val.scriptDocument())
 	case bson.D:
 		// ponytail: D is flattened to an object (last-wins on duplicate keys). v1
 		// exposed D as an array to JS, but D is not on the result path (results are
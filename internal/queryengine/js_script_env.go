@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"vervet/internal/queryengine/jsmodules"
 
 	"github.com/dop251/goja"
 )
@@ -30,8 +31,9 @@ func scriptLocation(scriptPath string) (path, baseDir string) {
 }
 
 // registerScriptEnv gives the script the file-location globals mongosh
-// provides — __filename, __dirname, process.cwd() and load().
-func registerScriptEnv(rt *goja.Runtime, scriptPath, baseDir string) error {
+// provides — __filename, __dirname, process.cwd() and load() — with load()
+// and process.env held to the sandbox.
+func registerScriptEnv(rt *goja.Runtime, scriptPath, baseDir string, sandbox jsmodules.Sandbox) error {
 	if err := rt.Set("__filename", scriptPath); err != nil {
 		return fmt.Errorf("failed to set __filename global: %w", err)
 	}
@@ -46,29 +48,44 @@ func registerScriptEnv(rt *goja.Runtime, scriptPath, baseDir string) error {
 		if err := proc.Set("cwd", func() string { return baseDir }); err != nil {
 			return fmt.Errorf("failed to set process.cwd: %w", err)
 		}
+		if !sandbox.ExposesEnv() {
+			if err := proc.Set("env", rt.NewObject()); err != nil {
+				return fmt.Errorf("failed to set process.env: %w", err)
+			}
+		}
 	}
 
-	return rt.Set("load", loadFn(rt, baseDir))
+	return rt.Set("load", loadFn(rt, baseDir, sandbox))
 }
 
 // loadFn implements mongosh's load(): it runs another script in the current
 // runtime, so anything the loaded file declares at the top level is visible
 // to the caller afterwards. It returns true, as mongosh's does.
-func loadFn(rt *goja.Runtime, baseDir string) func(goja.FunctionCall) goja.Value {
+func loadFn(rt *goja.Runtime, baseDir string, sandbox jsmodules.Sandbox) func(goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
 		path := call.Argument(0).String()
 		if !filepath.IsAbs(path) && baseDir != "" {
 			path = filepath.Join(baseDir, path)
 		}
 
+		if err := sandbox.CheckRead("open", path); err != nil {
+			exc := rt.NewGoError(fmt.Errorf("EACCES: %w", err))
+			_ = exc.Set("code", "EACCES")
+			panic(exc)
+		}
 		src, err := os.ReadFile(path)
 		if err != nil {
 			panic(rt.NewGoError(fmt.Errorf("could not open file: %s", path)))
 		}
 
-		// The same top-level rewrite the main script gets, so a loaded file's
-		// const/let declarations become globals the caller can see.
-		if _, err := rt.RunScript(path, rewriteTopLevelDeclarations(string(src))); err != nil {
+		// The same preparation the main script gets, so a loaded file's
+		// const/let declarations become globals the caller can see and a
+		// TypeScript helper is compiled first.
+		prepared, err := prepareScript(path, filepath.Dir(path), string(src), sandbox)
+		if err != nil {
+			panic(rt.NewGoError(fmt.Errorf("failed to load %s: %w", path, err)))
+		}
+		if _, err := rt.RunScript(path, prepared); err != nil {
 			var exc *goja.Exception
 			if errors.As(err, &exc) {
 				panic(exc.Value())
@@ -11,10 +11,10 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-// Query results are round-tripped through canonical Extended JSON (docsToResult)
-// so the frontend can render BSON types. Handing those raw maps to a script means
-// `doc.CustomerId` is `{$numberLong: "42"}` rather than a value, so String(),
-// date comparisons and _id.getTimestamp() all silently misbehave.
+// Query results reach scripts as canonical Extended JSON (see normalizeForJS),
+// the form the frontend renders BSON types from. Handing those raw maps to a
+// script means `doc.CustomerId` is `{$numberLong: "42"}` rather than a value,
+// so String(), date comparisons and _id.getTimestamp() all silently misbehave.
 //
 // scriptValue converts an Extended JSON tree back into the shapes mongosh gives a
 // script: real Dates, and objects whose toString() is the value. Each wrapper
@@ -0,0 +1,277 @@
+package queryengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vervet/internal/queryengine/jsmodules"
+
+	"github.com/dop251/goja"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultItBatch is how many documents `it` shows when the engine has no page
+// size, matching mongosh's default batch.
+const defaultItBatch = 20
+
+// shellState is what the shell commands remember between statements.
+type shellState struct {
+	// lastCursor is the most recent find() cursor, which `it` continues.
+	lastCursor *lazyCursor
+	// itSource and itBatches track how far `it` has paged through lastCursor.
+	itSource  *lazyCursor
+	itBatches int64
+}
+
+// scriptQuit is the interrupt value quit() stops the script with, so
+// ExecuteQuery can tell a deliberate exit from a cancelled one.
+type scriptQuit struct {
+	code int64
+}
+
+// registerShellHelpers installs the mongosh shell commands and utility
+// globals: the tags `use` and `show` are rewritten to (see
+// rewriteTopLevelDeclarations), `it`, sleep, cat, ls, pwd, isInteractive and
+// quit. File helpers resolve against baseDir and are held to the sandbox.
+func registerShellHelpers(ec *execContext, baseDir string, sandbox jsmodules.Sandbox) error {
+	rt := ec.rt
+	global := rt.GlobalObject()
+
+	// The rewrite targets are hidden so they don't turn up when a script
+	// lists its globals.
+	if err := global.DefineDataProperty("$u", rt.ToValue(shellUse(ec)), goja.FLAG_TRUE, goja.FLAG_TRUE, goja.FLAG_FALSE); err != nil {
+		return fmt.Errorf("failed to set use command: %w", err)
+	}
+	if err := global.DefineDataProperty("$sh", rt.ToValue(shellShow(ec)), goja.FLAG_TRUE, goja.FLAG_TRUE, goja.FLAG_FALSE); err != nil {
+		return fmt.Errorf("failed to set show command: %w", err)
+	}
+
+	// it is a getter so a bare `it` runs; assigning to it makes it an
+	// ordinary variable, since scripts written for plain JS may use the name.
+	getter := rt.ToValue(func(goja.FunctionCall) goja.Value { return shellIt(ec) })
+	setter := rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		_ = global.DefineDataProperty("it", call.Argument(0), goja.FLAG_TRUE, goja.FLAG_TRUE, goja.FLAG_TRUE)
+		return goja.Undefined()
+	})
+	if err := global.DefineAccessorProperty("it", getter, setter, goja.FLAG_TRUE, goja.FLAG_FALSE); err != nil {
+		return fmt.Errorf("failed to set it: %w", err)
+	}
+
+	helpers := map[string]any{
+		"sleep": func(ms int64) {
+			timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ec.ctx.Done():
+				panic(rt.NewGoError(fmt.Errorf("sleep: %w", ec.ctx.Err())))
+			}
+		},
+		"cat": func(path string) string {
+			path = resolveShellPath(baseDir, path)
+			if err := sandbox.CheckRead("open", path); err != nil {
+				panic(rt.NewGoError(fmt.Errorf("cat: %w", err)))
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				panic(rt.NewGoError(fmt.Errorf("cat: %w", err)))
+			}
+			return string(data)
+		},
+		"ls": func(call goja.FunctionCall) goja.Value {
+			dir := baseDir
+			if arg := call.Argument(0); !goja.IsUndefined(arg) {
+				dir = resolveShellPath(baseDir, arg.String())
+			}
+			if err := sandbox.CheckRead("scandir", dir); err != nil {
+				panic(rt.NewGoError(fmt.Errorf("ls: %w", err)))
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				panic(rt.NewGoError(fmt.Errorf("ls: %w", err)))
+			}
+			names := make([]any, len(entries))
+			for i, entry := range entries {
+				name := entry.Name()
+				if entry.IsDir() {
+					name += "/"
+				}
+				names[i] = name
+			}
+			return rt.ToValue(names)
+		},
+		"pwd":           func() string { return baseDir },
+		"isInteractive": func() bool { return false },
+		"quit": func(call goja.FunctionCall) goja.Value {
+			rt.Interrupt(&scriptQuit{code: call.Argument(0).ToInteger()})
+			return goja.Undefined()
+		},
+	}
+	for name, fn := range helpers {
+		if err := rt.Set(name, fn); err != nil {
+			return fmt.Errorf("failed to set %s function: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveShellPath resolves a path given to cat or ls against baseDir.
+func resolveShellPath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// templateText returns the text of the tagged template a shell command was
+// rewritten to, without surrounding whitespace.
+func templateText(rt *goja.Runtime, call goja.FunctionCall) string {
+	return strings.TrimSpace(call.Argument(0).ToObject(rt).Get("0").String())
+}
+
+// shellUse is `use <db>`: it switches the script's db for every statement
+// that follows, as mongosh does.
+func shellUse(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		name := templateText(ec.rt, call)
+		if strings.ContainsAny(name, " \t") {
+			panic(ec.rt.NewGoError(fmt.Errorf("use takes a single database name")))
+		}
+		ec.dbName = name
+		return ec.rt.ToValue("switched to db " + name)
+	}
+}
+
+// shellShow is `show <what>`. It returns what mongosh would print as data, so
+// the result renders like any other query result.
+func shellShow(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		args := strings.Fields(templateText(ec.rt, call))
+		if len(args) == 0 {
+			panic(ec.rt.NewGoError(fmt.Errorf("show requires an argument")))
+		}
+		what := args[0]
+		show, ok := showTargets[what]
+		if !ok {
+			panic(ec.rt.NewGoError(fmt.Errorf("show: don't know how to show %q", what)))
+		}
+		requireClient(ec)
+
+		result, err := show(ec, args[1:])
+		if err != nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("show %s: %w", what, err)))
+		}
+		return toJSValue(ec.rt, result)
+	}
+}
+
+// showTargets maps each `show` argument to what it lists. The rest of the
+// command's words are passed on, for `show log <type>`.
+var showTargets = map[string]func(ec *execContext, args []string) (any, error){
+	"dbs":         showDatabases,
+	"databases":   showDatabases,
+	"collections": showCollections,
+	"tables":      showCollections,
+	"users": func(ec *execContext, _ []string) (any, error) {
+		return showCommandList(ec, ec.dbName, bson.D{{Key: "usersInfo", Value: 1}}, "users")
+	},
+	"roles": func(ec *execContext, _ []string) (any, error) {
+		return showCommandList(ec, ec.dbName, bson.D{
+			{Key: "rolesInfo", Value: 1},
+			{Key: "showBuiltinRoles", Value: true},
+		}, "roles")
+	},
+	"profile": showProfile,
+	"log": func(ec *execContext, args []string) (any, error) {
+		logType := "global"
+		if len(args) > 0 {
+			logType = args[0]
+		}
+		return showCommandList(ec, "admin", bson.D{{Key: "getLog", Value: logType}}, "log")
+	},
+}
+
+func showDatabases(ec *execContext, _ []string) (any, error) {
+	res, err := ec.client.ListDatabases(ec.ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, len(res.Databases))
+	for i, spec := range res.Databases {
+		out[i] = bson.M{"name": spec.Name, "sizeOnDisk": spec.SizeOnDisk, "empty": spec.Empty}
+	}
+	return out, nil
+}
+
+func showCollections(ec *execContext, _ []string) (any, error) {
+	names, err := ec.client.Database(ec.dbName).ListCollectionNames(ec.ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	out := make([]any, len(names))
+	for i, name := range names {
+		out[i] = name
+	}
+	return out, nil
+}
+
+// showCommandList runs cmd against dbName and returns the array it reports
+// under field.
+func showCommandList(ec *execContext, dbName string, cmd bson.D, field string) (any, error) {
+	var result bson.M
+	if err := ec.client.Database(dbName).RunCommand(ec.ctx, cmd).Decode(&result); err != nil {
+		return nil, err
+	}
+	if list, ok := result[field]; ok {
+		return list, nil
+	}
+	return bson.A{}, nil
+}
+
+// showProfile returns the five most recent profiler entries, newest first.
+func showProfile(ec *execContext, _ []string) (any, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "$natural", Value: -1}}).SetLimit(5)
+	cursor, err := ec.client.Database(ec.dbName).Collection("system.profile").Find(ec.ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ec.ctx, &docs); err != nil {
+		return nil, err
+	}
+	out := make([]any, len(docs))
+	for i, doc := range docs {
+		out[i] = doc
+	}
+	return out, nil
+}
+
+// shellIt returns the next batch of the last find() cursor. The find itself
+// showed the first batch, so the first `it` after it starts at the second.
+func shellIt(ec *execContext) goja.Value {
+	state := ec.shell
+	if state == nil || state.lastCursor == nil {
+		panic(ec.rt.NewGoError(fmt.Errorf("no cursor")))
+	}
+	if state.itSource != state.lastCursor {
+		state.itSource = state.lastCursor
+		state.itBatches = 0
+	}
+	state.itBatches++
+
+	batch := ec.pageSize
+	if batch <= 0 {
+		batch = defaultItBatch
+	}
+	next := state.lastCursor.nextBatch(state.itBatches*batch, batch)
+	if next == nil {
+		panic(ec.rt.NewGoError(fmt.Errorf("no cursor")))
+	}
+	return next.toGojaObject()
+}
@@ -0,0 +1,173 @@
+package queryengine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vervet/internal/models"
+	"vervet/internal/queryengine/jsmodules"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupShellRuntime is setupRuntime with the shell helpers installed, rooted
+// at baseDir.
+func setupShellRuntime(t *testing.T, baseDir string, sandbox jsmodules.Sandbox) (*goja.Runtime, *execContext) {
+	t.Helper()
+	rt, ec := setupRuntime(t)
+	ec.shell = &shellState{}
+	require.NoError(t, registerShellHelpers(ec, baseDir, sandbox))
+	return rt, ec
+}
+
+func TestShellUse_SwitchesDatabaseForLaterStatements(t *testing.T) {
+	rt, ec := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	val, err := rt.RunString(rewriteTopLevelDeclarations("use reporting\ndb.getName()"))
+	require.NoError(t, err)
+	assert.Equal(t, "reporting", val.Export())
+	assert.Equal(t, "reporting", ec.dbName)
+}
+
+func TestShellUse_WorksInTypeScript(t *testing.T) {
+	dir := t.TempDir()
+	rt, ec := setupShellRuntime(t, dir, jsmodules.Sandbox{})
+	src, err := prepareScript(filepath.Join(dir, "main.ts"), dir, "use reporting\nconst name: string = db.getName()\nname", jsmodules.Sandbox{})
+	require.NoError(t, err)
+
+	val, err := rt.RunString(src)
+	require.NoError(t, err)
+	assert.Equal(t, "reporting", val.Export())
+	assert.Equal(t, "reporting", ec.dbName)
+}
+
+func TestShellUse_ReturnsConfirmation(t *testing.T) {
+	rt, _ := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	val, err := rt.RunString(rewriteTopLevelDeclarations("use reporting"))
+	require.NoError(t, err)
+	assert.Equal(t, "switched to db reporting", val.Export())
+}
+
+func TestShellShow_UnknownTargetIsError(t *testing.T) {
+	rt, _ := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	_, err := rt.RunString(rewriteTopLevelDeclarations("show nonsense"))
+	require.ErrorContains(t, err, `don't know how to show "nonsense"`)
+}
+
+func TestShellIt_ContinuesLastFind(t *testing.T) {
+	rt, ec := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	ec.pageSize = 10
+
+	_, err := rt.RunString(`db.users.find({ active: true }).skip(5)`)
+	require.NoError(t, err)
+
+	val, err := rt.RunString(`it`)
+	require.NoError(t, err)
+	cursor := extractLazyCursor(val)
+	require.NotNil(t, cursor)
+	assert.Equal(t, "users", cursor.collection)
+	assert.Equal(t, int64(15), cursor.skip)
+	assert.Equal(t, int64(10), cursor.limit)
+
+	val, err = rt.RunString(`it`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), extractLazyCursor(val).skip)
+
+	// A new find starts paging over.
+	_, err = rt.RunString(`db.orders.find()`)
+	require.NoError(t, err)
+	val, err = rt.RunString(`it`)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", extractLazyCursor(val).collection)
+	assert.Equal(t, int64(10), extractLazyCursor(val).skip)
+}
+
+func TestShellIt_StopsAtCursorLimit(t *testing.T) {
+	rt, _ := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+
+	_, err := rt.RunString(`db.users.find().limit(30)`)
+	require.NoError(t, err)
+
+	val, err := rt.RunString(`it`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), extractLazyCursor(val).skip)
+	assert.Equal(t, int64(10), extractLazyCursor(val).limit)
+
+	_, err = rt.RunString(`it`)
+	require.ErrorContains(t, err, "no cursor")
+}
+
+func TestShellIt_WithoutCursorIsError(t *testing.T) {
+	rt, _ := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	_, err := rt.RunString(`it`)
+	require.ErrorContains(t, err, "no cursor")
+}
+
+func TestShellIt_AssignmentMakesOrdinaryVariable(t *testing.T) {
+	rt, _ := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	val, err := rt.RunString(`it = 3; it + 1`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), val.Export())
+}
+
+func TestShellFileHelpers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	rt, _ := setupShellRuntime(t, dir, jsmodules.Sandbox{})
+
+	val, err := rt.RunString(`cat('notes.txt')`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", val.Export())
+
+	val, err = rt.RunString(`ls()`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"notes.txt", "sub/"}, val.Export())
+
+	val, err = rt.RunString(`pwd()`)
+	require.NoError(t, err)
+	assert.Equal(t, dir, val.Export())
+
+	val, err = rt.RunString(`isInteractive()`)
+	require.NoError(t, err)
+	assert.Equal(t, false, val.Export())
+}
+
+func TestShellFileHelpers_HeldToSandbox(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("x"), 0o644))
+	rt, _ := setupShellRuntime(t, dir, jsmodules.Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{dir}})
+
+	_, err := rt.RunString(`cat(` + "`" + filepath.Join(outside, "secret.txt") + "`" + `)`)
+	require.ErrorContains(t, err, "permission denied")
+
+	_, err = rt.RunString(`ls(` + "`" + outside + "`" + `)`)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestShellSleep_StopsWhenCancelled(t *testing.T) {
+	rt, ec := setupShellRuntime(t, t.TempDir(), jsmodules.Sandbox{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ec.ctx = ctx
+
+	_, err := rt.RunString(`sleep(60000)`)
+	require.ErrorContains(t, err, "context canceled")
+}
+
+func TestExecuteQuery_QuitEndsScriptWithOutput(t *testing.T) {
+	eng := NewGojaEngine(nil, 100, "")
+	result, err := eng.ExecuteQuery(context.Background(), "", "testdb", "print('before');\nquit();\nprint('after');")
+	require.NoError(t, err)
+	assert.Equal(t, "before", result.RawOutput)
+}
+
+func TestExecuteQuery_QuitWithCodeIsError(t *testing.T) {
+	eng := NewGojaEngine(nil, 100, "")
+	_, err := eng.ExecuteQuery(context.Background(), "", "testdb", "quit(2)")
+	require.ErrorContains(t, err, "exit code 2")
+}
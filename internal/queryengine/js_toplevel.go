@@ -11,9 +11,28 @@ package queryengine
 // side still sees the original value. Declarations inside functions, blocks and
 // loop headers are left alone, so block scoping is unaffected.
 //
-// The replacement is length-preserving ("const" -> "var  ", "let" -> "var")
+// The same pass turns the mongosh shell commands `use <db>` and
+// `show <what>` into calls, since neither is valid JavaScript. Each becomes a
+// tagged template whose tag is one character shorter than the keyword, which
+// leaves room for the backticks: `use reporting` -> "$u`reporting`",
+// `show collections` -> "$sh`collections`". A keyword followed by anything
+// other than plain arguments on the rest of the line (`use = 5`, `show(x)`) is
+// ordinary JavaScript and is left alone.
+//
+// Every replacement is length-preserving ("const" -> "var  ", "let" -> "var")
 // so error line and column numbers still point at the user's source.
 func rewriteTopLevelDeclarations(src string) string {
+	return rewriteTopLevel(src, true)
+}
+
+// rewriteShellCommands applies only the `use` and `show` rewrite. Scripts
+// that go through esbuild get it before they are transpiled, since esbuild
+// can't parse the commands and its bundling already hoists declarations.
+func rewriteShellCommands(src string) string {
+	return rewriteTopLevel(src, false)
+}
+
+func rewriteTopLevel(src string, declarations bool) string {
 	out := []byte(src)
 	depth := 0
 	atStatementStart := true
@@ -56,7 +75,7 @@ func rewriteTopLevelDeclarations(src string) string {
 		}
 
 		if depth == 0 && atStatementStart {
-			if kw := declarationKeywordAt(out, i); kw != 0 {
+			if kw := declarationKeywordAt(out, i); declarations && kw != 0 {
 				copy(out[i:], "var")
 				for pad := 3; pad < kw; pad++ {
 					out[i+pad] = ' '
@@ -65,6 +84,11 @@ func rewriteTopLevelDeclarations(src string) string {
 				atStatementStart = false
 				continue
 			}
+			if end := rewriteShellCommandAt(out, i); end != 0 {
+				i = end
+				atStatementStart = false
+				continue
+			}
 		}
 
 		switch c {
@@ -104,6 +128,80 @@ func declarationKeywordAt(src []byte, i int) int {
 	return 0
 }
 
+// shellCommandTags maps each shell command to the global its rewrite calls.
+var shellCommandTags = map[string]string{
+	"use":  "$u",
+	"show": "$sh",
+}
+
+// rewriteShellCommandAt rewrites a `use` or `show` command starting at i in
+// place and returns the index just past it, or 0 when there is no command
+// there. A command is the keyword, one or more whitespace-separated argument
+// words, and then only an optional semicolon and comment before the line ends.
+func rewriteShellCommandAt(src []byte, i int) int {
+	for kw, tag := range shellCommandTags {
+		end := i + len(kw)
+		if end >= len(src) || string(src[i:end]) != kw || (src[end] != ' ' && src[end] != '\t') {
+			continue
+		}
+
+		// Arguments run to the last word before the line's trailing
+		// semicolon, comment or end.
+		j := end
+		argsEnd := 0
+		for j < len(src) {
+			for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+				j++
+			}
+			start := j
+			for j < len(src) && isShellArgByte(src[j]) {
+				j++
+			}
+			if j == start {
+				break
+			}
+			argsEnd = j
+		}
+		if argsEnd == 0 || !atLineEnd(src, j) {
+			continue
+		}
+
+		// The tag and opening backtick take the keyword's place, the
+		// arguments shift left over the first space, and the closing
+		// backtick fills the gap that leaves at the end.
+		copy(src[i:], tag+"`")
+		copy(src[end:argsEnd-1], src[end+1:argsEnd])
+		src[argsEnd-1] = '`'
+		return argsEnd
+	}
+	return 0
+}
+
+// isShellArgByte reports whether c can appear in a shell command argument:
+// database and collection names, and words like "dbs" or "startupWarnings".
+func isShellArgByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return c == '_' || c == '-' || c == '.' || c == '$'
+}
+
+// atLineEnd reports whether only whitespace, an optional semicolon and an
+// optional line comment remain between i and the end of the line.
+func atLineEnd(src []byte, i int) bool {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\r') {
+		i++
+	}
+	if i < len(src) && src[i] == ';' {
+		i++
+		for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\r') {
+			i++
+		}
+	}
+	return i == len(src) || src[i] == '\n' || (src[i] == '/' && i+1 < len(src) && src[i+1] == '/')
+}
+
 func isJSSpace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
 }
@@ -54,3 +54,43 @@ func TestRewriteTopLevel_AllowsSelfReferentialGlobalRebind(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "sibling:other", val.Export())
 }
+
+func TestRewriteTopLevel_ShellCommandsBecomeTaggedTemplates(t *testing.T) {
+	cases := map[string]string{
+		"use reporting":          "$u`reporting`",
+		"use reporting;":         "$u`reporting`;",
+		"show dbs // list them":  "$sh`dbs` // list them",
+		"show log global":        "$sh`log global`",
+		"show collections\nx();": "$sh`collections`\nx();",
+		"use   spaced":           "$u`  spaced`",
+	}
+	for src, want := range cases {
+		t.Run(src, func(t *testing.T) {
+			got := rewriteTopLevelDeclarations(src)
+			assert.Equal(t, want, got)
+			assert.Len(t, got, len(src))
+		})
+	}
+}
+
+func TestRewriteShellCommands_LeavesDeclarationsAlone(t *testing.T) {
+	src := "const a = 1\nuse reporting\nlet b: number = a"
+	assert.Equal(t, "const a = 1\n$u`reporting`\nlet b: number = a", rewriteShellCommands(src))
+}
+
+func TestRewriteTopLevel_LeavesShellKeywordsUsedAsJavaScriptAlone(t *testing.T) {
+	cases := map[string]string{
+		"assignment":     "use = 5;",
+		"call":           "show(x);",
+		"member":         "use.x = 1;",
+		"more after arg": "use db; print(1)",
+		"nested":         "function f() {\n  use other\n}",
+		"no argument":    "show;",
+		"in a string":    "print('use other');",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, src, rewriteTopLevelDeclarations(src))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package queryengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"vervet/internal/queryengine/jsmodules"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// typeScriptExtensions are the script extensions that are always compiled as
+// TypeScript before they run.
+var typeScriptExtensions = map[string]bool{".ts": true, ".mts": true, ".cts": true}
+
+// moduleShim gives a bundled entry script the `module` and `exports` bindings
+// esbuild's CommonJS output assigns to when the script itself exports names.
+const moduleShim = "var module = { exports: {} }, exports = module.exports;"
+
+// moduleKeyword finds the words ES module syntax can't do without. Source
+// without any of them is a plain script, which spares it a pass through
+// esbuild just to find that out.
+var moduleKeyword = regexp.MustCompile(`\b(import|export|await)\b`)
+
+func isTypeScript(scriptPath string) bool {
+	return typeScriptExtensions[strings.ToLower(filepath.Ext(scriptPath))]
+}
+
+// isESModule asks esbuild's parser whether src is an ES module: whether it
+// has import or export statements, import.meta or top-level await. Words in
+// strings and comments don't count, and neither does dynamic import(), an
+// ordinary expression goja can't run either way. Source esbuild can't parse
+// isn't treated as a module, so goja reports the error against the original
+// script.
+func isESModule(src string) bool {
+	if !moduleKeyword.MatchString(src) {
+		return false
+	}
+	result := api.Build(api.BuildOptions{
+		Stdin:    &api.StdinOptions{Contents: src, Loader: api.LoaderJS},
+		Metafile: true,
+		LogLevel: api.LogLevelSilent,
+	})
+	if len(result.Errors) > 0 {
+		return false
+	}
+	meta, ok := parseMetafile(result.Metafile)
+	if !ok {
+		return false
+	}
+	for _, in := range meta.Inputs {
+		if in.Format == "esm" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMeta is the part of esbuild's metafile that says which files were
+// ES modules.
+type buildMeta struct {
+	Inputs map[string]struct {
+		Format string `json:"format"`
+	} `json:"inputs"`
+	Outputs map[string]struct {
+		EntryPoint string `json:"entryPoint"`
+	} `json:"outputs"`
+}
+
+func parseMetafile(metafile string) (buildMeta, bool) {
+	var meta buildMeta
+	if err := json.Unmarshal([]byte(metafile), &meta); err != nil {
+		return buildMeta{}, false
+	}
+	return meta, true
+}
+
+// entryIsModule reports whether a bundle's entry script, rather than
+// anything it pulled in, is an ES module.
+func (m buildMeta) entryIsModule() bool {
+	for _, out := range m.Outputs {
+		if m.Inputs[out.EntryPoint].Format == "esm" {
+			return true
+		}
+	}
+	return false
+}
+
+// transpiled is a script as esbuild bundled it.
+type transpiled struct {
+	code   string
+	module bool // the entry script is an ES module
+}
+
+// transpileScript compiles a TypeScript or ES module script, and everything it
+// imports from disk, into one script goja can run.
+//
+// The output is CommonJS so the Node built-ins stay external and reach the
+// runtime's own require(), and tree shaking is off because a script's final
+// expression is its result even though nothing "uses" it. Bundling hoists the
+// script's top-level const/let to var, which keeps mongosh's rebinding of
+// globals such as db working. An inline source map lets goja report error
+// positions against the original files rather than the bundle. Every file
+// pulled in is checked against the sandbox first. The metafile says whether
+// the script turned out to be a module.
+func transpileScript(scriptPath, baseDir, src string, sandbox jsmodules.Sandbox) (transpiled, error) {
+	loader := api.LoaderJS
+	if isTypeScript(scriptPath) {
+		loader = api.LoaderTS
+	}
+
+	sourceFile := scriptPath
+	if sourceFile == "" {
+		sourceFile = "<script>"
+	}
+
+	result := api.Build(api.BuildOptions{
+		Stdin: &api.StdinOptions{
+			Contents:   src,
+			Sourcefile: sourceFile,
+			ResolveDir: baseDir,
+			Loader:     loader,
+		},
+		Bundle:        true,
+		Format:        api.FormatCommonJS,
+		Platform:      api.PlatformNeutral,
+		MainFields:    []string{"main", "module"},
+		External:      append(jsmodules.Builtins(), "node:*"),
+		Target:        api.ES2020,
+		TreeShaking:   api.TreeShakingFalse,
+		Sourcemap:     api.SourceMapInline,
+		Banner:        map[string]string{"js": moduleShim},
+		AbsWorkingDir: baseDir,
+		Outfile:       filepath.Join(baseDir, "vervet-bundle.js"),
+		Metafile:      true,
+		LogLevel:      api.LogLevelSilent,
+		Plugins:       []api.Plugin{sandboxPlugin(sandbox)},
+	})
+
+	if len(result.Errors) > 0 {
+		return transpiled{}, transpileError(result.Errors)
+	}
+	if len(result.OutputFiles) == 0 {
+		return transpiled{}, errors.New("transpile produced no output")
+	}
+
+	meta, _ := parseMetafile(result.Metafile)
+	return transpiled{code: string(result.OutputFiles[0].Contents), module: meta.entryIsModule()}, nil
+}
+
+// sandboxPlugin refuses to bundle a file the script isn't allowed to read.
+// Returning no contents hands an allowed file back to esbuild's own loader.
+func sandboxPlugin(sandbox jsmodules.Sandbox) api.Plugin {
+	return api.Plugin{
+		Name: "vervet-sandbox",
+		Setup: func(build api.PluginBuild) {
+			build.OnLoad(api.OnLoadOptions{Filter: ".*", Namespace: "file"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				return api.OnLoadResult{}, sandbox.CheckRead("open", args.Path)
+			})
+		},
+	}
+}
+
+// transpileError reports esbuild's messages with 1-based line and column
+// numbers, the way goja reports positions for plain scripts.
+func transpileError(msgs []api.Message) error {
+	lines := make([]string, len(msgs))
+	for i, m := range msgs {
+		if m.Location == nil {
+			lines[i] = m.Text
+			continue
+		}
+		lines[i] = fmt.Sprintf("%s:%d:%d: %s", m.Location.File, m.Location.Line, m.Location.Column+1, m.Text)
+	}
+	return errors.New(strings.Join(lines, "\n"))
+}
+
+// prepareScript turns a script's source into what goja runs: the bundle for
+// TypeScript and ES module scripts, otherwise the source with the top-level
+// declaration rewrite applied. The shell command rewrite applies to both.
+//
+// A plain script that might be a module is bundled straight away, and the
+// bundle is only kept if it was one, so a module goes through esbuild once.
+// When bundling fails, the errors are only the script's if it is a module;
+// a plain script runs as it is and goja reports its own errors.
+func prepareScript(scriptPath, baseDir, src string, sandbox jsmodules.Sandbox) (string, error) {
+	src = rewriteShellCommands(src)
+	ts := isTypeScript(scriptPath)
+	if !ts && !moduleKeyword.MatchString(src) {
+		return rewriteTopLevelDeclarations(src), nil
+	}
+	bundle, err := transpileScript(scriptPath, baseDir, src, sandbox)
+	switch {
+	case ts:
+		return bundle.code, err
+	case err != nil:
+		if isESModule(src) {
+			return "", err
+		}
+	case bundle.module:
+		return bundle.code, nil
+	}
+	return rewriteTopLevelDeclarations(src), nil
+}
@@ -0,0 +1,191 @@
+package queryengine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"vervet/internal/queryengine/jsmodules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsESModule(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"plain script", "db.users.find()", false},
+		{"unsaved plain script", "const x = 1\nx", false},
+		{"named import", "import { a } from './lib'\na", true},
+		{"default import", "import lib from './lib'", true},
+		{"side-effect import", "import './setup'", true},
+		{"indented export", "  export const a = 1", true},
+		{"top-level await", "await db.users.find().toArray()", true},
+		{"await in a function", "async function f() { await 1 }\nf()", false},
+		{"dynamic import", "import('./lib')", false},
+		{"import in identifier", "const importer = 1", false},
+		{"import in a string", "const s = 'import x from \"y\"'\ns", false},
+		{"export in a comment", "// export const a = 1\n1", false},
+		{"import in a block comment", "/*\nimport { a } from './lib'\n*/\ndb.users.find()", false},
+		{"import.meta", "import.meta.url", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isESModule(tt.src))
+		})
+	}
+}
+
+func TestIsTypeScript(t *testing.T) {
+	assert.True(t, isTypeScript("/w/main.ts"))
+	assert.True(t, isTypeScript("/w/main.MTS"))
+	assert.False(t, isTypeScript("/w/main.js"))
+	assert.False(t, isTypeScript(""))
+}
+
+func TestPrepareScript(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "lib.js", `export const a = 1;`)
+	main := filepath.Join(dir, "main.js")
+
+	t.Run("module is bundled", func(t *testing.T) {
+		out, err := prepareScript(main, dir, "import { a } from './lib'\na", jsmodules.Sandbox{})
+		require.NoError(t, err)
+		assert.Contains(t, out, moduleShim)
+	})
+
+	t.Run("plain script mentioning import is not", func(t *testing.T) {
+		// It requires a module, but only the entry decides.
+		src := "// import\nconst lib = require('./lib')\nlib.a"
+		out, err := prepareScript(main, dir, src, jsmodules.Sandbox{})
+		require.NoError(t, err)
+		assert.NotContains(t, out, moduleShim)
+		assert.Contains(t, out, "require('./lib')")
+	})
+
+	t.Run("plain script that can't be bundled runs as it is", func(t *testing.T) {
+		src := "// import\nrequire('./missing')"
+		out, err := prepareScript(main, dir, src, jsmodules.Sandbox{})
+		require.NoError(t, err)
+		assert.Contains(t, out, "require('./missing')")
+	})
+
+	t.Run("module that can't be bundled fails", func(t *testing.T) {
+		_, err := prepareScript(main, dir, "import { x } from './missing'; x", jsmodules.Sandbox{})
+		assert.ErrorContains(t, err, "./missing")
+	})
+}
+
+func TestTranspileScript_SyntaxErrorReportsOriginalPosition(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.ts")
+
+	_, err := transpileScript(main, dir, "const a: number = 1\nconst b = (\n", jsmodules.Sandbox{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main.ts:3:1:")
+}
+
+func TestGojaEngine_TypeScript_RunsWithTypes(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.ts")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		interface Row { name: string; qty: number }
+		const rows: Row[] = [{ name: 'a', qty: 2 }, { name: 'b', qty: 3 }];
+		const total = (rs: Row[]): number => rs.reduce((n, r) => n + r.qty, 0);
+		total(rows)
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "5", res.RawOutput)
+}
+
+func TestGojaEngine_TypeScript_ImportsLocalModules(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "helpers.ts", `export function label(n: number): string { return 'n=' + n; }`)
+	writeScript(t, dir, "legacy.js", `module.exports = { base: 40 };`)
+	main := filepath.Join(dir, "main.ts")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		import { label } from './helpers';
+		import legacy from './legacy';
+		label(legacy.base + 2)
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "n=42", res.RawOutput)
+}
+
+// Node built-ins are left to the runtime's require() rather than bundled.
+func TestGojaEngine_ESModule_ImportsBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		import path from 'path';
+		import { createHash } from 'node:crypto';
+		[path.basename('/a/b.txt'), createHash('md5').update('x').digest('hex').length]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"b.txt", int64(32)}, res.Documents)
+}
+
+// Exports from the top-level script are allowed and don't displace its result.
+func TestGojaEngine_ESModule_EntryExports(t *testing.T) {
+	eng := NewGojaEngine(nil, 100, filepath.Join(t.TempDir(), "main.mjs"))
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		export const answer = 42;
+		answer
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "42", res.RawOutput)
+}
+
+// A bundled script still rebinds db the way mongosh scripts expect.
+func TestGojaEngine_TypeScript_RebindsDb(t *testing.T) {
+	eng := NewGojaEngine(nil, 100, filepath.Join(t.TempDir(), "main.ts"))
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		const db = db.getSiblingDB('other');
+		db.getName()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "other", res.RawOutput)
+}
+
+// Runtime errors point at the line in the original file, not the bundle.
+func TestGojaEngine_TypeScript_ErrorMapsToSource(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.ts")
+
+	eng := NewGojaEngine(nil, 100, main)
+	_, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		"const n: number = 1;\n\nundefinedFn(n);\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undefinedFn is not defined")
+	assert.Contains(t, err.Error(), "main.ts:3:")
+}
+
+func TestGojaEngine_TypeScript_UnresolvedImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.ts")
+
+	eng := NewGojaEngine(nil, 100, main)
+	_, err := eng.ExecuteQuery(context.Background(), "", "testdb", `import { x } from './missing'; x`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "script error:")
+	assert.Contains(t, err.Error(), "./missing")
+}
+
+func TestGojaEngine_Load_TypeScriptHelper(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "helper.ts", `function typed(n: number): number { return n + 1; }`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `load('helper.ts'); typed(1)`)
+	require.NoError(t, err)
+	assert.Equal(t, "2", res.RawOutput)
+}
@@ -0,0 +1,417 @@
+package jsmodules
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// registerAssert registers assert and assert/strict. The strict flavour is
+// the same module with the loose comparisons (equal, deepEqual, ...) swapped
+// for their strict counterparts, as Node's is.
+func registerAssert(r *require.Registry) {
+	r.RegisterNativeModule("assert", func(rt *goja.Runtime, m *goja.Object) {
+		loose := newAssert(rt, false)
+		_ = loose.Set("strict", newAssert(rt, true))
+		_ = m.Set("exports", loose)
+	})
+	r.RegisterNativeModule("assert/strict", func(rt *goja.Runtime, m *goja.Object) {
+		_ = m.Set("exports", require.Require(rt, "assert").ToObject(rt).Get("strict"))
+	})
+}
+
+// assertionError builds the error every failed assertion throws: an Error
+// with code ERR_ASSERTION carrying the values that were compared.
+func assertionError(rt *goja.Runtime, msg goja.Value, fallback string, actual, expected goja.Value, operator string) *goja.Object {
+	generated := msg == nil || goja.IsUndefined(msg)
+	if !generated {
+		if obj, ok := msg.(*goja.Object); ok && obj.ClassName() == "Error" {
+			panic(obj)
+		}
+		fallback = msg.String()
+	}
+	err := nodeError(rt, "ERR_ASSERTION", fallback)
+	_ = err.Set("name", "AssertionError")
+	_ = err.Set("actual", actual)
+	_ = err.Set("expected", expected)
+	_ = err.Set("operator", operator)
+	_ = err.Set("generatedMessage", generated)
+	return err
+}
+
+func newAssert(rt *goja.Runtime, strict bool) *goja.Object {
+	inspectValue := func(v goja.Value) string {
+		return inspect(rt, v, inspectOptions{depth: 2, breakLength: math.MaxInt})
+	}
+
+	ok := func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(assertionError(rt, nil, "No value argument passed to `assert.ok()`", goja.Undefined(), goja.Undefined(), "=="))
+		}
+		if !call.Argument(0).ToBoolean() {
+			panic(assertionError(rt, call.Argument(1), "The expression evaluated to a falsy value: "+inspectValue(call.Argument(0)),
+				call.Argument(0), rt.ToValue(true), "=="))
+		}
+		return goja.Undefined()
+	}
+	a := rt.ToValue(ok).ToObject(rt)
+	_ = a.Set("ok", ok)
+
+	// compare registers an assertion that passes when pass(actual, expected)
+	// does; a failure reads "Expected values to be <desc>:" followed by the
+	// two values either side of the JS operator the check stands for.
+	compare := func(name, desc, symbol string, pass func(actual, expected goja.Value) bool) {
+		_ = a.Set(name, func(call goja.FunctionCall) goja.Value {
+			actual, expected := call.Argument(0), call.Argument(1)
+			if !pass(actual, expected) {
+				panic(assertionError(rt, call.Argument(2),
+					"Expected values to be "+desc+":\n\n"+inspectValue(actual)+" "+symbol+" "+inspectValue(expected),
+					actual, expected, name))
+			}
+			return goja.Undefined()
+		})
+	}
+
+	looseEqual := func(x, y goja.Value) bool { return x.Equals(y) || (goja.IsNaN(x) && goja.IsNaN(y)) }
+	strictEqual := func(x, y goja.Value) bool { return x.SameAs(y) }
+	looseDeep := func(x, y goja.Value) bool { return deepEqual(rt, x, y, false) }
+	strictDeep := func(x, y goja.Value) bool { return deepEqual(rt, x, y, true) }
+	not := func(f func(x, y goja.Value) bool) func(x, y goja.Value) bool {
+		return func(x, y goja.Value) bool { return !f(x, y) }
+	}
+
+	compare("strictEqual", "strictly equal", "!==", strictEqual)
+	compare("notStrictEqual", "not strictly equal", "===", not(strictEqual))
+	compare("deepStrictEqual", "deeply strictly equal", "!==", strictDeep)
+	compare("notDeepStrictEqual", "not deeply strictly equal", "===", not(strictDeep))
+	if strict {
+		compare("equal", "strictly equal", "!==", strictEqual)
+		compare("notEqual", "not strictly equal", "===", not(strictEqual))
+		compare("deepEqual", "deeply strictly equal", "!==", strictDeep)
+		compare("notDeepEqual", "not deeply strictly equal", "===", not(strictDeep))
+	} else {
+		compare("equal", "loosely equal", "!=", looseEqual)
+		compare("notEqual", "not loosely equal", "==", not(looseEqual))
+		compare("deepEqual", "deeply loosely equal", "!=", looseDeep)
+		compare("notDeepEqual", "not deeply loosely equal", "==", not(looseDeep))
+	}
+
+	_ = a.Set("fail", func(call goja.FunctionCall) goja.Value {
+		panic(assertionError(rt, call.Argument(0), "Failed", goja.Undefined(), goja.Undefined(), "fail"))
+	})
+
+	_ = a.Set("ifError", func(call goja.FunctionCall) goja.Value {
+		v := call.Argument(0)
+		if goja.IsUndefined(v) || goja.IsNull(v) {
+			return goja.Undefined()
+		}
+		if obj, ok := v.(*goja.Object); ok && obj.ClassName() == "Error" {
+			panic(obj)
+		}
+		panic(assertionError(rt, nil, "ifError got unwanted exception: "+inspectValue(v), v, goja.Null(), "ifError"))
+	})
+
+	match := func(name string, want bool) {
+		_ = a.Set(name, func(call goja.FunctionCall) goja.Value {
+			str, re := call.Argument(0), call.Argument(1)
+			reObj, ok := re.(*goja.Object)
+			if !ok || reObj.ClassName() != "RegExp" {
+				panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"regexp\" argument must be an instance of RegExp"))
+			}
+			test, _ := goja.AssertFunction(reObj.Get("test"))
+			res, err := test(reObj, str)
+			if err != nil {
+				panic(err)
+			}
+			if goja.IsString(str) && res.ToBoolean() == want {
+				return goja.Undefined()
+			}
+			verb := "match"
+			if !want {
+				verb = "not match"
+			}
+			panic(assertionError(rt, call.Argument(2),
+				"The input was expected to "+verb+" the regular expression "+inspectValue(re)+". Input:\n\n"+inspectValue(str),
+				str, re, name))
+		})
+	}
+	match("match", true)
+	match("doesNotMatch", false)
+
+	_ = a.Set("throws", func(call goja.FunctionCall) goja.Value {
+		fn, ok := goja.AssertFunction(call.Argument(0))
+		if !ok {
+			panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"fn\" argument must be of type function"))
+		}
+		expected, msg := call.Argument(1), call.Argument(2)
+		if goja.IsString(expected) && len(call.Arguments) < 3 {
+			expected, msg = goja.Undefined(), expected
+		}
+
+		_, err := fn(goja.Undefined())
+		if err == nil {
+			panic(assertionError(rt, msg, "Missing expected exception.", goja.Undefined(), expected, "throws"))
+		}
+		thrown := errorValue(rt, err)
+		if !goja.IsUndefined(expected) && !thrownMatches(rt, thrown, expected) {
+			panic(assertionError(rt, msg, "The error thrown did not match the expected value: "+inspectValue(thrown),
+				thrown, expected, "throws"))
+		}
+		return goja.Undefined()
+	})
+
+	_ = a.Set("doesNotThrow", func(call goja.FunctionCall) goja.Value {
+		fn, ok := goja.AssertFunction(call.Argument(0))
+		if !ok {
+			panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"fn\" argument must be of type function"))
+		}
+		msg := call.Argument(len(call.Arguments) - 1)
+		if len(call.Arguments) < 2 || !goja.IsString(msg) {
+			msg = goja.Undefined()
+		}
+		if _, err := fn(goja.Undefined()); err != nil {
+			thrown := errorValue(rt, err)
+			panic(assertionError(rt, msg, "Got unwanted exception.\nActual message: \""+errorMessage(thrown)+"\"",
+				thrown, goja.Undefined(), "doesNotThrow"))
+		}
+		return goja.Undefined()
+	})
+
+	return a
+}
+
+func errorMessage(v goja.Value) string {
+	if obj, ok := v.(*goja.Object); ok {
+		if msg := obj.Get("message"); msg != nil && !goja.IsUndefined(msg) {
+			return msg.String()
+		}
+	}
+	return v.String()
+}
+
+// thrownMatches checks a thrown value against assert.throws' expected
+// argument: a RegExp tested against the error's string form, an error class,
+// a validation function, or an object whose properties must all match.
+func thrownMatches(rt *goja.Runtime, thrown, expected goja.Value) bool {
+	exp, ok := expected.(*goja.Object)
+	if !ok {
+		return false
+	}
+	if exp.ClassName() == "RegExp" {
+		test, _ := goja.AssertFunction(exp.Get("test"))
+		res, err := test(exp, rt.ToValue(thrown.String()))
+		return err == nil && res.ToBoolean()
+	}
+	if fn, ok := goja.AssertFunction(exp); ok {
+		if proto, _ := exp.Get("prototype").(*goja.Object); proto != nil {
+			if rt.InstanceOf(thrown, exp) {
+				return true
+			}
+			if isErrorClass(rt, exp) {
+				return false
+			}
+		}
+		res, err := fn(goja.Undefined(), thrown)
+		return err == nil && res.ToBoolean()
+	}
+	thrownObj, ok := thrown.(*goja.Object)
+	if !ok {
+		return false
+	}
+	for _, k := range exp.Keys() {
+		want := exp.Get(k)
+		got := thrownObj.Get(k)
+		if got == nil {
+			got = goja.Undefined()
+		}
+		if wantObj, ok := want.(*goja.Object); ok && wantObj.ClassName() == "RegExp" && goja.IsString(got) {
+			test, _ := goja.AssertFunction(wantObj.Get("test"))
+			if res, err := test(wantObj, got); err == nil && res.ToBoolean() {
+				continue
+			}
+			return false
+		}
+		if !deepEqual(rt, got, want, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// isErrorClass reports whether ctor is Error or a subclass of it, which
+// assert.throws treats as an instanceof check rather than a validator.
+func isErrorClass(rt *goja.Runtime, ctor *goja.Object) bool {
+	errCtor, _ := rt.Get("Error").(*goja.Object)
+	proto, _ := ctor.Get("prototype").(*goja.Object)
+	return proto != nil && (ctor == errCtor || rt.InstanceOf(proto, errCtor))
+}
+
+// deepEqual implements the comparison behind assert.deepEqual (loose) and
+// assert.deepStrictEqual / util.isDeepStrictEqual (strict).
+func deepEqual(rt *goja.Runtime, a, b goja.Value, strict bool) bool {
+	c := &deepComparer{rt: rt, strict: strict, visiting: map[[2]*goja.Object]bool{}}
+	return c.equal(a, b)
+}
+
+type deepComparer struct {
+	rt       *goja.Runtime
+	strict   bool
+	visiting map[[2]*goja.Object]bool
+}
+
+func (c *deepComparer) equal(a, b goja.Value) bool {
+	ao, aIsObj := a.(*goja.Object)
+	bo, bIsObj := b.(*goja.Object)
+	if !aIsObj || !bIsObj {
+		if c.strict {
+			return a.SameAs(b)
+		}
+		if aIsObj || bIsObj {
+			return false
+		}
+		return a.Equals(b) || (goja.IsNaN(a) && goja.IsNaN(b))
+	}
+	if ao == bo {
+		return true
+	}
+
+	// A pair already being compared further up is assumed equal; if it
+	// isn't, the comparison in progress will say so.
+	pair := [2]*goja.Object{ao, bo}
+	if c.visiting[pair] {
+		return true
+	}
+	c.visiting[pair] = true
+	defer delete(c.visiting, pair)
+
+	if c.strict && ao.Prototype() != bo.Prototype() {
+		return false
+	}
+	class := classOf(c.rt, ao)
+	if class != classOf(c.rt, bo) {
+		return false
+	}
+
+	switch class {
+	case "Date":
+		if !ao.ToNumber().SameAs(bo.ToNumber()) {
+			return false
+		}
+	case "RegExp":
+		if ao.String() != bo.String() {
+			return false
+		}
+	case "Error":
+		if ao.Get("name").String() != bo.Get("name").String() || ao.Get("message").String() != bo.Get("message").String() {
+			return false
+		}
+	case "Map":
+		return c.mapsEqual(ao, bo)
+	case "Set":
+		return c.setsEqual(ao, bo)
+	case "Array":
+		if ao.Get("length").ToInteger() != bo.Get("length").ToInteger() {
+			return false
+		}
+	}
+
+	if ab, ok := ao.Export().([]byte); ok {
+		bb, ok := bo.Export().([]byte)
+		if !ok || !bytes.Equal(ab, bb) {
+			return false
+		}
+	}
+
+	return c.keysEqual(ao, bo)
+}
+
+func (c *deepComparer) keysEqual(a, b *goja.Object) bool {
+	ak, bk := a.Keys(), b.Keys()
+	if len(ak) != len(bk) {
+		return false
+	}
+	sort.Strings(ak)
+	sort.Strings(bk)
+	for i := range ak {
+		if ak[i] != bk[i] {
+			return false
+		}
+	}
+	for _, k := range ak {
+		if !c.equal(a.Get(k), b.Get(k)) {
+			return false
+		}
+	}
+	if c.strict {
+		as, bs := a.Symbols(), b.Symbols()
+		if len(as) != len(bs) {
+			return false
+		}
+		for _, sym := range as {
+			if !c.equal(a.GetSymbol(sym), b.GetSymbol(sym)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *deepComparer) entries(obj *goja.Object) [][2]goja.Value {
+	var out [][2]goja.Value
+	forEach, _ := goja.AssertFunction(obj.Get("forEach"))
+	_, err := forEach(obj, c.rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		out = append(out, [2]goja.Value{call.Argument(1), call.Argument(0)})
+		return goja.Undefined()
+	}))
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func (c *deepComparer) mapsEqual(a, b *goja.Object) bool {
+	ae, be := c.entries(a), c.entries(b)
+	if len(ae) != len(be) {
+		return false
+	}
+	used := make([]bool, len(be))
+	for _, x := range ae {
+		found := false
+		for i, y := range be {
+			if !used[i] && c.equal(x[0], y[0]) && c.equal(x[1], y[1]) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *deepComparer) setsEqual(a, b *goja.Object) bool {
+	ae, be := c.entries(a), c.entries(b)
+	if len(ae) != len(be) {
+		return false
+	}
+	used := make([]bool, len(be))
+	for _, x := range ae {
+		found := false
+		for i, y := range be {
+			if !used[i] && c.equal(x[1], y[1]) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssert_PassingChecksReturnUndefined(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const assert = require('assert');
+		assert(true);
+		assert.ok(1);
+		assert.strictEqual(1, 1);
+		assert.deepStrictEqual({a: [1, 2]}, {a: [1, 2]});
+		assert.notStrictEqual(1, 2);
+		assert.match('order-42', /\d+/);
+		assert.throws(() => { throw new TypeError('bad'); }, TypeError);
+		'ok'
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", val.Export())
+}
+
+func TestAssert_FailureIsAssertionError(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try {
+			require('assert').strictEqual(1, 2);
+		} catch (e) {
+			[e.name, e.code, e.actual, e.expected, e.operator].join(',');
+		}
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "AssertionError,ERR_ASSERTION,1,2,strictEqual", val.Export())
+}
+
+func TestAssert_CustomMessage(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try {
+			require('assert').ok(false, 'orders must have a total');
+		} catch (e) {
+			e.message;
+		}
+	`)
+	require.NoError(t, err)
+	assert.Contains(t, val.Export(), "orders must have a total")
+}
+
+func TestAssert_LooseVersusStrict(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const results = [];
+		const check = (fn) => { try { fn(); results.push('pass'); } catch (e) { results.push('fail'); } };
+		check(() => require('assert').equal(1, '1'));
+		check(() => require('assert/strict').equal(1, '1'));
+		check(() => require('assert').strict.equal(1, '1'));
+		results
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"pass", "fail", "fail"}, val.Export())
+}
+
+func TestAssert_DeepStrictEqualDetectsDifference(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try {
+			require('assert').deepStrictEqual({a: 1, b: [1, 2]}, {a: 1, b: [1, 3]});
+			'no-error';
+		} catch (e) {
+			e.code;
+		}
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_ASSERTION", val.Export())
+}
+
+func TestAssert_ThrowsWithoutThrowFails(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try {
+			require('assert').throws(() => {});
+			'no-error';
+		} catch (e) {
+			e.code;
+		}
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_ASSERTION", val.Export())
+}
+
+func TestAssert_UncaughtFailureFailsScript(t *testing.T) {
+	rt := newTestRuntime(t)
+	_, err := rt.RunString(`require('assert').strictEqual('a', 'b')`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ERR_ASSERTION")
+}
+
+func TestAssert_DeepStrictEqualMaps(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const util = require('util');
+		[
+			util.isDeepStrictEqual(new Map([['a', 1]]), new Map([['a', 1]])),
+			util.isDeepStrictEqual(new Map([['a', 1]]), new Map([['a', 2]])),
+			util.isDeepStrictEqual(new Set([1]), new Map()),
+		]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, false, false}, val.Export())
+}
@@ -0,0 +1,240 @@
+package jsmodules
+
+import (
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+const defaultMaxListeners = 10
+
+// listener is one registered handler. fn is what gets called; original is
+// what the script passed, so off() can find a once() wrapper by the function
+// the script knows about.
+type listener struct {
+	fn       goja.Callable
+	original goja.Value
+	once     bool
+}
+
+// emitterState is the per-instance listener table, kept in Go and attached to
+// the emitter under a private symbol so subclasses and Object.create-style
+// inheritance both find it.
+type emitterState struct {
+	names        []goja.Value
+	listeners    map[string][]listener
+	maxListeners int
+}
+
+func registerEvents(r *require.Registry) {
+	r.RegisterNativeModule("events", func(rt *goja.Runtime, m *goja.Object) {
+		ctor := newEventEmitterClass(rt)
+		_ = ctor.Set("EventEmitter", ctor)
+		_ = ctor.Set("defaultMaxListeners", defaultMaxListeners)
+
+		_ = ctor.Set("once", func(call goja.FunctionCall) goja.Value {
+			emitter := call.Argument(0).ToObject(rt)
+			name := call.Argument(1)
+			promise, resolve, reject := rt.NewPromise()
+			onFn, _ := goja.AssertFunction(emitter.Get("once"))
+			_, err := onFn(emitter, name, rt.ToValue(func(inner goja.FunctionCall) goja.Value {
+				_ = resolve(rt.NewArray(valuesToAny(inner.Arguments)...))
+				return goja.Undefined()
+			}))
+			if err != nil {
+				_ = reject(errorValue(rt, err))
+			}
+			return rt.ToValue(promise)
+		})
+
+		_ = m.Set("exports", ctor)
+	})
+}
+
+func valuesToAny(vals []goja.Value) []any {
+	out := make([]any, len(vals))
+	for i, v := range vals {
+		out[i] = v
+	}
+	return out
+}
+
+// newEventEmitterClass builds the EventEmitter constructor and prototype.
+// The constructor is a native one, so `class Job extends EventEmitter` and
+// util.inherits both work.
+func newEventEmitterClass(rt *goja.Runtime) *goja.Object {
+	stateKey := goja.NewSymbol("eventEmitterState")
+
+	state := func(this goja.Value) *emitterState {
+		obj := this.ToObject(rt)
+		if v := obj.GetSymbol(stateKey); v != nil {
+			if s, ok := v.Export().(*emitterState); ok {
+				return s
+			}
+		}
+		s := &emitterState{listeners: map[string][]listener{}, maxListeners: defaultMaxListeners}
+		_ = obj.DefineDataPropertySymbol(stateKey, rt.ToValue(s), goja.FLAG_FALSE, goja.FLAG_TRUE, goja.FLAG_FALSE)
+		return s
+	}
+
+	ctor := rt.ToValue(func(call goja.ConstructorCall) *goja.Object {
+		state(call.This)
+		return nil
+	}).ToObject(rt)
+	proto := ctor.Get("prototype").ToObject(rt)
+
+	add := func(call goja.FunctionCall, once, prepend bool) goja.Value {
+		s := state(call.This)
+		name := call.Argument(0)
+		fn, ok := goja.AssertFunction(call.Argument(1))
+		if !ok {
+			panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"listener\" argument must be of type function"))
+		}
+
+		// Node announces every new listener before adding it.
+		if len(s.listeners["newListener"]) > 0 {
+			emit(rt, s, call.This, rt.ToValue("newListener"), []goja.Value{name, call.Argument(1)})
+		}
+
+		key := name.String()
+		if _, ok := s.listeners[key]; !ok {
+			s.names = append(s.names, name)
+		}
+		l := listener{fn: fn, original: call.Argument(1), once: once}
+		if prepend {
+			s.listeners[key] = append([]listener{l}, s.listeners[key]...)
+		} else {
+			s.listeners[key] = append(s.listeners[key], l)
+		}
+		return call.This
+	}
+
+	on := func(call goja.FunctionCall) goja.Value { return add(call, false, false) }
+	_ = proto.Set("on", on)
+	_ = proto.Set("addListener", on)
+	_ = proto.Set("prependListener", func(call goja.FunctionCall) goja.Value { return add(call, false, true) })
+	_ = proto.Set("once", func(call goja.FunctionCall) goja.Value { return add(call, true, false) })
+	_ = proto.Set("prependOnceListener", func(call goja.FunctionCall) goja.Value { return add(call, true, true) })
+
+	off := func(call goja.FunctionCall) goja.Value {
+		s := state(call.This)
+		key := call.Argument(0).String()
+		ls := s.listeners[key]
+		// Node removes the most recently added match.
+		for i := len(ls) - 1; i >= 0; i-- {
+			if ls[i].original.SameAs(call.Argument(1)) {
+				s.remove(key, i)
+				if len(s.listeners["removeListener"]) > 0 {
+					emit(rt, s, call.This, rt.ToValue("removeListener"), []goja.Value{call.Argument(0), call.Argument(1)})
+				}
+				break
+			}
+		}
+		return call.This
+	}
+	_ = proto.Set("off", off)
+	_ = proto.Set("removeListener", off)
+
+	_ = proto.Set("removeAllListeners", func(call goja.FunctionCall) goja.Value {
+		s := state(call.This)
+		if len(call.Arguments) == 0 || goja.IsUndefined(call.Argument(0)) {
+			s.names = nil
+			s.listeners = map[string][]listener{}
+			return call.This
+		}
+		key := call.Argument(0).String()
+		for len(s.listeners[key]) > 0 {
+			s.remove(key, 0)
+		}
+		return call.This
+	})
+
+	_ = proto.Set("emit", func(call goja.FunctionCall) goja.Value {
+		var args []goja.Value
+		if len(call.Arguments) > 1 {
+			args = call.Arguments[1:]
+		}
+		return rt.ToValue(emit(rt, state(call.This), call.This, call.Argument(0), args))
+	})
+
+	listenersOf := func(call goja.FunctionCall) goja.Value {
+		ls := state(call.This).listeners[call.Argument(0).String()]
+		out := make([]any, len(ls))
+		for i, l := range ls {
+			out[i] = l.original
+		}
+		return rt.NewArray(out...)
+	}
+	_ = proto.Set("listeners", listenersOf)
+	_ = proto.Set("rawListeners", listenersOf)
+
+	_ = proto.Set("listenerCount", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(len(state(call.This).listeners[call.Argument(0).String()]))
+	})
+
+	_ = proto.Set("eventNames", func(call goja.FunctionCall) goja.Value {
+		return rt.NewArray(valuesToAny(state(call.This).names)...)
+	})
+
+	// The limit is kept so scripts can read back what they set; nothing is
+	// warned about, because there is nowhere useful to print the warning.
+	_ = proto.Set("setMaxListeners", func(call goja.FunctionCall) goja.Value {
+		state(call.This).maxListeners = int(call.Argument(0).ToInteger())
+		return call.This
+	})
+	_ = proto.Set("getMaxListeners", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(state(call.This).maxListeners)
+	})
+
+	return ctor
+}
+
+func (s *emitterState) remove(key string, i int) {
+	ls := s.listeners[key]
+	s.listeners[key] = append(ls[:i:i], ls[i+1:]...)
+	if len(s.listeners[key]) > 0 {
+		return
+	}
+	delete(s.listeners, key)
+	for j, n := range s.names {
+		if n.String() == key {
+			s.names = append(s.names[:j:j], s.names[j+1:]...)
+			break
+		}
+	}
+}
+
+// emit calls the listeners registered for name, in order, and reports
+// whether there were any. An "error" event nobody listens for is thrown, as
+// Node does, so a failure can't vanish silently.
+func emit(rt *goja.Runtime, s *emitterState, this goja.Value, name goja.Value, args []goja.Value) bool {
+	key := name.String()
+	ls := append([]listener(nil), s.listeners[key]...)
+	if len(ls) == 0 {
+		if key == "error" {
+			if len(args) > 0 {
+				if obj, ok := args[0].(*goja.Object); ok && obj.ClassName() == "Error" {
+					panic(obj)
+				}
+				panic(nodeError(rt, "ERR_UNHANDLED_ERROR", "Unhandled error. ("+args[0].String()+")"))
+			}
+			panic(nodeError(rt, "ERR_UNHANDLED_ERROR", "Unhandled error."))
+		}
+		return false
+	}
+
+	for _, l := range ls {
+		if l.once {
+			current := s.listeners[key]
+			for i := range current {
+				if current[i].original.SameAs(l.original) && current[i].once {
+					s.remove(key, i)
+					break
+				}
+			}
+		}
+		if _, err := l.fn(this, args...); err != nil {
+			panic(err)
+		}
+	}
+	return true
+}
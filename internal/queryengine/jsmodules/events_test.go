@@ -0,0 +1,100 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents_OnAndEmit(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const EventEmitter = require('events');
+		const e = new EventEmitter();
+		const seen = [];
+		e.on('row', (a, b) => seen.push(a + b));
+		e.on('row', (a) => seen.push(a));
+		[e.emit('row', 1, 2), e.emit('other'), seen]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, false, []any{int64(3), int64(1)}}, val.Export())
+}
+
+func TestEvents_OnceAndOff(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { EventEmitter } = require('events');
+		const e = new EventEmitter();
+		let once = 0, on = 0;
+		const handler = () => on++;
+		e.once('x', () => once++);
+		e.on('x', handler);
+		e.emit('x');
+		e.off('x', handler);
+		e.emit('x');
+		[once, on, e.listenerCount('x')]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(1), int64(0)}, val.Export())
+}
+
+func TestEvents_RemoveOnceListenerByOriginal(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const EventEmitter = require('events');
+		const e = new EventEmitter();
+		let n = 0;
+		const fn = () => n++;
+		e.once('x', fn);
+		e.removeListener('x', fn);
+		e.emit('x');
+		n
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), val.Export())
+}
+
+func TestEvents_UnhandledErrorThrows(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const EventEmitter = require('events');
+		const e = new EventEmitter();
+		const out = [];
+		try { e.emit('error', new Error('boom')); } catch (err) { out.push(err.message); }
+		try { e.emit('error', 'plain'); } catch (err) { out.push(err.code); }
+		out
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"boom", "ERR_UNHANDLED_ERROR"}, val.Export())
+}
+
+func TestEvents_Subclass(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const EventEmitter = require('events');
+		class Job extends EventEmitter {
+			run() { this.emit('done', 'ok'); }
+		}
+		const j = new Job();
+		let result;
+		j.on('done', (v) => { result = v; });
+		j.run();
+		[result, j.eventNames()]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"ok", []any{"done"}}, val.Export())
+}
+
+func TestEvents_OnceReturnsPromise(t *testing.T) {
+	rt := newTestRuntime(t)
+	_, err := rt.RunString(`
+		var result;
+		const EventEmitter = require('events');
+		const e = new EventEmitter();
+		EventEmitter.once(e, 'ready').then(args => { result = args.join(','); });
+		e.emit('ready', 'a', 'b');
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b", rt.Get("result").Export())
+}
@@ -32,7 +32,7 @@ func panicFSErr(rt *goja.Runtime, err error) {
 	panic(nodeError(rt, fsErrCode(err), err.Error()))
 }
 
-func registerFS(r *require.Registry, baseDir string) {
+func registerFS(r *require.Registry, baseDir string, sandbox Sandbox) {
 	r.RegisterNativeModule("fs", func(rt *goja.Runtime, m *goja.Object) {
 		exports := m.Get("exports").(*goja.Object)
 
@@ -41,6 +41,19 @@ func registerFS(r *require.Registry, baseDir string) {
 			return resolve(baseDir, call.Argument(i).String())
 		}
 
+		// readable and writable stop the call with EACCES when the sandbox
+		// refuses the access.
+		readable := func(op, path string) {
+			if err := sandbox.CheckRead(op, path); err != nil {
+				panicFSErr(rt, err)
+			}
+		}
+		writable := func(op, path string) {
+			if err := sandbox.CheckWrite(op, path); err != nil {
+				panicFSErr(rt, err)
+			}
+		}
+
 		_ = exports.Set("readFileSync", func(call goja.FunctionCall) goja.Value {
 			path := pathArg(call, 0)
 			encoding := ""
@@ -54,6 +67,7 @@ func registerFS(r *require.Registry, baseDir string) {
 					}
 				}
 			}
+			readable("open", path)
 			data, err := os.ReadFile(path)
 			if err != nil {
 				panicFSErr(rt, err)
@@ -65,12 +79,19 @@ func registerFS(r *require.Registry, baseDir string) {
 		})
 
 		_ = exports.Set("existsSync", func(call goja.FunctionCall) goja.Value {
-			_, err := os.Stat(pathArg(call, 0))
+			// As in Node, a path the script may not see simply doesn't exist.
+			path := pathArg(call, 0)
+			if sandbox.CheckRead("stat", path) != nil {
+				return rt.ToValue(false)
+			}
+			_, err := os.Stat(path)
 			return rt.ToValue(err == nil)
 		})
 
 		_ = exports.Set("statSync", func(call goja.FunctionCall) goja.Value {
-			info, err := os.Stat(pathArg(call, 0))
+			path := pathArg(call, 0)
+			readable("stat", path)
+			info, err := os.Stat(path)
 			if err != nil {
 				panicFSErr(rt, err)
 			}
@@ -89,7 +110,9 @@ func registerFS(r *require.Registry, baseDir string) {
 		})
 
 		_ = exports.Set("readdirSync", func(call goja.FunctionCall) goja.Value {
-			entries, err := os.ReadDir(pathArg(call, 0))
+			path := pathArg(call, 0)
+			readable("scandir", path)
+			entries, err := os.ReadDir(path)
 			if err != nil {
 				panicFSErr(rt, err)
 			}
@@ -102,6 +125,7 @@ func registerFS(r *require.Registry, baseDir string) {
 
 		_ = exports.Set("writeFileSync", func(call goja.FunctionCall) goja.Value {
 			path := pathArg(call, 0)
+			writable("open", path)
 			data := []byte(call.Argument(1).String())
 			if err := os.WriteFile(path, data, 0o644); err != nil {
 				panicFSErr(rt, err)
@@ -111,6 +135,7 @@ func registerFS(r *require.Registry, baseDir string) {
 
 		_ = exports.Set("appendFileSync", func(call goja.FunctionCall) goja.Value {
 			path := pathArg(call, 0)
+			writable("open", path)
 			data := []byte(call.Argument(1).String())
 			f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 			if err != nil {
@@ -125,6 +150,7 @@ func registerFS(r *require.Registry, baseDir string) {
 
 		_ = exports.Set("mkdirSync", func(call goja.FunctionCall) goja.Value {
 			path := pathArg(call, 0)
+			writable("mkdir", path)
 			recursive := false
 			mode := os.FileMode(0o755)
 			if len(call.Arguments) > 1 {
@@ -151,6 +177,7 @@ func registerFS(r *require.Registry, baseDir string) {
 
 		_ = exports.Set("rmSync", func(call goja.FunctionCall) goja.Value {
 			path := pathArg(call, 0)
+			writable("rm", path)
 			recursive := false
 			force := false
 			if len(call.Arguments) > 1 {
@@ -176,14 +203,20 @@ func registerFS(r *require.Registry, baseDir string) {
 		})
 
 		_ = exports.Set("unlinkSync", func(call goja.FunctionCall) goja.Value {
-			if err := os.Remove(pathArg(call, 0)); err != nil {
+			path := pathArg(call, 0)
+			writable("unlink", path)
+			if err := os.Remove(path); err != nil {
 				panicFSErr(rt, err)
 			}
 			return goja.Undefined()
 		})
 
 		_ = exports.Set("renameSync", func(call goja.FunctionCall) goja.Value {
-			if err := os.Rename(pathArg(call, 0), pathArg(call, 1)); err != nil {
+			src := pathArg(call, 0)
+			dst := pathArg(call, 1)
+			writable("rename", src)
+			writable("rename", dst)
+			if err := os.Rename(src, dst); err != nil {
 				panicFSErr(rt, err)
 			}
 			return goja.Undefined()
@@ -192,6 +225,8 @@ func registerFS(r *require.Registry, baseDir string) {
 		_ = exports.Set("copyFileSync", func(call goja.FunctionCall) goja.Value {
 			src := pathArg(call, 0)
 			dst := pathArg(call, 1)
+			readable("copyfile", src)
+			writable("copyfile", dst)
 			data, err := os.ReadFile(src)
 			if err != nil {
 				panicFSErr(rt, err)
@@ -201,5 +236,51 @@ func registerFS(r *require.Registry, baseDir string) {
 			}
 			return goja.Undefined()
 		})
+
+		// createReadStream only supports being handed to readline: the file is
+		// checked here, so a missing one fails at the call that names it, and
+		// opened by whichever consumer reads it.
+		_ = exports.Set("createReadStream", func(call goja.FunctionCall) goja.Value {
+			path := pathArg(call, 0)
+			readable("open", path)
+			info, err := os.Stat(path)
+			if err != nil {
+				panicFSErr(rt, err)
+			}
+			if info.IsDir() {
+				panic(nodeError(rt, "EISDIR", "illegal operation on a directory, open '"+path+"'"))
+			}
+			obj := rt.NewObject()
+			_ = obj.Set("path", call.Argument(0).String())
+			_ = obj.DefineDataPropertySymbol(readStreamKey(rt), rt.ToValue(&readStream{path: path}), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE)
+			return obj
+		})
 	})
 }
+
+// readStream is the Go side of an fs.createReadStream() result.
+type readStream struct {
+	path string
+}
+
+// readStreamKey is the symbol a read stream keeps its readStream under. It is
+// a registered symbol so fs and readline, which are set up independently,
+// agree on it within a runtime.
+func readStreamKey(rt *goja.Runtime) *goja.Symbol {
+	return symbolFor(rt, "vervet.fs.readStream").(*goja.Symbol)
+}
+
+// readStreamOf returns the readStream behind v, if v came from
+// fs.createReadStream.
+func readStreamOf(rt *goja.Runtime, v goja.Value) (*readStream, bool) {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return nil, false
+	}
+	inner := obj.GetSymbol(readStreamKey(rt))
+	if inner == nil {
+		return nil, false
+	}
+	s, ok := inner.Export().(*readStream)
+	return s, ok
+}
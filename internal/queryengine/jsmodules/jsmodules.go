@@ -1,5 +1,7 @@
-// Package jsmodules registers Node-compatible built-in modules
-// (fs, path, os, crypto) onto a goja_nodejs require.Registry.
+// Package jsmodules registers Node-compatible built-in modules (fs, path,
+// os, crypto, util, assert, events, url, querystring, zlib, string_decoder,
+// readline) onto a goja_nodejs require.Registry, and installs the require()
+// that also loads the script's own CommonJS modules.
 package jsmodules
 
 import (
@@ -10,20 +12,40 @@ import (
 	"github.com/dop251/goja_nodejs/require"
 )
 
-// RegisterAll registers fs, path, os, and crypto on the given registry.
+// RegisterAll registers the package's built-in modules on the given registry.
 // baseDir is the directory relative paths resolve against — the running
 // script's own directory. An empty baseDir leaves relative paths to the
-// process working directory.
-func RegisterAll(r *require.Registry, baseDir string) {
+// process working directory. sandbox limits which files fs may touch.
+func RegisterAll(r *require.Registry, baseDir string, sandbox Sandbox) {
 	registerPath(r, baseDir)
 	registerOS(r)
 	registerCrypto(r)
-	registerFS(r, baseDir)
+	registerFS(r, baseDir, sandbox)
+	registerUtil(r)
+	registerAssert(r)
+	registerEvents(r)
+	registerURL(r)
+	registerQueryString(r)
+	registerZlib(r)
+	registerStringDecoder(r)
+	registerReadline(r)
+}
+
+// Builtins lists the module names require() serves without reading a file:
+// the modules RegisterAll adds, plus the goja_nodejs core modules the engine
+// enables. A bundler must leave imports of these to require() at run time.
+func Builtins() []string {
+	return []string{
+		"fs", "path", "os", "crypto",
+		"util", "assert", "assert/strict", "events", "url", "querystring",
+		"zlib", "string_decoder", "readline",
+		"buffer", "process",
+	}
 }
 
 // resolve makes a script-supplied path absolute against baseDir. Absolute
-// paths are used as given, so a script can always reach outside its own
-// directory by saying so explicitly.
+// paths are used as given; whether the script may actually reach them is the
+// Sandbox's decision, not resolve's.
 func resolve(baseDir, p string) string {
 	if baseDir == "" || filepath.IsAbs(p) {
 		return p
@@ -18,10 +18,17 @@ func newTestRuntime(t *testing.T) *goja.Runtime {
 // newTestRuntimeIn builds a runtime whose relative paths resolve against base.
 func newTestRuntimeIn(t *testing.T, base string) *goja.Runtime {
 	t.Helper()
-	registry := gojarequire.NewRegistry()
-	RegisterAll(registry, base)
+	return newSandboxedRuntime(t, base, Sandbox{})
+}
+
+// newSandboxedRuntime builds a runtime whose file access sandbox polices,
+// relative paths resolving against base.
+func newSandboxedRuntime(t *testing.T, base string, sandbox Sandbox) *goja.Runtime {
+	t.Helper()
+	registry := gojarequire.NewRegistry(gojarequire.WithLoader(SourceLoader(sandbox)))
+	RegisterAll(registry, base, sandbox)
 	rt := goja.New()
-	registry.Enable(rt)
+	Enable(registry, rt)
 	buffer.Enable(rt)
 	return rt
 }
@@ -0,0 +1,131 @@
+package jsmodules
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+func registerQueryString(r *require.Registry) {
+	r.RegisterNativeModule("querystring", func(rt *goja.Runtime, m *goja.Object) {
+		exports := m.Get("exports").(*goja.Object)
+
+		sepArgs := func(call goja.FunctionCall) (sep, eq string) {
+			sep, eq = "&", "="
+			if s := call.Argument(1); !goja.IsUndefined(s) && !goja.IsNull(s) && s.String() != "" {
+				sep = s.String()
+			}
+			if e := call.Argument(2); !goja.IsUndefined(e) && !goja.IsNull(e) && e.String() != "" {
+				eq = e.String()
+			}
+			return sep, eq
+		}
+
+		parse := func(call goja.FunctionCall) goja.Value {
+			sep, eq := sepArgs(call)
+			return queryStringParse(rt, call.Argument(0).String(), sep, eq)
+		}
+		_ = exports.Set("parse", parse)
+		_ = exports.Set("decode", parse)
+
+		stringify := func(call goja.FunctionCall) goja.Value {
+			obj, ok := call.Argument(0).(*goja.Object)
+			if !ok {
+				return rt.ToValue("")
+			}
+			sep, eq := sepArgs(call)
+			var parts []string
+			for _, k := range obj.Keys() {
+				key := queryEscape(k)
+				v := obj.Get(k)
+				if arr, ok := v.(*goja.Object); ok && arr.ClassName() == "Array" {
+					n := int(arr.Get("length").ToInteger())
+					for i := 0; i < n; i++ {
+						parts = append(parts, key+eq+queryEscape(queryPrimitive(arr.Get(strconv.Itoa(i)))))
+					}
+					continue
+				}
+				parts = append(parts, key+eq+queryEscape(queryPrimitive(v)))
+			}
+			return rt.ToValue(strings.Join(parts, sep))
+		}
+		_ = exports.Set("stringify", stringify)
+		_ = exports.Set("encode", stringify)
+
+		_ = exports.Set("escape", func(call goja.FunctionCall) goja.Value {
+			return rt.ToValue(queryEscape(call.Argument(0).String()))
+		})
+		_ = exports.Set("unescape", func(call goja.FunctionCall) goja.Value {
+			return rt.ToValue(queryUnescape(call.Argument(0).String()))
+		})
+	})
+}
+
+// queryStringParse splits a query string into an object. A key that appears
+// more than once collects its values into an array, as Node's does.
+func queryStringParse(rt *goja.Runtime, s, sep, eq string) *goja.Object {
+	obj := rt.NewObject()
+	values := map[string][]string{}
+	var order []string
+	for _, pair := range strings.Split(s, sep) {
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, eq)
+		k, v = queryUnescape(k), queryUnescape(v)
+		if _, seen := values[k]; !seen {
+			order = append(order, k)
+		}
+		values[k] = append(values[k], v)
+	}
+	for _, k := range order {
+		if vs := values[k]; len(vs) == 1 {
+			_ = obj.Set(k, vs[0])
+		} else {
+			_ = obj.Set(k, vs)
+		}
+	}
+	return obj
+}
+
+// queryPrimitive is how querystring.stringify renders a value: strings,
+// finite numbers, bigints and booleans as themselves, anything else empty.
+func queryPrimitive(v goja.Value) string {
+	switch {
+	case goja.IsString(v), goja.IsBigInt(v):
+		return v.String()
+	case goja.IsNumber(v):
+		if goja.IsNaN(v) || goja.IsInfinity(v) {
+			return ""
+		}
+		return v.String()
+	}
+	if b, ok := v.Export().(bool); ok {
+		if b {
+			return "true"
+		}
+		return "false"
+	}
+	return ""
+}
+
+// queryEscape percent-encodes like Node's querystring.escape, which leaves
+// the same characters alone as encodeURIComponent.
+func queryEscape(s string) string {
+	escaped := url.QueryEscape(s)
+	escaped = strings.ReplaceAll(escaped, "+", "%20")
+	for _, keep := range []string{"!", "'", "(", ")", "*"} {
+		escaped = strings.ReplaceAll(escaped, url.QueryEscape(keep), keep)
+	}
+	return escaped
+}
+
+func queryUnescape(s string) string {
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
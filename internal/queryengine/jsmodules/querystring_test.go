@@ -0,0 +1,35 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryString_Parse(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const q = require('querystring').parse('a=1&b=x%20y&a=2&c');
+		JSON.stringify(q)
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":["1","2"],"b":"x y","c":""}`, val.Export())
+}
+
+func TestQueryString_Stringify(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`require('querystring').stringify({a: [1, 2], b: 'x y', c: true, d: null})`)
+	require.NoError(t, err)
+	assert.Equal(t, "a=1&a=2&b=x%20y&c=true&d=", val.Export())
+}
+
+func TestQueryString_CustomSeparators(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const qs = require('querystring');
+		[JSON.stringify(qs.parse('a:1;b:2', ';', ':')), qs.stringify({a: 1, b: 2}, ';', ':')]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{`{"a":"1","b":"2"}`, "a:1;b:2"}, val.Export())
+}
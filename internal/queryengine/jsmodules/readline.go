@@ -0,0 +1,175 @@
+package jsmodules
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// lineReader reads a file one line at a time, with Node's readline
+// treatment of line endings: "\n" and "\r\n" both end a line and neither is
+// part of it, and a final line without a terminator still counts.
+type lineReader struct {
+	f *os.File
+	r *bufio.Reader
+}
+
+func openLineReader(path string) (*lineReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &lineReader{f: f, r: bufio.NewReader(f)}, nil
+}
+
+// next returns the next line, or io.EOF once the file is exhausted.
+func (l *lineReader) next() (string, error) {
+	line, err := l.r.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func (l *lineReader) close() {
+	_ = l.f.Close()
+}
+
+func registerReadline(r *require.Registry) {
+	r.RegisterNativeModule("readline", func(rt *goja.Runtime, m *goja.Object) {
+		exports := m.Get("exports").(*goja.Object)
+
+		_ = exports.Set("createInterface", func(call goja.FunctionCall) goja.Value {
+			// Both createInterface({ input }) and createInterface(input).
+			input := call.Argument(0)
+			if opts, ok := input.(*goja.Object); ok {
+				if in := opts.Get("input"); in != nil && !goja.IsUndefined(in) {
+					input = in
+				}
+			}
+			stream, ok := readStreamOf(rt, input)
+			if !ok {
+				panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"input\" argument must be a stream from fs.createReadStream()"))
+			}
+			return newInterface(rt, stream, input)
+		})
+	})
+}
+
+// newInterface builds a readline Interface: an EventEmitter that emits
+// "line" for every line of the stream and then "close".
+//
+// goja has no `for await`, so the lines are delivered the two ways it can
+// support. Events are emitted from a microtask, so listeners attached right
+// after createInterface() see every line, and `await events.once(rl,
+// 'close')` waits for the end. Alternatively the interface is a synchronous
+// iterable, `for (const line of rl)`, which takes over from the events.
+func newInterface(rt *goja.Runtime, stream *readStream, input goja.Value) goja.Value {
+	emitter := require.Require(rt, "events").ToObject(rt)
+	rl, err := rt.New(emitter)
+	if err != nil {
+		panic(err)
+	}
+	_ = rl.Set("input", input)
+	_ = rl.Set("terminal", false)
+
+	emitFn, _ := goja.AssertFunction(rl.Get("emit"))
+	emitEvent := func(args ...goja.Value) {
+		if _, err := emitFn(rl, args...); err != nil {
+			panic(err)
+		}
+	}
+
+	var closed, started bool
+	closeInterface := func() {
+		if closed {
+			return
+		}
+		closed = true
+		emitEvent(rt.ToValue("close"))
+	}
+	_ = rl.Set("close", func(goja.FunctionCall) goja.Value {
+		closeInterface()
+		return goja.Undefined()
+	})
+
+	_ = rl.SetSymbol(goja.SymIterator, func(goja.FunctionCall) goja.Value {
+		if started {
+			panic(nodeError(rt, "ERR_INVALID_STATE", "readline interface is already being read"))
+		}
+		started = true
+		lr, err := openLineReader(stream.path)
+		if err != nil {
+			panicFSErr(rt, err)
+		}
+		it := rt.NewObject()
+		_ = it.Set("next", func(goja.FunctionCall) goja.Value {
+			result := rt.NewObject()
+			line, err := "", io.EOF
+			if !closed {
+				line, err = lr.next()
+			}
+			if err != nil {
+				lr.close()
+				closeInterface()
+				if !errors.Is(err, io.EOF) {
+					panicFSErr(rt, err)
+				}
+				_ = result.Set("done", true)
+				_ = result.Set("value", goja.Undefined())
+				return result
+			}
+			_ = result.Set("done", false)
+			_ = result.Set("value", line)
+			return result
+		})
+		_ = it.Set("return", func(goja.FunctionCall) goja.Value {
+			lr.close()
+			closeInterface()
+			result := rt.NewObject()
+			_ = result.Set("done", true)
+			return result
+		})
+		return it
+	})
+
+	// Schedule the event-driven read for once the current script turn ends.
+	promise, resolve, _ := rt.NewPromise()
+	_ = resolve(goja.Undefined())
+	then, _ := goja.AssertFunction(rt.ToValue(promise).ToObject(rt).Get("then"))
+	_, err = then(rt.ToValue(promise), rt.ToValue(func(goja.FunctionCall) goja.Value {
+		if started || closed {
+			return goja.Undefined()
+		}
+		started = true
+		lr, err := openLineReader(stream.path)
+		if err != nil {
+			emitEvent(rt.ToValue("error"), nodeError(rt, fsErrCode(err), err.Error()))
+			return goja.Undefined()
+		}
+		defer lr.close()
+		for !closed {
+			line, err := lr.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				emitEvent(rt.ToValue("error"), nodeError(rt, fsErrCode(err), err.Error()))
+				return goja.Undefined()
+			}
+			emitEvent(rt.ToValue("line"), rt.ToValue(line))
+		}
+		closeInterface()
+		return goja.Undefined()
+	}))
+	if err != nil {
+		panic(err)
+	}
+	return rl
+}
@@ -0,0 +1,115 @@
+package jsmodules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadline_LineEvents(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rows.csv"), "a,b\r\n1,2\n3,4")
+
+	rt := newTestRuntimeIn(t, dir)
+	_, err := rt.RunString(`
+		var lines = [], closed = false;
+		const rl = require('readline').createInterface({
+			input: require('fs').createReadStream('rows.csv'),
+		});
+		rl.on('line', (line) => lines.push(line));
+		rl.on('close', () => { closed = true; });
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a,b", "1,2", "3,4"}, rt.Get("lines").Export())
+	assert.Equal(t, true, rt.Get("closed").Export())
+}
+
+func TestReadline_SyncIteration(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rows.txt"), "x\ny\n")
+
+	rt := newTestRuntimeIn(t, dir)
+	val, err := rt.RunString(`
+		const rl = require('readline').createInterface({
+			input: require('fs').createReadStream('rows.txt'),
+		});
+		let events = 0;
+		rl.on('line', () => events++);
+		const out = [];
+		for (const line of rl) out.push(line);
+		out
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"x", "y"}, val.Export())
+	assert.Equal(t, int64(0), rt.Get("events").ToInteger())
+}
+
+func TestReadline_CloseStopsLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rows.txt"), "1\n2\n3\n")
+
+	rt := newTestRuntimeIn(t, dir)
+	_, err := rt.RunString(`
+		var lines = [];
+		const rl = require('readline').createInterface(require('fs').createReadStream('rows.txt'));
+		rl.on('line', (line) => { lines.push(line); if (line === '2') rl.close(); });
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"1", "2"}, rt.Get("lines").Export())
+}
+
+func TestReadline_AwaitClose(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rows.txt"), "1\n2\n")
+
+	rt := newTestRuntimeIn(t, dir)
+	_, err := rt.RunString(`
+		var total = 0, done = false;
+		(async () => {
+			const rl = require('readline').createInterface({ input: require('fs').createReadStream('rows.txt') });
+			rl.on('line', (line) => { total += Number(line); });
+			await require('events').once(rl, 'close');
+			done = true;
+		})();
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rt.Get("total").ToInteger())
+	assert.Equal(t, true, rt.Get("done").Export())
+}
+
+func TestReadline_MissingFile(t *testing.T) {
+	rt := newTestRuntimeIn(t, t.TempDir())
+	val, err := rt.RunString(`
+		try { require('fs').createReadStream('nope.txt'); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ENOENT", val.Export())
+}
+
+func TestReadline_RequiresReadStream(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try { require('readline').createInterface({ input: 'x' }); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_INVALID_ARG_TYPE", val.Export())
+}
+
+func TestReadline_EmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.txt"), nil, 0o644))
+
+	rt := newTestRuntimeIn(t, dir)
+	_, err := rt.RunString(`
+		var lines = 0, closed = false;
+		const rl = require('readline').createInterface({ input: require('fs').createReadStream('empty.txt') });
+		rl.on('line', () => lines++);
+		rl.on('close', () => { closed = true; });
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), rt.Get("lines").ToInteger())
+	assert.Equal(t, true, rt.Get("closed").Export())
+}
@@ -0,0 +1,58 @@
+package jsmodules
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// Enable installs require() on rt. Module resolution is goja_nodejs's, which
+// follows Node's rules: relative paths resolve against the requiring file,
+// a directory loads its package.json "main" or index.js, and bare names are
+// looked up in node_modules folders up the directory tree. Modules are cached
+// on the runtime, so a module's body runs once per execution however many
+// files require it.
+//
+// goja_nodejs reports a module it cannot find as "Invalid module"; the
+// require() installed here raises Node's "Cannot find module" error with
+// code MODULE_NOT_FOUND instead, so scripts can test for it the usual way,
+// and accepts the node: prefix on every built-in, as Node does.
+func Enable(r *require.Registry, rt *goja.Runtime) *require.RequireModule {
+	mod := r.Enable(rt)
+
+	_ = rt.Set("require", func(call goja.FunctionCall) goja.Value {
+		name := call.Argument(0).String()
+		exports, err := mod.Require(name)
+		// goja_nodejs only honours the node: prefix for its own core modules,
+		// not for the ones registered here.
+		if errors.Is(err, require.NoSuchBuiltInModuleError) {
+			exports, err = mod.Require(strings.TrimPrefix(name, require.NodePrefix))
+			if errors.Is(err, require.InvalidModuleError) {
+				err = require.NoSuchBuiltInModuleError
+			}
+		}
+		if err == nil {
+			return exports
+		}
+
+		var exc *goja.Exception
+		switch {
+		case errors.As(err, &exc):
+			panic(exc)
+		case errors.Is(err, require.InvalidModuleError):
+			panic(nodeError(rt, "MODULE_NOT_FOUND", "Cannot find module '"+name+"'"))
+		case errors.Is(err, require.NoSuchBuiltInModuleError):
+			panic(nodeError(rt, "ERR_UNKNOWN_BUILTIN_MODULE", "No such built-in module: "+name))
+		case errors.Is(err, fs.ErrPermission):
+			panicFSErr(rt, err)
+			return nil
+		default:
+			panic(rt.NewGoError(err))
+		}
+	})
+
+	return mod
+}
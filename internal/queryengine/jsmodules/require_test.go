@@ -0,0 +1,105 @@
+package jsmodules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+}
+
+func TestRequire_RelativeFileFromModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "lib", "a.js"), `module.exports = require('./b') + 1;`)
+	writeFile(t, filepath.Join(dir, "lib", "b.js"), `module.exports = 41;`)
+
+	rt := newTestRuntime(t)
+	require.NoError(t, rt.Set("P", filepath.Join(dir, "lib", "a.js")))
+	val, err := rt.RunString(`require(P)`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), val.Export())
+}
+
+func TestRequire_DirectoryIndexAndPackageMain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "withindex", "index.js"), `module.exports = 'index';`)
+	writeFile(t, filepath.Join(dir, "withmain", "package.json"), `{"main": "lib/entry.js"}`)
+	writeFile(t, filepath.Join(dir, "withmain", "lib", "entry.js"), `module.exports = 'main';`)
+
+	rt := newTestRuntime(t)
+	require.NoError(t, rt.Set("D", dir))
+	val, err := rt.RunString(`[require(D + '/withindex'), require(D + '/withmain')]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"index", "main"}, val.Export())
+}
+
+func TestRequire_NodeModulesUpTheTree(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "node_modules", "shared", "index.js"), `exports.name = 'shared';`)
+	writeFile(t, filepath.Join(dir, "deep", "nested", "user.js"), `module.exports = require('shared').name;`)
+
+	rt := newTestRuntime(t)
+	require.NoError(t, rt.Set("P", filepath.Join(dir, "deep", "nested", "user.js")))
+	val, err := rt.RunString(`require(P)`)
+	require.NoError(t, err)
+	assert.Equal(t, "shared", val.Export())
+}
+
+// A module's body runs once however many times it is required.
+func TestRequire_CachesModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "counter.js"), `globalThis.loads = (globalThis.loads || 0) + 1; module.exports = {};`)
+
+	rt := newTestRuntime(t)
+	require.NoError(t, rt.Set("P", filepath.Join(dir, "counter.js")))
+	val, err := rt.RunString(`require(P) === require(P) && loads`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), val.Export())
+}
+
+func TestRequire_MissingModuleIsModuleNotFound(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try {
+			require('./definitely-not-here');
+			'no-error';
+		} catch (e) {
+			[e.code, e.message];
+		}
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"MODULE_NOT_FOUND", "MODULE_NOT_FOUND: Cannot find module './definitely-not-here'"}, val.Export())
+}
+
+// An error thrown while a module runs reaches the caller unchanged.
+func TestRequire_PropagatesModuleError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "boom.js"), `throw new TypeError('module exploded');`)
+
+	rt := newTestRuntime(t)
+	require.NoError(t, rt.Set("P", filepath.Join(dir, "boom.js")))
+	val, err := rt.RunString(`try { require(P); } catch (e) { e instanceof TypeError && e.message; }`)
+	require.NoError(t, err)
+	assert.Equal(t, "module exploded", val.Export())
+}
+
+func TestRequire_NodePrefixOnRegisteredModules(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`require('node:path') === require('path')`)
+	require.NoError(t, err)
+	assert.Equal(t, true, val.Export())
+}
+
+func TestRequire_UnknownNodePrefixedModule(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`try { require('node:nope'); } catch (e) { e.code; }`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_UNKNOWN_BUILTIN_MODULE", val.Export())
+}
@@ -0,0 +1,115 @@
+package jsmodules
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"vervet/internal/models"
+
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// Sandbox is the file and environment policy a script runs under. The zero
+// value is unrestricted, which is how scripts ran before there was a policy.
+type Sandbox struct {
+	// Mode is one of the models.ScriptSandbox* values. Anything else is
+	// treated as unrestricted.
+	Mode string
+	// Roots are the directories a restricted script may reach: the active
+	// workspace's folders and the script's own directory.
+	Roots []string
+}
+
+// SandboxError is a file access the sandbox refused. It matches
+// fs.ErrPermission, so it surfaces in scripts as EACCES like any other
+// permission failure.
+type SandboxError struct {
+	Op     string
+	Path   string
+	Reason string
+}
+
+func (e *SandboxError) Error() string {
+	return "permission denied, " + e.Op + " '" + e.Path + "' (" + e.Reason + ")"
+}
+
+func (e *SandboxError) Is(target error) bool {
+	return target == fs.ErrPermission
+}
+
+func (s Sandbox) restricted() bool {
+	return s.Mode == models.ScriptSandboxWorkspace || s.Mode == models.ScriptSandboxReadOnly
+}
+
+// ExposesEnv reports whether process.env may carry the real environment.
+// Restricted scripts see an empty one, since the environment is where
+// credentials tend to live.
+func (s Sandbox) ExposesEnv() bool {
+	return !s.restricted()
+}
+
+// CheckRead returns a *SandboxError if the script may not read path.
+func (s Sandbox) CheckRead(op, path string) error {
+	if !s.restricted() || s.contains(path) {
+		return nil
+	}
+	return &SandboxError{Op: op, Path: path, Reason: "outside the script sandbox"}
+}
+
+// CheckWrite returns a *SandboxError if the script may not create, change or
+// remove path.
+func (s Sandbox) CheckWrite(op, path string) error {
+	if s.Mode == models.ScriptSandboxReadOnly {
+		return &SandboxError{Op: op, Path: path, Reason: "the script sandbox is read-only"}
+	}
+	return s.CheckRead(op, path)
+}
+
+// contains reports whether path lies within one of the roots once symlinks
+// are resolved, so a link inside a workspace can't lead a script out of it.
+func (s Sandbox) contains(path string) bool {
+	target := realPath(path)
+	for _, root := range s.Roots {
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(realPath(root), target)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath makes path absolute and resolves its symlinks. A path that doesn't
+// exist yet (a file about to be written) resolves through its nearest
+// existing ancestor.
+func realPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	var rest []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		if filepath.Dir(dir) == dir {
+			return abs
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
+// SourceLoader is the require() file loader for scripts under s: a module
+// outside the sandbox fails to load with EACCES rather than running. The
+// check comes before the read, so nothing outside is opened, and a missing
+// module there fails the same way as an existing one.
+func SourceLoader(s Sandbox) require.SourceLoader {
+	return func(path string) ([]byte, error) {
+		if err := s.CheckRead("open", path); err != nil {
+			return nil, err
+		}
+		return require.DefaultSourceLoader(path)
+	}
+}
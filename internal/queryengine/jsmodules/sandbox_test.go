@@ -0,0 +1,129 @@
+package jsmodules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox_ZeroValueIsUnrestricted(t *testing.T) {
+	var s Sandbox
+	assert.NoError(t, s.CheckRead("open", "/etc/hosts"))
+	assert.NoError(t, s.CheckWrite("open", "/tmp/x"))
+	assert.True(t, s.ExposesEnv())
+}
+
+func TestSandbox_WorkspaceLimitsToRoots(t *testing.T) {
+	root := t.TempDir()
+	other := t.TempDir()
+	s := Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}}
+
+	assert.NoError(t, s.CheckRead("open", filepath.Join(root, "a", "b.txt")))
+	assert.NoError(t, s.CheckWrite("open", filepath.Join(root, "new.txt")))
+	assert.ErrorIs(t, s.CheckRead("open", filepath.Join(other, "x")), os.ErrPermission)
+	assert.ErrorIs(t, s.CheckRead("open", filepath.Join(root, "..", "escape")), os.ErrPermission)
+	assert.False(t, s.ExposesEnv())
+}
+
+func TestSandbox_ReadOnlyRefusesWrites(t *testing.T) {
+	root := t.TempDir()
+	s := Sandbox{Mode: models.ScriptSandboxReadOnly, Roots: []string{root}}
+
+	assert.NoError(t, s.CheckRead("open", filepath.Join(root, "x")))
+	err := s.CheckWrite("open", filepath.Join(root, "x"))
+	require.ErrorIs(t, err, os.ErrPermission)
+	assert.Contains(t, err.Error(), "read-only")
+}
+
+func TestSandbox_SymlinkOutOfRootIsRefused(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "secret.txt"), "s")
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+	s := Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}}
+
+	assert.ErrorIs(t, s.CheckRead("open", filepath.Join(root, "link", "secret.txt")), os.ErrPermission)
+}
+
+func TestSandbox_FSReadOutsideIsEACCES(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "secret.txt"), "s")
+
+	rt := newSandboxedRuntime(t, root, Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}})
+	require.NoError(t, rt.Set("P", filepath.Join(outside, "secret.txt")))
+	val, err := rt.RunString(`
+		const fs = require('fs');
+		let code;
+		try { fs.readFileSync(P, 'utf8'); } catch (e) { code = e.code; }
+		[code, fs.existsSync(P)]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"EACCES", false}, val.Export())
+}
+
+func TestSandbox_FSInsideRootWorks(t *testing.T) {
+	root := t.TempDir()
+
+	rt := newSandboxedRuntime(t, root, Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}})
+	val, err := rt.RunString(`
+		const fs = require('fs');
+		fs.writeFileSync('out.txt', 'ok');
+		fs.readFileSync('out.txt', 'utf8')
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", val.Export())
+}
+
+func TestSandbox_FSReadOnlyRefusesEveryWrite(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "keep.txt"), "k")
+
+	rt := newSandboxedRuntime(t, root, Sandbox{Mode: models.ScriptSandboxReadOnly, Roots: []string{root}})
+	val, err := rt.RunString(`
+		const fs = require('fs');
+		const codes = [];
+		const attempt = (fn) => { try { fn(); codes.push('ok'); } catch (e) { codes.push(e.code); } };
+		attempt(() => fs.writeFileSync('a.txt', 'x'));
+		attempt(() => fs.appendFileSync('a.txt', 'x'));
+		attempt(() => fs.mkdirSync('d'));
+		attempt(() => fs.rmSync('keep.txt'));
+		attempt(() => fs.unlinkSync('keep.txt'));
+		attempt(() => fs.renameSync('keep.txt', 'moved.txt'));
+		attempt(() => fs.copyFileSync('keep.txt', 'copy.txt'));
+		attempt(() => fs.readFileSync('keep.txt', 'utf8'));
+		codes
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"EACCES", "EACCES", "EACCES", "EACCES", "EACCES", "EACCES", "EACCES", "ok"}, val.Export())
+	_, statErr := os.Stat(filepath.Join(root, "keep.txt"))
+	assert.NoError(t, statErr)
+}
+
+func TestSandbox_RequireOutsideRootIsEACCES(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "helper.js"), `module.exports = 1;`)
+
+	rt := newSandboxedRuntime(t, root, Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}})
+	require.NoError(t, rt.Set("P", filepath.Join(outside, "helper.js")))
+	val, err := rt.RunString(`try { require(P); 'loaded'; } catch (e) { e.code; }`)
+	require.NoError(t, err)
+	assert.Equal(t, "EACCES", val.Export())
+}
+
+func TestSandbox_RequireMissingOutsideRootIsEACCES(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	// Telling missing from present would let a script probe paths outside.
+	rt := newSandboxedRuntime(t, root, Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{root}})
+	require.NoError(t, rt.Set("P", filepath.Join(outside, "missing.js")))
+	val, err := rt.RunString(`try { require(P); 'loaded'; } catch (e) { e.code; }`)
+	require.NoError(t, err)
+	assert.Equal(t, "EACCES", val.Export())
+}
@@ -0,0 +1,139 @@
+package jsmodules
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/buffer"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// stringDecoder turns a sequence of byte chunks into text without splitting
+// a multi-byte character (or a base64 quantum) across two write() calls: the
+// incomplete tail of one chunk is held back until the next.
+type stringDecoder struct {
+	encoding string
+	pending  []byte
+}
+
+// normaliseEncoding maps Node's encoding aliases to the names handled here,
+// or "" for an encoding string_decoder does not support.
+func normaliseEncoding(enc string) string {
+	switch strings.ToLower(enc) {
+	case "", "utf8", "utf-8":
+		return "utf8"
+	case "latin1", "binary":
+		return "latin1"
+	case "ascii":
+		return "ascii"
+	case "hex":
+		return "hex"
+	case "base64":
+		return "base64"
+	default:
+		return ""
+	}
+}
+
+func (d *stringDecoder) write(chunk []byte) string {
+	data := append(d.pending, chunk...)
+	d.pending = nil
+
+	keep := 0
+	switch d.encoding {
+	case "utf8":
+		keep = incompleteUTF8Tail(data)
+	case "base64":
+		keep = len(data) % 3
+	}
+	if keep > 0 {
+		d.pending = append([]byte(nil), data[len(data)-keep:]...)
+		data = data[:len(data)-keep]
+	}
+	return d.decode(data)
+}
+
+func (d *stringDecoder) end(chunk []byte) string {
+	out := ""
+	if len(chunk) > 0 {
+		out = d.write(chunk)
+	}
+	rest := d.pending
+	d.pending = nil
+	return out + d.decode(rest)
+}
+
+func (d *stringDecoder) decode(data []byte) string {
+	switch d.encoding {
+	case "hex":
+		return hex.EncodeToString(data)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data)
+	case "latin1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	case "ascii":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b & 0x7f)
+		}
+		return string(runes)
+	default:
+		return strings.ToValidUTF8(string(data), "�")
+	}
+}
+
+// incompleteUTF8Tail returns how many bytes at the end of data begin a UTF-8
+// sequence that is still missing continuation bytes.
+func incompleteUTF8Tail(data []byte) int {
+	for i := 1; i <= 3 && i <= len(data); i++ {
+		b := data[len(data)-i]
+		if b&0xC0 == 0x80 {
+			// Continuation byte: keep looking for the lead byte.
+			continue
+		}
+		if utf8.RuneStart(b) && !utf8.FullRune(data[len(data)-i:]) {
+			return i
+		}
+		return 0
+	}
+	return 0
+}
+
+func registerStringDecoder(r *require.Registry) {
+	r.RegisterNativeModule("string_decoder", func(rt *goja.Runtime, m *goja.Object) {
+		exports := m.Get("exports").(*goja.Object)
+
+		ctor := rt.ToValue(func(call goja.ConstructorCall) *goja.Object {
+			name := ""
+			if arg := call.Argument(0); !goja.IsUndefined(arg) {
+				name = arg.String()
+			}
+			enc := normaliseEncoding(name)
+			if enc == "" {
+				panic(nodeError(rt, "ERR_UNKNOWN_ENCODING", "Unknown encoding: "+name))
+			}
+			d := &stringDecoder{encoding: enc}
+
+			_ = call.This.Set("encoding", enc)
+			_ = call.This.Set("write", func(c goja.FunctionCall) goja.Value {
+				return rt.ToValue(d.write(buffer.DecodeBytes(rt, c.Argument(0), goja.Undefined())))
+			})
+			_ = call.This.Set("end", func(c goja.FunctionCall) goja.Value {
+				var chunk []byte
+				if arg := c.Argument(0); !goja.IsUndefined(arg) {
+					chunk = buffer.DecodeBytes(rt, arg, goja.Undefined())
+				}
+				return rt.ToValue(d.end(chunk))
+			})
+			return nil
+		})
+		_ = exports.Set("StringDecoder", ctor)
+	})
+}
@@ -0,0 +1,56 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringDecoder_SplitMultiByteCharacter(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { StringDecoder } = require('string_decoder');
+		const d = new StringDecoder('utf8');
+		const euro = Buffer.from('€');
+		[d.write(euro.subarray(0, 1)), d.write(euro.subarray(1)), d.end()]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"", "€", ""}, val.Export())
+}
+
+func TestStringDecoder_EndFlushesIncomplete(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { StringDecoder } = require('string_decoder');
+		const d = new StringDecoder();
+		d.write(Buffer.from([0xe2, 0x82])) + '|' + d.end()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "|�", val.Export())
+}
+
+func TestStringDecoder_OtherEncodings(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { StringDecoder } = require('string_decoder');
+		const b64 = new StringDecoder('base64');
+		[
+			new StringDecoder('hex').write(Buffer.from([1, 255])),
+			new StringDecoder('latin1').write(Buffer.from([0xe9])),
+			b64.write(Buffer.from('ab')) + b64.end(Buffer.from('c')),
+		]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"01ff", "é", "YWJj"}, val.Export())
+}
+
+func TestStringDecoder_UnknownEncoding(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { StringDecoder } = require('string_decoder');
+		try { new StringDecoder('utf32'); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_UNKNOWN_ENCODING", val.Export())
+}
@@ -0,0 +1,159 @@
+package jsmodules
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+	gojaurl "github.com/dop251/goja_nodejs/url"
+)
+
+// registerURL registers url: goja_nodejs's WHATWG URL and URLSearchParams,
+// plus the file-URL helpers and the legacy parse/format pair older scripts
+// still use.
+func registerURL(r *require.Registry) {
+	r.RegisterNativeModule("url", func(rt *goja.Runtime, m *goja.Object) {
+		gojaurl.Require(rt, m)
+		exports := m.Get("exports").(*goja.Object)
+		urlCtor := exports.Get("URL")
+
+		_ = exports.Set("fileURLToPath", func(call goja.FunctionCall) goja.Value {
+			u, err := url.Parse(call.Argument(0).String())
+			if err != nil {
+				panic(nodeError(rt, "ERR_INVALID_URL", err.Error()))
+			}
+			if u.Scheme != "file" {
+				panic(nodeError(rt, "ERR_INVALID_URL_SCHEME", "The URL must be of scheme file"))
+			}
+			p := u.Path
+			if runtime.GOOS == "windows" {
+				p = strings.TrimPrefix(p, "/")
+			}
+			return rt.ToValue(filepath.FromSlash(p))
+		})
+
+		_ = exports.Set("pathToFileURL", func(call goja.FunctionCall) goja.Value {
+			abs, err := filepath.Abs(call.Argument(0).String())
+			if err != nil {
+				panic(nodeError(rt, "ERR_INVALID_ARG_VALUE", err.Error()))
+			}
+			p := filepath.ToSlash(abs)
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			u := url.URL{Scheme: "file", Path: p}
+			obj, err := rt.New(urlCtor, rt.ToValue(u.String()))
+			if err != nil {
+				panic(err)
+			}
+			return obj
+		})
+
+		_ = exports.Set("parse", func(call goja.FunctionCall) goja.Value {
+			raw := call.Argument(0).String()
+			u, err := url.Parse(raw)
+			if err != nil {
+				panic(nodeError(rt, "ERR_INVALID_URL", err.Error()))
+			}
+			parseQuery := call.Argument(1).ToBoolean()
+			return rt.ToValue(legacyURL(rt, u, raw, parseQuery))
+		})
+
+		_ = exports.Set("format", func(call goja.FunctionCall) goja.Value {
+			obj, ok := call.Argument(0).(*goja.Object)
+			if !ok {
+				return rt.ToValue(call.Argument(0).String())
+			}
+			if href := obj.Get("href"); href != nil && !goja.IsUndefined(href) {
+				return rt.ToValue(href.String())
+			}
+			return rt.ToValue(formatLegacyURL(obj))
+		})
+	})
+}
+
+// legacyURL builds the object url.parse returns. Fields a URL doesn't have
+// are null, as they are in Node.
+func legacyURL(rt *goja.Runtime, u *url.URL, raw string, parseQuery bool) map[string]any {
+	orNull := func(s string) any {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	protocol := ""
+	if u.Scheme != "" {
+		protocol = u.Scheme + ":"
+	}
+	search := ""
+	if u.RawQuery != "" || u.ForceQuery {
+		search = "?" + u.RawQuery
+	}
+	hash := ""
+	if u.Fragment != "" {
+		hash = "#" + u.EscapedFragment()
+	}
+	var query any = orNull(u.RawQuery)
+	if parseQuery {
+		query = queryStringParse(rt, u.RawQuery, "&", "=")
+	}
+	var auth any
+	if u.User != nil {
+		auth = u.User.String()
+	}
+	path := u.EscapedPath()
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	return map[string]any{
+		"protocol": orNull(protocol),
+		"slashes":  u.Host != "" || strings.Contains(raw, "//"),
+		"auth":     auth,
+		"host":     orNull(u.Host),
+		"port":     orNull(u.Port()),
+		"hostname": orNull(u.Hostname()),
+		"hash":     orNull(hash),
+		"search":   orNull(search),
+		"query":    query,
+		"pathname": orNull(path),
+		"path":     orNull(path + search),
+		"href":     u.String(),
+	}
+}
+
+func formatLegacyURL(obj *goja.Object) string {
+	get := func(name string) string {
+		v := obj.Get(name)
+		if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+			return ""
+		}
+		return v.String()
+	}
+	var b strings.Builder
+	if p := get("protocol"); p != "" {
+		b.WriteString(strings.TrimSuffix(p, ":") + "://")
+	}
+	if a := get("auth"); a != "" {
+		b.WriteString(a + "@")
+	}
+	if h := get("host"); h != "" {
+		b.WriteString(h)
+	} else {
+		b.WriteString(get("hostname"))
+		if p := get("port"); p != "" {
+			b.WriteString(":" + p)
+		}
+	}
+	b.WriteString(get("pathname"))
+	if s := get("search"); s != "" {
+		b.WriteString("?" + strings.TrimPrefix(s, "?"))
+	}
+	if h := get("hash"); h != "" {
+		b.WriteString("#" + strings.TrimPrefix(h, "#"))
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL_WHATWG(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const { URL } = require('url');
+		const u = new URL('https://example.com:8443/a/b?x=1&y=2#frag');
+		[u.hostname, u.port, u.pathname, u.searchParams.get('y'), u.hash]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"example.com", "8443", "/a/b", "2", "#frag"}, val.Export())
+}
+
+func TestURL_FileURLRoundTrip(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const url = require('url');
+		const href = url.pathToFileURL('/tmp/my file.csv').href;
+		[href, url.fileURLToPath(href)]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"file:///tmp/my%20file.csv", "/tmp/my file.csv"}, val.Export())
+}
+
+func TestURL_FileURLToPathRejectsOtherSchemes(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try { require('url').fileURLToPath('http://x/y'); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "ERR_INVALID_URL_SCHEME", val.Export())
+}
+
+func TestURL_LegacyParseAndFormat(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const url = require('url');
+		const u = url.parse('http://user@host.com:8080/p/a?query=string#hash', true);
+		[u.protocol, u.host, u.pathname, u.query.query, u.hash, url.format(u)]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"http:", "host.com:8080", "/p/a", "string", "#hash", "http://user@host.com:8080/p/a?query=string#hash"}, val.Export())
+}
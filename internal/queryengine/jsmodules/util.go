@@ -0,0 +1,555 @@
+package jsmodules
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// inspectCustomKey is the Symbol.for key of util.inspect.custom, the symbol an
+// object can define to control how util.inspect renders it.
+const inspectCustomKey = "nodejs.util.inspect.custom"
+
+// identifierKey matches object keys util.inspect prints without quotes.
+var identifierKey = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func registerUtil(r *require.Registry) {
+	r.RegisterNativeModule("util", func(rt *goja.Runtime, m *goja.Object) {
+		exports := m.Get("exports").(*goja.Object)
+
+		inspectFn := rt.ToValue(func(call goja.FunctionCall) goja.Value {
+			return rt.ToValue(inspect(rt, call.Argument(0), inspectOptionsArg(call)))
+		}).ToObject(rt)
+		_ = inspectFn.Set("custom", symbolFor(rt, inspectCustomKey))
+		_ = exports.Set("inspect", inspectFn)
+
+		_ = exports.Set("format", func(call goja.FunctionCall) goja.Value {
+			return rt.ToValue(format(rt, call.Arguments))
+		})
+
+		_ = exports.Set("isDeepStrictEqual", func(call goja.FunctionCall) goja.Value {
+			return rt.ToValue(deepEqual(rt, call.Argument(0), call.Argument(1), true))
+		})
+
+		_ = exports.Set("inherits", func(call goja.FunctionCall) goja.Value {
+			ctor, ok := call.Argument(0).(*goja.Object)
+			superCtor, superOK := call.Argument(1).(*goja.Object)
+			if !ok || !superOK {
+				panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"ctor\" and \"superCtor\" arguments must be of type function"))
+			}
+			proto, _ := ctor.Get("prototype").(*goja.Object)
+			superProto, _ := superCtor.Get("prototype").(*goja.Object)
+			if proto == nil || superProto == nil {
+				panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"superCtor.prototype\" property must be of type object"))
+			}
+			_ = ctor.Set("super_", superCtor)
+			_ = proto.SetPrototype(superProto)
+			return goja.Undefined()
+		})
+
+		// deprecate returns the function unchanged: scripts have nowhere
+		// useful to send the one-off warning Node would print.
+		_ = exports.Set("deprecate", func(call goja.FunctionCall) goja.Value {
+			return call.Argument(0)
+		})
+
+		_ = exports.Set("promisify", func(call goja.FunctionCall) goja.Value {
+			fn, ok := goja.AssertFunction(call.Argument(0))
+			if !ok {
+				panic(nodeError(rt, "ERR_INVALID_ARG_TYPE", "The \"original\" argument must be of type function"))
+			}
+			return rt.ToValue(func(inner goja.FunctionCall) goja.Value {
+				promise, resolve, reject := rt.NewPromise()
+				callback := rt.ToValue(func(cb goja.FunctionCall) goja.Value {
+					if err := cb.Argument(0); !goja.IsUndefined(err) && !goja.IsNull(err) {
+						_ = reject(err)
+					} else {
+						_ = resolve(cb.Argument(1))
+					}
+					return goja.Undefined()
+				})
+				args := append(append([]goja.Value{}, inner.Arguments...), callback)
+				if _, err := fn(inner.This, args...); err != nil {
+					_ = reject(errorValue(rt, err))
+				}
+				return rt.ToValue(promise)
+			})
+		})
+
+		types := rt.NewObject()
+		for name, className := range map[string]string{
+			"isDate":        "Date",
+			"isRegExp":      "RegExp",
+			"isMap":         "Map",
+			"isSet":         "Set",
+			"isPromise":     "Promise",
+			"isNativeError": "Error",
+			"isArrayBuffer": "ArrayBuffer",
+		} {
+			className := className
+			_ = types.Set(name, func(call goja.FunctionCall) goja.Value {
+				obj, ok := call.Argument(0).(*goja.Object)
+				return rt.ToValue(ok && classOf(rt, obj) == className)
+			})
+		}
+		_ = types.Set("isTypedArray", func(call goja.FunctionCall) goja.Value {
+			obj, ok := call.Argument(0).(*goja.Object)
+			return rt.ToValue(ok && classOf(rt, obj) == "TypedArray")
+		})
+		_ = exports.Set("types", types)
+	})
+}
+
+// errorValue unwraps the JS value behind an error returned from a goja call.
+func errorValue(rt *goja.Runtime, err error) goja.Value {
+	if exc, ok := err.(*goja.Exception); ok {
+		return exc.Value()
+	}
+	return rt.NewGoError(err)
+}
+
+// classOf names the built-in type behind obj. goja reports only some
+// built-ins through ClassName; the rest read as "Object" and are told apart
+// by their constructor. Every typed array reports as "TypedArray".
+func classOf(rt *goja.Runtime, obj *goja.Object) string {
+	if name := obj.ClassName(); name != "Object" {
+		return name
+	}
+	for _, name := range []string{"Map", "Set", "Promise", "ArrayBuffer", "DataView"} {
+		if ctor, ok := rt.Get(name).(*goja.Object); ok && rt.InstanceOf(obj, ctor) {
+			return name
+		}
+	}
+	if abCtor, ok := rt.Get("ArrayBuffer").(*goja.Object); ok {
+		if isView, ok := goja.AssertFunction(abCtor.Get("isView")); ok {
+			if v, err := isView(abCtor, obj); err == nil && v.ToBoolean() {
+				return "TypedArray"
+			}
+		}
+	}
+	return "Object"
+}
+
+// symbolFor returns the registered symbol for key, as Symbol.for(key) does.
+func symbolFor(rt *goja.Runtime, key string) goja.Value {
+	symbolCtor := rt.Get("Symbol").ToObject(rt)
+	forFn, _ := goja.AssertFunction(symbolCtor.Get("for"))
+	sym, err := forFn(symbolCtor, rt.ToValue(key))
+	if err != nil {
+		panic(err)
+	}
+	return sym
+}
+
+// inspectOptions are the util.inspect options that change its output.
+type inspectOptions struct {
+	depth       float64
+	breakLength int
+	sorted      bool
+}
+
+func inspectOptionsArg(call goja.FunctionCall) inspectOptions {
+	opts := inspectOptions{depth: 2, breakLength: 80}
+	obj, ok := call.Argument(1).(*goja.Object)
+	if !ok {
+		return opts
+	}
+	if d := obj.Get("depth"); d != nil && !goja.IsUndefined(d) {
+		if goja.IsNull(d) {
+			opts.depth = math.Inf(1)
+		} else {
+			opts.depth = d.ToFloat()
+		}
+	}
+	if b := obj.Get("breakLength"); b != nil && !goja.IsUndefined(b) {
+		if f := b.ToFloat(); math.IsInf(f, 1) {
+			opts.breakLength = math.MaxInt
+		} else {
+			opts.breakLength = int(f)
+		}
+	}
+	if s := obj.Get("sorted"); s != nil {
+		opts.sorted = s.ToBoolean()
+	}
+	return opts
+}
+
+// inspector renders values the way Node's util.inspect does for the common
+// cases: primitives, arrays, plain and class-instance objects, Date, RegExp,
+// Error, Map, Set, Promise and Buffer, with depth limiting and circular
+// reference markers.
+type inspector struct {
+	rt     *goja.Runtime
+	opts   inspectOptions
+	custom goja.Value
+	stack  []*goja.Object
+	refs   map[*goja.Object]int
+}
+
+func inspect(rt *goja.Runtime, v goja.Value, opts inspectOptions) string {
+	in := &inspector{rt: rt, opts: opts, custom: symbolFor(rt, inspectCustomKey), refs: map[*goja.Object]int{}}
+	return in.value(v, 0)
+}
+
+func (in *inspector) value(v goja.Value, depth int) string {
+	switch {
+	case v == nil || goja.IsUndefined(v):
+		return "undefined"
+	case goja.IsNull(v):
+		return "null"
+	case goja.IsString(v):
+		return quoteJSString(v.String())
+	case goja.IsBigInt(v):
+		return v.String() + "n"
+	case goja.IsNumber(v):
+		if f := v.ToFloat(); f == 0 && math.Signbit(f) {
+			return "-0"
+		}
+		return v.String()
+	}
+
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		// Booleans and symbols.
+		return v.String()
+	}
+	return in.object(obj, depth)
+}
+
+func (in *inspector) object(obj *goja.Object, depth int) string {
+	for _, seen := range in.stack {
+		if seen == obj {
+			id, ok := in.refs[obj]
+			if !ok {
+				id = len(in.refs) + 1
+				in.refs[obj] = id
+			}
+			return "[Circular *" + strconv.Itoa(id) + "]"
+		}
+	}
+
+	if sym, ok := in.custom.(*goja.Symbol); ok {
+		if fn, ok := goja.AssertFunction(obj.GetSymbol(sym)); ok {
+			res, err := fn(obj, in.rt.ToValue(in.opts.depth-float64(depth)), in.rt.NewObject())
+			if err != nil {
+				panic(err)
+			}
+			if goja.IsString(res) {
+				return res.String()
+			}
+			return in.value(res, depth)
+		}
+	}
+
+	in.stack = append(in.stack, obj)
+	out := in.objectBody(obj, depth)
+	in.stack = in.stack[:len(in.stack)-1]
+
+	if id, ok := in.refs[obj]; ok {
+		out = "<ref *" + strconv.Itoa(id) + "> " + out
+	}
+	return out
+}
+
+func (in *inspector) objectBody(obj *goja.Object, depth int) string {
+	className := classOf(in.rt, obj)
+	switch className {
+	case "Function":
+		name := obj.Get("name").String()
+		if strings.HasPrefix(in.callString(obj, "toString"), "class") {
+			return "[class " + name + "]"
+		}
+		if name == "" {
+			return "[Function (anonymous)]"
+		}
+		return "[Function: " + name + "]"
+	case "Date":
+		if math.IsNaN(obj.ToNumber().ToFloat()) {
+			return "Invalid Date"
+		}
+		return in.callString(obj, "toISOString")
+	case "RegExp":
+		return in.callString(obj, "toString")
+	case "Error":
+		if stack := obj.Get("stack"); stack != nil && goja.IsString(stack) {
+			return stack.String()
+		}
+		return "[" + in.callString(obj, "toString") + "]"
+	case "Promise":
+		p, _ := obj.Export().(*goja.Promise)
+		if p == nil {
+			return "Promise {}"
+		}
+		switch p.State() {
+		case goja.PromiseStatePending:
+			return "Promise { <pending> }"
+		case goja.PromiseStateRejected:
+			return "Promise { <rejected> " + in.value(p.Result(), depth+1) + " }"
+		default:
+			return "Promise { " + in.value(p.Result(), depth+1) + " }"
+		}
+	}
+
+	ctorName := constructorName(obj)
+	if ctorName == "Buffer" {
+		return inspectBuffer(obj)
+	}
+
+	isArray := className == "Array" || className == "TypedArray"
+	if float64(depth) > in.opts.depth {
+		if isArray {
+			return "[Array]"
+		}
+		if ctorName == "" || ctorName == "Object" {
+			return "[Object]"
+		}
+		return "[" + ctorName + "]"
+	}
+
+	var entries []string
+	prefix := ""
+	open, close := "{", "}"
+	switch {
+	case isArray:
+		open, close = "[", "]"
+		length := int(obj.Get("length").ToInteger())
+		if className == "TypedArray" {
+			prefix = ctorName + "(" + strconv.Itoa(length) + ") "
+		}
+		for i := 0; i < length; i++ {
+			entries = append(entries, in.value(obj.Get(strconv.Itoa(i)), depth+1))
+		}
+	case className == "Map":
+		prefix = "Map(" + obj.Get("size").String() + ") "
+		in.forEach(obj, func(args []goja.Value) {
+			entries = append(entries, in.value(args[1], depth+1)+" => "+in.value(args[0], depth+1))
+		})
+	case className == "Set":
+		prefix = "Set(" + obj.Get("size").String() + ") "
+		in.forEach(obj, func(args []goja.Value) {
+			entries = append(entries, in.value(args[0], depth+1))
+		})
+	default:
+		switch {
+		case obj.Prototype() == nil:
+			prefix = "[Object: null prototype] "
+		case ctorName != "" && ctorName != "Object":
+			prefix = ctorName + " "
+		}
+	}
+
+	if !isArray {
+		keys := obj.Keys()
+		if in.opts.sorted {
+			sort.Strings(keys)
+		}
+		for _, k := range keys {
+			entries = append(entries, inspectKey(k)+": "+in.value(obj.Get(k), depth+1))
+		}
+		for _, sym := range obj.Symbols() {
+			entries = append(entries, "["+sym.String()+"]: "+in.value(obj.GetSymbol(sym), depth+1))
+		}
+	}
+
+	if len(entries) == 0 {
+		return prefix + open + close
+	}
+
+	single := prefix + open + " " + strings.Join(entries, ", ") + " " + close
+	if len(single)+depth*2 <= in.opts.breakLength && !strings.Contains(single, "\n") {
+		return single
+	}
+	indent := strings.Repeat("  ", depth+1)
+	return prefix + open + "\n" + indent + strings.Join(entries, ",\n"+indent) + "\n" + strings.Repeat("  ", depth) + close
+}
+
+func (in *inspector) callString(obj *goja.Object, method string) string {
+	fn, ok := goja.AssertFunction(obj.Get(method))
+	if !ok {
+		return ""
+	}
+	res, err := fn(obj)
+	if err != nil {
+		panic(err)
+	}
+	return res.String()
+}
+
+func (in *inspector) forEach(obj *goja.Object, each func(args []goja.Value)) {
+	fn, ok := goja.AssertFunction(obj.Get("forEach"))
+	if !ok {
+		return
+	}
+	_, err := fn(obj, in.rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		each([]goja.Value{call.Argument(0), call.Argument(1)})
+		return goja.Undefined()
+	}))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// constructorName returns the name of obj's constructor, or "" when it has
+// none (Object.create(null), or a prototype without a constructor).
+func constructorName(obj *goja.Object) string {
+	proto := obj.Prototype()
+	if proto == nil {
+		return ""
+	}
+	ctor, ok := proto.Get("constructor").(*goja.Object)
+	if !ok {
+		return ""
+	}
+	if name := ctor.Get("name"); name != nil {
+		return name.String()
+	}
+	return ""
+}
+
+func inspectBuffer(obj *goja.Object) string {
+	data, _ := obj.Export().([]byte)
+	const maxBytes = 50
+	parts := make([]string, 0, len(data))
+	for i, b := range data {
+		if i == maxBytes {
+			parts = append(parts, "... "+strconv.Itoa(len(data)-maxBytes)+" more bytes")
+			break
+		}
+		parts = append(parts, strconv.FormatUint(uint64(b)|0x100, 16)[1:])
+	}
+	if len(parts) == 0 {
+		return "<Buffer >"
+	}
+	return "<Buffer " + strings.Join(parts, " ") + ">"
+}
+
+func inspectKey(k string) string {
+	if identifierKey.MatchString(k) {
+		return k
+	}
+	return quoteJSString(k)
+}
+
+// quoteJSString quotes s the way util.inspect does: single quotes unless the
+// string contains one, then double quotes, then backticks.
+func quoteJSString(s string) string {
+	quote := "'"
+	if strings.Contains(s, "'") {
+		switch {
+		case !strings.Contains(s, `"`):
+			quote = `"`
+		case !strings.Contains(s, "`"):
+			quote = "`"
+		}
+	}
+	var b strings.Builder
+	b.WriteString(quote)
+	for _, r := range s {
+		switch {
+		case string(r) == quote:
+			b.WriteString(`\` + quote)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20:
+			b.WriteString(`\x` + strconv.FormatUint(uint64(r)|0x100, 16)[1:])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(quote)
+	return b.String()
+}
+
+// format implements util.format: printf-style substitution from the first
+// argument, with any arguments left over appended — strings as they are,
+// everything else through inspect.
+func format(rt *goja.Runtime, args []goja.Value) string {
+	opts := inspectOptions{depth: 2, breakLength: 80}
+	if len(args) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	rest := args
+	if goja.IsString(args[0]) {
+		f := args[0].String()
+		rest = args[1:]
+		for i := 0; i < len(f); i++ {
+			c := f[i]
+			if c != '%' || i+1 == len(f) {
+				b.WriteByte(c)
+				continue
+			}
+			verb := f[i+1]
+			if verb == '%' {
+				b.WriteByte('%')
+				i++
+				continue
+			}
+			if !strings.ContainsRune("sdifjoOc", rune(verb)) || len(rest) == 0 {
+				b.WriteByte(c)
+				continue
+			}
+			arg := rest[0]
+			rest = rest[1:]
+			i++
+			switch verb {
+			case 's':
+				if goja.IsString(arg) {
+					b.WriteString(arg.String())
+				} else if _, isObj := arg.(*goja.Object); isObj {
+					b.WriteString(inspect(rt, arg, inspectOptions{depth: 1, breakLength: math.MaxInt}))
+				} else {
+					b.WriteString(inspect(rt, arg, opts))
+				}
+			case 'd', 'i':
+				n := arg.ToFloat()
+				if verb == 'i' {
+					n = math.Trunc(n)
+				}
+				b.WriteString(rt.ToValue(n).String())
+			case 'f':
+				b.WriteString(rt.ToValue(arg.ToFloat()).String())
+			case 'j':
+				b.WriteString(jsonStringify(rt, arg))
+			case 'o', 'O':
+				b.WriteString(inspect(rt, arg, inspectOptions{depth: 4, breakLength: 80}))
+			case 'c':
+				// CSS styling has no meaning outside a browser console.
+			}
+		}
+	}
+
+	for i, arg := range rest {
+		if i > 0 || len(rest) < len(args) {
+			b.WriteByte(' ')
+		}
+		if goja.IsString(arg) {
+			b.WriteString(arg.String())
+		} else {
+			b.WriteString(inspect(rt, arg, opts))
+		}
+	}
+	return b.String()
+}
+
+func jsonStringify(rt *goja.Runtime, v goja.Value) string {
+	json := rt.Get("JSON").ToObject(rt)
+	stringify, _ := goja.AssertFunction(json.Get("stringify"))
+	res, err := stringify(json, v)
+	if err != nil {
+		return "[Circular]"
+	}
+	return res.String()
+}
@@ -0,0 +1,105 @@
+package jsmodules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtil_Format(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`require('util').format('%s has %d rows, %j', 'orders', 42, {a: 1}, 'extra')`)
+	require.NoError(t, err)
+	assert.Equal(t, `orders has 42 rows, {"a":1} extra`, val.Export())
+}
+
+func TestUtil_FormatPercentLiteral(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`require('util').format('100%% of %s', 'docs')`)
+	require.NoError(t, err)
+	assert.Equal(t, "100% of docs", val.Export())
+}
+
+func TestUtil_Inspect(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`require('util').inspect({a: 1, b: 'x', c: [1, 2], d: null})`)
+	require.NoError(t, err)
+	assert.Equal(t, `{ a: 1, b: 'x', c: [ 1, 2 ], d: null }`, val.Export())
+}
+
+func TestUtil_InspectCircular(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`const o = {name: 'a'}; o.self = o; require('util').inspect(o)`)
+	require.NoError(t, err)
+	assert.Equal(t, `<ref *1> { name: 'a', self: [Circular *1] }`, val.Export())
+}
+
+func TestUtil_InspectMapAndSet(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const util = require('util');
+		[util.inspect(new Map([['k', 1]])), util.inspect(new Set([1, 2]))].join('|')
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, `Map(1) { 'k' => 1 }|Set(2) { 1, 2 }`, val.Export())
+}
+
+func TestUtil_InspectCustom(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const util = require('util');
+		class Money { [util.inspect.custom]() { return 'Money<5>'; } }
+		util.inspect({price: new Money()})
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, `{ price: Money<5> }`, val.Export())
+}
+
+func TestUtil_IsDeepStrictEqual(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const util = require('util');
+		[util.isDeepStrictEqual({a: [1, {b: 2}]}, {a: [1, {b: 2}]}), util.isDeepStrictEqual({a: 1}, {a: '1'})]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, false}, val.Export())
+}
+
+func TestUtil_Promisify(t *testing.T) {
+	rt := newTestRuntime(t)
+	_, err := rt.RunString(`
+		var result;
+		const util = require('util');
+		const add = (a, b, cb) => cb(null, a + b);
+		const fail = (cb) => cb(new Error('boom'));
+		util.promisify(add)(2, 3).then(v => { result = v; });
+		util.promisify(fail)().catch(e => { result += ':' + e.message; });
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "5:boom", rt.Get("result").Export())
+}
+
+func TestUtil_Types(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const t = require('util').types;
+		[t.isDate(new Date()), t.isRegExp(/x/), t.isPromise(Promise.resolve()), t.isNativeError(new TypeError()), t.isDate({})]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, true, true, true, false}, val.Export())
+}
+
+func TestUtil_Inherits(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const util = require('util');
+		function Base() {}
+		Base.prototype.hello = function () { return 'hi'; };
+		function Child() { Base.call(this); }
+		util.inherits(Child, Base);
+		new Child().hello()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", val.Export())
+}
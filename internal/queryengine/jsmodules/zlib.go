@@ -0,0 +1,99 @@
+package jsmodules
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/buffer"
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// zlibErrCode maps a zlib failure to zlib's own error code, which Node
+// surfaces as the error's code. Input cut short is Z_BUF_ERROR; anything else,
+// a bad header, a checksum mismatch or corrupt data, is Z_DATA_ERROR.
+func zlibErrCode(err error) string {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return "Z_BUF_ERROR"
+	}
+	return "Z_DATA_ERROR"
+}
+
+func registerZlib(r *require.Registry) {
+	r.RegisterNativeModule("zlib", func(rt *goja.Runtime, m *goja.Object) {
+		exports := m.Get("exports").(*goja.Object)
+
+		input := func(call goja.FunctionCall) []byte {
+			return buffer.DecodeBytes(rt, call.Argument(0), goja.Undefined())
+		}
+
+		level := func(call goja.FunctionCall) int {
+			if opts, ok := call.Argument(1).(*goja.Object); ok {
+				if l := opts.Get("level"); l != nil && !goja.IsUndefined(l) {
+					return int(l.ToInteger())
+				}
+			}
+			return flate.DefaultCompression
+		}
+
+		compress := func(name string, newWriter func(io.Writer, int) (io.WriteCloser, error)) {
+			_ = exports.Set(name, func(call goja.FunctionCall) goja.Value {
+				var out bytes.Buffer
+				w, err := newWriter(&out, level(call))
+				if err != nil {
+					panic(nodeError(rt, "ERR_OUT_OF_RANGE", err.Error()))
+				}
+				if _, err := w.Write(input(call)); err != nil {
+					panic(nodeError(rt, zlibErrCode(err), err.Error()))
+				}
+				if err := w.Close(); err != nil {
+					panic(nodeError(rt, zlibErrCode(err), err.Error()))
+				}
+				return buffer.WrapBytes(rt, out.Bytes())
+			})
+		}
+
+		decompress := func(name string, newReader func(io.Reader) (io.ReadCloser, error)) {
+			_ = exports.Set(name, func(call goja.FunctionCall) goja.Value {
+				rd, err := newReader(bytes.NewReader(input(call)))
+				if err != nil {
+					panic(nodeError(rt, zlibErrCode(err), err.Error()))
+				}
+				defer rd.Close()
+				data, err := io.ReadAll(rd)
+				if err != nil {
+					panic(nodeError(rt, zlibErrCode(err), err.Error()))
+				}
+				return buffer.WrapBytes(rt, data)
+			})
+		}
+
+		compress("gzipSync", func(w io.Writer, l int) (io.WriteCloser, error) { return gzip.NewWriterLevel(w, l) })
+		compress("deflateSync", func(w io.Writer, l int) (io.WriteCloser, error) { return zlib.NewWriterLevel(w, l) })
+		compress("deflateRawSync", func(w io.Writer, l int) (io.WriteCloser, error) { return flate.NewWriter(w, l) })
+
+		decompress("gunzipSync", func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) })
+		decompress("inflateSync", zlib.NewReader)
+		decompress("inflateRawSync", func(r io.Reader) (io.ReadCloser, error) { return flate.NewReader(r), nil })
+		// unzipSync accepts either wrapper, telling them apart by gzip's
+		// magic bytes.
+		decompress("unzipSync", func(r io.Reader) (io.ReadCloser, error) {
+			data, _ := io.ReadAll(r)
+			if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+				return gzip.NewReader(bytes.NewReader(data))
+			}
+			return zlib.NewReader(bytes.NewReader(data))
+		})
+
+		_ = exports.Set("constants", map[string]any{
+			"Z_NO_COMPRESSION":      flate.NoCompression,
+			"Z_BEST_SPEED":          flate.BestSpeed,
+			"Z_BEST_COMPRESSION":    flate.BestCompression,
+			"Z_DEFAULT_COMPRESSION": flate.DefaultCompression,
+		})
+	})
+}
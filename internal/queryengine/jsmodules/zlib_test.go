@@ -0,0 +1,76 @@
+package jsmodules
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZlib_RoundTrips(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const zlib = require('zlib');
+		const text = 'name,total\nalice,10\nbob,20\n'.repeat(10);
+		[
+			zlib.gunzipSync(zlib.gzipSync(text)).toString() === text,
+			zlib.inflateSync(zlib.deflateSync(text)).toString() === text,
+			zlib.inflateRawSync(zlib.deflateRawSync(text, {level: 9})).toString() === text,
+			zlib.unzipSync(zlib.gzipSync(text)).toString() === text,
+			zlib.unzipSync(zlib.deflateSync(text)).toString() === text,
+		]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, true, true, true, true}, val.Export())
+}
+
+func TestZlib_GunzipFileFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte("a,b\n1,2\n"))
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "export.csv.gz"), buf.Bytes(), 0o644))
+
+	rt := newTestRuntimeIn(t, dir)
+	val, err := rt.RunString(`
+		const zlib = require('zlib');
+		const fs = require('fs');
+		zlib.gunzipSync(fs.readFileSync('export.csv.gz')).toString('utf8').split('\n')[1]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "1,2", val.Export())
+}
+
+func TestZlib_CorruptInput(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		try { require('zlib').gunzipSync('not gzip at all'); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "Z_DATA_ERROR", val.Export())
+}
+
+func TestZlib_TruncatedInput(t *testing.T) {
+	rt := newTestRuntime(t)
+	val, err := rt.RunString(`
+		const zlib = require('zlib');
+		const gz = zlib.gzipSync('hello world, hello world');
+		try { zlib.gunzipSync(gz.subarray(0, gz.length - 6)); 'no-error'; } catch (e) { e.code; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "Z_BUF_ERROR", val.Export())
+}
+
+func TestZlibErrCode_NeverEmpty(t *testing.T) {
+	assert.Equal(t, "Z_BUF_ERROR", zlibErrCode(fmt.Errorf("reading: %w", io.ErrUnexpectedEOF)))
+	assert.Equal(t, "Z_DATA_ERROR", zlibErrCode(gzip.ErrHeader))
+	assert.Equal(t, "Z_DATA_ERROR", zlibErrCode(errors.New("something else went wrong")))
+}
@@ -75,6 +75,34 @@ func (c *lazyCursor) setSort(spec any) error {
 	return nil
 }
 
+// nextBatch returns an unexecuted copy of this cursor's query that starts
+// offset documents in and returns at most batch of them, or nil when the
+// cursor's own limit ends before offset.
+func (c *lazyCursor) nextBatch(offset, batch int64) *lazyCursor {
+	limit := batch
+	if c.limit > 0 {
+		remaining := c.limit - offset
+		if remaining <= 0 {
+			return nil
+		}
+		limit = min(limit, remaining)
+	}
+	return &lazyCursor{
+		ec:         c.ec,
+		collection: c.collection,
+		filter:     c.filter,
+		projection: c.projection,
+		limit:      limit,
+		skip:       c.skip + offset,
+		sort:       c.sort,
+		hint:       c.hint,
+		maxTimeMS:  c.maxTimeMS,
+		batchSize:  c.batchSize,
+		collation:  c.collation,
+		comment:    c.comment,
+	}
+}
+
 // execute runs the query against MongoDB and caches the results.
 // Subsequent calls return cached results.
 //
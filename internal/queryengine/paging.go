@@ -2,9 +2,16 @@ package queryengine
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // effectivePaging computes the skip/limit to send to MongoDB for a given page.
@@ -32,6 +39,10 @@ func (e *GojaEngine) FetchPage(
 	pc models.PageContext,
 	page, pageSize int64,
 ) (models.QueryResult, error) {
+	dbName, pc, err := resolvePageContext(dbName, pc)
+	if err != nil {
+		return models.QueryResult{}, err
+	}
 	skip, limit, empty := effectivePaging(pc, page, pageSize)
 	if empty {
 		return models.QueryResult{Documents: []any{}}, nil
@@ -48,7 +59,12 @@ func (e *GojaEngine) FetchPage(
 		MaxTimeMS:  pc.MaxTimeMS,
 		Comment:    pc.Comment,
 	}
-	return dispatch(ctx, e.client, dbName, op)
+	res, err := dispatch(ctx, e.client, dbName, op)
+	if err != nil {
+		return models.QueryResult{}, err
+	}
+	res.Documents = limitDocuments(res.Documents, e.docLimit)
+	return res, nil
 }
 
 // CountForPage returns a total-row count for a PageContext. When the filter is
@@ -59,6 +75,10 @@ func (e *GojaEngine) CountForPage(
 	dbName string,
 	pc models.PageContext,
 ) (count int64, estimated bool, err error) {
+	dbName, pc, err = resolvePageContext(dbName, pc)
+	if err != nil {
+		return 0, false, err
+	}
 	method := "countDocuments"
 	args := []any{pc.Filter}
 	if isEmptyFilter(pc.Filter) {
@@ -82,16 +102,125 @@ func (e *GojaEngine) CountForPage(
 	return count, estimated, nil
 }
 
-func isEmptyFilter(f any) bool {
-	if f == nil {
-		return true
+// FetchValue loads a value a result left out of an oversized document (see
+// truncatedKey): the value at path, the keys of a marker, in the document of
+// collection whose _id is id, given as canonical Extended JSON. An empty path
+// loads the whole document. The value is returned whole, as the result's
+// only document.
+func (e *GojaEngine) FetchValue(ctx context.Context, dbName, collection, id string, path []string) (models.QueryResult, error) {
+	idDoc, err := IDFilter(id)
+	if err != nil {
+		return models.QueryResult{}, err
+	}
+	opts := options.FindOne()
+	// A projection reads dots as nesting and can't name a $ field, so a
+	// first key with either has the whole document fetched instead.
+	if len(path) > 0 && !strings.ContainsAny(path[0], ".$") {
+		opts.SetProjection(bson.D{{Key: path[0], Value: 1}})
 	}
-	if m, ok := f.(map[string]any); ok && len(m) == 0 {
+
+	coll := e.client.Database(dbName).Collection(collection)
+	doc, err := coll.FindOne(ctx, idDoc, opts).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.QueryResult{}, fmt.Errorf("document %s no longer exists", id)
+	}
+	if err != nil {
+		return models.QueryResult{}, fmt.Errorf("findOne failed: %w", err)
+	}
+	if len(path) == 0 {
+		return models.QueryResult{Documents: []any{resultDocument{raw: doc}}, Single: true}, nil
+	}
+
+	val, err := doc.LookupErr(path...)
+	if err != nil {
+		return models.QueryResult{}, fmt.Errorf("document %s has no value at %s", id, strings.Join(path, "."))
+	}
+	data, err := rawValueJSON(val)
+	if err != nil {
+		return models.QueryResult{}, err
+	}
+	return models.QueryResult{Documents: []any{data}, Single: true}, nil
+}
+
+func isEmptyFilter(f any) bool {
+	switch v := f.(type) {
+	case nil:
 		return true
+	case map[string]any:
+		return len(v) == 0
+	case bson.D:
+		return len(v) == 0
 	}
 	return false
 }
 
+// resolvePageContext prepares a PageContext the frontend has sent back for
+// dispatch: it picks the database the find ran against, and decodes the
+// Extended JSON a mongosh PageContext carries its find in.
+func resolvePageContext(dbName string, pc models.PageContext) (string, models.PageContext, error) {
+	if pc.Database != "" {
+		dbName = pc.Database
+	}
+	if !pc.ExtendedJSON {
+		return dbName, pc, nil
+	}
+
+	for _, field := range []*any{&pc.Filter, &pc.Projection, &pc.Sort, &pc.Hint} {
+		decoded, err := decodeExtendedJSON(*field)
+		if err != nil {
+			return "", pc, fmt.Errorf("invalid page context: %w", err)
+		}
+		*field = decoded
+	}
+	// mongosh sends the sort as [key, direction] pairs so its order survives
+	// the trip through JSON.
+	if pairs, ok := pc.Sort.(bson.A); ok {
+		sort := make(bson.D, 0, len(pairs))
+		for _, pair := range pairs {
+			kv, ok := pair.(bson.A)
+			if !ok || len(kv) != 2 {
+				return "", pc, fmt.Errorf("invalid page context: malformed sort")
+			}
+			key, _ := kv[0].(string)
+			sort = append(sort, bson.E{Key: key, Value: kv[1]})
+		}
+		pc.Sort = sort
+	}
+	if pc.Collation != nil {
+		decoded, err := decodeExtendedJSON(pc.Collation)
+		if err != nil {
+			return "", pc, fmt.Errorf("invalid page context: %w", err)
+		}
+		collation := make(map[string]any)
+		if doc, ok := decoded.(bson.D); ok {
+			for _, e := range doc {
+				collation[e.Key] = e.Value
+			}
+		}
+		pc.Collation = collation
+	}
+	pc.ExtendedJSON = false
+	return dbName, pc, nil
+}
+
+// decodeExtendedJSON turns one canonical Extended JSON value, as decoded by
+// encoding/json, into BSON. Documents become bson.D, keeping the key order a
+// sort depends on.
+func decodeExtendedJSON(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]any{"v": v})
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(data, true, &doc); err != nil {
+		return nil, err
+	}
+	return doc[0].Value, nil
+}
+
 func extractCount(r models.QueryResult) int64 {
 	if len(r.Documents) == 0 {
 		return 0
@@ -4,7 +4,9 @@ package queryengine
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,3 +98,47 @@ func TestIntegration_FetchPage_RespectsUserLimit(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(50), count)
 }
+
+func TestIntegration_FetchValue_LoadsTruncatedValues(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+
+	engine := NewGojaEngine(testClient, 25, "")
+	engine.docLimit = 1024
+	_, err := engine.ExecuteQuery(ctx, testURI, db,
+		`db.big.insertOne({_id: 1, body: "x".repeat(4096), nested: {list: [1, "y".repeat(4096)]}, "a.b": {"c.d": "z".repeat(4096)}})`)
+	require.NoError(t, err)
+
+	res, err := engine.ExecuteQuery(ctx, testURI, db, `db.big.find({})`)
+	require.NoError(t, err)
+	require.Len(t, res.Documents, 1)
+	data, err := json.Marshal(res.Documents[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), truncatedKey)
+
+	body, err := engine.FetchValue(ctx, db, "big", `{"$numberInt":"1"}`, []string{"body"})
+	require.NoError(t, err)
+	data, err = json.Marshal(body.Documents[0])
+	require.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("x", 4096)+`"`, string(data))
+
+	elem, err := engine.FetchValue(ctx, db, "big", `{"$numberInt":"1"}`, []string{"nested", "list", "1"})
+	require.NoError(t, err)
+	data, err = json.Marshal(elem.Documents[0])
+	require.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("y", 4096)+`"`, string(data))
+
+	dotted, err := engine.FetchValue(ctx, db, "big", `{"$numberInt":"1"}`, []string{"a.b", "c.d"})
+	require.NoError(t, err, "field names with dots are keys, not paths")
+	data, err = json.Marshal(dotted.Documents[0])
+	require.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("z", 4096)+`"`, string(data))
+
+	_, err = engine.FetchValue(ctx, db, "big", `{"$numberInt":"2"}`, []string{"body"})
+	assert.ErrorContains(t, err, "no longer exists")
+	_, err = engine.FetchValue(ctx, db, "big", `{"$numberInt":"1"}`, []string{"missing"})
+	assert.ErrorContains(t, err, "no value at missing")
+}
@@ -1,11 +1,14 @@
 package queryengine
 
 import (
+	"encoding/json"
 	"testing"
 
 	"vervet/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func TestEffectivePaging_NoUserLimit(t *testing.T) {
@@ -59,3 +62,54 @@ func TestExtractCount(t *testing.T) {
 	assert.Equal(t, int64(7), extractCount(r))
 	assert.Equal(t, int64(0), extractCount(models.QueryResult{}))
 }
+
+func TestIsEmptyFilter_BSON(t *testing.T) {
+	assert.True(t, isEmptyFilter(bson.D{}))
+	assert.False(t, isEmptyFilter(bson.D{{Key: "x", Value: 1}}))
+}
+
+func TestResolvePageContext_PlainContextIsUnchanged(t *testing.T) {
+	pc := models.PageContext{Collection: "c", Filter: map[string]any{"x": 1}}
+	db, got, err := resolvePageContext("tabdb", pc)
+	require.NoError(t, err)
+	assert.Equal(t, "tabdb", db)
+	assert.Equal(t, pc, got)
+}
+
+// A mongosh PageContext arrives as canonical Extended JSON, decoded by
+// encoding/json on its way back from the frontend.
+func TestResolvePageContext_DecodesExtendedJSON(t *testing.T) {
+	var pc models.PageContext
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"database": "other",
+		"collection": "c",
+		"filter": {"_id": {"$oid": "65a1b2c3d4e5f60718293a4b"}, "n": {"$gte": {"$numberInt": "5"}}},
+		"projection": {"n": {"$numberInt": "1"}},
+		"sort": [["b", {"$numberInt": "-1"}], ["a", {"$numberInt": "1"}]],
+		"hint": "n_1",
+		"collation": {"locale": "en", "strength": {"$numberInt": "2"}},
+		"extendedJSON": true
+	}`), &pc))
+
+	db, got, err := resolvePageContext("tabdb", pc)
+	require.NoError(t, err)
+	assert.Equal(t, "other", db)
+	assert.False(t, got.ExtendedJSON)
+
+	oid, _ := bson.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	filter := toBsonDoc(got.Filter)
+	assert.ElementsMatch(t, bson.D{
+		{Key: "_id", Value: oid},
+		{Key: "n", Value: bson.D{{Key: "$gte", Value: int32(5)}}},
+	}, filter)
+	assert.Equal(t, bson.D{{Key: "n", Value: int32(1)}}, got.Projection)
+	assert.Equal(t, bson.D{{Key: "b", Value: int32(-1)}, {Key: "a", Value: int32(1)}}, got.Sort)
+	assert.Equal(t, "n_1", got.Hint)
+	assert.Equal(t, 2, toCollation(got.Collation).Strength)
+}
+
+func TestResolvePageContext_RejectsMalformedSort(t *testing.T) {
+	pc := models.PageContext{Collection: "c", Sort: []any{"a"}, ExtendedJSON: true}
+	_, _, err := resolvePageContext("tabdb", pc)
+	assert.ErrorContains(t, err, "malformed sort")
+}
@@ -58,6 +58,9 @@ func newCollectionProxy(ec *execContext, collName string) goja.Value {
 		if len(args) > 1 {
 			cursor.projection = args[1]
 		}
+		if ec.shell != nil {
+			ec.shell.lastCursor = cursor
+		}
 		return cursor.toGojaObject()
 	})
 
@@ -89,6 +92,9 @@ func newCollectionProxy(ec *execContext, collName string) goja.Value {
 				Args:       args,
 			}
 			result, err := dispatch(ec.ctx, ec.client, ec.dbName, op)
+			if auditedMethods[m] {
+				ec.audit(collName, m, args, result.AffectedCount, err)
+			}
 			if err != nil {
 				panic(ec.rt.NewGoError(err))
 			}
@@ -97,6 +103,7 @@ func newCollectionProxy(ec *execContext, collName string) goja.Value {
 	}
 
 	setCollectionInfoMethods(obj, ec, collName)
+	setCollectionAdminMethods(obj, ec, collName)
 
 	return obj
 }
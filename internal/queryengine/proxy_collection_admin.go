@@ -0,0 +1,163 @@
+package queryengine
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// aggregateDocs runs pipeline against collName and returns every document as
+// a slice ready for toJSValue.
+func aggregateDocs(ec *execContext, collName string, pipeline bson.A) ([]any, error) {
+	cursor, err := ec.client.Database(ec.dbName).Collection(collName).Aggregate(ec.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ec.ctx, &docs); err != nil {
+		return nil, err
+	}
+	out := make([]any, len(docs))
+	for i, doc := range docs {
+		out[i] = doc
+	}
+	return out, nil
+}
+
+// indexSpec identifies an index for collMod by name or by key pattern, the
+// two ways mongosh's hideIndex accepts.
+func indexSpec(arg any) (bson.D, error) {
+	switch v := arg.(type) {
+	case string:
+		return bson.D{{Key: "name", Value: v}}, nil
+	case map[string]any:
+		return bson.D{{Key: "keyPattern", Value: convertToBson(v)}}, nil
+	}
+	return nil, fmt.Errorf("index must be a name or a key pattern")
+}
+
+// setCollectionAdminMethods attaches the administration helpers — index
+// visibility, latency stats, maintenance, sharding and search index
+// inspection, and a collection-scoped runCommand — to a collection proxy.
+func setCollectionAdminMethods(obj *goja.Object, ec *execContext, collName string) {
+	rt := ec.rt
+	ns := func() string { return ec.dbName + "." + collName }
+
+	setIndexHidden := func(method string, hidden bool) func(goja.FunctionCall) goja.Value {
+		return func(call goja.FunctionCall) goja.Value {
+			if len(call.Arguments) == 0 {
+				panic(rt.NewGoError(fmt.Errorf("%s requires an index name or key pattern", method)))
+			}
+			index := exportValue(call.Arguments[0])
+			spec, err := indexSpec(index)
+			if err != nil {
+				panic(rt.NewGoError(fmt.Errorf("%s: %w", method, err)))
+			}
+			requireClient(ec)
+			cmd := bson.D{
+				{Key: "collMod", Value: collName},
+				{Key: "index", Value: append(spec, bson.E{Key: "hidden", Value: hidden})},
+			}
+			var result bson.M
+			err = ec.client.Database(ec.dbName).RunCommand(ec.ctx, cmd).Decode(&result)
+			ec.audit(collName, method, []any{index}, 0, err)
+			if err != nil {
+				panic(rt.NewGoError(fmt.Errorf("%s: %w", method, err)))
+			}
+			return toJSValue(rt, result)
+		}
+	}
+	_ = obj.Set("hideIndex", setIndexHidden("hideIndex", true))
+	_ = obj.Set("unhideIndex", setIndexHidden("unhideIndex", false))
+
+	_ = obj.Set("latencyStats", func(call goja.FunctionCall) goja.Value {
+		requireClient(ec)
+		latency := withOptions(bson.D{}, call.Argument(0))
+		docs, err := aggregateDocs(ec, collName, bson.A{
+			bson.D{{Key: "$collStats", Value: bson.D{{Key: "latencyStats", Value: latency}}}},
+		})
+		if err != nil {
+			panic(rt.NewGoError(fmt.Errorf("latencyStats: %w", err)))
+		}
+		return withCursorMethods(rt, "aggregate", toJSValue(rt, docs))
+	})
+
+	_ = obj.Set("compact", func(call goja.FunctionCall) goja.Value {
+		cmd := withOptions(bson.D{{Key: "compact", Value: collName}}, call.Argument(0))
+		return toJSValue(rt, runAuditedHelper(ec, "compact", ec.dbName, collName, cmd))
+	})
+
+	_ = obj.Set("reIndex", func(call goja.FunctionCall) goja.Value {
+		return toJSValue(rt, runAuditedHelper(ec, "reIndex", ec.dbName, collName, bson.D{{Key: "reIndex", Value: collName}}))
+	})
+
+	_ = obj.Set("getShardVersion", func(call goja.FunctionCall) goja.Value {
+		return toJSValue(rt, runAdminHelper(ec, "getShardVersion", "admin", bson.D{{Key: "getShardVersion", Value: ns()}}))
+	})
+
+	_ = obj.Set("configureQueryAnalyzer", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(rt.NewGoError(fmt.Errorf("configureQueryAnalyzer requires an options document")))
+		}
+		cmd := withOptions(bson.D{{Key: "configureQueryAnalyzer", Value: ns()}}, call.Arguments[0])
+		return toJSValue(rt, runAuditedHelper(ec, "configureQueryAnalyzer", "admin", collName, cmd))
+	})
+
+	_ = obj.Set("analyzeShardKey", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(rt.NewGoError(fmt.Errorf("analyzeShardKey requires a shard key")))
+		}
+		cmd := bson.D{
+			{Key: "analyzeShardKey", Value: ns()},
+			{Key: "key", Value: convertToBson(exportValue(call.Arguments[0]))},
+		}
+		cmd = withOptions(cmd, call.Argument(1))
+		return toJSValue(rt, runAdminHelper(ec, "analyzeShardKey", "admin", cmd))
+	})
+
+	_ = obj.Set("getSearchIndexes", func(call goja.FunctionCall) goja.Value {
+		requireClient(ec)
+		stage := bson.D{}
+		if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			stage = append(stage, bson.E{Key: "name", Value: arg.String()})
+		}
+		docs, err := aggregateDocs(ec, collName, bson.A{bson.D{{Key: "$listSearchIndexes", Value: stage}}})
+		if err != nil {
+			panic(rt.NewGoError(fmt.Errorf("getSearchIndexes: %w", err)))
+		}
+		return toJSValue(rt, docs)
+	})
+
+	_ = obj.Set("checkMetadataConsistency", func(call goja.FunctionCall) goja.Value {
+		requireClient(ec)
+		cmd := withOptions(bson.D{{Key: "checkMetadataConsistency", Value: collName}}, call.Argument(0))
+		cursor, err := ec.client.Database(ec.dbName).RunCommandCursor(ec.ctx, cmd)
+		if err != nil {
+			panic(rt.NewGoError(fmt.Errorf("checkMetadataConsistency: %w", err)))
+		}
+		var docs []bson.M
+		if err := cursor.All(ec.ctx, &docs); err != nil {
+			panic(rt.NewGoError(fmt.Errorf("checkMetadataConsistency: %w", err)))
+		}
+		out := make([]any, len(docs))
+		for i, doc := range docs {
+			out[i] = doc
+		}
+		return withCursorMethods(rt, "aggregate", toJSValue(rt, out))
+	})
+
+	// runCommand("name", options) runs { name: <this collection>, ...options }
+	// against the collection's database.
+	_ = obj.Set("runCommand", func(call goja.FunctionCall) goja.Value {
+		name, ok := exportValue(call.Argument(0)).(string)
+		if !ok || name == "" {
+			panic(rt.NewGoError(fmt.Errorf("runCommand requires a command name")))
+		}
+		cmd := withOptions(bson.D{{Key: name, Value: collName}}, call.Argument(1))
+		if commandChanges(cmd) {
+			return toJSValue(rt, runAuditedHelper(ec, name, ec.dbName, collName, cmd))
+		}
+		return toJSValue(rt, runAdminHelper(ec, "runCommand", ec.dbName, cmd))
+	})
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package queryengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_HideIndex_UnhideIndex(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		db.people.createIndex({ email: 1 });
+		const hidden = db.people.hideIndex("email_1");
+		const shown = db.people.unhideIndex({ email: 1 });
+		[hidden.hidden_old, hidden.hidden_new, shown.hidden_new]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{false, true, false}, result.Documents)
+}
+
+func TestIntegration_LatencyStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		db.people.insertOne({ a: 1 });
+		const stats = db.people.latencyStats().toArray();
+		stats.length + "|" + ("reads" in stats[0].latencyStats)
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "1|true", resultText(result))
+}
+
+func TestIntegration_CollectionRunCommand(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		db.people.insertOne({ a: 1 });
+		db.people.runCommand("validate", { full: false }).valid
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "true", resultText(result))
+}
@@ -0,0 +1,52 @@
+package queryengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCollectionProxy_AdminMethodsExist(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	val, err := rt.RunString(`
+		['hideIndex', 'unhideIndex', 'latencyStats', 'compact', 'reIndex',
+		 'getShardVersion', 'configureQueryAnalyzer', 'analyzeShardKey',
+		 'getSearchIndexes', 'checkMetadataConsistency', 'runCommand']
+			.every(m => typeof db.users[m] === 'function')
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, true, val.Export())
+}
+
+func TestCollectionProxy_AdminMethods_PanicWithoutClient(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	_, err := rt.RunString(`db.users.latencyStats()`)
+	assert.Error(t, err)
+}
+
+func TestCollectionProxy_HideIndex_RequiresIndex(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	_, err := rt.RunString(`db.users.hideIndex()`)
+	assert.ErrorContains(t, err, "requires an index name or key pattern")
+}
+
+func TestCollectionProxy_RunCommand_RequiresName(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	_, err := rt.RunString(`db.users.runCommand({ compact: 1 })`)
+	assert.ErrorContains(t, err, "requires a command name")
+}
+
+func TestIndexSpec(t *testing.T) {
+	spec, err := indexSpec("email_1")
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "name", Value: "email_1"}}, spec)
+
+	spec, err = indexSpec(map[string]any{"email": int64(1)})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "keyPattern", Value: bson.D{{Key: "email", Value: int64(1)}}}}, spec)
+
+	_, err = indexSpec(int64(3))
+	assert.Error(t, err)
+}
@@ -157,7 +157,9 @@ func setCollectionInfoMethods(obj *goja.Object, ec *execContext, collName string
 			{Key: "dropTarget", Value: dropTarget},
 		}
 		var result bson.M
-		if err := ec.client.Database("admin").RunCommand(ec.ctx, cmd).Decode(&result); err != nil {
+		err := ec.client.Database("admin").RunCommand(ec.ctx, cmd).Decode(&result)
+		ec.audit(collName, "renameCollection", []any{newName, dropTarget}, 0, err)
+		if err != nil {
 			panic(rt.NewGoError(fmt.Errorf("renameCollection: %w", err)))
 		}
 		return toJSValue(rt, result)
@@ -194,6 +196,7 @@ func setCollectionInfoMethods(obj *goja.Object, ec *execContext, collName string
 			panic(rt.NewGoError(fmt.Errorf("findAndModify: spec must be an object")))
 		}
 		result, err := runFindAndModify(ec, collName, spec)
+		ec.audit(collName, "findAndModify", []any{spec}, 0, err)
 		if err != nil {
 			panic(rt.NewGoError(err))
 		}
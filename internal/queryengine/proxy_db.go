@@ -42,6 +42,20 @@ var dbMethodNames = map[string]bool{
 	"grantRolesToRole":         true,
 	"revokeRolesFromRole":      true,
 	"dropAllRoles":             true,
+	"serverStatus":             true,
+	"hostInfo":                 true,
+	"hello":                    true,
+	"currentOp":                true,
+	"killOp":                   true,
+	"setProfilingLevel":        true,
+	"getProfilingStatus":       true,
+	"getLogComponents":         true,
+	"setLogLevel":              true,
+	"fsyncLock":                true,
+	"fsyncUnlock":              true,
+	"getReplicationInfo":       true,
+	"printCollectionStats":     true,
+	"rotateCertificates":       true,
 }
 
 // newDatabaseProxy creates a Goja Proxy object that intercepts property access.
@@ -119,6 +133,34 @@ func newDatabaseProxy(ec *execContext) goja.Value {
 				return ec.rt.ToValue(dbRevokeRolesFromRole(ec))
 			case "dropAllRoles":
 				return ec.rt.ToValue(dbDropAllRoles(ec))
+			case "serverStatus":
+				return ec.rt.ToValue(dbServerStatus(ec))
+			case "hostInfo":
+				return ec.rt.ToValue(dbHostInfo(ec))
+			case "hello":
+				return ec.rt.ToValue(dbHello(ec))
+			case "currentOp":
+				return ec.rt.ToValue(dbCurrentOp(ec))
+			case "killOp":
+				return ec.rt.ToValue(dbKillOp(ec))
+			case "setProfilingLevel":
+				return ec.rt.ToValue(dbSetProfilingLevel(ec))
+			case "getProfilingStatus":
+				return ec.rt.ToValue(dbGetProfilingStatus(ec))
+			case "getLogComponents":
+				return ec.rt.ToValue(dbGetLogComponents(ec))
+			case "setLogLevel":
+				return ec.rt.ToValue(dbSetLogLevel(ec))
+			case "fsyncLock":
+				return ec.rt.ToValue(dbFsyncLock(ec))
+			case "fsyncUnlock":
+				return ec.rt.ToValue(dbFsyncUnlock(ec))
+			case "getReplicationInfo":
+				return ec.rt.ToValue(dbGetReplicationInfo(ec))
+			case "printCollectionStats":
+				return ec.rt.ToValue(dbPrintCollectionStats(ec))
+			case "rotateCertificates":
+				return ec.rt.ToValue(dbRotateCertificates(ec))
 			}
 			return newCollectionProxy(ec, property)
 		},
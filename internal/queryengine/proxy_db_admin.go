@@ -0,0 +1,361 @@
+package queryengine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// runAdminHelper runs cmd against dbName for the db helper named method and
+// returns the raw reply, panicking into the script on failure.
+func runAdminHelper(ec *execContext, method, dbName string, cmd bson.D) bson.M {
+	requireClient(ec)
+	var result bson.M
+	if err := ec.client.Database(dbName).RunCommand(ec.ctx, cmd).Decode(&result); err != nil {
+		panic(ec.rt.NewGoError(fmt.Errorf("%s: %w", method, err)))
+	}
+	return result
+}
+
+// runAuditedHelper is runAdminHelper for a helper that changes the server:
+// the command is audited as method, on collection coll of dbName or on the
+// database itself when coll is empty, whether it succeeds or not.
+func runAuditedHelper(ec *execContext, method, dbName, coll string, cmd bson.D) bson.M {
+	requireClient(ec)
+	var result bson.M
+	err := ec.client.Database(dbName).RunCommand(ec.ctx, cmd).Decode(&result)
+	ec.auditCommand(dbName, coll, method, cmd, err)
+	if err != nil {
+		panic(ec.rt.NewGoError(fmt.Errorf("%s: %w", method, err)))
+	}
+	return result
+}
+
+// withOptions appends the fields of an optional options document argument to
+// cmd, after the command name.
+func withOptions(cmd bson.D, arg goja.Value) bson.D {
+	if arg == nil || goja.IsUndefined(arg) || goja.IsNull(arg) {
+		return cmd
+	}
+	if opts, ok := convertToBson(exportValue(arg)).(bson.D); ok {
+		return append(cmd, opts...)
+	}
+	return cmd
+}
+
+// dbServerStatus returns a function: db.serverStatus(options?) → object
+func dbServerStatus(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		cmd := withOptions(bson.D{{Key: "serverStatus", Value: 1}}, call.Argument(0))
+		return toJSValue(ec.rt, runAdminHelper(ec, "serverStatus", "admin", cmd))
+	}
+}
+
+// dbHostInfo returns a function: db.hostInfo() → object
+func dbHostInfo(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		return toJSValue(ec.rt, runAdminHelper(ec, "hostInfo", "admin", bson.D{{Key: "hostInfo", Value: 1}}))
+	}
+}
+
+// dbHello returns a function: db.hello() → object
+func dbHello(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		return toJSValue(ec.rt, runAdminHelper(ec, "hello", ec.dbName, bson.D{{Key: "hello", Value: 1}}))
+	}
+}
+
+// currentOpCommand builds the currentOp command for db.currentOp's argument:
+// true reports idle connections and system operations too, as mongosh's
+// `$all` does, and a document filters the operations reported.
+func currentOpCommand(arg any) bson.D {
+	cmd := bson.D{{Key: "currentOp", Value: 1}}
+	switch v := arg.(type) {
+	case bool:
+		if v {
+			cmd = append(cmd, bson.E{Key: "$all", Value: true})
+		}
+	case map[string]any:
+		if filter, ok := convertToBson(v).(bson.D); ok {
+			cmd = append(cmd, filter...)
+		}
+	}
+	return cmd
+}
+
+// dbCurrentOp returns a function: db.currentOp(filter?) → { inprog: [...], ok }
+func dbCurrentOp(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		cmd := currentOpCommand(exportValue(call.Argument(0)))
+		return toJSValue(ec.rt, runAdminHelper(ec, "currentOp", "admin", cmd))
+	}
+}
+
+// dbKillOp returns a function: db.killOp(opid) → { info, ok }
+func dbKillOp(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(ec.rt.NewGoError(fmt.Errorf("killOp requires an operation id")))
+		}
+		cmd := bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: convertToBson(exportValue(call.Arguments[0]))}}
+		return toJSValue(ec.rt, runAuditedHelper(ec, "killOp", "admin", "", cmd))
+	}
+}
+
+// profileCommand builds the profile command for db.setProfilingLevel. The
+// second argument is either slowms as a number or an options document
+// (slowms, sampleRate, filter), as in mongosh.
+func profileCommand(level int64, opts any) (bson.D, error) {
+	if level < 0 || level > 2 {
+		return nil, fmt.Errorf("profiling level must be 0, 1 or 2")
+	}
+	cmd := bson.D{{Key: "profile", Value: level}}
+	switch v := opts.(type) {
+	case nil:
+	case int64:
+		cmd = append(cmd, bson.E{Key: "slowms", Value: v})
+	case float64:
+		cmd = append(cmd, bson.E{Key: "slowms", Value: int64(v)})
+	case map[string]any:
+		if doc, ok := convertToBson(v).(bson.D); ok {
+			cmd = append(cmd, doc...)
+		}
+	default:
+		return nil, fmt.Errorf("profiling options must be a number or a document")
+	}
+	return cmd, nil
+}
+
+// dbSetProfilingLevel returns a function:
+// db.setProfilingLevel(level, slowms|options?) → { was, slowms, sampleRate, ok }
+func dbSetProfilingLevel(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(ec.rt.NewGoError(fmt.Errorf("setProfilingLevel requires a level")))
+		}
+		cmd, err := profileCommand(call.Arguments[0].ToInteger(), exportValue(call.Argument(1)))
+		if err != nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("setProfilingLevel: %w", err)))
+		}
+		return toJSValue(ec.rt, runAuditedHelper(ec, "setProfilingLevel", ec.dbName, "", cmd))
+	}
+}
+
+// dbGetProfilingStatus returns a function: db.getProfilingStatus() → { was, slowms, sampleRate }
+func dbGetProfilingStatus(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		result := runAdminHelper(ec, "getProfilingStatus", ec.dbName, bson.D{{Key: "profile", Value: -1}})
+		delete(result, "ok")
+		return toJSValue(ec.rt, result)
+	}
+}
+
+// dbGetLogComponents returns a function: db.getLogComponents() → object of
+// verbosity levels by component
+func dbGetLogComponents(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		result := runAdminHelper(ec, "getLogComponents", "admin", bson.D{
+			{Key: "getParameter", Value: 1},
+			{Key: "logComponentVerbosity", Value: 1},
+		})
+		return toJSValue(ec.rt, result["logComponentVerbosity"])
+	}
+}
+
+// logVerbosityDoc nests a verbosity level under a dotted log component, so
+// ("storage.journal", 2) becomes { storage: { journal: { verbosity: 2 } } }.
+// No component sets the default verbosity.
+func logVerbosityDoc(level int64, component string) bson.D {
+	doc := bson.D{{Key: "verbosity", Value: level}}
+	if component == "" {
+		return doc
+	}
+	parts := strings.Split(component, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		doc = bson.D{{Key: parts[i], Value: doc}}
+	}
+	return doc
+}
+
+// dbSetLogLevel returns a function: db.setLogLevel(level, component?) → { was, ok }
+func dbSetLogLevel(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(ec.rt.NewGoError(fmt.Errorf("setLogLevel requires a level")))
+		}
+		component := ""
+		if arg := call.Argument(1); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			component = arg.String()
+		}
+		cmd := bson.D{
+			{Key: "setParameter", Value: 1},
+			{Key: "logComponentVerbosity", Value: logVerbosityDoc(call.Arguments[0].ToInteger(), component)},
+		}
+		return toJSValue(ec.rt, runAuditedHelper(ec, "setLogLevel", "admin", "", cmd))
+	}
+}
+
+// dbFsyncLock returns a function: db.fsyncLock(options?) → { info, lockCount, ok }
+func dbFsyncLock(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		cmd := withOptions(bson.D{{Key: "fsync", Value: 1}, {Key: "lock", Value: true}}, call.Argument(0))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "fsyncLock", "admin", "", cmd))
+	}
+}
+
+// dbFsyncUnlock returns a function: db.fsyncUnlock() → { info, lockCount, ok }
+func dbFsyncUnlock(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		return toJSValue(ec.rt, runAuditedHelper(ec, "fsyncUnlock", "admin", "", bson.D{{Key: "fsyncUnlock", Value: 1}}))
+	}
+}
+
+// dbRotateCertificates returns a function: db.rotateCertificates(message?) → { ok }
+func dbRotateCertificates(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		cmd := bson.D{{Key: "rotateCertificates", Value: 1}}
+		if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			cmd = append(cmd, bson.E{Key: "message", Value: arg.String()})
+		}
+		return toJSValue(ec.rt, runAuditedHelper(ec, "rotateCertificates", "admin", "", cmd))
+	}
+}
+
+// dbGetReplicationInfo returns a function: db.getReplicationInfo() → the oplog
+// size and time window, computed from local.oplog.rs as mongosh does.
+func dbGetReplicationInfo(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		requireClient(ec)
+		info, err := replicationInfo(ec)
+		if err != nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("getReplicationInfo: %w", err)))
+		}
+		return toJSValue(ec.rt, info)
+	}
+}
+
+func replicationInfo(ec *execContext) (bson.M, error) {
+	local := ec.client.Database("local")
+	names, err := local.ListCollectionNames(ec.ctx, bson.D{{Key: "name", Value: "oplog.rs"}})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return bson.M{"errmsg": "replication not detected"}, nil
+	}
+
+	var stats bson.M
+	if err := local.RunCommand(ec.ctx, bson.D{{Key: "collStats", Value: "oplog.rs"}}).Decode(&stats); err != nil {
+		return nil, err
+	}
+	const mb = 1024 * 1024
+	info := bson.M{
+		"configuredLogSizeMB": toFloat(stats["maxSize"]) / mb,
+		"logSizeMB":           toFloat(stats["maxSize"]) / mb,
+		"usedMB":              math.Ceil(toFloat(stats["size"])/mb*100) / 100,
+	}
+
+	oplog := local.Collection("oplog.rs")
+	first, err := oplogTime(ec, oplog, 1)
+	if err != nil {
+		return nil, err
+	}
+	last, err := oplogTime(ec, oplog, -1)
+	if err != nil {
+		return nil, err
+	}
+	if first.IsZero() || last.IsZero() {
+		info["errmsg"] = "objects not found in local.oplog.rs"
+		return info, nil
+	}
+
+	diff := last.Sub(first)
+	info["timeDiff"] = int64(diff.Seconds())
+	info["timeDiffHours"] = math.Round(diff.Hours()*100) / 100
+	info["tFirst"] = first.UTC().Format(time.RFC1123)
+	info["tLast"] = last.UTC().Format(time.RFC1123)
+	info["now"] = time.Now().UTC().Format(time.RFC1123)
+	return info, nil
+}
+
+// oplogTime returns the wall-clock time of the first (direction 1) or last
+// (direction -1) oplog entry, or the zero time for an empty oplog.
+func oplogTime(ec *execContext, oplog *mongo.Collection, direction int) (time.Time, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "$natural", Value: direction}}).
+		SetProjection(bson.D{{Key: "ts", Value: 1}})
+	var entry struct {
+		TS bson.Timestamp `bson:"ts"`
+	}
+	err := oplog.FindOne(ec.ctx, bson.D{}, opts).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(entry.TS.T), 0), nil
+}
+
+// toFloat reads a numeric field from a command reply, whichever BSON number
+// type the server chose.
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// dbPrintCollectionStats returns a function: db.printCollectionStats(scale?).
+// Like mongosh it prints each collection's name, its stats and a "---"
+// separator rather than returning them.
+func dbPrintCollectionStats(ec *execContext) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		requireClient(ec)
+		scale := int64(1)
+		if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			scale = arg.ToInteger()
+		}
+
+		names, err := ec.client.Database(ec.dbName).ListCollectionNames(ec.ctx, bson.D{})
+		if err != nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("printCollectionStats: %w", err)))
+		}
+		sort.Strings(names)
+
+		printFn, _ := goja.AssertFunction(ec.rt.Get("print"))
+		printjson, _ := goja.AssertFunction(ec.rt.Get("printjson"))
+		if printFn == nil || printjson == nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("printCollectionStats: no output available")))
+		}
+		for _, name := range names {
+			stats := runAdminHelper(ec, "printCollectionStats", ec.dbName, bson.D{
+				{Key: "collStats", Value: name},
+				{Key: "scale", Value: scale},
+			})
+			if _, err := printFn(goja.Undefined(), ec.rt.ToValue(name)); err != nil {
+				panic(err)
+			}
+			if _, err := printjson(goja.Undefined(), toJSValue(ec.rt, stats)); err != nil {
+				panic(err)
+			}
+			if _, err := printFn(goja.Undefined(), ec.rt.ToValue("---")); err != nil {
+				panic(err)
+			}
+		}
+		return goja.Undefined()
+	}
+}
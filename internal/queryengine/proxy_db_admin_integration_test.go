@@ -0,0 +1,93 @@
+//go:build integration
+
+package queryengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ServerStatusAndHello(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		const status = db.serverStatus();
+		const hello = db.hello();
+		[typeof status.uptime, typeof hello.isWritablePrimary, typeof db.hostInfo().system]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"number", "boolean", "object"}, result.Documents)
+}
+
+func TestIntegration_CurrentOp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `Array.isArray(db.currentOp(true).inprog)`)
+	require.NoError(t, err)
+	assert.Equal(t, "true", resultText(result))
+}
+
+func TestIntegration_ProfilingLevel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		db.setProfilingLevel(1, 250);
+		const status = db.getProfilingStatus();
+		db.setProfilingLevel(0);
+		status.was + "|" + status.slowms
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "1|250", resultText(result))
+}
+
+func TestIntegration_LogLevel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		const before = db.getLogComponents().query.verbosity;
+		db.setLogLevel(1, "query");
+		const during = db.getLogComponents().query.verbosity;
+		db.setLogLevel(before, "query");
+		during
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "1", resultText(result))
+}
+
+func TestIntegration_PrintCollectionStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+	engine := NewGojaEngine(testClient, 0, "")
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, `
+		db.alpha.insertOne({ a: 1 });
+		db.printCollectionStats();
+	`)
+	require.NoError(t, err)
+	assert.Contains(t, result.RawOutput, "alpha\n{")
+	assert.Contains(t, result.RawOutput, "\n---")
+}
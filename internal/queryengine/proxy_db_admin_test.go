@@ -0,0 +1,65 @@
+package queryengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestDatabaseProxy_AdminMethodsExist(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	val, err := rt.RunString(`
+		['serverStatus', 'hostInfo', 'hello', 'currentOp', 'killOp',
+		 'setProfilingLevel', 'getProfilingStatus', 'getLogComponents',
+		 'setLogLevel', 'fsyncLock', 'fsyncUnlock', 'getReplicationInfo',
+		 'printCollectionStats', 'rotateCertificates']
+			.every(m => typeof db[m] === 'function')
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, true, val.Export())
+}
+
+func TestDatabaseProxy_AdminMethods_PanicWithoutClient(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	_, err := rt.RunString(`db.serverStatus()`)
+	assert.Error(t, err)
+}
+
+func TestDatabaseProxy_KillOp_RequiresID(t *testing.T) {
+	rt, _ := setupRuntime(t)
+	_, err := rt.RunString(`db.killOp()`)
+	assert.ErrorContains(t, err, "requires an operation id")
+}
+
+func TestCurrentOpCommand(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "currentOp", Value: 1}}, currentOpCommand(nil))
+	assert.Equal(t, bson.D{{Key: "currentOp", Value: 1}, {Key: "$all", Value: true}}, currentOpCommand(true))
+	assert.Equal(t,
+		bson.D{{Key: "currentOp", Value: 1}, {Key: "active", Value: true}},
+		currentOpCommand(map[string]any{"active": true}))
+}
+
+func TestProfileCommand(t *testing.T) {
+	cmd, err := profileCommand(1, int64(50))
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "profile", Value: int64(1)}, {Key: "slowms", Value: int64(50)}}, cmd)
+
+	cmd, err = profileCommand(2, map[string]any{"sampleRate": 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "profile", Value: int64(2)}, {Key: "sampleRate", Value: 0.5}}, cmd)
+
+	_, err = profileCommand(3, nil)
+	assert.Error(t, err)
+
+	_, err = profileCommand(1, "fast")
+	assert.Error(t, err)
+}
+
+func TestLogVerbosityDoc(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "verbosity", Value: int64(1)}}, logVerbosityDoc(1, ""))
+	assert.Equal(t,
+		bson.D{{Key: "storage", Value: bson.D{{Key: "journal", Value: bson.D{{Key: "verbosity", Value: int64(2)}}}}}},
+		logVerbosityDoc(2, "storage.journal"))
+}
@@ -72,6 +72,7 @@ func dbCreateCollection(ec *execContext) func(goja.FunctionCall) goja.Value {
 
 		name := call.Arguments[0].String()
 		err := ec.client.Database(ec.dbName).CreateCollection(ec.ctx, name)
+		ec.audit(name, "createCollection", nil, 0, err)
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("createCollection: %w", err)))
 		}
@@ -110,6 +111,7 @@ func dbCreateView(ec *execContext) func(goja.FunctionCall) goja.Value {
 
 		var result bson.M
 		err := ec.client.Database(ec.dbName).RunCommand(ec.ctx, cmd).Decode(&result)
+		ec.audit(name, "createView", []any{source, pipelineRaw}, 0, err)
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("createView: %w", err)))
 		}
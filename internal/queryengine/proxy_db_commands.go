@@ -16,11 +16,14 @@ func dbRunCommand(ec *execContext, dbName string) func(goja.FunctionCall) goja.V
 			panic(ec.rt.NewGoError(fmt.Errorf("runCommand requires a command document")))
 		}
 
-		cmdRaw := exportValue(call.Arguments[0])
-		cmdDoc := convertToBson(cmdRaw)
+		// The command's name is its first field, so the order must hold.
+		cmdDoc := orderedValue(call.Arguments[0])
 
 		var result bson.M
 		err := ec.client.Database(dbName).RunCommand(ec.ctx, cmdDoc).Decode(&result)
+		if cmd, ok := cmdDoc.(bson.D); ok && commandChanges(cmd) {
+			ec.auditCommand(dbName, "", cmd[0].Key, cmd, err)
+		}
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("runCommand: %w", err)))
 		}
@@ -66,6 +69,7 @@ func dbDropDatabase(ec *execContext) func(goja.FunctionCall) goja.Value {
 		requireClient(ec)
 
 		err := ec.client.Database(ec.dbName).Drop(ec.ctx)
+		ec.audit("", "dropDatabase", nil, 0, err)
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("dropDatabase: %w", err)))
 		}
@@ -1,11 +1,24 @@
 package queryengine
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/dop251/goja"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+// ServerResolver gives scripts the clients of other registered servers, so
+// Mongo() and connect() can name a server instead of spelling out its
+// connection string.
+type ServerResolver interface {
+	// ResolveClient returns a connected client for the registered server
+	// whose name or ID is ref, connecting to it first if needed.
+	ResolveClient(ref string) (*mongo.Client, error)
+}
+
 // dbGetSiblingDB returns a function: db.getSiblingDB(name) → db proxy for that database
 func dbGetSiblingDB(ec *execContext) func(goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
@@ -15,29 +28,119 @@ func dbGetSiblingDB(ec *execContext) func(goja.FunctionCall) goja.Value {
 
 		siblingName := call.Arguments[0].String()
 		siblingEC := &execContext{
-			ctx:    ec.ctx,
-			client: ec.client,
-			dbName: siblingName,
-			rt:     ec.rt,
+			ctx:     ec.ctx,
+			client:  ec.client,
+			dbName:  siblingName,
+			rt:      ec.rt,
+			shell:   ec.shell,
+			auditor: ec.auditor,
+			server:  ec.server,
 		}
 		return newDatabaseProxy(siblingEC)
 	}
 }
 
-// dbGetMongo returns a function: db.getMongo() → a simple object representing the connection.
-// In Vervet this is a stub since connections are managed by the app, not scripts.
+// dbGetMongo returns a function: db.getMongo() → the connection the db
+// proxy runs on.
 func dbGetMongo(ec *execContext) func(goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
-		obj := ec.rt.NewObject()
-		_ = obj.Set("getDB", func(name string) goja.Value {
-			siblingEC := &execContext{
-				ctx:    ec.ctx,
-				client: ec.client,
-				dbName: name,
-				rt:     ec.rt,
-			}
-			return newDatabaseProxy(siblingEC)
-		})
-		return obj
+		return newMongoProxy(ec, ec.client, ec.server)
+	}
+}
+
+// newMongoProxy builds the script-side Mongo connection object for client,
+// the connection to server. Connections are owned by the app, not the
+// script, so close() leaves the client open.
+func newMongoProxy(ec *execContext, client *mongo.Client, server string) *goja.Object {
+	obj := ec.rt.NewObject()
+	getDB := func(name string) goja.Value { return clientDatabase(ec, client, server, name) }
+	_ = obj.Set("getDB", getDB)
+	_ = obj.Set("getSiblingDB", getDB)
+	_ = obj.Set("getDBNames", func(goja.FunctionCall) goja.Value {
+		if client == nil {
+			panic(ec.rt.NewGoError(fmt.Errorf("no active connection")))
+		}
+		names, err := client.ListDatabaseNames(ec.ctx, bson.D{})
+		if err != nil {
+			panic(ec.rt.NewGoError(err))
+		}
+		return ec.rt.ToValue(names)
+	})
+	_ = obj.Set("close", func(goja.FunctionCall) goja.Value { return goja.Undefined() })
+	return obj
+}
+
+// clientDatabase is a db proxy for database name on client, the connection
+// to server, running in the same script as ec.
+func clientDatabase(ec *execContext, client *mongo.Client, server, name string) goja.Value {
+	return newDatabaseProxy(&execContext{
+		ctx:      ec.ctx,
+		client:   client,
+		dbName:   name,
+		rt:       ec.rt,
+		pageSize: ec.pageSize,
+		shell:    ec.shell,
+		auditor:  ec.auditor,
+		server:   server,
+	})
+}
+
+// registerMongo installs the Mongo() constructor and connect(). Mongo() with
+// no argument is the script's own connection; Mongo("name") and
+// connect("name/db") reach another registered server through servers.
+func registerMongo(ec *execContext, servers ServerResolver) error {
+	resolve := func(ref string) *mongo.Client {
+		client, err := resolveScriptServer(servers, ref)
+		if err != nil {
+			panic(ec.rt.NewGoError(err))
+		}
+		return client
+	}
+
+	if err := ec.rt.Set("Mongo", func(call goja.ConstructorCall) *goja.Object {
+		ref := call.Argument(0)
+		if goja.IsUndefined(ref) || goja.IsNull(ref) {
+			return newMongoProxy(ec, ec.client, ec.server)
+		}
+		return newMongoProxy(ec, resolve(ref.String()), ref.String())
+	}); err != nil {
+		return fmt.Errorf("failed to set Mongo global: %w", err)
+	}
+
+	if err := ec.rt.Set("connect", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			panic(ec.rt.NewGoError(fmt.Errorf("connect requires a server name, e.g. connect(\"staging/reporting\")")))
+		}
+		ref, dbName := splitConnectTarget(call.Argument(0).String())
+		return clientDatabase(ec, resolve(ref), ref, dbName)
+	}); err != nil {
+		return fmt.Errorf("failed to set connect global: %w", err)
+	}
+	return nil
+}
+
+// splitConnectTarget splits connect()'s "server/db" argument. The database
+// follows the last slash, so a server name may itself contain slashes; with
+// no slash the database is "test", as in mongosh.
+func splitConnectTarget(target string) (server, dbName string) {
+	if i := strings.LastIndex(target, "/"); i >= 0 {
+		return target[:i], target[i+1:]
+	}
+	return target, "test"
+}
+
+// resolveScriptServer looks up a server a script named. Connection strings
+// are refused outright: the point of naming a registered server is that its
+// credentials never appear in the script.
+func resolveScriptServer(servers ServerResolver, ref string) (*mongo.Client, error) {
+	if strings.HasPrefix(ref, "mongodb://") || strings.HasPrefix(ref, "mongodb+srv://") {
+		return nil, errors.New("connection strings are not accepted in scripts; pass the name or ID of a registered server")
+	}
+	if ref == "" {
+		return nil, errors.New("a registered server name or ID is required")
+	}
+	if servers == nil {
+		return nil, fmt.Errorf("cannot connect to %q: other servers are not available to this script", ref)
 	}
+	return servers.ResolveClient(ref)
 }
@@ -0,0 +1,100 @@
+package queryengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// fakeServers resolves every known name to a nil client and records what
+// the script asked for.
+type fakeServers struct {
+	known []string
+	asked []string
+}
+
+func (f *fakeServers) ResolveClient(ref string) (*mongo.Client, error) {
+	f.asked = append(f.asked, ref)
+	for _, k := range f.known {
+		if k == ref {
+			return nil, nil
+		}
+	}
+	return nil, errors.New("no registered server named " + ref)
+}
+
+func setupMongoRuntime(t *testing.T, servers ServerResolver) *goja.Runtime {
+	t.Helper()
+	rt, ec := setupRuntime(t)
+	require.NoError(t, registerMongo(ec, servers))
+	return rt
+}
+
+func TestMongo_NoArgumentIsOwnConnection(t *testing.T) {
+	servers := &fakeServers{}
+	rt := setupMongoRuntime(t, servers)
+	val, err := rt.RunString(`new Mongo().getDB("other").getName()`)
+	require.NoError(t, err)
+	assert.Equal(t, "other", val.Export())
+	assert.Empty(t, servers.asked)
+}
+
+func TestMongo_ResolvesRegisteredServer(t *testing.T) {
+	servers := &fakeServers{known: []string{"staging"}}
+	rt := setupMongoRuntime(t, servers)
+	val, err := rt.RunString(`[new Mongo("staging").getDB("ref").getName(), Mongo("staging").getSiblingDB("x").getName()]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"ref", "x"}, val.Export())
+	assert.Equal(t, []string{"staging", "staging"}, servers.asked)
+}
+
+func TestConnect_ServerAndDatabase(t *testing.T) {
+	servers := &fakeServers{known: []string{"team/staging"}}
+	rt := setupMongoRuntime(t, servers)
+	val, err := rt.RunString(`connect("team/staging/reporting").getName()`)
+	require.NoError(t, err)
+	assert.Equal(t, "reporting", val.Export())
+	assert.Equal(t, []string{"team/staging"}, servers.asked)
+}
+
+func TestConnect_UnknownServerErrors(t *testing.T) {
+	rt := setupMongoRuntime(t, &fakeServers{})
+	_, err := rt.RunString(`connect("nowhere/db")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no registered server named nowhere")
+}
+
+func TestConnect_RefusesConnectionStrings(t *testing.T) {
+	servers := &fakeServers{}
+	rt := setupMongoRuntime(t, servers)
+	_, err := rt.RunString(`new Mongo("mongodb://user:pw@host:27017")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection strings are not accepted")
+	assert.Empty(t, servers.asked)
+}
+
+func TestConnect_WithoutResolverErrors(t *testing.T) {
+	rt := setupMongoRuntime(t, nil)
+	_, err := rt.RunString(`connect("staging/db")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not available to this script")
+}
+
+func TestSplitConnectTarget(t *testing.T) {
+	tests := []struct {
+		target, server, db string
+	}{
+		{"staging/reporting", "staging", "reporting"},
+		{"staging", "staging", "test"},
+		{"group/staging/reporting", "group/staging", "reporting"},
+	}
+	for _, tt := range tests {
+		server, db := splitConnectTarget(tt.target)
+		assert.Equal(t, tt.server, server, tt.target)
+		assert.Equal(t, tt.db, db, tt.target)
+	}
+}
@@ -70,7 +70,7 @@ func dbCreateRole(ec *execContext) func(goja.FunctionCall) goja.Value {
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("createRole: %w", err)))
 		}
-		return toJSValue(ec.rt, runDBCommand(ec, "createRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "createRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -82,7 +82,7 @@ func dbDropRole(ec *execContext) func(goja.FunctionCall) goja.Value {
 		}
 		name := call.Arguments[0].String()
 		cmd := bson.D{{Key: "dropRole", Value: name}}
-		return toJSValue(ec.rt, runDBCommand(ec, "dropRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "dropRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -128,7 +128,7 @@ func dbUpdateRole(ec *execContext) func(goja.FunctionCall) goja.Value {
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("updateRole: %w", err)))
 		}
-		return toJSValue(ec.rt, runDBCommand(ec, "updateRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "updateRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -141,7 +141,7 @@ func dbGrantPrivilegesToRole(ec *execContext) func(goja.FunctionCall) goja.Value
 		name := call.Arguments[0].String()
 		privs := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "grantPrivilegesToRole", Value: name}, {Key: "privileges", Value: privs}}
-		return toJSValue(ec.rt, runDBCommand(ec, "grantPrivilegesToRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "grantPrivilegesToRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -154,7 +154,7 @@ func dbRevokePrivilegesFromRole(ec *execContext) func(goja.FunctionCall) goja.Va
 		name := call.Arguments[0].String()
 		privs := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "revokePrivilegesFromRole", Value: name}, {Key: "privileges", Value: privs}}
-		return toJSValue(ec.rt, runDBCommand(ec, "revokePrivilegesFromRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "revokePrivilegesFromRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -167,7 +167,7 @@ func dbGrantRolesToRole(ec *execContext) func(goja.FunctionCall) goja.Value {
 		name := call.Arguments[0].String()
 		roles := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "grantRolesToRole", Value: name}, {Key: "roles", Value: roles}}
-		return toJSValue(ec.rt, runDBCommand(ec, "grantRolesToRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "grantRolesToRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -180,7 +180,7 @@ func dbRevokeRolesFromRole(ec *execContext) func(goja.FunctionCall) goja.Value {
 		name := call.Arguments[0].String()
 		roles := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "revokeRolesFromRole", Value: name}, {Key: "roles", Value: roles}}
-		return toJSValue(ec.rt, runDBCommand(ec, "revokeRolesFromRole", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "revokeRolesFromRole", ec.dbName, "", cmd))
 	}
 }
 
@@ -188,6 +188,6 @@ func dbDropAllRoles(ec *execContext) func(goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
 		requireClient(ec)
 		cmd := bson.D{{Key: "dropAllRolesFromDatabase", Value: 1}}
-		return toJSValue(ec.rt, runDBCommand(ec, "dropAllRoles", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "dropAllRoles", ec.dbName, "", cmd))
 	}
 }
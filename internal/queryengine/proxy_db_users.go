@@ -81,7 +81,7 @@ func dbCreateUser(ec *execContext) func(goja.FunctionCall) goja.Value {
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("createUser: %w", err)))
 		}
-		return toJSValue(ec.rt, runDBCommand(ec, "createUser", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "createUser", ec.dbName, "", cmd))
 	}
 }
 
@@ -93,7 +93,7 @@ func dbDropUser(ec *execContext) func(goja.FunctionCall) goja.Value {
 		}
 		name := call.Arguments[0].String()
 		cmd := bson.D{{Key: "dropUser", Value: name}}
-		return toJSValue(ec.rt, runDBCommand(ec, "dropUser", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "dropUser", ec.dbName, "", cmd))
 	}
 }
 
@@ -139,7 +139,7 @@ func dbUpdateUser(ec *execContext) func(goja.FunctionCall) goja.Value {
 		if err != nil {
 			panic(ec.rt.NewGoError(fmt.Errorf("updateUser: %w", err)))
 		}
-		return toJSValue(ec.rt, runDBCommand(ec, "updateUser", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "updateUser", ec.dbName, "", cmd))
 	}
 }
 
@@ -152,7 +152,7 @@ func dbChangeUserPassword(ec *execContext) func(goja.FunctionCall) goja.Value {
 		name := call.Arguments[0].String()
 		pwd := call.Arguments[1].String()
 		cmd := bson.D{{Key: "updateUser", Value: name}, {Key: "pwd", Value: pwd}}
-		return toJSValue(ec.rt, runDBCommand(ec, "changeUserPassword", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "changeUserPassword", ec.dbName, "", cmd))
 	}
 }
 
@@ -165,7 +165,7 @@ func dbGrantRolesToUser(ec *execContext) func(goja.FunctionCall) goja.Value {
 		name := call.Arguments[0].String()
 		roles := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "grantRolesToUser", Value: name}, {Key: "roles", Value: roles}}
-		return toJSValue(ec.rt, runDBCommand(ec, "grantRolesToUser", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "grantRolesToUser", ec.dbName, "", cmd))
 	}
 }
 
@@ -178,7 +178,7 @@ func dbRevokeRolesFromUser(ec *execContext) func(goja.FunctionCall) goja.Value {
 		name := call.Arguments[0].String()
 		roles := convertToBson(exportValue(call.Arguments[1]))
 		cmd := bson.D{{Key: "revokeRolesFromUser", Value: name}, {Key: "roles", Value: roles}}
-		return toJSValue(ec.rt, runDBCommand(ec, "revokeRolesFromUser", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "revokeRolesFromUser", ec.dbName, "", cmd))
 	}
 }
 
@@ -186,6 +186,6 @@ func dbDropAllUsers(ec *execContext) func(goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
 		requireClient(ec)
 		cmd := bson.D{{Key: "dropAllUsersFromDatabase", Value: 1}}
-		return toJSValue(ec.rt, runDBCommand(ec, "dropAllUsers", cmd))
+		return toJSValue(ec.rt, runAuditedHelper(ec, "dropAllUsers", ec.dbName, "", cmd))
 	}
 }
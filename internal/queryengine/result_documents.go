@@ -0,0 +1,201 @@
+package queryengine
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+// DefaultDocumentLimit is how many bytes of a single document a query result
+// hands the frontend before it starts leaving values out. A document over the
+// limit keeps its _id and as many of its values as fit; the rest are replaced
+// by truncation markers the UI loads on demand (see GojaEngine.FetchValue).
+const DefaultDocumentLimit = 1 << 20
+
+// truncatedKey names the marker a value left out of an oversized document is
+// replaced by:
+//
+//	{"$vervetTruncated": {"path": ["a", "b"], "type": "string", "size": 123456}}
+//
+// path is the keys leading to the value in the stored document (array
+// elements by index), kept apart rather than dotted since a field name may
+// itself contain a dot, and size is its length in BSON. A marker that also carries "omitted"
+// stands for that many trailing elements of the array or document at path,
+// left out once there was no room even for their markers.
+const truncatedKey = "$vervetTruncated"
+
+// markerSize is roughly what a truncation marker costs, charged against the
+// document limit so an oversized array of small values cannot turn into an
+// even larger array of markers.
+const markerSize = 96
+
+// resultDocument is one document of a query result, kept as the BSON the
+// server sent. It encodes straight to canonical Extended JSON, so key order
+// and every BSON type survive the trip to the frontend without passing through
+// a Go map on the way.
+type resultDocument struct {
+	raw bson.Raw
+	// limit caps the encoded document's BSON size; zero leaves it whole.
+	limit int
+}
+
+func (d resultDocument) MarshalJSON() ([]byte, error) {
+	raw := d.raw
+	if d.limit > 0 && len(raw) > d.limit {
+		raw = truncateDocument(raw, d.limit)
+	}
+	return bson.MarshalExtJSON(raw, true, false)
+}
+
+// scriptDocument decodes the whole document into the canonical Extended JSON
+// map scripts are handed (see scriptValue). Scripts always see every value:
+// the limit only applies to what is displayed.
+func (d resultDocument) scriptDocument() any {
+	data, err := bson.MarshalExtJSON(d.raw, true, false)
+	if err == nil {
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err == nil {
+			return m
+		}
+	}
+	var m bson.M
+	_ = bson.Unmarshal(d.raw, &m)
+	return m
+}
+
+// limitDocuments returns docs with every raw document capped at limit bytes.
+func limitDocuments(docs []any, limit int) []any {
+	out := make([]any, len(docs))
+	for i, doc := range docs {
+		if d, ok := doc.(resultDocument); ok {
+			d.limit = limit
+			doc = d
+		}
+		out[i] = doc
+	}
+	return out
+}
+
+// truncateDocument copies doc with values left out until it fits in about
+// limit bytes. The top-level _id is always kept, so a left-out value can be
+// fetched again by _id and path.
+func truncateDocument(doc bson.Raw, limit int) bson.Raw {
+	budget := limit
+	out, err := appendTruncated(nil, bsoncore.Document(doc), nil, &budget, false)
+	if err != nil {
+		return doc
+	}
+	return bson.Raw(out)
+}
+
+// appendTruncated appends a copy of doc, a document or array at path, to dst.
+// Values are copied while they fit in budget. One that does not is descended
+// into when it is a document or array and there is room for more than a few
+// markers, and otherwise replaced by a marker. Once even a marker no longer
+// fits, the remaining elements share a single marker.
+func appendTruncated(dst []byte, doc bsoncore.Document, path []string, budget *int, isArray bool) ([]byte, error) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil, err
+	}
+	idx, dst := bsoncore.AppendDocumentStart(dst)
+	for i, elem := range elems {
+		key := elem.Key()
+		val := elem.Value()
+		size := len(val.Data)
+		switch {
+		case size <= *budget || (len(path) == 0 && key == "_id"):
+			dst = bsoncore.AppendValueElement(dst, key, val)
+			*budget -= size
+		case *budget < markerSize:
+			rest := 0
+			for _, e := range elems[i:] {
+				rest += len(e.Value().Data)
+			}
+			dst = appendMarker(dst, key, path, containerType(isArray), rest, len(elems)-i)
+			*budget = 0
+			return bsoncore.AppendDocumentEnd(dst, idx)
+		case (val.Type == bsoncore.TypeEmbeddedDocument || val.Type == bsoncore.TypeArray) && *budget >= 4*markerSize:
+			dst = bsoncore.AppendHeader(dst, val.Type, key)
+			if dst, err = appendTruncated(dst, val.Data, childPath(path, key), budget, val.Type == bsoncore.TypeArray); err != nil {
+				return nil, err
+			}
+		default:
+			dst = appendMarker(dst, key, childPath(path, key), valueType(val.Type), size, 0)
+			*budget -= markerSize
+		}
+	}
+	return bsoncore.AppendDocumentEnd(dst, idx)
+}
+
+// appendMarker appends a truncation marker for the value at path as the
+// element key.
+func appendMarker(dst []byte, key string, path []string, typ string, size, omitted int) []byte {
+	outer, dst := bsoncore.AppendDocumentElementStart(dst, key)
+	inner, dst := bsoncore.AppendDocumentElementStart(dst, truncatedKey)
+	keys, dst := bsoncore.AppendArrayElementStart(dst, "path")
+	for i, k := range path {
+		dst = bsoncore.AppendStringElement(dst, strconv.Itoa(i), k)
+	}
+	dst, _ = bsoncore.AppendArrayEnd(dst, keys)
+	dst = bsoncore.AppendStringElement(dst, "type", typ)
+	dst = bsoncore.AppendInt64Element(dst, "size", int64(size))
+	if omitted > 0 {
+		dst = bsoncore.AppendInt64Element(dst, "omitted", int64(omitted))
+	}
+	dst, _ = bsoncore.AppendDocumentEnd(dst, inner)
+	dst, _ = bsoncore.AppendDocumentEnd(dst, outer)
+	return dst
+}
+
+// childPath is path with key added, copied so sibling paths don't share
+// their backing array.
+func childPath(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+func containerType(isArray bool) string {
+	if isArray {
+		return "array"
+	}
+	return "object"
+}
+
+// valueType names a BSON type the way the result tree's type column does.
+func valueType(t bsoncore.Type) string {
+	switch t {
+	case bsoncore.TypeString:
+		return "string"
+	case bsoncore.TypeEmbeddedDocument:
+		return "object"
+	case bsoncore.TypeArray:
+		return "array"
+	case bsoncore.TypeBinary:
+		return "binary"
+	case bsoncore.TypeJavaScript, bsoncore.TypeCodeWithScope:
+		return "javascript"
+	case bsoncore.TypeRegex:
+		return "regex"
+	case bsoncore.TypeSymbol:
+		return "symbol"
+	}
+	return t.String()
+}
+
+// rawValueJSON encodes a single BSON value as canonical Extended JSON.
+func rawValueJSON(val bson.RawValue) (json.RawMessage, error) {
+	if val.Type == bson.TypeEmbeddedDocument {
+		return bson.MarshalExtJSON(val.Document(), true, false)
+	}
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: val}}, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper["v"], nil
+}
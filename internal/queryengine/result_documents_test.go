@@ -0,0 +1,295 @@
+package queryengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func mustRaw(t testing.TB, doc bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return raw
+}
+
+// marker returns the truncation marker at v, or nil when v is not one.
+func marker(v any) map[string]any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	inner, _ := m[truncatedKey].(map[string]any)
+	return inner
+}
+
+func TestResultDocument_KeepsKeyOrderAndTypes(t *testing.T) {
+	raw := mustRaw(t, bson.D{
+		{Key: "z", Value: int32(1)},
+		{Key: "a", Value: int64(2)},
+		{Key: "m", Value: 1.5},
+		{Key: "when", Value: bson.DateTime(0)},
+	})
+
+	data, err := json.Marshal(resultDocument{raw: raw})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"z": {"$numberInt": "1"},
+		"a": {"$numberLong": "2"},
+		"m": {"$numberDouble": "1.5"},
+		"when": {"$date": {"$numberLong": "0"}}
+	}`, string(data))
+	assert.Less(t, strings.Index(string(data), `"z"`), strings.Index(string(data), `"a"`))
+}
+
+func TestResultDocument_UnderLimitIsWhole(t *testing.T) {
+	raw := mustRaw(t, bson.D{{Key: "_id", Value: int32(1)}, {Key: "s", Value: "short"}})
+
+	whole, err := json.Marshal(resultDocument{raw: raw})
+	require.NoError(t, err)
+	limited, err := json.Marshal(resultDocument{raw: raw, limit: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, string(whole), string(limited))
+}
+
+func TestResultDocument_TruncatesLargeValues(t *testing.T) {
+	big := strings.Repeat("x", 4096)
+	raw := mustRaw(t, bson.D{
+		{Key: "_id", Value: "id-1"},
+		{Key: "name", Value: "small"},
+		{Key: "body", Value: big},
+		{Key: "after", Value: int32(7)},
+	})
+
+	data, err := json.Marshal(resultDocument{raw: raw, limit: 1024})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Equal(t, "id-1", doc["_id"])
+	assert.Equal(t, "small", doc["name"])
+	assert.Equal(t, map[string]any{"$numberInt": "7"}, doc["after"])
+	m := marker(doc["body"])
+	require.NotNil(t, m, "body should be replaced by a marker")
+	assert.Equal(t, []any{"body"}, m["path"])
+	assert.Equal(t, "string", m["type"])
+	assert.Equal(t, map[string]any{"$numberLong": fmt.Sprint(len(big) + 5)}, m["size"], "size is the BSON length")
+	assert.NotContains(t, m, "omitted")
+}
+
+func TestResultDocument_KeepsIDOverLimit(t *testing.T) {
+	id := strings.Repeat("i", 2048)
+	raw := mustRaw(t, bson.D{{Key: "_id", Value: id}, {Key: "v", Value: strings.Repeat("v", 2048)}})
+
+	data, err := json.Marshal(resultDocument{raw: raw, limit: 1024})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, id, doc["_id"])
+	assert.NotNil(t, marker(doc["v"]))
+}
+
+func TestResultDocument_DescendsIntoNestedValues(t *testing.T) {
+	raw := mustRaw(t, bson.D{
+		{Key: "_id", Value: int32(1)},
+		{Key: "outer", Value: bson.D{
+			{Key: "keep", Value: "yes"},
+			{Key: "list", Value: bson.A{"a", strings.Repeat("b", 4096)}},
+		}},
+	})
+
+	data, err := json.Marshal(resultDocument{raw: raw, limit: 1024})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	outer := doc["outer"].(map[string]any)
+	assert.Equal(t, "yes", outer["keep"])
+	list := outer["list"].([]any)
+	require.Len(t, list, 2)
+	assert.Equal(t, "a", list[0])
+	m := marker(list[1])
+	require.NotNil(t, m)
+	assert.Equal(t, []any{"outer", "list", "1"}, m["path"])
+}
+
+func TestResultDocument_CollapsesTheRestOfALongArray(t *testing.T) {
+	items := make(bson.A, 10000)
+	for i := range items {
+		items[i] = int64(i)
+	}
+	raw := mustRaw(t, bson.D{{Key: "_id", Value: int32(1)}, {Key: "items", Value: items}})
+
+	data, err := json.Marshal(resultDocument{raw: raw, limit: 4096})
+	require.NoError(t, err)
+	assert.Less(t, len(data), 16*1024, "markers must not outgrow the values they replace")
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	list := doc["items"].([]any)
+	last := marker(list[len(list)-1])
+	require.NotNil(t, last, "the remaining elements share one marker")
+	assert.Equal(t, []any{"items"}, last["path"])
+	assert.Equal(t, "array", last["type"])
+	assert.Equal(t, map[string]any{"$numberLong": fmt.Sprint(10000 - (len(list) - 1))}, last["omitted"])
+	assert.Equal(t, map[string]any{"$numberLong": "0"}, list[0])
+}
+
+func TestLimitDocuments_OnlyCapsRawDocuments(t *testing.T) {
+	raw := resultDocument{raw: mustRaw(t, bson.D{{Key: "a", Value: int32(1)}})}
+	plain := map[string]any{"a": 1}
+
+	out := limitDocuments([]any{raw, plain}, 512)
+	assert.Equal(t, 512, out[0].(resultDocument).limit)
+	assert.Equal(t, plain, out[1])
+	assert.Zero(t, raw.limit, "the input is left alone")
+}
+
+func TestNormalizeForJS_DecodesWholeResultDocument(t *testing.T) {
+	raw := mustRaw(t, bson.D{{Key: "n", Value: int64(5)}, {Key: "s", Value: strings.Repeat("s", 4096)}})
+
+	got := normalizeForJS(resultDocument{raw: raw, limit: 64})
+	doc, ok := got.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"$numberLong": "5"}, doc["n"])
+	assert.Len(t, doc["s"], 4096, "scripts see values the display leaves out")
+}
+
+func TestRawValueJSON(t *testing.T) {
+	raw := mustRaw(t, bson.D{
+		{Key: "s", Value: "text"},
+		{Key: "d", Value: bson.D{{Key: "b", Value: int32(1)}, {Key: "a", Value: int32(2)}}},
+		{Key: "l", Value: bson.A{int64(1)}},
+	})
+
+	s, err := rawValueJSON(raw.Lookup("s"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"text"`, string(s))
+
+	d, err := rawValueJSON(raw.Lookup("d"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":{"$numberInt":"1"},"a":{"$numberInt":"2"}}`, string(d))
+
+	l, err := rawValueJSON(raw.Lookup("l"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"$numberLong":"1"}]`, string(l))
+}
+
+func TestResultDocument_MarkerKeepsDottedKeysApart(t *testing.T) {
+	raw := mustRaw(t, bson.D{
+		{Key: "_id", Value: int32(1)},
+		{Key: "a.b", Value: bson.D{
+			{Key: "c", Value: "small"},
+			{Key: "d.e", Value: strings.Repeat("x", 4096)},
+		}},
+	})
+
+	data, err := json.Marshal(resultDocument{raw: raw, limit: 1024})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	m := marker(doc["a.b"].(map[string]any)["d.e"])
+	require.NotNil(t, m)
+	path := []string{}
+	for _, k := range m["path"].([]any) {
+		path = append(path, k.(string))
+	}
+	assert.Equal(t, []string{"a.b", "d.e"}, path)
+	val, err := raw.LookupErr(path...)
+	require.NoError(t, err, "the marker's keys find the value")
+	assert.Equal(t, strings.Repeat("x", 4096), val.StringValue())
+}
+
+// benchDocs builds n documents shaped like typical application data: a mix of
+// ids, dates, numbers, strings and a nested array of subdocuments.
+func benchDocs(b *testing.B, n int) []bson.Raw {
+	docs := make([]bson.Raw, n)
+	for i := range docs {
+		items := make(bson.A, 10)
+		for j := range items {
+			items[j] = bson.D{
+				{Key: "sku", Value: fmt.Sprintf("SKU-%05d", j)},
+				{Key: "qty", Value: int32(j)},
+				{Key: "price", Value: float64(j) * 1.25},
+			}
+		}
+		docs[i] = mustRaw(b, bson.D{
+			{Key: "_id", Value: bson.NewObjectID()},
+			{Key: "customerId", Value: int64(i)},
+			{Key: "createdAt", Value: bson.NewDateTimeFromTime(time.Unix(int64(i), 0))},
+			{Key: "status", Value: "shipped"},
+			{Key: "notes", Value: strings.Repeat("lorem ipsum ", 20)},
+			{Key: "items", Value: items},
+		})
+	}
+	return docs
+}
+
+// legacyDocsToResult is the result path docsToResult replaced: decode each
+// document into a map, encode it as Extended JSON and decode that again into
+// generic Go values for encoding/json to marshal a third time.
+func legacyDocsToResult(docs []bson.M) []any {
+	cleaned := make([]any, 0, len(docs))
+	for _, doc := range docs {
+		b, err := bson.MarshalExtJSON(doc, true, false)
+		if err != nil {
+			cleaned = append(cleaned, doc)
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			cleaned = append(cleaned, doc)
+			continue
+		}
+		cleaned = append(cleaned, m)
+	}
+	return cleaned
+}
+
+// The benchmarks cover the whole trip from the driver's BSON to the JSON
+// handed to the frontend.
+
+func BenchmarkResultPath_Legacy(b *testing.B) {
+	raws := benchDocs(b, 1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		docs := make([]bson.M, len(raws))
+		for i, raw := range raws {
+			if err := bson.Unmarshal(raw, &docs[i]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if _, err := json.Marshal(legacyDocsToResult(docs)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResultPath_Raw(b *testing.B) {
+	raws := benchDocs(b, 1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		result := docsToResult(raws)
+		if _, err := json.Marshal(limitDocuments(result.Documents, DefaultDocumentLimit)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResultPath_RawTruncated(b *testing.B) {
+	raws := benchDocs(b, 1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		result := docsToResult(raws)
+		if _, err := json.Marshal(limitDocuments(result.Documents, 512)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"vervet/internal/models"
+	"vervet/internal/queryengine/jsmodules"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -202,3 +204,143 @@ func TestGojaEngine_BaseDirIsPerEngine(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "B", resB.RawOutput)
 }
+
+func TestGojaEngine_Require_RelativeToScriptDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0o755))
+	writeScript(t, filepath.Join(dir, "lib"), "helpers.js", `exports.double = n => n * 2;`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		`const { double } = require('./lib/helpers'); double(21)`)
+	require.NoError(t, err)
+	assert.Equal(t, "42", res.RawOutput)
+}
+
+// Unlike load(), a required module keeps its declarations to itself.
+func TestGojaEngine_Require_DoesNotLeakGlobals(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "helpers.js", `const secret = 1; function hidden() {} module.exports = 'ok';`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		`[require('./helpers'), typeof secret, typeof hidden]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"ok", "undefined", "undefined"}, res.Documents)
+}
+
+func TestGojaEngine_Require_NodeModulesFromScriptDir(t *testing.T) {
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "node_modules", "team-utils")
+	require.NoError(t, os.MkdirAll(pkg, 0o755))
+	writeScript(t, pkg, "package.json", `{"main": "main.js"}`)
+	writeScript(t, pkg, "main.js", `module.exports = { team: 'data' };`)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "scripts"), 0o755))
+	main := filepath.Join(dir, "scripts", "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `require('team-utils').team`)
+	require.NoError(t, err)
+	assert.Equal(t, "data", res.RawOutput)
+}
+
+// Module caches are per execution: an edit to a helper between two runs is
+// picked up by the second.
+func TestGojaEngine_Require_CacheIsPerExecution(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "version.js", `module.exports = 1;`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `require('./version')`)
+	require.NoError(t, err)
+	assert.Equal(t, "1", res.RawOutput)
+
+	writeScript(t, dir, "version.js", `module.exports = 2;`)
+	res, err = NewGojaEngine(nil, 100, main).ExecuteQuery(context.Background(), "", "testdb", `require('./version')`)
+	require.NoError(t, err)
+	assert.Equal(t, "2", res.RawOutput)
+}
+
+// readline's line events are delivered once the script's own code is done,
+// and an awaited close lets the script's result depend on them.
+func TestGojaEngine_Readline_AwaitClose(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "totals.csv", "10\n20\n12\n")
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `
+		const rl = require('readline').createInterface({ input: require('fs').createReadStream('totals.csv') });
+		let sum = 0;
+		rl.on('line', (line) => { sum += Number(line); });
+		(async () => { await require('events').once(rl, 'close'); return sum; })()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "42", res.RawOutput)
+}
+
+func TestGojaEngine_RejectedPromiseResultIsError(t *testing.T) {
+	eng := NewGojaEngine(nil, 100, "")
+	_, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		`(async () => { throw new Error('check failed'); })()`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check failed")
+}
+
+func TestGojaEngine_Sandbox_LoadOutsideRootsIsRefused(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	helper := writeScript(t, outside, "helper.js", `var loaded = true;`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	eng.SetSandbox(jsmodules.Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{dir}})
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		`try { load(`+strconv.Quote(helper)+`); 'loaded'; } catch (e) { e.code; }`)
+	require.NoError(t, err)
+	assert.Equal(t, "EACCES", res.RawOutput)
+}
+
+func TestGojaEngine_Sandbox_LoadInsideRootsWorks(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "helper.js", `function helperFn() { return 7; }`)
+	main := filepath.Join(dir, "main.js")
+
+	eng := NewGojaEngine(nil, 100, main)
+	eng.SetSandbox(jsmodules.Sandbox{Mode: models.ScriptSandboxReadOnly, Roots: []string{dir}})
+	res, err := eng.ExecuteQuery(context.Background(), "", "testdb", `load('helper.js'); helperFn()`)
+	require.NoError(t, err)
+	assert.Equal(t, "7", res.RawOutput)
+}
+
+func TestGojaEngine_Sandbox_HidesEnvironment(t *testing.T) {
+	t.Setenv("VERVET_SANDBOX_TEST", "secret")
+
+	open := NewGojaEngine(nil, 100, "")
+	res, err := open.ExecuteQuery(context.Background(), "", "testdb", `process.env.VERVET_SANDBOX_TEST`)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", res.RawOutput)
+
+	restricted := NewGojaEngine(nil, 100, "")
+	restricted.SetSandbox(jsmodules.Sandbox{Mode: models.ScriptSandboxWorkspace})
+	res, err = restricted.ExecuteQuery(context.Background(), "", "testdb", `Object.keys(process.env).length`)
+	require.NoError(t, err)
+	assert.Equal(t, "0", res.RawOutput)
+}
+
+func TestGojaEngine_Sandbox_ImportOutsideRootsFailsToBundle(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	helper := writeScript(t, outside, "data.json", `{"token": "abc"}`)
+	main := filepath.Join(dir, "main.ts")
+
+	eng := NewGojaEngine(nil, 100, main)
+	eng.SetSandbox(jsmodules.Sandbox{Mode: models.ScriptSandboxWorkspace, Roots: []string{dir}})
+	_, err := eng.ExecuteQuery(context.Background(), "", "testdb",
+		`import data from `+strconv.Quote(helper)+`; data.token`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
@@ -191,3 +191,20 @@ func TestIntegration_Script_EndToEndMaintenanceScript(t *testing.T) {
 		"total: 3",
 	}, "\n"), got)
 }
+
+// The shell commands a pasted mongosh session is full of.
+func TestIntegration_Script_ShellCommands(t *testing.T) {
+	engine, db, ctx := setupScriptData(t)
+	got := runScript(t, engine, ctx, db, `
+		use admin
+		print(db.getName());
+		use `+db+`
+		db.responses.find().sort({ CustomerId: 1 });
+		print(String(it.toArray()[0].CustomerId));
+	`)
+	assert.Equal(t, "admin\n2309810", got)
+
+	result, err := engine.ExecuteQuery(ctx, testURI, db, "show collections")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"responses"}, result.Documents)
+}
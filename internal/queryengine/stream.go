@@ -0,0 +1,64 @@
+package queryengine
+
+import (
+	"context"
+	"fmt"
+
+	"vervet/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// streamBatchSize is the cursor batch size for streamed reads, large enough
+// that an export of millions of documents is not dominated by round trips.
+const streamBatchSize = 1000
+
+// OpenPageCursor opens a cursor over everything a PageContext's find returns,
+// within the find's own skip and limit, for callers that read the whole
+// result rather than a page at a time. The find's maxTimeMS is left out: it
+// was meant for one page, not the whole result. The caller closes the cursor.
+func (e *GojaEngine) OpenPageCursor(ctx context.Context, dbName string, pc models.PageContext) (*mongo.Cursor, error) {
+	dbName, pc, err := resolvePageContext(dbName, pc)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.D{}
+	if pc.Filter != nil {
+		filter = toBsonDoc(pc.Filter)
+	}
+	opts := options.Find()
+	switch proj := pc.Projection.(type) {
+	case map[string]any:
+		opts.SetProjection(toBsonDoc(proj))
+	case bson.D:
+		opts.SetProjection(proj)
+	}
+	applyFindOptions(opts, CapturedOp{
+		Limit:     pc.UserLimit,
+		Skip:      pc.UserSkip,
+		Sort:      pc.Sort,
+		Hint:      pc.Hint,
+		Collation: pc.Collation,
+		Comment:   pc.Comment,
+		BatchSize: streamBatchSize,
+	})
+
+	cursor, err := e.client.Database(dbName).Collection(pc.Collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find failed: %w", err)
+	}
+	return cursor, nil
+}
+
+// OpenAggregateCursor opens a cursor over a pipeline's results. The caller
+// closes the cursor.
+func (e *GojaEngine) OpenAggregateCursor(ctx context.Context, dbName, collection string, pipeline bson.A) (*mongo.Cursor, error) {
+	opts := options.Aggregate().SetBatchSize(streamBatchSize).SetAllowDiskUse(true)
+	cursor, err := e.client.Database(dbName).Collection(collection).Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate failed: %w", err)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,83 @@
+//go:build integration
+
+package queryengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func drain(t *testing.T, ctx context.Context, cur *mongo.Cursor) []bson.M {
+	t.Helper()
+	defer cur.Close(ctx)
+	var docs []bson.M
+	require.NoError(t, cur.All(ctx, &docs))
+	return docs
+}
+
+func TestIntegration_OpenPageCursor_ReadsPastThePageSize(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+
+	engine := NewGojaEngine(testClient, 25, "")
+	docs := make([]string, 60)
+	for i := range docs {
+		docs[i] = fmt.Sprintf("{n:%d, odd:%t}", i, i%2 == 1)
+	}
+	_, err := engine.ExecuteQuery(ctx, testURI, db, "db.streamed.insertMany(["+strings.Join(docs, ",")+"])")
+	require.NoError(t, err)
+
+	res, err := engine.ExecuteQuery(ctx, testURI, db, `db.streamed.find({odd: true}, {_id: 0, n: 1}).sort({n: -1}).skip(2)`)
+	require.NoError(t, err)
+	require.NotNil(t, res.PageContext)
+
+	cur, err := engine.OpenPageCursor(ctx, db, *res.PageContext)
+	require.NoError(t, err)
+	got := drain(t, ctx, cur)
+
+	require.Len(t, got, 28, "every match after the skip, not one page")
+	assert.Equal(t, bson.M{"n": int32(55)}, got[0])
+	assert.Equal(t, bson.M{"n": int32(1)}, got[27])
+}
+
+func TestIntegration_OpenAggregateCursor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := dbName(t)
+	defer testClient.Database(db).Drop(ctx)
+
+	coll := testClient.Database(db).Collection("orders")
+	_, err := coll.InsertMany(ctx, []any{
+		bson.D{{Key: "status", Value: "open"}, {Key: "total", Value: 5}},
+		bson.D{{Key: "status", Value: "open"}, {Key: "total", Value: 7}},
+		bson.D{{Key: "status", Value: "closed"}, {Key: "total", Value: 3}},
+	})
+	require.NoError(t, err)
+
+	engine := NewGojaEngine(testClient, 25, "")
+	cur, err := engine.OpenAggregateCursor(ctx, db, "orders", bson.A{
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$status"},
+			{Key: "sum", Value: bson.D{{Key: "$sum", Value: "$total"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []bson.M{
+		{"_id": "closed", "sum": int32(3)},
+		{"_id": "open", "sum": int32(12)},
+	}, drain(t, ctx, cur))
+}
@@ -0,0 +1,95 @@
+package queryexecutor
+
+import (
+	"context"
+	"fmt"
+
+	"vervet/internal/audit"
+	"vervet/internal/models"
+	"vervet/internal/queryengine"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// The results view's document edits run through the Go driver whichever
+// engine is selected, so each one is audited even when mongosh runs the
+// query tab's scripts. Documents are written in shell syntax, as the edit
+// dialog writes them; ids are canonical Extended JSON, as FetchValue takes.
+
+// InsertDocument inserts document into collection.
+func (qe *QueryExecutor) InsertDocument(serverID, dbName, collection, document string) error {
+	doc, err := queryengine.ParseDocument(document)
+	if err != nil {
+		return err
+	}
+	return qe.editDocument(serverID, dbName, collection, "insertOne", bson.A{doc},
+		func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+			if _, err := coll.InsertOne(ctx, doc); err != nil {
+				return 0, fmt.Errorf("insertOne failed: %w", err)
+			}
+			return 1, nil
+		})
+}
+
+// ReplaceDocument replaces the document whose _id is id with document.
+func (qe *QueryExecutor) ReplaceDocument(serverID, dbName, collection, id, document string) error {
+	filter, err := queryengine.IDFilter(id)
+	if err != nil {
+		return err
+	}
+	doc, err := queryengine.ParseDocument(document)
+	if err != nil {
+		return err
+	}
+	return qe.editDocument(serverID, dbName, collection, "replaceOne", bson.A{filter, doc},
+		func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+			res, err := coll.ReplaceOne(ctx, filter, doc)
+			if err != nil {
+				return 0, fmt.Errorf("replaceOne failed: %w", err)
+			}
+			if res.MatchedCount == 0 {
+				return 0, fmt.Errorf("document %s no longer exists", id)
+			}
+			return res.ModifiedCount, nil
+		})
+}
+
+// DeleteDocument deletes the document whose _id is id.
+func (qe *QueryExecutor) DeleteDocument(serverID, dbName, collection, id string) error {
+	filter, err := queryengine.IDFilter(id)
+	if err != nil {
+		return err
+	}
+	return qe.editDocument(serverID, dbName, collection, "deleteOne", bson.A{filter},
+		func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+			res, err := coll.DeleteOne(ctx, filter)
+			if err != nil {
+				return 0, fmt.Errorf("deleteOne failed: %w", err)
+			}
+			if res.DeletedCount == 0 {
+				return 0, fmt.Errorf("document %s no longer exists", id)
+			}
+			return res.DeletedCount, nil
+		})
+}
+
+// editDocument runs write against collection and audits it as operation,
+// called with args.
+func (qe *QueryExecutor) editDocument(serverID, dbName, collection, operation string, args bson.A, write func(context.Context, *mongo.Collection) (int64, error)) error {
+	client, err := qe.registry.GetClient(serverID)
+	if err != nil {
+		return fmt.Errorf("no active connection: %w", err)
+	}
+	affected, err := write(qe.ctx, client.Database(dbName).Collection(collection))
+	audit.RecordOrLog(qe.log, qe.audit, models.AuditAction{
+		ServerID:      serverID,
+		Database:      dbName,
+		Collection:    collection,
+		Operation:     operation,
+		Source:        models.AuditSourceApp,
+		Arguments:     args,
+		AffectedCount: affected,
+	}, err)
+	return err
+}
@@ -12,21 +12,54 @@ import (
 	"time"
 	"vervet/internal/clientregistry"
 	"vervet/internal/connectionStrings"
+	"vervet/internal/errcodes"
 	"vervet/internal/logging"
 	"vervet/internal/models"
 	"vervet/internal/queryengine"
+	"vervet/internal/queryengine/jsmodules"
 	"vervet/internal/shell"
-)
 
-// ErrPagingUnsupported is returned by FetchPage / CountForPage when the active
-// query engine doesn't support server-side paging (e.g. mongosh).
-var ErrPagingUnsupported = errors.New("paging is supported only for the builtin engine")
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
 
 // SettingsProvider allows QueryExecutor to read app settings without depending on the full settings package.
 type SettingsProvider interface {
 	GetSettings() (models.Settings, error)
 }
 
+// WorkspaceProvider gives QueryExecutor the workspace folders a sandboxed
+// script may reach, without depending on the full workspaces package.
+type WorkspaceProvider interface {
+	GetWorkspaces() (models.WorkspaceData, error)
+}
+
+// ServerDirectory lists the registered servers a script can name in Mongo()
+// and connect().
+type ServerDirectory interface {
+	GetServers() ([]models.RegisteredServer, error)
+}
+
+// ServerConnector opens the app's connection to a registered server.
+// Implemented by connections.ConnectionManager, which also tells the frontend.
+type ServerConnector interface {
+	Connect(serverID string) (models.Connection, error)
+}
+
+// HistoryRecorder keeps a record of every query the executor runs.
+// Implemented by history.Service.
+type HistoryRecorder interface {
+	Record(entry models.HistoryEntry) error
+}
+
+// AuditRecorder keeps the audit log of the changes scripts make to servers.
+// Implemented by audit.Service.
+type AuditRecorder interface {
+	Record(action models.AuditAction) error
+}
+
 // queryKey identifies a single in-flight query. Keying by both serverID and
 // queryID lets multiple queries run concurrently against the same connection
 // while still allowing a specific query to be cancelled.
@@ -38,23 +71,38 @@ type queryKey struct {
 // QueryExecutor executes queries against connected servers.
 // Each query spawns a one-shot mongosh process or uses the built-in goja engine.
 type QueryExecutor struct {
-	mu       sync.Mutex
-	ctx      context.Context
-	log      *slog.Logger
-	registry *clientregistry.ClientRegistry
-	store    connectionStrings.Store
-	cancels  map[queryKey]context.CancelFunc // (serverID, queryID) -> cancel for in-flight query
-	cfg      shell.Config
-	settings SettingsProvider
+	mu         sync.Mutex
+	ctx        context.Context
+	log        *slog.Logger
+	registry   *clientregistry.ClientRegistry
+	store      connectionStrings.Store
+	cancels    map[queryKey]context.CancelFunc // (serverID, queryID) -> cancel for in-flight query
+	cfg        shell.Config
+	settings   SettingsProvider
+	workspaces WorkspaceProvider
+	servers    ServerDirectory
+	connector  ServerConnector
+	history    HistoryRecorder
+	audit      AuditRecorder
+	prompts    map[string]chan promptAnswer // promptID -> the script waiting on it
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
 }
 
-func NewQueryExecutor(log *slog.Logger, registry *clientregistry.ClientRegistry, store connectionStrings.Store, settings SettingsProvider) *QueryExecutor {
+func NewQueryExecutor(log *slog.Logger, registry *clientregistry.ClientRegistry, store connectionStrings.Store, settings SettingsProvider, workspaces WorkspaceProvider, servers ServerDirectory, connector ServerConnector) *QueryExecutor {
 	return &QueryExecutor{
-		log:      log.With(slog.String(logging.SourceKey, "QueryExecutor")),
-		registry: registry,
-		store:    store,
-		cancels:  make(map[queryKey]context.CancelFunc),
-		settings: settings,
+		log:        log.With(slog.String(logging.SourceKey, "QueryExecutor")),
+		registry:   registry,
+		store:      store,
+		cancels:    make(map[queryKey]context.CancelFunc),
+		settings:   settings,
+		workspaces: workspaces,
+		servers:    servers,
+		connector:  connector,
+		prompts:    make(map[string]chan promptAnswer),
+		emit:       runtime.EventsEmit,
 		cfg: shell.Config{
 			Timeout: 30 * time.Second,
 		},
@@ -66,6 +114,18 @@ func (qe *QueryExecutor) Init(ctx context.Context) {
 	qe.ctx = ctx
 }
 
+// SetHistory makes the executor record each query it runs. Without one,
+// nothing is recorded.
+func (qe *QueryExecutor) SetHistory(h HistoryRecorder) {
+	qe.history = h
+}
+
+// SetAuditor makes the executor audit the changes built-in engine scripts
+// make. Without one, nothing is audited.
+func (qe *QueryExecutor) SetAuditor(a AuditRecorder) {
+	qe.audit = a
+}
+
 // registerQuery records the cancel func for an in-flight query, keyed by
 // (serverID, queryID). It deliberately does NOT cancel other queries for the
 // same server — concurrent queries against one connection are supported.
@@ -100,10 +160,52 @@ func (qe *QueryExecutor) ExecuteQuery(serverID, queryID, dbName, query, scriptPa
 	}()
 
 	cfg, _ := qe.settings.GetSettings()
+	started := time.Now()
+	var result models.QueryResult
+	var err error
 	if cfg.Query.QueryEngine == "builtin" {
-		return qe.executeWithGoja(queryCtx, serverID, dbName, query, scriptPath)
+		result, err = qe.executeWithGoja(queryCtx, serverID, queryID, dbName, query, scriptPath)
+	} else {
+		result, err = qe.executeWithMongosh(queryCtx, serverID, queryID, dbName, query, scriptPath)
+	}
+
+	qe.recordHistory(models.HistoryEntry{
+		ServerID:   serverID,
+		Database:   dbName,
+		ScriptPath: scriptPath,
+		Query:      query,
+		Engine:     cfg.Query.QueryEngine,
+		StartedAt:  started.UnixMilli(),
+		DurationMS: time.Since(started).Milliseconds(),
+	}, result, err, queryCtx.Err())
+	return result, err
+}
+
+// recordHistory completes entry with how the query ended and adds it to the
+// history. A history that cannot be written is logged, never failing the
+// query itself.
+func (qe *QueryExecutor) recordHistory(entry models.HistoryEntry, result models.QueryResult, err, ctxErr error) {
+	if qe.history == nil {
+		return
+	}
+	switch {
+	case err != nil && errors.Is(ctxErr, context.Canceled):
+		entry.Outcome = models.HistoryCancelled
+		entry.ErrorCode = errcodes.QueryCancelled
+	case err != nil:
+		entry.Outcome = models.HistoryFailed
+		entry.ErrorCode = errcodes.ClassifyError(err).Code
+	default:
+		entry.Outcome = models.HistorySucceeded
+		entry.ResultCount = len(result.Documents)
+		entry.ResultsPaged = result.PageContext != nil && entry.ResultCount > 0
+		if entry.ResultCount == 0 {
+			entry.ResultCount = result.AffectedCount
+		}
+	}
+	if err := qe.history.Record(entry); err != nil {
+		qe.log.Warn("Failed to record query history", slog.Any("error", err))
 	}
-	return qe.executeWithMongosh(queryCtx, serverID, dbName, query, scriptPath)
 }
 
 // scriptDir is the directory a saved query tab lives in, empty when the tab
@@ -115,7 +217,7 @@ func scriptDir(scriptPath string) string {
 	return filepath.Dir(scriptPath)
 }
 
-func (qe *QueryExecutor) executeWithGoja(ctx context.Context, serverID, dbName, query, scriptPath string) (models.QueryResult, error) {
+func (qe *QueryExecutor) executeWithGoja(ctx context.Context, serverID, queryID, dbName, query, scriptPath string) (models.QueryResult, error) {
 	client, err := qe.registry.GetClient(serverID)
 	if err != nil {
 		return models.QueryResult{}, fmt.Errorf("no active connection: %w", err)
@@ -123,6 +225,16 @@ func (qe *QueryExecutor) executeWithGoja(ctx context.Context, serverID, dbName,
 
 	cfg, _ := qe.settings.GetSettings()
 	engine := queryengine.NewGojaEngine(client, int64(cfg.Query.DefaultPageSize), scriptPath)
+	engine.SetSandbox(qe.scriptSandbox(cfg.Query.ScriptSandbox, scriptPath))
+	engine.SetServers(qe)
+	output := newOutputStream(qe, serverID, queryID)
+	defer output.Close()
+	ui := &scriptUI{qe: qe, serverID: serverID, queryID: queryID, output: output}
+	defer ui.Close()
+	engine.SetUI(ui)
+	if qe.audit != nil {
+		engine.SetAuditor(&scriptAuditor{qe: qe, serverID: serverID})
+	}
 	result, err := engine.ExecuteQuery(ctx, "", dbName, query)
 	if err != nil {
 		return models.QueryResult{}, err
@@ -131,7 +243,85 @@ func (qe *QueryExecutor) executeWithGoja(ctx context.Context, serverID, dbName,
 	return result, nil
 }
 
-func (qe *QueryExecutor) executeWithMongosh(ctx context.Context, serverID, dbName, query, scriptPath string) (models.QueryResult, error) {
+// scriptSandbox builds the policy a built-in engine script runs under. A
+// restricted script may reach the active workspace's folders and, once the
+// tab is saved, its own directory.
+func (qe *QueryExecutor) scriptSandbox(mode, scriptPath string) jsmodules.Sandbox {
+	sandbox := jsmodules.Sandbox{Mode: mode}
+	if dir := scriptDir(scriptPath); dir != "" {
+		sandbox.Roots = append(sandbox.Roots, dir)
+	}
+	if qe.workspaces == nil {
+		return sandbox
+	}
+
+	data, err := qe.workspaces.GetWorkspaces()
+	if err != nil {
+		qe.log.Warn("Failed to read workspaces for script sandbox", slog.Any("error", err))
+		return sandbox
+	}
+	for _, ws := range data.Workspaces {
+		if ws.ID == data.ActiveWorkspaceID {
+			sandbox.Roots = append(sandbox.Roots, ws.Folders...)
+		}
+	}
+	return sandbox
+}
+
+// ResolveClient gives a script the client for the registered server named or
+// identified by ref. A server the app isn't connected to yet is connected the
+// same way the sidebar does it — OIDC logins included — and stays connected
+// after the script finishes.
+func (qe *QueryExecutor) ResolveClient(ref string) (*mongo.Client, error) {
+	if qe.servers == nil || qe.connector == nil {
+		return nil, fmt.Errorf("cannot connect to %q: registered servers are unavailable", ref)
+	}
+	all, err := qe.servers.GetServers()
+	if err != nil {
+		return nil, err
+	}
+	server, err := findScriptServer(all, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if qe.registry.IsConnected(server.ID) {
+		return qe.registry.GetClient(server.ID)
+	}
+	qe.log.Debug("Connecting to server for script", slog.String("serverID", server.ID))
+	// A concurrent connect may win the race; its client is as good as ours.
+	if _, err := qe.connector.Connect(server.ID); err != nil && !qe.registry.IsConnected(server.ID) {
+		return nil, fmt.Errorf("failed to connect to %s: %w", server.Name, err)
+	}
+	return qe.registry.GetClient(server.ID)
+}
+
+// findScriptServer picks the server a script means by ref: an exact ID
+// first, otherwise the one server with that name. Groups are never a match.
+func findScriptServer(servers []models.RegisteredServer, ref string) (models.RegisteredServer, error) {
+	var byName []models.RegisteredServer
+	for _, s := range servers {
+		if s.IsGroup {
+			continue
+		}
+		if s.ID == ref {
+			return s, nil
+		}
+		if s.Name == ref {
+			byName = append(byName, s)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return models.RegisteredServer{}, fmt.Errorf("no registered server named %q", ref)
+	case 1:
+		return byName[0], nil
+	default:
+		return models.RegisteredServer{}, fmt.Errorf("%d registered servers are named %q; use the server's ID instead", len(byName), ref)
+	}
+}
+
+func (qe *QueryExecutor) executeWithMongosh(ctx context.Context, serverID, queryID, dbName, query, scriptPath string) (models.QueryResult, error) {
 	cfg, err := qe.store.GetConnectionConfig(serverID)
 	if err != nil {
 		return models.QueryResult{}, err
@@ -145,8 +335,13 @@ func (qe *QueryExecutor) executeWithMongosh(ctx context.Context, serverID, dbNam
 		uri = appendDatabase(uri, dbName)
 	}
 
+	settings, _ := qe.settings.GetSettings()
 	shellCfg := qe.cfg
 	shellCfg.ScriptDir = scriptDir(scriptPath)
+	shellCfg.PageSize = int64(settings.Query.DefaultPageSize)
+	output := newOutputStream(qe, serverID, queryID)
+	defer output.Close()
+	shellCfg.OnOutput = output.Line
 
 	var result models.QueryResult
 	if cfg.AuthMethod == models.AuthOIDC {
@@ -162,13 +357,10 @@ func (qe *QueryExecutor) executeWithMongosh(ctx context.Context, serverID, dbNam
 	return result, nil
 }
 
-// FetchPage fetches a single page for a previously captured PageContext using
-// the builtin engine. Returns ErrPagingUnsupported when mongosh is selected.
+// FetchPage fetches a single page for a previously captured PageContext.
+// Whichever engine ran the query, pages are fetched through the Go driver.
 func (qe *QueryExecutor) FetchPage(serverID, dbName string, pc models.PageContext, page, pageSize int64) (models.QueryResult, error) {
 	cfg, _ := qe.settings.GetSettings()
-	if cfg.Query.QueryEngine != "builtin" {
-		return models.QueryResult{}, ErrPagingUnsupported
-	}
 	client, err := qe.registry.GetClient(serverID)
 	if err != nil {
 		return models.QueryResult{}, fmt.Errorf("no active connection: %w", err)
@@ -177,13 +369,9 @@ func (qe *QueryExecutor) FetchPage(serverID, dbName string, pc models.PageContex
 	return engine.FetchPage(qe.ctx, dbName, pc, page, pageSize)
 }
 
-// CountForPage returns the row count for a PageContext using the builtin
-// engine. Returns ErrPagingUnsupported when mongosh is selected.
+// CountForPage returns the row count for a PageContext, through the Go
+// driver whichever engine ran the query.
 func (qe *QueryExecutor) CountForPage(serverID, dbName string, pc models.PageContext) (int64, bool, error) {
-	cfg, _ := qe.settings.GetSettings()
-	if cfg.Query.QueryEngine != "builtin" {
-		return 0, false, ErrPagingUnsupported
-	}
 	client, err := qe.registry.GetClient(serverID)
 	if err != nil {
 		return 0, false, fmt.Errorf("no active connection: %w", err)
@@ -192,6 +380,37 @@ func (qe *QueryExecutor) CountForPage(serverID, dbName string, pc models.PageCon
 	return engine.CountForPage(qe.ctx, dbName, pc)
 }
 
+// OpenPageCursor opens a cursor over every document a PageContext's find
+// returns, for exports that stream the whole result to disk.
+func (qe *QueryExecutor) OpenPageCursor(ctx context.Context, serverID, dbName string, pc models.PageContext) (*mongo.Cursor, error) {
+	client, err := qe.registry.GetClient(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("no active connection: %w", err)
+	}
+	return queryengine.NewGojaEngine(client, 0, "").OpenPageCursor(ctx, dbName, pc)
+}
+
+// OpenAggregateCursor opens a cursor over an aggregation pipeline's results,
+// for exports that stream them to disk.
+func (qe *QueryExecutor) OpenAggregateCursor(ctx context.Context, serverID, dbName, collection string, pipeline bson.A) (*mongo.Cursor, error) {
+	client, err := qe.registry.GetClient(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("no active connection: %w", err)
+	}
+	return queryengine.NewGojaEngine(client, 0, "").OpenAggregateCursor(ctx, dbName, collection, pipeline)
+}
+
+// FetchValue loads a value a query result left out of an oversized document,
+// through the Go driver whichever engine ran the query.
+func (qe *QueryExecutor) FetchValue(serverID, dbName, collection, id string, path []string) (models.QueryResult, error) {
+	client, err := qe.registry.GetClient(serverID)
+	if err != nil {
+		return models.QueryResult{}, fmt.Errorf("no active connection: %w", err)
+	}
+	engine := queryengine.NewGojaEngine(client, 0, "")
+	return engine.FetchValue(qe.ctx, dbName, collection, id, path)
+}
+
 // CancelQuery cancels the in-flight query identified by (serverID, queryID).
 // Other queries against the same server are left running.
 func (qe *QueryExecutor) CancelQuery(serverID, queryID string) {
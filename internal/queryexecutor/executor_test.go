@@ -2,13 +2,21 @@ package queryexecutor
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
 	"testing"
+	"vervet/internal/errcodes"
+	"vervet/internal/models"
 )
 
 func newTestExecutor() *QueryExecutor {
 	return &QueryExecutor{
 		ctx:     context.Background(),
 		cancels: make(map[queryKey]context.CancelFunc),
+		prompts: make(map[string]chan promptAnswer),
+		emit:    func(context.Context, string, ...interface{}) {},
 	}
 }
 
@@ -149,3 +157,241 @@ func TestValidDBName(t *testing.T) {
 		}
 	}
 }
+
+type workspacesStub struct {
+	data models.WorkspaceData
+}
+
+func (w workspacesStub) GetWorkspaces() (models.WorkspaceData, error) { return w.data, nil }
+
+// A restricted script reaches its own directory and the active workspace's
+// folders, and nothing from the other workspaces.
+func TestScriptSandbox_RootsAreScriptDirAndActiveWorkspace(t *testing.T) {
+	qe := newTestExecutor()
+	qe.workspaces = workspacesStub{data: models.WorkspaceData{
+		ActiveWorkspaceID: "b",
+		Workspaces: []models.Workspace{
+			{ID: "a", Folders: []string{"/ws/a"}},
+			{ID: "b", Folders: []string{"/ws/b1", "/ws/b2"}},
+		},
+	}}
+
+	sandbox := qe.scriptSandbox(models.ScriptSandboxWorkspace, "/scripts/fix.js")
+
+	if sandbox.Mode != models.ScriptSandboxWorkspace {
+		t.Fatalf("mode = %q, want %q", sandbox.Mode, models.ScriptSandboxWorkspace)
+	}
+	want := []string{"/scripts", "/ws/b1", "/ws/b2"}
+	if !reflect.DeepEqual(sandbox.Roots, want) {
+		t.Fatalf("roots = %v, want %v", sandbox.Roots, want)
+	}
+}
+
+// An unsaved tab has no directory of its own to grant.
+func TestScriptSandbox_UnsavedTabHasNoScriptDir(t *testing.T) {
+	qe := newTestExecutor()
+
+	sandbox := qe.scriptSandbox(models.ScriptSandboxReadOnly, "")
+
+	if len(sandbox.Roots) != 0 {
+		t.Fatalf("roots = %v, want none", sandbox.Roots)
+	}
+}
+
+func TestFindScriptServer(t *testing.T) {
+	servers := []models.RegisteredServer{
+		{ID: "g1", Name: "staging", IsGroup: true},
+		{ID: "s1", Name: "staging"},
+		{ID: "s2", Name: "local"},
+		{ID: "s3", Name: "dup"},
+		{ID: "s4", Name: "dup"},
+	}
+
+	tests := []struct {
+		ref     string
+		wantID  string
+		wantErr string
+	}{
+		{ref: "staging", wantID: "s1"},
+		{ref: "s2", wantID: "s2"},
+		{ref: "g1", wantErr: `no registered server named "g1"`},
+		{ref: "missing", wantErr: `no registered server named "missing"`},
+		{ref: "dup", wantErr: "use the server's ID"},
+	}
+	for _, tt := range tests {
+		got, err := findScriptServer(servers, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("findScriptServer(%q) error = %v, want containing %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.ID != tt.wantID {
+			t.Errorf("findScriptServer(%q) = %q, %v; want %q", tt.ref, got.ID, err, tt.wantID)
+		}
+	}
+}
+
+func TestResolveClient_WithoutServersErrors(t *testing.T) {
+	qe := newTestExecutor()
+	if _, err := qe.ResolveClient("staging"); err == nil {
+		t.Fatal("expected an error without a server directory")
+	}
+}
+
+type recordingHistory struct {
+	entries []models.HistoryEntry
+	err     error
+}
+
+func (h *recordingHistory) Record(entry models.HistoryEntry) error {
+	h.entries = append(h.entries, entry)
+	return h.err
+}
+
+func TestRecordHistory_Outcomes(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      models.QueryResult
+		err         error
+		ctxErr      error
+		wantOutcome string
+		wantCode    string
+		wantCount   int
+		wantPaged   bool
+	}{
+		{
+			name:        "documents",
+			result:      models.QueryResult{Documents: []any{1, 2, 3}},
+			wantOutcome: models.HistorySucceeded,
+			wantCount:   3,
+		},
+		{
+			name: "first page of a find",
+			result: models.QueryResult{
+				Documents:   []any{1, 2},
+				PageContext: &models.PageContext{},
+			},
+			wantOutcome: models.HistorySucceeded,
+			wantCount:   2,
+			wantPaged:   true,
+		},
+		{
+			name:        "write",
+			result:      models.QueryResult{AffectedCount: 7},
+			wantOutcome: models.HistorySucceeded,
+			wantCount:   7,
+		},
+		{
+			name:        "failed",
+			err:         errors.New("boom"),
+			wantOutcome: models.HistoryFailed,
+			wantCode:    errcodes.UnknownError,
+		},
+		{
+			name:        "cancelled",
+			err:         errors.New("interrupted"),
+			ctxErr:      context.Canceled,
+			wantOutcome: models.HistoryCancelled,
+			wantCode:    errcodes.QueryCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := &recordingHistory{}
+			qe := newTestExecutor()
+			qe.SetHistory(history)
+
+			qe.recordHistory(models.HistoryEntry{ServerID: "srv", Query: "q"}, tt.result, tt.err, tt.ctxErr)
+
+			if len(history.entries) != 1 {
+				t.Fatalf("recorded %d entries, want 1", len(history.entries))
+			}
+			got := history.entries[0]
+			if got.ServerID != "srv" || got.Query != "q" {
+				t.Errorf("entry lost its fields: %+v", got)
+			}
+			if got.Outcome != tt.wantOutcome || got.ErrorCode != tt.wantCode || got.ResultCount != tt.wantCount {
+				t.Errorf("got outcome %q, code %q, count %d; want %q, %q, %d",
+					got.Outcome, got.ErrorCode, got.ResultCount, tt.wantOutcome, tt.wantCode, tt.wantCount)
+			}
+			if got.ResultsPaged != tt.wantPaged {
+				t.Errorf("got paged %v, want %v", got.ResultsPaged, tt.wantPaged)
+			}
+		})
+	}
+}
+
+// A history that cannot be written must not take the query down with it.
+func TestRecordHistory_RecordErrorIsLogged(t *testing.T) {
+	qe := newTestExecutor()
+	qe.log = slog.New(slog.DiscardHandler)
+	qe.SetHistory(&recordingHistory{err: errors.New("disk full")})
+
+	qe.recordHistory(models.HistoryEntry{}, models.QueryResult{}, nil, nil)
+}
+
+type recordingAudit struct {
+	actions []models.AuditAction
+	err     error
+}
+
+func (a *recordingAudit) Record(action models.AuditAction) error {
+	a.actions = append(a.actions, action)
+	return a.err
+}
+
+type serversStub []models.RegisteredServer
+
+func (s serversStub) GetServers() ([]models.RegisteredServer, error) { return s, nil }
+
+func TestScriptAuditor_NamesTheServer(t *testing.T) {
+	audit := &recordingAudit{}
+	qe := newTestExecutor()
+	qe.servers = serversStub{{ID: "id-staging", Name: "staging"}}
+	qe.SetAuditor(audit)
+	auditor := &scriptAuditor{qe: qe, serverID: "id-prod"}
+
+	for _, ref := range []string{"", "staging", "id-staging", "gone"} {
+		auditor.Audit(models.AuditAction{ServerID: ref, Operation: "insertOne"})
+	}
+
+	var got []string
+	for _, a := range audit.actions {
+		got = append(got, a.ServerID)
+	}
+	want := []string{"id-prod", "id-staging", "id-staging", "gone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server IDs = %v, want %v", got, want)
+	}
+}
+
+// An audit log that cannot be written must not fail a script whose change
+// has already been made.
+func TestScriptAuditor_RecordErrorIsLogged(t *testing.T) {
+	qe := newTestExecutor()
+	qe.log = slog.New(slog.DiscardHandler)
+	qe.SetAuditor(&recordingAudit{err: errors.New("disk full")})
+
+	(&scriptAuditor{qe: qe, serverID: "srv"}).Audit(models.AuditAction{Operation: "drop"})
+}
+
+// A document edit that never reached the server is not a change to audit.
+func TestDocumentEdits_RejectBadInput(t *testing.T) {
+	qe := newTestExecutor()
+	audit := &recordingAudit{}
+	qe.SetAuditor(audit)
+
+	if err := qe.InsertDocument("srv", "shop", "orders", `{ total: }`); err == nil {
+		t.Error("an unparseable document was accepted")
+	}
+	if err := qe.ReplaceDocument("srv", "shop", "orders", `{"$oid": 1}`, `{ total: 1 }`); err == nil {
+		t.Error("an invalid _id was accepted")
+	}
+	if err := qe.DeleteDocument("srv", "shop", "orders", `not json`); err == nil {
+		t.Error("an invalid _id was accepted")
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("audited %d rejected edits", len(audit.actions))
+	}
+}
@@ -0,0 +1,39 @@
+package queryexecutor
+
+import (
+	"vervet/internal/audit"
+	"vervet/internal/models"
+)
+
+// scriptAuditor passes the changes a built-in engine script makes to the
+// executor's audit log, naming the server each one was made on.
+type scriptAuditor struct {
+	qe       *QueryExecutor
+	serverID string
+}
+
+func (a *scriptAuditor) Audit(action models.AuditAction) {
+	action.ServerID = a.resolve(action.ServerID)
+	audit.RecordOrLog(a.qe.log, a.qe.audit, action, nil)
+}
+
+// resolve turns the server a script named in Mongo() or connect() into its
+// ID. Empty is the server the script runs on. A name that no longer resolves
+// is kept as the script gave it.
+func (a *scriptAuditor) resolve(ref string) string {
+	if ref == "" {
+		return a.serverID
+	}
+	if a.qe.servers == nil {
+		return ref
+	}
+	all, err := a.qe.servers.GetServers()
+	if err != nil {
+		return ref
+	}
+	server, err := findScriptServer(all, ref)
+	if err != nil {
+		return ref
+	}
+	return server.ID
+}
@@ -0,0 +1,238 @@
+package queryexecutor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"vervet/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// QueryOutputEvent carries a models.QueryOutput from a running query.
+	QueryOutputEvent = "query-output"
+	// QueryProgressEvent carries a models.QueryProgress from a running script.
+	QueryProgressEvent = "query-progress"
+	// QueryPromptEvent carries a models.QueryPrompt the script is waiting on.
+	QueryPromptEvent = "query-prompt"
+)
+
+// progressInterval is the least time between progress events for one query.
+// A migration loop calling progress() per document would otherwise flood the
+// frontend with thousands of events a second.
+const progressInterval = 100 * time.Millisecond
+
+// outputInterval is how long printed lines are collected before they are sent
+// as one QueryOutputEvent, for the same reason as progressInterval.
+const outputInterval = 100 * time.Millisecond
+
+// outputStream batches the lines a running query prints into QueryOutput
+// events. Lines may come from several goroutines (mongosh's stdout and
+// stderr), so everything happens under mu, emitting included: that keeps the
+// batches in the order the lines were written.
+type outputStream struct {
+	qe       *QueryExecutor
+	serverID string
+	queryID  string
+
+	mu      sync.Mutex
+	pending []string
+	timer   *time.Timer
+	closed  bool
+}
+
+func newOutputStream(qe *QueryExecutor, serverID, queryID string) *outputStream {
+	return &outputStream{qe: qe, serverID: serverID, queryID: queryID}
+}
+
+// Line queues one printed line, to be sent within outputInterval.
+func (s *outputStream) Line(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.pending = append(s.pending, line)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(outputInterval, s.flush)
+	}
+}
+
+func (s *outputStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timer = nil
+	s.emitPending()
+}
+
+// Close sends whatever is still queued. It is called before the query's
+// result is returned, so the frontend has every line by the time it arrives.
+func (s *outputStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.emitPending()
+	s.closed = true
+}
+
+func (s *outputStream) emitPending() {
+	if len(s.pending) == 0 {
+		return
+	}
+	s.qe.emit(s.qe.ctx, QueryOutputEvent, models.QueryOutput{
+		ServerID: s.serverID,
+		QueryID:  s.queryID,
+		Lines:    s.pending,
+	})
+	s.pending = nil
+}
+
+// promptAnswer is the user's reply to a QueryPrompt.
+type promptAnswer struct {
+	value string
+	ok    bool
+}
+
+// scriptUI connects one running built-in engine script to the frontend tab
+// that started it.
+type scriptUI struct {
+	qe       *QueryExecutor
+	serverID string
+	queryID  string
+	output   *outputStream
+
+	mu       sync.Mutex
+	lastSent time.Time
+	// latest is the last report dropped by the throttle, sent by timer once
+	// progressInterval has passed so the bar ends on it.
+	latest *models.QueryProgress
+	timer  *time.Timer
+	closed bool
+}
+
+// Output forwards a printed line.
+func (u *scriptUI) Output(line string) {
+	u.output.Line(line)
+}
+
+// Progress forwards a progress report. Reports that come sooner than
+// progressInterval after the last one sent are held back, and the latest of
+// them is sent when the interval is up. The report that completes the work is
+// always sent at once, so the bar never stops short of full.
+func (u *scriptUI) Progress(done, total float64, message string) {
+	p := models.QueryProgress{
+		ServerID: u.serverID,
+		QueryID:  u.queryID,
+		Done:     done,
+		Total:    total,
+		Message:  message,
+	}
+
+	u.mu.Lock()
+	if u.closed {
+		u.mu.Unlock()
+		return
+	}
+	finished := total > 0 && done >= total
+	if wait := progressInterval - time.Since(u.lastSent); !finished && wait > 0 {
+		u.latest = &p
+		if u.timer == nil {
+			u.timer = time.AfterFunc(wait, u.flushProgress)
+		}
+		u.mu.Unlock()
+		return
+	}
+	u.latest = nil
+	u.lastSent = time.Now()
+	u.mu.Unlock()
+
+	u.qe.emit(u.qe.ctx, QueryProgressEvent, p)
+}
+
+// flushProgress sends the report the throttle held back, if one still is.
+func (u *scriptUI) flushProgress() {
+	u.mu.Lock()
+	u.timer = nil
+	p := u.latest
+	u.latest = nil
+	if p != nil {
+		u.lastSent = time.Now()
+	}
+	u.mu.Unlock()
+
+	if p != nil {
+		u.qe.emit(u.qe.ctx, QueryProgressEvent, *p)
+	}
+}
+
+// Close sends a held-back report at once and ignores any later ones. It is
+// called before the query's result is returned.
+func (u *scriptUI) Close() {
+	u.mu.Lock()
+	if u.timer != nil {
+		u.timer.Stop()
+		u.timer = nil
+	}
+	u.closed = true
+	u.mu.Unlock()
+	u.flushProgress()
+}
+
+// Ask shows the question in the script's tab and waits for AnswerPrompt.
+// Cancelling the query ends the wait.
+func (u *scriptUI) Ask(ctx context.Context, kind, message, defaultValue string) (string, bool, error) {
+	promptID := uuid.NewString()
+	answers := make(chan promptAnswer, 1)
+
+	u.qe.mu.Lock()
+	u.qe.prompts[promptID] = answers
+	u.qe.mu.Unlock()
+	defer func() {
+		u.qe.mu.Lock()
+		delete(u.qe.prompts, promptID)
+		u.qe.mu.Unlock()
+	}()
+
+	u.qe.emit(u.qe.ctx, QueryPromptEvent, models.QueryPrompt{
+		ServerID: u.serverID,
+		QueryID:  u.queryID,
+		PromptID: promptID,
+		Kind:     kind,
+		Message:  message,
+		Default:  defaultValue,
+	})
+
+	select {
+	case answer := <-answers:
+		return answer.value, answer.ok, nil
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+}
+
+// AnswerPrompt delivers the user's reply to the script waiting on promptID.
+// ok is false when the user dismissed the question.
+func (qe *QueryExecutor) AnswerPrompt(promptID, value string, ok bool) error {
+	qe.mu.Lock()
+	answers, found := qe.prompts[promptID]
+	qe.mu.Unlock()
+	if !found {
+		return fmt.Errorf("no script is waiting on prompt %s", promptID)
+	}
+
+	if qe.log != nil {
+		qe.log.Debug("Answering script prompt", slog.String("promptID", promptID))
+	}
+	select {
+	case answers <- promptAnswer{value: value, ok: ok}:
+	default:
+		// Already answered; the first reply stands.
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package queryexecutor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"vervet/internal/models"
+)
+
+// recordEvents replaces qe's emitter with one that collects what is sent.
+func recordEvents(qe *QueryExecutor) func() []any {
+	var mu sync.Mutex
+	var events []any
+	qe.emit = func(_ context.Context, name string, data ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, data...)
+	}
+	return func() []any {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]any(nil), events...)
+	}
+}
+
+func TestScriptUI_ProgressIsThrottled(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	for i := 0; i < 50; i++ {
+		ui.Progress(float64(i), 100, "copying")
+	}
+	if got := len(events()); got != 1 {
+		t.Fatalf("a burst of progress calls should send one event, sent %d", got)
+	}
+
+	p := events()[0].(models.QueryProgress)
+	if p.ServerID != "srv" || p.QueryID != "q1" || p.Message != "copying" || p.Total != 100 {
+		t.Fatalf("unexpected progress event %+v", p)
+	}
+}
+
+func TestScriptUI_LatestDroppedProgressIsSentLater(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	for i := 0; i < 50; i++ {
+		ui.Progress(float64(i), 100, "copying")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(events()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := events()
+	if len(got) != 2 {
+		t.Fatalf("expected the latest held-back report to follow, got %d events", len(got))
+	}
+	if got[1].(models.QueryProgress).Done != 49 {
+		t.Fatalf("expected the trailing report to be the latest, got %+v", got[1])
+	}
+}
+
+func TestScriptUI_CloseSendsHeldBackProgress(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	ui.Progress(1, 10, "")
+	ui.Progress(2, 10, "")
+	ui.Close()
+	ui.Progress(3, 10, "")
+
+	got := events()
+	if len(got) != 2 || got[1].(models.QueryProgress).Done != 2 {
+		t.Fatalf("expected Close to send the held-back report and nothing after, got %+v", got)
+	}
+}
+
+func TestScriptUI_FinalProgressIsAlwaysSent(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	ui.Progress(99, 100, "")
+	ui.Progress(100, 100, "done")
+
+	got := events()
+	if len(got) != 2 {
+		t.Fatalf("expected the completing report to bypass the throttle, got %d events", len(got))
+	}
+	if got[1].(models.QueryProgress).Done != 100 {
+		t.Fatalf("unexpected final event %+v", got[1])
+	}
+}
+
+func TestScriptUI_AskWaitsForAnswer(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	type reply struct {
+		value string
+		ok    bool
+		err   error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		value, ok, err := ui.Ask(context.Background(), models.PromptKindText, "Batch size?", "100")
+		done <- reply{value, ok, err}
+	}()
+
+	var prompt models.QueryPrompt
+	deadline := time.Now().Add(time.Second)
+	for len(events()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no prompt event was sent")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	prompt = events()[0].(models.QueryPrompt)
+	if prompt.Message != "Batch size?" || prompt.Default != "100" || prompt.Kind != models.PromptKindText {
+		t.Fatalf("unexpected prompt event %+v", prompt)
+	}
+
+	if err := qe.AnswerPrompt(prompt.PromptID, "250", true); err != nil {
+		t.Fatal(err)
+	}
+	got := <-done
+	if got.err != nil || got.value != "250" || !got.ok {
+		t.Fatalf("unexpected answer %+v", got)
+	}
+
+	if err := qe.AnswerPrompt(prompt.PromptID, "again", true); err == nil {
+		t.Fatal("a prompt that was answered should no longer accept answers")
+	}
+}
+
+func TestScriptUI_AskEndsWhenQueryIsCancelled(t *testing.T) {
+	qe := newTestExecutor()
+	recordEvents(qe)
+	ui := &scriptUI{qe: qe, serverID: "srv", queryID: "q1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	qe.registerQuery("srv", "q1", cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := ui.Ask(ctx, models.PromptKindConfirm, "Drop it?", "")
+		done <- err
+	}()
+	qe.CancelQuery("srv", "q1")
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelling the query did not end the prompt")
+	}
+	if len(qe.prompts) != 0 {
+		t.Fatal("a cancelled prompt should be forgotten")
+	}
+}
+
+func TestAnswerPrompt_UnknownPromptErrors(t *testing.T) {
+	qe := newTestExecutor()
+	if err := qe.AnswerPrompt("missing", "", false); err == nil {
+		t.Fatal("expected an error for a prompt nobody is waiting on")
+	}
+}
+
+func TestOutputStream_BatchesLines(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	out := newOutputStream(qe, "srv", "q1")
+
+	for _, line := range []string{"a", "b", "c"} {
+		out.Line(line)
+	}
+	if got := len(events()); got != 0 {
+		t.Fatalf("lines should wait for the batch interval, sent %d events", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(events()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := events()
+	if len(got) != 1 {
+		t.Fatalf("expected one batched event, got %d", len(got))
+	}
+	o := got[0].(models.QueryOutput)
+	if o.ServerID != "srv" || o.QueryID != "q1" || len(o.Lines) != 3 || o.Lines[2] != "c" {
+		t.Fatalf("unexpected output event %+v", o)
+	}
+}
+
+func TestOutputStream_CloseSendsTheRest(t *testing.T) {
+	qe := newTestExecutor()
+	events := recordEvents(qe)
+	out := newOutputStream(qe, "srv", "q1")
+
+	out.Line("last words")
+	out.Close()
+	out.Line("after close")
+	out.Close()
+
+	got := events()
+	if len(got) != 1 {
+		t.Fatalf("expected Close to send the queued line once, got %d events", len(got))
+	}
+	if lines := got[0].(models.QueryOutput).Lines; len(lines) != 1 || lines[0] != "last words" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"vervet/internal/infrastructure"
 	"vervet/internal/logging"
+	"vervet/internal/masking"
 	"vervet/internal/models"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -85,6 +86,10 @@ func (s *settingsService) GetSettings() (models.Settings, error) {
 }
 
 func (s *settingsService) SetSettings(settings *models.Settings) error {
+	if err := masking.ValidateProfiles(settings.Masking.Profiles); err != nil {
+		return err
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -225,6 +230,7 @@ func (s *settingsService) getSettings() (models.Settings, error) {
 
 	settings.Logging.Normalize()
 	settings.Query.Normalize()
+	settings.Audit.Normalize()
 
 	if migrated {
 		if saveErr := s.saveSettings(&settings); saveErr != nil {
@@ -348,6 +354,7 @@ func defaultSettingsForBuild(isDev bool) models.Settings {
 			DefaultLimit:    DefaultResultLimit,
 			DefaultPageSize: DefaultResultPageSize,
 			QueryEngine:     "builtin",
+			ScriptSandbox:   models.ScriptSandboxUnrestricted,
 		},
 		Terminal: models.TerminalSettings{
 			Font: models.FontSettings{
@@ -368,5 +375,8 @@ func defaultSettingsForBuild(isDev bool) models.Settings {
 			MaxSizeMB:      10,
 			MaxBackups:     5,
 		},
+		Audit: models.AuditSettings{
+			Redaction: models.AuditRedactValues,
+		},
 	}
 }
@@ -115,6 +115,19 @@ func Test_SettingsService_SetSettings(t *testing.T) {
 			t.Error("expected an error, got nil")
 		}
 	})
+
+	t.Run("rejects an invalid masking profile", func(t *testing.T) {
+		store := &storeStub{}
+		m := newTestService(store, nil)
+		cfg := expectedSettings()
+		cfg.Masking.Profiles = []models.MaskingProfile{{
+			Name:  "customers",
+			Rules: []models.MaskingRule{{Path: "email", Strategy: "scramble"}},
+		}}
+		err := m.SetSettings(&cfg)
+		assert.ErrorContains(t, err, `unknown masking strategy "scramble"`)
+		assert.Nil(t, store.content)
+	})
 }
 
 func Test_SettingsService_DefaultQueryEngine(t *testing.T) {
@@ -175,6 +188,56 @@ func Test_SettingsService_QueryDefaults(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "builtin", c.Query.QueryEngine)
 	})
+
+	t.Run("first run leaves scripts unrestricted", func(t *testing.T) {
+		m := newTestService(nil, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScriptSandboxUnrestricted, c.Query.ScriptSandbox)
+	})
+
+	t.Run("keeps a recognised script sandbox", func(t *testing.T) {
+		m := newTestService(&storeStub{
+			content: []byte("query:\n  defaultLimit: 42\n  defaultPageSize: 25\n  queryEngine: builtin\n  scriptSandbox: readonly"),
+		}, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScriptSandboxReadOnly, c.Query.ScriptSandbox)
+	})
+
+	t.Run("clamps unknown script sandbox to unrestricted", func(t *testing.T) {
+		m := newTestService(&storeStub{
+			content: []byte("query:\n  defaultLimit: 42\n  defaultPageSize: 25\n  queryEngine: builtin\n  scriptSandbox: bogus"),
+		}, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScriptSandboxUnrestricted, c.Query.ScriptSandbox)
+	})
+
+	t.Run("first run redacts audit values", func(t *testing.T) {
+		m := newTestService(nil, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuditRedactValues, c.Audit.Redaction)
+	})
+
+	t.Run("keeps a recognised audit redaction", func(t *testing.T) {
+		m := newTestService(&storeStub{
+			content: []byte("audit:\n  redaction: none"),
+		}, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuditRedactNone, c.Audit.Redaction)
+	})
+
+	t.Run("clamps unknown audit redaction to values", func(t *testing.T) {
+		m := newTestService(&storeStub{
+			content: []byte("audit:\n  redaction: bogus"),
+		}, nil)
+		c, err := m.GetSettings()
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuditRedactValues, c.Audit.Redaction)
+	})
 }
 
 func Test_SettingsService_LegacyQueryEngineMigration(t *testing.T) {
@@ -303,6 +366,7 @@ func expectedSettings() models.Settings {
 			DefaultLimit:    settings.DefaultResultLimit,
 			DefaultPageSize: settings.DefaultResultPageSize,
 			QueryEngine:     "builtin",
+			ScriptSandbox:   models.ScriptSandboxUnrestricted,
 		},
 		Terminal: models.TerminalSettings{
 			Font: models.FontSettings{
@@ -320,6 +384,9 @@ func expectedSettings() models.Settings {
 			MaxSizeMB:      10,
 			MaxBackups:     5,
 		},
+		Audit: models.AuditSettings{
+			Redaction: models.AuditRedactValues,
+		},
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -93,3 +94,59 @@ func TestExecute_NoScriptDirStillRuns(t *testing.T) {
 	// Numbers come back as canonical Extended JSON.
 	assert.Equal(t, []any{map[string]any{"$numberInt": "2"}}, result.Documents)
 }
+
+// Printed lines reach OnOutput while mongosh runs; the JSON result line the
+// wrapper prints last does not.
+func TestExecute_StreamsOutput(t *testing.T) {
+	if !CheckMongosh() {
+		t.Skip("mongosh not in PATH")
+	}
+
+	var mu sync.Mutex
+	var lines []string
+	cfg := Config{Timeout: 60 * time.Second, OnOutput: func(line string) {
+		mu.Lock()
+		lines = append(lines, line)
+		mu.Unlock()
+	}}
+
+	result, err := Execute(context.Background(), testURI, `print("first"); console.error("warned"); print("second"); 42`, cfg)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.RawOutput)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"first", "warned", "second"}, lines)
+}
+
+// A query ending in a find comes back one page at a time with the find's
+// parameters, and its sort survives as an ordered document.
+func TestExecute_PagesFindCursors(t *testing.T) {
+	if !CheckMongosh() {
+		t.Skip("mongosh not in PATH")
+	}
+
+	cfg := Config{Timeout: 60 * time.Second, PageSize: 5}
+	seed := `const items = db.getSiblingDB('paging_test').items;
+		items.drop();
+		items.insertMany(Array.from({length: 12}, (_, i) => ({n: i, g: i % 3})))`
+	_, err := Execute(context.Background(), testURI, seed, cfg)
+	require.NoError(t, err)
+
+	query := `db.getSiblingDB('paging_test').items.find({n: {$gte: 2}}, {_id: 0}).sort({g: 1, n: -1}).skip(1).limit(8)`
+	result, err := Execute(context.Background(), testURI, query, cfg)
+	require.NoError(t, err)
+	assert.Len(t, result.Documents, 5)
+	require.NotNil(t, result.PageContext)
+
+	pc := result.PageContext
+	assert.True(t, pc.ExtendedJSON)
+	assert.Equal(t, "paging_test", pc.Database)
+	assert.Equal(t, "items", pc.Collection)
+	assert.Equal(t, int64(8), pc.UserLimit)
+	assert.Equal(t, int64(1), pc.UserSkip)
+	assert.Equal(t, []any{
+		[]any{"g", map[string]any{"$numberInt": "1"}},
+		[]any{"n", map[string]any{"$numberInt": "-1"}},
+	}, pc.Sort)
+}
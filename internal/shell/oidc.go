@@ -22,29 +22,27 @@ func ExecuteWithOIDC(ctx context.Context, uri string, query string, cfg Config)
 	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
 	defer cancel()
 
-	queryFile, cleanup, err := writeQueryFile(wrapQuery(query), cfg.ScriptDir)
+	queryFile, cleanup, err := writeQueryFile(wrapQuery(query, cfg.PageSize), cfg.ScriptDir)
 	if err != nil {
 		return models.QueryResult{}, err
 	}
 	defer cleanup()
 
-	args := []string{
-		uri,
-		"--quiet",
-		"--norc",
-		"--authenticationMechanism", "MONGODB-OIDC",
-		"--oidcFlows", "auth-code",
-		"--file", queryFile,
-	}
+	args := []string{uri, "--quiet", "--norc"}
+	args = append(args, oidcArgs...)
+	args = append(args, "--file", queryFile)
 
 	cmd := exec.CommandContext(ctx, "mongosh", args...)
 	cmd.Dir = cfg.ScriptDir
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var stdoutLines, stderrLines *lineWriter
+	cmd.Stdout, stdoutLines = newOutputWriter(&stdout, cfg.OnOutput, true)
+	cmd.Stderr, stderrLines = newOutputWriter(&stderr, cfg.OnOutput, false)
 
 	err = cmd.Run()
+	stdoutLines.flush()
+	stderrLines.flush()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return models.QueryResult{}, ErrQueryTimeout
@@ -0,0 +1,74 @@
+package shell
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"vervet/internal/models"
+)
+
+// pageMarker starts the line wrapQuery prints for a paged find cursor.
+const pageMarker = "__vervetPage:"
+
+// pagedOutput is what follows pageMarker: the find's parameters, as canonical
+// Extended JSON, and its first page. The counts are strings so canonical
+// EJSON leaves them as plain values.
+type pagedOutput struct {
+	Database   string         `json:"database"`
+	Collection string         `json:"collection"`
+	Filter     any            `json:"filter"`
+	Projection any            `json:"projection"`
+	Sort       any            `json:"sort"`
+	Hint       any            `json:"hint"`
+	Collation  map[string]any `json:"collation"`
+	UserLimit  string         `json:"userLimit"`
+	UserSkip   string         `json:"userSkip"`
+	MaxTimeMS  string         `json:"maxTimeMS"`
+	Comment    string         `json:"comment"`
+	Documents  []any          `json:"documents"`
+}
+
+// parsePagedOutput recognises output ending in a pageMarker line and returns
+// its first page with a PageContext for the rest. Anything printed before the
+// marker was already streamed as the script ran, and is left out, as the
+// built-in engine does when a script ends in a find.
+func parsePagedOutput(output string) (models.QueryResult, bool) {
+	last := output[strings.LastIndexByte(output, '\n')+1:]
+	data, ok := strings.CutPrefix(strings.TrimSpace(last), pageMarker)
+	if !ok {
+		return models.QueryResult{}, false
+	}
+
+	var page pagedOutput
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		return models.QueryResult{}, false
+	}
+	docs := page.Documents
+	if docs == nil {
+		docs = []any{}
+	}
+
+	return models.QueryResult{
+		Documents:     docs,
+		OperationType: "find",
+		PageContext: &models.PageContext{
+			Database:     page.Database,
+			Collection:   page.Collection,
+			Filter:       page.Filter,
+			Projection:   page.Projection,
+			Sort:         page.Sort,
+			Hint:         page.Hint,
+			Collation:    page.Collation,
+			UserLimit:    parseCount(page.UserLimit),
+			UserSkip:     parseCount(page.UserSkip),
+			MaxTimeMS:    parseCount(page.MaxTimeMS),
+			Comment:      page.Comment,
+			ExtendedJSON: true,
+		},
+	}, true
+}
+
+func parseCount(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
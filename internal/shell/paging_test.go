@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapQuery_PagesFindCursorsOnlyWithAPageSize(t *testing.T) {
+	if got := wrapQuery(`db.users.find({})`, 25); !strings.Contains(got, "const __pageSize = 25;") ||
+		!strings.Contains(got, "print('"+pageMarker+"'") {
+		t.Errorf("expected the paging epilogue; got:\n%s", got)
+	}
+	if got := wrapQuery(`db.users.find({})`, 0); !strings.Contains(got, "const __pageSize = 0;") {
+		t.Errorf("expected paging to be off; got:\n%s", got)
+	}
+}
+
+func TestParseOutput_PagedFind(t *testing.T) {
+	output := "starting\n" + pageMarker + `{"database":"shop","collection":"orders",` +
+		`"filter":{"n":{"$gte":{"$numberInt":"5"}}},"sort":[["n",{"$numberInt":"1"}]],` +
+		`"userLimit":"40","userSkip":"10","maxTimeMS":"0","comment":"report",` +
+		`"documents":[{"n":{"$numberInt":"15"}}]}`
+
+	result := parseOutput(output)
+	if len(result.Documents) != 1 || result.OperationType != "find" || result.RawOutput != "" {
+		t.Fatalf("expected one document and no raw output, got %+v", result)
+	}
+	pc := result.PageContext
+	if pc == nil {
+		t.Fatal("expected a PageContext")
+	}
+	if pc.Database != "shop" || pc.Collection != "orders" || !pc.ExtendedJSON {
+		t.Errorf("unexpected namespace or encoding: %+v", pc)
+	}
+	if pc.UserLimit != 40 || pc.UserSkip != 10 || pc.MaxTimeMS != 0 || pc.Comment != "report" {
+		t.Errorf("unexpected cursor options: %+v", pc)
+	}
+	if _, ok := pc.Filter.(map[string]any); !ok {
+		t.Errorf("expected the filter to be kept as a document, got %T", pc.Filter)
+	}
+}
+
+func TestParseOutput_EmptyPagedFind(t *testing.T) {
+	result := parseOutput(pageMarker + `{"collection":"orders","userLimit":"0","userSkip":"0","maxTimeMS":"0","documents":[]}`)
+	if result.PageContext == nil || result.Documents == nil || len(result.Documents) != 0 {
+		t.Errorf("expected an empty first page with a PageContext, got %+v", result)
+	}
+}
+
+// The marker only counts as the last line; printed text that happens to
+// contain it is just output.
+func TestParseOutput_MarkerMustBeTheLastLine(t *testing.T) {
+	result := parseOutput(pageMarker + "{}\nplain text")
+	if result.PageContext != nil || result.RawOutput == "" {
+		t.Errorf("expected raw output, got %+v", result)
+	}
+}
@@ -25,6 +25,12 @@ type Config struct {
 	// with it as its working directory, so the script's __dirname, load() and
 	// relative paths all point at the user's own directory rather than /tmp.
 	ScriptDir string
+	// OnOutput, when set, is given each line mongosh writes while the query
+	// runs. The result still carries the complete output.
+	OnOutput func(line string)
+	// PageSize, when positive, makes a query ending in a find cursor return
+	// only its first page, with a PageContext for fetching the rest.
+	PageSize int64
 }
 
 // writeQueryFile writes the wrapped query to a temp file in dir (the system
@@ -55,7 +61,9 @@ func CheckMongosh() bool {
 
 // wrapQuery appends JavaScript that converts the query's value to JSON. It
 // handles cursors (via toArray()), plain objects, and falls back to string
-// output for non-serializable results.
+// output for non-serializable results. With a positive pageSize, a find
+// cursor is instead cut to its first page and printed with the parameters of
+// its find as one pageMarker line (see parsePagedOutput).
 //
 // The value is captured by assigning the last top-level statement to a global.
 // The query itself stays at top level and is NOT wrapped in a function: mongosh
@@ -67,13 +75,46 @@ func CheckMongosh() bool {
 // tracking bracket depth, strings, and comments — so multi-line expressions are
 // handled. When that statement is a declaration or other non-expression, no
 // value is captured and only what the script printed is returned.
-func wrapQuery(query string) string {
+func wrapQuery(query string, pageSize int64) string {
 	body := prependToLastStatement(strings.TrimSpace(query), resultGlobal+" = ")
 
 	return fmt.Sprintf(`%s
 ;(function () {
   const __result = globalThis.%s;
   if (__result === undefined) { return; }
+  const __pageSize = %d;
+  // A mongosh find cursor wraps the driver's FindCursor, which keeps the
+  // find's filter and options in cursorFilter and findOptions.
+  const __find = (__pageSize > 0 && __result !== null && typeof __result === 'object' &&
+    __result._cursor && 'cursorFilter' in __result._cursor) ? __result._cursor : null;
+  if (__find !== null) {
+    try {
+      const __opts = __find.findOptions || {};
+      const __limit = Math.abs(Number(__opts.limit || 0));
+      __result.limit(__limit > 0 && __limit < __pageSize ? __limit : __pageSize);
+      print('%s' + EJSON.stringify({
+        database: __find.namespace.db,
+        collection: __find.namespace.collection,
+        filter: __find.cursorFilter,
+        projection: __opts.projection,
+        // [key, direction] pairs, so the sort keeps its order through JSON.
+        sort: __opts.sort instanceof Map ? Array.from(__opts.sort.entries())
+          : (__opts.sort && typeof __opts.sort === 'object' && !Array.isArray(__opts.sort)) ? Object.entries(__opts.sort)
+          : __opts.sort,
+        hint: __opts.hint,
+        collation: __opts.collation,
+        userLimit: String(__limit),
+        userSkip: String(Number(__opts.skip || 0)),
+        maxTimeMS: String(Number(__opts.maxTimeMS || 0)),
+        comment: typeof __opts.comment === 'string' ? __opts.comment : undefined,
+        documents: __result.toArray(),
+      }, {relaxed: false}));
+      return;
+    } catch (_e) {
+      // A cursor the script has already read from can't be limited; it is
+      // returned whole below.
+    }
+  }
   const __val = (__result !== null && typeof __result.toArray === 'function') ? __result.toArray() : __result;
   try {
     const __json = EJSON.stringify(__val, {relaxed: false});
@@ -87,7 +128,7 @@ func wrapQuery(query string) string {
     }
   }
 })();
-`, body, resultGlobal)
+`, body, resultGlobal, pageSize, pageMarker)
 }
 
 // resultGlobal holds the query's value between the user's script and the
@@ -107,7 +148,7 @@ func Execute(ctx context.Context, uri string, query string, cfg Config) (models.
 	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
 	defer cancel()
 
-	queryFile, cleanup, err := writeQueryFile(wrapQuery(query), cfg.ScriptDir)
+	queryFile, cleanup, err := writeQueryFile(wrapQuery(query, cfg.PageSize), cfg.ScriptDir)
 	if err != nil {
 		return models.QueryResult{}, err
 	}
@@ -118,10 +159,13 @@ func Execute(ctx context.Context, uri string, query string, cfg Config) (models.
 	cmd.Dir = cfg.ScriptDir
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var stdoutLines, stderrLines *lineWriter
+	cmd.Stdout, stdoutLines = newOutputWriter(&stdout, cfg.OnOutput, true)
+	cmd.Stderr, stderrLines = newOutputWriter(&stderr, cfg.OnOutput, false)
 
 	err = cmd.Run()
+	stdoutLines.flush()
+	stderrLines.flush()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return models.QueryResult{}, ErrQueryTimeout
@@ -166,6 +210,10 @@ func parseOutput(output string) models.QueryResult {
 		return models.QueryResult{}
 	}
 
+	if result, ok := parsePagedOutput(output); ok {
+		return result
+	}
+
 	// Try parsing as a single JSON array
 	var arr []any
 	if err := json.Unmarshal([]byte(output), &arr); err == nil {
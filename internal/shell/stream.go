@@ -0,0 +1,76 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriter passes each complete line written to it on to onLine, alongside
+// the buffer that collects the process's full output.
+//
+// With holdLast set, the most recent line is only passed on once another
+// follows it. That is for stdout, whose last line may be the JSON the wrapper
+// prints as the query's result: it arrives with the result, not as output.
+type lineWriter struct {
+	mu       sync.Mutex
+	buf      *bytes.Buffer
+	onLine   func(line string)
+	holdLast bool
+
+	partial []byte
+	held    *string
+}
+
+// newOutputWriter returns where a mongosh stream should be written: buf
+// alone, or buf plus onLine when the caller wants the output as it happens.
+func newOutputWriter(buf *bytes.Buffer, onLine func(string), holdLast bool) (io.Writer, *lineWriter) {
+	if onLine == nil {
+		return buf, nil
+	}
+	w := &lineWriter{buf: buf, onLine: onLine, holdLast: holdLast}
+	return w, w
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimSuffix(w.partial[:i], []byte("\r")))
+		w.partial = w.partial[i+1:]
+		w.line(line)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) line(line string) {
+	if !w.holdLast {
+		w.onLine(line)
+		return
+	}
+	if w.held != nil {
+		w.onLine(*w.held)
+	}
+	w.held = &line
+}
+
+// flush passes on an unterminated last line once the process has exited.
+// A held line stays held: it is part of the result.
+func (w *lineWriter) flush() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 && !w.holdLast {
+		w.onLine(string(w.partial))
+	}
+	w.partial = nil
+}
@@ -0,0 +1,52 @@
+package shell
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLineWriter_SplitsWritesIntoLines(t *testing.T) {
+	var buf bytes.Buffer
+	var got []string
+	w, lines := newOutputWriter(&buf, func(line string) { got = append(got, line) }, false)
+
+	_, _ = w.Write([]byte("one\r\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	lines.flush()
+
+	if want := []string{"one", "two", "three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if buf.String() != "one\r\ntwo\nthree" {
+		t.Errorf("buffer lost output: %q", buf.String())
+	}
+}
+
+// The last stdout line may be the query's JSON result, so it must not be
+// streamed as output.
+func TestLineWriter_HoldLastKeepsFinalLineBack(t *testing.T) {
+	var buf bytes.Buffer
+	var got []string
+	w, lines := newOutputWriter(&buf, func(line string) { got = append(got, line) }, true)
+
+	_, _ = w.Write([]byte("progress 1\nprogress 2\n"))
+	if want := []string{"progress 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	_, _ = w.Write([]byte(`{"ok":1}` + "\n"))
+	lines.flush()
+
+	if want := []string{"progress 1", "progress 2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewOutputWriter_WithoutCallbackWritesBufferOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w, lines := newOutputWriter(&buf, nil, true)
+	if w != &buf {
+		t.Errorf("expected the buffer itself, got %T", w)
+	}
+	lines.flush() // must be safe on nil
+}
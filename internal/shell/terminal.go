@@ -0,0 +1,116 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// ErrTerminalUnsupported is returned by StartTerminal on platforms without
+// pseudo-terminals.
+var ErrTerminalUnsupported = fmt.Errorf("interactive terminals are not supported on this platform")
+
+// oidcArgs make mongosh run its own OIDC auth-code login. See ExecuteWithOIDC
+// for why that completes without the user having to sign in again.
+var oidcArgs = []string{
+	"--authenticationMechanism", "MONGODB-OIDC",
+	"--oidcFlows", "auth-code",
+}
+
+// Terminal is an interactive mongosh running in a pseudo-terminal. Reading
+// gives what mongosh draws, prompt and escape sequences included; writing
+// is typing at it.
+type Terminal struct {
+	cmd *exec.Cmd
+	pty *os.File
+
+	closeOnce sync.Once
+	done      chan struct{}
+	exitErr   error
+}
+
+// StartTerminal starts mongosh against uri in a pseudo-terminal of the given
+// size. Unlike Execute, the user's .mongoshrc is loaded and mongosh keeps
+// its own history: it is the same shell they would get in a terminal window.
+func StartTerminal(uri string, useOIDC bool, cols, rows uint16) (*Terminal, error) {
+	if _, err := exec.LookPath("mongosh"); err != nil {
+		return nil, ErrShellNotFound
+	}
+
+	args := []string{uri}
+	if useOIDC {
+		args = append(args, oidcArgs...)
+	}
+	return startTerminal(exec.Command("mongosh", args...), cols, rows)
+}
+
+func startTerminal(cmd *exec.Cmd, cols, rows uint16) (*Terminal, error) {
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: cols, Rows: rows})
+	if err != nil {
+		if errors.Is(err, pty.ErrUnsupported) {
+			return nil, ErrTerminalUnsupported
+		}
+		return nil, fmt.Errorf("failed to start terminal: %w", err)
+	}
+
+	t := &Terminal{cmd: cmd, pty: f, done: make(chan struct{})}
+	go func() {
+		t.exitErr = cmd.Wait()
+		close(t.done)
+	}()
+	return t, nil
+}
+
+// Read reads what the shell has written. It returns io.EOF once the shell
+// has exited and everything it wrote has been read.
+func (t *Terminal) Read(p []byte) (int, error) {
+	n, err := t.pty.Read(p)
+	// Linux reports EIO rather than EOF once the other end of the pty has
+	// gone away.
+	if errors.Is(err, syscall.EIO) {
+		err = os.ErrClosed
+	}
+	if errors.Is(err, os.ErrClosed) {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// Write sends input to the shell as if typed.
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.pty.Write(p)
+}
+
+// Resize tells the shell the terminal is now cols by rows.
+func (t *Terminal) Resize(cols, rows uint16) error {
+	return pty.Setsize(t.pty, &pty.Winsize{Cols: cols, Rows: rows})
+}
+
+// Wait blocks until the shell exits and returns its exit error, nil for a
+// clean exit.
+func (t *Terminal) Wait() error {
+	<-t.done
+	return t.exitErr
+}
+
+// Close ends the shell, if it is still running, and releases the pty.
+func (t *Terminal) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		select {
+		case <-t.done:
+		default:
+			_ = t.cmd.Process.Kill()
+		}
+		err = t.pty.Close()
+	})
+	return err
+}
@@ -0,0 +1,121 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startTestTerminal(t *testing.T, script string) *Terminal {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("no pseudo-terminals on windows")
+	}
+	term, err := startTerminal(exec.Command("sh", "-c", script), 80, 24)
+	if err != nil {
+		t.Fatalf("startTerminal: %v", err)
+	}
+	t.Cleanup(func() { _ = term.Close() })
+	return term
+}
+
+// readAll collects the terminal's output until it ends, failing the test if
+// that takes too long.
+func readAll(t *testing.T, term *Terminal) string {
+	t.Helper()
+	out := make(chan string, 1)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, term)
+		out <- buf.String()
+	}()
+	select {
+	case s := <-out:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("terminal output never ended")
+		return ""
+	}
+}
+
+// readUntil reads from the terminal until its output contains want.
+func readUntil(t *testing.T, term *Terminal, want string) {
+	t.Helper()
+	found := make(chan bool, 1)
+	go func() {
+		var seen []byte
+		buf := make([]byte, 256)
+		for {
+			n, err := term.Read(buf)
+			seen = append(seen, buf[:n]...)
+			if strings.Contains(string(seen), want) {
+				found <- true
+				return
+			}
+			if err != nil {
+				found <- false
+				return
+			}
+		}
+	}()
+	select {
+	case ok := <-found:
+		if !ok {
+			t.Fatalf("terminal ended without writing %q", want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("terminal never wrote %q", want)
+	}
+}
+
+func TestTerminal_InputAndOutput(t *testing.T) {
+	term := startTestTerminal(t, `read line; echo "got:$line"`)
+
+	if _, err := term.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if out := readAll(t, term); !strings.Contains(out, "got:hello") {
+		t.Errorf("expected the shell to echo its input, got %q", out)
+	}
+	if err := term.Wait(); err != nil {
+		t.Errorf("expected a clean exit, got %v", err)
+	}
+}
+
+func TestTerminal_SizeAndResize(t *testing.T) {
+	term := startTestTerminal(t, `stty size; read _; stty size`)
+	readUntil(t, term, "24 80")
+
+	if err := term.Resize(100, 40); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if _, err := term.Write([]byte("\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if out := readAll(t, term); !strings.Contains(out, "40 100") {
+		t.Errorf("expected the new size to be reported, got %q", out)
+	}
+}
+
+func TestTerminal_CloseEndsTheShell(t *testing.T) {
+	term := startTestTerminal(t, `sleep 30`)
+
+	if err := term.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	readAll(t, term) // reading must end too, or the session's relay would hang
+	done := make(chan struct{})
+	go func() {
+		_ = term.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not end the shell")
+	}
+}
@@ -0,0 +1,237 @@
+// Package terminals runs interactive mongosh sessions for connected servers
+// and relays them to the frontend over Wails events.
+package terminals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"unicode/utf8"
+	"vervet/internal/connectionStrings"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+	"vervet/internal/shell"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// OutputEvent carries a models.TerminalOutput.
+	OutputEvent = "terminal-output"
+	// ExitEvent carries a models.TerminalExit.
+	ExitEvent = "terminal-exit"
+)
+
+// readBufferSize is how much terminal output is read, and sent, at a time.
+const readBufferSize = 32 * 1024
+
+// ConnectionChecker reports whether the app is connected to a server.
+// Implemented by clientregistry.ClientRegistry.
+type ConnectionChecker interface {
+	IsConnected(serverID string) bool
+}
+
+// terminal is the part of shell.Terminal the manager uses, so tests can run
+// sessions without mongosh.
+type terminal interface {
+	io.ReadWriter
+	Resize(cols, rows uint16) error
+	Wait() error
+	Close() error
+}
+
+type session struct {
+	serverID string
+	term     terminal
+}
+
+// Manager owns the open terminal sessions. Each session is a mongosh process
+// of its own; it keeps running, with its own connection, until the user quits
+// it or the frontend closes it.
+type Manager struct {
+	mu         sync.Mutex
+	ctx        context.Context
+	log        *slog.Logger
+	store      connectionStrings.Store
+	registry   ConnectionChecker
+	sessions   map[string]*session
+	wg         sync.WaitGroup
+	shutdown   bool
+	startShell func(uri string, useOIDC bool, cols, rows uint16) (terminal, error)
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
+}
+
+func NewManager(log *slog.Logger, registry ConnectionChecker, store connectionStrings.Store) *Manager {
+	return &Manager{
+		log:      log.With(slog.String(logging.SourceKey, "TerminalManager")),
+		registry: registry,
+		store:    store,
+		sessions: make(map[string]*session),
+		startShell: func(uri string, useOIDC bool, cols, rows uint16) (terminal, error) {
+			return shell.StartTerminal(uri, useOIDC, cols, rows)
+		},
+		emit: runtime.EventsEmit,
+	}
+}
+
+// Init stores the Wails application context events are emitted on.
+func (m *Manager) Init(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// Open starts mongosh for a connected server in a terminal cols wide and rows
+// high, and returns the ID the session's events and calls use.
+func (m *Manager) Open(serverID string, cols, rows uint16) (string, error) {
+	if !m.registry.IsConnected(serverID) {
+		return "", fmt.Errorf("not connected to server %s", serverID)
+	}
+	if cols == 0 || rows == 0 {
+		return "", fmt.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+
+	cfg, err := m.store.GetConnectionConfig(serverID)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving connection config: %w", err)
+	}
+
+	term, err := m.startShell(cfg.URI, cfg.AuthMethod == models.AuthOIDC, cols, rows)
+	if err != nil {
+		return "", err
+	}
+
+	sessionID := uuid.NewString()
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		_ = term.Close()
+		return "", fmt.Errorf("terminals are shutting down")
+	}
+	m.sessions[sessionID] = &session{serverID: serverID, term: term}
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	m.log.Debug("Opened terminal", slog.String("serverID", serverID), slog.String("sessionID", sessionID))
+	go m.relay(sessionID, serverID, term)
+	return sessionID, nil
+}
+
+// relay sends the session's output to the frontend until the shell exits,
+// then reports the exit.
+func (m *Manager) relay(sessionID, serverID string, term terminal) {
+	defer m.wg.Done()
+
+	buf := make([]byte, readBufferSize)
+	var carry []byte
+	for {
+		n, err := term.Read(buf)
+		if n > 0 {
+			var data []byte
+			data, carry = splitUTF8(append(carry, buf[:n]...))
+			if len(data) > 0 {
+				m.emit(m.ctx, OutputEvent, models.TerminalOutput{SessionID: sessionID, Data: string(data)})
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	exitErr := term.Wait()
+	_ = term.Close()
+
+	m.mu.Lock()
+	delete(m.sessions, sessionID)
+	m.mu.Unlock()
+
+	exit := models.TerminalExit{SessionID: sessionID, ServerID: serverID}
+	var exitCode *exec.ExitError
+	switch {
+	case errors.As(exitErr, &exitCode):
+		exit.ExitCode = exitCode.ExitCode()
+	case exitErr != nil:
+		exit.ExitCode = -1
+		exit.Error = exitErr.Error()
+	}
+	m.log.Debug("Terminal exited", slog.String("sessionID", sessionID), slog.Int("exitCode", exit.ExitCode))
+	m.emit(m.ctx, ExitEvent, exit)
+}
+
+// splitUTF8 splits b before a UTF-8 sequence it ends part way through, so a
+// character a read cut in two is sent whole with the next chunk.
+func splitUTF8(b []byte) (complete, rest []byte) {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], append([]byte(nil), b[i:]...)
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+func (m *Manager) get(sessionID string) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("no terminal session %s", sessionID)
+	}
+	return s, nil
+}
+
+// Write sends what the user typed to the session.
+func (m *Manager) Write(sessionID, data string) error {
+	s, err := m.get(sessionID)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(s.term, data); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return nil
+}
+
+// Resize tells the session its terminal is now cols by rows.
+func (m *Manager) Resize(sessionID string, cols, rows uint16) error {
+	if cols == 0 || rows == 0 {
+		return fmt.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+	s, err := m.get(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.term.Resize(cols, rows); err != nil {
+		return fmt.Errorf("failed to resize terminal: %w", err)
+	}
+	return nil
+}
+
+// Close ends the session. Its ExitEvent follows once the shell has gone.
+func (m *Manager) Close(sessionID string) error {
+	s, err := m.get(sessionID)
+	if err != nil {
+		return err
+	}
+	m.log.Debug("Closing terminal", slog.String("sessionID", sessionID))
+	return s.term.Close()
+}
+
+// CloseAll ends every session and waits for them to finish, for shutdown.
+func (m *Manager) CloseAll() {
+	m.mu.Lock()
+	m.shutdown = true
+	for _, s := range m.sessions {
+		_ = s.term.Close()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
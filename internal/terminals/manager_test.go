@@ -0,0 +1,228 @@
+package terminals
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConnections map[string]bool
+
+func (f fakeConnections) IsConnected(serverID string) bool { return f[serverID] }
+
+type fakeStore struct {
+	cfg models.ConnectionConfig
+}
+
+func (f *fakeStore) StoreRegisteredServerURI(string, string) error { return nil }
+func (f *fakeStore) GetRegisteredServerURI(string) (string, error) { return f.cfg.URI, nil }
+func (f *fakeStore) DeleteRegisteredServerURI(string) error        { return nil }
+func (f *fakeStore) StoreConnectionConfig(string, models.ConnectionConfig) error {
+	return nil
+}
+func (f *fakeStore) GetConnectionConfig(string) (models.ConnectionConfig, error) {
+	return f.cfg, nil
+}
+func (f *fakeStore) UpdateRefreshToken(string, string) error { return nil }
+
+// fakeTerminal is a shell whose output the test writes to out and whose
+// input it reads from in.
+type fakeTerminal struct {
+	outR *io.PipeReader
+	outW *io.PipeWriter
+	in   chan string
+
+	mu      sync.Mutex
+	size    [2]uint16
+	closed  bool
+	exitErr error
+}
+
+func newFakeTerminal() *fakeTerminal {
+	r, w := io.Pipe()
+	return &fakeTerminal{outR: r, outW: w, in: make(chan string, 10)}
+}
+
+func (f *fakeTerminal) Read(p []byte) (int, error) { return f.outR.Read(p) }
+func (f *fakeTerminal) Write(p []byte) (int, error) {
+	f.in <- string(p)
+	return len(p), nil
+}
+func (f *fakeTerminal) Resize(cols, rows uint16) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.size = [2]uint16{cols, rows}
+	return nil
+}
+func (f *fakeTerminal) Wait() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.exitErr
+}
+func (f *fakeTerminal) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	return f.outW.Close()
+}
+
+type recorded struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (r *recorded) all() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]any(nil), r.events...)
+}
+
+// waitFor polls until an event satisfies match.
+func (r *recorded) waitFor(t *testing.T, match func(any) bool) any {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, e := range r.all() {
+			if match(e) {
+				return e
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected event never arrived; got %v", r.all())
+	return nil
+}
+
+type startCall struct {
+	uri        string
+	useOIDC    bool
+	cols, rows uint16
+}
+
+func newTestManager(t *testing.T, cfg models.ConnectionConfig) (*Manager, *fakeTerminal, *recorded, *startCall) {
+	t.Helper()
+	term := newFakeTerminal()
+	events := &recorded{}
+	started := &startCall{}
+	m := NewManager(slog.Default(), fakeConnections{"srv": true}, &fakeStore{cfg: cfg})
+	m.Init(context.Background())
+	m.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		events.events = append(events.events, data...)
+	}
+	m.startShell = func(uri string, useOIDC bool, cols, rows uint16) (terminal, error) {
+		*started = startCall{uri, useOIDC, cols, rows}
+		return term, nil
+	}
+	t.Cleanup(m.CloseAll)
+	return m, term, events, started
+}
+
+func TestManager_OpenUsesTheServersConnection(t *testing.T) {
+	m, _, _, started := newTestManager(t, models.ConnectionConfig{
+		URI:        "mongodb://example:27017",
+		AuthMethod: models.AuthOIDC,
+	})
+
+	id, err := m.Open("srv", 120, 30)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, startCall{"mongodb://example:27017", true, 120, 30}, *started)
+}
+
+func TestManager_OpenRequiresAConnection(t *testing.T) {
+	m, _, _, _ := newTestManager(t, models.ConnectionConfig{URI: "mongodb://x"})
+
+	_, err := m.Open("other", 80, 24)
+	assert.ErrorContains(t, err, "not connected")
+
+	_, err = m.Open("srv", 0, 24)
+	assert.ErrorContains(t, err, "invalid terminal size")
+}
+
+func TestManager_RelaysInputOutputAndResize(t *testing.T) {
+	m, term, events, _ := newTestManager(t, models.ConnectionConfig{URI: "mongodb://x"})
+	id, err := m.Open("srv", 80, 24)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Write(id, "db.version()\r"))
+	assert.Equal(t, "db.version()\r", <-term.in)
+
+	_, _ = term.outW.Write([]byte("test> "))
+	events.waitFor(t, func(e any) bool {
+		out, ok := e.(models.TerminalOutput)
+		return ok && out.SessionID == id && out.Data == "test> "
+	})
+
+	require.NoError(t, m.Resize(id, 100, 40))
+	term.mu.Lock()
+	assert.Equal(t, [2]uint16{100, 40}, term.size)
+	term.mu.Unlock()
+}
+
+func TestManager_KeepsSplitCharactersWhole(t *testing.T) {
+	m, term, events, _ := newTestManager(t, models.ConnectionConfig{URI: "mongodb://x"})
+	id, err := m.Open("srv", 80, 24)
+	require.NoError(t, err)
+
+	euro := []byte("€")
+	_, _ = term.outW.Write(append([]byte("a"), euro[:1]...))
+	_, _ = term.outW.Write(euro[1:])
+
+	events.waitFor(t, func(e any) bool {
+		out, ok := e.(models.TerminalOutput)
+		return ok && out.SessionID == id && out.Data == "€"
+	})
+	for _, e := range events.all() {
+		if out, ok := e.(models.TerminalOutput); ok {
+			assert.Contains(t, []string{"a", "€"}, out.Data)
+		}
+	}
+}
+
+func TestManager_CloseReportsExitAndForgetsSession(t *testing.T) {
+	m, term, events, _ := newTestManager(t, models.ConnectionConfig{URI: "mongodb://x"})
+	term.exitErr = errors.New("signal: killed")
+	id, err := m.Open("srv", 80, 24)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Close(id))
+	exit := events.waitFor(t, func(e any) bool {
+		_, ok := e.(models.TerminalExit)
+		return ok
+	}).(models.TerminalExit)
+
+	assert.Equal(t, id, exit.SessionID)
+	assert.Equal(t, "srv", exit.ServerID)
+	assert.Equal(t, -1, exit.ExitCode)
+	assert.Equal(t, "signal: killed", exit.Error)
+
+	assert.Eventually(t, func() bool { return m.Write(id, "x") != nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestManager_UnknownSession(t *testing.T) {
+	m, _, _, _ := newTestManager(t, models.ConnectionConfig{URI: "mongodb://x"})
+	assert.Error(t, m.Write("nope", "x"))
+	assert.Error(t, m.Resize("nope", 80, 24))
+	assert.Error(t, m.Close("nope"))
+}
+
+func TestSplitUTF8(t *testing.T) {
+	euro := []byte("€")
+	complete, rest := splitUTF8(append([]byte("ab"), euro[:2]...))
+	assert.Equal(t, "ab", string(complete))
+	assert.Equal(t, euro[:2], rest)
+
+	complete, rest = splitUTF8([]byte("ab€"))
+	assert.Equal(t, "ab€", string(complete))
+	assert.Nil(t, rest)
+}
@@ -0,0 +1,388 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"vervet/internal/masking"
+	"vervet/internal/models"
+	"vervet/internal/mongowrite"
+	"vervet/internal/pipeline"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// collectionSpec is a models.TransferCollection with its filter or pipeline
+// parsed.
+type collectionSpec struct {
+	models.TransferCollection
+	filter   bson.D
+	pipeline bson.A
+}
+
+// target names the copy.
+func (c collectionSpec) target() string {
+	if c.Target != "" {
+		return c.Target
+	}
+	return c.Name
+}
+
+// parseRequest checks a request and parses its collections' filters and
+// pipelines.
+func parseRequest(req models.TransferRequest) ([]collectionSpec, error) {
+	switch {
+	case req.TransferID == "":
+		return nil, errors.New("a transfer ID is required")
+	case req.SourceServerID == "" || req.TargetServerID == "":
+		return nil, errors.New("a source and a target server are required")
+	case req.SourceDatabase == "" || req.TargetDatabase == "":
+		return nil, errors.New("a source and a target database are required")
+	case len(req.Collections) == 0:
+		return nil, errors.New("choose at least one collection to copy")
+	case req.Mode != models.TransferDrop && req.Mode != models.TransferMerge:
+		return nil, fmt.Errorf("unknown transfer mode %q", req.Mode)
+	case req.BatchSize < 0:
+		return nil, errors.New("the batch size cannot be negative")
+	}
+
+	sameDatabase := req.SourceServerID == req.TargetServerID && req.SourceDatabase == req.TargetDatabase
+	specs := make([]collectionSpec, len(req.Collections))
+	targets := make(map[string]bool, len(req.Collections))
+	for i, c := range req.Collections {
+		spec := collectionSpec{TransferCollection: c}
+		if c.Name == "" {
+			return nil, errors.New("a collection name is required")
+		}
+		if targets[spec.target()] {
+			return nil, fmt.Errorf("%s is the target of more than one collection", spec.target())
+		}
+		targets[spec.target()] = true
+		if sameDatabase && spec.target() == c.Name {
+			return nil, fmt.Errorf("%s cannot be copied onto itself", c.Name)
+		}
+		if strings.TrimSpace(c.Filter) != "" && strings.TrimSpace(c.Pipeline) != "" {
+			return nil, fmt.Errorf("%s: give a filter or a pipeline, not both", c.Name)
+		}
+		if strings.TrimSpace(c.Filter) != "" {
+			if err := bson.UnmarshalExtJSON([]byte(c.Filter), false, &spec.filter); err != nil {
+				return nil, fmt.Errorf("%s: the filter must be an Extended JSON document", c.Name)
+			}
+		}
+		if strings.TrimSpace(c.Pipeline) != "" {
+			pipeline, err := parsePipeline(c.Pipeline)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.Name, err)
+			}
+			spec.pipeline = pipeline
+		}
+		specs[i] = spec
+	}
+	return specs, nil
+}
+
+// parsePipeline reads an aggregation pipeline given as an Extended JSON
+// array. Stages that write would change the source, so they are refused.
+func parsePipeline(raw string) (bson.A, error) {
+	stages, err := pipeline.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range stages {
+		if key := stage.(bson.D)[0].Key; key == "$out" || key == "$merge" {
+			return nil, fmt.Errorf("a transfer pipeline cannot use %s", key)
+		}
+	}
+	return stages, nil
+}
+
+// encodeLastID gives an _id as canonical Extended JSON, the form a
+// checkpoint keeps it in.
+func encodeLastID(id bson.RawValue) (string, error) {
+	b, err := bson.MarshalExtJSON(bson.D{{Key: "_id", Value: id}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	s := string(b)
+	return s[len(`{"_id":`) : len(s)-1], nil
+}
+
+// decodeLastID reads an _id kept by encodeLastID.
+func decodeLastID(id string) (any, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"_id":`+id+`}`), true, &doc); err != nil || len(doc) != 1 {
+		return nil, fmt.Errorf("the checkpoint's last _id %q cannot be read", id)
+	}
+	return doc[0].Value, nil
+}
+
+// after matches documents whose _id sorts after id. $expr compares across
+// BSON types in sort order, as the _id sort the copy reads in does, where a
+// plain $gt would only match ids of id's type.
+func after(id any) bson.D {
+	return bson.D{{Key: "$expr", Value: bson.D{{Key: "$gt", Value: bson.A{"$_id", bson.D{{Key: "$literal", Value: id}}}}}}}
+}
+
+// transfer is one run of a transfer, from its start or its checkpoint.
+type transfer struct {
+	svc       *Service
+	ctx       context.Context
+	req       models.TransferRequest
+	source    *mongo.Database
+	target    *mongo.Database
+	cp        *models.TransferCheckpoint
+	batchSize int
+	progress  *progress
+	mask      *masking.Masker // nil copies documents as they are
+}
+
+// copyCollection copies one collection: it prepares the target, copies the
+// documents after the checkpoint's last _id, then builds the indexes.
+func (t *transfer) copyCollection(spec collectionSpec, state *models.TransferCollectionState) error {
+	info, err := t.sourceInfo(spec.Name)
+	if err != nil {
+		return err
+	}
+	src := t.source.Collection(spec.Name)
+	dst := t.target.Collection(spec.target())
+
+	if !state.Prepared {
+		if err := t.prepare(spec, info, dst); err != nil {
+			return err
+		}
+		state.Prepared = true
+		if err := t.save(); err != nil {
+			return err
+		}
+	}
+
+	var total atomic.Int64
+	if spec.pipeline == nil {
+		go func() {
+			if n, err := src.CountDocuments(t.ctx, filterOrAll(spec.filter)); err == nil {
+				total.Store(n)
+			}
+		}()
+	}
+
+	cursor, err := t.open(src, spec, state.LastID)
+	if err != nil {
+		return fmt.Errorf("error reading %s.%s: %w", t.req.SourceDatabase, spec.Name, err)
+	}
+	defer cursor.Close(t.ctx)
+
+	// A resumed copy may resend documents written after the checkpoint was
+	// last saved, so its first batch replaces rather than inserts.
+	replace := t.req.Mode == models.TransferMerge || state.LastID != ""
+	var batch []bson.Raw
+	var batchBytes int
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		docs := batch
+		if t.mask != nil {
+			var err error
+			if docs, err = t.mask.Mask(batch); err != nil {
+				return err
+			}
+		}
+		if err := t.write(dst, docs, replace, state); err != nil {
+			return err
+		}
+		// The position is the source's _id, which masking may have changed.
+		if id, err := batch[len(batch)-1].LookupErr("_id"); err == nil {
+			if state.LastID, err = encodeLastID(id); err != nil {
+				return fmt.Errorf("error saving the transfer's position: %w", err)
+			}
+		}
+		replace = t.req.Mode == models.TransferMerge
+		batch, batchBytes = nil, 0
+		if err := t.save(); err != nil {
+			return err
+		}
+		t.progress.update(spec.Name, state.Copied, total.Load())
+		return nil
+	}
+	for cursor.Next(t.ctx) {
+		doc := make(bson.Raw, len(cursor.Current))
+		copy(doc, cursor.Current)
+		batch = append(batch, doc)
+		batchBytes += len(doc)
+		if len(batch) >= t.batchSize || batchBytes >= maxBatchBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("error reading %s.%s: %w", t.req.SourceDatabase, spec.Name, err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if t.req.CopyIndexes && info.Type != "view" {
+		if err := t.copyIndexes(src, dst, state); err != nil {
+			return err
+		}
+	}
+	state.Done = true
+	return t.save()
+}
+
+// sourceInfo describes a source collection, failing if it doesn't exist.
+func (t *transfer) sourceInfo(name string) (mongo.CollectionSpecification, error) {
+	specs, err := t.source.ListCollectionSpecifications(t.ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		return mongo.CollectionSpecification{}, fmt.Errorf("error reading %s.%s: %w", t.req.SourceDatabase, name, err)
+	}
+	if len(specs) == 0 {
+		return mongo.CollectionSpecification{}, fmt.Errorf("%s.%s does not exist", t.req.SourceDatabase, name)
+	}
+	return specs[0], nil
+}
+
+// prepare drops the target in drop mode and, when options are copied,
+// creates it like the source. A target that already exists in merge mode
+// keeps its own options.
+func (t *transfer) prepare(spec collectionSpec, info mongo.CollectionSpecification, dst *mongo.Collection) error {
+	if t.req.Mode == models.TransferDrop {
+		if err := dst.Drop(t.ctx); err != nil {
+			return fmt.Errorf("error dropping %s.%s: %w", t.req.TargetDatabase, spec.target(), err)
+		}
+	}
+	if !t.req.CopyOptions || info.Type == "view" {
+		return nil
+	}
+	cmd := bson.D{{Key: "create", Value: spec.target()}}
+	if len(info.Options) > 0 {
+		var opts bson.D
+		if err := bson.Unmarshal(info.Options, &opts); err != nil {
+			return fmt.Errorf("error reading the options of %s.%s: %w", t.req.SourceDatabase, spec.Name, err)
+		}
+		cmd = append(cmd, opts...)
+	}
+	err := t.target.RunCommand(t.ctx, cmd).Err()
+	if mongowrite.NamespaceExists(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error creating %s.%s: %w", t.req.TargetDatabase, spec.target(), err)
+	}
+	return nil
+}
+
+// open reads the documents to copy in _id order, starting after lastID when
+// the copy is resumed.
+func (t *transfer) open(src *mongo.Collection, spec collectionSpec, lastID string) (*mongo.Cursor, error) {
+	var resume bson.D
+	if lastID != "" {
+		id, err := decodeLastID(lastID)
+		if err != nil {
+			return nil, err
+		}
+		resume = after(id)
+	}
+	byID := bson.D{{Key: "_id", Value: 1}}
+
+	if spec.pipeline != nil {
+		pipeline := append(bson.A{}, spec.pipeline...)
+		if resume != nil {
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: resume}})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: byID}})
+		return src.Aggregate(t.ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	}
+	filter := filterOrAll(spec.filter)
+	if resume != nil {
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, resume}}}
+	}
+	return src.Find(t.ctx, filter, options.Find().SetSort(byID))
+}
+
+// write sends one batch. Inserting is quickest; replacing by _id keeps a
+// document the target already has from failing as a duplicate. Documents
+// the target refuses are counted and the rest are still written.
+func (t *transfer) write(dst *mongo.Collection, batch []bson.Raw, replace bool, state *models.TransferCollectionState) error {
+	var err error
+	if replace {
+		writes := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			id, lookupErr := doc.LookupErr("_id")
+			if lookupErr != nil {
+				writes[i] = mongo.NewInsertOneModel().SetDocument(doc)
+				continue
+			}
+			writes[i] = mongo.NewReplaceOneModel().
+				SetFilter(bson.D{{Key: "_id", Value: id}}).
+				SetReplacement(doc).
+				SetUpsert(true)
+		}
+		_, err = dst.BulkWrite(t.ctx, writes, options.BulkWrite().SetOrdered(false))
+	} else {
+		_, err = dst.InsertMany(t.ctx, batch, options.InsertMany().SetOrdered(false))
+	}
+	failed, err := mongowrite.WriteErrors(err)
+	if err != nil {
+		return fmt.Errorf("error writing to %s.%s: %w", t.req.TargetDatabase, dst.Name(), err)
+	}
+
+	state.Copied += int64(len(batch) - len(failed))
+	state.Failed += int64(len(failed))
+	if len(failed) > 0 && state.FirstError == "" {
+		state.FirstError = failed[0].Message
+	}
+	return nil
+}
+
+// copyIndexes builds the source's indexes on the target. An index the
+// target cannot build is reported against the collection rather than
+// stopping the transfer.
+func (t *transfer) copyIndexes(src, dst *mongo.Collection, state *models.TransferCollectionState) error {
+	cursor, err := src.Indexes().List(t.ctx)
+	if err != nil {
+		return fmt.Errorf("error listing the indexes of %s.%s: %w", t.req.SourceDatabase, src.Name(), err)
+	}
+	var indexes []bson.D
+	if err := cursor.All(t.ctx, &indexes); err != nil {
+		return fmt.Errorf("error listing the indexes of %s.%s: %w", t.req.SourceDatabase, src.Name(), err)
+	}
+	specs := mongowrite.IndexesToCreate(indexes)
+	if len(specs) == 0 {
+		return nil
+	}
+	cmd := bson.D{{Key: "createIndexes", Value: dst.Name()}, {Key: "indexes", Value: specs}}
+	if err := t.target.RunCommand(t.ctx, cmd).Err(); err != nil {
+		if t.ctx.Err() != nil {
+			return t.ctx.Err()
+		}
+		if state.FirstError == "" {
+			state.FirstError = "error creating indexes: " + err.Error()
+		}
+		return nil
+	}
+	state.Indexes = len(specs)
+	return nil
+}
+
+// save stores the checkpoint after progress.
+func (t *transfer) save() error {
+	if err := t.svc.saveCheckpoint(*t.cp); err != nil {
+		return fmt.Errorf("error saving the transfer's checkpoint: %w", err)
+	}
+	return nil
+}
+
+// filterOrAll gives an empty filter for a nil one, which the driver refuses.
+func filterOrAll(filter bson.D) bson.D {
+	if filter == nil {
+		return bson.D{}
+	}
+	return filter
+}
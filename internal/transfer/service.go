@@ -0,0 +1,379 @@
+// Package transfer copies collections from one server and database to
+// another, checkpointing as it goes so an interrupted copy can be resumed.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"vervet/internal/audit"
+	"vervet/internal/logging"
+	"vervet/internal/masking"
+	"vervet/internal/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// TransferProgressEvent carries a models.TransferProgress from a running
+	// transfer.
+	TransferProgressEvent = "transfer-progress"
+
+	// DefaultBatchSize is how many documents each write sends when the
+	// request does not say.
+	DefaultBatchSize = 1000
+	// maxBatchBytes ends a batch early when its documents are large.
+	maxBatchBytes = 16 * 1024 * 1024
+)
+
+// progressInterval is the least time between progress events for one
+// transfer.
+const progressInterval = 100 * time.Millisecond
+
+// ClientProvider provides access to active MongoDB connections
+type ClientProvider interface {
+	GetClient(serverID string) (*mongo.Client, error)
+}
+
+// Auditor records the changes the service makes to a server.
+// Implemented by audit.Service.
+type Auditor interface {
+	Record(action models.AuditAction) error
+}
+
+// MaskerSource compiles saved masking profiles by name.
+// Implemented by masking.Service.
+type MaskerSource interface {
+	Masker(name string) (*masking.Masker, error)
+}
+
+// Service copies collections between servers.
+type Service struct {
+	mu      sync.Mutex
+	log     *slog.Logger
+	ctx     context.Context
+	clients ClientProvider
+	store   CheckpointStore
+	auditor Auditor
+	masks   MaskerSource
+	cancels map[string]context.CancelFunc // transferID -> cancel for a running transfer
+
+	storeMu sync.Mutex // serialises reading, changing and saving checkpoints
+
+	// emit is a seam over runtime.EventsEmit, which log.Fatalf's without a
+	// Wails context, so tests must replace it.
+	emit func(ctx context.Context, eventName string, optionalData ...interface{})
+	now  func() time.Time
+}
+
+func NewService(log *slog.Logger, clients ClientProvider, store CheckpointStore) *Service {
+	return &Service{
+		log:     log.With(slog.String(logging.SourceKey, "TransferService")),
+		clients: clients,
+		store:   store,
+		cancels: make(map[string]context.CancelFunc),
+		emit:    runtime.EventsEmit,
+		now:     time.Now,
+	}
+}
+
+// Init stores the Wails application context, used as the parent for
+// transfers.
+func (s *Service) Init(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// SetAuditor makes the service audit every collection it copies into.
+func (s *Service) SetAuditor(a Auditor) {
+	s.auditor = a
+}
+
+// SetMasking lets requests name a saved masking profile to apply.
+func (s *Service) SetMasking(masks MaskerSource) {
+	s.masks = masks
+}
+
+// Transfer copies the request's collections. Documents the target refuses
+// are counted against their collection and do not stop the transfer; an
+// error means a server failed and the transfer stopped part way. A transfer
+// that stops, or is cancelled, keeps a checkpoint and can be resumed.
+func (s *Service) Transfer(req models.TransferRequest) (models.TransferResult, error) {
+	if _, err := parseRequest(req); err != nil {
+		return models.TransferResult{}, err
+	}
+	return s.run(models.TransferCheckpoint{Request: req})
+}
+
+// Resume carries on an interrupted transfer from its checkpoint.
+func (s *Service) Resume(transferID string) (models.TransferResult, error) {
+	checkpoints, err := s.loadCheckpoints()
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	i := slices.IndexFunc(checkpoints, func(c models.TransferCheckpoint) bool {
+		return c.Request.TransferID == transferID
+	})
+	if i < 0 {
+		return models.TransferResult{}, errors.New("no interrupted transfer to resume")
+	}
+	return s.run(checkpoints[i])
+}
+
+// Interrupted lists the transfers that can be resumed, the most recently
+// interrupted first.
+func (s *Service) Interrupted() ([]models.TransferCheckpoint, error) {
+	checkpoints, err := s.loadCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(checkpoints, func(a, b models.TransferCheckpoint) int {
+		// RFC 3339 in UTC sorts as text.
+		return -compareStrings(a.UpdatedAt, b.UpdatedAt)
+	})
+	if checkpoints == nil {
+		checkpoints = []models.TransferCheckpoint{}
+	}
+	return checkpoints, nil
+}
+
+// Discard forgets an interrupted transfer, so it can no longer be resumed.
+// What it copied stays copied.
+func (s *Service) Discard(transferID string) error {
+	return s.updateCheckpoints(func(checkpoints []models.TransferCheckpoint) []models.TransferCheckpoint {
+		return slices.DeleteFunc(checkpoints, func(c models.TransferCheckpoint) bool {
+			return c.Request.TransferID == transferID
+		})
+	})
+}
+
+// Cancel stops a running transfer after the write in flight. It can be
+// resumed later.
+func (s *Service) Cancel(transferID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[transferID]; ok {
+		cancel()
+	}
+}
+
+func (s *Service) register(transferID string, cancel context.CancelFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, running := s.cancels[transferID]; running {
+		return errors.New("this transfer is already running")
+	}
+	s.cancels[transferID] = cancel
+	return nil
+}
+
+func (s *Service) unregister(transferID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, transferID)
+}
+
+// run copies each collection the checkpoint has not finished.
+func (s *Service) run(cp models.TransferCheckpoint) (models.TransferResult, error) {
+	req := cp.Request
+	specs, err := parseRequest(req)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	source, err := s.clients.GetClient(req.SourceServerID)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	target, err := s.clients.GetClient(req.TargetServerID)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	var mask *masking.Masker
+	if req.MaskingProfile != "" {
+		if s.masks == nil {
+			return models.TransferResult{}, errors.New("masking is not available")
+		}
+		if mask, err = s.masks.Masker(req.MaskingProfile); err != nil {
+			return models.TransferResult{}, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	if err := s.register(req.TransferID, cancel); err != nil {
+		return models.TransferResult{}, err
+	}
+	defer s.unregister(req.TransferID)
+
+	// A checkpoint for a different list of collections can't be trusted.
+	if len(cp.Collections) != len(specs) {
+		cp.Collections = make([]models.TransferCollectionState, len(specs))
+		for i, spec := range specs {
+			cp.Collections[i].Name = spec.Name
+		}
+	}
+
+	t := &transfer{
+		svc:       s,
+		ctx:       ctx,
+		req:       req,
+		source:    source.Database(req.SourceDatabase),
+		target:    target.Database(req.TargetDatabase),
+		cp:        &cp,
+		batchSize: req.BatchSize,
+		progress:  &progress{svc: s, current: models.TransferProgress{TransferID: req.TransferID, Collections: len(specs)}},
+		mask:      mask,
+	}
+	if t.batchSize <= 0 {
+		t.batchSize = DefaultBatchSize
+	}
+
+	for i, spec := range specs {
+		state := &cp.Collections[i]
+		if !state.Done {
+			if err = t.copyCollection(spec, state); err != nil {
+				break
+			}
+		}
+		t.progress.done()
+	}
+
+	result := models.TransferResult{Collections: make([]models.TransferCollectionResult, len(specs))}
+	for i, spec := range specs {
+		state := cp.Collections[i]
+		result.Collections[i] = models.TransferCollectionResult{
+			Name:       spec.Name,
+			Target:     spec.target(),
+			Copied:     state.Copied,
+			Failed:     state.Failed,
+			FirstError: state.FirstError,
+			Indexes:    state.Indexes,
+		}
+	}
+	s.audit(req, specs, cp, err)
+
+	if err != nil {
+		// Batches save the checkpoint as they go; this keeps a transfer that
+		// stopped before its first one.
+		if saveErr := s.saveCheckpoint(cp); saveErr != nil {
+			s.log.Warn("Failed to save the checkpoint of an interrupted transfer", slog.Any("error", saveErr))
+		}
+		if ctx.Err() != nil {
+			s.log.Info("Transfer cancelled", slog.String("transferId", req.TransferID))
+			result.Cancelled = true
+			return result, nil
+		}
+		return result, fmt.Errorf("transfer stopped: %w", err)
+	}
+	if err := s.Discard(req.TransferID); err != nil {
+		s.log.Warn("Failed to remove the checkpoint of a finished transfer", slog.Any("error", err))
+	}
+	return result, nil
+}
+
+// saveCheckpoint stores cp in place of any earlier checkpoint of the same
+// transfer.
+func (s *Service) saveCheckpoint(cp models.TransferCheckpoint) error {
+	cp.UpdatedAt = s.now().UTC().Format(time.RFC3339)
+	return s.updateCheckpoints(func(checkpoints []models.TransferCheckpoint) []models.TransferCheckpoint {
+		for i := range checkpoints {
+			if checkpoints[i].Request.TransferID == cp.Request.TransferID {
+				checkpoints[i] = cp
+				return checkpoints
+			}
+		}
+		return append(checkpoints, cp)
+	})
+}
+
+func (s *Service) loadCheckpoints() ([]models.TransferCheckpoint, error) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	return s.store.Load()
+}
+
+func (s *Service) updateCheckpoints(change func([]models.TransferCheckpoint) []models.TransferCheckpoint) error {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	checkpoints, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+	return s.store.Save(change(checkpoints))
+}
+
+// audit records each collection copied into as one change.
+func (s *Service) audit(req models.TransferRequest, specs []collectionSpec, cp models.TransferCheckpoint, err error) {
+	if s.auditor == nil {
+		return
+	}
+	for i, spec := range specs {
+		state := cp.Collections[i]
+		if !state.Prepared {
+			continue
+		}
+		action := models.AuditAction{
+			ServerID:      req.TargetServerID,
+			Database:      req.TargetDatabase,
+			Collection:    spec.target(),
+			Operation:     "transfer",
+			Source:        models.AuditSourceApp,
+			Target:        req.SourceDatabase + "." + spec.Name,
+			AffectedCount: state.Copied,
+		}
+		// A collection that finished copying did not fail with the rest.
+		failure := err
+		if state.Done {
+			failure = nil
+		}
+		audit.RecordOrLog(s.log, s.auditor, action, failure)
+	}
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// progress reports how far a transfer has got.
+type progress struct {
+	svc      *Service
+	current  models.TransferProgress
+	lastSent time.Time
+}
+
+// update reports copied of total documents in collection.
+func (p *progress) update(collection string, copied, total int64) {
+	p.current.Collection = collection
+	p.current.Copied = copied
+	p.current.Total = total
+	p.send(false)
+}
+
+// done reports a collection finished.
+func (p *progress) done() {
+	p.current.CollectionsDone++
+	p.send(true)
+}
+
+// send emits a progress event, dropping those that come sooner than
+// progressInterval after the last unless final.
+func (p *progress) send(final bool) {
+	now := time.Now()
+	if !final && now.Sub(p.lastSent) < progressInterval {
+		return
+	}
+	p.lastSent = now
+	p.svc.emit(p.svc.ctx, TransferProgressEvent, p.current)
+}
@@ -0,0 +1,253 @@
+//go:build integration
+
+package transfer
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"vervet/internal/models"
+)
+
+var testClient *mongo.Client
+
+// stubProvider serves every server ID from the one test server, so "two
+// servers" are two databases on it.
+type stubProvider struct {
+	client *mongo.Client
+}
+
+func (s stubProvider) GetClient(string) (*mongo.Client, error) {
+	return s.client, nil
+}
+
+type recordingAuditor struct {
+	actions []models.AuditAction
+}
+
+func (r *recordingAuditor) Record(action models.AuditAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	os.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
+
+	container, err := mongodb.Run(ctx, "mongo:7")
+	if err != nil {
+		log.Fatalf("start container: %v", err)
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			log.Printf("terminate: %v", err)
+		}
+	}()
+
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		log.Fatalf("conn string: %v", err)
+	}
+
+	testClient, err = mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer testClient.Disconnect(ctx)
+
+	os.Exit(m.Run())
+}
+
+func newService(t *testing.T) (*Service, *mockStore, *[]models.TransferProgress) {
+	t.Helper()
+	store := &mockStore{}
+	svc := NewService(slog.Default(), stubProvider{client: testClient}, store)
+	svc.Init(context.Background())
+	var events []models.TransferProgress
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		events = append(events, data[0].(models.TransferProgress))
+	}
+	return svc, store, &events
+}
+
+// seed creates a source database with an indexed collection, a validated
+// capped collection and a view, and an empty target database.
+func seed(t *testing.T, source, target string) {
+	t.Helper()
+	ctx := context.Background()
+	for _, name := range []string{source, target} {
+		db := testClient.Database(name)
+		require.NoError(t, db.Drop(ctx))
+		t.Cleanup(func() { _ = db.Drop(context.Background()) })
+	}
+	db := testClient.Database(source)
+
+	orders := make([]any, 2500)
+	for i := range orders {
+		orders[i] = bson.D{{Key: "_id", Value: i}, {Key: "sku", Value: "sku-" + string(rune('a'+i%26))}, {Key: "total", Value: i}}
+	}
+	_, err := db.Collection("orders").InsertMany(ctx, orders)
+	require.NoError(t, err)
+	_, err = db.Collection("orders").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "sku", Value: 1}},
+		Options: options.Index().SetName("sku_1"),
+	})
+	require.NoError(t, err)
+
+	validator := bson.D{{Key: "$jsonSchema", Value: bson.D{{Key: "required", Value: bson.A{"msg"}}}}}
+	require.NoError(t, db.CreateCollection(ctx, "log", options.CreateCollection().
+		SetCapped(true).SetSizeInBytes(1<<20).SetValidator(validator)))
+	_, err = db.Collection("log").InsertOne(ctx, bson.D{{Key: "msg", Value: "started"}})
+	require.NoError(t, err)
+
+	require.NoError(t, db.CreateView(ctx, "big", "orders", bson.A{bson.D{{Key: "$match", Value: bson.D{{Key: "total", Value: bson.D{{Key: "$gte", Value: 2000}}}}}}}))
+}
+
+func request(id, source, target string, collections ...models.TransferCollection) models.TransferRequest {
+	return models.TransferRequest{
+		TransferID:     id,
+		SourceServerID: "a",
+		SourceDatabase: source,
+		TargetServerID: "b",
+		TargetDatabase: target,
+		Collections:    collections,
+		Mode:           models.TransferDrop,
+		CopyIndexes:    true,
+		CopyOptions:    true,
+		BatchSize:      300,
+	}
+}
+
+func count(t *testing.T, db, coll string, filter bson.D) int64 {
+	t.Helper()
+	n, err := testClient.Database(db).Collection(coll).CountDocuments(context.Background(), filter)
+	require.NoError(t, err)
+	return n
+}
+
+func TestTransfer(t *testing.T) {
+	seed(t, "transfer_src", "transfer_dst")
+	svc, store, events := newService(t)
+	auditor := &recordingAuditor{}
+	svc.SetAuditor(auditor)
+
+	result, err := svc.Transfer(request("t1", "transfer_src", "transfer_dst",
+		models.TransferCollection{Name: "orders"},
+		models.TransferCollection{Name: "log", Target: "events"},
+		models.TransferCollection{Name: "big"},
+		models.TransferCollection{Name: "orders", Target: "cheap", Filter: `{"total": {"$lt": 10}}`},
+		models.TransferCollection{Name: "orders", Target: "skus", Pipeline: `[{"$group": {"_id": "$sku", "n": {"$sum": 1}}}]`},
+	))
+	require.NoError(t, err)
+	assert.False(t, result.Cancelled)
+	require.Len(t, result.Collections, 5)
+	assert.Equal(t, models.TransferCollectionResult{Name: "orders", Target: "orders", Copied: 2500, Indexes: 1}, result.Collections[0])
+	assert.Equal(t, int64(1), result.Collections[1].Copied)
+	assert.Equal(t, int64(500), result.Collections[2].Copied)
+	assert.Equal(t, int64(10), result.Collections[3].Copied)
+	assert.Equal(t, int64(26), result.Collections[4].Copied)
+
+	assert.Equal(t, int64(2500), count(t, "transfer_dst", "orders", bson.D{}))
+	assert.Equal(t, int64(26), count(t, "transfer_dst", "skus", bson.D{}))
+
+	// The capped collection kept its options under its new name; the view
+	// became a collection.
+	specs, err := testClient.Database("transfer_dst").ListCollectionSpecifications(context.Background(), bson.D{})
+	require.NoError(t, err)
+	byName := map[string]mongo.CollectionSpecification{}
+	for _, s := range specs {
+		byName[s.Name] = s
+	}
+	assert.Equal(t, "collection", byName["big"].Type)
+	var opts bson.M
+	require.NoError(t, bson.Unmarshal(byName["events"].Options, &opts))
+	assert.Equal(t, true, opts["capped"])
+	assert.Contains(t, opts, "validator")
+
+	indexes, err := testClient.Database("transfer_dst").Collection("orders").Indexes().ListSpecifications(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, indexes, 2)
+
+	assert.Empty(t, store.checkpoints, "a finished transfer keeps no checkpoint")
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, 5, last.CollectionsDone)
+	require.Len(t, auditor.actions, 5)
+	assert.Equal(t, models.AuditAction{
+		ServerID: "b", Database: "transfer_dst", Collection: "events", Operation: "transfer",
+		Source: models.AuditSourceApp, Target: "transfer_src.log", AffectedCount: 1,
+	}, auditor.actions[1])
+}
+
+func TestTransfer_Merge(t *testing.T) {
+	seed(t, "transfer_merge_src", "transfer_merge_dst")
+	ctx := context.Background()
+	dst := testClient.Database("transfer_merge_dst").Collection("orders")
+	_, err := dst.InsertMany(ctx, []any{
+		bson.D{{Key: "_id", Value: 0}, {Key: "sku", Value: "stale"}},
+		bson.D{{Key: "_id", Value: "kept"}},
+	})
+	require.NoError(t, err)
+
+	svc, _, _ := newService(t)
+	req := request("t1", "transfer_merge_src", "transfer_merge_dst", models.TransferCollection{Name: "orders"})
+	req.Mode = models.TransferMerge
+	result, err := svc.Transfer(req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), result.Collections[0].Copied)
+	assert.Zero(t, result.Collections[0].Failed)
+
+	assert.Equal(t, int64(2501), count(t, "transfer_merge_dst", "orders", bson.D{}))
+	assert.Equal(t, int64(0), count(t, "transfer_merge_dst", "orders", bson.D{{Key: "sku", Value: "stale"}}))
+}
+
+func TestTransfer_CancelAndResume(t *testing.T) {
+	seed(t, "transfer_resume_src", "transfer_resume_dst")
+	svc, store, _ := newService(t)
+	svc.emit = func(_ context.Context, _ string, data ...interface{}) {
+		if data[0].(models.TransferProgress).Copied > 0 {
+			svc.Cancel("t1")
+		}
+	}
+
+	req := request("t1", "transfer_resume_src", "transfer_resume_dst", models.TransferCollection{Name: "orders"})
+	result, err := svc.Transfer(req)
+	require.NoError(t, err)
+	require.True(t, result.Cancelled)
+	require.Len(t, store.checkpoints, 1)
+	state := store.checkpoints[0].Collections[0]
+	assert.True(t, state.Prepared)
+	assert.False(t, state.Done)
+	assert.Equal(t, int64(300), state.Copied)
+	assert.Equal(t, `{"$numberInt":"299"}`, state.LastID)
+
+	interrupted, err := svc.Interrupted()
+	require.NoError(t, err)
+	require.Len(t, interrupted, 1)
+
+	svc.emit = func(context.Context, string, ...interface{}) {}
+	result, err = svc.Resume("t1")
+	require.NoError(t, err)
+	assert.False(t, result.Cancelled)
+	assert.Equal(t, int64(2500), result.Collections[0].Copied)
+	assert.Equal(t, int64(2500), count(t, "transfer_resume_dst", "orders", bson.D{}))
+	assert.Empty(t, store.checkpoints)
+}
+
+func TestTransfer_MissingCollection(t *testing.T) {
+	seed(t, "transfer_missing_src", "transfer_missing_dst")
+	svc, store, _ := newService(t)
+	_, err := svc.Transfer(request("t1", "transfer_missing_src", "transfer_missing_dst", models.TransferCollection{Name: "nope"}))
+	assert.ErrorContains(t, err, "transfer_missing_src.nope does not exist")
+	assert.Len(t, store.checkpoints, 1, "a failed transfer can be resumed")
+}
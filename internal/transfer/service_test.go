@@ -0,0 +1,179 @@
+package transfer
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"vervet/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type mockStore struct {
+	checkpoints []models.TransferCheckpoint
+	saves       int
+	loadErr     error
+}
+
+func (m *mockStore) Load() ([]models.TransferCheckpoint, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	return append([]models.TransferCheckpoint(nil), m.checkpoints...), nil
+}
+
+func (m *mockStore) Save(checkpoints []models.TransferCheckpoint) error {
+	m.saves++
+	m.checkpoints = append([]models.TransferCheckpoint(nil), checkpoints...)
+	return nil
+}
+
+func newTestService() (*Service, *mockStore) {
+	store := &mockStore{}
+	return NewService(slog.Default(), nil, store), store
+}
+
+func validRequest() models.TransferRequest {
+	return models.TransferRequest{
+		TransferID:     "t1",
+		SourceServerID: "a",
+		SourceDatabase: "shop",
+		TargetServerID: "b",
+		TargetDatabase: "shop",
+		Collections:    []models.TransferCollection{{Name: "orders"}},
+		Mode:           models.TransferMerge,
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	specs, err := parseRequest(validRequest())
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+	assert.Equal(t, "orders", specs[0].target())
+
+	tests := []struct {
+		name   string
+		change func(*models.TransferRequest)
+		err    string
+	}{
+		{"no ID", func(r *models.TransferRequest) { r.TransferID = "" }, "transfer ID is required"},
+		{"no target server", func(r *models.TransferRequest) { r.TargetServerID = "" }, "source and a target server"},
+		{"no source database", func(r *models.TransferRequest) { r.SourceDatabase = "" }, "source and a target database"},
+		{"no collections", func(r *models.TransferRequest) { r.Collections = nil }, "at least one collection"},
+		{"bad mode", func(r *models.TransferRequest) { r.Mode = "append" }, `unknown transfer mode "append"`},
+		{"negative batch", func(r *models.TransferRequest) { r.BatchSize = -1 }, "batch size cannot be negative"},
+		{"onto itself", func(r *models.TransferRequest) { r.TargetServerID = "a" }, "orders cannot be copied onto itself"},
+		{"same target twice", func(r *models.TransferRequest) {
+			r.Collections = append(r.Collections, models.TransferCollection{Name: "old", Target: "orders"})
+		}, "orders is the target of more than one collection"},
+		{"filter and pipeline", func(r *models.TransferRequest) {
+			r.Collections[0].Filter = `{}`
+			r.Collections[0].Pipeline = `[]`
+		}, "not both"},
+		{"bad filter", func(r *models.TransferRequest) { r.Collections[0].Filter = `[1]` }, "filter must be an Extended JSON document"},
+		{"bad pipeline", func(r *models.TransferRequest) { r.Collections[0].Pipeline = `{"$match":{}}` }, "must be an Extended JSON array"},
+		{"writing pipeline", func(r *models.TransferRequest) { r.Collections[0].Pipeline = `[{"$out":"x"}]` }, "cannot use $out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.change(&req)
+			_, err := parseRequest(req)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseRequest_RenamedInSameDatabase(t *testing.T) {
+	req := validRequest()
+	req.TargetServerID = "a"
+	req.Collections[0].Target = "orders_copy"
+	req.Collections[0].Filter = `{"total": {"$gt": 10}}`
+
+	specs, err := parseRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "orders_copy", specs[0].target())
+	assert.Equal(t, bson.D{{Key: "total", Value: bson.D{{Key: "$gt", Value: int32(10)}}}}, specs[0].filter)
+}
+
+func TestLastID_RoundTrip(t *testing.T) {
+	oid := bson.NewObjectID()
+	for _, id := range []any{oid, int32(7), "abc", bson.D{{Key: "a", Value: int64(1)}}} {
+		doc, err := bson.Marshal(bson.D{{Key: "_id", Value: id}})
+		require.NoError(t, err)
+
+		encoded, err := encodeLastID(bson.Raw(doc).Lookup("_id"))
+		require.NoError(t, err)
+		decoded, err := decodeLastID(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, id, decoded)
+	}
+
+	_, err := decodeLastID("not json")
+	assert.ErrorContains(t, err, "cannot be read")
+}
+
+func TestCheckpoints(t *testing.T) {
+	svc, store := newTestService()
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return clock }
+
+	first := models.TransferCheckpoint{Request: validRequest()}
+	require.NoError(t, svc.saveCheckpoint(first))
+
+	clock = clock.Add(time.Hour)
+	second := models.TransferCheckpoint{Request: validRequest()}
+	second.Request.TransferID = "t2"
+	require.NoError(t, svc.saveCheckpoint(second))
+
+	// Saving again replaces the transfer's earlier checkpoint.
+	clock = clock.Add(time.Hour)
+	first.Collections = []models.TransferCollectionState{{Name: "orders", Prepared: true, Copied: 10}}
+	require.NoError(t, svc.saveCheckpoint(first))
+	require.Len(t, store.checkpoints, 2)
+
+	interrupted, err := svc.Interrupted()
+	require.NoError(t, err)
+	require.Len(t, interrupted, 2)
+	assert.Equal(t, "t1", interrupted[0].Request.TransferID)
+	assert.Equal(t, "2026-03-01T14:00:00Z", interrupted[0].UpdatedAt)
+	assert.Equal(t, int64(10), interrupted[0].Collections[0].Copied)
+	assert.Equal(t, "t2", interrupted[1].Request.TransferID)
+
+	require.NoError(t, svc.Discard("t1"))
+	interrupted, err = svc.Interrupted()
+	require.NoError(t, err)
+	require.Len(t, interrupted, 1)
+	assert.Equal(t, "t2", interrupted[0].Request.TransferID)
+}
+
+func TestInterrupted_Empty(t *testing.T) {
+	svc, _ := newTestService()
+	interrupted, err := svc.Interrupted()
+	require.NoError(t, err)
+	assert.NotNil(t, interrupted)
+	assert.Empty(t, interrupted)
+}
+
+func TestResume(t *testing.T) {
+	svc, store := newTestService()
+	_, err := svc.Resume("t1")
+	assert.ErrorContains(t, err, "no interrupted transfer to resume")
+
+	store.loadErr = errors.New("disk on fire")
+	_, err = svc.Resume("t1")
+	assert.ErrorContains(t, err, "disk on fire")
+}
+
+func TestTransfer_Validation(t *testing.T) {
+	svc, store := newTestService()
+	req := validRequest()
+	req.Mode = ""
+	_, err := svc.Transfer(req)
+	assert.ErrorContains(t, err, "unknown transfer mode")
+	assert.Zero(t, store.saves)
+}
@@ -0,0 +1,63 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"vervet/internal/infrastructure"
+	"vervet/internal/logging"
+	"vervet/internal/models"
+)
+
+// CheckpointStore persists the checkpoints of interrupted transfers.
+type CheckpointStore interface {
+	Load() ([]models.TransferCheckpoint, error)
+	Save(checkpoints []models.TransferCheckpoint) error
+}
+
+type store struct {
+	cfgStore infrastructure.Store
+	log      *slog.Logger
+}
+
+// NewStore returns the store kept in transfers.json in the config directory.
+// Like the query history it is JSON, never edited by hand and rewritten
+// after every batch a transfer writes.
+func NewStore(log *slog.Logger) (*store, error) {
+	logger := log.With(slog.String(logging.SourceKey, "TransferStore"))
+	cfgStore, err := infrastructure.NewStore("transfers.json", logger)
+	if err != nil {
+		return nil, fmt.Errorf("error loading transfer checkpoints: %w", err)
+	}
+	return &store{cfgStore: cfgStore, log: logger}, nil
+}
+
+// Load reads the checkpoints. A file that cannot be parsed is logged and
+// treated as empty: the transfers it described can still be started again.
+func (s *store) Load() ([]models.TransferCheckpoint, error) {
+	b, err := s.cfgStore.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error loading transfer checkpoints: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var checkpoints []models.TransferCheckpoint
+	if err := json.Unmarshal(b, &checkpoints); err != nil {
+		s.log.Warn("Transfer checkpoints are corrupted, discarding them", slog.Any("error", err))
+		return nil, nil
+	}
+	return checkpoints, nil
+}
+
+func (s *store) Save(checkpoints []models.TransferCheckpoint) error {
+	b, err := json.Marshal(checkpoints)
+	if err != nil {
+		return fmt.Errorf("error marshalling transfer checkpoints: %w", err)
+	}
+	if err := s.cfgStore.Save(b); err != nil {
+		return fmt.Errorf("error saving transfer checkpoints: %w", err)
+	}
+	return nil
+}
@@ -101,6 +101,14 @@ func main() {
 			application.UpdatesProxy,
 			application.ExportProxy,
 			application.OIDCProxy,
+			application.TerminalProxy,
+			application.HistoryProxy,
+			application.AuditProxy,
+			application.ImportProxy,
+			application.BackupProxy,
+			application.TransferProxy,
+			application.MaskingProxy,
+			application.GenerateProxy,
 		},
 		EnumBind: []any{
 			api.AllOperatingSystems,